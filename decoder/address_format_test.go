@@ -0,0 +1,165 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_CandidatesAtPos_addressFormat(t *testing.T) {
+	targets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "local"},
+				lang.AttrStep{Name: "café"},
+			},
+			Type: cty.String,
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "local"},
+				lang.AttrStep{Name: "my key"},
+			},
+			Type: cty.String,
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "local"},
+				lang.IndexStep{Key: cty.StringVal("${x}\n\"ü\"")},
+			},
+			Type: cty.String,
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		format           lang.AddressFormat
+		expectedLabels   []string
+		expectedSnippets []string
+	}{
+		{
+			"default format",
+			lang.AddressFormat{},
+			[]string{
+				`local.café`,
+				`local["$${x}\n\"ü\""]`,
+				`local["my key"]`,
+			},
+			[]string{
+				`local.café`,
+				`local["\$\${x\}\\n\\"ü\\""]`,
+				`local["my key"]`,
+			},
+		},
+		{
+			"escaped non-ASCII",
+			lang.AddressFormat{EscapeNonASCII: true},
+			[]string{
+				`local["$${x}\n\"\u00FC\""]`,
+				`local["caf\u00E9"]`,
+				`local["my key"]`,
+			},
+			[]string{
+				`local["\$\${x\}\\n\\"\\u00FC\\""]`,
+				`local["caf\\u00E9"]`,
+				`local["my key"]`,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"attr": {
+						IsOptional: true,
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfType: cty.String},
+						},
+					},
+				},
+			})
+			d.SetAddressFormat(tc.format)
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return targets
+			})
+
+			f, _ := hclsyntax.ParseConfig([]byte(`attr = 
+`), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 8, Byte: 7})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, 0)
+			snippets := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+				snippets = append(snippets, c.TextEdit.Snippet)
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected labels: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedSnippets, snippets); diff != "" {
+				t.Fatalf("unexpected snippets: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_HoverAtPos_addressFormat(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"attr": {
+				IsOptional: true,
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.String},
+				},
+			},
+		},
+	})
+	d.SetAddressFormat(lang.AddressFormat{EscapeNonASCII: true})
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return lang.ReferenceTargets{
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "local"},
+					lang.IndexStep{Key: cty.StringVal("ü")},
+				},
+				Type: cty.String,
+			},
+		}
+	})
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`attr = local["ü"]
+`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 1, Column: 9, Byte: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedContent := lang.Markdown("`local[\"\\u00FC\"]`\n_string_")
+	if diff := cmp.Diff(expectedContent, data.Content); diff != "" {
+		t.Fatalf("unexpected hover content: %s", diff)
+	}
+}
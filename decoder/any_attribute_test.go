@@ -0,0 +1,220 @@
+package decoder
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var anyAttributePatternSchema = &schema.BodySchema{
+	AnyAttribute: &schema.AttributeSchema{
+		IsOptional: true,
+		Expr:       schema.LiteralTypeOnly(cty.String),
+		NameConstraint: schema.KeyPattern{
+			Pattern: regexp.MustCompile(`^[a-z_]+$`),
+		},
+		Address: &schema.AttributeAddrSchema{
+			Steps: []schema.AddrStep{
+				schema.StaticStep{Name: "local"},
+				schema.AttrNameStep{},
+			},
+			AsExprType: true,
+		},
+	},
+}
+
+var anyAttributeEnumSchema = &schema.BodySchema{
+	AnyAttribute: &schema.AttributeSchema{
+		IsOptional: true,
+		Expr:       schema.LiteralTypeOnly(cty.String),
+		NameConstraint: schema.KeyEnum{
+			Names: []string{"second", "first", "third"},
+		},
+	},
+}
+
+func TestDecoder_ValidateFile_anyAttributeNameConstraint(t *testing.T) {
+	testCases := []struct {
+		name          string
+		bodySchema    *schema.BodySchema
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"name matching pattern",
+			anyAttributePatternSchema,
+			`foo_bar = "baz"
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"name not matching pattern",
+			anyAttributePatternSchema,
+			`Foo = "baz"
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid attribute name",
+					Detail:   `An attribute named "Foo" is not valid here, expected name matching "^[a-z_]+$"`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+					},
+				},
+			},
+		},
+		{
+			"name not in enum",
+			anyAttributeEnumSchema,
+			`fourth = "baz"
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid attribute name",
+					Detail:   `An attribute named "fourth" is not valid here, expected name one of "second", "first", "third"`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 7, Byte: 6},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(tc.bodySchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_anyAttributeNameConstraint(t *testing.T) {
+	testCases := []struct {
+		name           string
+		bodySchema     *schema.BodySchema
+		cfg            string
+		pos            hcl.Pos
+		expectedLabels []string
+	}{
+		{
+			"pattern without prefix",
+			anyAttributePatternSchema,
+			`
+`,
+			hcl.InitialPos,
+			[]string{"name"},
+		},
+		{
+			"enum without prefix",
+			anyAttributeEnumSchema,
+			`second = "foo"
+
+`,
+			hcl.Pos{Line: 2, Column: 1, Byte: 15},
+			[]string{"first", "third"},
+		},
+		{
+			"enum with prefix",
+			anyAttributeEnumSchema,
+			`t
+`,
+			hcl.Pos{Line: 1, Column: 2, Byte: 1},
+			[]string{"third"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(tc.bodySchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CollectReferenceTargets_anyAttributeNameConstraint(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(anyAttributePatternSchema)
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`foo = "bar"
+Invalid = "baz"
+`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := d.CollectReferenceTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTargets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "local"},
+				lang.AttrStep{Name: "foo"},
+			},
+			RangePtr: &hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			},
+			Type: cty.String,
+		},
+	}
+	if diff := cmp.Diff(expectedTargets, targets, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected targets: %s", diff)
+	}
+}
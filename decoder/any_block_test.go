@@ -0,0 +1,192 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var anyBlockSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"known": {
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"count": {
+						Expr: schema.LiteralTypeOnly(cty.Number),
+					},
+				},
+			},
+		},
+	},
+	AnyBlock: &schema.BlockSchema{
+		Body: &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"name": {
+					Expr: schema.LiteralTypeOnly(cty.String),
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_ValidateFile_anyBlock(t *testing.T) {
+	testCases := []struct {
+		name          string
+		bodySchema    *schema.BodySchema
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"arbitrary block without AnyBlock",
+			&schema.BodySchema{},
+			`custom {
+  name = "foo"
+}
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected block",
+					Detail:   `Blocks of type "custom" are not expected here`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 7, Byte: 6},
+					},
+				},
+			},
+		},
+		{
+			"arbitrary block with AnyBlock",
+			anyBlockSchema,
+			`custom {
+  name = "foo"
+}
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"unexpected attribute in arbitrary block",
+			anyBlockSchema,
+			`custom {
+  count = 1
+}
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected attribute",
+					Detail:   `An attribute named "count" is not expected here`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 2, Column: 3, Byte: 11},
+						End:      hcl.Pos{Line: 2, Column: 8, Byte: 16},
+					},
+				},
+			},
+		},
+		{
+			"declared block takes precedence",
+			anyBlockSchema,
+			`known {
+  count = 1
+}
+`,
+			hcl.Diagnostics{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(tc.bodySchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_anyBlock(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(anyBlockSchema)
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`custom {
+
+}
+`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 2, Column: 1, Byte: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := make([]string, 0)
+	for _, c := range candidates.List {
+		labels = append(labels, c.Label)
+	}
+	expectedLabels := []string{"name"}
+	if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_HoverAtPos_anyBlock(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(anyBlockSchema)
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`custom {
+  name = "foo"
+}
+`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 2, Column: 4, Byte: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData := &lang.HoverData{
+		Content: lang.Markdown("**name** _string_"),
+		Range: hcl.Range{
+			Filename: "test.tf",
+			Start:    hcl.Pos{Line: 2, Column: 3, Byte: 11},
+			End:      hcl.Pos{Line: 2, Column: 15, Byte: 23},
+		},
+	}
+	if diff := cmp.Diff(expectedData, data); diff != "" {
+		t.Fatalf("unexpected hover data: %s", diff)
+	}
+}
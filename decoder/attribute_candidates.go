@@ -11,7 +11,22 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
-func attributeSchemaToCandidate(name string, attr *schema.AttributeSchema, rng hcl.Range) lang.Candidate {
+func attributeSchemaToCandidate(name string, attr *schema.AttributeSchema, rng hcl.Range, withValue bool, vf valueFormat) lang.Candidate {
+	if !withValue {
+		return lang.Candidate{
+			Label:        name,
+			Detail:       detailForAttribute(attr),
+			Description:  attr.Description,
+			IsDeprecated: attr.IsDeprecated,
+			Kind:         lang.AttributeCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: name,
+				Snippet: name,
+				Range:   rng,
+			},
+		}
+	}
+
 	return lang.Candidate{
 		Label:        name,
 		Detail:       detailForAttribute(attr),
@@ -20,11 +35,23 @@ func attributeSchemaToCandidate(name string, attr *schema.AttributeSchema, rng h
 		Kind:         lang.AttributeCandidateKind,
 		TextEdit: lang.TextEdit{
 			NewText: name,
-			Snippet: snippetForAttribute(name, attr),
+			Snippet: snippetForAttribute(name, attr, vf),
 			Range:   rng,
 		},
-		TriggerSuggest: triggerSuggestForExprConstraints(attr.Expr),
+		Command: triggerSuggestCommand(triggerSuggestForExprConstraints(attr.Expr)),
+	}
+}
+
+// attributeCompletionWithValue returns true if completing name
+// of the given attribute should also insert " = " and the value
+func (d *Decoder) attributeCompletionWithValue(attr *schema.AttributeSchema) bool {
+	switch attr.NameCompletion {
+	case schema.NameCompletionWithValue:
+		return true
+	case schema.NameCompletionNameOnly:
+		return false
 	}
+	return d.clientCaps.AttributeValueCompletion
 }
 
 func detailForAttribute(attr *schema.AttributeSchema) string {
@@ -40,6 +67,10 @@ func detailForAttribute(attr *schema.AttributeSchema) string {
 		details = append(details, "sensitive")
 	}
 
+	if attr.IsWriteOnly {
+		details = append(details, "write-only")
+	}
+
 	friendlyName := attr.Expr.FriendlyName()
 	if friendlyName != "" {
 		details = append(details, friendlyName)
@@ -48,8 +79,11 @@ func detailForAttribute(attr *schema.AttributeSchema) string {
 	return strings.Join(details[:], ", ")
 }
 
-func snippetForAttribute(name string, attr *schema.AttributeSchema) string {
-	return fmt.Sprintf("%s = %s", name, snippetForExprContraints(1, attr.Expr))
+func snippetForAttribute(name string, attr *schema.AttributeSchema, vf valueFormat) string {
+	if _, snippet, ok := defaultValueText(attr, vf); ok {
+		return fmt.Sprintf("%s = %s", name, snippet)
+	}
+	return fmt.Sprintf("%s = %s", name, snippetForExprContraints(1, attr.Expr, vf))
 }
 
 func sortedObjectAttrNames(obj cty.Type) []string {
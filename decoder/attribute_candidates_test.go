@@ -6,6 +6,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -122,10 +124,184 @@ func TestSnippetForAttribute(t *testing.T) {
 
 	for i, tc := range testCases {
 		t.Run(fmt.Sprintf("%d-%s", i, tc.testName), func(t *testing.T) {
-			snippet := snippetForAttribute(tc.attrName, tc.attrSchema)
+			snippet := snippetForAttribute(tc.attrName, tc.attrSchema, valueFormat{})
 			if diff := cmp.Diff(tc.expectedSnippet, snippet); diff != "" {
 				t.Fatalf("unexpected snippet: %s", diff)
 			}
 		})
 	}
 }
+
+func TestDecoder_CandidatesAtPos_nameCompletion(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"flag": {
+				IsOptional: true,
+				Expr:       schema.LiteralTypeOnly(cty.Bool),
+			},
+			"flag_name_only": {
+				IsOptional:     true,
+				Expr:           schema.LiteralTypeOnly(cty.Bool),
+				NameCompletion: schema.NameCompletionNameOnly,
+			},
+			"flag_with_value": {
+				IsOptional:     true,
+				Expr:           schema.LiteralTypeOnly(cty.Bool),
+				NameCompletion: schema.NameCompletionWithValue,
+			},
+		},
+	}
+
+	type insertion struct {
+		Snippet        string
+		Range          hcl.Range
+		TriggerSuggest bool
+	}
+
+	testCases := []struct {
+		testName           string
+		cfg                string
+		caps               ClientCapabilities
+		pos                hcl.Pos
+		expectedInsertions map[string]insertion
+	}{
+		{
+			"empty body with value completion",
+			"\n",
+			FullClientCapabilities(),
+			hcl.InitialPos,
+			map[string]insertion{
+				"flag": {
+					Snippet:        "flag = ${1:false}",
+					Range:          hcl.Range{Filename: "test.tf", Start: hcl.InitialPos, End: hcl.InitialPos},
+					TriggerSuggest: true,
+				},
+				"flag_name_only": {
+					Snippet: "flag_name_only",
+					Range:   hcl.Range{Filename: "test.tf", Start: hcl.InitialPos, End: hcl.InitialPos},
+				},
+				"flag_with_value": {
+					Snippet:        "flag_with_value = ${1:false}",
+					Range:          hcl.Range{Filename: "test.tf", Start: hcl.InitialPos, End: hcl.InitialPos},
+					TriggerSuggest: true,
+				},
+			},
+		},
+		{
+			"empty body without value completion",
+			"\n",
+			ClientCapabilities{SnippetSupport: true},
+			hcl.InitialPos,
+			map[string]insertion{
+				"flag": {
+					Snippet: "flag",
+					Range:   hcl.Range{Filename: "test.tf", Start: hcl.InitialPos, End: hcl.InitialPos},
+				},
+				"flag_name_only": {
+					Snippet: "flag_name_only",
+					Range:   hcl.Range{Filename: "test.tf", Start: hcl.InitialPos, End: hcl.InitialPos},
+				},
+				"flag_with_value": {
+					Snippet:        "flag_with_value = ${1:false}",
+					Range:          hcl.Range{Filename: "test.tf", Start: hcl.InitialPos, End: hcl.InitialPos},
+					TriggerSuggest: true,
+				},
+			},
+		},
+		{
+			"end of existing attribute name",
+			"fla = true\n",
+			FullClientCapabilities(),
+			hcl.Pos{Line: 1, Column: 4, Byte: 3},
+			map[string]insertion{
+				"flag": {
+					Snippet: "flag",
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.InitialPos,
+						End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+					},
+				},
+				"flag_name_only": {
+					Snippet: "flag_name_only",
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.InitialPos,
+						End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+					},
+				},
+				"flag_with_value": {
+					Snippet: "flag_with_value",
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.InitialPos,
+						End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+					},
+				},
+			},
+		},
+		{
+			"name followed by equals without value",
+			"fla =\n",
+			FullClientCapabilities(),
+			hcl.Pos{Line: 1, Column: 3, Byte: 2},
+			map[string]insertion{
+				"flag": {
+					Snippet: "flag",
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.InitialPos,
+						End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+					},
+				},
+				"flag_name_only": {
+					Snippet: "flag_name_only",
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.InitialPos,
+						End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+					},
+				},
+				"flag_with_value": {
+					Snippet: "flag_with_value",
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.InitialPos,
+						End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.testName), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			d.SetClientCapabilities(tc.caps)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			insertions := make(map[string]insertion, 0)
+			for _, c := range candidates.List {
+				insertions[c.Label] = insertion{
+					Snippet:        c.TextEdit.Snippet,
+					Range:          c.TextEdit.Range,
+					TriggerSuggest: c.Command != nil,
+				}
+			}
+			if diff := cmp.Diff(tc.expectedInsertions, insertions); diff != "" {
+				t.Fatalf("unexpected insertions: %s", diff)
+			}
+		})
+	}
+}
@@ -2,14 +2,19 @@ package decoder
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl-lang/lang"
 	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
 )
 
-func blockSchemaToCandidate(blockType string, block *schema.BlockSchema, rng hcl.Range) lang.Candidate {
+func blockSchemaToCandidate(blockType string, block *schema.BlockSchema, rng hcl.Range, maxChoices uint, vf valueFormat) lang.Candidate {
+	sg := &snippetGenerator{placeholder: 1, vf: vf}
+	labelChoices := labelChoicesFromDependentBody(block, maxChoices)
+
 	triggerSuggest := false
 	if len(block.Labels) > 0 {
 		// We make some naive assumptions here for simplicity
@@ -20,7 +25,11 @@ func blockSchemaToCandidate(blockType string, block *schema.BlockSchema, rng hcl
 		// The implementation can certainly be more sophisticated
 		// but it would likely involve changes in snippet placeholder
 		// numbering and full understanding of UX implications.
-		triggerSuggest = block.Labels[0].IsDepKey
+		//
+		// Labels with enumerated choices are completed by the client
+		// from the snippet itself, so no further suggestion is needed.
+		_, hasChoices := labelChoices[0]
+		triggerSuggest = block.Labels[0].IsDepKey && !hasChoices
 	}
 
 	return lang.Candidate{
@@ -31,10 +40,10 @@ func blockSchemaToCandidate(blockType string, block *schema.BlockSchema, rng hcl
 		Kind:         lang.BlockCandidateKind,
 		TextEdit: lang.TextEdit{
 			NewText: blockType,
-			Snippet: snippetForBlock(blockType, block),
+			Snippet: sg.forBlock(blockType, block, labelChoices, 0),
 			Range:   rng,
 		},
-		TriggerSuggest: triggerSuggest,
+		Command: triggerSuggestCommand(triggerSuggest),
 	}
 }
 
@@ -54,18 +63,141 @@ func detailForBlock(block *schema.BlockSchema) string {
 	return strings.TrimSpace(detail)
 }
 
-func snippetForBlock(blockType string, block *schema.BlockSchema) string {
-	labels := ""
-	placeholder := 1
+// labelChoicesFromDependentBody returns label values enumerated
+// by keys of the DependentBody, indexed by position of the label.
+//
+// Labels with more than maxChoices values are left out, as these
+// are better served by label completion, which filters by prefix.
+func labelChoicesFromDependentBody(block *schema.BlockSchema, maxChoices uint) map[int][]string {
+	choices := make(map[int][]string, 0)
+	dependentBodies := dependentBodiesForCompletion(block)
+
+	for idx, label := range block.Labels {
+		if !label.IsDepKey || !label.Completable {
+			continue
+		}
+
+		values := make([]string, 0)
+		seen := make(map[string]bool, 0)
+		for _, schemaKey := range sortedSchemaKeys(dependentBodies) {
+			depKeys, err := schemaKey.DependencyKeys()
+			if err != nil {
+				// key undecodable
+				continue
+			}
+			for _, dl := range depKeys.Labels {
+				if dl.Index != idx || seen[dl.Value] {
+					continue
+				}
+				if label.ValidateValue(dl.Value) != nil {
+					continue
+				}
+				values = append(values, dl.Value)
+				seen[dl.Value] = true
+			}
+		}
+
+		if len(values) == 0 || uint(len(values)) > maxChoices {
+			continue
+		}
+		sort.Strings(values)
+		choices[idx] = values
+	}
+
+	return choices
+}
 
-	for _, l := range block.Labels {
-		if l.IsDepKey {
-			labels += fmt.Sprintf(` "${%d}"`, placeholder)
+func (sg *snippetGenerator) forBlock(blockType string, block *schema.BlockSchema, labelChoices map[int][]string, nestingLvl int) string {
+	labels := ""
+	for idx, l := range block.Labels {
+		if values, ok := labelChoices[idx]; ok {
+			labels += fmt.Sprintf(` "${%d|%s|}"`, sg.placeholder, snippetChoices(values))
+		} else if l.IsDepKey {
+			labels += fmt.Sprintf(` "${%d}"`, sg.placeholder)
 		} else {
-			labels += fmt.Sprintf(` "${%d:%s}"`, placeholder, l.Name)
+			labels += fmt.Sprintf(` "${%d:%s}"`, sg.placeholder, l.Name)
+		}
+		sg.placeholder++
+	}
+
+	nesting := strings.Repeat("  ", nestingLvl+1)
+	endBraceNesting := strings.Repeat("  ", nestingLvl)
+
+	body := sg.forRequiredBody(block.Body, nestingLvl+1)
+	if nestingLvl == 0 {
+		// leave the cursor inside the top-level block
+		body += fmt.Sprintf("%s${%d}\n", nesting, sg.placeholder)
+		sg.placeholder++
+	} else if body == "" {
+		body = fmt.Sprintf("%s${%d}\n", nesting, sg.placeholder)
+		sg.placeholder++
+	}
+
+	return fmt.Sprintf("%s%s {\n%s%s}", blockType, labels, body, endBraceNesting)
+}
+
+// forRequiredBody returns a skeleton of the body, consisting of
+// required attributes and blocks, one per line
+func (sg *snippetGenerator) forRequiredBody(body *schema.BodySchema, nestingLvl int) string {
+	if body == nil {
+		return ""
+	}
+
+	nesting := strings.Repeat("  ", nestingLvl)
+	skeleton := ""
+
+	for _, name := range sortedAttributeNames(body.Attributes) {
+		attr := body.Attributes[name]
+		if !attr.IsRequired {
+			continue
+		}
+		skeleton += fmt.Sprintf("%s%s = %s\n", nesting, name,
+			sg.forRequiredAttribute(attr.Expr, nestingLvl))
+	}
+
+	for _, bType := range sortedBlockTypes(body.Blocks) {
+		block := body.Blocks[bType]
+		if block.MinItems == 0 {
+			continue
 		}
-		placeholder++
+		skeleton += fmt.Sprintf("%s%s\n", nesting,
+			sg.forBlock(bType, block, map[int][]string{}, nestingLvl))
 	}
 
-	return fmt.Sprintf("%s%s {\n  ${%d}\n}", blockType, labels, placeholder)
+	return skeleton
+}
+
+func (sg *snippetGenerator) forRequiredAttribute(ec schema.ExprConstraints, nestingLvl int) string {
+	if c, ok := firstForSnippet(ec); ok {
+		if lt, ok := c.(schema.LiteralTypeExpr); ok && lt.Type == cty.String && lt.Heredoc != schema.HeredocOptional {
+			snippet := snippetForHeredoc(sg.placeholder, lt.Heredoc, nestingLvl)
+			sg.placeholder++
+			return snippet
+		}
+		if lt, ok := c.(schema.LiteralTypeExpr); ok && lt.Type != cty.NilType {
+			if snippet := sg.forLiteralType(lt.Type, nestingLvl); snippet != "" {
+				return snippet
+			}
+		}
+	}
+
+	sg.placeholder++
+	return fmt.Sprintf("${%d}", sg.placeholder-1)
+}
+
+// snippetChoices formats values as choices of a snippet placeholder,
+// escaping characters which have special meaning in the choice syntax
+func snippetChoices(values []string) string {
+	escaper := strings.NewReplacer(
+		`\`, `\\`,
+		`$`, `\$`,
+		`}`, `\}`,
+		`,`, `\,`,
+		`|`, `\|`,
+	)
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = escaper.Replace(v)
+	}
+	return strings.Join(escaped, ",")
 }
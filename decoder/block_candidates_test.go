@@ -0,0 +1,185 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestBlockSchemaToCandidate_snippet(t *testing.T) {
+	dependentBody := map[schema.SchemaKey]*schema.BodySchema{
+		schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: "second"},
+			},
+		}): {},
+		schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: "first"},
+			},
+		}): {},
+		schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: "first"},
+			},
+			Attributes: []schema.AttributeDependent{
+				{
+					Name: "provider",
+					Expr: schema.ExpressionValue{
+						Static: cty.StringVal("foo"),
+					},
+				},
+			},
+		}): {},
+	}
+
+	testCases := []struct {
+		testName        string
+		blockSchema     *schema.BlockSchema
+		maxChoices      uint
+		expectedSnippet string
+		expectedTrigger bool
+	}{
+		{
+			"no labels",
+			&schema.BlockSchema{},
+			100,
+			"block {\n  ${1}\n}",
+			false,
+		},
+		{
+			"dependency key without choices",
+			&schema.BlockSchema{
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true, Completable: true},
+					{Name: "name"},
+				},
+			},
+			100,
+			"block \"${1}\" \"${2:name}\" {\n  ${3}\n}",
+			true,
+		},
+		{
+			"dependency key with choices",
+			&schema.BlockSchema{
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true, Completable: true},
+					{Name: "name"},
+				},
+				DependentBody: dependentBody,
+			},
+			100,
+			"block \"${1|first,second|}\" \"${2:name}\" {\n  ${3}\n}",
+			false,
+		},
+		{
+			"choices over limit",
+			&schema.BlockSchema{
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true, Completable: true},
+				},
+				DependentBody: dependentBody,
+			},
+			1,
+			"block \"${1}\" {\n  ${2}\n}",
+			true,
+		},
+		{
+			"choices with special characters",
+			&schema.BlockSchema{
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true, Completable: true},
+				},
+				DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{
+							{Index: 0, Value: "a,b|c"},
+						},
+					}): {},
+				},
+			},
+			100,
+			"block \"${1|a\\,b\\|c|}\" {\n  ${2}\n}",
+			false,
+		},
+		{
+			"required body skeleton",
+			&schema.BlockSchema{
+				Labels: []*schema.LabelSchema{
+					{Name: "name"},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"optional": {
+							IsOptional: true,
+							Expr:       schema.LiteralTypeOnly(cty.String),
+						},
+						"source": {
+							IsRequired: true,
+							Expr:       schema.LiteralTypeOnly(cty.String),
+						},
+						"tags": {
+							IsRequired: true,
+							Expr:       schema.LiteralTypeOnly(cty.Map(cty.String)),
+						},
+						"ref": {
+							IsRequired: true,
+							Expr: schema.ExprConstraints{
+								schema.TraversalExpr{OfType: cty.String},
+							},
+						},
+					},
+					Blocks: map[string]*schema.BlockSchema{
+						"optional_block": {},
+						"required_block": {
+							MinItems: 1,
+							Body: &schema.BodySchema{
+								Attributes: map[string]*schema.AttributeSchema{
+									"size": {
+										IsRequired: true,
+										Expr:       schema.LiteralTypeOnly(cty.Number),
+									},
+								},
+							},
+						},
+						"empty_block": {
+							MinItems: 1,
+						},
+					},
+				},
+			},
+			100,
+			`block "${1:name}" {
+  ref = ${2}
+  source = "${3:value}"
+  tags = {
+    "${4:key}" = "${5:value}"
+  }
+  empty_block {
+    ${6}
+  }
+  required_block {
+    size = ${7:1}
+  }
+  ${8}
+}`,
+			false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.testName), func(t *testing.T) {
+			candidate := blockSchemaToCandidate("block", tc.blockSchema, hcl.Range{}, tc.maxChoices, valueFormat{})
+			if diff := cmp.Diff(tc.expectedSnippet, candidate.TextEdit.Snippet); diff != "" {
+				t.Fatalf("unexpected snippet: %s", diff)
+			}
+			if hasTrigger := candidate.Command != nil; hasTrigger != tc.expectedTrigger {
+				t.Fatalf("expected trigger suggest: %t, given: %t", tc.expectedTrigger, hasTrigger)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package decoder
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// blockConversionCandidate returns a candidate converting
+// the attribute (e.g. foo =) with no value yet to a block
+// of the same type, i.e. removing the "=", where the schema
+// declares such block.
+func (d *Decoder) blockConversionCandidate(attr *hclsyntax.Attribute, bodySchema *schema.BodySchema, pos hcl.Pos) (lang.Candidate, bool) {
+	bSchema, ok := blockSchemaForType(bodySchema, attr.Name)
+	if !ok || !d.isAttrValueEmpty(attr) {
+		return lang.Candidate{}, false
+	}
+
+	// LSP does not support multi-line edits of candidates
+	if attr.NameRange.Start.Line != pos.Line {
+		return lang.Candidate{}, false
+	}
+	rng := hcl.Range{
+		Filename: attr.NameRange.Filename,
+		Start:    attr.NameRange.Start,
+		End:      pos,
+	}
+	typed, err := d.bytesFromRange(rng)
+	if err != nil {
+		return lang.Candidate{}, false
+	}
+
+	candidate := blockSchemaToCandidate(attr.Name, bSchema, rng, d.maxCandidates, d.valueFormat())
+	candidate.Detail = "Convert to block"
+	// the edit replaces the typed "name =", so that is what
+	// the client should filter by, rather than the label
+	candidate.FilterText = string(typed)
+
+	return candidate, true
+}
+
+// isAttrValueEmpty returns true if the value of the attribute
+// is missing, i.e. only whitespace follows the "="
+func (d *Decoder) isAttrValueEmpty(attr *hclsyntax.Attribute) bool {
+	rng := hcl.Range{
+		Filename: attr.EqualsRange.Filename,
+		Start:    attr.EqualsRange.End,
+		End:      attr.Expr.Range().End,
+	}
+	src, err := d.bytesFromRange(rng)
+	if err != nil {
+		return false
+	}
+	return len(bytes.TrimSpace(src)) == 0
+}
+
+// attributeConversionCandidate returns a candidate converting
+// the block (e.g. foo {}) with an empty body and no labels
+// to an attribute of the same name, where the schema declares
+// such attribute.
+func (d *Decoder) attributeConversionCandidate(block *hclsyntax.Block, bodySchema *schema.BodySchema, pos hcl.Pos) (lang.Candidate, bool) {
+	aSchema, ok := attributeSchemaForName(bodySchema, block.Type)
+	if !ok || len(block.Labels) > 0 || block.Body == nil {
+		return lang.Candidate{}, false
+	}
+	if len(block.Body.Attributes) > 0 || len(block.Body.Blocks) > 0 {
+		return lang.Candidate{}, false
+	}
+
+	blockRng := block.Range()
+	filename := blockRng.Filename
+
+	// LSP does not support multi-line edits of candidates,
+	// so the attribute is inserted at the position and the rest
+	// of the block is removed via additional edits
+	return lang.Candidate{
+		Label:        block.Type,
+		Detail:       "Convert to attribute",
+		Description:  aSchema.Description,
+		IsDeprecated: aSchema.IsDeprecated,
+		Kind:         lang.AttributeCandidateKind,
+		TextEdit: lang.TextEdit{
+			NewText: block.Type,
+			Snippet: snippetForAttribute(block.Type, aSchema, d.valueFormat()),
+			Range: hcl.Range{
+				Filename: filename,
+				Start:    pos,
+				End:      pos,
+			},
+		},
+		AdditionalTextEdits: []lang.TextEdit{
+			{
+				Range: hcl.Range{
+					Filename: filename,
+					Start:    blockRng.Start,
+					End:      pos,
+				},
+			},
+			{
+				Range: hcl.Range{
+					Filename: filename,
+					Start:    pos,
+					End:      blockRng.End,
+				},
+			},
+		},
+		Command: triggerSuggestCommand(triggerSuggestForExprConstraints(aSchema.Expr)),
+	}, true
+}
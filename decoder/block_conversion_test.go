@@ -0,0 +1,172 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var blockConversionSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"name": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+		},
+		"rule": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.Bool),
+		},
+	},
+	Blocks: map[string]*schema.BlockSchema{
+		"setting": {
+			Description: lang.PlainText("Setting of the thing"),
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"value": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.String),
+					},
+				},
+			},
+		},
+		"rule": {
+			Body: &schema.BodySchema{},
+		},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_blockConversion(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cfg                string
+		posAfter           string
+		expectedCandidates string
+	}{
+		{
+			"block as attribute",
+			"setting = \n",
+			"setting = ",
+			`
+complete: true
+candidates: 1
+---
+label: "setting"
+kind: BlockCandidateKind
+detail: "Convert to block"
+description (PlainTextKind): "Setting of the thing"
+filter text: "setting = "
+edit: test.tf:1,1-1,11 "setting" (snippet "setting {\n  ${1}\n}")
+`,
+		},
+		{
+			"block as attribute with value",
+			"setting = \"foo\"\n",
+			"setting = \"foo\"",
+			`
+complete: true
+candidates: 0
+`,
+		},
+		{
+			"attribute",
+			"name = \n",
+			"name = ",
+			`
+complete: true
+candidates: 0
+`,
+		},
+		{
+			"both allowed as attribute",
+			"rule = \n",
+			"rule = ",
+			`
+complete: true
+candidates: 3
+---
+label: "false"
+kind: BoolCandidateKind
+detail: "bool"
+edit: test.tf:1,8-1,8 "false" (snippet "${1:false}")
+---
+label: "rule"
+kind: BlockCandidateKind
+detail: "Convert to block"
+filter text: "rule = "
+edit: test.tf:1,1-1,8 "rule" (snippet "rule {\n  ${1}\n}")
+---
+label: "true"
+kind: BoolCandidateKind
+detail: "bool"
+edit: test.tf:1,8-1,8 "true" (snippet "${1:true}")
+`,
+		},
+		{
+			"attribute as block",
+			"name {\n  \n}\n",
+			"{\n  ",
+			`
+complete: true
+candidates: 1
+---
+label: "name"
+kind: AttributeCandidateKind
+detail: "Convert to attribute"
+edit: test.tf:2,3-2,3 "name" (snippet "name = \"${1:value}\"")
+additional edit: test.tf:1,1-2,3 ""
+additional edit: test.tf:2,3-3,2 ""
+`,
+		},
+		{
+			"both allowed as block",
+			"rule {\n  \n}\n",
+			"{\n  ",
+			`
+complete: true
+candidates: 1
+---
+label: "rule"
+kind: AttributeCandidateKind
+detail: "Convert to attribute"
+edit: test.tf:2,3-2,3 "rule" (snippet "rule = ${1:false}")
+additional edit: test.tf:1,1-2,3 ""
+additional edit: test.tf:2,3-3,2 ""
+command: editor.action.triggerSuggest
+`,
+		},
+		{
+			"non-empty block",
+			"setting {\n  value = \"foo\"\n  \n}\n",
+			"\"foo\"\n  ",
+			`
+complete: true
+candidates: 0
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(blockConversionSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package decoder
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// blockHeader represents a (possibly incomplete) header of a block
+// in the native syntax, as recognized from tokens, such that it can be
+// completed even if the parser didn't recognize the block yet,
+// e.g. because labels are incomplete or the opening brace is missing
+type blockHeader struct {
+	blockType string
+	typeRange hcl.Range
+	labels    []headerLabel
+
+	openBraceRange *hcl.Range
+}
+
+type headerLabel struct {
+	// valueRange represents range of the value, excluding quotes
+	valueRange hcl.Range
+	// rng represents range of the whole label, including quotes
+	rng hcl.Range
+
+	isQuoted     bool
+	isTerminated bool
+}
+
+// headerLabelPos represents position of a label
+// to be completed within the block header
+type headerLabelPos struct {
+	index     int
+	prefixRng hcl.Range
+	editRng   hcl.Range
+
+	// needsQuotes indicates that the value is inserted
+	// as a new label and must be quoted
+	needsQuotes bool
+	// needsClosingQuote indicates that the value is inserted
+	// into a quoted label which is missing the closing quote
+	needsClosingQuote bool
+}
+
+func (d *Decoder) blockHeaderAtPos(filename string, pos hcl.Pos) (*blockHeader, bool) {
+	// lexer errors are tolerated, as the header
+	// may contain an unterminated label
+	tokens, _, err := d.lexSegmentAtPos(filename, pos)
+	if err != nil {
+		return nil, false
+	}
+	return blockHeaderAtPos(tokens, pos)
+}
+
+// blockHeaderAtPos parses block header on the line of the given position,
+// up to the opening brace, end of line or an unterminated label.
+func blockHeaderAtPos(tokens hclsyntax.Tokens, pos hcl.Pos) (*blockHeader, bool) {
+	lineStart := -1
+	for i, t := range tokens {
+		if t.Range.Start.Byte > pos.Byte {
+			break
+		}
+		if i == 0 || (tokens[i-1].Type == hclsyntax.TokenNewline && tokens[i-1].Range.End.Byte <= pos.Byte) {
+			lineStart = i
+		}
+	}
+	if lineStart < 0 {
+		return nil, false
+	}
+
+	typeToken := tokens[lineStart]
+	if typeToken.Type != hclsyntax.TokenIdent || pos.Byte <= typeToken.Range.End.Byte {
+		return nil, false
+	}
+
+	header := &blockHeader{
+		blockType: string(typeToken.Bytes),
+		typeRange: typeToken.Range,
+	}
+
+	for i := lineStart + 1; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t.Type {
+		case hclsyntax.TokenIdent:
+			header.labels = append(header.labels, headerLabel{
+				valueRange:   t.Range,
+				rng:          t.Range,
+				isTerminated: true,
+			})
+		case hclsyntax.TokenOQuote:
+			label := headerLabel{
+				valueRange: hcl.Range{
+					Filename: t.Range.Filename,
+					Start:    t.Range.End,
+					End:      t.Range.End,
+				},
+				rng:      t.Range,
+				isQuoted: true,
+			}
+			if i+1 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenQuotedLit {
+				i++
+				label.valueRange = tokens[i].Range
+				label.rng.End = tokens[i].Range.End
+			}
+			if i+1 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenCQuote {
+				i++
+				label.rng.End = tokens[i].Range.End
+				label.isTerminated = true
+			}
+			header.labels = append(header.labels, label)
+			if !label.isTerminated {
+				return header, true
+			}
+		case hclsyntax.TokenOBrace:
+			header.openBraceRange = t.Range.Ptr()
+			return header, true
+		case hclsyntax.TokenNewline, hclsyntax.TokenEOF:
+			return header, true
+		default:
+			// e.g. an attribute or an expression
+			if t.Range.Start.Byte < pos.Byte {
+				return nil, false
+			}
+			return header, true
+		}
+	}
+
+	return header, true
+}
+
+// labelPos returns position of the label to be completed
+// at the given position within the header
+func (bh *blockHeader) labelPos(pos hcl.Pos) (headerLabelPos, bool) {
+	if bh.openBraceRange != nil && pos.Byte > bh.openBraceRange.Start.Byte {
+		return headerLabelPos{}, false
+	}
+
+	for i, label := range bh.labels {
+		if pos.Byte < label.valueRange.Start.Byte || pos.Byte > label.valueRange.End.Byte {
+			continue
+		}
+
+		prefixRng := label.valueRange
+		prefixRng.End = pos
+		return headerLabelPos{
+			index:             i,
+			prefixRng:         prefixRng,
+			editRng:           label.valueRange,
+			needsQuotes:       !label.isQuoted,
+			needsClosingQuote: label.isQuoted && !label.isTerminated,
+		}, true
+	}
+
+	index := 0
+	for _, label := range bh.labels {
+		if label.rng.End.Byte <= pos.Byte {
+			index++
+		}
+	}
+	if index < len(bh.labels) && !bh.labels[index].isTerminated {
+		// past the unterminated label, i.e. on another line
+		return headerLabelPos{}, false
+	}
+
+	rng := hcl.Range{
+		Filename: bh.typeRange.Filename,
+		Start:    pos,
+		End:      pos,
+	}
+	return headerLabelPos{
+		index:       index,
+		prefixRng:   rng,
+		editRng:     rng,
+		needsQuotes: true,
+	}, true
+}
+
+// blockHeaderCandidates returns candidates for the label
+// at the given position within header of the block.
+// No candidates are returned if the schema expects no more labels.
+func (d *Decoder) blockHeaderCandidates(bSchema *schema.BlockSchema, lp headerLabelPos) (lang.Candidates, error) {
+	if lp.index >= len(bSchema.Labels) {
+		return lang.ZeroCandidates(), nil
+	}
+
+	candidates, err := d.labelCandidates(lp.index, bSchema.Labels[lp.index], dependentBodiesForCompletion(bSchema), lp.prefixRng, lp.editRng)
+	if err != nil {
+		return candidates, err
+	}
+
+	for i, candidate := range candidates.List {
+		text := candidate.TextEdit.NewText
+		if lp.needsQuotes {
+			text = d.addrFormat.Quote(text)
+		} else if lp.needsClosingQuote {
+			text = strings.TrimPrefix(d.addrFormat.Quote(text), `"`)
+		} else {
+			continue
+		}
+		candidates.List[i].TextEdit.NewText = text
+		candidates.List[i].TextEdit.Snippet = escapeSnippetText(text)
+	}
+
+	return candidates, nil
+}
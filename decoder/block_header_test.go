@@ -0,0 +1,195 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestDecoder_CandidatesAtPos_blockHeader(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true, Completable: true},
+					{Name: "name"},
+				},
+				DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{
+							{Index: 0, Value: "aws_instance"},
+						},
+					}): {},
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{
+							{Index: 0, Value: "aws_vpc"},
+						},
+					}): {},
+				},
+			},
+			"backend": {
+				Labels: []*schema.LabelSchema{
+					{
+						Name:            "type",
+						ValueConstraint: schema.KeyEnum{Names: []string{"remote", "local"}},
+					},
+				},
+			},
+		},
+	}
+
+	type insertion struct {
+		Label   string
+		NewText string
+		Range   hcl.Range
+	}
+
+	testCases := []struct {
+		testName           string
+		cfg                string
+		pos                hcl.Pos
+		expectedInsertions []insertion
+	}{
+		{
+			"missing label before brace",
+			"resource  {\n}\n",
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			[]insertion{
+				{
+					Label:   "aws_instance",
+					NewText: `"aws_instance"`,
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+					},
+				},
+				{
+					Label:   "aws_vpc",
+					NewText: `"aws_vpc"`,
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+					},
+				},
+			},
+		},
+		{
+			"missing brace",
+			"resource \n",
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			[]insertion{
+				{
+					Label:   "aws_instance",
+					NewText: `"aws_instance"`,
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+					},
+				},
+				{
+					Label:   "aws_vpc",
+					NewText: `"aws_vpc"`,
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+					},
+				},
+			},
+		},
+		{
+			"unterminated label",
+			"resource \"aws_in\n",
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			[]insertion{
+				{
+					Label:   "aws_instance",
+					NewText: `aws_instance"`,
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 11, Byte: 10},
+						End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+					},
+				},
+			},
+		},
+		{
+			"label without completable values",
+			"resource \"aws_vpc\" \n",
+			hcl.Pos{Line: 1, Column: 20, Byte: 19},
+			[]insertion{},
+		},
+		{
+			"enumerated label values",
+			"backend  {\n}\n",
+			hcl.Pos{Line: 1, Column: 9, Byte: 8},
+			[]insertion{
+				{
+					Label:   "local",
+					NewText: `"local"`,
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+						End:      hcl.Pos{Line: 1, Column: 9, Byte: 8},
+					},
+				},
+				{
+					Label:   "remote",
+					NewText: `"remote"`,
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+						End:      hcl.Pos{Line: 1, Column: 9, Byte: 8},
+					},
+				},
+			},
+		},
+		{
+			"no more labels expected",
+			"backend \"local\" \n",
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			[]insertion{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.testName), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			insertions := make([]insertion, 0)
+			for _, c := range candidates.List {
+				if c.Kind != lang.LabelCandidateKind {
+					t.Fatalf("unexpected candidate kind %s of %q", c.Kind, c.Label)
+				}
+				insertions = append(insertions, insertion{
+					Label:   c.Label,
+					NewText: c.TextEdit.NewText,
+					Range:   c.TextEdit.Range,
+				})
+			}
+			if diff := cmp.Diff(tc.expectedInsertions, insertions); diff != "" {
+				t.Fatalf("unexpected insertions: %s", diff)
+			}
+		})
+	}
+}
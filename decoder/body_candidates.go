@@ -10,12 +10,27 @@ import (
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
-func (d *Decoder) bodySchemaCandidates(body *hclsyntax.Body, schema *schema.BodySchema, prefixRng, editRng hcl.Range) lang.Candidates {
-	prefix, _ := d.bytesFromRange(prefixRng)
+// bodySchemaCandidates returns candidates for attributes and blocks
+// declarable in the body. hasEquals indicates that " = " already
+// follows the edit range, in which case attribute candidates
+// only insert the name. Candidates are ranked by hints, if any.
+func (d *Decoder) bodySchemaCandidates(body *hclsyntax.Body, schema *schema.BodySchema, prefixRng, editRng hcl.Range, hasEquals bool, hints *schema.CompletionHints) lang.Candidates {
+	prefix := d.prefixFromRange(prefixRng)
 
 	candidates := lang.NewCandidates()
 	count := 0
 
+	if hints != nil && len(prefix) == 0 && isBodyEmpty(body) {
+		for _, tpl := range hints.Templates {
+			if uint(count) >= d.maxCandidates {
+				return candidates
+			}
+
+			candidates.List = append(candidates.List, fileTemplateCandidate(tpl, editRng))
+			count++
+		}
+	}
+
 	if len(schema.Attributes) > 0 {
 		attrNames := sortedAttributeNames(schema.Attributes)
 		for _, name := range attrNames {
@@ -31,19 +46,44 @@ func (d *Decoder) bodySchemaCandidates(body *hclsyntax.Body, schema *schema.Body
 				return candidates
 			}
 
-			candidates.List = append(candidates.List, attributeSchemaToCandidate(name, attr, editRng))
+			candidate := attributeSchemaToCandidate(name, attr, editRng, !hasEquals && d.attributeCompletionWithValue(attr), d.valueFormat())
+			candidate.Score = hints.Weight(name)
+			candidates.List = append(candidates.List, candidate)
 			count++
 		}
-	} else if attr := schema.AnyAttribute; attr != nil && len(prefix) == 0 {
-		if uint(count) >= d.maxCandidates {
-			return candidates
-		}
+	} else if attr := schema.AnyAttribute; attr != nil {
+		if names, ok := enumeratedAttributeNames(attr); ok {
+			for _, name := range names {
+				if !isAttributeDeclarable(body, name, attr) {
+					continue
+				}
+				if len(prefix) > 0 && !strings.HasPrefix(name, string(prefix)) {
+					continue
+				}
+				if uint(count) >= d.maxCandidates {
+					return candidates
+				}
+
+				candidates.List = append(candidates.List, attributeSchemaToCandidate(name, attr, editRng, !hasEquals && d.attributeCompletionWithValue(attr), d.valueFormat()))
+				count++
+			}
+		} else if len(prefix) == 0 {
+			if uint(count) >= d.maxCandidates {
+				return candidates
+			}
 
-		candidates.List = append(candidates.List, attributeSchemaToCandidate("name", attr, editRng))
-		count++
+			candidates.List = append(candidates.List, attributeSchemaToCandidate("name", attr, editRng, !hasEquals && d.attributeCompletionWithValue(attr), d.valueFormat()))
+			count++
+		}
 	}
 
 	blockTypes := sortedBlockTypes(schema.Blocks)
+	if hints != nil {
+		// weighted blocks first, such that they're not cut off by maxCandidates
+		sort.SliceStable(blockTypes, func(i, j int) bool {
+			return hints.Weight(blockTypes[i]) > hints.Weight(blockTypes[j])
+		})
+	}
 	for _, bType := range blockTypes {
 		block := schema.Blocks[bType]
 
@@ -57,16 +97,15 @@ func (d *Decoder) bodySchemaCandidates(body *hclsyntax.Body, schema *schema.Body
 			return candidates
 		}
 
-		candidates.List = append(candidates.List, blockSchemaToCandidate(bType, block, editRng))
+		candidate := blockSchemaToCandidate(bType, block, editRng, d.maxCandidates, d.valueFormat())
+		candidate.Score = hints.Weight(bType)
+		candidates.List = append(candidates.List, candidate)
 		count++
 	}
 
 	candidates.IsComplete = true
 
-	// TODO: sort by more metadata, such as IsRequired or IsDeprecated
-	sort.Slice(candidates.List, func(i, j int) bool {
-		return candidates.List[i].Label < candidates.List[j].Label
-	})
+	candidates.Sort()
 
 	return candidates
 }
@@ -82,6 +121,19 @@ func sortedAttributeNames(attrs map[string]*schema.AttributeSchema) []string {
 	return names
 }
 
+// enumeratedAttributeNames returns names permitted by the attribute's
+// NameConstraint, if these are known upfront
+func enumeratedAttributeNames(attr *schema.AttributeSchema) ([]string, bool) {
+	enum, ok := attr.NameConstraint.(schema.KeyEnum)
+	if !ok {
+		return nil, false
+	}
+	names := make([]string, len(enum.Names))
+	copy(names, enum.Names)
+	sort.Strings(names)
+	return names, true
+}
+
 func sortedBlockTypes(blocks map[string]*schema.BlockSchema) []string {
 	bTypes := make([]string, len(blocks))
 	i := 0
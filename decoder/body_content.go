@@ -0,0 +1,189 @@
+package decoder
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// BodyContent represents a summary of content of a body
+// in terms of its schema (see BodyContentAtPos)
+type BodyContent struct {
+	// Range represents the range of the body
+	Range hcl.Range
+
+	// Schema represents the (merged) schema of the body,
+	// which is nil for blocks declared without body
+	Schema *schema.BodySchema
+
+	// Attributes and Blocks represent content declared
+	// in the schema, in the order of appearance
+	Attributes []BodyContentAttribute
+	Blocks     []BodyContentBlock
+
+	// MissingAttributes represents names of required attributes
+	// which are not present, in alphabetical order
+	MissingAttributes []string
+
+	// MissingBlocks represents types of blocks which are present
+	// fewer times than their schema requires (see MinItems),
+	// in alphabetical order
+	MissingBlocks []string
+
+	// UnknownAttributes and UnknownBlocks represent content
+	// not declared in the schema, in the order of appearance
+	UnknownAttributes []BodyContentAttribute
+	UnknownBlocks     []BodyContentBlock
+}
+
+// BodyContentAttribute represents an attribute present in a body
+type BodyContentAttribute struct {
+	Name  string
+	Range hcl.Range
+
+	// Schema is nil for attributes not declared in the schema
+	Schema *schema.AttributeSchema
+}
+
+// BodyContentBlock represents a block present in a body
+type BodyContentBlock struct {
+	Type   string
+	Labels []string
+	Range  hcl.Range
+
+	// Schema is nil for blocks not declared in the schema
+	Schema *schema.BlockSchema
+}
+
+// BodyContentAtPos returns a summary of the innermost body (of a block
+// declared in the schema, or the root body) enclosing the given position,
+// i.e. which attributes and blocks are present, which ones are required
+// but missing and which ones are unknown, e.g. such that code actions
+// or UI panels don't need to compare the body against its schema.
+//
+// Schema is required in order to summarize the body and method will
+// return error if there isn't one.
+func (d *Decoder) BodyContentAtPos(filename string, pos hcl.Pos, opts ...RequestOption) (*BodyContent, error) {
+	d = d.withRequestOptions(opts)
+
+	pos = d.posForFile(filename, pos)
+
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	body, bodySchema := d.innermostBodyAndSchema(rootBody, d.rootSchema, 0, pos)
+
+	return bodyContent(body, bodySchema), nil
+}
+
+// innermostBodyAndSchema returns the body of the innermost block
+// declared in the schema which contains the given position,
+// along with its (merged) schema, or the body itself
+func (d *Decoder) innermostBodyAndSchema(body *hclsyntax.Body, bodySchema *schema.BodySchema, nestingLvl int, pos hcl.Pos) (*hclsyntax.Body, *schema.BodySchema) {
+	if d.isNestedTooDeep(nestingLvl) {
+		return body, bodySchema
+	}
+
+	for _, block := range body.Blocks {
+		if block.Body == nil || !block.Body.Range().ContainsPos(pos) {
+			continue
+		}
+
+		bSchema, ok := blockSchemaForType(bodySchema, block.Type)
+		if !ok {
+			break
+		}
+		mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
+		if err != nil {
+			break
+		}
+
+		return d.innermostBodyAndSchema(block.Body, mergedSchema, nestingLvl+1, pos)
+	}
+
+	return body, bodySchema
+}
+
+func bodyContent(body *hclsyntax.Body, bodySchema *schema.BodySchema) *BodyContent {
+	content := &BodyContent{
+		Range:             body.Range(),
+		Schema:            bodySchema,
+		Attributes:        make([]BodyContentAttribute, 0),
+		Blocks:            make([]BodyContentBlock, 0),
+		MissingAttributes: make([]string, 0),
+		MissingBlocks:     make([]string, 0),
+		UnknownAttributes: make([]BodyContentAttribute, 0),
+		UnknownBlocks:     make([]BodyContentBlock, 0),
+	}
+	if bodySchema == nil {
+		// blocks may be declared without body,
+		// in which case any content is unknown
+		bodySchema = &schema.BodySchema{}
+	}
+
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte
+	})
+
+	for _, attr := range attrs {
+		bcAttr := BodyContentAttribute{
+			Name:  attr.Name,
+			Range: attr.SrcRange,
+		}
+		aSchema, ok := attributeSchemaForName(bodySchema, attr.Name)
+		if !ok {
+			content.UnknownAttributes = append(content.UnknownAttributes, bcAttr)
+			continue
+		}
+		bcAttr.Schema = aSchema
+		content.Attributes = append(content.Attributes, bcAttr)
+	}
+
+	blockCounts := make(map[string]uint64, 0)
+	for _, block := range body.Blocks {
+		bcBlock := BodyContentBlock{
+			Type:   block.Type,
+			Labels: block.Labels,
+			Range:  block.Range(),
+		}
+		bSchema, ok := blockSchemaForType(bodySchema, block.Type)
+		if !ok {
+			content.UnknownBlocks = append(content.UnknownBlocks, bcBlock)
+			continue
+		}
+		bcBlock.Schema = bSchema
+		content.Blocks = append(content.Blocks, bcBlock)
+		blockCounts[block.Type]++
+	}
+
+	for name, aSchema := range bodySchema.Attributes {
+		if _, ok := body.Attributes[name]; !ok && aSchema.IsRequired {
+			content.MissingAttributes = append(content.MissingAttributes, name)
+		}
+	}
+	sort.Strings(content.MissingAttributes)
+
+	for blockType, bSchema := range bodySchema.Blocks {
+		if blockCounts[blockType] < bSchema.MinItems {
+			content.MissingBlocks = append(content.MissingBlocks, blockType)
+		}
+	}
+	sort.Strings(content.MissingBlocks)
+
+	return content
+}
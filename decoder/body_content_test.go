@@ -0,0 +1,229 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var bodyContentSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"name": {Expr: schema.LiteralTypeOnly(cty.String), IsRequired: true},
+	},
+	Blocks: map[string]*schema.BlockSchema{
+		"server": {
+			Labels: []*schema.LabelSchema{
+				{Name: "name"},
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"host":    {Expr: schema.LiteralTypeOnly(cty.String), IsRequired: true},
+					"port":    {Expr: schema.LiteralTypeOnly(cty.Number), IsRequired: true},
+					"timeout": {Expr: schema.LiteralTypeOnly(cty.Number), IsOptional: true},
+				},
+				Blocks: map[string]*schema.BlockSchema{
+					"tls": {
+						MinItems: 1,
+						Body: &schema.BodySchema{
+							Attributes: map[string]*schema.AttributeSchema{
+								"cert": {Expr: schema.LiteralTypeOnly(cty.String), IsOptional: true},
+							},
+						},
+					},
+					"listener": {},
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_BodyContentAtPos(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		pos             hcl.Pos
+		expectedContent bodyContentSummary
+	}{
+		{
+			"root body",
+			`name = "foo"
+unknown = 42
+server "a" {
+}
+other {}
+`,
+			hcl.Pos{Line: 1, Column: 1, Byte: 0},
+			bodyContentSummary{
+				Range:             "1,1-6,1",
+				Attributes:        []string{"name 1,1-1,13"},
+				Blocks:            []string{"server a 3,1-4,2"},
+				MissingAttributes: []string{},
+				MissingBlocks:     []string{},
+				UnknownAttributes: []string{"unknown 2,1-2,13"},
+				UnknownBlocks:     []string{"other 5,1-5,9"},
+			},
+		},
+		{
+			"block body",
+			`server "a" {
+  port = 80
+  bar  = true
+  listener {}
+  foo {}
+
+}
+`,
+			hcl.Pos{Line: 6, Column: 1, Byte: 62},
+			bodyContentSummary{
+				Range:             "1,12-7,2",
+				Attributes:        []string{"port 2,3-2,12"},
+				Blocks:            []string{"listener 4,3-4,14"},
+				MissingAttributes: []string{"host"},
+				MissingBlocks:     []string{"tls"},
+				UnknownAttributes: []string{"bar 3,3-3,14"},
+				UnknownBlocks:     []string{"foo 5,3-5,9"},
+			},
+		},
+		{
+			"nested block body",
+			`server "a" {
+  tls {
+    cert = "foo"
+  }
+}
+`,
+			hcl.Pos{Line: 3, Column: 5, Byte: 24},
+			bodyContentSummary{
+				Range:             "2,7-4,4",
+				Attributes:        []string{"cert 3,5-3,17"},
+				Blocks:            []string{},
+				MissingAttributes: []string{},
+				MissingBlocks:     []string{},
+				UnknownAttributes: []string{},
+				UnknownBlocks:     []string{},
+			},
+		},
+		{
+			"body of unknown block",
+			`other {
+  foo = 1
+}
+`,
+			hcl.Pos{Line: 2, Column: 3, Byte: 10},
+			bodyContentSummary{
+				Range:             "1,1-4,1",
+				Attributes:        []string{},
+				Blocks:            []string{},
+				MissingAttributes: []string{"name"},
+				MissingBlocks:     []string{},
+				UnknownAttributes: []string{},
+				UnknownBlocks:     []string{"other 1,1-3,2"},
+			},
+		},
+		{
+			"block declared without body",
+			`server "a" {
+  listener {
+    foo = 1
+  }
+}
+`,
+			hcl.Pos{Line: 3, Column: 5, Byte: 29},
+			bodyContentSummary{
+				Range:             "2,12-4,4",
+				Attributes:        []string{},
+				Blocks:            []string{},
+				MissingAttributes: []string{},
+				MissingBlocks:     []string{},
+				UnknownAttributes: []string{"foo 3,5-3,12"},
+				UnknownBlocks:     []string{},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+
+			d := NewDecoder()
+			d.SetSchema(bodyContentSchema)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := d.BodyContentAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedContent, summarizeBodyContent(content)); diff != "" {
+				t.Fatalf("unexpected content: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_BodyContentAtPos_noSchema(t *testing.T) {
+	f, _ := hclsyntax.ParseConfig([]byte("name = \"foo\"\n"), "test.tf", hcl.InitialPos)
+
+	d := NewDecoder()
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = d.BodyContentAtPos("test.tf", hcl.InitialPos)
+	if _, ok := err.(*NoSchemaError); !ok {
+		t.Fatalf("expected NoSchemaError, given: %#v", err)
+	}
+}
+
+type bodyContentSummary struct {
+	Range             string
+	Attributes        []string
+	Blocks            []string
+	MissingAttributes []string
+	MissingBlocks     []string
+	UnknownAttributes []string
+	UnknownBlocks     []string
+}
+
+func summarizeBodyContent(content *BodyContent) bodyContentSummary {
+	attrs := func(bcAttrs []BodyContentAttribute) []string {
+		names := make([]string, len(bcAttrs))
+		for i, attr := range bcAttrs {
+			names[i] = fmt.Sprintf("%s %s", attr.Name, rangeWithoutFilename(&attr.Range))
+		}
+		return names
+	}
+	blocks := func(bcBlocks []BodyContentBlock) []string {
+		names := make([]string, len(bcBlocks))
+		for i, block := range bcBlocks {
+			name := block.Type
+			for _, label := range block.Labels {
+				name += " " + label
+			}
+			names[i] = fmt.Sprintf("%s %s", name, rangeWithoutFilename(&block.Range))
+		}
+		return names
+	}
+
+	return bodyContentSummary{
+		Range:             rangeWithoutFilename(&content.Range),
+		Attributes:        attrs(content.Attributes),
+		Blocks:            blocks(content.Blocks),
+		MissingAttributes: content.MissingAttributes,
+		MissingBlocks:     content.MissingBlocks,
+		UnknownAttributes: attrs(content.UnknownAttributes),
+		UnknownBlocks:     blocks(content.UnknownBlocks),
+	}
+}
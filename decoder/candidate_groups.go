@@ -0,0 +1,76 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// candidateGroups represents names of groups of candidates
+// (see Features.CandidateGroups) in the order of the groups
+var candidateGroups = []string{
+	"Templates",
+	"Attributes",
+	"Blocks",
+	"Labels",
+	"References",
+	"Functions",
+	"Literals",
+	"Keywords",
+}
+
+// candidateGroupName returns the name of the group
+// which the candidate belongs to, based on its kind
+func candidateGroupName(c lang.Candidate) string {
+	switch c.Kind {
+	case lang.TemplateCandidateKind:
+		return "Templates"
+	case lang.AttributeCandidateKind:
+		return "Attributes"
+	case lang.BlockCandidateKind:
+		return "Blocks"
+	case lang.LabelCandidateKind:
+		return "Labels"
+	case lang.TraversalCandidateKind:
+		return "References"
+	case lang.FunctionCandidateKind:
+		return "Functions"
+	case lang.KeywordCandidateKind:
+		return "Keywords"
+	}
+	return "Literals"
+}
+
+// candidatesInGroups returns the candidates grouped by their kind
+// (see candidateGroupName), if enabled. The order of candidates
+// within each group is retained and empty groups are left out.
+func (d *Decoder) candidatesInGroups(candidates lang.Candidates) lang.Candidates {
+	if !d.features.CandidateGroups {
+		return candidates
+	}
+
+	lists := make(map[string][]lang.Candidate, 0)
+	for _, c := range candidates.List {
+		name := candidateGroupName(c)
+		lists[name] = append(lists[name], c)
+	}
+
+	grouped := lang.Candidates{
+		List:       make([]lang.Candidate, 0),
+		IsComplete: candidates.IsComplete,
+		Groups:     make([]lang.CandidateGroup, 0),
+	}
+	for _, name := range candidateGroups {
+		list, ok := lists[name]
+		if !ok {
+			continue
+		}
+		grouped.Groups = append(grouped.Groups, lang.CandidateGroup{
+			Name: name,
+			List: list,
+			// candidates are only left out at the end of the list,
+			// which may be the end of any group
+			IsComplete: candidates.IsComplete,
+		})
+	}
+
+	return grouped
+}
@@ -0,0 +1,118 @@
+package decoder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+var candidateGroupsSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"enabled": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.TraversalExpr{OfType: cty.Bool},
+				schema.LiteralTypeExpr{Type: cty.Bool},
+			},
+		},
+	},
+}
+
+var candidateGroupsTargets = lang.ReferenceTargets{
+	{
+		Addr: lang.Address{
+			lang.RootStep{Name: "var"},
+			lang.AttrStep{Name: "flag"},
+		},
+		Type: cty.Bool,
+	},
+}
+
+func TestDecoder_CandidatesAtPos_candidateGroups(t *testing.T) {
+	cfg := "enabled = \n"
+	d := newTestDecoder(t, candidateGroupsSchema, map[string]string{"test.tf": cfg})
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return candidateGroupsTargets
+	})
+	d.SetFunctions(map[string]schema.FunctionSignature{
+		"alltrue": {
+			Params:     []function.Parameter{{Name: "list", Type: cty.List(cty.Bool)}},
+			ReturnType: cty.Bool,
+		},
+	})
+	pos := posInCfg(t, cfg, "enabled = ")
+
+	candidates, err := d.CandidatesAtPos("test.tf", pos, WithFeatures(Features{CandidateGroups: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	langtest.AssertCandidates(t, `
+complete: true
+candidates: 4
+=== group: "References" (complete: true)
+---
+label: "var.flag"
+kind: TraversalCandidateKind
+detail: "bool"
+edit: test.tf:1,11-1,11 "var.flag"
+=== group: "Functions" (complete: true)
+---
+label: "alltrue"
+kind: FunctionCandidateKind
+detail: "alltrue(list list of bool) bool"
+label details: "(list list of bool)" "bool"
+edit: test.tf:1,11-1,11 "alltrue()" (snippet "alltrue(${0})")
+=== group: "Literals" (complete: true)
+---
+label: "false"
+kind: BoolCandidateKind
+detail: "bool"
+edit: test.tf:1,11-1,11 "false" (snippet "${1:false}")
+---
+label: "true"
+kind: BoolCandidateKind
+detail: "bool"
+edit: test.tf:1,11-1,11 "true" (snippet "${1:true}")
+`, candidates)
+
+	// flattened groups are the same as candidates without groups,
+	// apart from the order, which follows the groups
+	flatCandidates, err := d.CandidatesAtPos("test.tf", pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flatCandidates.Sort()
+	flattened := candidates.Flatten()
+	flattened.Sort()
+	if diff := cmp.Diff(flatCandidates, flattened); diff != "" {
+		t.Fatalf("unexpected flattened candidates: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_candidateGroupsBody(t *testing.T) {
+	d := newTestDecoder(t, candidateGroupsSchema, map[string]string{"test.tf": ""})
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return candidateGroupsTargets
+	})
+	d.SetFeatures(Features{CandidateGroups: true})
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.InitialPos, WithMaxCandidates(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	langtest.AssertCandidates(t, `
+complete: false
+candidates: 0
+`, candidates)
+	if diff := cmp.Diff(lang.Candidates{List: []lang.Candidate{}, IsComplete: false}, candidates.Flatten()); diff != "" {
+		t.Fatalf("unexpected flattened candidates: %s", diff)
+	}
+}
@@ -0,0 +1,38 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// referenceTargetScore returns relevance score of a reference target
+// as a candidate completed at the given position, such that targets
+// declared in the same file rank above targets declared elsewhere
+// and nearer targets rank above those further away.
+func referenceTargetScore(ref lang.ReferenceTarget, filename string, pos hcl.Pos) float64 {
+	if ref.RangePtr == nil || ref.RangePtr.Filename != filename {
+		return 0
+	}
+
+	distance := 0
+	switch {
+	case pos.Line < ref.RangePtr.Start.Line:
+		distance = ref.RangePtr.Start.Line - pos.Line
+	case pos.Line > ref.RangePtr.End.Line:
+		distance = pos.Line - ref.RangePtr.End.Line
+	}
+
+	// targets in the same file always score above 0.5
+	return 0.5 + 0.5/float64(1+distance)
+}
+
+// functionScore returns relevance score of a function
+// as a candidate, such that deprecated functions rank
+// below any other candidates
+func functionScore(sig schema.FunctionSignature) float64 {
+	if sig.IsDeprecated() {
+		return -1
+	}
+	return 0
+}
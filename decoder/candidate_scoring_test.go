@@ -0,0 +1,98 @@
+package decoder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_CandidatesAtPos_referenceScoring(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"attr": {
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.String},
+				},
+			},
+		},
+	})
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return lang.ReferenceTargets{
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "var"},
+					lang.AttrStep{Name: "a_builtin"},
+				},
+				Type: cty.String,
+			},
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "var"},
+					lang.AttrStep{Name: "b_other_file"},
+				},
+				Type: cty.String,
+				RangePtr: &hcl.Range{
+					Filename: "other.tf",
+					Start:    hcl.Pos{Line: 10, Column: 1, Byte: 100},
+					End:      hcl.Pos{Line: 10, Column: 10, Byte: 109},
+				},
+			},
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "var"},
+					lang.AttrStep{Name: "c_far"},
+				},
+				Type: cty.String,
+				RangePtr: &hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 40, Column: 1, Byte: 400},
+					End:      hcl.Pos{Line: 42, Column: 2, Byte: 420},
+				},
+			},
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "var"},
+					lang.AttrStep{Name: "d_near"},
+				},
+				Type: cty.String,
+				RangePtr: &hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 3, Column: 1, Byte: 30},
+					End:      hcl.Pos{Line: 5, Column: 2, Byte: 50},
+				},
+			},
+		}
+	})
+
+	f, _ := hclsyntax.ParseConfig([]byte(`attr = var.
+`), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 12, Byte: 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := make([]string, 0)
+	for _, c := range candidates.List {
+		labels = append(labels, c.Label)
+	}
+	expectedLabels := []string{
+		"var.d_near",
+		"var.c_far",
+		"var.a_builtin",
+		"var.b_other_file",
+	}
+	if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+		t.Fatalf("unexpected candidate order: %s", diff)
+	}
+}
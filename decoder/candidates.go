@@ -2,6 +2,7 @@ package decoder
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl-lang/lang"
 	"github.com/hashicorp/hcl-lang/schema"
@@ -13,15 +14,21 @@ import (
 //
 // Schema is required in order to return any candidates and method will return
 // error if there isn't one.
-func (d *Decoder) CandidatesAtPos(filename string, pos hcl.Pos) (lang.Candidates, error) {
-	f, err := d.fileByName(filename)
+//
+// Candidates are returned in a deterministic order (see lang.Candidates.Sort).
+func (d *Decoder) CandidatesAtPos(filename string, pos hcl.Pos, opts ...RequestOption) (lang.Candidates, error) {
+	d = d.withRequestOptions(opts)
+
+	pos = d.posForFile(filename, pos)
+
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
 	if err != nil {
 		return lang.ZeroCandidates(), err
 	}
 
-	rootBody, err := d.bodyForFileAndPos(filename, f, pos)
-	if err != nil {
-		return lang.ZeroCandidates(), err
+	// directives of templates do not depend on the schema
+	if candidates, ok := d.templateDirectiveCandidatesAtPos(rootBody, pos); ok {
+		return d.candidatesInGroups(d.candidatesForClient(candidates)), nil
 	}
 
 	d.rootSchemaMu.RLock()
@@ -40,31 +47,68 @@ func (d *Decoder) CandidatesAtPos(filename string, pos hcl.Pos) (lang.Candidates
 		outerBodyRng = ob.Range()
 	}
 
-	return d.candidatesAtPos(rootBody, outerBodyRng, d.rootSchema, pos)
+	candidates, err := d.candidatesAtPos(rootBody, outerBodyRng, d.rootSchema, 0, pos)
+	if err != nil {
+		return candidates, err
+	}
+
+	candidates = d.candidatesWithSchemaPaths(candidates, rootBody, pos)
+	candidates = d.candidatesWithAdditionalTextEdits(filename, candidates)
+
+	return d.candidatesInGroups(d.candidatesForClient(candidates)), nil
+}
+
+func (d *Decoder) candidatesWithAdditionalTextEdits(filename string, candidates lang.Candidates) lang.Candidates {
+	if d.textEditsHook == nil {
+		return candidates
+	}
+
+	for i, candidate := range candidates.List {
+		edits := d.textEditsHook(filename, candidate)
+		if len(edits) == 0 {
+			continue
+		}
+		candidates.List[i].AdditionalTextEdits = lang.MergeTextEdits(candidate.TextEdit,
+			append(candidate.AdditionalTextEdits, edits...))
+	}
+
+	return candidates
 }
 
-func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, outerBodyRng hcl.Range, bodySchema *schema.BodySchema, pos hcl.Pos) (lang.Candidates, error) {
-	if bodySchema == nil {
+func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, outerBodyRng hcl.Range, bodySchema *schema.BodySchema, nestingLvl int, pos hcl.Pos) (lang.Candidates, error) {
+	if bodySchema == nil || d.isNestedTooDeep(nestingLvl) {
 		return lang.ZeroCandidates(), nil
 	}
 
 	filename := body.Range().Filename
 
-	for _, attr := range body.Attributes {
+	for _, attr := range d.attributesOfBody(body) {
+		if aSchema, ok := attributeSchemaForName(bodySchema, attr.Name); ok {
+			if candidates, ok := d.sizeCandidatesAtPos(attr, aSchema, pos); ok {
+				return candidates, nil
+			}
+		}
 		if d.isPosInsideAttrExpr(attr, pos) {
-			if aSchema, ok := bodySchema.Attributes[attr.Name]; ok {
-				return d.attrValueCandidatesAtPos(attr, aSchema, outerBodyRng, pos)
+			conversion, hasConversion := d.blockConversionCandidate(attr, bodySchema, pos)
+			if aSchema, ok := attributeSchemaForName(bodySchema, attr.Name); ok {
+				candidates, err := d.attrValueCandidatesAtPos(attr, aSchema, outerBodyRng, pos)
+				if err == nil && hasConversion {
+					candidates.List = append(candidates.List, conversion)
+					candidates.Sort()
+				}
+				return candidates, err
 			}
-			if bodySchema.AnyAttribute != nil {
-				return d.attrValueCandidatesAtPos(attr, bodySchema.AnyAttribute, outerBodyRng, pos)
+			if hasConversion {
+				return lang.CompleteCandidates([]lang.Candidate{conversion}), nil
 			}
 
 			return lang.ZeroCandidates(), nil
 		}
-		if attr.NameRange.ContainsPos(pos) {
+		if attr.NameRange.ContainsPos(pos) || attr.NameRange.End.Byte == pos.Byte {
+			// only the name is replaced, as " = " and the value are already present
 			prefixRng := attr.NameRange
 			prefixRng.End = pos
-			return d.bodySchemaCandidates(body, bodySchema, prefixRng, attr.Range()), nil
+			return d.bodySchemaCandidates(body, bodySchema, prefixRng, attr.NameRange, true, topLevelCompletionHints(bodySchema, nestingLvl)), nil
 		}
 		if attr.EqualsRange.ContainsPos(pos) {
 			return lang.ZeroCandidates(), nil
@@ -79,8 +123,13 @@ func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, outerBodyRng hcl.Range,
 
 	for _, block := range body.Blocks {
 		if block.Range().ContainsPos(pos) {
-			bSchema, ok := bodySchema.Blocks[block.Type]
+			bSchema, ok := blockSchemaForType(bodySchema, block.Type)
 			if !ok {
+				if block.Body != nil && block.Body.Range().ContainsPos(pos) {
+					if conversion, ok := d.attributeConversionCandidate(block, bodySchema, pos); ok {
+						return lang.CompleteCandidates([]lang.Candidate{conversion}), nil
+					}
+				}
 				return lang.ZeroCandidates(), &PositionalError{
 					Filename: filename,
 					Pos:      pos,
@@ -91,7 +140,7 @@ func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, outerBodyRng hcl.Range,
 			if block.TypeRange.ContainsPos(pos) {
 				prefixRng := block.TypeRange
 				prefixRng.End = pos
-				return d.bodySchemaCandidates(body, bodySchema, prefixRng, block.Range()), nil
+				return d.bodySchemaCandidates(body, bodySchema, prefixRng, block.Range(), false, topLevelCompletionHints(bodySchema, nestingLvl)), nil
 			}
 
 			for i, labelRange := range block.LabelRanges {
@@ -111,13 +160,17 @@ func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, outerBodyRng hcl.Range,
 					}
 					prefixRng.End = pos
 
-					labelSchema := bSchema.Labels[i]
+					return d.labelCandidates(i, bSchema.Labels[i], dependentBodiesForCompletion(bSchema), prefixRng, rng)
+				}
+			}
 
-					if !labelSchema.Completable {
-						return lang.ZeroCandidates(), nil
+			if isPosInBlockHeader(block, pos) {
+				header, ok := d.blockHeaderAtPos(filename, pos)
+				if ok && header.typeRange == block.TypeRange {
+					lp, ok := header.labelPos(pos)
+					if ok && lp.index < len(bSchema.Labels) {
+						return d.blockHeaderCandidates(bSchema, lp)
 					}
-
-					return d.labelCandidatesFromDependentSchema(i, bSchema.DependentBody, prefixRng, rng)
 				}
 			}
 
@@ -135,17 +188,73 @@ func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, outerBodyRng hcl.Range,
 					return lang.ZeroCandidates(), err
 				}
 
-				return d.candidatesAtPos(block.Body, outerBodyRng, mergedSchema, pos)
+				candidates, err := d.candidatesAtPos(block.Body, outerBodyRng, mergedSchema, nestingLvl+1, pos)
+				if err != nil {
+					return candidates, err
+				}
+				if conversion, ok := d.attributeConversionCandidate(block, bodySchema, pos); ok {
+					candidates.List = append(candidates.List, conversion)
+					candidates.Sort()
+				}
+				return candidates, nil
 			}
 		}
 	}
 
+	// block header not recognized by the parser yet,
+	// e.g. due to missing opening brace
+	if header, ok := d.blockHeaderAtPos(filename, pos); ok {
+		if bSchema, ok := blockSchemaForType(bodySchema, header.blockType); ok {
+			lp, ok := header.labelPos(pos)
+			if !ok {
+				return lang.ZeroCandidates(), nil
+			}
+			return d.blockHeaderCandidates(bSchema, lp)
+		}
+	}
+
 	tokenRng, err := d.nameTokenRangeAtPos(body.Range().Filename, pos)
 	if err == nil {
 		rng = tokenRng
 	}
 
-	return d.bodySchemaCandidates(body, bodySchema, rng, rng), nil
+	return d.bodySchemaCandidates(body, bodySchema, rng, rng, d.isEqualsAfterRange(rng), topLevelCompletionHints(bodySchema, nestingLvl)), nil
+}
+
+// isEqualsAfterRange returns true if the token following the given
+// range is "=", i.e. the range represents name of an attribute which
+// the parser didn't recognize yet, such as when the value is missing
+func (d *Decoder) isEqualsAfterRange(rng hcl.Range) bool {
+	tokens, err := d.tokensForFileAndPos(rng.Filename, rng.End)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range tokens {
+		if t.Range.Start.Byte < rng.End.Byte {
+			continue
+		}
+		return t.Type == hclsyntax.TokenEqual
+	}
+
+	return false
+}
+
+// escapeSnippetText escapes characters which have special
+// meaning in snippets, such that the text is inserted as-is
+func escapeSnippetText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`$`, `\$`,
+		`}`, `\}`,
+	).Replace(text)
+}
+
+func triggerSuggestCommand(triggerSuggest bool) *lang.Command {
+	if !triggerSuggest {
+		return nil
+	}
+	return lang.TriggerSuggestCommand()
 }
 
 func (d *Decoder) isPosInsideAttrExpr(attr *hclsyntax.Attribute, pos hcl.Pos) bool {
@@ -165,7 +274,7 @@ func (d *Decoder) isPosInsideAttrExpr(attr *hclsyntax.Attribute, pos hcl.Pos) bo
 
 	// edge case: end of incomplete traversal with '.' (which parser ignores)
 	endByte := attr.Expr.Range().End.Byte
-	if _, ok := attr.Expr.(*hclsyntax.ScopeTraversalExpr); ok && pos.Byte-endByte == 1 {
+	if isTraversalLikeExpr(attr.Expr) && pos.Byte-endByte == 1 {
 		suspectedDotRng := hcl.Range{
 			Filename: attr.Expr.Range().Filename,
 			Start:    attr.Expr.Range().End,
@@ -180,6 +289,16 @@ func (d *Decoder) isPosInsideAttrExpr(attr *hclsyntax.Attribute, pos hcl.Pos) bo
 	return false
 }
 
+func isTraversalLikeExpr(expr hclsyntax.Expression) bool {
+	switch expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr,
+		*hclsyntax.SplatExpr,
+		*hclsyntax.RelativeTraversalExpr:
+		return true
+	}
+	return false
+}
+
 func (d *Decoder) nameTokenRangeAtPos(filename string, pos hcl.Pos) (hcl.Range, error) {
 	rng := hcl.Range{
 		Filename: filename,
@@ -187,14 +306,10 @@ func (d *Decoder) nameTokenRangeAtPos(filename string, pos hcl.Pos) (hcl.Range,
 		End:      pos,
 	}
 
-	f, err := d.fileByName(filename)
+	tokens, err := d.tokensForFileAndPos(filename, pos)
 	if err != nil {
 		return rng, err
 	}
-	tokens, diags := hclsyntax.LexConfig(f.Bytes, filename, hcl.InitialPos)
-	if diags.HasErrors() {
-		return rng, diags
-	}
 
 	return nameTokenRangeAtPos(tokens, pos)
 }
@@ -234,14 +349,10 @@ func (d *Decoder) labelTokenRangeAtPos(filename string, pos hcl.Pos) (hcl.Range,
 		End:      pos,
 	}
 
-	f, err := d.fileByName(filename)
+	tokens, err := d.tokensForFileAndPos(filename, pos)
 	if err != nil {
 		return rng, err
 	}
-	tokens, diags := hclsyntax.LexConfig(f.Bytes, filename, hcl.InitialPos)
-	if diags.HasErrors() {
-		return rng, diags
-	}
 
 	prefixRng, err := labelTokenRangeAtPos(tokens, pos)
 	if err != nil {
@@ -268,6 +379,12 @@ func labelTokenRangeAtPos(tokens hclsyntax.Tokens, pos hcl.Pos) (hcl.Range, erro
 	return hcl.Range{}, fmt.Errorf("no valid token found at %s", stringPos(pos))
 }
 
+// isPosInBlockHeader returns true if the position is between
+// the block type and the opening brace
+func isPosInBlockHeader(block *hclsyntax.Block, pos hcl.Pos) bool {
+	return pos.Byte > block.TypeRange.End.Byte && pos.Byte <= block.OpenBraceRange.Start.Byte
+}
+
 func isPosOutsideBody(block *hclsyntax.Block, pos hcl.Pos) bool {
 	if block.OpenBraceRange.ContainsPos(pos) {
 		return true
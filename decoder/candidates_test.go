@@ -208,8 +208,8 @@ func TestDecoder_CandidatesAtPos_nilBodySchema(t *testing.T) {
 						NewText: "three",
 						Snippet: "three = ${1:false}",
 					},
-					Kind:           lang.AttributeCandidateKind,
-					TriggerSuggest: true,
+					Kind:    lang.AttributeCandidateKind,
+					Command: lang.TriggerSuggestCommand(),
 				},
 				{
 					Label:  "two",
@@ -644,6 +644,61 @@ func TestDecoder_CandidatesAtPos_nonCompletableLabel(t *testing.T) {
 	}
 }
 
+func TestDecoder_CandidatesAtPos_stableLabelOrder(t *testing.T) {
+	dependentBodies := make(map[schema.SchemaKey]*schema.BodySchema, 0)
+	for i := 0; i < 50; i++ {
+		dependentBodies[schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: fmt.Sprintf("type_%02d", i)},
+			},
+		})] = &schema.BodySchema{}
+	}
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"myblock": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true, Completable: true},
+				},
+				DependentBody: dependentBodies,
+			},
+		},
+	}
+	testConfig := []byte(`myblock "" {
+}
+`)
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.maxCandidates = 3
+	f, _ := hclsyntax.ParseConfig(testConfig, "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedLabels := []string{"type_00", "type_01", "type_02"}
+	for i := 0; i < 10; i++ {
+		candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{
+			Line:   1,
+			Column: 10,
+			Byte:   9,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if candidates.IsComplete {
+			t.Fatal("expected candidates to be incomplete")
+		}
+		labels := make([]string, 0)
+		for _, c := range candidates.List {
+			labels = append(labels, c.Label)
+		}
+		if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+			t.Fatalf("unexpected labels: %s", diff)
+		}
+	}
+}
+
 func TestDecoder_CandidatesAtPos_zeroByteContent(t *testing.T) {
 	resourceLabelSchema := []*schema.LabelSchema{
 		{Name: "type", IsDepKey: true, Completable: true},
@@ -691,8 +746,8 @@ func TestDecoder_CandidatesAtPos_zeroByteContent(t *testing.T) {
 				NewText: "resource",
 				Snippet: "resource \"${1}\" \"${2:name}\" {\n  ${3}\n}",
 			},
-			Kind:           lang.BlockCandidateKind,
-			TriggerSuggest: true,
+			Kind:    lang.BlockCandidateKind,
+			Command: lang.TriggerSuggestCommand(),
 		},
 	})
 	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
@@ -753,8 +808,8 @@ func TestDecoder_CandidatesAtPos_endOfFilePos(t *testing.T) {
 					NewText: "resource",
 					Snippet: "resource \"${1}\" \"${2:name}\" {\n  ${3}\n}",
 				},
-				Kind:           lang.BlockCandidateKind,
-				TriggerSuggest: true,
+				Kind:    lang.BlockCandidateKind,
+				Command: lang.TriggerSuggestCommand(),
 			},
 		},
 		IsComplete: true,
@@ -1048,10 +1103,9 @@ resource "random_resource" "test" {
 							End:      hcl.Pos{Line: 4, Column: 1, Byte: 52},
 						},
 						NewText: "resource",
-						Snippet: "resource \"${1}\" \"${2:name}\" {\n  ${3}\n}",
+						Snippet: "resource \"${1|azurerm_subnet,random_resource,sensitive_resource|}\" \"${2:name}\" {\n  ${3}\n}",
 					},
-					Kind:           lang.BlockCandidateKind,
-					TriggerSuggest: true,
+					Kind: lang.BlockCandidateKind,
 				},
 			}),
 		},
@@ -1069,10 +1123,9 @@ resource "random_resource" "test" {
 							End:      hcl.Pos{Line: 3, Column: 2, Byte: 51},
 						},
 						NewText: "resource",
-						Snippet: "resource \"${1}\" \"${2:name}\" {\n  ${3}\n}",
+						Snippet: "resource \"${1|azurerm_subnet,random_resource,sensitive_resource|}\" \"${2:name}\" {\n  ${3}\n}",
 					},
-					Kind:           lang.BlockCandidateKind,
-					TriggerSuggest: true,
+					Kind: lang.BlockCandidateKind,
 				},
 			}),
 		},
@@ -1151,8 +1204,8 @@ resource "random_resource" "test" {
 						NewText: "three",
 						Snippet: "three = ${1:false}",
 					},
-					Kind:           lang.AttributeCandidateKind,
-					TriggerSuggest: true,
+					Kind:    lang.AttributeCandidateKind,
+					Command: lang.TriggerSuggestCommand(),
 				},
 				{
 					Label:  "two",
@@ -1181,10 +1234,10 @@ resource "random_resource" "test" {
 						Range: hcl.Range{
 							Filename: "test.tf",
 							Start:    hcl.Pos{Line: 2, Column: 3, Byte: 40},
-							End:      hcl.Pos{Line: 2, Column: 12, Byte: 49},
+							End:      hcl.Pos{Line: 2, Column: 8, Byte: 45},
 						},
 						NewText: "one",
-						Snippet: `one = "${1:value}"`,
+						Snippet: "one",
 					},
 					Kind: lang.AttributeCandidateKind,
 				},
@@ -1195,13 +1248,12 @@ resource "random_resource" "test" {
 						Range: hcl.Range{
 							Filename: "test.tf",
 							Start:    hcl.Pos{Line: 2, Column: 3, Byte: 40},
-							End:      hcl.Pos{Line: 2, Column: 12, Byte: 49},
+							End:      hcl.Pos{Line: 2, Column: 8, Byte: 45},
 						},
 						NewText: "three",
-						Snippet: "three = ${1:false}",
+						Snippet: "three",
 					},
-					Kind:           lang.AttributeCandidateKind,
-					TriggerSuggest: true,
+					Kind: lang.AttributeCandidateKind,
 				},
 				{
 					Label:  "two",
@@ -1210,10 +1262,10 @@ resource "random_resource" "test" {
 						Range: hcl.Range{
 							Filename: "test.tf",
 							Start:    hcl.Pos{Line: 2, Column: 3, Byte: 40},
-							End:      hcl.Pos{Line: 2, Column: 12, Byte: 49},
+							End:      hcl.Pos{Line: 2, Column: 8, Byte: 45},
 						},
 						NewText: "two",
-						Snippet: "two = ${1:1}",
+						Snippet: "two",
 					},
 					Kind: lang.AttributeCandidateKind,
 				},
@@ -1632,3 +1684,112 @@ resource "random_resource" "test" {
   arg = ""
 }
 `)
+
+func TestDecoder_CandidatesAtPos_additionalTextEdits(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"provider": {
+				Labels: []*schema.LabelSchema{
+					{Name: "name"},
+				},
+				Body: schema.NewBodySchema(),
+			},
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type"},
+				},
+				Body: schema.NewBodySchema(),
+			},
+		},
+	}
+	testConfig := []byte(`terraform {}
+
+`)
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.SetAdditionalTextEditsHook(func(filename string, candidate lang.Candidate) []lang.TextEdit {
+		if candidate.Label != "resource" {
+			return []lang.TextEdit{}
+		}
+		insertRng := hcl.Range{
+			Filename: filename,
+			Start:    hcl.InitialPos,
+			End:      hcl.InitialPos,
+		}
+		return []lang.TextEdit{
+			{
+				NewText: "provider \"aws\" {}\n",
+				Snippet: "provider \"aws\" {}\n",
+				Range:   insertRng,
+			},
+			{
+				NewText: "provider \"aws\" {}\n",
+				Snippet: "provider \"aws\" {}\n",
+				Range:   insertRng,
+			},
+			{
+				NewText: "# ignored\n",
+				Snippet: "# ignored\n",
+				Range: hcl.Range{
+					Filename: filename,
+					Start:    hcl.Pos{Line: 3, Column: 1, Byte: 14},
+					End:      hcl.Pos{Line: 3, Column: 1, Byte: 14},
+				},
+			},
+		}
+	})
+	f, _ := hclsyntax.ParseConfig(testConfig, "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pos := hcl.Pos{Line: 3, Column: 1, Byte: 14}
+	candidates, err := d.CandidatesAtPos("test.tf", pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := hcl.Range{
+		Filename: "test.tf",
+		Start:    pos,
+		End:      pos,
+	}
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:  "provider",
+			Detail: "Block",
+			Kind:   lang.BlockCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: "provider",
+				Snippet: "provider \"${1:name}\" {\n  ${2}\n}",
+				Range:   rng,
+			},
+		},
+		{
+			Label:  "resource",
+			Detail: "Block",
+			Kind:   lang.BlockCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: "resource",
+				Snippet: "resource \"${1:type}\" {\n  ${2}\n}",
+				Range:   rng,
+			},
+			AdditionalTextEdits: []lang.TextEdit{
+				{
+					NewText: "provider \"aws\" {}\n",
+					Snippet: "provider \"aws\" {}\n",
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.InitialPos,
+						End:      hcl.InitialPos,
+					},
+				},
+			},
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
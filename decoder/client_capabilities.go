@@ -0,0 +1,133 @@
+package decoder
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// ClientCapabilities represents capabilities of the client (editor)
+// which the decoder takes into account when producing candidates
+// and hover data, such that output unsupported by the client
+// is downgraded rather than post-processed by each server.
+type ClientCapabilities struct {
+	// SnippetSupport indicates whether the client can insert
+	// text edits as snippets (with placeholders). Snippet of every
+	// text edit is replaced with plain NewText otherwise.
+	SnippetSupport bool
+
+	// MarkdownSupport indicates whether the client can render
+	// Markdown. Any Markdown content (candidate descriptions
+	// and hover data) is converted to plain text otherwise.
+	MarkdownSupport bool
+
+	// AttributeValueCompletion indicates whether completing
+	// an attribute name also inserts " = " followed by the value
+	// and triggers completion of the value. Only the name is inserted
+	// otherwise, e.g. for clients which insert " = " on their own.
+	//
+	// This can be overridden per attribute via
+	// schema.AttributeSchema.NameCompletion.
+	AttributeValueCompletion bool
+
+	// LabelDetailsSupport indicates whether the client can render
+	// label details of candidates (see lang.Candidate.LabelDetails).
+	// Label details are moved into Detail (where empty) otherwise.
+	LabelDetailsSupport bool
+}
+
+// FullClientCapabilities returns capabilities of a client which
+// supports everything the decoder can produce. These are assumed
+// unless different capabilities are set via SetClientCapabilities.
+func FullClientCapabilities() ClientCapabilities {
+	return ClientCapabilities{
+		SnippetSupport:           true,
+		MarkdownSupport:          true,
+		AttributeValueCompletion: true,
+		LabelDetailsSupport:      true,
+	}
+}
+
+// SetClientCapabilities sets capabilities of the client
+func (d *Decoder) SetClientCapabilities(caps ClientCapabilities) {
+	d.clientCaps = caps
+}
+
+// candidatesForClient returns a copy of the candidates downgraded
+// to the capabilities of the client, leaving the given candidates
+// (and any text edits they may share with others) untouched
+func (d *Decoder) candidatesForClient(candidates lang.Candidates) lang.Candidates {
+	list := make([]lang.Candidate, len(candidates.List))
+	for i, candidate := range candidates.List {
+		if !d.clientCaps.SnippetSupport {
+			candidate.TextEdit = textEditWithoutSnippet(candidate.TextEdit)
+			if candidate.AdditionalTextEdits != nil {
+				edits := make([]lang.TextEdit, len(candidate.AdditionalTextEdits))
+				for j, edit := range candidate.AdditionalTextEdits {
+					edits[j] = textEditWithoutSnippet(edit)
+				}
+				candidate.AdditionalTextEdits = edits
+			}
+		}
+		if !d.clientCaps.MarkdownSupport {
+			candidate.Description = markupContentAsPlainText(candidate.Description)
+		}
+		if !d.clientCaps.LabelDetailsSupport && candidate.LabelDetails != nil {
+			candidate = candidateWithoutLabelDetails(candidate)
+		}
+		list[i] = candidate
+	}
+	candidates.List = list
+	return candidates
+}
+
+func (d *Decoder) hoverDataForClient(data *lang.HoverData) *lang.HoverData {
+	if data == nil || d.clientCaps.MarkdownSupport {
+		return data
+	}
+	return &lang.HoverData{
+		Content: markupContentAsPlainText(data.Content),
+		Range:   data.Range,
+	}
+}
+
+// candidateWithoutLabelDetails returns the candidate with label details
+// moved into Detail, unless the candidate already has one
+func candidateWithoutLabelDetails(candidate lang.Candidate) lang.Candidate {
+	if candidate.Detail == "" {
+		candidate.Detail = strings.TrimSpace(candidate.LabelDetails.Detail + " " +
+			candidate.LabelDetails.Description)
+	}
+	candidate.LabelDetails = nil
+	return candidate
+}
+
+func textEditWithoutSnippet(edit lang.TextEdit) lang.TextEdit {
+	edit.Snippet = edit.NewText
+	return edit
+}
+
+var (
+	mdCodeFence = regexp.MustCompile("(?m)^```.*\n?")
+	mdBold      = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	mdItalic    = regexp.MustCompile(`(^|[\s(])_([^_\n]+)_($|[\s).,:;])`)
+	mdCode      = regexp.MustCompile("`([^`\n]+)`")
+	mdLink      = regexp.MustCompile(`\[([^\]\n]+)\]\(([^)\s]+)\)`)
+)
+
+// markupContentAsPlainText converts Markdown content into plain text,
+// stripping the most common (inline) formatting
+func markupContentAsPlainText(content lang.MarkupContent) lang.MarkupContent {
+	if content.Kind != lang.MarkdownKind {
+		return content
+	}
+
+	value := mdCodeFence.ReplaceAllString(content.Value, "")
+	value = mdBold.ReplaceAllString(value, "$1")
+	value = mdItalic.ReplaceAllString(value, "$1$2$3")
+	value = mdCode.ReplaceAllString(value, "$1")
+	value = mdLink.ReplaceAllString(value, "$1 ($2)")
+
+	return lang.PlainText(value)
+}
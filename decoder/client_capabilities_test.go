@@ -0,0 +1,228 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestMarkupContentAsPlainText(t *testing.T) {
+	testCases := []struct {
+		content         lang.MarkupContent
+		expectedContent lang.MarkupContent
+	}{
+		{
+			lang.PlainText("**not markdown**"),
+			lang.PlainText("**not markdown**"),
+		},
+		{
+			lang.Markdown("**name** _required, string_\n\nName of the `foo_bar` thing"),
+			lang.PlainText("name required, string\n\nName of the foo_bar thing"),
+		},
+		{
+			lang.Markdown("snake_case_name stays (_emphasis_)"),
+			lang.PlainText("snake_case_name stays (emphasis)"),
+		},
+		{
+			lang.Markdown("See [the docs](https://example.com/docs)."),
+			lang.PlainText("See the docs (https://example.com/docs)."),
+		},
+		{
+			lang.Markdown("```\nfoo = \"bar\"\n```\n"),
+			lang.PlainText("foo = \"bar\"\n"),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			content := markupContentAsPlainText(tc.content)
+			if diff := cmp.Diff(tc.expectedContent, content); diff != "" {
+				t.Fatalf("unexpected content: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_clientCapabilities(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"name": {
+				Expr:        schema.LiteralTypeOnly(cty.String),
+				Description: lang.Markdown("**Name** of the thing"),
+			},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.SetClientCapabilities(ClientCapabilities{})
+
+	f, _ := hclsyntax.ParseConfig([]byte("\n"), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.InitialPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:       "name",
+			Detail:      "string",
+			Description: lang.PlainText("Name of the thing"),
+			TextEdit: lang.TextEdit{
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.InitialPos,
+					End:      hcl.InitialPos,
+				},
+				NewText: "name",
+				Snippet: "name",
+			},
+			Kind: lang.AttributeCandidateKind,
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_HoverAtPos_clientCapabilities(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"name": {
+				Expr:        schema.LiteralTypeOnly(cty.String),
+				Description: lang.Markdown("Name of the thing"),
+			},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.SetClientCapabilities(ClientCapabilities{SnippetSupport: true})
+
+	f, _ := hclsyntax.ParseConfig([]byte(`name = "foo"`), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 1, Column: 2, Byte: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData := &lang.HoverData{
+		Content: lang.PlainText("name string\n\nName of the thing"),
+		Range: hcl.Range{
+			Filename: "test.tf",
+			Start:    hcl.InitialPos,
+			End:      hcl.Pos{Line: 1, Column: 13, Byte: 12},
+		},
+	}
+	if diff := cmp.Diff(expectedData, data); diff != "" {
+		t.Fatalf("unexpected hover data: %s", diff)
+	}
+}
+
+func TestDecoder_candidatesForClient_doesNotMutateInput(t *testing.T) {
+	edits := []lang.TextEdit{
+		{NewText: "foo", Snippet: "${1:foo}"},
+	}
+	candidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:               "first",
+			Description:         lang.Markdown("**first**"),
+			TextEdit:            lang.TextEdit{NewText: "first", Snippet: "${1:first}"},
+			AdditionalTextEdits: edits,
+		},
+		{
+			Label:               "second",
+			TextEdit:            lang.TextEdit{NewText: "second", Snippet: "${1:second}"},
+			AdditionalTextEdits: edits,
+		},
+	})
+	expectedInput := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:               "first",
+			Description:         lang.Markdown("**first**"),
+			TextEdit:            lang.TextEdit{NewText: "first", Snippet: "${1:first}"},
+			AdditionalTextEdits: []lang.TextEdit{{NewText: "foo", Snippet: "${1:foo}"}},
+		},
+		{
+			Label:               "second",
+			TextEdit:            lang.TextEdit{NewText: "second", Snippet: "${1:second}"},
+			AdditionalTextEdits: []lang.TextEdit{{NewText: "foo", Snippet: "${1:foo}"}},
+		},
+	})
+
+	d := NewDecoder()
+	d.SetClientCapabilities(ClientCapabilities{})
+	downgraded := d.candidatesForClient(candidates)
+
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:               "first",
+			Description:         lang.PlainText("first"),
+			TextEdit:            lang.TextEdit{NewText: "first", Snippet: "first"},
+			AdditionalTextEdits: []lang.TextEdit{{NewText: "foo", Snippet: "foo"}},
+		},
+		{
+			Label:               "second",
+			TextEdit:            lang.TextEdit{NewText: "second", Snippet: "second"},
+			AdditionalTextEdits: []lang.TextEdit{{NewText: "foo", Snippet: "foo"}},
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, downgraded); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+	if diff := cmp.Diff(expectedInput, candidates); diff != "" {
+		t.Fatalf("input candidates mutated: %s", diff)
+	}
+}
+
+func TestDecoder_candidatesForClient_labelDetails(t *testing.T) {
+	candidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label: "upper",
+			LabelDetails: &lang.LabelDetails{
+				Detail:      "(str string)",
+				Description: "string",
+			},
+		},
+		{
+			Label:  "lower",
+			Detail: "lower(str string) string",
+			LabelDetails: &lang.LabelDetails{
+				Detail:      "(str string)",
+				Description: "string",
+			},
+		},
+	})
+
+	d := NewDecoder()
+	caps := FullClientCapabilities()
+	caps.LabelDetailsSupport = false
+	d.SetClientCapabilities(caps)
+
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:  "upper",
+			Detail: "(str string) string",
+		},
+		{
+			Label:  "lower",
+			Detail: "lower(str string) string",
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, d.candidatesForClient(candidates)); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
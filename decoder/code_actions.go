@@ -0,0 +1,299 @@
+package decoder
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// CodeActionsAtPos returns actions which rewrite the literal value
+// of the attribute at the given position into an equivalent form
+// permitted by the attribute's constraints, such as removing duplicate
+// elements of a set or switching between object and map style keys.
+//
+// Each action consists of minimal edits, i.e. only elements or keys
+// which change are replaced.
+func (d *Decoder) CodeActionsAtPos(filename string, pos hcl.Pos, opts ...RequestOption) ([]lang.CodeAction, error) {
+	d = d.withRequestOptions(opts)
+
+	f, err := d.fileByName(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	pos = d.posForFile(filename, pos)
+
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	body, bodySchema := rootBody, d.rootSchema
+	block, blockSchema, err := d.innermostBlockAndSchema(rootBody, d.rootSchema, 0, pos)
+	if err != nil {
+		return nil, err
+	}
+	if block != nil {
+		body, bodySchema = block.Body, blockSchema
+	}
+
+	actions := make([]lang.CodeAction, 0)
+
+	for _, attr := range body.Attributes {
+		if !attr.Range().ContainsPos(pos) {
+			continue
+		}
+
+		aSchema, ok := attributeSchemaForName(bodySchema, attr.Name)
+		if !ok {
+			return actions, nil
+		}
+		constraints := ExprConstraints(aSchema.Expr)
+
+		switch e := attr.Expr.(type) {
+		case *hclsyntax.TupleConsExpr:
+			c, ok := constraints.constraintForExpr(e)
+			if !ok || !isSetConstraint(c) {
+				return actions, nil
+			}
+			if edits, ok := dedupeTupleEdits(f.Bytes, e); ok {
+				actions = append(actions, lang.CodeAction{
+					Title: "Remove duplicate elements",
+					Edits: edits,
+				})
+			}
+			if edits, ok := sortTupleEdits(f.Bytes, e); ok {
+				actions = append(actions, lang.CodeAction{
+					Title: "Sort elements",
+					Edits: edits,
+				})
+			}
+		case *hclsyntax.ObjectConsExpr:
+			if !allowsObjectAndMap(constraints) {
+				return actions, nil
+			}
+			if edits, ok := quoteKeysEdits(e); ok {
+				actions = append(actions, lang.CodeAction{
+					Title: "Convert to map (quote keys)",
+					Edits: edits,
+				})
+			}
+			if edits, ok := unquoteKeysEdits(e); ok {
+				actions = append(actions, lang.CodeAction{
+					Title: "Convert to object (unquote keys)",
+					Edits: edits,
+				})
+			}
+		}
+
+		return actions, nil
+	}
+
+	return actions, nil
+}
+
+// isSetConstraint returns true if the constraint
+// describes a set, where order and duplicates have no meaning
+func isSetConstraint(c schema.ExprConstraint) bool {
+	switch ct := c.(type) {
+	case schema.SetExpr:
+		return true
+	case schema.LiteralTypeExpr:
+		return ct.Type.IsSetType() && !ct.SkipLiteralComplexTypes
+	}
+	return false
+}
+
+// allowsObjectAndMap returns true if the constraints permit
+// both an object and a map, such that keys may be written either way
+func allowsObjectAndMap(constraints ExprConstraints) bool {
+	hasObject, hasMap := false, false
+	for _, c := range constraints {
+		switch ct := c.(type) {
+		case schema.ObjectExpr:
+			hasObject = true
+		case schema.MapExpr:
+			hasMap = true
+		case schema.LiteralTypeExpr:
+			if ct.SkipLiteralComplexTypes {
+				continue
+			}
+			if ct.Type.IsObjectType() {
+				hasObject = true
+			}
+			if ct.Type.IsMapType() {
+				hasMap = true
+			}
+		}
+	}
+	return hasObject && hasMap
+}
+
+// dedupeTupleEdits returns edits removing elements which are
+// textually identical to an earlier element, along with
+// the separator preceding each such element
+func dedupeTupleEdits(src []byte, expr *hclsyntax.TupleConsExpr) ([]lang.TextEdit, bool) {
+	edits := make([]lang.TextEdit, 0)
+	seen := make(map[string]bool, 0)
+
+	for i, elemExpr := range expr.Exprs {
+		text := string(elemExpr.Range().SliceBytes(src))
+		if !seen[text] {
+			seen[text] = true
+			continue
+		}
+
+		edits = append(edits, lang.TextEdit{
+			Range: hcl.Range{
+				Filename: elemExpr.Range().Filename,
+				Start:    expr.Exprs[i-1].Range().End,
+				End:      elemExpr.Range().End,
+			},
+			NewText: "",
+			Snippet: "",
+		})
+	}
+
+	return edits, len(edits) > 0
+}
+
+// sortTupleEdits returns edits which sort the elements, provided
+// that all elements are string literals or all are number literals
+func sortTupleEdits(src []byte, expr *hclsyntax.TupleConsExpr) ([]lang.TextEdit, bool) {
+	if len(expr.Exprs) < 2 {
+		return nil, false
+	}
+
+	vals := make([]cty.Value, len(expr.Exprs))
+	for i, elemExpr := range expr.Exprs {
+		val, ok := literalValueOfElem(elemExpr)
+		if !ok || (i > 0 && !val.Type().Equals(vals[0].Type())) {
+			return nil, false
+		}
+		vals[i] = val
+	}
+	if vals[0].Type() != cty.String && vals[0].Type() != cty.Number {
+		return nil, false
+	}
+
+	order := make([]int, len(vals))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return literalLess(vals[order[i]], vals[order[j]])
+	})
+
+	edits := make([]lang.TextEdit, 0)
+	for i, j := range order {
+		if i == j {
+			continue
+		}
+		newText := string(expr.Exprs[j].Range().SliceBytes(src))
+		edits = append(edits, lang.TextEdit{
+			Range:   expr.Exprs[i].Range(),
+			NewText: newText,
+			Snippet: escapeSnippetText(newText),
+		})
+	}
+
+	return edits, len(edits) > 0
+}
+
+func literalValueOfElem(expr hclsyntax.Expression) (cty.Value, bool) {
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		return e.Val, e.Val.IsKnown() && !e.Val.IsNull()
+	case *hclsyntax.TemplateExpr:
+		return stringValFromTemplateExpr(e)
+	}
+	return cty.NilVal, false
+}
+
+func literalLess(a, b cty.Value) bool {
+	if a.Type() == cty.Number {
+		return a.AsBigFloat().Cmp(b.AsBigFloat()) < 0
+	}
+	return a.AsString() < b.AsString()
+}
+
+// quoteKeysEdits returns edits which turn bare (object-style)
+// keys into quoted (map-style) keys
+func quoteKeysEdits(expr *hclsyntax.ObjectConsExpr) ([]lang.TextEdit, bool) {
+	edits := make([]lang.TextEdit, 0)
+
+	for _, item := range expr.Items {
+		keyExpr, ok := item.KeyExpr.(*hclsyntax.ObjectConsKeyExpr)
+		if !ok || keyExpr.ForceNonLiteral {
+			continue
+		}
+		name := hcl.ExprAsKeyword(keyExpr.Wrapped)
+		if name == "" {
+			continue
+		}
+
+		newText := `"` + name + `"`
+		edits = append(edits, lang.TextEdit{
+			Range:   keyExpr.Range(),
+			NewText: newText,
+			Snippet: newText,
+		})
+	}
+
+	return edits, len(edits) > 0
+}
+
+// unquoteKeysEdits returns edits which turn quoted (map-style)
+// keys into bare (object-style) keys, where the key
+// is a valid identifier
+func unquoteKeysEdits(expr *hclsyntax.ObjectConsExpr) ([]lang.TextEdit, bool) {
+	edits := make([]lang.TextEdit, 0)
+
+	for _, item := range expr.Items {
+		keyExpr, ok := item.KeyExpr.(*hclsyntax.ObjectConsKeyExpr)
+		if !ok {
+			continue
+		}
+		tplExpr, ok := keyExpr.Wrapped.(*hclsyntax.TemplateExpr)
+		if !ok {
+			continue
+		}
+		val, ok := stringValFromTemplateExpr(tplExpr)
+		if !ok {
+			continue
+		}
+		name := val.AsString()
+		if !hclsyntax.ValidIdentifier(name) || isReservedKeyName(name) {
+			continue
+		}
+
+		edits = append(edits, lang.TextEdit{
+			Range:   keyExpr.Range(),
+			NewText: name,
+			Snippet: name,
+		})
+	}
+
+	return edits, len(edits) > 0
+}
+
+// isReservedKeyName returns true for names which
+// would not be parsed as a literal key when unquoted
+func isReservedKeyName(name string) bool {
+	switch name {
+	case "true", "false", "null", "for":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var codeActionsSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"zones": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.SetExpr{
+					Elem: schema.LiteralTypeOnly(cty.String),
+				},
+			},
+		},
+		"ports": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.Set(cty.Number)),
+		},
+		"names": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.ListExpr{
+					Elem: schema.LiteralTypeOnly(cty.String),
+				},
+			},
+		},
+		"tags": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.ObjectExpr{
+					Attributes: schema.ObjectExprAttributes{
+						"name": {Expr: schema.LiteralTypeOnly(cty.String)},
+					},
+				},
+				schema.MapExpr{
+					Elem: schema.LiteralTypeOnly(cty.String),
+				},
+			},
+		},
+		"labels": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.MapExpr{
+					Elem: schema.LiteralTypeOnly(cty.String),
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_CodeActionsAtPos(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      string
+		expected map[string]string
+	}{
+		{
+			"set with duplicates",
+			`zones = ["b", "a", "b"]
+`,
+			map[string]string{
+				"Remove duplicate elements": `zones = ["b", "a"]
+`,
+				"Sort elements": `zones = ["a", "b", "b"]
+`,
+			},
+		},
+		{
+			"multi-line set with duplicates",
+			`zones = [
+  "a",
+  "a",
+  "b",
+]
+`,
+			map[string]string{
+				"Remove duplicate elements": `zones = [
+  "a",
+  "b",
+]
+`,
+			},
+		},
+		{
+			"set of references",
+			`zones = [var.a, var.a]
+`,
+			map[string]string{
+				"Remove duplicate elements": `zones = [var.a]
+`,
+			},
+		},
+		{
+			"set type of numbers",
+			`ports = [443, 80, 8080]
+`,
+			map[string]string{
+				"Sort elements": `ports = [80, 443, 8080]
+`,
+			},
+		},
+		{
+			"list is left alone",
+			`names = ["b", "a", "b"]
+`,
+			map[string]string{},
+		},
+		{
+			"object with bare keys",
+			`tags = {
+  name = "foo"
+  "env" = "dev"
+}
+`,
+			map[string]string{
+				"Convert to map (quote keys)": `tags = {
+  "name" = "foo"
+  "env" = "dev"
+}
+`,
+				"Convert to object (unquote keys)": `tags = {
+  name = "foo"
+  env = "dev"
+}
+`,
+			},
+		},
+		{
+			"quoted key which is not an identifier",
+			`tags = {
+  "foo-bar" = "dev"
+  "1st" = "dev"
+}
+`,
+			map[string]string{
+				"Convert to object (unquote keys)": `tags = {
+  foo-bar = "dev"
+  "1st" = "dev"
+}
+`,
+			},
+		},
+		{
+			"map only",
+			`labels = {
+  name = "foo"
+}
+`,
+			map[string]string{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(codeActionsSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pos := hcl.Pos{Line: 1, Column: 3, Byte: 2}
+			actions, err := d.CodeActionsAtPos("test.tf", pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results := make(map[string]string, 0)
+			for _, action := range actions {
+				results[action.Title] = applyTextEdits(tc.cfg, action.Edits)
+			}
+
+			if diff := cmp.Diff(tc.expected, results); diff != "" {
+				t.Fatalf("unexpected actions: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package decoder
+
+import (
+	"math/big"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// SetMaxIndexTargets sets the upper bound of numeric indexes
+// for which element targets (e.g. aws_instance.web[0]) are
+// synthesized from reference targets of list type, such that
+// references to individual elements resolve even if they were
+// not collected as nested targets. Default is 100.
+//
+// Splat targets (e.g. aws_instance.web[*].id) are synthesized
+// regardless of the bound.
+func (d *Decoder) SetMaxIndexTargets(max uint) {
+	d.maxIndexTargets = max
+}
+
+// exprReference represents a reference within an expression
+type exprReference struct {
+	Addr  lang.Address
+	Range hcl.Range
+
+	// IndexRole describes whether the reference is indexed
+	// by a variable key, or is (part of) such key
+	IndexRole indexRole
+}
+
+// exprReferences returns all references within the expression.
+//
+// Splat expressions (such as aws_instance.web[*].id) are represented
+// as a single reference using lang.SplatStep, in place of their
+// source traversal.
+func exprReferences(expr hcl.Expression) []exprReference {
+	splats := make(map[hcl.Range]exprReference, 0)
+	if hsExpr, ok := expr.(hclsyntax.Expression); ok {
+		hclsyntax.VisitAll(hsExpr, func(node hclsyntax.Node) hcl.Diagnostics {
+			splat, ok := node.(*hclsyntax.SplatExpr)
+			if !ok {
+				return nil
+			}
+			addr, srcRng, ok := splatExprAddress(splat)
+			if ok {
+				splats[srcRng] = exprReference{
+					Addr:  addr,
+					Range: splat.Range(),
+				}
+			}
+			return nil
+		})
+	}
+
+	roles := indexRolesInExpr(expr)
+
+	refs := make([]exprReference, 0)
+	for _, traversal := range expr.Variables() {
+		if splatRef, ok := splats[traversal.SourceRange()]; ok {
+			refs = append(refs, splatRef)
+			continue
+		}
+
+		addr, err := lang.TraversalToAddress(traversal)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, exprReference{
+			Addr:      addr,
+			Range:     traversal.SourceRange(),
+			IndexRole: roles[traversal.SourceRange()],
+		})
+	}
+
+	return refs
+}
+
+// splatExprAddress returns address of a splat expression
+// in the form of source[*].each along with range of the source
+func splatExprAddress(splat *hclsyntax.SplatExpr) (lang.Address, hcl.Range, bool) {
+	src, ok := splat.Source.(*hclsyntax.ScopeTraversalExpr)
+	if !ok {
+		return nil, hcl.Range{}, false
+	}
+
+	addr, err := lang.TraversalToAddress(src.Traversal)
+	if err != nil {
+		return nil, hcl.Range{}, false
+	}
+	addr = append(addr, lang.SplatStep{})
+
+	switch each := splat.Each.(type) {
+	case *hclsyntax.AnonSymbolExpr:
+	case *hclsyntax.RelativeTraversalExpr:
+		if _, ok := each.Source.(*hclsyntax.AnonSymbolExpr); !ok {
+			return nil, hcl.Range{}, false
+		}
+		eachAddr, err := lang.TraversalToAddress(each.Traversal)
+		if err != nil {
+			return nil, hcl.Range{}, false
+		}
+		addr = append(addr, eachAddr...)
+	default:
+		return nil, hcl.Range{}, false
+	}
+
+	return addr, src.Range(), true
+}
+
+func (refs ReferenceTargets) containsAddr(addr lang.Address) bool {
+	found := false
+	refs.DeepWalk(func(ref lang.ReferenceTarget) error {
+		if Address(ref.Addr).Equals(Address(addr)) {
+			found = true
+			return StopWalking
+		}
+		return nil
+	})
+	return found
+}
+
+// referenceTargetForOrigin returns the first target of the origin
+// among the given targets, or an element target synthesized
+// from a target of collection type. Origins referring to
+// (targets nested under) an alias resolve via the canonical address.
+func (d *Decoder) referenceTargetForOrigin(targets ReferenceTargets, origin lang.ReferenceOrigin) (lang.ReferenceTarget, error) {
+	ref, err := d.referenceTargetForAddr(targets, origin)
+	if err == nil {
+		return ref, nil
+	}
+
+	canonical, _, ok := targets.canonicalAddr(origin.Addr)
+	if !ok {
+		return lang.ReferenceTarget{}, err
+	}
+	canonicalOrigin := origin.Copy()
+	canonicalOrigin.Addr = canonical
+	ref, cErr := d.referenceTargetForAddr(targets, canonicalOrigin)
+	if cErr != nil {
+		return lang.ReferenceTarget{}, err
+	}
+	return ref, nil
+}
+
+func (d *Decoder) referenceTargetForAddr(targets ReferenceTargets, origin lang.ReferenceOrigin) (lang.ReferenceTarget, error) {
+	ref, err := targets.FirstTargetableBy(origin)
+	if err == nil {
+		return ref, nil
+	}
+
+	synthesized, ok := d.synthesizedTargetForAddr(targets, origin.Addr)
+	if ok && ReferenceTarget(synthesized).IsTargetableBy(origin) {
+		return synthesized, nil
+	}
+
+	return lang.ReferenceTarget{}, err
+}
+
+// synthesizedTargetForAddr returns a target for the given address
+// derived from the closest target of collection type, such as
+// aws_instance.web[0].id or aws_instance.web[*].id derived
+// from aws_instance.web of type list of objects
+func (d *Decoder) synthesizedTargetForAddr(targets ReferenceTargets, addr lang.Address) (lang.ReferenceTarget, bool) {
+	var closestTarget *lang.ReferenceTarget
+	targets.DeepWalk(func(ref lang.ReferenceTarget) error {
+		if ref.Type == cty.NilType || len(ref.Addr) >= len(addr) {
+			return nil
+		}
+		if closestTarget != nil && len(closestTarget.Addr) >= len(ref.Addr) {
+			return nil
+		}
+		if Address(ref.Addr).Equals(Address(addr).FirstSteps(uint(len(ref.Addr)))) {
+			closestTarget = &ref
+		}
+		return nil
+	})
+	if closestTarget == nil {
+		return lang.ReferenceTarget{}, false
+	}
+
+	steps := addr[len(closestTarget.Addr):]
+	if !d.isWithinIndexBound(steps) {
+		return lang.ReferenceTarget{}, false
+	}
+
+	typ, ok := typeOfAddressSteps(closestTarget.Type, steps)
+	if !ok {
+		return lang.ReferenceTarget{}, false
+	}
+
+	return lang.ReferenceTarget{
+		Addr:        addr.Copy(),
+		ScopeId:     closestTarget.ScopeId,
+		RangePtr:    closestTarget.RangePtr,
+		Type:        typ,
+		Description: closestTarget.Description,
+		Sensitive:   closestTarget.Sensitive,
+		WriteOnly:   closestTarget.WriteOnly,
+	}, true
+}
+
+// isAbstractAddress returns true if the address refers to any element
+// of a collection (via a splat) rather than a particular one, such as
+// the address of a map element keyed by an expression (var.name)
+func isAbstractAddress(addr lang.Address) bool {
+	for _, step := range addr {
+		if _, ok := step.(lang.SplatStep); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithinIndexBound returns true if the steps address elements
+// of a collection (via a splat or numeric index within the bound
+// of synthesized index targets)
+func (d *Decoder) isWithinIndexBound(steps lang.Address) bool {
+	isElement := false
+	for _, step := range steps {
+		switch s := step.(type) {
+		case lang.SplatStep:
+			isElement = true
+		case lang.IndexStep:
+			if s.Key.Type() != cty.Number || !s.Key.IsKnown() {
+				continue
+			}
+			idx, accuracy := s.Key.AsBigFloat().Int64()
+			if accuracy != big.Exact || idx < 0 || idx >= int64(d.maxIndexTargets) {
+				return false
+			}
+			isElement = true
+		}
+	}
+	return isElement
+}
@@ -0,0 +1,60 @@
+package decoder
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// CommentStyle represents a style of comments of the native syntax,
+// such that a set of styles can be combined via bitwise OR
+type CommentStyle uint
+
+const (
+	// HashComment represents single-line comments starting with #
+	HashComment CommentStyle = 1 << iota
+
+	// SlashComment represents single-line comments starting with //
+	SlashComment
+
+	// BlockComment represents comments enclosed in /* and */
+	BlockComment
+
+	// AllCommentStyles represents all styles of comments
+	AllCommentStyles = HashComment | SlashComment | BlockComment
+)
+
+// SetCommentStyles sets which styles of comments are recognized
+// by comment-aware features, such as leading comments of symbols
+// (see SymbolTrivia), for dialects which discourage some of them.
+// All styles are recognized by default.
+//
+// Comments of other styles are still valid syntax, but are treated
+// as any other token, e.g. not included in ranges of symbols.
+func (d *Decoder) SetCommentStyles(styles CommentStyle) {
+	d.commentStyles = styles
+}
+
+// commentStyleOfToken returns style of the comment token
+func commentStyleOfToken(token hclsyntax.Token) (CommentStyle, bool) {
+	if token.Type != hclsyntax.TokenComment {
+		return 0, false
+	}
+
+	switch {
+	case bytes.HasPrefix(token.Bytes, []byte("#")):
+		return HashComment, true
+	case bytes.HasPrefix(token.Bytes, []byte("//")):
+		return SlashComment, true
+	case bytes.HasPrefix(token.Bytes, []byte("/*")):
+		return BlockComment, true
+	}
+	return 0, false
+}
+
+// isRecognizedComment returns true if the token
+// is a comment of any of the given styles
+func isRecognizedComment(token hclsyntax.Token, styles CommentStyle) bool {
+	style, ok := commentStyleOfToken(token)
+	return ok && styles&style != 0
+}
@@ -0,0 +1,27 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// CompleteExpression returns completion candidates for the given
+// position within a standalone expression in the native syntax,
+// such as a value entered into a query bar, based on the given
+// constraints, targets of references and signatures of functions
+// which can be called.
+//
+// The position and ranges of candidates' edits are relative
+// to the beginning of src and the filename of the ranges is empty.
+//
+// Unlike ValidateExpression, syntax errors do not prevent completion,
+// as the expression is expected to be incomplete while it's typed.
+func CompleteExpression(src []byte, pos hcl.Pos, constraints schema.ExprConstraints, refTargets lang.ReferenceTargets, funcs map[string]schema.FunctionSignature) (lang.Candidates, error) {
+	d, _ := newExpressionDecoder(src, constraints, funcs)
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return refTargets
+	})
+
+	return d.CandidatesAtPos("", pos)
+}
@@ -0,0 +1,90 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCompleteExpression(t *testing.T) {
+	refTargets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "region"},
+			},
+			Type: cty.String,
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "replicas"},
+			},
+			Type: cty.Number,
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		src                string
+		pos                hcl.Pos
+		constraints        schema.ExprConstraints
+		expectedCandidates string
+	}{
+		{
+			"empty expression",
+			``,
+			hcl.InitialPos,
+			schema.ExprConstraints{
+				schema.KeywordExpr{Keyword: "disabled"},
+				schema.KeywordExpr{Keyword: "enabled"},
+			},
+			`
+complete: true
+candidates: 2
+---
+label: "disabled"
+kind: KeywordCandidateKind
+detail: "keyword"
+edit: :1,1-1,1 "disabled"
+---
+label: "enabled"
+kind: KeywordCandidateKind
+detail: "keyword"
+edit: :1,1-1,1 "enabled"
+`,
+		},
+		{
+			"partial reference",
+			`var.`,
+			hcl.Pos{Line: 1, Column: 5, Byte: 4},
+			schema.ExprConstraints{
+				schema.TraversalExpr{OfType: cty.String},
+			},
+			`
+complete: true
+candidates: 1
+---
+label: "var.region"
+kind: TraversalCandidateKind
+detail: "string"
+edit: :1,1-1,5 "var.region"
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			candidates, err := CompleteExpression([]byte(tc.src), tc.pos, tc.constraints, refTargets, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
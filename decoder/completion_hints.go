@@ -0,0 +1,42 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// topLevelCompletionHints returns completion hints of the body
+// if it is the root body, i.e. at the top level of a file
+func topLevelCompletionHints(bodySchema *schema.BodySchema, nestingLvl int) *schema.CompletionHints {
+	if nestingLvl > 0 {
+		return nil
+	}
+	return bodySchema.TopLevelCompletionHints
+}
+
+func fileTemplateCandidate(tpl schema.FileTemplate, editRng hcl.Range) lang.Candidate {
+	snippet := tpl.Snippet
+	if snippet == "" {
+		snippet = escapeSnippetText(tpl.Text)
+	}
+
+	return lang.Candidate{
+		Label:       tpl.Name,
+		Detail:      "template",
+		Description: tpl.Description,
+		Kind:        lang.TemplateCandidateKind,
+		TextEdit: lang.TextEdit{
+			NewText: tpl.Text,
+			Snippet: snippet,
+			Range:   editRng,
+		},
+	}
+}
+
+// isBodyEmpty returns true if the body contains
+// no attributes or blocks (but possibly comments)
+func isBodyEmpty(body *hclsyntax.Body) bool {
+	return len(body.Attributes) == 0 && len(body.Blocks) == 0
+}
@@ -0,0 +1,197 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var completionHintsSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"alpha": {
+			Body: &schema.BodySchema{
+				Blocks: map[string]*schema.BlockSchema{
+					"nested": {},
+					"other":  {},
+				},
+			},
+		},
+		"beta":  {},
+		"gamma": {},
+	},
+	TopLevelCompletionHints: &schema.CompletionHints{
+		Weights: map[string]float64{
+			"gamma":  2,
+			"beta":   1,
+			"nested": 5,
+		},
+		Templates: []schema.FileTemplate{
+			{
+				Name:        "starter",
+				Description: lang.PlainText("Common blocks"),
+				Text:        "gamma {\n}\n",
+				Snippet:     "gamma {\n  ${1}\n}\n",
+			},
+		},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_topLevelCompletionHints(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cfg                string
+		pos                hcl.Pos
+		expectedCandidates string
+	}{
+		{
+			"empty file",
+			``,
+			hcl.InitialPos,
+			`
+complete: true
+candidates: 4
+---
+label: "gamma"
+kind: BlockCandidateKind
+detail: "Block"
+score: 2
+edit: test.tf:1,1-1,1 "gamma" (snippet "gamma {\n  ${1}\n}")
+---
+label: "beta"
+kind: BlockCandidateKind
+detail: "Block"
+score: 1
+edit: test.tf:1,1-1,1 "beta" (snippet "beta {\n  ${1}\n}")
+---
+label: "alpha"
+kind: BlockCandidateKind
+detail: "Block"
+edit: test.tf:1,1-1,1 "alpha" (snippet "alpha {\n  ${1}\n}")
+---
+label: "starter"
+kind: TemplateCandidateKind
+detail: "template"
+description (PlainTextKind): "Common blocks"
+edit: test.tf:1,1-1,1 "gamma {\n}\n" (snippet "gamma {\n  ${1}\n}\n")
+`,
+		},
+		{
+			"top level of non-empty file",
+			`beta {}
+
+`,
+			hcl.Pos{Line: 2, Column: 1, Byte: 8},
+			`
+complete: true
+candidates: 3
+---
+label: "gamma"
+kind: BlockCandidateKind
+detail: "Block"
+score: 2
+edit: test.tf:2,1-2,1 "gamma" (snippet "gamma {\n  ${1}\n}")
+---
+label: "beta"
+kind: BlockCandidateKind
+detail: "Block"
+score: 1
+edit: test.tf:2,1-2,1 "beta" (snippet "beta {\n  ${1}\n}")
+---
+label: "alpha"
+kind: BlockCandidateKind
+detail: "Block"
+edit: test.tf:2,1-2,1 "alpha" (snippet "alpha {\n  ${1}\n}")
+`,
+		},
+		{
+			"nested body",
+			`alpha {
+  
+}
+`,
+			hcl.Pos{Line: 2, Column: 3, Byte: 10},
+			`
+complete: true
+candidates: 2
+---
+label: "nested"
+kind: BlockCandidateKind
+detail: "Block"
+edit: test.tf:2,3-2,3 "nested" (snippet "nested {\n  ${1}\n}")
+---
+label: "other"
+kind: BlockCandidateKind
+detail: "Block"
+edit: test.tf:2,3-2,3 "other" (snippet "other {\n  ${1}\n}")
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(completionHintsSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_completionHintsWithMaxCandidates(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"name": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+		},
+		Blocks: map[string]*schema.BlockSchema{
+			"alpha": {},
+			"beta":  {},
+		},
+		TopLevelCompletionHints: &schema.CompletionHints{
+			Weights: map[string]float64{"beta": 1},
+		},
+	})
+	d.maxCandidates = 2
+
+	f, _ := hclsyntax.ParseConfig([]byte(""), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.InitialPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	langtest.AssertCandidates(t, `
+complete: false
+candidates: 2
+---
+label: "name"
+kind: AttributeCandidateKind
+detail: "optional, string"
+edit: test.tf:1,1-1,1 "name" (snippet "name = \"${1:value}\"")
+---
+label: "beta"
+kind: BlockCandidateKind
+detail: "Block"
+score: 1
+edit: test.tf:1,1-1,1 "beta" (snippet "beta {\n  ${1}\n}")
+`, candidates)
+}
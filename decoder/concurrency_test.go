@@ -0,0 +1,124 @@
+package decoder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var concurrencySchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"locals": {
+			Body: &schema.BodySchema{
+				AnyAttribute: &schema.AttributeSchema{
+					Expr: schema.ExprConstraints{
+						schema.TraversalExpr{OfType: cty.DynamicPseudoType},
+						schema.LiteralTypeExpr{Type: cty.DynamicPseudoType},
+					},
+					Address: &schema.AttributeAddrSchema{
+						Steps: []schema.AddrStep{
+							schema.StaticStep{Name: "local"},
+							schema.AttrNameStep{},
+						},
+						AsReference: true,
+						ScopeId:     lang.ScopeId("local"),
+					},
+					SemanticToken: &schema.SemanticToken{
+						Type:      "local-1",
+						Modifiers: []string{"mod-1"},
+					},
+				},
+			},
+		},
+	},
+}
+
+// TestDecoder_concurrentQueriesAndUpdates is expected
+// to be run with -race, which reports any unsynchronized access
+func TestDecoder_concurrentQueriesAndUpdates(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(concurrencySchema)
+
+	cfg := func(i int) []byte {
+		return []byte(fmt.Sprintf(`locals {
+  foo = "foo-%d"
+  bar = local.foo
+}
+`, i))
+	}
+	load := func(i int) {
+		f, _ := hclsyntax.ParseConfig(cfg(i), "test.tf", hcl.InitialPos)
+		err := d.LoadFile("test.tf", f)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	load(0)
+
+	// completion of the value includes functions
+	funcsFile, _ := hclsyntax.ParseConfig([]byte("locals {\n  baz = \n}\n"), "funcs.tf", hcl.InitialPos)
+	err := d.LoadFile("funcs.tf", funcsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	funcsPos := hcl.Pos{Line: 2, Column: 9, Byte: 17}
+
+	pos := hcl.Pos{Line: 3, Column: 13, Byte: 40}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				d.CandidatesAtPos("test.tf", pos)
+				d.CandidatesAtPos("funcs.tf", funcsPos)
+				d.HoverAtPos("test.tf", pos)
+				d.ValidateFile("test.tf")
+				d.ValidateFileWithFixes("test.tf")
+				d.ValidateFileWithResultID(context.Background(), "test.tf", "")
+				d.DefinitionAtPos("test.tf", pos)
+				d.LinksInFile("test.tf")
+				d.InnermostReferenceTargetAtPos("test.tf", pos)
+				d.SemanticTokensInFile("test.tf")
+				d.SemanticTokensLegend()
+				d.SymbolsInFile("test.tf")
+				d.CollectReferenceTargets()
+				d.CollectReferenceOrigins()
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 50; j++ {
+			load(j)
+			d.SetSchema(concurrencySchema)
+			d.PatchSchema(func(s *schema.BodySchema) error {
+				return nil
+			})
+			targets := lang.ReferenceTargets{}
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return targets
+			})
+			d.SetReferenceOriginReader(func() lang.ReferenceOrigins {
+				return lang.ReferenceOrigins{}
+			})
+			d.SetFunctions(map[string]schema.FunctionSignature{
+				fmt.Sprintf("func%d", j): {ReturnType: cty.String},
+			})
+			d.RegisterSemanticTokenType(fmt.Sprintf("local-%d", j))
+			d.RegisterSemanticTokenModifier(fmt.Sprintf("mod-%d", j))
+		}
+	}()
+
+	wg.Wait()
+}
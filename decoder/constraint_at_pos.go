@@ -0,0 +1,238 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ConstraintAtPos returns the expression constraint matched
+// by the innermost expression at the given position.
+//
+// Where more than one constraint matches the same expression,
+// the one which takes precedence (see ExprConstraints.ByPrecedence)
+// is returned, which is also the one used for hover.
+func (d *Decoder) ConstraintAtPos(filename string, pos hcl.Pos, opts ...RequestOption) (schema.ExprConstraint, error) {
+	d = d.withRequestOptions(opts)
+
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	body, bodySchema := rootBody, d.rootSchema
+	block, blockSchema, err := d.innermostBlockAndSchema(rootBody, d.rootSchema, 0, pos)
+	if err != nil {
+		return nil, err
+	}
+	if block != nil {
+		body, bodySchema = block.Body, blockSchema
+	}
+
+	for _, attr := range body.Attributes {
+		if !attr.Expr.Range().ContainsPos(pos) {
+			continue
+		}
+
+		aSchema, ok := attributeSchemaForName(bodySchema, attr.Name)
+		if !ok {
+			return nil, &PositionalError{
+				Filename: filename,
+				Pos:      pos,
+				Msg:      "unknown attribute " + attr.Name,
+			}
+		}
+
+		c, ok := d.constraintAtPos(attr.Expr, ExprConstraints(aSchema.Expr), 0, pos)
+		if !ok {
+			return nil, &ConstraintMismatch{attr.Expr}
+		}
+		return c, nil
+	}
+
+	return nil, &PositionalError{
+		Filename: filename,
+		Pos:      pos,
+		Msg:      "no expression found",
+	}
+}
+
+// constraintAtPos returns the constraint matched by the innermost
+// expression at the given position, or the constraint matched
+// by the outer expression if the inner one is not constrained
+func (d *Decoder) constraintAtPos(expr hclsyntax.Expression, constraints ExprConstraints, nestingLvl int, pos hcl.Pos) (schema.ExprConstraint, bool) {
+	c, ok := constraints.constraintForExpr(expr)
+	if !ok {
+		return nil, false
+	}
+	if d.isNestedTooDeep(nestingLvl + 1) {
+		return c, true
+	}
+
+	switch e := expr.(type) {
+	case *hclsyntax.TemplateWrapExpr:
+		return d.constraintAtPos(e.Wrapped, constraints, nestingLvl, pos)
+	case *hclsyntax.TupleConsExpr:
+		for i, elemExpr := range e.Exprs {
+			if !elemExpr.Range().ContainsPos(pos) {
+				continue
+			}
+			elemConstraints, ok := elemConstraintsOf(c, i)
+			if !ok {
+				break
+			}
+			if inner, ok := d.constraintAtPos(elemExpr, elemConstraints, nestingLvl+1, pos); ok {
+				return inner, true
+			}
+		}
+	case *hclsyntax.ObjectConsExpr:
+		for _, item := range e.Items {
+			if !item.ValueExpr.Range().ContainsPos(pos) {
+				continue
+			}
+			attrConstraints, ok := attrConstraintsOf(c, item.KeyExpr)
+			if !ok {
+				break
+			}
+			if inner, ok := d.constraintAtPos(item.ValueExpr, attrConstraints, nestingLvl+1, pos); ok {
+				return inner, true
+			}
+		}
+	case *hclsyntax.ForExpr:
+		if !e.ValExpr.Range().ContainsPos(pos) || isForExprSymbol(e.ValExpr, e) {
+			break
+		}
+		if valConstraints, ok := forExprValueConstraints(c, e); ok {
+			if inner, ok := d.constraintAtPos(e.ValExpr, valConstraints, nestingLvl+1, pos); ok {
+				return inner, true
+			}
+		}
+	}
+
+	return c, true
+}
+
+// constraintForExpr returns the constraint which the expression
+// matches, taking precedence of the constraints into account
+func (ec ExprConstraints) constraintForExpr(expr hclsyntax.Expression) (schema.ExprConstraint, bool) {
+	for _, c := range ec.byPrecedence() {
+		if constraintMatchesExpr(c, expr) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+func constraintMatchesExpr(constraint schema.ExprConstraint, expr hclsyntax.Expression) bool {
+	single := ExprConstraints{constraint}
+
+	switch e := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		switch c := constraint.(type) {
+		case schema.KeywordExpr:
+			return len(e.Traversal) == 1 && e.Traversal.RootName() == c.Keyword
+		case schema.TraversalExpr:
+			return true
+		case schema.TypeDeclarationExpr:
+			return len(e.Traversal) == 1
+		}
+	case *hclsyntax.SplatExpr, *hclsyntax.RelativeTraversalExpr:
+		_, ok := constraint.(schema.TraversalExpr)
+		return ok
+	case *hclsyntax.FunctionCallExpr:
+		_, ok := constraint.(schema.TypeDeclarationExpr)
+		return ok
+	case *hclsyntax.TemplateExpr:
+		if single.HasLiteralTypeOf(cty.String) {
+			return true
+		}
+		if _, ok := constraint.(schema.SizeExpr); ok {
+			return true
+		}
+		if v, ok := stringValFromTemplateExpr(e); ok {
+			return single.HasLiteralValueOf(v)
+		}
+	case *hclsyntax.TemplateWrapExpr:
+		return constraintMatchesExpr(constraint, e.Wrapped)
+	case *hclsyntax.TupleConsExpr:
+		switch constraint.(type) {
+		case schema.TupleConsExpr, schema.SetExpr, schema.ListExpr, schema.TupleExpr:
+			return true
+		}
+		if _, ok := single.LiteralTypeOfTupleExpr(); ok {
+			return true
+		}
+		_, ok := single.LiteralValueOfTupleExpr(e)
+		return ok
+	case *hclsyntax.ObjectConsExpr:
+		switch constraint.(type) {
+		case schema.ObjectExpr, schema.MapExpr:
+			return true
+		}
+		if _, ok := single.LiteralTypeOfObjectConsExpr(); ok {
+			return true
+		}
+		_, ok := single.LiteralValueOfObjectConsExpr(e)
+		return ok
+	case *hclsyntax.LiteralValueExpr:
+		return single.HasLiteralTypeOf(e.Val.Type()) || single.HasLiteralValueOf(e.Val)
+	case *hclsyntax.ForExpr:
+		return forExprMatchesConstraint(constraint, e)
+	}
+
+	return false
+}
+
+// elemConstraintsOf returns constraints of an element
+// at the given index of a tuple matching the constraint
+func elemConstraintsOf(constraint schema.ExprConstraint, idx int) (ExprConstraints, bool) {
+	switch c := constraint.(type) {
+	case schema.TupleConsExpr:
+		return ExprConstraints(c.AnyElem), true
+	case schema.SetExpr:
+		return ExprConstraints(c.Elem), true
+	case schema.ListExpr:
+		return ExprConstraints(c.Elem), true
+	case schema.TupleExpr:
+		if idx < len(c.Elems) {
+			return ExprConstraints(c.Elems[idx]), true
+		}
+	case schema.LiteralTypeExpr:
+		if elemType, ok := literalElemType(c.Type, idx); ok {
+			return ExprConstraints(schema.LiteralTypeOnly(elemType)), true
+		}
+	}
+	return ExprConstraints{}, false
+}
+
+// attrConstraintsOf returns constraints of an item with the given
+// key of an object matching the constraint
+func attrConstraintsOf(constraint schema.ExprConstraint, keyExpr hcl.Expression) (ExprConstraints, bool) {
+	switch c := constraint.(type) {
+	case schema.MapExpr:
+		return ExprConstraints(c.Elem), true
+	case schema.ObjectExpr:
+		key, _ := keyExpr.Value(nil)
+		if key.IsNull() || !key.IsWhollyKnown() || key.Type() != cty.String {
+			return ExprConstraints{}, false
+		}
+		attr, ok := c.Attributes[key.AsString()]
+		if !ok {
+			return ExprConstraints{}, false
+		}
+		return ExprConstraints(attr.Expr), true
+	case schema.LiteralTypeExpr:
+		if attrType, ok := literalAttrType(c.Type, keyExpr); ok {
+			return ExprConstraints(schema.LiteralTypeOnly(attrType)), true
+		}
+	}
+	return ExprConstraints{}, false
+}
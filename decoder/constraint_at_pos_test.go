@@ -0,0 +1,176 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var overlappingMapExpr = schema.MapExpr{
+	Name: "map of strings",
+	Elem: schema.LiteralTypeOnly(cty.String),
+}
+
+var overlappingObjectExpr = schema.ObjectExpr{
+	Attributes: schema.ObjectExprAttributes{
+		"foo": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.Number),
+		},
+	},
+}
+
+func TestDecoder_ConstraintAtPos(t *testing.T) {
+	testCases := []struct {
+		name               string
+		constraints        schema.ExprConstraints
+		pos                hcl.Pos
+		expectedConstraint schema.ExprConstraint
+		expectedErr        error
+	}{
+		{
+			"declaration order",
+			schema.ExprConstraints{overlappingMapExpr, overlappingObjectExpr},
+			hcl.Pos{Line: 1, Column: 9, Byte: 8},
+			overlappingMapExpr,
+			nil,
+		},
+		{
+			"weight over declaration order",
+			schema.ExprConstraints{
+				overlappingMapExpr,
+				schema.WeightedExpr{Expr: overlappingObjectExpr, Weight: 1},
+			},
+			hcl.Pos{Line: 1, Column: 9, Byte: 8},
+			overlappingObjectExpr,
+			nil,
+		},
+		{
+			"nested expression of weighted constraint",
+			schema.ExprConstraints{
+				overlappingMapExpr,
+				schema.WeightedExpr{Expr: overlappingObjectExpr, Weight: 1},
+			},
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			schema.LiteralTypeExpr{Type: cty.Number},
+			nil,
+		},
+		{
+			"unconstrained nested expression",
+			schema.ExprConstraints{overlappingMapExpr, overlappingObjectExpr},
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			overlappingMapExpr,
+			nil,
+		},
+		{
+			"outside of any expression",
+			schema.ExprConstraints{overlappingMapExpr},
+			hcl.Pos{Line: 1, Column: 2, Byte: 1},
+			nil,
+			&PositionalError{
+				Filename: "test.tf",
+				Pos:      hcl.Pos{Line: 1, Column: 2, Byte: 1},
+				Msg:      "no expression found",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"attr": {
+						IsOptional: true,
+						Expr:       tc.constraints,
+					},
+				},
+			})
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(`attr = { foo = 42 }
+`), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			constraint, err := d.ConstraintAtPos("test.tf", tc.pos)
+			if tc.expectedErr != nil {
+				if diff := cmp.Diff(tc.expectedErr, err); diff != "" {
+					t.Fatalf("unexpected error: %s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedConstraint, constraint, ctydebug.CmpOptions); diff != "" {
+				t.Fatalf("unexpected constraint: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_HoverAtPos_constraintPrecedence(t *testing.T) {
+	testCases := []struct {
+		name            string
+		constraints     schema.ExprConstraints
+		expectedContent lang.MarkupContent
+	}{
+		{
+			"declaration order",
+			schema.ExprConstraints{overlappingMapExpr, overlappingObjectExpr},
+			lang.Markdown("_map of strings_"),
+		},
+		{
+			"weight over declaration order",
+			schema.ExprConstraints{
+				overlappingMapExpr,
+				schema.WeightedExpr{Expr: overlappingObjectExpr, Weight: 1},
+			},
+			lang.Markdown("```\n{\n  foo = number\n}\n```\n_object_"),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"attr": {
+						IsOptional: true,
+						Expr:       tc.constraints,
+					},
+				},
+			})
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(`attr = { foo = 42 }
+`), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 1, Column: 9, Byte: 8})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedContent, data.Content); diff != "" {
+				t.Fatalf("unexpected hover content: %s", diff)
+			}
+		})
+	}
+}
@@ -12,14 +12,60 @@ import (
 )
 
 type Decoder struct {
-	files   map[string]*hcl.File
-	filesMu *sync.RWMutex
-
-	refTargetReader ReferenceTargetReader
-	refOriginReader ReferenceOriginReader
-	rootSchema      *schema.BodySchema
-	rootSchemaMu    *sync.RWMutex
-	maxCandidates   uint
+	files    map[string]*hcl.File
+	segments map[string][]FileSegment
+	filesMu  *sync.RWMutex
+
+	// redefined attributes recovered via LoadFileWithDiagnostics
+	redefinedAttrs map[string][]redefinedAttribute
+
+	// files read on demand from the file system
+	fsys    FileSystem
+	fsFiles map[string]*hcl.File
+	fsMu    *sync.Mutex
+
+	// reference readers, functions and overlay, which may be
+	// swapped while queries are being served
+	refTargetReader  ReferenceTargetReader
+	refOriginReader  ReferenceOriginReader
+	targetReader     ReferenceTargetReader
+	functions        map[string]schema.FunctionSignature
+	overlay          *RootOverlay
+	readersMu        *sync.RWMutex
+	textEditsHook    AdditionalTextEditsHook
+	originsHook      ReferenceOriginsHook
+	schemaChangeHook SchemaChangeHook
+	diagCodeURLFunc  DiagnosticCodeURLFunc
+	progressHook     ProgressHook
+	rootSchema       *schema.BodySchema
+	rootSchemaMu     *sync.RWMutex
+	schemaRevision   uint64
+	maxCandidates    uint
+	maxNestingDepth  uint
+	maxIndexTargets  uint
+	clientCaps       ClientCapabilities
+	symbolTrivia     SymbolTrivia
+	commentStyles    CommentStyle
+	addrFormat       lang.AddressFormat
+	objectItemSep    lang.ObjectItemSeparator
+	numberFormat     lang.NumberFormat
+	features         Features
+	validationOpts   ValidationOptions
+	refCandidateOpts ReferenceCandidateOptions
+
+	// whether the decoder serves a single request,
+	// with reference readers read once (see withRequestOptions)
+	isRequest bool
+
+	// codes of diagnostics recorded during validation
+	// (see withDiagnosticCodes), or nil where not requested
+	diagCodes diagnosticCodes
+
+	// custom (dialect-specific) semantic token types and modifiers,
+	// which may be registered while queries are being served
+	customTokenTypes     []string
+	customTokenModifiers []string
+	tokensMu             *sync.RWMutex
 
 	// UTM parameters for docs URLs
 	// utm_source parameter, typically language server identification
@@ -33,16 +79,48 @@ type Decoder struct {
 type ReferenceTargetReader func() lang.ReferenceTargets
 type ReferenceOriginReader func() lang.ReferenceOrigins
 
+// AdditionalTextEditsHook represents a function which is called for
+// every completion candidate (in a given file) and can return
+// edits to be made elsewhere in the file when the candidate is selected,
+// such as inserting a supporting block at the top of the file.
+type AdditionalTextEditsHook func(filename string, candidate lang.Candidate) []lang.TextEdit
+
 // NewDecoder creates a new Decoder
 //
 // Decoder is safe for use without any schema, but configuration files are loaded
 // via LoadFile and (optionally) schema is set via SetSchema.
+//
+// Decoder is safe for concurrent use. Any number of queries
+// (e.g. CandidatesAtPos, HoverAtPos or ValidateFile) can be served
+// concurrently while files are (re)loaded via LoadFile, the schema
+// is replaced via SetSchema or PatchSchema, reference readers
+// are replaced via SetReferenceTargetReader or SetReferenceOriginReader
+// and functions are replaced via SetFunctions.
+// Each of these swaps is atomic, i.e. a query observes either the old
+// or the new schema, functions and reference targets and origins
+// (which are read once per query), but a query may observe e.g.
+// a new file along with an old schema if both are swapped
+// while it is in progress.
+//
+// Any other settings (such as SetFeatures or SetAddressFormat) are expected
+// to be set before the Decoder starts serving queries. Some of them
+// can be overridden for individual queries via RequestOption.
 func NewDecoder() *Decoder {
 	return &Decoder{
-		rootSchemaMu:  &sync.RWMutex{},
-		files:         make(map[string]*hcl.File, 0),
-		filesMu:       &sync.RWMutex{},
-		maxCandidates: 100,
+		rootSchemaMu:    &sync.RWMutex{},
+		readersMu:       &sync.RWMutex{},
+		tokensMu:        &sync.RWMutex{},
+		files:           make(map[string]*hcl.File, 0),
+		segments:        make(map[string][]FileSegment, 0),
+		redefinedAttrs:  make(map[string][]redefinedAttribute, 0),
+		filesMu:         &sync.RWMutex{},
+		fsFiles:         make(map[string]*hcl.File, 0),
+		fsMu:            &sync.Mutex{},
+		maxCandidates:   100,
+		maxNestingDepth: 100,
+		maxIndexTargets: 100,
+		clientCaps:      FullClientCapabilities(),
+		commentStyles:   AllCommentStyles,
 	}
 }
 
@@ -51,20 +129,155 @@ func NewDecoder() *Decoder {
 // This is useful for progressive enhancement experience, where a
 // Decoder without schema can provide limited functionality (e.g. symbols), and
 // the schema can be gradually enriched (e.g. Terraform core -> providers).
+//
+// See PatchSchema for modifying parts of the schema in use.
 func (d *Decoder) SetSchema(schema *schema.BodySchema) {
 	d.rootSchemaMu.Lock()
-	defer d.rootSchemaMu.Unlock()
 	d.rootSchema = schema
+	d.schemaRevision = nextSchemaRevision()
+	d.rootSchemaMu.Unlock()
+
+	d.schemaChanged()
+}
+
+// SetFunctions sets signatures of functions which can be called
+// within expressions, such that the type of the returned value
+// is known, e.g. when completing keys(var.map)[0].
+//
+// Functions can be replaced at any time, including while queries
+// are being served.
+func (d *Decoder) SetFunctions(functions map[string]schema.FunctionSignature) {
+	d.readersMu.Lock()
+	defer d.readersMu.Unlock()
+	d.functions = functions
+}
+
+// functionSignature returns signature of the function of the given name,
+// preferring functions of the overlay over the ones set via SetFunctions
+func (d *Decoder) functionSignature(name string) (schema.FunctionSignature, bool) {
+	d.readersMu.RLock()
+	overlay, functions := d.overlay, d.functions
+	d.readersMu.RUnlock()
+
+	if overlay != nil {
+		if sig, ok := overlay.Functions[name]; ok {
+			return sig, true
+		}
+	}
+	sig, ok := functions[name]
+	return sig, ok
+}
+
+// functionNames returns sorted names of all known functions,
+// including functions of the overlay
+func (d *Decoder) functionNames() []string {
+	d.readersMu.RLock()
+	overlay, functions := d.overlay, d.functions
+	d.readersMu.RUnlock()
+
+	names := make(map[string]bool, len(functions))
+	for name := range functions {
+		names[name] = true
+	}
+	if overlay != nil {
+		for name := range overlay.Functions {
+			names[name] = true
+		}
+	}
+
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	return sortedNames
+}
+
+// SetAddressFormat sets how addresses of reference targets
+// are rendered in completion candidates, hover and rename edits.
+func (d *Decoder) SetAddressFormat(format lang.AddressFormat) {
+	d.addrFormat = format
+}
+
+// SetObjectItemSeparator sets the separator between keys and values
+// of object and map items in completion candidates, e.g. for dialects
+// which prefer key : value. Both separators are always accepted on input.
+func (d *Decoder) SetObjectItemSeparator(sep lang.ObjectItemSeparator) {
+	d.objectItemSep = sep
 }
 
+// SetNumberFormat sets how numbers are rendered in hover
+// and as default values in completion candidates.
+//
+// Decoders of different paths (see MultiRootDecoder.RegisterPath)
+// can use different formats.
+func (d *Decoder) SetNumberFormat(format lang.NumberFormat) {
+	d.numberFormat = format
+}
+
+// SetReferenceTargetReader sets the reader of reference targets,
+// which can be replaced at any time, including while queries
+// are being served.
 func (d *Decoder) SetReferenceTargetReader(f ReferenceTargetReader) {
-	d.refTargetReader = f
+	d.readersMu.Lock()
+	defer d.readersMu.Unlock()
+	d.targetReader = f
+	d.refTargetReader = overlayTargetReader(f, d.overlay)
 }
 
+// SetReferenceOriginReader sets the reader of reference origins,
+// which can be replaced at any time, including while queries
+// are being served.
 func (d *Decoder) SetReferenceOriginReader(f ReferenceOriginReader) {
+	d.readersMu.Lock()
+	defer d.readersMu.Unlock()
 	d.refOriginReader = f
 }
 
+// referenceTargetReader returns the reader of reference targets
+// which is in use at the time of the call, or nil.
+//
+// Within a request (see withRequestOptions) the reader is the one
+// in use when the request started, which reads targets only once.
+func (d *Decoder) referenceTargetReader() ReferenceTargetReader {
+	d.readersMu.RLock()
+	defer d.readersMu.RUnlock()
+	return d.refTargetReader
+}
+
+// referenceOriginReader returns the reader of reference origins
+// which is in use at the time of the call, or nil.
+func (d *Decoder) referenceOriginReader() ReferenceOriginReader {
+	d.readersMu.RLock()
+	defer d.readersMu.RUnlock()
+	return d.refOriginReader
+}
+
+// SetMaxNestingDepth sets the maximum depth of nested blocks,
+// and of nested expressions within an attribute, which the decoder
+// descends into when providing completion, hover or symbols.
+//
+// Anything nested deeper is ignored, such that pathological
+// (e.g. generated) configuration results in partial data
+// rather than excessive resource usage. Default is 100.
+func (d *Decoder) SetMaxNestingDepth(depth uint) {
+	d.maxNestingDepth = depth
+}
+
+func (d *Decoder) isNestedTooDeep(nestingLvl int) bool {
+	return nestingLvl > int(d.maxNestingDepth)
+}
+
+// SetAdditionalTextEditsHook sets a hook to attach additional
+// text edits to completion candidates.
+//
+// Edits which overlap with the main edit of the candidate, or with
+// each other, are discarded, as clients cannot apply them reliably.
+func (d *Decoder) SetAdditionalTextEditsHook(f AdditionalTextEditsHook) {
+	d.textEditsHook = f
+}
+
 func (d *Decoder) SetUtmSource(src string) {
 	d.utmSource = src
 }
@@ -93,6 +306,9 @@ func (d *Decoder) LoadFile(filename string, f *hcl.File) error {
 	}
 
 	d.files[filename] = f
+	delete(d.segments, filename)
+	delete(d.redefinedAttrs, filename)
+	d.InvalidateFile(filename)
 
 	return nil
 }
@@ -112,11 +328,28 @@ func (p *Decoder) Filenames() []string {
 	return files
 }
 
+// File returns the parsed file of the given name, which was either
+// loaded via LoadFile or read from the FileSystem (see SetFileSystem)
+func (d *Decoder) File(filename string) (*hcl.File, error) {
+	return d.fileByName(filename)
+}
+
+// Schema returns a copy of the schema in use
+func (d *Decoder) Schema() (*schema.BodySchema, error) {
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+	return d.rootSchema.Copy(), nil
+}
+
 func (d *Decoder) bytesForFile(file string) ([]byte, error) {
 	d.filesMu.RLock()
 	defer d.filesMu.RUnlock()
 
-	f, ok := d.files[file]
+	f, ok := d.fileForName(file)
 	if !ok {
 		return nil, &FileNotFoundError{Filename: file}
 	}
@@ -137,15 +370,20 @@ func (d *Decoder) fileByName(name string) (*hcl.File, error) {
 	d.filesMu.RLock()
 	defer d.filesMu.RUnlock()
 
-	f, ok := d.files[name]
+	f, ok := d.fileForName(name)
 	if !ok {
 		return nil, &FileNotFoundError{Filename: name}
 	}
 	return f, nil
 }
 
-func (d *Decoder) bodyForFileAndPos(name string, f *hcl.File, pos hcl.Pos) (*hclsyntax.Body, error) {
-	body, isHcl := f.Body.(*hclsyntax.Body)
+func (d *Decoder) bodyForFileAndPos(name string, pos hcl.Pos) (*hclsyntax.Body, error) {
+	segments, err := d.segmentsForFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	body, isHcl := segments[segmentIndexAtPos(segments, pos)].Body.(*hclsyntax.Body)
 	if !isHcl {
 		return nil, &UnknownFileFormatError{Filename: name}
 	}
@@ -170,8 +408,55 @@ func posEqual(pos, other hcl.Pos) bool {
 		pos.Byte == other.Byte
 }
 
+// attributeSchemaForName returns schema of the attribute with the given
+// name, falling back to AnyAttribute if its NameConstraint permits the name
+func attributeSchemaForName(bodySchema *schema.BodySchema, name string) (*schema.AttributeSchema, bool) {
+	aSchema, ok := bodySchema.Attributes[name]
+	if ok {
+		return aSchema, true
+	}
+	if isAnyAttributeName(bodySchema, name) {
+		return bodySchema.AnyAttribute, true
+	}
+	return nil, false
+}
+
+// isAnyAttributeName returns true if the given name is permitted by
+// AnyAttribute (and its NameConstraint, if any)
+func isAnyAttributeName(bodySchema *schema.BodySchema, name string) bool {
+	anyAttr := bodySchema.AnyAttribute
+	if anyAttr == nil {
+		return false
+	}
+	return anyAttr.NameConstraint == nil || anyAttr.NameConstraint.IsValidKey(name)
+}
+
+// blockSchemaForType returns schema of the given block type,
+// falling back to AnyBlock for types not declared in Blocks
+func blockSchemaForType(bodySchema *schema.BodySchema, blockType string) (*schema.BlockSchema, bool) {
+	bSchema, ok := bodySchema.Blocks[blockType]
+	if ok {
+		return bSchema, true
+	}
+	if bodySchema.AnyBlock != nil {
+		return bodySchema.AnyBlock, true
+	}
+	return nil, false
+}
+
 func mergeBlockBodySchemas(block *hclsyntax.Block, blockSchema *schema.BlockSchema) (*schema.BodySchema, error) {
-	if len(blockSchema.DependentBody) == 0 {
+	return mergeBodySchemasForBlock(syntaxBlock{block}, blockSchema)
+}
+
+func mergeBodySchemasForBlock(block blockContent, blockSchema *schema.BlockSchema) (*schema.BodySchema, error) {
+	if !hasDependentBody(blockSchema) {
+		if blockSchema.AllowUnknownAttributes && blockSchema.Body != nil &&
+			!blockSchema.Body.AllowUnknownAttributes {
+			// shallow copy is sufficient, as nothing else is changed
+			bodySchema := *blockSchema.Body
+			bodySchema.AllowUnknownAttributes = true
+			return &bodySchema, nil
+		}
 		return blockSchema.Body, nil
 	}
 
@@ -186,8 +471,15 @@ func mergeBlockBodySchemas(block *hclsyntax.Block, blockSchema *schema.BlockSche
 		mergedSchema.Blocks = make(map[string]*schema.BlockSchema, 0)
 	}
 
-	depSchema, _, ok := NewBlockSchema(blockSchema).DependentBodySchema(block)
+	if blockSchema.AllowUnknownAttributes {
+		mergedSchema.AllowUnknownAttributes = true
+	}
+
+	depSchema, _, ok := NewBlockSchema(blockSchema).dependentBodySchema(block)
 	if ok {
+		if depSchema.AllowUnknownAttributes {
+			mergedSchema.AllowUnknownAttributes = true
+		}
 		for name, attr := range depSchema.Attributes {
 			if _, exists := mergedSchema.Attributes[name]; !exists {
 				mergedSchema.Attributes[name] = attr
@@ -6,12 +6,33 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/position"
+	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty-debug/ctydebug"
 	"github.com/zclconf/go-cty/cty"
 )
 
+// newTestDecoder returns a decoder for the given files (keyed by filename)
+// with reference targets and origins collected (see NewFromSources).
+//
+// Parse diagnostics are ignored, as configs being edited
+// (e.g. with missing values) are not expected to be valid.
+func newTestDecoder(t *testing.T, bodySchema *schema.BodySchema, files map[string]string) *Decoder {
+	t.Helper()
+
+	sources := make(map[string][]byte, len(files))
+	for filename, src := range files {
+		sources[filename] = []byte(src)
+	}
+	d, _, err := NewFromSources(sources, bodySchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
 func TestDecoder_LoadFile_nilFile(t *testing.T) {
 	d := NewDecoder()
 	err := d.LoadFile("test.tf", nil)
@@ -89,3 +110,41 @@ func TestTraversalToAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoder_PosFromLSP(t *testing.T) {
+	d := NewDecoder()
+	f, pDiags := hclsyntax.ParseConfig([]byte(`attr = "🚀"
+`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pos, err := d.PosFromLSP("test.tf", position.LSPPos{Line: 0, Character: 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedPos := hcl.Pos{Line: 1, Column: 11, Byte: 13}
+	if diff := cmp.Diff(expectedPos, pos); diff != "" {
+		t.Fatalf("unexpected position: %s", diff)
+	}
+
+	rng, err := d.RangeToLSP(hcl.Range{
+		Filename: "test.tf",
+		Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+		End:      expectedPos,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRange := position.LSPRange{
+		Start: position.LSPPos{Line: 0, Character: 7},
+		End:   position.LSPPos{Line: 0, Character: 11},
+	}
+	if diff := cmp.Diff(expectedRange, rng); diff != "" {
+		t.Fatalf("unexpected range: %s", diff)
+	}
+}
@@ -0,0 +1,178 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// defaultValue returns the default value of the attribute,
+// provided that it is known and can be rendered as a literal
+func defaultValue(attr *schema.AttributeSchema) (cty.Value, bool) {
+	val := attr.DefaultValue
+	if val == cty.NilVal || !val.IsWhollyKnown() || val.IsNull() {
+		return cty.NilVal, false
+	}
+	return val, true
+}
+
+// defaultValueText returns new text and snippet inserting the default
+// value of the attribute, with numbers rendered as per the value format
+func defaultValueText(attr *schema.AttributeSchema, vf valueFormat) (string, string, bool) {
+	val, ok := defaultValue(attr)
+	if !ok {
+		return "", "", false
+	}
+	return newTextForLiteralValue(val, vf), snippetForLiteralValue(1, val, vf), true
+}
+
+// withDefaultValueCandidate marks the candidate inserting the default
+// value of the attribute, or adds one where none of the candidates
+// does so, e.g. for numbers which otherwise have no candidates
+func withDefaultValueCandidate(candidates lang.Candidates, attr *schema.AttributeSchema, editRng hcl.Range, vf valueFormat) lang.Candidates {
+	val, ok := defaultValue(attr)
+	if !ok {
+		return candidates
+	}
+	newText, _, _ := defaultValueText(attr, vf)
+
+	for i, c := range candidates.List {
+		if c.TextEdit.NewText == newText {
+			candidates.List[i].Detail = fmt.Sprintf("%s, default", c.Detail)
+			return candidates
+		}
+	}
+
+	c, ok := valueToCandidate(val, lang.MarkupContent{}, false, editRng, vf)
+	if !ok {
+		return candidates
+	}
+	c.Detail = fmt.Sprintf("%s, default", c.Detail)
+	candidates.List = append(candidates.List, c)
+	candidates.Sort()
+
+	return candidates
+}
+
+// hoverContentForDefaultValue returns hover content
+// describing the default value of the attribute, if any
+func (d *Decoder) hoverContentForDefaultValue(attr *schema.AttributeSchema) (string, bool) {
+	val, ok := defaultValue(attr)
+	if !ok {
+		return "", false
+	}
+
+	if val.Type().IsPrimitiveType() {
+		content, err := d.hoverContentForValue(val, 1)
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("Defaults to `%s`.", content), true
+	}
+
+	content, err := d.hoverContentForValue(val, 0)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("Defaults to:\n%s", content), true
+}
+
+// validateRedundantDefault reports an attribute whose value is
+// the same as its default, along with a fix to remove the attribute
+func (d *Decoder) validateRedundantDefault(attr *hclsyntax.Attribute, aSchema *schema.AttributeSchema, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if !d.features.RedundantDefaultHints {
+		return diags
+	}
+
+	defVal, ok := defaultValue(aSchema)
+	if !ok {
+		return diags
+	}
+
+	if len(attr.Expr.Variables()) > 0 {
+		return diags
+	}
+	val, vDiags := attr.Expr.Value(nil)
+	if vDiags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return diags
+	}
+	val, err := convert.Convert(val, defVal.Type())
+	if err != nil {
+		return diags
+	}
+	if eq := val.Equals(defVal); !eq.IsKnown() || eq.False() {
+		return diags
+	}
+
+	diag := d.newDiagnostic(CodeRedundantDefault, &hcl.Diagnostic{
+		Severity: lang.DiagHint,
+		Summary:  "Redundant default value",
+		Detail:   fmt.Sprintf("Value of %q is the same as its default, so the attribute can be removed", attr.Name),
+		Subject:  attr.SrcRange.Ptr(),
+	})
+	diags = append(diags, diag)
+
+	if fixes != nil {
+		if rng, ok := d.attributeLineRange(attr); ok {
+			*fixes = append(*fixes, lang.DiagnosticFix{
+				Title:      "Remove attribute",
+				Diagnostic: diag,
+				Edits: []lang.TextEdit{
+					{
+						Range:   rng,
+						NewText: "",
+						Snippet: "",
+					},
+				},
+			})
+		}
+	}
+
+	return diags
+}
+
+// attributeLineRange returns the range of the whole line(s)
+// of the attribute, including the indentation and the newline,
+// provided that nothing else is on the same line(s)
+func (d *Decoder) attributeLineRange(attr *hclsyntax.Attribute) (hcl.Range, bool) {
+	src, err := d.bytesForFile(attr.SrcRange.Filename)
+	if err != nil {
+		return hcl.Range{}, false
+	}
+
+	rng := attr.SrcRange
+	if rng.End.Byte > len(src) {
+		return hcl.Range{}, false
+	}
+
+	lineStart := rng.Start.Byte - (rng.Start.Column - 1)
+	if lineStart < 0 || len(bytes.TrimLeft(src[lineStart:rng.Start.Byte], " \t")) > 0 {
+		return hcl.Range{}, false
+	}
+	rng.Start = hcl.Pos{
+		Line:   rng.Start.Line,
+		Column: 1,
+		Byte:   lineStart,
+	}
+
+	if rng.End.Byte < len(src) {
+		if src[rng.End.Byte] != '\n' {
+			return hcl.Range{}, false
+		}
+		rng.End = hcl.Pos{
+			Line:   rng.End.Line + 1,
+			Column: 1,
+			Byte:   rng.End.Byte + 1,
+		}
+	}
+
+	return rng, true
+}
@@ -0,0 +1,437 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var defaultValueSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"retries": {
+			IsOptional:   true,
+			Expr:         schema.LiteralTypeOnly(cty.Number),
+			DefaultValue: cty.NumberIntVal(5),
+		},
+		"mode": {
+			IsOptional:   true,
+			Description:  lang.PlainText("Mode of operation"),
+			Expr:         schema.LiteralTypeOnly(cty.String),
+			DefaultValue: cty.StringVal("fast"),
+		},
+		"tags": {
+			IsOptional:   true,
+			Expr:         schema.LiteralTypeOnly(cty.Map(cty.String)),
+			DefaultValue: cty.MapVal(map[string]cty.Value{"env": cty.StringVal("dev")}),
+		},
+		"settings": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.ObjectExpr{
+					Attributes: schema.ObjectExprAttributes{
+						"enabled": {
+							IsOptional:   true,
+							Expr:         schema.LiteralTypeOnly(cty.Bool),
+							DefaultValue: cty.True,
+						},
+					},
+				},
+			},
+		},
+		"name": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+		},
+		"ratio": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralValue{Val: cty.NumberFloatVal(0.5)},
+				schema.LiteralValue{Val: cty.NumberFloatVal(0.75)},
+			},
+			DefaultValue: cty.NumberFloatVal(0.75),
+		},
+	},
+}
+
+func TestDecoder_HoverAtPos_defaultValue(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		posAfter        string
+		expectedContent string
+	}{
+		{
+			"number",
+			`retries = 3
+`,
+			"retr",
+			"**retries** _optional, number_\n\nDefaults to `5`.",
+		},
+		{
+			"string with description",
+			`mode = "slow"
+`,
+			"mo",
+			"**mode** _optional, string_\n\nMode of operation\n\nDefaults to `\"fast\"`.",
+		},
+		{
+			"map",
+			`tags = {}
+`,
+			"ta",
+			"**tags** _optional, map of string_\n\nDefaults to:\n```\n{\n  \"env\" = \"dev\"\n}\n```\n_map of string_",
+		},
+		{
+			"object attribute",
+			`settings = {
+  enabled = false
+}
+`,
+			"  ena",
+			"**enabled** _optional, bool_\n\nDefaults to `true`.",
+		},
+		{
+			"no default",
+			`name = "foo"
+`,
+			"na",
+			"**name** _optional, string_",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(defaultValueSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := d.HoverAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedContent, data.Content.Value); diff != "" {
+				t.Fatalf("unexpected hover content: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_defaultValue(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cfg                string
+		posAfter           string
+		expectedCandidates string
+	}{
+		{
+			"attribute with default",
+			`ret
+`,
+			"ret",
+			`
+complete: true
+candidates: 1
+---
+label: "retries"
+kind: AttributeCandidateKind
+detail: "optional, number"
+edit: test.tf:1,1-1,4 "retries" (snippet "retries = ${1:5}")
+`,
+		},
+		{
+			"attribute with map default",
+			`ta
+`,
+			"ta",
+			`
+complete: true
+candidates: 1
+---
+label: "tags"
+kind: AttributeCandidateKind
+detail: "optional, map of string"
+edit: test.tf:1,1-1,3 "tags" (snippet "tags = {\n  \"${1:env}\" = \"${2:dev}\"\n}")
+`,
+		},
+		{
+			"attribute without default",
+			`na
+`,
+			"na",
+			`
+complete: true
+candidates: 1
+---
+label: "name"
+kind: AttributeCandidateKind
+detail: "optional, string"
+edit: test.tf:1,1-1,3 "name" (snippet "name = \"${1:value}\"")
+`,
+		},
+		{
+			"object attribute with default",
+			`settings = {
+  
+}
+`,
+			"{\n  ",
+			`
+complete: true
+candidates: 1
+---
+label: "enabled"
+kind: AttributeCandidateKind
+detail: "optional, bool"
+edit: test.tf:2,3-2,3 "enabled = true" (snippet "enabled = ${1:true}")
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(defaultValueSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_defaultValueOfAttribute(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cfg                string
+		posAfter           string
+		expectedCandidates string
+	}{
+		{
+			"number",
+			`retries = 
+`,
+			"retries = ",
+			`
+complete: true
+candidates: 1
+---
+label: "5"
+kind: NumberCandidateKind
+detail: "number, default"
+edit: test.tf:1,11-1,11 "5" (snippet "${1:5}")
+`,
+		},
+		{
+			"string",
+			`mode = 
+`,
+			"mode = ",
+			`
+complete: true
+candidates: 1
+---
+label: "fast"
+kind: StringCandidateKind
+detail: "string, default"
+edit: test.tf:1,8-1,8 "\"fast\"" (snippet "\"${1:fast}\"")
+`,
+		},
+		{
+			"one of values",
+			`ratio = 
+`,
+			"ratio = ",
+			`
+complete: true
+candidates: 2
+---
+label: "0.5"
+kind: NumberCandidateKind
+detail: "number"
+edit: test.tf:1,9-1,9 "0.5" (snippet "${1:0.5}")
+---
+label: "0.75"
+kind: NumberCandidateKind
+detail: "number, default"
+edit: test.tf:1,9-1,9 "0.75" (snippet "${1:0.75}")
+`,
+		},
+		{
+			"nested value",
+			`tags = {
+  env = 
+}
+`,
+			"env = ",
+			`
+complete: true
+candidates: 0
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(defaultValueSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
+
+func TestDecoder_ValidateFileWithFixes_redundantDefault(t *testing.T) {
+	testCases := []struct {
+		name            string
+		features        Features
+		cfg             string
+		expectedSummary string
+		expectedFix     string
+		expectedCfg     string
+	}{
+		{
+			"disabled by default",
+			Features{},
+			`retries = 5
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"different value",
+			Features{RedundantDefaultHints: true},
+			`retries = 3
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"reference",
+			Features{RedundantDefaultHints: true},
+			`retries = var.retries
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"same number",
+			Features{RedundantDefaultHints: true},
+			`name = "foo"
+retries = 5.0
+mode = "slow"
+`,
+			"Redundant default value",
+			"Remove attribute",
+			`name = "foo"
+mode = "slow"
+`,
+		},
+		{
+			"same map",
+			Features{RedundantDefaultHints: true},
+			`tags = {
+  env = "dev"
+}
+`,
+			"Redundant default value",
+			"Remove attribute",
+			"",
+		},
+		{
+			"trailing comment",
+			Features{RedundantDefaultHints: true},
+			`mode = "fast" # explicit
+`,
+			"Redundant default value",
+			"",
+			"",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(defaultValueSchema)
+			d.SetFeatures(tc.features)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expectedSummary == "" {
+				if len(diags) > 0 {
+					t.Fatalf("expected no diagnostics, given: %#v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, given: %#v", diags)
+			}
+			if diags[0].Severity != lang.DiagHint || diags[0].Summary != tc.expectedSummary {
+				t.Fatalf("unexpected diagnostic: %#v", diags[0])
+			}
+
+			diagFixes := fixes.ForDiagnostic(diags[0])
+			if tc.expectedFix == "" {
+				if len(diagFixes) > 0 {
+					t.Fatalf("expected no fixes, given: %#v", diagFixes)
+				}
+				return
+			}
+			if len(diagFixes) != 1 {
+				t.Fatalf("expected 1 fix, given: %#v", diagFixes)
+			}
+			if diagFixes[0].Title != tc.expectedFix {
+				t.Fatalf("unexpected fix: %q", diagFixes[0].Title)
+			}
+
+			fixedCfg := applyTextEdits(tc.cfg, diagFixes[0].Edits)
+			if fixedCfg != tc.expectedCfg {
+				t.Fatalf("unexpected config after fix:\n%s", fixedCfg)
+			}
+		})
+	}
+}
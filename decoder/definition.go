@@ -0,0 +1,81 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// DefinitionAtPos returns definition of the label at the given position,
+// if the label selects a dependent body whose schema declares
+// DefinitionLocation (e.g. a resource type pointing to provider docs).
+//
+// nil is returned if there is no such label at the position.
+func (d *Decoder) DefinitionAtPos(filename string, pos hcl.Pos, opts ...RequestOption) (*lang.Definition, error) {
+	d = d.withRequestOptions(opts)
+
+	pos = d.posForFile(filename, pos)
+
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	return d.definitionAtPos(rootBody, d.rootSchema, 0, pos)
+}
+
+func (d *Decoder) definitionAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema, nestingLvl int, pos hcl.Pos) (*lang.Definition, error) {
+	if bodySchema == nil || d.isNestedTooDeep(nestingLvl) {
+		return nil, nil
+	}
+
+	for _, block := range body.Blocks {
+		if !block.Range().ContainsPos(pos) {
+			continue
+		}
+
+		bSchema, ok := blockSchemaForType(bodySchema, block.Type)
+		if !ok {
+			return nil, nil
+		}
+
+		for i, labelRange := range block.LabelRanges {
+			if !labelRange.ContainsPos(pos) {
+				continue
+			}
+			if i >= len(bSchema.Labels) || !bSchema.Labels[i].IsDepKey {
+				return nil, nil
+			}
+
+			depSchema, _, ok := NewBlockSchema(bSchema).DependentBodySchema(block)
+			if !ok || depSchema.DefinitionLocation == nil {
+				return nil, nil
+			}
+
+			return &lang.Definition{
+				OriginRange: labelRange,
+				URI:         depSchema.DefinitionLocation.URI,
+				Range:       depSchema.DefinitionLocation.Range,
+			}, nil
+		}
+
+		if block.Body != nil && block.Body.Range().ContainsPos(pos) {
+			mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
+			if err != nil {
+				return nil, err
+			}
+
+			return d.definitionAtPos(block.Body, mergedSchema, nestingLvl+1, pos)
+		}
+	}
+
+	return nil, nil
+}
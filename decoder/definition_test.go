@@ -0,0 +1,143 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+var definitionSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": {
+			Labels: []*schema.LabelSchema{
+				{Name: "type", IsDepKey: true},
+				{Name: "name"},
+			},
+			Body: &schema.BodySchema{
+				Blocks: map[string]*schema.BlockSchema{
+					"provisioner": {
+						Labels: []*schema.LabelSchema{
+							{Name: "type", IsDepKey: true},
+						},
+						DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+							schema.NewSchemaKey(schema.DependencyKeys{
+								Labels: []schema.LabelDependent{
+									{Index: 0, Value: "local-exec"},
+								},
+							}): {
+								DefinitionLocation: &schema.DefinitionLocation{
+									URI: "https://example.com/docs/local-exec",
+								},
+							},
+						},
+					},
+				},
+			},
+			DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{
+						{Index: 0, Value: "aws_instance"},
+					},
+				}): {
+					DefinitionLocation: &schema.DefinitionLocation{
+						URI: "file:///schemas/aws.hcl",
+						Range: hcl.Range{
+							Start: hcl.Pos{Line: 10, Column: 1, Byte: 120},
+							End:   hcl.Pos{Line: 20, Column: 2, Byte: 300},
+						},
+					},
+				},
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{
+						{Index: 0, Value: "aws_vpc"},
+					},
+				}): {},
+			},
+		},
+	},
+}
+
+func TestDecoder_DefinitionAtPos(t *testing.T) {
+	cfg := `resource "aws_instance" "web" {
+  provisioner "local-exec" {}
+}
+resource "aws_vpc" "main" {}
+resource "unknown" "foo" {}
+`
+
+	testCases := []struct {
+		name               string
+		pos                hcl.Pos
+		expectedDefinition *lang.Definition
+	}{
+		{
+			"dependency key label",
+			hcl.Pos{Line: 1, Column: 14, Byte: 13},
+			&lang.Definition{
+				OriginRange: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+					End:      hcl.Pos{Line: 1, Column: 24, Byte: 23},
+				},
+				URI: "file:///schemas/aws.hcl",
+				Range: hcl.Range{
+					Start: hcl.Pos{Line: 10, Column: 1, Byte: 120},
+					End:   hcl.Pos{Line: 20, Column: 2, Byte: 300},
+				},
+			},
+		},
+		{
+			"other label",
+			hcl.Pos{Line: 1, Column: 27, Byte: 26},
+			nil,
+		},
+		{
+			"nested dependency key label",
+			hcl.Pos{Line: 2, Column: 18, Byte: 49},
+			&lang.Definition{
+				OriginRange: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 2, Column: 15, Byte: 46},
+					End:      hcl.Pos{Line: 2, Column: 27, Byte: 58},
+				},
+				URI: "https://example.com/docs/local-exec",
+			},
+		},
+		{
+			"dependent body without definition",
+			hcl.Pos{Line: 4, Column: 15, Byte: 78},
+			nil,
+		},
+		{
+			"unknown dependent body",
+			hcl.Pos{Line: 5, Column: 14, Byte: 106},
+			nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(definitionSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			definition, err := d.DefinitionAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDefinition, definition); diff != "" {
+				t.Fatalf("unexpected definition: %s", diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,10 @@ func NewBlockSchema(bs *schema.BlockSchema) blockSchema {
 // DependentBodySchema finds relevant BodySchema based on dependency keys
 // such as a label or an attribute (or combination of both).
 func (bs blockSchema) DependentBodySchema(block *hclsyntax.Block) (*schema.BodySchema, schema.DependencyKeys, bool) {
+	return bs.dependentBodySchema(syntaxBlock{block})
+}
+
+func (bs blockSchema) dependentBodySchema(block blockContent) (*schema.BodySchema, schema.DependencyKeys, bool) {
 	dks := dependencyKeysFromBlock(block, bs)
 	b, err := dks.MarshalJSON()
 	if err != nil {
@@ -25,7 +29,7 @@ func (bs blockSchema) DependentBodySchema(block *hclsyntax.Block) (*schema.BodyS
 	}
 
 	key := schema.SchemaKey(string(b))
-	depBodySchema, ok := bs.DependentBody[key]
+	depBodySchema, ok := dependentBodyForKey(bs.BlockSchema, key)
 	if ok {
 		hasDepKeys := false
 		for _, attr := range depBodySchema.Attributes {
@@ -38,7 +42,7 @@ func (bs blockSchema) DependentBodySchema(block *hclsyntax.Block) (*schema.BodyS
 			mergedBlockSchema := NewBlockSchema(bs.Copy())
 			mergedBlockSchema.seenNestedDepKeys = true
 			mergedBlockSchema.Body = depBodySchema
-			if depBodySchema, dks, ok := mergedBlockSchema.DependentBodySchema(block); ok {
+			if depBodySchema, dks, ok := mergedBlockSchema.dependentBodySchema(block); ok {
 				return depBodySchema, dks, ok
 			}
 		}
@@ -47,21 +51,21 @@ func (bs blockSchema) DependentBodySchema(block *hclsyntax.Block) (*schema.BodyS
 	return depBodySchema, dks, ok
 }
 
-func dependencyKeysFromBlock(block *hclsyntax.Block, blockSchema blockSchema) schema.DependencyKeys {
+func dependencyKeysFromBlock(block blockContent, blockSchema blockSchema) schema.DependencyKeys {
 	dk := schema.DependencyKeys{
 		Labels:     []schema.LabelDependent{},
 		Attributes: []schema.AttributeDependent{},
 	}
 	for i, labelSchema := range blockSchema.Labels {
 		if labelSchema.IsDepKey {
-			if i+1 > len(block.Labels) {
+			if i+1 > len(block.labels()) {
 				// mismatching label schema
 				return dk
 			}
 
 			dk.Labels = append(dk.Labels, schema.LabelDependent{
 				Index: i,
-				Value: block.Labels[i],
+				Value: block.labels()[i],
 			})
 		}
 	}
@@ -70,20 +74,15 @@ func dependencyKeysFromBlock(block *hclsyntax.Block, blockSchema blockSchema) sc
 		return dk
 	}
 
-	if block.Body == nil {
-		// no attributes to find
-		return dk
-	}
-
 	for name, attrSchema := range blockSchema.Body.Attributes {
 		if attrSchema.IsDepKey {
-			attr, ok := block.Body.Attributes[name]
+			expr, ok := block.attributeExpr(name)
 			if !ok {
 				// dependent attribute not present
 				continue
 			}
 
-			st, ok := attr.Expr.(*hclsyntax.ScopeTraversalExpr)
+			st, ok := expr.(*hclsyntax.ScopeTraversalExpr)
 			if ok {
 				addr, err := lang.TraversalToAddress(st.AsTraversal())
 				if err != nil {
@@ -99,7 +98,7 @@ func dependencyKeysFromBlock(block *hclsyntax.Block, blockSchema blockSchema) sc
 				continue
 			}
 
-			value, diags := attr.Expr.Value(nil)
+			value, diags := expr.Value(nil)
 			if len(diags) > 0 && value.IsNull() {
 				// skip attribute if we can't get the value
 				continue
@@ -115,3 +114,43 @@ func dependencyKeysFromBlock(block *hclsyntax.Block, blockSchema blockSchema) sc
 	}
 	return dk
 }
+
+// hasDependentBody returns true if the block declares
+// any dependent body schemas, including lazily loaded ones
+func hasDependentBody(bs *schema.BlockSchema) bool {
+	return len(bs.DependentBody) > 0 || bs.LazyDependentBody != nil
+}
+
+// dependentBodyForKey returns the dependent body schema for the key,
+// loading it if it's not declared in DependentBody
+func dependentBodyForKey(bs *schema.BlockSchema, key schema.SchemaKey) (*schema.BodySchema, bool) {
+	if bodySchema, ok := bs.DependentBody[key]; ok {
+		return bodySchema, true
+	}
+	if bs.LazyDependentBody != nil {
+		return bs.LazyDependentBody.BodySchema(key)
+	}
+	return nil, false
+}
+
+// dependentBodiesForCompletion returns all dependent body schemas
+// by key, without loading any lazily loaded schemas, which are
+// represented by empty schemas until loaded
+func dependentBodiesForCompletion(bs *schema.BlockSchema) map[schema.SchemaKey]*schema.BodySchema {
+	if bs.LazyDependentBody == nil {
+		return bs.DependentBody
+	}
+
+	bodies := make(map[schema.SchemaKey]*schema.BodySchema, len(bs.DependentBody)+len(bs.LazyDependentBody.Keys))
+	for _, key := range bs.LazyDependentBody.Keys {
+		if bodySchema, ok := bs.LazyDependentBody.Cached(key); ok {
+			bodies[key] = bodySchema
+			continue
+		}
+		bodies[key] = &schema.BodySchema{}
+	}
+	for key, bodySchema := range bs.DependentBody {
+		bodies[key] = bodySchema
+	}
+	return bodies
+}
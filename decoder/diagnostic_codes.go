@@ -0,0 +1,135 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Codes of diagnostics produced by the built-in validators,
+// which remain stable across versions
+const (
+	CodeUnexpectedAttribute       lang.DiagnosticCode = "HCLLANG001"
+	CodeInvalidAttributeName      lang.DiagnosticCode = "HCLLANG002"
+	CodeUnknownAttribute          lang.DiagnosticCode = "HCLLANG003"
+	CodeUnexpectedBlock           lang.DiagnosticCode = "HCLLANG004"
+	CodeMissingLabel              lang.DiagnosticCode = "HCLLANG005"
+	CodeExtraneousLabel           lang.DiagnosticCode = "HCLLANG006"
+	CodeInvalidLabel              lang.DiagnosticCode = "HCLLANG007"
+	CodeInvalidValueType          lang.DiagnosticCode = "HCLLANG008"
+	CodeInvalidReferenceType      lang.DiagnosticCode = "HCLLANG009"
+	CodeInvalidReferenceScope     lang.DiagnosticCode = "HCLLANG010"
+	CodeSensitiveValueInInsecure  lang.DiagnosticCode = "HCLLANG011"
+	CodeReferenceToWriteOnly      lang.DiagnosticCode = "HCLLANG012"
+	CodeInvalidKeyword            lang.DiagnosticCode = "HCLLANG013"
+	CodeMissingTrailingNewline    lang.DiagnosticCode = "HCLLANG014"
+	CodeUnexpectedTrailingNewline lang.DiagnosticCode = "HCLLANG015"
+	CodeNotEnoughArguments        lang.DiagnosticCode = "HCLLANG016"
+	CodeTooManyArguments          lang.DiagnosticCode = "HCLLANG017"
+	CodeInvalidFunctionArgument   lang.DiagnosticCode = "HCLLANG018"
+	CodeDeprecatedFunction        lang.DiagnosticCode = "HCLLANG019"
+	CodeRedundantDefault          lang.DiagnosticCode = "HCLLANG020"
+	CodeInvalidSize               lang.DiagnosticCode = "HCLLANG021"
+	CodeInvalidSizeUnit           lang.DiagnosticCode = "HCLLANG022"
+	CodeMissingTupleElement       lang.DiagnosticCode = "HCLLANG023"
+	CodeExtraneousTupleElement    lang.DiagnosticCode = "HCLLANG024"
+	CodeUnexpectedOrder           lang.DiagnosticCode = "HCLLANG025"
+	CodeUnexpectedGrouping        lang.DiagnosticCode = "HCLLANG026"
+	CodeUnclosedDirective         lang.DiagnosticCode = "HCLLANG027"
+	CodeUnexpectedDirective       lang.DiagnosticCode = "HCLLANG028"
+	CodeInvalidEscapeSequence     lang.DiagnosticCode = "HCLLANG029"
+	CodeTooManyDiagnostics        lang.DiagnosticCode = "HCLLANG030"
+	CodeNonCanonicalReference     lang.DiagnosticCode = "HCLLANG031"
+	CodeLiteralInsteadOfReference lang.DiagnosticCode = "HCLLANG032"
+	CodeHeredocExpected           lang.DiagnosticCode = "HCLLANG033"
+	CodeUnexpectedHeredocStyle    lang.DiagnosticCode = "HCLLANG034"
+)
+
+// DiagnosticCodeURLFunc represents a function which returns
+// a link to description of the given code (e.g. docs),
+// or an empty string if there is none
+type DiagnosticCodeURLFunc func(code lang.DiagnosticCode) string
+
+// SetDiagnosticCodeURLFunc sets the function which provides links
+// to descriptions of diagnostic codes (see lang.Diagnostic)
+func (d *Decoder) SetDiagnosticCodeURLFunc(f DiagnosticCodeURLFunc) {
+	d.diagCodeURLFunc = f
+}
+
+// allDiagnosticCodes returns codes of all built-in validators
+func allDiagnosticCodes() []lang.DiagnosticCode {
+	return []lang.DiagnosticCode{
+		CodeUnexpectedAttribute,
+		CodeInvalidAttributeName,
+		CodeUnknownAttribute,
+		CodeUnexpectedBlock,
+		CodeMissingLabel,
+		CodeExtraneousLabel,
+		CodeInvalidLabel,
+		CodeInvalidValueType,
+		CodeInvalidReferenceType,
+		CodeInvalidReferenceScope,
+		CodeSensitiveValueInInsecure,
+		CodeReferenceToWriteOnly,
+		CodeInvalidKeyword,
+		CodeMissingTrailingNewline,
+		CodeUnexpectedTrailingNewline,
+		CodeNotEnoughArguments,
+		CodeTooManyArguments,
+		CodeInvalidFunctionArgument,
+		CodeDeprecatedFunction,
+		CodeRedundantDefault,
+		CodeInvalidSize,
+		CodeInvalidSizeUnit,
+		CodeMissingTupleElement,
+		CodeExtraneousTupleElement,
+		CodeUnexpectedOrder,
+		CodeUnexpectedGrouping,
+		CodeUnclosedDirective,
+		CodeUnexpectedDirective,
+		CodeInvalidEscapeSequence,
+		CodeTooManyDiagnostics,
+		CodeNonCanonicalReference,
+		CodeLiteralInsteadOfReference,
+		CodeHeredocExpected,
+		CodeUnexpectedHeredocStyle,
+	}
+}
+
+// diagnosticCodes collects codes of diagnostics produced
+// by the built-in validators, keyed by the diagnostic
+type diagnosticCodes map[*hcl.Diagnostic]lang.DiagnosticCode
+
+// withDiagnosticCodes returns a shallow copy of d which records
+// codes of diagnostics produced by the built-in validators in codes
+func (d *Decoder) withDiagnosticCodes(codes diagnosticCodes) *Decoder {
+	dCopy := *d
+	dCopy.diagCodes = codes
+	return &dCopy
+}
+
+// newDiagnostic returns the diagnostic produced by a built-in validator,
+// recording its code, if codes are requested (see withDiagnosticCodes)
+func (d *Decoder) newDiagnostic(code lang.DiagnosticCode, diag *hcl.Diagnostic) *hcl.Diagnostic {
+	if d.diagCodes != nil {
+		d.diagCodes[diag] = code
+	}
+	return diag
+}
+
+// validationDiagnostics returns diagnostics produced by the built-in
+// validators along with their codes, any related locations
+// and schema paths (if enabled)
+func (d *Decoder) validationDiagnostics(diags hcl.Diagnostics, related relatedInfos) lang.Diagnostics {
+	langDiags := related.diagnostics(diags)
+	for i, diag := range langDiags {
+		code, ok := d.diagCodes[diag.Diagnostic]
+		if !ok {
+			continue
+		}
+		langDiags[i].Code = code
+		if d.diagCodeURLFunc != nil {
+			langDiags[i].CodeDescriptionURL = d.diagCodeURLFunc(code)
+		}
+	}
+	return d.diagnosticsWithSchemaPaths(langDiags)
+}
@@ -0,0 +1,197 @@
+package decoder
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDiagnosticCode_allValidators(t *testing.T) {
+	filenames, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0)
+	for _, filename := range filenames {
+		if strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filename, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+
+	// values of declared codes keyed by name of the constant
+	declaredCodes := make(map[string]lang.DiagnosticCode, 0)
+	for _, f := range files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			spec, ok := node.(*ast.ValueSpec)
+			if !ok {
+				return true
+			}
+			sel, ok := spec.Type.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "DiagnosticCode" {
+				return true
+			}
+			lit := spec.Values[0].(*ast.BasicLit)
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			declaredCodes[spec.Names[0].Name] = lang.DiagnosticCode(value)
+			return true
+		})
+	}
+
+	expectedCodes := make([]lang.DiagnosticCode, 0, len(declaredCodes))
+	for _, code := range declaredCodes {
+		expectedCodes = append(expectedCodes, code)
+	}
+	sort.Slice(expectedCodes, func(i, j int) bool {
+		return expectedCodes[i] < expectedCodes[j]
+	})
+	if diff := cmp.Diff(expectedCodes, allDiagnosticCodes()); diff != "" {
+		t.Fatalf("unexpected list of all codes: %s", diff)
+	}
+
+	// every diagnostic is expected to be created
+	// via newDiagnostic with one of the declared codes
+	for _, f := range files {
+		coded := make(map[ast.Node]bool, 0)
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.CallExpr:
+				sel, ok := n.Fun.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "newDiagnostic" || len(n.Args) != 2 {
+					return true
+				}
+				code, ok := n.Args[0].(*ast.Ident)
+				if !ok {
+					t.Errorf("%s: code is not a constant", fset.Position(n.Pos()))
+				} else if _, ok := declaredCodes[code.Name]; !ok {
+					t.Errorf("%s: unknown code %s", fset.Position(n.Pos()), code.Name)
+				}
+				if ue, ok := n.Args[1].(*ast.UnaryExpr); ok {
+					coded[ue.X] = true
+				}
+			case *ast.CompositeLit:
+				sel, ok := n.Type.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "Diagnostic" {
+					return true
+				}
+				if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "hcl" {
+					return true
+				}
+				if !coded[n] {
+					t.Errorf("%s: diagnostic created without code", fset.Position(n.Pos()))
+				}
+			}
+			return true
+		})
+	}
+}
+
+func TestDiagnosticCode_unique(t *testing.T) {
+	seen := make(map[lang.DiagnosticCode]bool, 0)
+	for _, code := range allDiagnosticCodes() {
+		if seen[code] {
+			t.Fatalf("%s listed more than once", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestDecoder_validationDiagnostics_uncoded(t *testing.T) {
+	d := NewDecoder().withDiagnosticCodes(make(diagnosticCodes, 0))
+
+	// diagnostics not produced by the built-in validators
+	// get no code, even if their summary is the same
+	diags := hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid function argument",
+			Subject:  &hcl.Range{Filename: "test.tf"},
+		},
+		d.newDiagnostic(CodeInvalidFunctionArgument, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid function argument",
+			Subject:  &hcl.Range{Filename: "test.tf"},
+		}),
+	}
+
+	langDiags := d.validationDiagnostics(diags, nil)
+	codes := []lang.DiagnosticCode{langDiags[0].Code, langDiags[1].Code}
+	expectedCodes := []lang.DiagnosticCode{"", CodeInvalidFunctionArgument}
+	if diff := cmp.Diff(expectedCodes, codes); diff != "" {
+		t.Fatalf("unexpected codes: %s", diff)
+	}
+}
+
+func TestDecoder_ValidateFileWithRelatedInfo_codes(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type"},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"name": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+					},
+				},
+			},
+		},
+	})
+	d.SetDiagnosticCodeURLFunc(func(code lang.DiagnosticCode) string {
+		return "https://example.com/diagnostics/" + string(code)
+	})
+
+	cfg := `resource {
+  name = {}
+  foo = "bar"
+}
+`
+	f, pDiags := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := d.ValidateFileWithRelatedInfo("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes := make(map[string]string, 0)
+	for _, diag := range diags {
+		codes[string(diag.Code)] = diag.CodeDescriptionURL
+	}
+	expectedCodes := map[string]string{
+		"HCLLANG001": "https://example.com/diagnostics/HCLLANG001",
+		"HCLLANG005": "https://example.com/diagnostics/HCLLANG005",
+		"HCLLANG008": "https://example.com/diagnostics/HCLLANG008",
+	}
+	if diff := cmp.Diff(expectedCodes, codes); diff != "" {
+		t.Fatalf("unexpected codes: %s", diff)
+	}
+}
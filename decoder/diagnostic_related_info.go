@@ -0,0 +1,66 @@
+package decoder
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// relatedInfos collects locations related to diagnostics,
+// where nil represents that these are not requested
+type relatedInfos map[*hcl.Diagnostic][]lang.DiagnosticRelatedInfo
+
+func (ri relatedInfos) add(diag *hcl.Diagnostic, info lang.DiagnosticRelatedInfo) {
+	if ri == nil {
+		return
+	}
+	ri[diag] = append(ri[diag], info)
+}
+
+// diagnostics returns the given diagnostics along with
+// any locations related to each of them
+func (ri relatedInfos) diagnostics(diags hcl.Diagnostics) lang.Diagnostics {
+	langDiags := make(lang.Diagnostics, len(diags))
+	for i, diag := range diags {
+		langDiags[i] = lang.Diagnostic{
+			Diagnostic:  diag,
+			RelatedInfo: ri[diag],
+		}
+	}
+	return langDiags
+}
+
+// addBodyDocs relates the diagnostic to docs of the body, if any,
+// e.g. where an attribute or block is not expected in the body
+func (d *Decoder) addBodyDocs(ri relatedInfos, diag *hcl.Diagnostic, bodySchema *schema.BodySchema) {
+	if ri == nil || bodySchema.DocsLink == nil {
+		return
+	}
+	u, err := d.docsURL(bodySchema.DocsLink.URL, "diagnostic")
+	if err != nil {
+		return
+	}
+
+	msg := bodySchema.DocsLink.Tooltip
+	if msg == "" {
+		msg = "Documentation of the expected attributes and blocks"
+	}
+	ri.add(diag, lang.DiagnosticRelatedInfo{
+		URI:     u.String(),
+		Message: msg,
+	})
+}
+
+// addTargetDefinition relates the diagnostic to the range
+// where the referenced target is defined, if known
+func (d *Decoder) addTargetDefinition(ri relatedInfos, diag *hcl.Diagnostic, target lang.ReferenceTarget) {
+	if target.RangePtr == nil {
+		return
+	}
+	ri.add(diag, lang.DiagnosticRelatedInfo{
+		Range:   *target.RangePtr,
+		Message: fmt.Sprintf("%s is defined here", d.addrFormat.Format(target.Addr)),
+	})
+}
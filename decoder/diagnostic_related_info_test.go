@@ -0,0 +1,150 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_ValidateFileWithRelatedInfo(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"count": {
+							IsOptional: true,
+							Expr: schema.ExprConstraints{
+								schema.TraversalExpr{OfType: cty.Number},
+							},
+						},
+						"name": {
+							IsOptional: true,
+							Expr:       schema.LiteralTypeOnly(cty.String),
+						},
+					},
+					DocsLink: &schema.DocsLink{
+						URL:     "https://example.com/resource",
+						Tooltip: "Resource docs",
+					},
+					Ordering: &schema.Ordering{
+						First: []string{"count"},
+					},
+				},
+			},
+		},
+	}
+	refTargets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "str"},
+			},
+			Type: cty.String,
+			RangePtr: &hcl.Range{
+				Filename: "variables.tf",
+				Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:      hcl.Pos{Line: 1, Column: 16, Byte: 15},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		cfg                 string
+		expectedRelatedInfo map[string][]lang.DiagnosticRelatedInfo
+	}{
+		{
+			"unexpected attribute",
+			`resource {
+  foo = "bar"
+}
+`,
+			map[string][]lang.DiagnosticRelatedInfo{
+				"Unexpected attribute": {
+					{
+						URI:     "https://example.com/resource",
+						Message: "Resource docs",
+					},
+				},
+			},
+		},
+		{
+			"invalid reference type",
+			`resource {
+  count = var.str
+}
+`,
+			map[string][]lang.DiagnosticRelatedInfo{
+				"Invalid reference type": {
+					{
+						Range: hcl.Range{
+							Filename: "variables.tf",
+							Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+							End:      hcl.Pos{Line: 1, Column: 16, Byte: 15},
+						},
+						Message: "var.str is defined here",
+					},
+				},
+			},
+		},
+		{
+			"unexpected order",
+			`resource {
+  name = "foo"
+  count = 1
+}
+`,
+			map[string][]lang.DiagnosticRelatedInfo{
+				"Unexpected order": {
+					{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 3, Byte: 13},
+							End:      hcl.Pos{Line: 2, Column: 7, Byte: 17},
+						},
+						Message: `"name" is declared here`,
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return refTargets
+			})
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, err := d.ValidateFileWithRelatedInfo("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			relatedInfo := make(map[string][]lang.DiagnosticRelatedInfo, 0)
+			for _, diag := range diags {
+				relatedInfo[diag.Summary] = diag.RelatedInfo
+			}
+			if diff := cmp.Diff(tc.expectedRelatedInfo, relatedInfo); diff != "" {
+				t.Fatalf("unexpected related information: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package decoder
+
+import (
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// ValidateFiles returns diagnostics for all loaded files,
+// keyed by filename. See ValidateFile for details.
+//
+// Files which cannot be validated (e.g. due to unknown format)
+// are skipped. Progress is reported via the hook, if any
+// (see SetProgressHook).
+func (d *Decoder) ValidateFiles(opts ...RequestOption) (map[string]hcl.Diagnostics, error) {
+	d = d.withRequestOptions(opts)
+
+	d.rootSchemaMu.RLock()
+	hasSchema := d.rootSchema != nil
+	d.rootSchemaMu.RUnlock()
+
+	if !hasSchema {
+		return map[string]hcl.Diagnostics{}, &NoSchemaError{}
+	}
+
+	diags := make(map[string]hcl.Diagnostics, 0)
+	files := d.Filenames()
+	p := beginProgress(d.progressHook, "Validating files", len(files))
+	defer p.end()
+	for i, filename := range files {
+		p.report(i, filename)
+		fDiags, err := d.ValidateFile(filename)
+		if err != nil {
+			continue
+		}
+		diags[filename] = fDiags
+	}
+
+	return diags, nil
+}
+
+// ChangedDiagnostics compares diagnostics of each file and returns
+// only those of files whose diagnostics changed, such that
+// unchanged diagnostics need not be published again.
+//
+// Files which have diagnostics in previous but are missing in current
+// are returned with empty diagnostics, which clears them when published.
+// The order of diagnostics within a file is not significant.
+func ChangedDiagnostics(previous, current map[string]hcl.Diagnostics) map[string]hcl.Diagnostics {
+	changed := make(map[string]hcl.Diagnostics, 0)
+
+	for filename, diags := range current {
+		if !diagnosticsEqual(previous[filename], diags) {
+			changed[filename] = diags
+		}
+	}
+
+	for filename, diags := range previous {
+		if _, ok := current[filename]; !ok && len(diags) > 0 {
+			changed[filename] = hcl.Diagnostics{}
+		}
+	}
+
+	return changed
+}
+
+// DiagnosticsCheckpoint keeps track of the last published
+// diagnostics, such that only changed ones are published next time.
+//
+// DiagnosticsCheckpoint is safe for concurrent use.
+type DiagnosticsCheckpoint struct {
+	diags map[string]hcl.Diagnostics
+	mu    *sync.Mutex
+}
+
+func NewDiagnosticsCheckpoint() *DiagnosticsCheckpoint {
+	return &DiagnosticsCheckpoint{
+		diags: make(map[string]hcl.Diagnostics, 0),
+		mu:    &sync.Mutex{},
+	}
+}
+
+// Update records diagnostics of all files (e.g. from ValidateFiles)
+// and returns diagnostics of files which changed since the last update,
+// including empty diagnostics for files which are no longer present.
+func (dc *DiagnosticsCheckpoint) Update(diags map[string]hcl.Diagnostics) map[string]hcl.Diagnostics {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	changed := ChangedDiagnostics(dc.diags, diags)
+
+	dc.diags = make(map[string]hcl.Diagnostics, len(diags))
+	for filename, fDiags := range diags {
+		dc.diags[filename] = fDiags
+	}
+
+	return changed
+}
+
+// UpdateFile records diagnostics of a single file (e.g. from ValidateFile)
+// and reports whether they changed since the last update.
+func (dc *DiagnosticsCheckpoint) UpdateFile(filename string, diags hcl.Diagnostics) bool {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	changed := !diagnosticsEqual(dc.diags[filename], diags)
+	dc.diags[filename] = diags
+
+	return changed
+}
+
+type diagnosticKey struct {
+	severity hcl.DiagnosticSeverity
+	summary  string
+	detail   string
+	subject  hcl.Range
+	context  hcl.Range
+}
+
+func keyForDiagnostic(diag *hcl.Diagnostic) diagnosticKey {
+	key := diagnosticKey{
+		severity: diag.Severity,
+		summary:  diag.Summary,
+		detail:   diag.Detail,
+	}
+	if diag.Subject != nil {
+		key.subject = *diag.Subject
+	}
+	if diag.Context != nil {
+		key.context = *diag.Context
+	}
+	return key
+}
+
+// diagnosticsEqual reports whether both sets of diagnostics
+// are equal, regardless of their order
+func diagnosticsEqual(a, b hcl.Diagnostics) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[diagnosticKey]int, len(a))
+	for _, diag := range a {
+		counts[keyForDiagnostic(diag)]++
+	}
+	for _, diag := range b {
+		key := keyForDiagnostic(diag)
+		if counts[key] == 0 {
+			return false
+		}
+		counts[key]--
+	}
+
+	return true
+}
@@ -0,0 +1,170 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestChangedDiagnostics(t *testing.T) {
+	diagA := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unexpected attribute",
+		Subject: &hcl.Range{
+			Filename: "a.tf",
+			Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+			End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+		},
+	}
+	diagB := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid value type",
+		Subject: &hcl.Range{
+			Filename: "a.tf",
+			Start:    hcl.Pos{Line: 2, Column: 1, Byte: 10},
+			End:      hcl.Pos{Line: 2, Column: 4, Byte: 13},
+		},
+	}
+	movedDiagA := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unexpected attribute",
+		Subject: &hcl.Range{
+			Filename: "a.tf",
+			Start:    hcl.Pos{Line: 2, Column: 1, Byte: 10},
+			End:      hcl.Pos{Line: 2, Column: 4, Byte: 13},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		previous map[string]hcl.Diagnostics
+		current  map[string]hcl.Diagnostics
+		expected map[string]hcl.Diagnostics
+	}{
+		{
+			"no change",
+			map[string]hcl.Diagnostics{
+				"a.tf": {diagA, diagB},
+				"b.tf": {},
+			},
+			map[string]hcl.Diagnostics{
+				"a.tf": {diagB, diagA},
+				"b.tf": nil,
+			},
+			map[string]hcl.Diagnostics{},
+		},
+		{
+			"new file",
+			map[string]hcl.Diagnostics{},
+			map[string]hcl.Diagnostics{
+				"a.tf": {diagA},
+				"b.tf": {},
+			},
+			map[string]hcl.Diagnostics{
+				"a.tf": {diagA},
+			},
+		},
+		{
+			"moved diagnostic",
+			map[string]hcl.Diagnostics{
+				"a.tf": {diagA},
+			},
+			map[string]hcl.Diagnostics{
+				"a.tf": {movedDiagA},
+			},
+			map[string]hcl.Diagnostics{
+				"a.tf": {movedDiagA},
+			},
+		},
+		{
+			"removed file",
+			map[string]hcl.Diagnostics{
+				"a.tf": {diagA},
+				"b.tf": {},
+			},
+			map[string]hcl.Diagnostics{},
+			map[string]hcl.Diagnostics{
+				"a.tf": {},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			changed := ChangedDiagnostics(tc.previous, tc.current)
+			if diff := cmp.Diff(tc.expected, changed); diff != "" {
+				t.Fatalf("unexpected changed diagnostics: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsCheckpoint(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"attr": {
+				IsOptional: true,
+				Expr:       schema.LiteralTypeOnly(cty.Number),
+			},
+		},
+	})
+
+	loadFile := func(filename, cfg string) {
+		f, _ := hclsyntax.ParseConfig([]byte(cfg), filename, hcl.InitialPos)
+		err := d.LoadFile(filename, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	loadFile("a.tf", `attr = "foo"
+`)
+	loadFile("b.tf", `attr = 42
+`)
+
+	checkpoint := NewDiagnosticsCheckpoint()
+	diags, err := d.ValidateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := checkpoint.Update(diags)
+	// b.tf has no diagnostics to publish
+	if diff := cmp.Diff([]string{"a.tf"}, sortedFilenames(changed)); diff != "" {
+		t.Fatalf("unexpected files on first update: %s", diff)
+	}
+
+	// only b.tf changes
+	loadFile("b.tf", `attr = true
+`)
+	diags, err = d.ValidateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed = checkpoint.Update(diags)
+	if diff := cmp.Diff([]string{"b.tf"}, sortedFilenames(changed)); diff != "" {
+		t.Fatalf("unexpected files on second update: %s", diff)
+	}
+
+	fDiags, err := d.ValidateFile("b.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.UpdateFile("b.tf", fDiags) {
+		t.Fatal("expected unchanged diagnostics of b.tf")
+	}
+}
+
+func sortedFilenames(diags map[string]hcl.Diagnostics) []string {
+	filenames := make([]string, 0, len(diags))
+	for filename := range diags {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
@@ -0,0 +1,186 @@
+package decoder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// DiagnosticsReport represents diagnostics of a file along with
+// an identifier of the result, compatible with the pull model
+// of diagnostics in LSP (textDocument/diagnostic).
+type DiagnosticsReport struct {
+	// ResultID identifies the result, i.e. the file content, schema
+	// and reference targets which the diagnostics are computed from
+	ResultID string
+
+	// IsUnchanged indicates that the result is the same
+	// as the one identified by the previous result ID,
+	// in which case Diagnostics are not computed (nil).
+	IsUnchanged bool
+
+	Diagnostics hcl.Diagnostics
+}
+
+// ValidateFileWithResultID returns diagnostics for the given file
+// (see ValidateFile) unless the result is unchanged since
+// the report identified by previousResultID.
+//
+// An empty previousResultID always produces new diagnostics.
+// Result IDs are only meaningful within the same process.
+func (d *Decoder) ValidateFileWithResultID(ctx context.Context, filename, previousResultID string, opts ...RequestOption) (DiagnosticsReport, error) {
+	d = d.withRequestOptions(opts)
+
+	if err := ctx.Err(); err != nil {
+		return DiagnosticsReport{}, err
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	resultID, err := d.validationResultID(filename)
+	if err != nil {
+		return DiagnosticsReport{}, err
+	}
+
+	if previousResultID != "" && previousResultID == resultID {
+		return DiagnosticsReport{
+			ResultID:    resultID,
+			IsUnchanged: true,
+		}, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return DiagnosticsReport{}, err
+	}
+
+	diags, err := d.validateFile(filename, nil, nil)
+	if err != nil {
+		return DiagnosticsReport{}, err
+	}
+
+	return DiagnosticsReport{
+		ResultID:    resultID,
+		Diagnostics: diags,
+	}, nil
+}
+
+// validationResultID returns a fingerprint of all inputs of validation
+// of the given file, i.e. its content (including any redefined attributes
+// recovered via LoadFileWithDiagnostics), the schema, functions, reference
+// targets and any settings read by the validators, assuming the read lock
+// of the schema is held
+func (d *Decoder) validationResultID(filename string) (string, error) {
+	b, err := d.bytesForFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(b)
+
+	d.filesMu.RLock()
+	for _, ra := range d.redefinedAttrs[filename] {
+		writeRange(h, ra.attr.SrcRange.Ptr())
+	}
+	d.filesMu.RUnlock()
+	h.Write([]byte("\x00"))
+
+	rev := make([]byte, 8)
+	binary.BigEndian.PutUint64(rev, d.schemaRevision)
+	h.Write(rev)
+
+	// options may differ between requests (see RequestOption)
+	fmt.Fprintf(h, "%+v\x00%+v\x00%+v\x00%d\x00%d\x00", d.features, d.validationOpts,
+		d.addrFormat, d.maxNestingDepth, d.maxIndexTargets)
+
+	if d.diagCodeURLFunc != nil {
+		for _, code := range allDiagnosticCodes() {
+			fmt.Fprintf(h, "%s\x00%s\x00", code, d.diagCodeURLFunc(code))
+		}
+	}
+
+	d.writeFunctions(h)
+
+	readTargets := d.referenceTargetReader()
+	if readTargets != nil {
+		targets := readTargets()
+		writeReferenceTargets(h, targets)
+		if d.features.LiteralReferenceHints {
+			d.writeStaticTargetValues(h, targets)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeFunctions writes signatures of all functions known
+// to the decoder, including functions of the overlay
+func (d *Decoder) writeFunctions(h hash.Hash) {
+	for _, name := range d.functionNames() {
+		sig, _ := d.functionSignature(name)
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00", name, typeString(sig.ReturnType),
+			sig.DeprecatedText, sig.ReplacedBy)
+		params := sig.Params
+		if sig.VarParam != nil {
+			params = append(append([]function.Parameter{}, params...), *sig.VarParam)
+			h.Write([]byte("..."))
+		}
+		for _, param := range params {
+			fmt.Fprintf(h, "%s\x00%s\x00%t\x00", param.Name, typeString(param.Type), param.AllowNull)
+		}
+		h.Write([]byte(";"))
+	}
+}
+
+func writeReferenceTargets(h hash.Hash, targets lang.ReferenceTargets) {
+	for _, target := range targets {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%t\x00%t\x00", target.Addr.String(), target.ScopeId,
+			target.Name, typeString(target.Type), target.Sensitive, target.WriteOnly)
+		writeRange(h, target.RangePtr)
+		for _, alias := range target.Aliases {
+			fmt.Fprintf(h, "%s\x00", alias.String())
+		}
+		h.Write([]byte("{"))
+		writeReferenceTargets(h, target.NestedTargets)
+		h.Write([]byte("}"))
+	}
+}
+
+// writeStaticTargetValues writes statically known values of targets,
+// which are read from other files (see validateLiteralReferences)
+func (d *Decoder) writeStaticTargetValues(h hash.Hash, targets lang.ReferenceTargets) {
+	ReferenceTargets(targets).DeepWalk(func(target lang.ReferenceTarget) error {
+		if target.RangePtr == nil {
+			return nil
+		}
+		if val, ok := d.staticValueOfTarget(*target.RangePtr); ok {
+			writeRange(h, target.RangePtr)
+			h.Write([]byte(val.GoString()))
+		}
+		return nil
+	})
+}
+
+func writeRange(h hash.Hash, rng *hcl.Range) {
+	if rng == nil {
+		h.Write([]byte("-\x00"))
+		return
+	}
+	fmt.Fprintf(h, "%s:%d-%d\x00", rng.Filename, rng.Start.Byte, rng.End.Byte)
+}
+
+func typeString(typ cty.Type) string {
+	if typ == cty.NilType {
+		return ""
+	}
+	return typ.GoString()
+}
@@ -0,0 +1,286 @@
+package decoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_ValidateFileWithResultID(t *testing.T) {
+	ctx := context.Background()
+
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"count": {
+				IsOptional: true,
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.Number},
+				},
+			},
+		},
+	})
+
+	loadFile := func(src string) {
+		f, _ := hclsyntax.ParseConfig([]byte(src), "test.tf", hcl.InitialPos)
+		err := d.LoadFile("test.tf", f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	loadFile("count = var.foo\nunknown = 1\n")
+
+	first, err := d.ValidateFileWithResultID(ctx, "test.tf", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.IsUnchanged || first.ResultID == "" {
+		t.Fatalf("expected new result, given: %#v", first)
+	}
+	if len(first.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, given: %#v", first.Diagnostics)
+	}
+
+	second, err := d.ValidateFileWithResultID(ctx, "test.tf", first.ResultID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.IsUnchanged || second.ResultID != first.ResultID || second.Diagnostics != nil {
+		t.Fatalf("expected unchanged result, given: %#v", second)
+	}
+
+	// content change
+	loadFile("count = var.foo\n")
+	third, err := d.ValidateFileWithResultID(ctx, "test.tf", second.ResultID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.IsUnchanged || third.ResultID == second.ResultID {
+		t.Fatalf("expected new result after content change, given: %#v", third)
+	}
+	if len(third.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, given: %#v", third.Diagnostics)
+	}
+
+	// reference targets change
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return lang.ReferenceTargets{
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "var"},
+					lang.AttrStep{Name: "foo"},
+				},
+				Type: cty.String,
+			},
+		}
+	})
+	fourth, err := d.ValidateFileWithResultID(ctx, "test.tf", third.ResultID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fourth.IsUnchanged || fourth.ResultID == third.ResultID {
+		t.Fatalf("expected new result after reference targets change, given: %#v", fourth)
+	}
+	if len(fourth.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, given: %#v", fourth.Diagnostics)
+	}
+
+	// schema change
+	err = d.PatchSchema(func(bodySchema *schema.BodySchema) error {
+		bodySchema.Attributes["count"].Expr = schema.ExprConstraints{
+			schema.TraversalExpr{OfType: cty.String},
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fifth, err := d.ValidateFileWithResultID(ctx, "test.tf", fourth.ResultID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fifth.IsUnchanged || fifth.ResultID == fourth.ResultID {
+		t.Fatalf("expected new result after schema change, given: %#v", fifth)
+	}
+	if len(fifth.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, given: %#v", fifth.Diagnostics)
+	}
+}
+
+func TestDecoder_ValidateFileWithResultID_canceled(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(schema.NewBodySchema())
+
+	f, _ := hclsyntax.ParseConfig([]byte("\n"), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = d.ValidateFileWithResultID(ctx, "test.tf", "")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, given: %v", err)
+	}
+}
+
+func TestDecoder_ValidateFileWithResultID_inputs(t *testing.T) {
+	cfg := "count = var.foo\ncount = upper(var.foo)\n"
+	targetRange := &hcl.Range{
+		Filename: "variables.tf",
+		Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+		End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+	}
+
+	testCases := []struct {
+		name   string
+		change func(d *Decoder)
+	}{
+		{
+			"functions",
+			func(d *Decoder) {
+				d.SetFunctions(map[string]schema.FunctionSignature{
+					"lower": {ReturnType: cty.String},
+				})
+			},
+		},
+		{
+			"deprecated function",
+			func(d *Decoder) {
+				d.SetFunctions(map[string]schema.FunctionSignature{
+					"upper": {ReturnType: cty.String, DeprecatedText: "deprecated"},
+				})
+			},
+		},
+		{
+			"overlay functions",
+			func(d *Decoder) {
+				d.setOverlay(&RootOverlay{
+					Functions: map[string]schema.FunctionSignature{
+						"upper": {ReturnType: cty.Number},
+					},
+				})
+			},
+		},
+		{
+			"redefined attributes",
+			func(d *Decoder) {
+				f, pDiags := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+				_, err := d.LoadFileWithDiagnostics("test.tf", f, pDiags)
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			"diagnostic code URLs",
+			func(d *Decoder) {
+				d.SetDiagnosticCodeURLFunc(func(code lang.DiagnosticCode) string {
+					return "https://example.com/" + string(code)
+				})
+			},
+		},
+		{
+			"target aliases",
+			func(d *Decoder) {
+				d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+					return lang.ReferenceTargets{
+						{
+							Addr: lang.Address{
+								lang.RootStep{Name: "var"},
+								lang.AttrStep{Name: "foo"},
+							},
+							Aliases: []lang.Address{
+								{
+									lang.RootStep{Name: "variable"},
+									lang.AttrStep{Name: "foo"},
+								},
+							},
+							Type:     cty.String,
+							RangePtr: targetRange,
+						},
+					}
+				})
+			},
+		},
+		{
+			"target range",
+			func(d *Decoder) {
+				d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+					return lang.ReferenceTargets{
+						{
+							Addr: lang.Address{
+								lang.RootStep{Name: "var"},
+								lang.AttrStep{Name: "foo"},
+							},
+							Type: cty.String,
+						},
+					}
+				})
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			ctx := context.Background()
+
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"count": {
+						IsOptional: true,
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfType: cty.Number},
+						},
+					},
+				},
+			})
+			d.SetFunctions(map[string]schema.FunctionSignature{
+				"upper": {ReturnType: cty.String},
+			})
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return lang.ReferenceTargets{
+					{
+						Addr: lang.Address{
+							lang.RootStep{Name: "var"},
+							lang.AttrStep{Name: "foo"},
+						},
+						Type:     cty.String,
+						RangePtr: targetRange,
+					},
+				}
+			})
+
+			f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			first, err := d.ValidateFileWithResultID(ctx, "test.tf", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tc.change(d)
+
+			second, err := d.ValidateFileWithResultID(ctx, "test.tf", first.ResultID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if second.IsUnchanged || second.ResultID == first.ResultID {
+				t.Fatalf("expected new result after change, given: %#v", second)
+			}
+		})
+	}
+}
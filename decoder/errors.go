@@ -3,6 +3,7 @@ package decoder
 import (
 	"fmt"
 
+	"github.com/hashicorp/hcl-lang/lang"
 	"github.com/hashicorp/hcl/v2"
 )
 
@@ -59,8 +60,27 @@ type PositionalError struct {
 	Filename string
 	Pos      hcl.Pos
 	Msg      string
+
+	// Err represents the underlying error, if any
+	Err error
 }
 
 func (e *PositionalError) Error() string {
 	return fmt.Sprintf("%s (%s): %s", e.Filename, stringPos(e.Pos), e.Msg)
 }
+
+func (e *PositionalError) Unwrap() error {
+	return e.Err
+}
+
+type AddressCollisionError struct {
+	Addr  lang.Address
+	Range *hcl.Range
+}
+
+func (e *AddressCollisionError) Error() string {
+	if e.Range != nil {
+		return fmt.Sprintf("%s: address %s is already declared", e.Range, e.Addr)
+	}
+	return fmt.Sprintf("address %s is already declared", e.Addr)
+}
@@ -0,0 +1,95 @@
+package decoder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// ExamplesAtPos returns examples of the innermost block
+// or attribute at the position, e.g. such that the client
+// can offer to insert an example via a code action.
+//
+// Examples of the enclosing block are returned where
+// the innermost block or attribute has no examples.
+func (d *Decoder) ExamplesAtPos(filename string, pos hcl.Pos, opts ...RequestOption) (schema.Examples, error) {
+	d = d.withRequestOptions(opts)
+
+	pos = d.posForFile(filename, pos)
+
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	return d.examplesAtPos(rootBody, d.rootSchema, pos)
+}
+
+func (d *Decoder) examplesAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema, pos hcl.Pos) (schema.Examples, error) {
+	if bodySchema == nil {
+		return nil, nil
+	}
+
+	for _, attr := range body.Attributes {
+		if attr.Range().ContainsPos(pos) {
+			aSchema, ok := attributeSchemaForName(bodySchema, attr.Name)
+			if !ok {
+				return nil, nil
+			}
+			return aSchema.Examples, nil
+		}
+	}
+
+	for _, block := range body.Blocks {
+		if block.Range().ContainsPos(pos) {
+			bSchema, ok := blockSchemaForType(bodySchema, block.Type)
+			if !ok {
+				return nil, nil
+			}
+
+			if block.Body != nil && block.Body.Range().ContainsPos(pos) {
+				mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
+				if err != nil {
+					return nil, err
+				}
+
+				examples, err := d.examplesAtPos(block.Body, mergedSchema, pos)
+				if err != nil || len(examples) > 0 {
+					return examples, err
+				}
+			}
+
+			return bSchema.Examples, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// hoverContentForExamples returns the "Examples" section of hover
+func hoverContentForExamples(examples schema.Examples) string {
+	if len(examples) == 0 {
+		return ""
+	}
+
+	content := "\n\n**Examples**"
+	for _, example := range examples {
+		content += fmt.Sprintf("\n\n_%s_", example.Name)
+		if example.Description.Value != "" {
+			content += fmt.Sprintf("\n\n%s", example.Description.Value)
+		}
+		content += fmt.Sprintf("\n\n```\n%s\n```", strings.TrimSuffix(example.Code, "\n"))
+	}
+
+	return content
+}
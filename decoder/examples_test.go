@@ -0,0 +1,182 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var examplesSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"listener": {
+			Labels: []*schema.LabelSchema{
+				{Name: "name"},
+			},
+			Description: lang.PlainText("Listener of the server"),
+			Examples: schema.Examples{
+				{
+					Name: "HTTP",
+					Code: `listener "http" {
+  port = 80
+}
+`,
+				},
+				{
+					Name:        "HTTPS",
+					Description: lang.PlainText("Requires a certificate"),
+					Code: `listener "https" {
+  port = 443
+  tls {}
+}`,
+				},
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"port": {
+						IsRequired: true,
+						Expr:       schema.LiteralTypeOnly(cty.Number),
+						Examples: schema.Examples{
+							{Name: "Alternative HTTP", Code: "port = 8080"},
+						},
+					},
+					"host": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.String),
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_ExamplesAtPos(t *testing.T) {
+	cfg := `listener "http" {
+  port = 80
+  host = "localhost"
+
+}
+`
+	testCases := []struct {
+		name             string
+		posAfter         string
+		expectedExamples schema.Examples
+	}{
+		{
+			"block type",
+			"liste",
+			examplesSchema.Blocks["listener"].Examples,
+		},
+		{
+			"block body",
+			"\"localhost\"\n",
+			examplesSchema.Blocks["listener"].Examples,
+		},
+		{
+			"attribute",
+			"po",
+			examplesSchema.Blocks["listener"].Body.Attributes["port"].Examples,
+		},
+		{
+			"attribute without examples",
+			"ho",
+			examplesSchema.Blocks["listener"].Examples,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(examplesSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			examples, err := d.ExamplesAtPos("test.tf", posInCfg(t, cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedExamples, examples); diff != "" {
+				t.Fatalf("unexpected examples: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_ExamplesAtPos_outsideOfAnyBlock(t *testing.T) {
+	cfg := `listener "http" {
+  port = 80
+}
+
+`
+	d := NewDecoder()
+	d.SetSchema(examplesSchema)
+
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	examples, err := d.ExamplesAtPos("test.tf", posInCfg(t, cfg, "}\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) > 0 {
+		t.Fatalf("expected no examples, given: %#v", examples)
+	}
+}
+
+func TestDecoder_HoverAtPos_examples(t *testing.T) {
+	cfg := `listener "http" {
+  port = 80
+}
+`
+	testCases := []struct {
+		name            string
+		posAfter        string
+		expectedContent string
+	}{
+		{
+			"block",
+			"liste",
+			"**listener** _Block_\n\nListener of the server\n\n**Examples**" +
+				"\n\n_HTTP_\n\n```\nlistener \"http\" {\n  port = 80\n}\n```" +
+				"\n\n_HTTPS_\n\nRequires a certificate\n\n```\nlistener \"https\" {\n  port = 443\n  tls {}\n}\n```",
+		},
+		{
+			"attribute",
+			"po",
+			"**port** _required, number_\n\n**Examples**\n\n_Alternative HTTP_\n\n```\nport = 8080\n```",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(examplesSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := d.HoverAtPos("test.tf", posInCfg(t, cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedContent, data.Content.Value); diff != "" {
+				t.Fatalf("unexpected hover content: %s", diff)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package decoder
 import (
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/hashicorp/hcl-lang/lang"
@@ -14,16 +13,34 @@ import (
 )
 
 func (d *Decoder) attrValueCandidatesAtPos(attr *hclsyntax.Attribute, schema *schema.AttributeSchema, outerBodyRng hcl.Range, pos hcl.Pos) (lang.Candidates, error) {
-	constraints, editRng := constraintsAtPos(attr.Expr, ExprConstraints(schema.Expr), pos)
+	if _, ok := ExprConstraints(schema.Expr).TraversalExpr(); ok {
+		if ie, ok := indexExprWithKeyAtPos(attr.Expr, pos); ok {
+			return d.indexKeyCandidatesAtPos(ie, outerBodyRng, pos), nil
+		}
+	}
+
+	constraints, editRng := d.constraintsAtPos(attr.Expr, ExprConstraints(schema.Expr), 0, pos)
 	if len(constraints) > 0 {
 		prefixRng := editRng
 		prefixRng.End = pos
-		return d.expressionCandidatesAtPos(constraints, outerBodyRng, prefixRng, editRng)
+		candidates, err := d.expressionCandidatesAtPos(constraints, outerBodyRng, prefixRng, editRng)
+		if err != nil {
+			return candidates, err
+		}
+		if editRng.Start.Byte == attr.Expr.Range().Start.Byte {
+			// the default only applies to the whole value
+			candidates = withDefaultValueCandidate(candidates, schema, editRng, d.valueFormat())
+		}
+		return candidates, nil
 	}
 	return lang.ZeroCandidates(), nil
 }
 
-func constraintsAtPos(expr hcl.Expression, constraints ExprConstraints, pos hcl.Pos) (ExprConstraints, hcl.Range) {
+func (d *Decoder) constraintsAtPos(expr hcl.Expression, constraints ExprConstraints, nestingLvl int, pos hcl.Pos) (ExprConstraints, hcl.Range) {
+	if d.isNestedTooDeep(nestingLvl) {
+		return ExprConstraints{}, expr.Range()
+	}
+
 	// TODO: Support middle-of-expression completion
 
 	// Ideally the edit range should always match the expression range
@@ -64,6 +81,19 @@ func constraintsAtPos(expr hcl.Expression, constraints ExprConstraints, pos hcl.
 				End:      endPos,
 			}
 		}
+	case *hclsyntax.SplatExpr:
+		te, ok := constraints.TraversalExpr()
+		if ok {
+			if eType.Source.Range().ContainsPos(pos) {
+				return d.constraintsAtPos(eType.Source, constraints, nestingLvl+1, pos)
+			}
+			return ExprConstraints{te}, relativeTraversalEditRange(eType, pos)
+		}
+	case *hclsyntax.RelativeTraversalExpr:
+		te, ok := constraints.TraversalExpr()
+		if ok && !eType.Source.Range().ContainsPos(pos) {
+			return ExprConstraints{te}, relativeTraversalEditRange(eType, pos)
+		}
 	case *hclsyntax.TupleConsExpr:
 		rng := eType.Range()
 		tupleConsBody := hcl.Range{
@@ -116,7 +146,12 @@ func constraintsAtPos(expr hcl.Expression, constraints ExprConstraints, pos hcl.
 	case *hclsyntax.ObjectConsExpr:
 		oe, ok := constraints.ObjectExpr()
 		if ok {
-			undeclaredAttributes := oe.Attributes
+			// declared attributes are deleted from a copy, as the schema
+			// is shared with other requests and must not be modified
+			undeclaredAttributes := make(schema.ObjectExprAttributes, len(oe.Attributes))
+			for name, attr := range oe.Attributes {
+				undeclaredAttributes[name] = attr
+			}
 			for _, item := range eType.Items {
 				key, _ := item.KeyExpr.Value(nil)
 				if key.IsNull() || !key.IsWhollyKnown() || key.Type() != cty.String {
@@ -133,7 +168,7 @@ func constraintsAtPos(expr hcl.Expression, constraints ExprConstraints, pos hcl.
 
 				itemRng := hcl.RangeBetween(item.KeyExpr.Range(), item.ValueExpr.Range())
 				if item.ValueExpr.Range().ContainsPos(pos) {
-					return constraintsAtPos(item.ValueExpr, ExprConstraints(attr.Expr), pos)
+					return d.constraintsAtPos(item.ValueExpr, ExprConstraints(attr.Expr), nestingLvl+1, pos)
 				} else if itemRng.ContainsPos(pos) {
 					// middle of attribute name or equal sign
 					return ExprConstraints{}, expr.Range()
@@ -146,30 +181,59 @@ func constraintsAtPos(expr hcl.Expression, constraints ExprConstraints, pos hcl.
 				Filename: eType.Range().Filename,
 			}
 		}
+	case *hclsyntax.ForExpr:
+		c, ok := constraints.constraintForExpr(eType)
+		if ok {
+			return d.forExprConstraintsAtPos(eType, c, nestingLvl, pos)
+		}
 	}
 
 	return ExprConstraints{}, expr.Range()
 }
 
+// relativeTraversalEditRange returns range of a splat expression
+// or relative traversal up to the position, accounting
+// for a trailing dot which may not be part of the expression yet
+func relativeTraversalEditRange(expr hclsyntax.Expression, pos hcl.Pos) hcl.Range {
+	endPos := expr.Range().End
+	if pos.Byte-endPos.Byte == 1 {
+		endPos = pos
+	}
+	return hcl.Range{
+		Filename: expr.Range().Filename,
+		Start:    expr.Range().Start,
+		End:      endPos,
+	}
+}
+
 func (d *Decoder) expressionCandidatesAtPos(constraints ExprConstraints, outerBodyRng, prefixRng, editRng hcl.Range) (lang.Candidates, error) {
 	candidates := lang.NewCandidates()
 
-	for _, c := range constraints {
+	for _, c := range constraints.byPrecedence() {
 		candidates.List = append(candidates.List, d.constraintToCandidates(c, outerBodyRng, prefixRng, editRng)...)
 	}
 
 	candidates.IsComplete = true
-	return candidates, nil
+	candidates.Sort()
+	return d.withFuzzyFilterText(candidates, prefixRng), nil
 }
 
 func (d *Decoder) constraintToCandidates(constraint schema.ExprConstraint, outerBodyRng, prefixRng, editRng hcl.Range) []lang.Candidate {
 	candidates := make([]lang.Candidate, 0)
+	vf := d.valueFormat()
 
 	switch c := constraint.(type) {
 	case schema.LiteralTypeExpr:
-		candidates = append(candidates, typeToCandidates(c.Type, editRng)...)
+		if isSkippedComplexType(c) {
+			break
+		}
+		if c.Type == cty.String && c.Heredoc != schema.HeredocOptional {
+			candidates = append(candidates, heredocCandidate(c, editRng))
+			break
+		}
+		candidates = append(candidates, typeToCandidates(c.Type, editRng, vf)...)
 	case schema.LiteralValue:
-		if c, ok := valueToCandidate(c.Val, c.Description, c.IsDeprecated, editRng); ok {
+		if c, ok := valueToCandidate(c.Val, c.Description, c.IsDeprecated, editRng, vf); ok {
 			candidates = append(candidates, c)
 		}
 	case schema.KeywordExpr:
@@ -188,7 +252,7 @@ func (d *Decoder) constraintToCandidates(constraint schema.ExprConstraint, outer
 		candidates = append(candidates, d.candidatesForTraversalConstraint(c, outerBodyRng, prefixRng, editRng)...)
 	case schema.TupleConsExpr:
 		candidates = append(candidates, lang.Candidate{
-			Label:       fmt.Sprintf(`[%s]`, labelForConstraints(c.AnyElem)),
+			Label:       fmt.Sprintf(`[%s]`, labelForConstraints(c.AnyElem, vf)),
 			Detail:      c.Name,
 			Description: c.Description,
 			Kind:        lang.TupleCandidateKind,
@@ -197,11 +261,11 @@ func (d *Decoder) constraintToCandidates(constraint schema.ExprConstraint, outer
 				Snippet: `[ ${0} ]`,
 				Range:   editRng,
 			},
-			TriggerSuggest: triggerSuggestForExprConstraints(c.AnyElem),
+			Command: triggerSuggestCommand(triggerSuggestForExprConstraints(c.AnyElem)),
 		})
 	case schema.ListExpr:
 		candidates = append(candidates, lang.Candidate{
-			Label:       fmt.Sprintf(`[%s]`, labelForConstraints(c.Elem)),
+			Label:       fmt.Sprintf(`[%s]`, labelForConstraints(c.Elem, vf)),
 			Detail:      c.FriendlyName(),
 			Description: c.Description,
 			Kind:        lang.ListCandidateKind,
@@ -210,11 +274,11 @@ func (d *Decoder) constraintToCandidates(constraint schema.ExprConstraint, outer
 				Snippet: `[ ${0} ]`,
 				Range:   editRng,
 			},
-			TriggerSuggest: triggerSuggestForExprConstraints(c.Elem),
+			Command: triggerSuggestCommand(triggerSuggestForExprConstraints(c.Elem)),
 		})
 	case schema.SetExpr:
 		candidates = append(candidates, lang.Candidate{
-			Label:       fmt.Sprintf(`[%s]`, labelForConstraints(c.Elem)),
+			Label:       fmt.Sprintf(`[%s]`, labelForConstraints(c.Elem, vf)),
 			Detail:      c.FriendlyName(),
 			Description: c.Description,
 			Kind:        lang.SetCandidateKind,
@@ -223,39 +287,43 @@ func (d *Decoder) constraintToCandidates(constraint schema.ExprConstraint, outer
 				Snippet: `[ ${0} ]`,
 				Range:   editRng,
 			},
-			TriggerSuggest: triggerSuggestForExprConstraints(c.Elem),
+			Command: triggerSuggestCommand(triggerSuggestForExprConstraints(c.Elem)),
 		})
 	case schema.TupleExpr:
 		triggerSuggest := false
 		if len(c.Elems) > 0 {
 			triggerSuggest = triggerSuggestForExprConstraints(c.Elems[0])
 		}
+		label, snippet := fmt.Sprintf(`[%s]`, labelForConstraints(c.Elems[0], vf)), `[ ${0} ]`
+		if c.IsNamed() {
+			label, snippet = labelForNamedTuple(c), snippetForNamedTuple(1, c)
+		}
 		candidates = append(candidates, lang.Candidate{
-			Label:       fmt.Sprintf(`[%s]`, labelForConstraints(c.Elems[0])),
+			Label:       label,
 			Detail:      c.FriendlyName(),
 			Description: c.Description,
 			Kind:        lang.TupleCandidateKind,
 			TextEdit: lang.TextEdit{
 				NewText: `[ ]`,
-				Snippet: `[ ${0} ]`,
+				Snippet: snippet,
 				Range:   editRng,
 			},
-			TriggerSuggest: triggerSuggest,
+			Command: triggerSuggestCommand(triggerSuggest),
 		})
 	case schema.MapExpr:
 		candidates = append(candidates, lang.Candidate{
-			Label:       fmt.Sprintf(`{ key =%s}`, labelForConstraints(c.Elem)),
+			Label:       fmt.Sprintf(`{ key %s%s}`, vf.objectItemSep, labelForConstraints(c.Elem, vf)),
 			Detail:      c.FriendlyName(),
 			Description: c.Description,
 			Kind:        lang.MapCandidateKind,
 			TextEdit: lang.TextEdit{
-				NewText: fmt.Sprintf("{\n  name = %s\n}",
-					newTextForConstraints(c.Elem, true)),
-				Snippet: fmt.Sprintf("{\n  ${1:name} = %s\n}",
-					snippetForConstraints(1, c.Elem, true)),
+				NewText: fmt.Sprintf("{\n  name %s %s\n}", vf.objectItemSep,
+					newTextForConstraints(c.Elem, true, vf)),
+				Snippet: fmt.Sprintf("{\n  ${1:name} %s %s\n}", vf.objectItemSep,
+					snippetForConstraints(1, c.Elem, true, vf)),
 				Range: editRng,
 			},
-			TriggerSuggest: triggerSuggestForExprConstraints(c.Elem),
+			Command: triggerSuggestCommand(triggerSuggestForExprConstraints(c.Elem)),
 		})
 	case schema.ObjectExpr:
 		candidates = append(candidates, lang.Candidate{
@@ -268,12 +336,17 @@ func (d *Decoder) constraintToCandidates(constraint schema.ExprConstraint, outer
 				Snippet: "{\n  ${1}\n}",
 				Range:   editRng,
 			},
-			TriggerSuggest: len(c.Attributes) > 0,
+			Command: triggerSuggestCommand(len(c.Attributes) > 0),
 		})
 	case schema.ObjectExprAttributes:
 		attrNames := sortedObjectExprAttrNames(c)
 		for _, name := range attrNames {
 			attr := c[name]
+			newText := newTextForConstraints(attr.Expr, true, vf)
+			snippet := snippetForConstraints(1, attr.Expr, true, vf)
+			if defNewText, defSnippet, ok := defaultValueText(attr, vf); ok {
+				newText, snippet = defNewText, defSnippet
+			}
 			candidates = append(candidates, lang.Candidate{
 				Label:        name,
 				Detail:       detailForAttribute(attr),
@@ -281,8 +354,8 @@ func (d *Decoder) constraintToCandidates(constraint schema.ExprConstraint, outer
 				Description:  attr.Description,
 				Kind:         lang.AttributeCandidateKind,
 				TextEdit: lang.TextEdit{
-					NewText: fmt.Sprintf("%s = %s", name, newTextForConstraints(attr.Expr, true)),
-					Snippet: fmt.Sprintf("%s = %s", name, snippetForConstraints(1, attr.Expr, true)),
+					NewText: fmt.Sprintf("%s %s %s", name, vf.objectItemSep, newText),
+					Snippet: fmt.Sprintf("%s %s %s", name, vf.objectItemSep, snippet),
 					Range:   editRng,
 				},
 			})
@@ -304,7 +377,7 @@ func (d *Decoder) constraintToCandidates(constraint schema.ExprConstraint, outer
 				Kind:   lang.AttributeCandidateKind,
 				TextEdit: lang.TextEdit{
 					NewText: t,
-					Snippet: snippetForTypeDeclaration(t),
+					Snippet: snippetForTypeDeclaration(t, vf),
 					Range:   editRng,
 				},
 			})
@@ -317,20 +390,26 @@ func (d *Decoder) constraintToCandidates(constraint schema.ExprConstraint, outer
 func (d *Decoder) candidatesForTraversalConstraint(tc schema.TraversalExpr, outerBodyRng, prefixRng, editRng hcl.Range) []lang.Candidate {
 	candidates := make([]lang.Candidate, 0)
 
-	if d.refTargetReader == nil {
-		return candidates
-	}
-
 	if tc.Address != nil {
 		// no candidates if traversal itself is addressable
 		return candidates
 	}
 
-	prefix, _ := d.bytesFromRange(prefixRng)
+	prefix := d.prefixFromRange(prefixRng)
+
+	relCandidates, ok := d.candidatesForRelativeTraversal(tc, prefix, prefixRng, editRng)
+	if ok {
+		return relCandidates
+	}
+
+	var refs ReferenceTargets
+	if readTargets := d.referenceTargetReader(); readTargets != nil {
+		refs = ReferenceTargets(readTargets())
+	}
 
-	refs := ReferenceTargets(d.refTargetReader())
+	match := d.referenceMatch(string(prefix))
 
-	refs.MatchWalk(tc, string(prefix), func(ref lang.ReferenceTarget) error {
+	refs.matchWalk(tc, match, func(ref lang.ReferenceTarget) error {
 		// avoid suggesting references to block's own fields from within (for now)
 		if ref.RangePtr != nil &&
 			(outerBodyRng.ContainsPos(ref.RangePtr.Start) ||
@@ -338,54 +417,106 @@ func (d *Decoder) candidatesForTraversalConstraint(tc schema.TraversalExpr, oute
 			return nil
 		}
 
-		candidates = append(candidates, lang.Candidate{
-			Label:       ref.Addr.String(),
-			Detail:      ref.FriendlyName(),
-			Description: ref.Description,
-			Kind:        lang.TraversalCandidateKind,
-			TextEdit: lang.TextEdit{
-				NewText: ref.Addr.String(),
-				Snippet: ref.Addr.String(),
-				Range:   editRng,
-			},
-		})
+		candidates = append(candidates, d.referenceTargetCandidate(ref, prefixRng, editRng))
 		return nil
 	})
 
+	if len(candidates) == 0 && d.features.ScopeFallbackCompletion &&
+		tc.OfType == cty.DynamicPseudoType {
+		refs.scopeMatchWalk(tc, match, func(ref lang.ReferenceTarget) error {
+			if ref.RangePtr != nil &&
+				(outerBodyRng.ContainsPos(ref.RangePtr.Start) ||
+					posEqual(outerBodyRng.End, ref.RangePtr.End)) {
+				return nil
+			}
+
+			candidate := d.referenceTargetCandidate(ref, prefixRng, editRng)
+			candidate.Detail = "unknown type, " + candidate.Detail
+			candidates = append(candidates, candidate)
+			return nil
+		})
+	}
+
+	if tc.OfType != cty.NilType {
+		// values of a type may also come from function calls
+		candidates = append(candidates, d.functionCandidates(string(prefix), tc.OfType, editRng)...)
+	}
+
 	return candidates
 }
 
-func newTextForConstraints(cons schema.ExprConstraints, isNested bool) string {
-	for _, constraint := range cons {
+func (d *Decoder) referenceTargetCandidate(ref lang.ReferenceTarget, prefixRng, editRng hcl.Range) lang.Candidate {
+	addr := d.addrFormat.Format(ref.Addr)
+	detail := ref.FriendlyName()
+	if ref.WriteOnly {
+		detail = "write-only, " + detail
+	}
+	if ref.Sensitive {
+		detail = "sensitive, " + detail
+	}
+	candidate := lang.Candidate{
+		Label:       addr,
+		Detail:      detail,
+		Description: ref.Description,
+		Kind:        lang.TraversalCandidateKind,
+		TextEdit: lang.TextEdit{
+			NewText: addr,
+			Snippet: escapeSnippetText(addr),
+			Range:   editRng,
+		},
+		Score: referenceTargetScore(ref, prefixRng.Filename, prefixRng.End),
+	}
+
+	if label := truncateAddress(ref.Addr, d.addrFormat, d.refCandidateOpts.MaxLabelLength); label != addr {
+		candidate = withFullAddress(candidate, addr)
+		candidate.Label = label
+	}
+	if d.refCandidateOpts.MatchAnySegment {
+		// the client would otherwise filter out
+		// candidates matched in the middle of the address
+		prefix := string(d.prefixFromRange(prefixRng))
+		if !strings.HasPrefix(addr, prefix) {
+			candidate.FilterText = prefix
+		}
+	}
+
+	return candidate
+}
+
+func newTextForConstraints(cons schema.ExprConstraints, isNested bool, vf valueFormat) string {
+	for _, constraint := range cons.ByPrecedence() {
+		if isSkippedComplexType(constraint) {
+			continue
+		}
 		switch c := constraint.(type) {
 		case schema.LiteralTypeExpr:
-			return newTextForLiteralType(c.Type)
+			return newTextForLiteralType(c.Type, vf)
 		case schema.LiteralValue:
-			return newTextForLiteralValue(c.Val)
+			return newTextForLiteralValue(c.Val, vf)
 		case schema.KeywordExpr:
 			return c.Keyword
 		case schema.TupleConsExpr:
 			if isNested {
 				return "[  ]"
 			}
-			return fmt.Sprintf("[\n  %s\n]", newTextForConstraints(c.AnyElem, true))
+			return fmt.Sprintf("[\n  %s\n]", newTextForConstraints(c.AnyElem, true, vf))
 		case schema.ListExpr:
 			if isNested {
 				return "[  ]"
 			}
-			return fmt.Sprintf("[\n  %s\n]", newTextForConstraints(c.Elem, true))
+			return fmt.Sprintf("[\n  %s\n]", newTextForConstraints(c.Elem, true, vf))
 		case schema.SetExpr:
 			if isNested {
 				return "[  ]"
 			}
-			return fmt.Sprintf("[\n  %s\n]", newTextForConstraints(c.Elem, true))
+			return fmt.Sprintf("[\n  %s\n]", newTextForConstraints(c.Elem, true, vf))
 		case schema.TupleExpr:
 			if isNested {
 				return "[  ]"
 			}
-			return fmt.Sprintf("[\n  %s\n]", newTextForConstraints(c.Elems[0], true))
+			return fmt.Sprintf("[\n  %s\n]", newTextForConstraints(c.Elems[0], true, vf))
 		case schema.MapExpr:
-			return fmt.Sprintf("{\n  %s\n}", newTextForConstraints(c.Elem, true))
+			return fmt.Sprintf("{\n  %s\n}", newTextForConstraints(c.Elem, true, vf))
 		case schema.ObjectExpr:
 			return "{\n  \n}"
 		}
@@ -393,7 +524,7 @@ func newTextForConstraints(cons schema.ExprConstraints, isNested bool) string {
 	return ""
 }
 
-func snippetForTypeDeclaration(td string) string {
+func snippetForTypeDeclaration(td string, vf valueFormat) string {
 	switch td {
 	case "list()":
 		return "list(${0})"
@@ -404,43 +535,46 @@ func snippetForTypeDeclaration(td string) string {
 	case "map()":
 		return "map(${0})"
 	case "object({})":
-		return "object({\n ${1:name} = ${2}\n})"
+		return fmt.Sprintf("object({\n ${1:name} %s ${2}\n})", vf.objectItemSep)
 	default:
 		return td
 	}
 }
 
-func snippetForConstraints(placeholder uint, cons schema.ExprConstraints, isNested bool) string {
-	for _, constraint := range cons {
+func snippetForConstraints(placeholder uint, cons schema.ExprConstraints, isNested bool, vf valueFormat) string {
+	for _, constraint := range cons.ByPrecedence() {
+		if isSkippedComplexType(constraint) {
+			continue
+		}
 		switch c := constraint.(type) {
 		case schema.LiteralTypeExpr:
-			return snippetForLiteralType(placeholder, c.Type)
+			return snippetForLiteralType(placeholder, c.Type, vf)
 		case schema.LiteralValue:
-			return snippetForLiteralValue(placeholder, c.Val)
+			return snippetForLiteralValue(placeholder, c.Val, vf)
 		case schema.KeywordExpr:
 			return fmt.Sprintf("${%d:%s}", placeholder, c.Keyword)
 		case schema.TupleConsExpr:
 			if isNested {
 				return fmt.Sprintf("[ ${%d} ]", placeholder+1)
 			}
-			return fmt.Sprintf("[\n  %s\n]", snippetForConstraints(placeholder+1, c.AnyElem, true))
+			return fmt.Sprintf("[\n  %s\n]", snippetForConstraints(placeholder+1, c.AnyElem, true, vf))
 		case schema.ListExpr:
 			if isNested {
 				return fmt.Sprintf("[ ${%d} ]", placeholder+1)
 			}
-			return fmt.Sprintf("[\n  %s\n]", snippetForConstraints(placeholder+1, c.Elem, true))
+			return fmt.Sprintf("[\n  %s\n]", snippetForConstraints(placeholder+1, c.Elem, true, vf))
 		case schema.SetExpr:
 			if isNested {
 				return fmt.Sprintf("[ ${%d} ]", placeholder+1)
 			}
-			return fmt.Sprintf("[\n  %s\n]", snippetForConstraints(placeholder+1, c.Elem, true))
+			return fmt.Sprintf("[\n  %s\n]", snippetForConstraints(placeholder+1, c.Elem, true, vf))
 		case schema.TupleExpr:
 			if isNested {
 				return fmt.Sprintf("[ ${%d} ]", placeholder+1)
 			}
-			return fmt.Sprintf("[\n  %s\n]", snippetForConstraints(placeholder+1, c.Elems[0], true))
+			return fmt.Sprintf("[\n  %s\n]", snippetForConstraints(placeholder+1, c.Elems[0], true, vf))
 		case schema.MapExpr:
-			return fmt.Sprintf("{\n  %s\n}", snippetForConstraints(placeholder+1, c.Elem, true))
+			return fmt.Sprintf("{\n  %s\n}", snippetForConstraints(placeholder+1, c.Elem, true, vf))
 		case schema.ObjectExpr:
 			return fmt.Sprintf("{\n  ${%d}\n}", placeholder+1)
 		}
@@ -448,10 +582,13 @@ func snippetForConstraints(placeholder uint, cons schema.ExprConstraints, isNest
 	return ""
 }
 
-func labelForConstraints(cons schema.ExprConstraints) string {
+func labelForConstraints(cons schema.ExprConstraints, vf valueFormat) string {
 	labels := " "
 	labelsAdded := 0
-	for _, constraint := range cons {
+	for _, constraint := range cons.ByPrecedence() {
+		if isSkippedComplexType(constraint) {
+			continue
+		}
 		if len(labels) > 10 {
 			labels += "…"
 			break
@@ -461,7 +598,7 @@ func labelForConstraints(cons schema.ExprConstraints) string {
 		}
 		switch c := constraint.(type) {
 		case schema.LiteralTypeExpr:
-			labels += labelForLiteralType(c.Type)
+			labels += labelForLiteralType(c.Type, vf)
 		case schema.LiteralValue:
 			continue
 		case schema.KeywordExpr:
@@ -469,13 +606,17 @@ func labelForConstraints(cons schema.ExprConstraints) string {
 		case schema.TraversalExpr:
 			labels += c.FriendlyName()
 		case schema.TupleConsExpr:
-			labels += fmt.Sprintf("[%s]", labelForConstraints(c.AnyElem))
+			labels += fmt.Sprintf("[%s]", labelForConstraints(c.AnyElem, vf))
 		case schema.ListExpr:
-			labels += fmt.Sprintf("[%s]", labelForConstraints(c.Elem))
+			labels += fmt.Sprintf("[%s]", labelForConstraints(c.Elem, vf))
 		case schema.SetExpr:
-			labels += fmt.Sprintf("[%s]", labelForConstraints(c.Elem))
+			labels += fmt.Sprintf("[%s]", labelForConstraints(c.Elem, vf))
 		case schema.TupleExpr:
-			labels += fmt.Sprintf("[%s]", labelForConstraints(c.Elems[0]))
+			if c.IsNamed() {
+				labels += labelForNamedTuple(c)
+				break
+			}
+			labels += fmt.Sprintf("[%s]", labelForConstraints(c.Elems[0], vf))
 		}
 		labelsAdded++
 	}
@@ -484,17 +625,17 @@ func labelForConstraints(cons schema.ExprConstraints) string {
 	return labels
 }
 
-func typeToCandidates(ofType cty.Type, editRng hcl.Range) []lang.Candidate {
+func typeToCandidates(ofType cty.Type, editRng hcl.Range, vf valueFormat) []lang.Candidate {
 	candidates := make([]lang.Candidate, 0)
 
 	// TODO: Ensure TextEdit is always single-line, otherwise use AdditionalTextEdit
 	// See https://github.com/microsoft/language-server-protocol/issues/92
 
 	if ofType == cty.Bool {
-		if c, ok := valueToCandidate(cty.True, lang.MarkupContent{}, false, editRng); ok {
+		if c, ok := valueToCandidate(cty.True, lang.MarkupContent{}, false, editRng, vf); ok {
 			candidates = append(candidates, c)
 		}
-		if c, ok := valueToCandidate(cty.False, lang.MarkupContent{}, false, editRng); ok {
+		if c, ok := valueToCandidate(cty.False, lang.MarkupContent{}, false, editRng, vf); ok {
 			candidates = append(candidates, c)
 		}
 		return candidates
@@ -506,12 +647,12 @@ func typeToCandidates(ofType cty.Type, editRng hcl.Range) []lang.Candidate {
 	}
 
 	candidates = append(candidates, lang.Candidate{
-		Label:  labelForLiteralType(ofType),
+		Label:  labelForLiteralType(ofType, vf),
 		Detail: ofType.FriendlyNameForConstraint(),
 		Kind:   candidateKindForType(ofType),
 		TextEdit: lang.TextEdit{
-			NewText: newTextForLiteralType(ofType),
-			Snippet: snippetForLiteralType(1, ofType),
+			NewText: newTextForLiteralType(ofType, vf),
+			Snippet: snippetForLiteralType(1, ofType, vf),
 			Range:   editRng,
 		},
 	})
@@ -519,7 +660,7 @@ func typeToCandidates(ofType cty.Type, editRng hcl.Range) []lang.Candidate {
 	return candidates
 }
 
-func valueToCandidate(val cty.Value, desc lang.MarkupContent, isDeprecated bool, editRng hcl.Range) (lang.Candidate, bool) {
+func valueToCandidate(val cty.Value, desc lang.MarkupContent, isDeprecated bool, editRng hcl.Range, vf valueFormat) (lang.Candidate, bool) {
 	if !val.IsWhollyKnown() {
 		// Avoid unknown values
 		return lang.Candidate{}, false
@@ -545,14 +686,14 @@ func valueToCandidate(val cty.Value, desc lang.MarkupContent, isDeprecated bool,
 	}
 
 	return lang.Candidate{
-		Label:        labelForLiteralValue(val, false),
+		Label:        labelForLiteralValue(val, false, vf),
 		Detail:       detail,
 		Description:  desc,
 		IsDeprecated: isDeprecated,
 		Kind:         candidateKindForType(val.Type()),
 		TextEdit: lang.TextEdit{
-			NewText: newTextForLiteralValue(val),
-			Snippet: snippetForLiteralValue(1, val),
+			NewText: newTextForLiteralValue(val, vf),
+			Snippet: snippetForLiteralValue(1, val, vf),
 			Range:   editRng,
 		},
 	}, true
@@ -588,8 +729,7 @@ func candidateKindForType(t cty.Type) lang.CandidateKind {
 }
 
 func triggerSuggestForExprConstraints(ec schema.ExprConstraints) bool {
-	if len(ec) > 0 {
-		expr := ec[0]
+	if expr, ok := firstForSnippet(ec); ok {
 		switch et := expr.(type) {
 		case schema.LiteralTypeExpr:
 			if et.Type == cty.Bool {
@@ -624,16 +764,18 @@ func triggerSuggestForExprConstraints(ec schema.ExprConstraints) bool {
 	return false
 }
 
-func snippetForExprContraints(placeholder uint, ec schema.ExprConstraints) string {
-	if len(ec) > 0 {
-		expr := ec[0]
+func snippetForExprContraints(placeholder uint, ec schema.ExprConstraints, vf valueFormat) string {
+	if expr, ok := firstForSnippet(ec); ok {
 
 		switch et := expr.(type) {
 		case schema.LiteralTypeExpr:
-			return snippetForLiteralType(placeholder, et.Type)
+			if et.Type == cty.String && et.Heredoc != schema.HeredocOptional {
+				return snippetForHeredoc(placeholder, et.Heredoc, 0)
+			}
+			return snippetForLiteralType(placeholder, et.Type, vf)
 		case schema.LiteralValue:
 			if len(ec) == 1 {
-				return snippetForLiteralValue(placeholder, et.Val)
+				return snippetForLiteralValue(placeholder, et.Val, vf)
 			}
 			return ""
 		case schema.TupleConsExpr:
@@ -662,9 +804,9 @@ func snippetForExprContraints(placeholder uint, ec schema.ExprConstraints) strin
 			}
 			return "[\n  ${0}\n]"
 		case schema.MapExpr:
-			return fmt.Sprintf("{\n  ${%d:name} = %s\n }",
-				placeholder,
-				snippetForExprContraints(placeholder+1, et.Elem))
+			return fmt.Sprintf("{\n  ${%d:name} %s %s\n }",
+				placeholder, vf.objectItemSep,
+				snippetForExprContraints(placeholder+1, et.Elem, vf))
 		case schema.ObjectExpr:
 			return fmt.Sprintf("{\n  ${%d}\n }", placeholder+1)
 		}
@@ -675,10 +817,11 @@ func snippetForExprContraints(placeholder uint, ec schema.ExprConstraints) strin
 
 type snippetGenerator struct {
 	placeholder uint
+	vf          valueFormat
 }
 
-func snippetForLiteralType(placeholder uint, attrType cty.Type) string {
-	sg := &snippetGenerator{placeholder: placeholder}
+func snippetForLiteralType(placeholder uint, attrType cty.Type, vf valueFormat) string {
+	sg := &snippetGenerator{placeholder: placeholder, vf: vf}
 	return sg.forLiteralType(attrType, 0)
 }
 
@@ -703,7 +846,7 @@ func (sg *snippetGenerator) forLiteralType(attrType cty.Type, nestingLvl int) st
 
 	if attrType.IsMapType() {
 		mapSnippet := "{\n"
-		mapSnippet += fmt.Sprintf(`%s"${%d:key}" = `, nesting, sg.placeholder)
+		mapSnippet += fmt.Sprintf(`%s"${%d:key}" %s `, nesting, sg.placeholder, sg.vf.objectItemSep)
 		sg.placeholder++
 		mapSnippet += sg.forLiteralType(*attrType.MapElementType(), nestingLvl+1)
 		mapSnippet += fmt.Sprintf("\n%s}", endBraceNesting)
@@ -720,8 +863,8 @@ func (sg *snippetGenerator) forLiteralType(attrType cty.Type, nestingLvl int) st
 		for _, name := range sortedObjectAttrNames(attrType) {
 			valType := attrType.AttributeType(name)
 
-			objSnippet += fmt.Sprintf("%s%s = %s\n",
-				nesting, name, sg.forLiteralType(valType, nestingLvl+1))
+			objSnippet += fmt.Sprintf("%s%s %s %s\n",
+				nesting, name, sg.vf.objectItemSep, sg.forLiteralType(valType, nestingLvl+1))
 		}
 		return fmt.Sprintf("{\n%s%s}", objSnippet, endBraceNesting)
 	}
@@ -742,7 +885,7 @@ func (sg *snippetGenerator) forLiteralType(attrType cty.Type, nestingLvl int) st
 	return ""
 }
 
-func labelForLiteralValue(val cty.Value, isNested bool) string {
+func labelForLiteralValue(val cty.Value, isNested bool, vf valueFormat) string {
 	if !val.IsWhollyKnown() {
 		return ""
 	}
@@ -756,7 +899,7 @@ func labelForLiteralValue(val cty.Value, isNested bool) string {
 		}
 		return val.AsString()
 	case cty.Number:
-		return formatNumberVal(val)
+		return vf.number.Format(val)
 	}
 
 	if val.Type().IsMapType() {
@@ -773,8 +916,8 @@ func labelForLiteralValue(val cty.Value, isNested bool) string {
 				break
 			}
 
-			label += fmt.Sprintf("%q = %s",
-				key, labelForLiteralValue(valueMap[key], true))
+			label += fmt.Sprintf("%q %s %s",
+				key, vf.objectItemSep, labelForLiteralValue(valueMap[key], true, vf))
 			i++
 		}
 		label += ` }`
@@ -792,7 +935,7 @@ func labelForLiteralValue(val cty.Value, isNested bool) string {
 				break
 			}
 
-			label += labelForLiteralValue(elem, true)
+			label += labelForLiteralValue(elem, true, vf)
 
 		}
 		label += ` ]`
@@ -813,7 +956,7 @@ func labelForLiteralValue(val cty.Value, isNested bool) string {
 			}
 			val := val.GetAttr(name)
 
-			label += fmt.Sprintf("%s = %s", name, labelForLiteralValue(val, true))
+			label += fmt.Sprintf("%s %s %s", name, vf.objectItemSep, labelForLiteralValue(val, true, vf))
 			i++
 		}
 
@@ -824,46 +967,33 @@ func labelForLiteralValue(val cty.Value, isNested bool) string {
 	return ""
 }
 
-func formatNumberVal(val cty.Value) string {
-	bf := val.AsBigFloat()
-
-	if bf.IsInt() {
-		intNum, _ := bf.Int64()
-		return fmt.Sprintf("%d", intNum)
-	}
-
-	fNum, _ := bf.Float64()
-	return strconv.FormatFloat(fNum, 'f', -1, 64)
-
-}
-
-func labelForLiteralType(attrType cty.Type) string {
+func labelForLiteralType(attrType cty.Type, vf valueFormat) string {
 	if attrType.IsMapType() {
 		elType := *attrType.MapElementType()
-		return fmt.Sprintf(`{ "key" = %s }`,
-			labelForLiteralType(elType))
+		return fmt.Sprintf(`{ "key" %s %s }`, vf.objectItemSep,
+			labelForLiteralType(elType, vf))
 	}
 
 	if attrType.IsListType() || attrType.IsSetType() {
 		elType := attrType.ElementType()
 		return fmt.Sprintf(`[ %s ]`,
-			labelForLiteralType(elType))
+			labelForLiteralType(elType, vf))
 	}
 
 	if attrType.IsTupleType() {
 		elTypes := attrType.TupleElementTypes()
 		if len(elTypes) > 2 {
 			return fmt.Sprintf("[ %s , %s , … ]",
-				labelForLiteralType(elTypes[0]),
-				labelForLiteralType(elTypes[1]))
+				labelForLiteralType(elTypes[0], vf),
+				labelForLiteralType(elTypes[1], vf))
 		}
 		if len(elTypes) == 2 {
 			return fmt.Sprintf("[ %s , %s ]",
-				labelForLiteralType(elTypes[0]),
-				labelForLiteralType(elTypes[1]))
+				labelForLiteralType(elTypes[0], vf),
+				labelForLiteralType(elTypes[1], vf))
 		}
 		if len(elTypes) == 1 {
-			return fmt.Sprintf("[ %s ]", labelForLiteralType(elTypes[0]))
+			return fmt.Sprintf("[ %s ]", labelForLiteralType(elTypes[0], vf))
 		}
 		return "[ ]"
 	}
@@ -880,9 +1010,9 @@ func labelForLiteralType(attrType cty.Type) string {
 				break
 			}
 
-			label += fmt.Sprintf("%s = %s",
-				attrName,
-				labelForLiteralType(attrType.AttributeType(attrName)))
+			label += fmt.Sprintf("%s %s %s",
+				attrName, vf.objectItemSep,
+				labelForLiteralType(attrType.AttributeType(attrName), vf))
 		}
 		label += " }"
 		return label
@@ -891,14 +1021,14 @@ func labelForLiteralType(attrType cty.Type) string {
 	return attrType.FriendlyNameForConstraint()
 }
 
-func newTextForLiteralValue(val cty.Value) string {
+func newTextForLiteralValue(val cty.Value, vf valueFormat) string {
 	switch val.Type() {
 	case cty.String:
 		return fmt.Sprintf("%q", val.AsString())
 	case cty.Bool:
 		return fmt.Sprintf("%t", val.True())
 	case cty.Number:
-		return formatNumberVal(val)
+		return vf.number.Format(val)
 	case cty.DynamicPseudoType:
 		return ""
 	}
@@ -908,8 +1038,8 @@ func newTextForLiteralValue(val cty.Value) string {
 		valueMap := val.AsValueMap()
 		mapKeys := sortedKeysOfValueMap(valueMap)
 		for _, key := range mapKeys {
-			newText += fmt.Sprintf("  %q = %s\n",
-				key, newTextForLiteralValue(valueMap[key]))
+			newText += fmt.Sprintf("  %q %s %s\n",
+				key, vf.objectItemSep, newTextForLiteralValue(valueMap[key], vf))
 		}
 		newText += "}"
 		return newText
@@ -918,7 +1048,7 @@ func newTextForLiteralValue(val cty.Value) string {
 	if val.Type().IsListType() || val.Type().IsSetType() || val.Type().IsTupleType() {
 		newText := "[\n"
 		for _, elem := range val.AsValueSlice() {
-			newText += fmt.Sprintf("  %s,\n", newTextForLiteralValue(elem))
+			newText += fmt.Sprintf("  %s,\n", newTextForLiteralValue(elem, vf))
 		}
 		newText += "]"
 		return newText
@@ -929,7 +1059,7 @@ func newTextForLiteralValue(val cty.Value) string {
 		attrNames := sortedObjectAttrNames(val.Type())
 		for _, name := range attrNames {
 			v := val.GetAttr(name)
-			newText += fmt.Sprintf("  %s = %s\n", name, newTextForLiteralValue(v))
+			newText += fmt.Sprintf("  %s %s %s\n", name, vf.objectItemSep, newTextForLiteralValue(v, vf))
 		}
 		newText += "}"
 		return newText
@@ -938,8 +1068,8 @@ func newTextForLiteralValue(val cty.Value) string {
 	return ""
 }
 
-func snippetForLiteralValue(placeholder uint, val cty.Value) string {
-	sg := &snippetGenerator{placeholder: placeholder}
+func snippetForLiteralValue(placeholder uint, val cty.Value, vf valueFormat) string {
+	sg := &snippetGenerator{placeholder: placeholder, vf: vf}
 	return sg.forLiteralValue(val, 0)
 }
 
@@ -953,7 +1083,7 @@ func (sg *snippetGenerator) forLiteralValue(val cty.Value, nestingLvl int) strin
 		return fmt.Sprintf(`${%d:%t}`, sg.placeholder-1, val.True())
 	case cty.Number:
 		sg.placeholder++
-		return fmt.Sprintf(`${%d:%s}`, sg.placeholder-1, formatNumberVal(val))
+		return fmt.Sprintf(`${%d:%s}`, sg.placeholder-1, sg.vf.number.Format(val))
 	case cty.DynamicPseudoType:
 		sg.placeholder++
 		return fmt.Sprintf(`${%d}`, sg.placeholder-1)
@@ -967,7 +1097,7 @@ func (sg *snippetGenerator) forLiteralValue(val cty.Value, nestingLvl int) strin
 		valueMap := val.AsValueMap()
 		mapKeys := sortedKeysOfValueMap(valueMap)
 		for _, key := range mapKeys {
-			mapSnippet += fmt.Sprintf(`%s"${%d:%s}" = `, nesting, sg.placeholder, key)
+			mapSnippet += fmt.Sprintf(`%s"${%d:%s}" %s `, nesting, sg.placeholder, key, sg.vf.objectItemSep)
 			sg.placeholder++
 			mapSnippet += sg.forLiteralValue(valueMap[key], nestingLvl+1)
 			mapSnippet += "\n"
@@ -989,8 +1119,8 @@ func (sg *snippetGenerator) forLiteralValue(val cty.Value, nestingLvl int) strin
 		snippet := "{\n"
 		for _, name := range sortedObjectAttrNames(val.Type()) {
 			v := val.GetAttr(name)
-			snippet += fmt.Sprintf("%s%s = %s\n",
-				nesting, name, sg.forLiteralValue(v, nestingLvl+1))
+			snippet += fmt.Sprintf("%s%s %s %s\n",
+				nesting, name, sg.vf.objectItemSep, sg.forLiteralValue(v, nestingLvl+1))
 		}
 		snippet += fmt.Sprintf("%s}", endBraceNesting)
 		return snippet
@@ -1008,7 +1138,7 @@ func sortedKeysOfValueMap(m map[string]cty.Value) []string {
 	return keys
 }
 
-func newTextForLiteralType(attrType cty.Type) string {
+func newTextForLiteralType(attrType cty.Type, vf valueFormat) string {
 	switch attrType {
 	case cty.String:
 		return `""`
@@ -1022,13 +1152,13 @@ func newTextForLiteralType(attrType cty.Type) string {
 
 	if attrType.IsMapType() {
 		elType := *attrType.MapElementType()
-		return fmt.Sprintf("{\n"+`  "key" = %s`+"\n}",
-			newTextForLiteralType(elType))
+		return fmt.Sprintf("{\n"+`  "key" %s %s`+"\n}", vf.objectItemSep,
+			newTextForLiteralType(elType, vf))
 	}
 
 	if attrType.IsListType() || attrType.IsSetType() {
 		elType := attrType.ElementType()
-		return fmt.Sprintf("[ %s ]", newTextForLiteralType(elType))
+		return fmt.Sprintf("[ %s ]", newTextForLiteralType(elType, vf))
 	}
 
 	if attrType.IsObjectType() {
@@ -1037,8 +1167,8 @@ func newTextForLiteralType(attrType cty.Type) string {
 		for _, name := range attrNames {
 			valType := attrType.AttributeType(name)
 
-			objSnippet += fmt.Sprintf("  %s = %s\n", name,
-				newTextForLiteralType(valType))
+			objSnippet += fmt.Sprintf("  %s %s %s\n", name, vf.objectItemSep,
+				newTextForLiteralType(valType, vf))
 		}
 		return fmt.Sprintf("{\n%s}", objSnippet)
 	}
@@ -1046,12 +1176,12 @@ func newTextForLiteralType(attrType cty.Type) string {
 	if attrType.IsTupleType() {
 		elTypes := attrType.TupleElementTypes()
 		if len(elTypes) == 1 {
-			return fmt.Sprintf("[ %s ]", newTextForLiteralType(elTypes[0]))
+			return fmt.Sprintf("[ %s ]", newTextForLiteralType(elTypes[0], vf))
 		}
 
 		tupleSnippet := ""
 		for _, elType := range elTypes {
-			tupleSnippet += newTextForLiteralType(elType)
+			tupleSnippet += newTextForLiteralType(elType, vf)
 		}
 		return fmt.Sprintf("[\n%s]", tupleSnippet)
 	}
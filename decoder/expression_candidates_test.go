@@ -166,8 +166,8 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 						NewText: "{\n  \n}",
 						Snippet: "{\n  ${1}\n}",
 					},
-					Kind:           lang.ObjectCandidateKind,
-					TriggerSuggest: true,
+					Kind:    lang.ObjectCandidateKind,
+					Command: lang.TriggerSuggestCommand(),
 				},
 			}),
 		},
@@ -482,7 +482,7 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 			hcl.Pos{Line: 1, Column: 8, Byte: 7},
 			lang.CompleteCandidates([]lang.Candidate{
 				{
-					Label:  "true",
+					Label:  "false",
 					Detail: "bool",
 					TextEdit: lang.TextEdit{
 						Range: hcl.Range{
@@ -498,13 +498,13 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 								Byte:   7,
 							},
 						},
-						NewText: "true",
-						Snippet: "${1:true}",
+						NewText: "false",
+						Snippet: "${1:false}",
 					},
 					Kind: lang.BoolCandidateKind,
 				},
 				{
-					Label:  "false",
+					Label:  "true",
 					Detail: "bool",
 					TextEdit: lang.TextEdit{
 						Range: hcl.Range{
@@ -520,8 +520,8 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 								Byte:   7,
 							},
 						},
-						NewText: "false",
-						Snippet: "${1:false}",
+						NewText: "true",
+						Snippet: "${1:true}",
 					},
 					Kind: lang.BoolCandidateKind,
 				},
@@ -703,8 +703,8 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 						NewText: "[ ]",
 						Snippet: "[ ${0} ]",
 					},
-					Kind:           lang.TupleCandidateKind,
-					TriggerSuggest: true,
+					Kind:    lang.TupleCandidateKind,
+					Command: lang.TriggerSuggestCommand(),
 				},
 			}),
 		},
@@ -897,7 +897,7 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 			hcl.Pos{Line: 1, Column: 10, Byte: 9},
 			lang.CompleteCandidates([]lang.Candidate{
 				{
-					Label:  "true",
+					Label:  "false",
 					Detail: "bool",
 					TextEdit: lang.TextEdit{
 						Range: hcl.Range{
@@ -913,13 +913,13 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 								Byte:   9,
 							},
 						},
-						NewText: "true",
-						Snippet: "${1:true}",
+						NewText: "false",
+						Snippet: "${1:false}",
 					},
 					Kind: lang.BoolCandidateKind,
 				},
 				{
-					Label:  "false",
+					Label:  "true",
 					Detail: "bool",
 					TextEdit: lang.TextEdit{
 						Range: hcl.Range{
@@ -935,8 +935,8 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 								Byte:   9,
 							},
 						},
-						NewText: "false",
-						Snippet: "${1:false}",
+						NewText: "true",
+						Snippet: "${1:true}",
 					},
 					Kind: lang.BoolCandidateKind,
 				},
@@ -1020,7 +1020,7 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 			hcl.Pos{Line: 1, Column: 10, Byte: 9},
 			lang.CompleteCandidates([]lang.Candidate{
 				{
-					Label:  "true",
+					Label:  "false",
 					Detail: "bool",
 					TextEdit: lang.TextEdit{
 						Range: hcl.Range{
@@ -1036,13 +1036,13 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 								Byte:   9,
 							},
 						},
-						NewText: "true",
-						Snippet: "${1:true}",
+						NewText: "false",
+						Snippet: "${1:false}",
 					},
 					Kind: lang.BoolCandidateKind,
 				},
 				{
-					Label:  "false",
+					Label:  "true",
 					Detail: "bool",
 					TextEdit: lang.TextEdit{
 						Range: hcl.Range{
@@ -1058,8 +1058,8 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 								Byte:   9,
 							},
 						},
-						NewText: "false",
-						Snippet: "${1:false}",
+						NewText: "true",
+						Snippet: "${1:true}",
 					},
 					Kind: lang.BoolCandidateKind,
 				},
@@ -1152,7 +1152,7 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 			hcl.Pos{Line: 1, Column: 10, Byte: 9},
 			lang.CompleteCandidates([]lang.Candidate{
 				{
-					Label:  "true",
+					Label:  "false",
 					Detail: "bool",
 					TextEdit: lang.TextEdit{
 						Range: hcl.Range{
@@ -1168,13 +1168,13 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 								Byte:   9,
 							},
 						},
-						NewText: "true",
-						Snippet: "${1:true}",
+						NewText: "false",
+						Snippet: "${1:false}",
 					},
 					Kind: lang.BoolCandidateKind,
 				},
 				{
-					Label:  "false",
+					Label:  "true",
 					Detail: "bool",
 					TextEdit: lang.TextEdit{
 						Range: hcl.Range{
@@ -1190,8 +1190,8 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 								Byte:   9,
 							},
 						},
-						NewText: "false",
-						Snippet: "${1:false}",
+						NewText: "true",
+						Snippet: "${1:true}",
 					},
 					Kind: lang.BoolCandidateKind,
 				},
@@ -1373,8 +1373,8 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 					Kind: lang.AttributeCandidateKind,
 				},
 				{
-					Label:  "number",
-					Detail: "number",
+					Label:  "list()",
+					Detail: "list()",
 					TextEdit: lang.TextEdit{Range: hcl.Range{
 						Filename: "test.tf",
 						Start: hcl.Pos{
@@ -1387,12 +1387,12 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 							Column: 8,
 							Byte:   7,
 						},
-					}, NewText: "number", Snippet: "number"},
+					}, NewText: "list()", Snippet: "list(${0})"},
 					Kind: lang.AttributeCandidateKind,
 				},
 				{
-					Label:  "string",
-					Detail: "string",
+					Label:  "map()",
+					Detail: "map()",
 					TextEdit: lang.TextEdit{Range: hcl.Range{
 						Filename: "test.tf",
 						Start: hcl.Pos{
@@ -1405,12 +1405,12 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 							Column: 8,
 							Byte:   7,
 						},
-					}, NewText: "string", Snippet: "string"},
+					}, NewText: "map()", Snippet: "map(${0})"},
 					Kind: lang.AttributeCandidateKind,
 				},
 				{
-					Label:  "list()",
-					Detail: "list()",
+					Label:  "number",
+					Detail: "number",
 					TextEdit: lang.TextEdit{Range: hcl.Range{
 						Filename: "test.tf",
 						Start: hcl.Pos{
@@ -1423,12 +1423,12 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 							Column: 8,
 							Byte:   7,
 						},
-					}, NewText: "list()", Snippet: "list(${0})"},
+					}, NewText: "number", Snippet: "number"},
 					Kind: lang.AttributeCandidateKind,
 				},
 				{
-					Label:  "set()",
-					Detail: "set()",
+					Label:  "object({})",
+					Detail: "object({})",
 					TextEdit: lang.TextEdit{Range: hcl.Range{
 						Filename: "test.tf",
 						Start: hcl.Pos{
@@ -1441,12 +1441,12 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 							Column: 8,
 							Byte:   7,
 						},
-					}, NewText: "set()", Snippet: "set(${0})"},
+					}, NewText: "object({})", Snippet: "object({\n ${1:name} = ${2}\n})"},
 					Kind: lang.AttributeCandidateKind,
 				},
 				{
-					Label:  "tuple()",
-					Detail: "tuple()",
+					Label:  "set()",
+					Detail: "set()",
 					TextEdit: lang.TextEdit{Range: hcl.Range{
 						Filename: "test.tf",
 						Start: hcl.Pos{
@@ -1459,12 +1459,12 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 							Column: 8,
 							Byte:   7,
 						},
-					}, NewText: "tuple()", Snippet: "tuple(${0})"},
+					}, NewText: "set()", Snippet: "set(${0})"},
 					Kind: lang.AttributeCandidateKind,
 				},
 				{
-					Label:  "map()",
-					Detail: "map()",
+					Label:  "string",
+					Detail: "string",
 					TextEdit: lang.TextEdit{Range: hcl.Range{
 						Filename: "test.tf",
 						Start: hcl.Pos{
@@ -1477,12 +1477,12 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 							Column: 8,
 							Byte:   7,
 						},
-					}, NewText: "map()", Snippet: "map(${0})"},
+					}, NewText: "string", Snippet: "string"},
 					Kind: lang.AttributeCandidateKind,
 				},
 				{
-					Label:  "object({})",
-					Detail: "object({})",
+					Label:  "tuple()",
+					Detail: "tuple()",
 					TextEdit: lang.TextEdit{Range: hcl.Range{
 						Filename: "test.tf",
 						Start: hcl.Pos{
@@ -1495,7 +1495,7 @@ func TestDecoder_CandidateAtPos_expressions(t *testing.T) {
 							Column: 8,
 							Byte:   7,
 						},
-					}, NewText: "object({})", Snippet: "object({\n ${1:name} = ${2}\n})"},
+					}, NewText: "tuple()", Snippet: "tuple(${0})"},
 					Kind: lang.AttributeCandidateKind,
 				},
 			}),
@@ -1582,6 +1582,41 @@ func TestDecoder_CandidateAtPos_traversalExpressions(t *testing.T) {
 				},
 			},
 			`attr = 
+`,
+			hcl.Pos{Line: 1, Column: 8, Byte: 7},
+			lang.ZeroCandidates(),
+		},
+		{
+			"abstract element references",
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"attr": {
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfType: cty.String},
+						},
+					},
+				},
+			},
+			lang.ReferenceTargets{
+				lang.ReferenceTarget{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "tags"},
+					},
+					Type: cty.Map(cty.String),
+					NestedTargets: lang.ReferenceTargets{
+						{
+							Addr: lang.Address{
+								lang.RootStep{Name: "var"},
+								lang.AttrStep{Name: "tags"},
+								lang.SplatStep{},
+							},
+							Type: cty.String,
+						},
+					},
+				},
+			},
+			`attr = 
 `,
 			hcl.Pos{Line: 1, Column: 8, Byte: 7},
 			lang.ZeroCandidates(),
@@ -1943,7 +1978,8 @@ another_block "meh" {
 						NewText: `custom.test`,
 						Snippet: `custom.test`,
 					},
-					Kind: lang.TraversalCandidateKind,
+					Kind:  lang.TraversalCandidateKind,
+					Score: 0.625,
 				},
 			}),
 		},
@@ -2476,3 +2512,42 @@ another_block "meh" {
 		})
 	}
 }
+
+func TestDecoder_CandidateAtPos_objectDoesNotModifySchema(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"obj": {
+				Expr: schema.ExprConstraints{
+					schema.ObjectExpr{
+						Attributes: schema.ObjectExprAttributes{
+							"first":  {Expr: schema.LiteralTypeOnly(cty.String)},
+							"second": {Expr: schema.LiteralTypeOnly(cty.String)},
+						},
+					},
+				},
+			},
+		},
+	}
+	cfg := `obj = {
+  first = "foo"
+  
+}
+`
+	d := newTestDecoder(t, bodySchema, map[string]string{"test.tf": cfg})
+	pos := hcl.Pos{Line: 3, Column: 3, Byte: 24}
+
+	for i := 0; i < 2; i++ {
+		candidates, err := d.CandidatesAtPos("test.tf", pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if candidates.Len() != 1 || candidates.List[0].Label != "second" {
+			t.Fatalf("unexpected candidates: %#v", candidates)
+		}
+	}
+
+	oe := bodySchema.Attributes["obj"].Expr[0].(schema.ObjectExpr)
+	if len(oe.Attributes) != 2 {
+		t.Fatalf("expected schema to remain unmodified, given attributes: %#v", oe.Attributes)
+	}
+}
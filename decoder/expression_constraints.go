@@ -8,9 +8,43 @@ import (
 
 type ExprConstraints schema.ExprConstraints
 
+// byPrecedence returns constraints in the order in which
+// they are considered when matching an expression
+func (ec ExprConstraints) byPrecedence() ExprConstraints {
+	return ExprConstraints(schema.ExprConstraints(ec).ByPrecedence())
+}
+
+// skipsComplexLiterals returns true if the constraint is a literal type
+// which complex literal values ([ ] and { }) do not match
+// (see schema.LiteralTypeExpr.SkipLiteralComplexTypes)
+func skipsComplexLiterals(c schema.ExprConstraint) bool {
+	lt, ok := c.(schema.LiteralTypeExpr)
+	return ok && lt.SkipLiteralComplexTypes
+}
+
+// isSkippedComplexType returns true if the constraint is a literal
+// complex type which skips complex literal values, such that
+// the value is neither suggested as a candidate, nor as a snippet
+func isSkippedComplexType(c schema.ExprConstraint) bool {
+	lt, ok := c.(schema.LiteralTypeExpr)
+	return ok && lt.SkipLiteralComplexTypes &&
+		!lt.Type.IsPrimitiveType() && lt.Type != cty.DynamicPseudoType
+}
+
+// firstForSnippet returns the constraint of the highest precedence
+// to generate a snippet for, excluding skipped complex types
+func firstForSnippet(ec schema.ExprConstraints) (schema.ExprConstraint, bool) {
+	for _, c := range ec.ByPrecedence() {
+		if !isSkippedComplexType(c) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
 func (ec ExprConstraints) HasKeywordsOnly() bool {
 	hasKeywordExpr := false
-	for _, constraint := range ec {
+	for _, constraint := range ec.byPrecedence() {
 		if _, ok := constraint.(schema.KeywordExpr); ok {
 			hasKeywordExpr = true
 		} else {
@@ -21,7 +55,7 @@ func (ec ExprConstraints) HasKeywordsOnly() bool {
 }
 
 func (ec ExprConstraints) KeywordExpr() (schema.KeywordExpr, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if kw, ok := c.(schema.KeywordExpr); ok {
 			return kw, ok
 		}
@@ -30,7 +64,7 @@ func (ec ExprConstraints) KeywordExpr() (schema.KeywordExpr, bool) {
 }
 
 func (ec ExprConstraints) TraversalExpr() (schema.TraversalExpr, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if te, ok := c.(schema.TraversalExpr); ok {
 			return te, ok
 		}
@@ -38,8 +72,27 @@ func (ec ExprConstraints) TraversalExpr() (schema.TraversalExpr, bool) {
 	return schema.TraversalExpr{}, false
 }
 
+func (ec ExprConstraints) TraversalExprs() []schema.TraversalExpr {
+	tes := make([]schema.TraversalExpr, 0)
+	for _, c := range ec.byPrecedence() {
+		if te, ok := c.(schema.TraversalExpr); ok {
+			tes = append(tes, te)
+		}
+	}
+	return tes
+}
+
+func (ec ExprConstraints) SizeExpr() (schema.SizeExpr, bool) {
+	for _, c := range ec.byPrecedence() {
+		if se, ok := c.(schema.SizeExpr); ok {
+			return se, ok
+		}
+	}
+	return schema.SizeExpr{}, false
+}
+
 func (ec ExprConstraints) MapExpr() (schema.MapExpr, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if me, ok := c.(schema.MapExpr); ok {
 			return me, ok
 		}
@@ -48,7 +101,7 @@ func (ec ExprConstraints) MapExpr() (schema.MapExpr, bool) {
 }
 
 func (ec ExprConstraints) ObjectExpr() (schema.ObjectExpr, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if me, ok := c.(schema.ObjectExpr); ok {
 			return me, ok
 		}
@@ -57,7 +110,7 @@ func (ec ExprConstraints) ObjectExpr() (schema.ObjectExpr, bool) {
 }
 
 func (ec ExprConstraints) TupleConsExpr() (schema.TupleConsExpr, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if tc, ok := c.(schema.TupleConsExpr); ok {
 			return tc, ok
 		}
@@ -66,7 +119,7 @@ func (ec ExprConstraints) TupleConsExpr() (schema.TupleConsExpr, bool) {
 }
 
 func (ec ExprConstraints) SetExpr() (schema.SetExpr, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if se, ok := c.(schema.SetExpr); ok {
 			return se, ok
 		}
@@ -75,7 +128,7 @@ func (ec ExprConstraints) SetExpr() (schema.SetExpr, bool) {
 }
 
 func (ec ExprConstraints) ListExpr() (schema.ListExpr, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if le, ok := c.(schema.ListExpr); ok {
 			return le, ok
 		}
@@ -84,7 +137,7 @@ func (ec ExprConstraints) ListExpr() (schema.ListExpr, bool) {
 }
 
 func (ec ExprConstraints) TupleExpr() (schema.TupleExpr, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if te, ok := c.(schema.TupleExpr); ok {
 			return te, ok
 		}
@@ -93,7 +146,7 @@ func (ec ExprConstraints) TupleExpr() (schema.TupleExpr, bool) {
 }
 
 func (ec ExprConstraints) HasLiteralTypeOf(exprType cty.Type) bool {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if lt, ok := c.(schema.LiteralTypeExpr); ok && lt.Type.Equals(exprType) {
 			return true
 		}
@@ -102,7 +155,7 @@ func (ec ExprConstraints) HasLiteralTypeOf(exprType cty.Type) bool {
 }
 
 func (ec ExprConstraints) LiteralType() (cty.Type, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if lt, ok := c.(schema.LiteralTypeExpr); ok {
 			return lt.Type, true
 		}
@@ -110,8 +163,22 @@ func (ec ExprConstraints) LiteralType() (cty.Type, bool) {
 	return cty.NilType, false
 }
 
+// LiteralTypesOnly returns all literal types of the constraints
+// if the constraints consist of literal types only
+func (ec ExprConstraints) LiteralTypesOnly() ([]cty.Type, bool) {
+	types := make([]cty.Type, 0)
+	for _, c := range ec.byPrecedence() {
+		lt, ok := c.(schema.LiteralTypeExpr)
+		if !ok {
+			return []cty.Type{}, false
+		}
+		types = append(types, lt.Type)
+	}
+	return types, len(types) > 0
+}
+
 func (ec ExprConstraints) HasLiteralValueOf(val cty.Value) bool {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if lv, ok := c.(schema.LiteralValue); ok && lv.Val.RawEquals(val) {
 			return true
 		}
@@ -120,7 +187,7 @@ func (ec ExprConstraints) HasLiteralValueOf(val cty.Value) bool {
 }
 
 func (ec ExprConstraints) LiteralValueOf(val cty.Value) (schema.LiteralValue, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if lv, ok := c.(schema.LiteralValue); ok && lv.Val.RawEquals(val) {
 			return lv, true
 		}
@@ -129,8 +196,8 @@ func (ec ExprConstraints) LiteralValueOf(val cty.Value) (schema.LiteralValue, bo
 }
 
 func (ec ExprConstraints) LiteralTypeOfTupleExpr() (schema.LiteralTypeExpr, bool) {
-	for _, c := range ec {
-		if lv, ok := c.(schema.LiteralTypeExpr); ok {
+	for _, c := range ec.byPrecedence() {
+		if lv, ok := c.(schema.LiteralTypeExpr); ok && !lv.SkipLiteralComplexTypes {
 			if lv.Type.IsListType() {
 				return lv, true
 			}
@@ -146,8 +213,8 @@ func (ec ExprConstraints) LiteralTypeOfTupleExpr() (schema.LiteralTypeExpr, bool
 }
 
 func (ec ExprConstraints) LiteralTypeOfObjectConsExpr() (schema.LiteralTypeExpr, bool) {
-	for _, c := range ec {
-		if lv, ok := c.(schema.LiteralTypeExpr); ok {
+	for _, c := range ec.byPrecedence() {
+		if lv, ok := c.(schema.LiteralTypeExpr); ok && !lv.SkipLiteralComplexTypes {
 			if lv.Type.IsObjectType() {
 				return lv, true
 			}
@@ -169,7 +236,7 @@ func (ec ExprConstraints) LiteralValueOfTupleExpr(expr *hclsyntax.TupleConsExpr)
 		exprValues[i] = val
 	}
 
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if lv, ok := c.(schema.LiteralValue); ok {
 			valType := lv.Val.Type()
 			if valType.IsListType() && lv.Val.RawEquals(cty.ListVal(exprValues)) {
@@ -205,7 +272,7 @@ func (ec ExprConstraints) LiteralValueOfObjectConsExpr(expr *hclsyntax.ObjectCon
 		exprValues[key.AsString()] = val
 	}
 
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if lv, ok := c.(schema.LiteralValue); ok {
 			valType := lv.Val.Type()
 			if valType.IsMapType() && lv.Val.RawEquals(cty.MapVal(exprValues)) {
@@ -221,7 +288,7 @@ func (ec ExprConstraints) LiteralValueOfObjectConsExpr(expr *hclsyntax.ObjectCon
 }
 
 func (ec ExprConstraints) TypeDeclarationExpr() (schema.TypeDeclarationExpr, bool) {
-	for _, c := range ec {
+	for _, c := range ec.byPrecedence() {
 		if td, ok := c.(schema.TypeDeclarationExpr); ok {
 			return td, ok
 		}
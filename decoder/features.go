@@ -0,0 +1,48 @@
+package decoder
+
+// Features represents optional features of the decoder,
+// all of which are disabled by default.
+type Features struct {
+	// FuzzyMatching enables tolerance of typos, such as keyword
+	// candidates matching a prefix within one edit (e.g. "ture")
+	// and validation of misspelled keywords with suggested fixes
+	FuzzyMatching bool
+
+	// UnknownAttributeHints enables diagnostics of lang.DiagHint severity
+	// for attributes not declared in schema of bodies which allow them
+	// (see schema.BodySchema.AllowUnknownAttributes).
+	// Such attributes are not reported at all otherwise.
+	UnknownAttributeHints bool
+
+	// RedundantDefaultHints enables diagnostics of lang.DiagHint severity
+	// for attributes set to their default value
+	// (see schema.AttributeSchema.DefaultValue).
+	RedundantDefaultHints bool
+
+	// ScopeFallbackCompletion enables candidates of reference targets
+	// matching the constraint only by scope, where the constraint
+	// accepts any type (cty.DynamicPseudoType) and no targets
+	// match by type, e.g. because their type is not known.
+	// Constraints without any scope are not affected.
+	ScopeFallbackCompletion bool
+
+	// SchemaPaths enables paths to elements of the schema
+	// (see lang.SchemaPath) in candidates and in diagnostics
+	// along with related information, e.g. for telemetry.
+	SchemaPaths bool
+
+	// LiteralReferenceHints enables diagnostics of lang.DiagHint severity
+	// for string literals equal to the statically known value
+	// of a reference target, which can be referenced instead.
+	LiteralReferenceHints bool
+
+	// CandidateGroups enables grouping of candidates by their kind
+	// into "Templates", "Attributes", "Blocks", "Labels", "References",
+	// "Functions", "Literals" and "Keywords" (see lang.Candidates.Groups).
+	CandidateGroups bool
+}
+
+// SetFeatures sets which optional features are enabled
+func (d *Decoder) SetFeatures(features Features) {
+	d.features = features
+}
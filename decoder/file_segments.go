@@ -0,0 +1,157 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// FileSegment represents a single HCL document within a file
+// which consists of multiple documents, or of a document
+// alongside other (non-HCL) content, such as frontmatter.
+type FileSegment struct {
+	// Offset represents the position within the file
+	// at which the segment starts
+	Offset hcl.Pos
+
+	// Body represents the parsed segment, with ranges relative
+	// to the whole file, e.g. result of hclsyntax.ParseConfig
+	// with Offset passed as the start position
+	Body hcl.Body
+}
+
+// LoadFileSegments loads a file consisting of multiple
+// (non-empty) parsed segments, where src represents
+// the content of the whole file.
+//
+// Position-based methods (such as CandidatesAtPos) use the segment
+// with the highest offset preceding the position and file-wide
+// methods (such as SemanticTokensInFile) combine all segments.
+func (d *Decoder) LoadFileSegments(filename string, src []byte, segments []FileSegment) error {
+	if len(segments) == 0 {
+		return fmt.Errorf("%s: no segments provided", filename)
+	}
+
+	sortedSegments := make([]FileSegment, len(segments))
+	copy(sortedSegments, segments)
+	sort.SliceStable(sortedSegments, func(i, j int) bool {
+		return sortedSegments[i].Offset.Byte < sortedSegments[j].Offset.Byte
+	})
+
+	for i, segment := range sortedSegments {
+		if segment.Body == nil {
+			return fmt.Errorf("%s: segment %d has no body", filename, i)
+		}
+		if segment.Offset.Byte < 0 || segment.Offset.Byte > len(src) {
+			return fmt.Errorf("%s: segment %d offset (%s) out of range",
+				filename, i, stringPos(segment.Offset))
+		}
+	}
+
+	d.filesMu.Lock()
+	defer d.filesMu.Unlock()
+
+	d.files[filename] = &hcl.File{
+		Body:  sortedSegments[0].Body,
+		Bytes: src,
+	}
+	d.segments[filename] = sortedSegments
+	delete(d.redefinedAttrs, filename)
+	d.InvalidateFile(filename)
+
+	return nil
+}
+
+// segmentsForFile returns segments of the given file, or a single
+// segment representing the whole file if it was loaded via LoadFile.
+func (d *Decoder) segmentsForFile(name string) ([]FileSegment, error) {
+	d.filesMu.RLock()
+	defer d.filesMu.RUnlock()
+
+	f, ok := d.fileForName(name)
+	if !ok {
+		return nil, &FileNotFoundError{Filename: name}
+	}
+
+	segments, ok := d.segments[name]
+	if !ok {
+		return []FileSegment{
+			{Offset: hcl.InitialPos, Body: f.Body},
+		}, nil
+	}
+
+	return segments, nil
+}
+
+// bodiesForFile returns bodies of all segments of the given file
+func (d *Decoder) bodiesForFile(name string) ([]*hclsyntax.Body, error) {
+	segments, err := d.segmentsForFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	bodies := make([]*hclsyntax.Body, 0, len(segments))
+	for _, segment := range segments {
+		body, isHcl := segment.Body.(*hclsyntax.Body)
+		if !isHcl {
+			return nil, &UnknownFileFormatError{Filename: name}
+		}
+		bodies = append(bodies, body)
+	}
+
+	return bodies, nil
+}
+
+// segmentIndexAtPos returns index of the segment which contains
+// the given position, i.e. the last segment starting at or before it
+func segmentIndexAtPos(segments []FileSegment, pos hcl.Pos) int {
+	idx := 0
+	for i, segment := range segments {
+		if segment.Offset.Byte > pos.Byte {
+			break
+		}
+		idx = i
+	}
+	return idx
+}
+
+// tokensForFileAndPos returns tokens of the segment
+// which contains the given position
+func (d *Decoder) tokensForFileAndPos(name string, pos hcl.Pos) (hclsyntax.Tokens, error) {
+	tokens, diags, err := d.lexSegmentAtPos(name, pos)
+	if err != nil {
+		return nil, err
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return tokens, nil
+}
+
+// lexSegmentAtPos returns tokens of the segment which contains
+// the given position along with any lexer diagnostics,
+// such that callers can tolerate incomplete tokens
+func (d *Decoder) lexSegmentAtPos(name string, pos hcl.Pos) (hclsyntax.Tokens, hcl.Diagnostics, error) {
+	b, err := d.bytesForFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	segments, err := d.segmentsForFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idx := segmentIndexAtPos(segments, pos)
+	startPos := segments[idx].Offset
+	endByte := len(b)
+	if idx+1 < len(segments) {
+		endByte = segments[idx+1].Offset.Byte
+	}
+
+	tokens, diags := hclsyntax.LexConfig(b[startPos.Byte:endByte], name, startPos)
+
+	return tokens, diags, nil
+}
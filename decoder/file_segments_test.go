@@ -0,0 +1,156 @@
+package decoder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var segmentsSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"name": {
+			Expr: schema.LiteralTypeOnly(cty.String),
+		},
+		"count": {
+			Expr: schema.LiteralTypeOnly(cty.Number),
+		},
+	},
+}
+
+// segmentsCfg represents two documents separated by a line
+// which is not valid HCL, such that neither document would
+// be parsed meaningfully as a single file
+var segmentsCfg = []byte(`name = "first"
+---
+name = "second"
+`)
+
+func TestDecoder_LoadFileSegments_noSegments(t *testing.T) {
+	d := NewDecoder()
+	err := d.LoadFileSegments("test.tf", []byte{}, []FileSegment{})
+	if err == nil {
+		t.Fatal("expected error for no segments")
+	}
+}
+
+func TestDecoder_fileSegments(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(segmentsSchema)
+
+	first, diags := hclsyntax.ParseConfig(segmentsCfg[0:15], "test.tf", hcl.InitialPos)
+	if len(diags) > 0 {
+		t.Fatal(diags)
+	}
+	secondPos := hcl.Pos{Line: 3, Column: 1, Byte: 19}
+	second, diags := hclsyntax.ParseConfig(segmentsCfg[19:], "test.tf", secondPos)
+	if len(diags) > 0 {
+		t.Fatal(diags)
+	}
+
+	// segments are intentionally out of order
+	err := d.LoadFileSegments("test.tf", segmentsCfg, []FileSegment{
+		{Offset: secondPos, Body: second.Body},
+		{Offset: hcl.InitialPos, Body: first.Body},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("hover", func(t *testing.T) {
+		data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 3, Column: 2, Byte: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedData := &lang.HoverData{
+			Content: lang.Markdown("**name** _string_"),
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 3, Column: 1, Byte: 19},
+				End:      hcl.Pos{Line: 3, Column: 16, Byte: 34},
+			},
+		}
+		if diff := cmp.Diff(expectedData, data); diff != "" {
+			t.Fatalf("unexpected hover data: %s", diff)
+		}
+	})
+
+	t.Run("candidates", func(t *testing.T) {
+		candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 4, Column: 1, Byte: 35})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		labels := make([]string, 0)
+		for _, c := range candidates.List {
+			labels = append(labels, c.Label)
+		}
+		expectedLabels := []string{"count"}
+		if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+			t.Fatalf("unexpected candidates: %s", diff)
+		}
+	})
+
+	t.Run("semantic tokens", func(t *testing.T) {
+		tokens, err := d.SemanticTokensInFile("test.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedTokens := []lang.SemanticToken{
+			{
+				Type:      lang.TokenAttrName,
+				Modifiers: []lang.SemanticTokenModifier{},
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+					End:      hcl.Pos{Line: 1, Column: 5, Byte: 4},
+				},
+			},
+			{
+				Type:      lang.TokenString,
+				Modifiers: []lang.SemanticTokenModifier{},
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+					End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+				},
+			},
+			{
+				Type:      lang.TokenAttrName,
+				Modifiers: []lang.SemanticTokenModifier{},
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 3, Column: 1, Byte: 19},
+					End:      hcl.Pos{Line: 3, Column: 5, Byte: 23},
+				},
+			},
+			{
+				Type:      lang.TokenString,
+				Modifiers: []lang.SemanticTokenModifier{},
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 3, Column: 8, Byte: 26},
+					End:      hcl.Pos{Line: 3, Column: 16, Byte: 34},
+				},
+			},
+		}
+		if diff := cmp.Diff(expectedTokens, tokens); diff != "" {
+			t.Fatalf("unexpected tokens: %s", diff)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		diags, err := d.ValidateFile("test.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diags) > 0 {
+			t.Fatalf("unexpected diagnostics: %s", diags)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// FileSystem provides read access to files which were not loaded
+// via LoadFile (e.g. files not yet opened in the client), such that
+// features referring to other files can read their content on demand.
+//
+// The interface matches the ReadFile method of io/fs.ReadFileFS,
+// so any such file system can be used as-is.
+type FileSystem interface {
+	ReadFile(name string) ([]byte, error)
+}
+
+// SetFileSystem sets the file system used to read files
+// which were not loaded via LoadFile or LoadFileSegments.
+//
+// Files read from the file system are parsed (as HCL JSON if
+// they have the .json extension) and cached until
+// invalidated via InvalidateFile, or until the file is loaded.
+// Loaded files always take precedence and only loaded files
+// are returned from Filenames.
+func (d *Decoder) SetFileSystem(fsys FileSystem) {
+	d.fsMu.Lock()
+	defer d.fsMu.Unlock()
+
+	d.fsys = fsys
+	d.fsFiles = make(map[string]*hcl.File, 0)
+}
+
+// InvalidateFile discards cached content of the given file
+// read from the file system, e.g. after it changed on disk.
+func (d *Decoder) InvalidateFile(filename string) {
+	d.fsMu.Lock()
+	defer d.fsMu.Unlock()
+
+	delete(d.fsFiles, filename)
+}
+
+// fileForName returns the loaded file of the given name,
+// or the file read from the file system (if any).
+//
+// The caller is expected to hold the read lock of files.
+func (d *Decoder) fileForName(name string) (*hcl.File, bool) {
+	f, ok := d.files[name]
+	if ok {
+		return f, true
+	}
+
+	d.fsMu.Lock()
+	defer d.fsMu.Unlock()
+
+	if d.fsys == nil {
+		return nil, false
+	}
+
+	f, ok = d.fsFiles[name]
+	if ok {
+		return f, true
+	}
+
+	src, err := d.fsys.ReadFile(name)
+	if err != nil {
+		// not cached, as the file may yet be created
+		return nil, false
+	}
+	// files with syntax errors are still useful for most features
+	f, _ = parseSource(name, src)
+	d.fsFiles[name] = f
+
+	return f, true
+}
@@ -0,0 +1,96 @@
+package decoder
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+type mapFileSystem map[string]string
+
+func (fsys mapFileSystem) ReadFile(name string) ([]byte, error) {
+	src, ok := fsys[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(src), nil
+}
+
+func symbolNamesInFile(t *testing.T, d *Decoder, filename string) []string {
+	symbols, err := d.SymbolsInFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		names[i] = symbol.Name()
+	}
+	return names
+}
+
+func TestDecoder_SetFileSystem(t *testing.T) {
+	fsys := mapFileSystem{
+		"other.tf": "foo = 1\n",
+	}
+
+	d := NewDecoder()
+	d.SetFileSystem(fsys)
+
+	if diff := cmp.Diff([]string{"foo"}, symbolNamesInFile(t, d, "other.tf")); diff != "" {
+		t.Fatalf("unexpected symbols: %s", diff)
+	}
+
+	if len(d.Filenames()) != 0 {
+		t.Fatalf("expected no loaded files, given: %q", d.Filenames())
+	}
+
+	// cached until invalidated
+	fsys["other.tf"] = "bar = 1\n"
+	if diff := cmp.Diff([]string{"foo"}, symbolNamesInFile(t, d, "other.tf")); diff != "" {
+		t.Fatalf("unexpected symbols: %s", diff)
+	}
+
+	d.InvalidateFile("other.tf")
+	if diff := cmp.Diff([]string{"bar"}, symbolNamesInFile(t, d, "other.tf")); diff != "" {
+		t.Fatalf("unexpected symbols after invalidation: %s", diff)
+	}
+
+	// loaded files take precedence
+	f, _ := hclsyntax.ParseConfig([]byte("baz = 1\n"), "other.tf", hcl.InitialPos)
+	err := d.LoadFile("other.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"baz"}, symbolNamesInFile(t, d, "other.tf")); diff != "" {
+		t.Fatalf("unexpected symbols of loaded file: %s", diff)
+	}
+
+	_, err = d.SymbolsInFile("unknown.tf")
+	var notFoundErr *FileNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Fatalf("expected FileNotFoundError, given: %v", err)
+	}
+}
+
+func TestDecoder_SetFileSystem_json(t *testing.T) {
+	d := NewDecoder()
+	d.SetFileSystem(mapFileSystem{
+		"other.tf.json": `{"foo": 1}`,
+	})
+
+	f, err := d.File("other.tf.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs, diags := f.Body.JustAttributes()
+	if len(diags) > 0 {
+		t.Fatal(diags)
+	}
+	if _, ok := attrs["foo"]; !ok {
+		t.Fatalf("expected attribute foo, given: %#v", attrs)
+	}
+}
@@ -0,0 +1,329 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/position"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// groupingKeyword represents the grouping operator (...) of object
+// for expressions, which is offered in completion where values
+// are expected to be lists
+var groupingKeyword = schema.KeywordExpr{
+	Keyword: "...",
+	Name:    "grouping",
+	Description: lang.Markdown("Groups values with the same key into a list, " +
+		"instead of requiring keys to be unique"),
+}
+
+// forExprKeyConstraints represents constraints of keys
+// produced by object for expressions
+var forExprKeyConstraints = ExprConstraints(schema.LiteralTypeOnly(cty.String))
+
+// forExprMatchesConstraint returns true if the for expression produces
+// a collection of the kind expected by the constraint, i.e. a map
+// for object for expressions and a list, set or tuple otherwise
+func forExprMatchesConstraint(constraint schema.ExprConstraint, e *hclsyntax.ForExpr) bool {
+	if e.KeyExpr != nil {
+		switch c := constraint.(type) {
+		case schema.MapExpr:
+			return true
+		case schema.LiteralTypeExpr:
+			return c.Type == cty.DynamicPseudoType || c.Type.IsMapType() || c.Type.IsObjectType()
+		}
+		return false
+	}
+
+	switch c := constraint.(type) {
+	case schema.TupleConsExpr, schema.SetExpr, schema.ListExpr:
+		return true
+	case schema.LiteralTypeExpr:
+		return c.Type == cty.DynamicPseudoType || c.Type.IsListType() ||
+			c.Type.IsSetType() || c.Type.IsTupleType()
+	}
+	return false
+}
+
+// forExprValueConstraints returns constraints of each value produced
+// by the for expression (i.e. of its value expression) where
+// the expression matches the constraint.
+//
+// Where values of an object for expression are grouped (...),
+// constraints of elements of the grouped lists are returned.
+func forExprValueConstraints(constraint schema.ExprConstraint, e *hclsyntax.ForExpr) (ExprConstraints, bool) {
+	if e.KeyExpr == nil {
+		return collectionElemConstraints(constraint)
+	}
+
+	valConstraints, ok := mapValueConstraints(constraint)
+	if !ok {
+		return ExprConstraints{}, false
+	}
+	if !e.Group {
+		return valConstraints, true
+	}
+	return groupedValueConstraints(valConstraints)
+}
+
+// mapValueConstraints returns constraints of any value
+// of a map matching the constraint
+func mapValueConstraints(constraint schema.ExprConstraint) (ExprConstraints, bool) {
+	switch c := constraint.(type) {
+	case schema.MapExpr:
+		return ExprConstraints(c.Elem), true
+	case schema.LiteralTypeExpr:
+		switch {
+		case c.Type == cty.DynamicPseudoType:
+			return ExprConstraints(schema.LiteralTypeOnly(cty.DynamicPseudoType)), true
+		case c.Type.IsMapType():
+			return ExprConstraints(schema.LiteralTypeOnly(c.Type.ElementType())), true
+		}
+		// attributes of objects cannot be known
+		// without evaluating the keys
+	}
+	return ExprConstraints{}, false
+}
+
+// groupedValueConstraints returns constraints of elements of any
+// list-like constraints, i.e. what each grouped value is expected to be
+func groupedValueConstraints(ec ExprConstraints) (ExprConstraints, bool) {
+	elemConstraints := make(ExprConstraints, 0)
+	for _, c := range ec {
+		if lt, ok := c.(schema.LiteralTypeExpr); ok && lt.Type == cty.DynamicPseudoType {
+			elemConstraints = append(elemConstraints, lt)
+			continue
+		}
+		if elem, ok := collectionElemConstraints(c); ok {
+			elemConstraints = append(elemConstraints, elem...)
+		}
+	}
+	return elemConstraints, len(elemConstraints) > 0
+}
+
+// collectionElemConstraints returns constraints of any element
+// of a list, set or tuple (of any length) matching the constraint
+func collectionElemConstraints(constraint schema.ExprConstraint) (ExprConstraints, bool) {
+	switch c := constraint.(type) {
+	case schema.TupleConsExpr:
+		return ExprConstraints(c.AnyElem), true
+	case schema.SetExpr:
+		return ExprConstraints(c.Elem), true
+	case schema.ListExpr:
+		return ExprConstraints(c.Elem), true
+	case schema.LiteralTypeExpr:
+		switch {
+		case c.Type == cty.DynamicPseudoType:
+			return ExprConstraints(schema.LiteralTypeOnly(cty.DynamicPseudoType)), true
+		case c.Type.IsListType() || c.Type.IsSetType():
+			return ExprConstraints(schema.LiteralTypeOnly(c.Type.ElementType())), true
+		}
+	}
+	return ExprConstraints{}, false
+}
+
+// isGroupingExpected returns true if values of the object for expression
+// are not grouped yet, but can be, i.e. lists of values are expected
+func isGroupingExpected(constraint schema.ExprConstraint, e *hclsyntax.ForExpr) bool {
+	if e.KeyExpr == nil || e.Group {
+		return false
+	}
+	valConstraints, ok := mapValueConstraints(constraint)
+	if !ok {
+		return false
+	}
+	for _, c := range valConstraints {
+		if _, ok := collectionElemConstraints(c); !ok {
+			return false
+		}
+		if lt, ok := c.(schema.LiteralTypeExpr); ok && lt.Type == cty.DynamicPseudoType {
+			return false
+		}
+	}
+	return true
+}
+
+// isForExprSymbol returns true if the expression refers
+// to the key or value symbol declared by the for expression
+func isForExprSymbol(expr hclsyntax.Expression, e *hclsyntax.ForExpr) bool {
+	ste, ok := expr.(*hclsyntax.ScopeTraversalExpr)
+	if !ok {
+		return false
+	}
+	rootName := ste.Traversal.RootName()
+	return rootName == e.ValVar || (e.KeyVar != "" && rootName == e.KeyVar)
+}
+
+// containsPosOrEnd is like hcl.Range.ContainsPos,
+// but also contains the position at the end of the range
+func containsPosOrEnd(rng hcl.Range, pos hcl.Pos) bool {
+	return rng.ContainsPos(pos) || rng.End.Byte == pos.Byte
+}
+
+// forExprConstraintsAtPos returns constraints of the key, value or condition
+// expression of the for expression at the given position, or the grouping
+// operator where it is expected after the value expression
+func (d *Decoder) forExprConstraintsAtPos(e *hclsyntax.ForExpr, constraint schema.ExprConstraint, nestingLvl int, pos hcl.Pos) (ExprConstraints, hcl.Range) {
+	if e.KeyExpr != nil && containsPosOrEnd(e.KeyExpr.Range(), pos) {
+		return d.constraintsAtPos(e.KeyExpr, forExprKeyConstraints, nestingLvl+1, pos)
+	}
+	if containsPosOrEnd(e.ValExpr.Range(), pos) {
+		valConstraints, ok := forExprValueConstraints(constraint, e)
+		if !ok {
+			return ExprConstraints{}, e.ValExpr.Range()
+		}
+		return d.constraintsAtPos(e.ValExpr, valConstraints, nestingLvl+1, pos)
+	}
+	if e.CondExpr != nil && containsPosOrEnd(e.CondExpr.Range(), pos) {
+		return d.constraintsAtPos(e.CondExpr, ExprConstraints(schema.LiteralTypeOnly(cty.Bool)), nestingLvl+1, pos)
+	}
+
+	afterValue := pos.Byte > e.ValExpr.Range().End.Byte
+	beforeCond := pos.Byte <= e.CloseRange.Start.Byte
+	if e.CondExpr != nil {
+		beforeCond = pos.Byte < e.CondExpr.Range().Start.Byte
+	}
+	if afterValue && beforeCond && isGroupingExpected(constraint, e) {
+		return ExprConstraints{groupingKeyword}, hcl.Range{
+			Filename: e.Range().Filename,
+			Start:    pos,
+			End:      pos,
+		}
+	}
+
+	return ExprConstraints{}, e.Range()
+}
+
+// hoverDataForForExpr returns hover data for the key or value
+// expression of the for expression at the given position,
+// or for the whole for expression
+func (d *Decoder) hoverDataForForExpr(e *hclsyntax.ForExpr, constraint schema.ExprConstraint, nestingLvl int, pos hcl.Pos) (*lang.HoverData, error) {
+	if !d.isNestedTooDeep(nestingLvl + 1) {
+		if e.KeyExpr != nil && e.KeyExpr.Range().ContainsPos(pos) && !isForExprSymbol(e.KeyExpr, e) {
+			return d.hoverDataForExpr(e.KeyExpr, forExprKeyConstraints, nestingLvl+1, pos)
+		}
+		if e.ValExpr.Range().ContainsPos(pos) && !isForExprSymbol(e.ValExpr, e) {
+			if valConstraints, ok := forExprValueConstraints(constraint, e); ok {
+				return d.hoverDataForExpr(e.ValExpr, valConstraints, nestingLvl+1, pos)
+			}
+		}
+	}
+
+	var content string
+	if lt, ok := constraint.(schema.LiteralTypeExpr); ok {
+		var err error
+		content, err = hoverContentForType(lt.Type, nestingLvl)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		content = constraint.FriendlyName()
+		if nestingLvl == 0 {
+			content = fmt.Sprintf("_%s_", constraint.FriendlyName())
+		}
+	}
+
+	if e.Group {
+		content += "\n\nValues with the same key are grouped into lists (`...`)"
+	}
+
+	return &lang.HoverData{
+		Content: lang.Markdown(content),
+		Range:   e.Range(),
+	}, nil
+}
+
+// validateForExprGrouping reports grouping (...) of values
+// of an object for expression where the values are not
+// expected to be lists, along with a fix removing it
+func (d *Decoder) validateForExprGrouping(name string, expr hcl.Expression, ec ExprConstraints, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	e, ok := expr.(*hclsyntax.ForExpr)
+	if !ok || !e.Group || e.KeyExpr == nil {
+		return diags
+	}
+	c, ok := ec.constraintForExpr(e)
+	if !ok {
+		return diags
+	}
+	if _, ok := mapValueConstraints(c); !ok {
+		// values are not known to be anything,
+		// so neither are the grouped ones
+		return diags
+	}
+	if _, ok := forExprValueConstraints(c, e); ok {
+		return diags
+	}
+
+	subject := e.SrcRange
+	rng, rngOk := d.groupingOperatorRange(e)
+	if rngOk {
+		subject = rng
+	}
+
+	diag := d.newDiagnostic(CodeUnexpectedGrouping, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unexpected grouping",
+		Detail: fmt.Sprintf("Value of %q must be %s, grouping (...) produces lists of values",
+			name, c.FriendlyName()),
+		Subject: subject.Ptr(),
+	})
+	diags = append(diags, diag)
+
+	if fixes != nil && rngOk {
+		*fixes = append(*fixes, lang.DiagnosticFix{
+			Title:      "Remove grouping",
+			Diagnostic: diag,
+			Edits: []lang.TextEdit{
+				{Range: rng},
+			},
+		})
+	}
+
+	return diags
+}
+
+// groupingOperatorRange returns range of the grouping operator (...)
+// which is located between the value expression and either
+// the condition or the end of the for expression
+func (d *Decoder) groupingOperatorRange(e *hclsyntax.ForExpr) (hcl.Range, bool) {
+	src, err := d.bytesForFile(e.SrcRange.Filename)
+	if err != nil {
+		return hcl.Range{}, false
+	}
+
+	start := e.ValExpr.Range().End.Byte
+	end := e.CloseRange.Start.Byte
+	if e.CondExpr != nil {
+		end = e.CondExpr.Range().Start.Byte
+	}
+	if start < 0 || end > len(src) || start > end {
+		return hcl.Range{}, false
+	}
+
+	idx := bytes.Index(src[start:end], []byte("..."))
+	if idx < 0 {
+		return hcl.Range{}, false
+	}
+
+	startPos, err := position.ByteOffsetToPos(src, start+idx)
+	if err != nil {
+		return hcl.Range{}, false
+	}
+	endPos, err := position.ByteOffsetToPos(src, start+idx+3)
+	if err != nil {
+		return hcl.Range{}, false
+	}
+
+	return hcl.Range{
+		Filename: e.SrcRange.Filename,
+		Start:    startPos,
+		End:      endPos,
+	}, true
+}
@@ -0,0 +1,235 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var roleKeywords = schema.ExprConstraints{
+	schema.KeywordExpr{Keyword: "admin"},
+	schema.KeywordExpr{Keyword: "reader"},
+}
+
+var forExprSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"roles": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.MapExpr{Elem: roleKeywords},
+			},
+		},
+		"members": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.MapExpr{
+					Elem: schema.ExprConstraints{
+						schema.ListExpr{Elem: roleKeywords},
+					},
+				},
+			},
+		},
+		"names": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.List(cty.String)),
+		},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_forExpr(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cfg                string
+		posAfter           string
+		expectedCandidates string
+	}{
+		{
+			"value",
+			`roles = {for k, v in var.users : k => a }
+`,
+			"k => a",
+			`
+complete: true
+candidates: 1
+---
+label: "admin"
+kind: KeywordCandidateKind
+detail: "keyword"
+edit: test.tf:1,39-1,40 "admin"
+`,
+		},
+		{
+			"grouping expected",
+			`members = {for k, v in var.users : k => admin }
+`,
+			"k => admin ",
+			`
+complete: true
+candidates: 1
+---
+label: "..."
+kind: KeywordCandidateKind
+detail: "grouping"
+description (MarkdownKind): "Groups values with the same key into a list, instead of requiring keys to be unique"
+edit: test.tf:1,47-1,47 "..."
+`,
+		},
+		{
+			"grouping not expected",
+			`roles = {for k, v in var.users : k => admin }
+`,
+			"k => admin ",
+			`
+complete: true
+candidates: 0
+`,
+		},
+		{
+			"grouped value",
+			`members = {for k, v in var.users : k => a... }
+`,
+			"k => a",
+			`
+complete: true
+candidates: 1
+---
+label: "admin"
+kind: KeywordCandidateKind
+detail: "keyword"
+edit: test.tf:1,41-1,42 "admin"
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, forExprSchema, map[string]string{"test.tf": tc.cfg})
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
+
+func TestDecoder_HoverAtPos_forExpr(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		posAfter        string
+		expectedContent lang.MarkupContent
+	}{
+		{
+			"whole expression",
+			`roles = {for k, v in var.users : k => admin}
+`,
+			"roles = {f",
+			lang.Markdown("_map_"),
+		},
+		{
+			"whole grouped expression",
+			`members = {for k, v in var.users : k => admin...}
+`,
+			"members = {f",
+			lang.Markdown("_map_\n\nValues with the same key are grouped into lists (`...`)"),
+		},
+		{
+			"grouped value",
+			`members = {for k, v in var.users : k => admin...}
+`,
+			"k => ad",
+			lang.Markdown("keyword"),
+		},
+		{
+			"literal type",
+			`names = [for v in var.users : v.name]
+`,
+			"names = [f",
+			lang.Markdown("_list of string_"),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, forExprSchema, map[string]string{"test.tf": tc.cfg})
+
+			data, err := d.HoverAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedContent, data.Content); diff != "" {
+				t.Fatalf("unexpected hover content: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFileWithFixes_forExprGrouping(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+		expectedCfg   string
+	}{
+		{
+			"expected grouping",
+			`members = {for k, v in var.users : k => admin...}
+`,
+			hcl.Diagnostics{},
+			"",
+		},
+		{
+			"unexpected grouping",
+			`roles = {for k, v in var.users : k => admin ... if v.enabled}
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected grouping",
+					Detail:   `Value of "roles" must be map, grouping (...) produces lists of values`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 45, Byte: 44},
+						End:      hcl.Pos{Line: 1, Column: 48, Byte: 47},
+					},
+				},
+			},
+			`roles = {for k, v in var.users : k => admin  if v.enabled}
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, forExprSchema, map[string]string{"test.tf": tc.cfg})
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+			if len(diags) == 0 {
+				return
+			}
+
+			diagFixes := fixes.ForDiagnostic(diags[0])
+			if len(diagFixes) != 1 {
+				t.Fatalf("expected 1 fix, given: %#v", diagFixes)
+			}
+			fixedCfg := applyTextEdits(tc.cfg, diagFixes[0].Edits)
+			if fixedCfg != tc.expectedCfg {
+				t.Fatalf("unexpected config after fix:\n%s", fixedCfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// FunctionCall represents a call of a function within an expression,
+// as needed e.g. for signature help, hover or validation of arguments
+type FunctionCall struct {
+	Name      string
+	NameRange hcl.Range
+
+	// Range represents the whole call, including the parentheses
+	Range hcl.Range
+
+	// Signature represents signature of the called function,
+	// or nil if the function is not known
+	Signature *schema.FunctionSignature
+
+	// ArgRanges represents ranges of the (parsed) arguments
+	ArgRanges []hcl.Range
+
+	// ExpandFinal indicates that the final argument
+	// is expanded into multiple arguments (...)
+	ExpandFinal bool
+
+	// ArgIndex represents index of the argument at the position,
+	// which may not be parsed yet (e.g. right after a comma),
+	// or -1 if the position is not within the parentheses
+	ArgIndex int
+}
+
+// Param returns the parameter which the argument at ArgIndex
+// corresponds to, which is the variadic parameter for any arguments
+// beyond the fixed ones
+func (fc *FunctionCall) Param() (function.Parameter, bool) {
+	if fc.Signature == nil || fc.ArgIndex < 0 {
+		return function.Parameter{}, false
+	}
+	if fc.ArgIndex < len(fc.Signature.Params) {
+		return fc.Signature.Params[fc.ArgIndex], true
+	}
+	if fc.Signature.VarParam != nil {
+		return *fc.Signature.VarParam, true
+	}
+	return function.Parameter{}, false
+}
+
+// FunctionCallAtPos returns the innermost function call
+// enclosing the position in a file, if one exists, else nil
+func (d *Decoder) FunctionCallAtPos(filename string, pos hcl.Pos, opts ...RequestOption) (*FunctionCall, error) {
+	d = d.withRequestOptions(opts)
+
+	pos = d.posForFile(filename, pos)
+
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := d.bytesForFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.functionCallAtPos(rootBody, src, pos), nil
+}
+
+func (d *Decoder) functionCallAtPos(body *hclsyntax.Body, src []byte, pos hcl.Pos) *FunctionCall {
+	for _, attr := range body.Attributes {
+		if !d.isPosInsideAttrExpr(attr, pos) {
+			continue
+		}
+
+		var found *hclsyntax.FunctionCallExpr
+		hclsyntax.VisitAll(attr.Expr, func(node hclsyntax.Node) hcl.Diagnostics {
+			fce, ok := node.(*hclsyntax.FunctionCallExpr)
+			if ok && fce.Range().ContainsPos(pos) {
+				// nodes are visited outside-in, so the last match is innermost
+				found = fce
+			}
+			return nil
+		})
+		if found == nil {
+			return nil
+		}
+
+		call := d.functionCallForExpr(found)
+		call.ArgIndex = argIndexAtPos(found, src, pos)
+		return call
+	}
+
+	for _, block := range body.Blocks {
+		if block.Body != nil && block.Body.Range().ContainsPos(pos) {
+			return d.functionCallAtPos(block.Body, src, pos)
+		}
+	}
+
+	return nil
+}
+
+func (d *Decoder) functionCallForExpr(fce *hclsyntax.FunctionCallExpr) *FunctionCall {
+	call := &FunctionCall{
+		Name:        fce.Name,
+		NameRange:   fce.NameRange,
+		Range:       fce.Range(),
+		ArgRanges:   make([]hcl.Range, len(fce.Args)),
+		ExpandFinal: fce.ExpandFinal,
+		ArgIndex:    -1,
+	}
+	for i, arg := range fce.Args {
+		call.ArgRanges[i] = arg.Range()
+	}
+	if sig, ok := d.functionSignature(fce.Name); ok {
+		call.Signature = &sig
+	}
+	return call
+}
+
+// argIndexAtPos returns index of the argument at the position,
+// accounting for commas between arguments (and after the last one)
+// which aren't part of any argument range
+func argIndexAtPos(fce *hclsyntax.FunctionCallExpr, src []byte, pos hcl.Pos) int {
+	if pos.Byte < fce.OpenParenRange.End.Byte || pos.Byte > fce.CloseParenRange.Start.Byte {
+		return -1
+	}
+
+	for i, arg := range fce.Args {
+		argRng := arg.Range()
+		if pos.Byte <= argRng.End.Byte {
+			return i
+		}
+
+		nextStart := fce.CloseParenRange.Start.Byte
+		if i+1 < len(fce.Args) {
+			nextStart = fce.Args[i+1].Range().Start.Byte
+		}
+		if pos.Byte < nextStart {
+			nextStart = pos.Byte
+		}
+		if nextStart > len(src) || !bytes.ContainsRune(src[argRng.End.Byte:nextStart], ',') {
+			return i
+		}
+	}
+
+	return len(fce.Args)
+}
+
+// validateFunctionCalls reports calls of known functions
+// with a wrong number of arguments, or with literal arguments
+// which cannot be converted to the type of the parameter,
+// as well as calls of deprecated functions, along with a fix
+// to call the replacement function (if any).
+func (d *Decoder) validateFunctionCalls(expr hclsyntax.Expression, ec ExprConstraints, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if _, ok := ec.TypeDeclarationExpr(); ok {
+		// type declarations look like calls, but aren't
+		return diags
+	}
+
+	hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		fce, ok := node.(*hclsyntax.FunctionCallExpr)
+		if !ok {
+			return nil
+		}
+		call := d.functionCallForExpr(fce)
+		if call.Signature == nil {
+			return nil
+		}
+
+		diags = append(diags, d.deprecatedFunctionDiagnostics(call, fixes)...)
+		diags = append(diags, d.validateFunctionArgTypes(call, fce.Args)...)
+
+		if call.ExpandFinal {
+			// the number of expanded arguments is not known
+			return nil
+		}
+
+		params := call.Signature.Params
+		switch {
+		case len(call.ArgRanges) < len(params):
+			missing := params[len(call.ArgRanges)]
+			diags = append(diags, d.newDiagnostic(CodeNotEnoughArguments, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Not enough function arguments",
+				Detail: fmt.Sprintf("Function %q expects %d argument(s). Missing value for %q.",
+					call.Name, len(params), missing.Name),
+				Subject: fce.CloseParenRange.Ptr(),
+			}))
+		case len(call.ArgRanges) > len(params) && call.Signature.VarParam == nil:
+			diags = append(diags, d.newDiagnostic(CodeTooManyArguments, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Too many function arguments",
+				Detail: fmt.Sprintf("Function %q expects only %d argument(s).",
+					call.Name, len(params)),
+				Subject: call.ArgRanges[len(params)].Ptr(),
+			}))
+		}
+		return nil
+	})
+
+	return diags
+}
+
+// validateFunctionArgTypes reports literal arguments of the call
+// which cannot be converted to the type of the corresponding parameter
+func (d *Decoder) validateFunctionArgTypes(call *FunctionCall, args []hclsyntax.Expression) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for i, arg := range args {
+		if call.ExpandFinal && i == len(args)-1 {
+			// the expanded argument is not a value of any single parameter
+			break
+		}
+
+		var param function.Parameter
+		switch {
+		case i < len(call.Signature.Params):
+			param = call.Signature.Params[i]
+		case call.Signature.VarParam != nil:
+			param = *call.Signature.VarParam
+		default:
+			// extraneous arguments are reported separately
+			return diags
+		}
+
+		if len(arg.Variables()) > 0 {
+			continue
+		}
+		val, vDiags := arg.Value(nil)
+		if vDiags.HasErrors() || !val.IsWhollyKnown() {
+			// value not known without further context (e.g. functions)
+			continue
+		}
+
+		if val.IsNull() {
+			if !param.AllowNull {
+				diags = append(diags, d.newDiagnostic(CodeInvalidFunctionArgument, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid function argument",
+					Detail: fmt.Sprintf("Invalid value for %q parameter: argument must not be null.",
+						param.Name),
+					Subject: call.ArgRanges[i].Ptr(),
+				}))
+			}
+			continue
+		}
+
+		if _, err := convert.Convert(val, param.Type); err != nil {
+			diags = append(diags, d.newDiagnostic(CodeInvalidFunctionArgument, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid function argument",
+				Detail: fmt.Sprintf("Invalid value for %q parameter: %s.",
+					param.Name, err),
+				Subject: call.ArgRanges[i].Ptr(),
+			}))
+		}
+	}
+
+	return diags
+}
+
+// deprecatedFunctionDiagnostics reports a call of a deprecated function
+func (d *Decoder) deprecatedFunctionDiagnostics(call *FunctionCall, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	sig := call.Signature
+	if !sig.IsDeprecated() {
+		return hcl.Diagnostics{}
+	}
+
+	diag := d.newDiagnostic(CodeDeprecatedFunction, &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  "Deprecated function",
+		Detail:   deprecatedFunctionDetail(call.Name, *sig),
+		Subject:  call.NameRange.Ptr(),
+	})
+
+	if fixes != nil && sig.ReplacedBy != "" {
+		*fixes = append(*fixes, lang.DiagnosticFix{
+			Title:      fmt.Sprintf("Replace with %q", sig.ReplacedBy),
+			Diagnostic: diag,
+			Edits: []lang.TextEdit{
+				{
+					Range:   call.NameRange,
+					NewText: sig.ReplacedBy,
+					Snippet: sig.ReplacedBy,
+				},
+			},
+		})
+	}
+
+	return hcl.Diagnostics{diag}
+}
+
+func deprecatedFunctionDetail(name string, sig schema.FunctionSignature) string {
+	detail := fmt.Sprintf("Function %q is deprecated", name)
+	if sig.DeprecatedText != "" {
+		detail += ": " + strings.TrimSuffix(sig.DeprecatedText, ".")
+	}
+	if sig.ReplacedBy != "" {
+		detail += fmt.Sprintf(". Use %q instead", sig.ReplacedBy)
+	}
+	return detail + "."
+}
+
+// hoverContentForFunction returns hover content describing
+// signature of the function, including any deprecation
+func hoverContentForFunction(name string, sig schema.FunctionSignature) string {
+	value := fmt.Sprintf("`%s`", functionSignatureText(name, sig))
+
+	if sig.Description.Value != "" {
+		value += "\n\n" + sig.Description.Value
+	}
+	if sig.IsDeprecated() {
+		value += "\n\n**Deprecated:** " + deprecatedFunctionDetail(name, sig)
+	}
+
+	return value
+}
+
+// functionSignatureText returns the signature of the function
+// in the form of name(param type, ...) return type
+func functionSignatureText(name string, sig schema.FunctionSignature) string {
+	text := name + functionParamsText(sig)
+	if returnType := functionReturnTypeText(sig); returnType != "" {
+		text += " " + returnType
+	}
+	return text
+}
+
+// functionParamsText returns parameters of the function
+// in the form of (param type, ...)
+func functionParamsText(sig schema.FunctionSignature) string {
+	params := make([]string, 0, len(sig.Params)+1)
+	for _, param := range sig.Params {
+		params = append(params, fmt.Sprintf("%s %s", param.Name, param.Type.FriendlyNameForConstraint()))
+	}
+	if sig.VarParam != nil {
+		params = append(params, fmt.Sprintf("...%s %s", sig.VarParam.Name, sig.VarParam.Type.FriendlyNameForConstraint()))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(params, ", "))
+}
+
+// functionReturnTypeText returns the type returned by the function,
+// or an empty string if it is not known
+func functionReturnTypeText(sig schema.FunctionSignature) string {
+	if sig.ReturnType == cty.NilType {
+		return ""
+	}
+	return sig.ReturnType.FriendlyNameForConstraint()
+}
+
+// functionCandidates returns candidates for calls of known functions
+// whose names start with the prefix and which return a value
+// conforming to the given type.
+//
+// Deprecated functions are tagged as such and sorted last.
+func (d *Decoder) functionCandidates(prefix string, ofType cty.Type, editRng hcl.Range) []lang.Candidate {
+	candidates := make([]lang.Candidate, 0)
+
+	for _, name := range d.functionNames() {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		sig, _ := d.functionSignature(name)
+		if !returnTypeConforms(sig.ReturnType, ofType) {
+			continue
+		}
+
+		candidates = append(candidates, lang.Candidate{
+			Label:        name,
+			Detail:       functionSignatureText(name, sig),
+			Description:  sig.Description,
+			IsDeprecated: sig.IsDeprecated(),
+			Kind:         lang.FunctionCandidateKind,
+			LabelDetails: &lang.LabelDetails{
+				Detail:      functionParamsText(sig),
+				Description: functionReturnTypeText(sig),
+			},
+			TextEdit: lang.TextEdit{
+				NewText: fmt.Sprintf("%s()", name),
+				Snippet: fmt.Sprintf("%s(${0})", name),
+				Range:   editRng,
+			},
+			Score: functionScore(sig),
+		})
+	}
+
+	return candidates
+}
+
+// returnTypeConforms returns true if values of the return type
+// may conform to the given type, which is always the case for
+// functions whose return type depends on arguments
+func returnTypeConforms(returnType, typ cty.Type) bool {
+	if returnType == cty.NilType || returnType == cty.DynamicPseudoType ||
+		typ == cty.DynamicPseudoType {
+		return true
+	}
+	return len(returnType.TestConformance(typ)) == 0
+}
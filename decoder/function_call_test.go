@@ -0,0 +1,549 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+var functionCallFunctions = map[string]schema.FunctionSignature{
+	"upper": {
+		ReturnType: cty.String,
+		Params: []function.Parameter{
+			{Name: "str", Type: cty.String},
+		},
+	},
+	"lowercase": {
+		ReturnType: cty.String,
+		Params: []function.Parameter{
+			{Name: "str", Type: cty.String},
+		},
+		DeprecatedText: "Deprecated since v2.",
+		ReplacedBy:     "lower",
+	},
+	"legacy": {
+		Description:    lang.Markdown("Does something the old way"),
+		ReturnType:     cty.String,
+		DeprecatedText: "No longer supported",
+	},
+	"join": {
+		ReturnType: cty.String,
+		Params: []function.Parameter{
+			{Name: "separator", Type: cty.String},
+		},
+		VarParam: &function.Parameter{Name: "lists", Type: cty.List(cty.String), AllowNull: true},
+	},
+}
+
+var functionCallSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"value": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+		"type":  {IsOptional: true, Expr: schema.ExprConstraints{schema.TypeDeclarationExpr{}}},
+	},
+	Blocks: map[string]*schema.BlockSchema{
+		"block": {
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"value": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_FunctionCallAtPos(t *testing.T) {
+	type callSummary struct {
+		Name         string
+		HasSignature bool
+		ArgCount     int
+		ArgIndex     int
+		Param        string
+	}
+
+	testCases := []struct {
+		name         string
+		cfg          string
+		posAfter     string
+		expectedCall *callSummary
+	}{
+		{
+			"first argument",
+			`value = upper("foo")
+`,
+			`upper(`,
+			&callSummary{Name: "upper", HasSignature: true, ArgCount: 1, ArgIndex: 0, Param: "str"},
+		},
+		{
+			"variadic argument",
+			`value = join(",", ["a"])
+`,
+			`join(",", `,
+			&callSummary{Name: "join", HasSignature: true, ArgCount: 2, ArgIndex: 1, Param: "lists"},
+		},
+		{
+			"right after comma",
+			`value = join(",", ["a"])
+`,
+			`join(",",`,
+			&callSummary{Name: "join", HasSignature: true, ArgCount: 2, ArgIndex: 1, Param: "lists"},
+		},
+		{
+			"end of argument",
+			`value = join(",", ["a"])
+`,
+			`join(","`,
+			&callSummary{Name: "join", HasSignature: true, ArgCount: 2, ArgIndex: 0, Param: "separator"},
+		},
+		{
+			"after trailing comma",
+			`value = join(",", ["a"], )
+`,
+			`["a"], `,
+			&callSummary{Name: "join", HasSignature: true, ArgCount: 2, ArgIndex: 2, Param: "lists"},
+		},
+		{
+			"function name",
+			`value = upper("foo")
+`,
+			`up`,
+			&callSummary{Name: "upper", HasSignature: true, ArgCount: 1, ArgIndex: -1},
+		},
+		{
+			"nested call",
+			`value = upper(join(",", ["a"]))
+`,
+			`join(",", `,
+			&callSummary{Name: "join", HasSignature: true, ArgCount: 2, ArgIndex: 1, Param: "lists"},
+		},
+		{
+			"outer call of nested call",
+			`value = join(",", upper("a"))
+`,
+			`join(",",`,
+			&callSummary{Name: "join", HasSignature: true, ArgCount: 2, ArgIndex: 1, Param: "lists"},
+		},
+		{
+			"unknown function",
+			`value = unknown(1)
+`,
+			`unknown(`,
+			&callSummary{Name: "unknown", HasSignature: false, ArgCount: 1, ArgIndex: 0},
+		},
+		{
+			"call in block",
+			`block {
+  value = upper("foo")
+}
+`,
+			`upper("f`,
+			&callSummary{Name: "upper", HasSignature: true, ArgCount: 1, ArgIndex: 0, Param: "str"},
+		},
+		{
+			"no call",
+			`value = "foo"
+`,
+			`"f`,
+			nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, functionCallSchema, map[string]string{"test.tf": tc.cfg})
+			d.SetFunctions(functionCallFunctions)
+
+			call, err := d.FunctionCallAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var summary *callSummary
+			if call != nil {
+				summary = &callSummary{
+					Name:         call.Name,
+					HasSignature: call.Signature != nil,
+					ArgCount:     len(call.ArgRanges),
+					ArgIndex:     call.ArgIndex,
+				}
+				if param, ok := call.Param(); ok {
+					summary.Param = param.Name
+				}
+			}
+
+			if diff := cmp.Diff(tc.expectedCall, summary); diff != "" {
+				t.Fatalf("unexpected call: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFile_functionCalls(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"valid calls",
+			`value = join(",", ["a"], ["b"])
+block {
+  value = upper(join("", []))
+}
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"not enough arguments",
+			`value = upper()
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Not enough function arguments",
+					Detail:   `Function "upper" expects 1 argument(s). Missing value for "str".`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 15, Byte: 14},
+						End:      hcl.Pos{Line: 1, Column: 16, Byte: 15},
+					},
+				},
+			},
+		},
+		{
+			"too many arguments",
+			`value = upper("a", "b")
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Too many function arguments",
+					Detail:   `Function "upper" expects only 1 argument(s).`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 20, Byte: 19},
+						End:      hcl.Pos{Line: 1, Column: 23, Byte: 22},
+					},
+				},
+			},
+		},
+		{
+			"convertible literal arguments",
+			`value = join(1, ["a", 2], toset(["b"]), null)
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"invalid literal argument",
+			`value = upper(["a"])
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid function argument",
+					Detail:   `Invalid value for "str" parameter: string required.`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 15, Byte: 14},
+						End:      hcl.Pos{Line: 1, Column: 20, Byte: 19},
+					},
+				},
+			},
+		},
+		{
+			"invalid variadic argument",
+			`value = join(",", ["a"], "b")
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid function argument",
+					Detail:   `Invalid value for "lists" parameter: list of string required.`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 26, Byte: 25},
+						End:      hcl.Pos{Line: 1, Column: 29, Byte: 28},
+					},
+				},
+			},
+		},
+		{
+			"null argument",
+			`value = upper(null)
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid function argument",
+					Detail:   `Invalid value for "str" parameter: argument must not be null.`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 15, Byte: 14},
+						End:      hcl.Pos{Line: 1, Column: 19, Byte: 18},
+					},
+				},
+			},
+		},
+		{
+			"invalid argument before expanded arguments",
+			`value = join([], var.lists...)
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid function argument",
+					Detail:   `Invalid value for "separator" parameter: string required.`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 14, Byte: 13},
+						End:      hcl.Pos{Line: 1, Column: 16, Byte: 15},
+					},
+				},
+			},
+		},
+		{
+			"expanded arguments",
+			`value = upper(var.list...)
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"unknown function",
+			`value = unknown(1, 2, 3)
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"type declaration",
+			`type = map(string, number)
+`,
+			hcl.Diagnostics{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, functionCallSchema, map[string]string{"test.tf": tc.cfg})
+			d.SetFunctions(functionCallFunctions)
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFileWithFixes_deprecatedFunctions(t *testing.T) {
+	cfg := `value = lowercase(legacy())
+`
+	d := newTestDecoder(t, functionCallSchema, map[string]string{"test.tf": cfg})
+	d.SetFunctions(functionCallFunctions)
+
+	diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDiags := hcl.Diagnostics{
+		{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated function",
+			Detail:   `Function "lowercase" is deprecated: Deprecated since v2. Use "lower" instead.`,
+			Subject: &hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+				End:      hcl.Pos{Line: 1, Column: 18, Byte: 17},
+			},
+		},
+		{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated function",
+			Detail:   `Function "legacy" is deprecated: No longer supported.`,
+			Subject: &hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 19, Byte: 18},
+				End:      hcl.Pos{Line: 1, Column: 25, Byte: 24},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedDiags, diags); diff != "" {
+		t.Fatalf("unexpected diagnostics: %s", diff)
+	}
+
+	expectedFixes := lang.DiagnosticFixes{
+		{
+			Title:      `Replace with "lower"`,
+			Diagnostic: diags[0],
+			Edits: []lang.TextEdit{
+				{
+					Range:   *expectedDiags[0].Subject,
+					NewText: "lower",
+					Snippet: "lower",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedFixes, fixes); diff != "" {
+		t.Fatalf("unexpected fixes: %s", diff)
+	}
+	if fixes[0].Diagnostic != diags[0] {
+		t.Fatal("expected fix to reference the diagnostic")
+	}
+}
+
+func TestDecoder_HoverAtPos_functions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		posAfter      string
+		expectedHover *lang.HoverData
+	}{
+		{
+			"function name",
+			`value = join(",", ["a"])
+`,
+			"jo",
+			&lang.HoverData{
+				Content: lang.Markdown("`join(separator string, ...lists list of string) string`"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+					End:      hcl.Pos{Line: 1, Column: 13, Byte: 12},
+				},
+			},
+		},
+		{
+			"deprecated function",
+			`value = legacy()
+`,
+			"leg",
+			&lang.HoverData{
+				Content: lang.Markdown("`legacy() string`\n\nDoes something the old way" +
+					"\n\n**Deprecated:** Function \"legacy\" is deprecated: No longer supported."),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+					End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, functionCallSchema, map[string]string{"test.tf": tc.cfg})
+			d.SetFunctions(functionCallFunctions)
+
+			hoverData, err := d.HoverAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedHover, hoverData); diff != "" {
+				t.Fatalf("unexpected hover data: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_functions(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"ref": {
+				IsOptional: true,
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.String},
+				},
+			},
+		},
+	}
+	cfg := `ref = 
+`
+	d := newTestDecoder(t, bodySchema, map[string]string{"test.tf": cfg})
+	d.SetFunctions(functionCallFunctions)
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 7, Byte: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	editRng := hcl.Range{
+		Filename: "test.tf",
+		Start:    hcl.Pos{Line: 1, Column: 7, Byte: 6},
+		End:      hcl.Pos{Line: 1, Column: 7, Byte: 6},
+	}
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:  "join",
+			Detail: "join(separator string, ...lists list of string) string",
+			Kind:   lang.FunctionCandidateKind,
+			LabelDetails: &lang.LabelDetails{
+				Detail:      "(separator string, ...lists list of string)",
+				Description: "string",
+			},
+			TextEdit: lang.TextEdit{
+				Range:   editRng,
+				NewText: "join()",
+				Snippet: "join(${0})",
+			},
+		},
+		{
+			Label:  "upper",
+			Detail: "upper(str string) string",
+			Kind:   lang.FunctionCandidateKind,
+			LabelDetails: &lang.LabelDetails{
+				Detail:      "(str string)",
+				Description: "string",
+			},
+			TextEdit: lang.TextEdit{
+				Range:   editRng,
+				NewText: "upper()",
+				Snippet: "upper(${0})",
+			},
+		},
+		{
+			Label:        "legacy",
+			Detail:       "legacy() string",
+			Description:  lang.Markdown("Does something the old way"),
+			IsDeprecated: true,
+			Kind:         lang.FunctionCandidateKind,
+			LabelDetails: &lang.LabelDetails{
+				Detail:      "()",
+				Description: "string",
+			},
+			TextEdit: lang.TextEdit{
+				Range:   editRng,
+				NewText: "legacy()",
+				Snippet: "legacy(${0})",
+			},
+			Score: -1,
+		},
+		{
+			Label:        "lowercase",
+			Detail:       "lowercase(str string) string",
+			IsDeprecated: true,
+			Kind:         lang.FunctionCandidateKind,
+			LabelDetails: &lang.LabelDetails{
+				Detail:      "(str string)",
+				Description: "string",
+			},
+			TextEdit: lang.TextEdit{
+				Range:   editRng,
+				NewText: "lowercase()",
+				Snippet: "lowercase(${0})",
+			},
+			Score: -1,
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
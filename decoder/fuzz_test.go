@@ -0,0 +1,272 @@
+//go:build go1.18
+// +build go1.18
+
+package decoder
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// fuzzSchema covers most kinds of schema the decoder understands,
+// so that random configuration has a good chance of exercising them
+var fuzzSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"str":  {Expr: schema.LiteralTypeOnly(cty.String)},
+		"num":  {Expr: schema.LiteralTypeOnly(cty.Number)},
+		"bool": {Expr: schema.LiteralTypeOnly(cty.Bool)},
+		"obj": {Expr: schema.LiteralTypeOnly(cty.Object(map[string]cty.Type{
+			"foo": cty.String,
+			"bar": cty.List(cty.Number),
+		}))},
+		"map": {Expr: schema.LiteralTypeOnly(cty.Map(cty.String))},
+		"tuple": {Expr: schema.LiteralTypeOnly(cty.Tuple([]cty.Type{
+			cty.String, cty.Number,
+		}))},
+		"kw": {Expr: schema.ExprConstraints{
+			schema.KeywordExpr{Keyword: "foo"},
+			schema.LiteralValue{Val: cty.StringVal("bar")},
+		}},
+		"ref": {Expr: schema.ExprConstraints{
+			schema.TraversalExpr{OfType: cty.String},
+			schema.TraversalExpr{OfScopeId: lang.ScopeId("res")},
+		}},
+		"list": {Expr: schema.ExprConstraints{
+			schema.ListExpr{Elem: schema.ExprConstraints{
+				schema.TraversalExpr{OfType: cty.String},
+				schema.KeywordExpr{Keyword: "kw"},
+			}},
+		}},
+		"set": {Expr: schema.ExprConstraints{
+			schema.SetExpr{Elem: schema.LiteralTypeOnly(cty.String)},
+		}},
+		"tuplecons": {Expr: schema.ExprConstraints{
+			schema.TupleConsExpr{AnyElem: schema.LiteralTypeOnly(cty.Number)},
+		}},
+		"tupleexpr": {Expr: schema.ExprConstraints{
+			schema.TupleExpr{Elems: []schema.ExprConstraints{
+				schema.LiteralTypeOnly(cty.String),
+			}},
+		}},
+		"mapexpr": {Expr: schema.ExprConstraints{
+			schema.MapExpr{Elem: schema.LiteralTypeOnly(cty.String)},
+		}},
+		"objexpr": {Expr: schema.ExprConstraints{
+			schema.ObjectExpr{Attributes: schema.ObjectExprAttributes{
+				"foo": {Expr: schema.LiteralTypeOnly(cty.String)},
+				"nested": {Expr: schema.ExprConstraints{
+					schema.ObjectExpr{Attributes: schema.ObjectExprAttributes{
+						"bar": {Expr: schema.LiteralTypeOnly(cty.Number)},
+					}},
+				}},
+			}},
+		}},
+		"type": {Expr: schema.ExprConstraints{
+			schema.TypeDeclarationExpr{},
+		}},
+	},
+	Blocks: map[string]*schema.BlockSchema{
+		"res": {
+			Labels: []*schema.LabelSchema{
+				{Name: "type", IsDepKey: true, Completable: true},
+				{Name: "name"},
+			},
+			Address: &schema.BlockAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.LabelStep{Index: 0},
+					schema.LabelStep{Index: 1},
+				},
+				ScopeId:             lang.ScopeId("res"),
+				AsReference:         true,
+				BodyAsData:          true,
+				InferBody:           true,
+				DependentBodyAsData: true,
+				InferDependentBody:  true,
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"count": {Expr: schema.LiteralTypeOnly(cty.Number)},
+					"ref": {Expr: schema.ExprConstraints{
+						schema.TraversalExpr{OfScopeId: lang.ScopeId("res")},
+					}},
+				},
+				Blocks: map[string]*schema.BlockSchema{
+					"nested": {
+						Type: schema.BlockTypeList,
+						Body: &schema.BodySchema{
+							Attributes: map[string]*schema.AttributeSchema{
+								"foo": {Expr: schema.LiteralTypeOnly(cty.String)},
+							},
+						},
+					},
+				},
+			},
+			DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{{Index: 0, Value: "special"}},
+				}): {
+					Attributes: map[string]*schema.AttributeSchema{
+						"special": {Expr: schema.LiteralTypeOnly(cty.Map(cty.String))},
+					},
+				},
+			},
+		},
+		"var": {
+			Labels: []*schema.LabelSchema{
+				{Name: "name"},
+			},
+			Address: &schema.BlockAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.StaticStep{Name: "var"},
+					schema.LabelStep{Index: 0},
+				},
+				AsTypeOf: &schema.BlockAsTypeOf{
+					AttributeExpr:  "type",
+					AttributeValue: "default",
+				},
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"type":    {Expr: schema.ExprConstraints{schema.TypeDeclarationExpr{}}},
+					"default": {Expr: schema.LiteralTypeOnly(cty.DynamicPseudoType)},
+				},
+			},
+		},
+		"any": {
+			Body: &schema.BodySchema{
+				AnyAttribute: &schema.AttributeSchema{
+					Expr: schema.LiteralTypeOnly(cty.DynamicPseudoType),
+				},
+			},
+		},
+	},
+}
+
+var fuzzSeedConfigs = []string{
+	``,
+	`str = "foo"
+num = 42
+bool = true
+`,
+	`obj = {
+  foo = "bar"
+  bar = [1, 2]
+}
+map = { key = "value" }
+tuple = ["foo", 42]
+kw = foo
+`,
+	`ref = var.foo
+list = [var.foo, kw]
+set = ["foo"]
+tuplecons = [1, 2]
+tupleexpr = ["one"]
+mapexpr = { foo = "bar" }
+objexpr = {
+  foo = "bar"
+  nested = {
+    bar = 1
+  }
+}
+type = object({ foo = list(string) })
+`,
+	`res "special" "foo" {
+  count = 1
+  ref = res.special.bar
+  special = { key = "value" }
+  nested {
+    foo = "bar"
+  }
+}
+var "foo" {
+  type = string
+  default = "bar"
+}
+any {
+  anything = 42
+}
+`,
+	`res "" {
+  str =
+}
+`,
+	`# ☃ comment 🚀
+str = "café"
+`,
+	`res "foo" "bar" { nested { foo = `,
+	`obj = { foo = "bar", bar = [1, 2] `,
+	`list = [`,
+}
+
+// fuzzPos returns a position within the config, or (if inconsistent)
+// arbitrary position based on given line, column and byte offset
+func fuzzPos(cfg []byte, line, column, byteOffset int, isConsistent bool) hcl.Pos {
+	if !isConsistent {
+		return hcl.Pos{Line: line, Column: column, Byte: byteOffset}
+	}
+	if byteOffset < 0 {
+		byteOffset = -byteOffset
+	}
+	if len(cfg) == 0 {
+		return hcl.InitialPos
+	}
+	byteOffset = byteOffset % (len(cfg) + 1)
+
+	pos := hcl.InitialPos
+	for i := 0; i < byteOffset; i++ {
+		if cfg[i] == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+		pos.Byte++
+	}
+	return pos
+}
+
+func addFuzzSeeds(f *testing.F) {
+	for _, cfg := range fuzzSeedConfigs {
+		for _, offset := range []int{0, len(cfg) / 3, len(cfg) / 2, len(cfg) - 1, len(cfg)} {
+			f.Add([]byte(cfg), 1, 1, offset, true)
+		}
+		f.Add([]byte(cfg), 2, 100, 5, false)
+		f.Add([]byte(cfg), -1, 0, len(cfg)+10, false)
+	}
+}
+
+func FuzzDecoder_CandidatesAtPos(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, cfg []byte, line, column, byteOffset int, isConsistent bool) {
+		d := newTestDecoder(t, fuzzSchema, map[string]string{"test.tf": string(cfg)})
+		pos := fuzzPos(cfg, line, column, byteOffset, isConsistent)
+		d.CandidatesAtPos("test.tf", pos)
+	})
+}
+
+func FuzzDecoder_HoverAtPos(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, cfg []byte, line, column, byteOffset int, isConsistent bool) {
+		d := newTestDecoder(t, fuzzSchema, map[string]string{"test.tf": string(cfg)})
+		pos := fuzzPos(cfg, line, column, byteOffset, isConsistent)
+		d.HoverAtPos("test.tf", pos)
+		d.ReferenceOriginAtPos("test.tf", pos)
+		d.InnermostReferenceTargetAtPos("test.tf", pos)
+		d.OutermostReferenceTargetAtPos("test.tf", pos)
+	})
+}
+
+func FuzzDecoder_SemanticTokensInFile(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, cfg []byte, line, column, byteOffset int, isConsistent bool) {
+		d := newTestDecoder(t, fuzzSchema, map[string]string{"test.tf": string(cfg)})
+		d.SemanticTokensInFile("test.tf")
+		d.SymbolsInFile("test.tf")
+		d.LinksInFile("test.tf")
+		d.ValidateFile("test.tf")
+	})
+}
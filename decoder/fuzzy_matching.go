@@ -0,0 +1,181 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// minFuzzyPrefixLength represents the minimum length of a prefix
+// to be matched with a tolerance of typos, as shorter prefixes
+// would match nearly everything
+const minFuzzyPrefixLength = 2
+
+// withFuzzyFilterText sets FilterText of keyword candidates
+// which only match the prefix within one edit, such that
+// the client doesn't filter them out
+func (d *Decoder) withFuzzyFilterText(candidates lang.Candidates, prefixRng hcl.Range) lang.Candidates {
+	if !d.features.FuzzyMatching {
+		return candidates
+	}
+
+	prefix := string(d.prefixFromRange(prefixRng))
+	if len(prefix) == 0 {
+		return candidates
+	}
+
+	for i, c := range candidates.List {
+		if c.Kind != lang.KeywordCandidateKind || strings.HasPrefix(c.Label, prefix) {
+			continue
+		}
+		if fuzzyPrefixMatch(prefix, c.Label) {
+			candidates.List[i].FilterText = prefix
+		}
+	}
+
+	return candidates
+}
+
+// fuzzyPrefixMatch returns true if the word starts with the prefix,
+// or with a string which is within one edit of the prefix
+func fuzzyPrefixMatch(prefix, word string) bool {
+	if strings.HasPrefix(word, prefix) {
+		return true
+	}
+
+	p, w := []rune(prefix), []rune(word)
+	if len(p) < minFuzzyPrefixLength {
+		return false
+	}
+
+	for _, n := range []int{len(p) - 1, len(p), len(p) + 1} {
+		if n > 0 && n <= len(w) && isWithinOneEdit(p, w[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithinOneEdit returns true if a can be turned into b by at most
+// one insertion, deletion, substitution or transposition
+// of two adjacent characters
+func isWithinOneEdit(a, b []rune) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	i := 0
+	for i < len(a) && a[i] == b[i] {
+		i++
+	}
+
+	switch len(b) - len(a) {
+	case 0:
+		if i == len(a) {
+			return true
+		}
+		if equalRunes(a[i+1:], b[i+1:]) {
+			// substitution
+			return true
+		}
+		return i+1 < len(a) && a[i] == b[i+1] && a[i+1] == b[i] &&
+			equalRunes(a[i+2:], b[i+2:])
+	case 1:
+		return equalRunes(a[i:], b[i+1:])
+	}
+
+	return false
+}
+
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keywordsOf returns sorted keywords accepted by the constraints,
+// including bool literals where bool type is accepted
+func (ec ExprConstraints) keywordsOf() []string {
+	keywords := make([]string, 0)
+	for _, c := range ec.byPrecedence() {
+		if kw, ok := c.(schema.KeywordExpr); ok {
+			keywords = append(keywords, kw.Keyword)
+		}
+	}
+	if ec.HasLiteralTypeOf(cty.Bool) {
+		keywords = append(keywords, "false", "true")
+	}
+	sort.Strings(keywords)
+	return keywords
+}
+
+// validateKeywordSpelling reports an expression which is not a valid
+// keyword (nor reference), but is within one edit of a keyword accepted
+// by the constraints, such as "ture", along with a fix to correct it
+func (d *Decoder) validateKeywordSpelling(expr hclsyntax.Expression, ec ExprConstraints, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if !d.features.FuzzyMatching {
+		return diags
+	}
+
+	ste, ok := expr.(*hclsyntax.ScopeTraversalExpr)
+	if !ok || len(ste.Traversal) != 1 {
+		return diags
+	}
+	if _, ok := ec.TraversalExpr(); ok {
+		// possibly a valid reference
+		return diags
+	}
+
+	name := ste.Traversal.RootName()
+	keywords := ec.keywordsOf()
+	for _, keyword := range keywords {
+		if keyword == name {
+			return diags
+		}
+	}
+
+	for _, keyword := range keywords {
+		if !isWithinOneEdit([]rune(name), []rune(keyword)) {
+			continue
+		}
+
+		diag := d.newDiagnostic(CodeInvalidKeyword, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid keyword",
+			Detail:   fmt.Sprintf("%q is not a valid keyword, did you mean %q?", name, keyword),
+			Subject:  ste.Range().Ptr(),
+		})
+		diags = append(diags, diag)
+
+		if fixes != nil {
+			*fixes = append(*fixes, lang.DiagnosticFix{
+				Title:      fmt.Sprintf("Replace with %q", keyword),
+				Diagnostic: diag,
+				Edits: []lang.TextEdit{
+					{
+						Range:   ste.Range(),
+						NewText: keyword,
+						Snippet: keyword,
+					},
+				},
+			})
+		}
+		return diags
+	}
+
+	return diags
+}
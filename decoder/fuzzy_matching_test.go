@@ -0,0 +1,227 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestIsWithinOneEdit(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"true", "true", true},
+		{"ture", "true", true},
+		{"tru", "true", true},
+		{"trues", "true", true},
+		{"trye", "true", true},
+		{"rtue", "true", true},
+		{"tuer", "true", false},
+		{"fasle", "false", true},
+		{"flase", "false", true},
+		{"fals", "true", false},
+		{"", "a", true},
+		{"", "ab", false},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s-%s", i, tc.a, tc.b), func(t *testing.T) {
+			if given := isWithinOneEdit([]rune(tc.a), []rune(tc.b)); given != tc.expected {
+				t.Fatalf("expected %t, given %t", tc.expected, given)
+			}
+		})
+	}
+}
+
+func TestFuzzyPrefixMatch(t *testing.T) {
+	testCases := []struct {
+		prefix, word string
+		expected     bool
+	}{
+		{"", "ignore", true},
+		{"ig", "ignore", true},
+		{"in", "ignore", true},
+		{"ingo", "ignore", true},
+		{"ignroe", "ignore", true},
+		{"x", "ignore", false},
+		{"xy", "ignore", false},
+		{"ingroe", "ignore", false},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s-%s", i, tc.prefix, tc.word), func(t *testing.T) {
+			if given := fuzzyPrefixMatch(tc.prefix, tc.word); given != tc.expected {
+				t.Fatalf("expected %t, given %t", tc.expected, given)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_fuzzyKeywords(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"mode": {
+				IsOptional: true,
+				Expr: schema.ExprConstraints{
+					schema.KeywordExpr{Keyword: "ignore"},
+					schema.KeywordExpr{Keyword: "fail"},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		features           Features
+		expectedFilterText map[string]string
+	}{
+		{
+			"disabled",
+			Features{},
+			map[string]string{
+				"ignore": "",
+			},
+		},
+		{
+			"enabled",
+			Features{FuzzyMatching: true},
+			map[string]string{
+				"ignore": "ingo",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			d.SetFeatures(tc.features)
+
+			f, _ := hclsyntax.ParseConfig([]byte("mode = ingo\n"), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 12, Byte: 11})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			filterText := make(map[string]string, 0)
+			for _, c := range candidates.List {
+				if c.Kind == lang.KeywordCandidateKind {
+					filterText[c.Label] = c.FilterText
+				}
+			}
+			if diff := cmp.Diff(tc.expectedFilterText, filterText); diff != "" {
+				t.Fatalf("unexpected filter text: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFileWithFixes_keywords(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"enabled": {
+				IsOptional: true,
+				Expr:       schema.LiteralTypeOnly(cty.Bool),
+			},
+			"mode": {
+				IsOptional: true,
+				Expr: schema.ExprConstraints{
+					schema.KeywordExpr{Keyword: "ignore"},
+				},
+			},
+			"ref": {
+				IsOptional: true,
+				Expr: schema.ExprConstraints{
+					schema.KeywordExpr{Keyword: "ignore"},
+					schema.TraversalExpr{OfType: cty.String},
+				},
+			},
+		},
+	}
+	cfg := `enabled = ture
+mode = ingore
+ref = ingore
+`
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.SetFeatures(Features{FuzzyMatching: true})
+
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enabledRng := hcl.Range{
+		Filename: "test.tf",
+		Start:    hcl.Pos{Line: 1, Column: 11, Byte: 10},
+		End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+	}
+	modeRng := hcl.Range{
+		Filename: "test.tf",
+		Start:    hcl.Pos{Line: 2, Column: 8, Byte: 22},
+		End:      hcl.Pos{Line: 2, Column: 14, Byte: 28},
+	}
+	expectedDiags := hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid keyword",
+			Detail:   `"ture" is not a valid keyword, did you mean "true"?`,
+			Subject:  enabledRng.Ptr(),
+		},
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid keyword",
+			Detail:   `"ingore" is not a valid keyword, did you mean "ignore"?`,
+			Subject:  modeRng.Ptr(),
+		},
+	}
+	if diff := cmp.Diff(expectedDiags, diags); diff != "" {
+		t.Fatalf("unexpected diagnostics: %s", diff)
+	}
+
+	expectedEdits := [][]lang.TextEdit{
+		{
+			{Range: enabledRng, NewText: "true", Snippet: "true"},
+		},
+		{
+			{Range: modeRng, NewText: "ignore", Snippet: "ignore"},
+		},
+	}
+	for i, diag := range diags {
+		diagFixes := fixes.ForDiagnostic(diag)
+		if len(diagFixes) != 1 {
+			t.Fatalf("expected 1 fix for diagnostic %d, given %d", i, len(diagFixes))
+		}
+		if diff := cmp.Diff(expectedEdits[i], diagFixes[0].Edits); diff != "" {
+			t.Fatalf("unexpected edits of fix %d: %s", i, diff)
+		}
+	}
+
+	d.SetFeatures(Features{})
+	diags, fixes, err = d.ValidateFileWithFixes("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 0 || len(fixes) != 0 {
+		t.Fatalf("expected no diagnostics or fixes with fuzzy matching disabled, given: %#v, %#v", diags, fixes)
+	}
+}
@@ -0,0 +1,366 @@
+package decoder
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// blockContent provides access to labels and attributes of a block
+// regardless of the hcl.Body implementation it comes from, such that
+// schema and address of the block can be resolved in the same way
+// for native syntax and for other bodies (e.g. JSON).
+type blockContent interface {
+	labels() []string
+	attributeExpr(name string) (hcl.Expression, bool)
+}
+
+type syntaxBlock struct {
+	block *hclsyntax.Block
+}
+
+func (sb syntaxBlock) labels() []string {
+	return sb.block.Labels
+}
+
+func (sb syntaxBlock) attributeExpr(name string) (hcl.Expression, bool) {
+	if sb.block.Body == nil {
+		return nil, false
+	}
+	attr, ok := sb.block.Body.Attributes[name]
+	if !ok {
+		return nil, false
+	}
+	return attr.Expr, true
+}
+
+type genericBlock struct {
+	block *hcl.Block
+}
+
+func (gb genericBlock) labels() []string {
+	return gb.block.Labels
+}
+
+func (gb genericBlock) attributeExpr(name string) (hcl.Expression, bool) {
+	if gb.block.Body == nil {
+		return nil, false
+	}
+	content, _, _ := gb.block.Body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: name},
+		},
+	})
+	attr, ok := content.Attributes[name]
+	if !ok {
+		return nil, false
+	}
+	return attr.Expr, true
+}
+
+// hclBodySchema converts the body schema into hcl.BodySchema
+// which is understood by any hcl.Body implementation
+func hclBodySchema(bodySchema *schema.BodySchema) *hcl.BodySchema {
+	hclSchema := &hcl.BodySchema{}
+
+	for _, name := range sortedAttributeNames(bodySchema.Attributes) {
+		hclSchema.Attributes = append(hclSchema.Attributes, hcl.AttributeSchema{
+			Name: name,
+		})
+	}
+
+	for _, bType := range sortedBlockTypes(bodySchema.Blocks) {
+		bSchema := bodySchema.Blocks[bType]
+		labelNames := make([]string, len(bSchema.Labels))
+		for i, label := range bSchema.Labels {
+			labelNames[i] = label.Name
+		}
+		hclSchema.Blocks = append(hclSchema.Blocks, hcl.BlockHeaderSchema{
+			Type:       bType,
+			LabelNames: labelNames,
+		})
+	}
+
+	return hclSchema
+}
+
+// genericBodyContent returns content of a body which is not
+// represented by *hclsyntax.Body (e.g. JSON body or a body
+// produced by an extension such as dynblock), along with
+// diagnostics for any content not declared in the schema.
+//
+// Such bodies are decoded with reduced functionality, as hcl.Body
+// only exposes content declared upfront. Blocks of types
+// not declared in the schema (AnyBlock) are therefore not supported
+// and expressions are only interpreted via hcl.Expression.
+func genericBodyContent(body hcl.Body, bodySchema *schema.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	hclSchema := hclBodySchema(bodySchema)
+
+	if bodySchema.AnyAttribute == nil && !bodySchema.AllowUnknownAttributes {
+		return body.Content(hclSchema)
+	}
+
+	content, remain, diags := body.PartialContent(hclSchema)
+	if content.Attributes == nil {
+		content.Attributes = make(hcl.Attributes, 0)
+	}
+	attrs, aDiags := remain.JustAttributes()
+	diags = append(diags, aDiags...)
+	for name, attr := range attrs {
+		content.Attributes[name] = attr
+	}
+
+	return content, diags
+}
+
+func (d *Decoder) validateGenericBody(body hcl.Body, bodySchema *schema.BodySchema, fixes *lang.DiagnosticFixes, related relatedInfos) hcl.Diagnostics {
+	if hsBody, ok := body.(*hclsyntax.Body); ok {
+		return d.validateBody(hsBody, bodySchema, fixes, related)
+	}
+
+	diags := hcl.Diagnostics{}
+	if bodySchema == nil {
+		return diags
+	}
+
+	content, cDiags := genericBodyContent(body, bodySchema)
+	diags = append(diags, cDiags...)
+
+	for name, attr := range content.Attributes {
+		aSchema, ok := attributeSchemaForName(bodySchema, name)
+		if !ok {
+			diags = append(diags, d.unknownAttributeDiagnostics(bodySchema, name, attr.NameRange, related)...)
+			continue
+		}
+
+		diags = append(diags, d.validateAttributeExpr(name, attr.Expr, aSchema)...)
+		diags = append(diags, d.validateNamedTuple(name, attr.Expr, ExprConstraints(aSchema.Expr))...)
+		diags = append(diags, d.validateSize(name, attr.Expr, ExprConstraints(aSchema.Expr), fixes)...)
+		diags = append(diags, d.validateReferenceTypes(attr.Expr, ExprConstraints(aSchema.Expr), related)...)
+		diags = append(diags, d.validateReferenceScopes(attr.Expr, ExprConstraints(aSchema.Expr), related)...)
+		diags = append(diags, d.validateSensitiveReferences(name, attr.Expr, aSchema)...)
+		diags = append(diags, d.validateWriteOnlyReferences(attr.Expr, aSchema)...)
+	}
+
+	for _, block := range content.Blocks {
+		bSchema := bodySchema.Blocks[block.Type]
+		diags = append(diags, d.validateLabels(block.Labels, block.LabelRanges, bSchema.Labels)...)
+
+		mergedSchema, err := mergeBodySchemasForBlock(genericBlock{block}, bSchema)
+		if err != nil {
+			continue
+		}
+		diags = append(diags, d.validateGenericBody(block.Body, mergedSchema, fixes, related)...)
+	}
+
+	return diags
+}
+
+func (d *Decoder) symbolsForGenericBody(body hcl.Body, bodySchema *schema.BodySchema, nestingLvl int) []Symbol {
+	if hsBody, ok := body.(*hclsyntax.Body); ok {
+		return d.symbolsForBody(hsBody, nestingLvl)
+	}
+
+	symbols := make([]Symbol, 0)
+	if body == nil || d.isNestedTooDeep(nestingLvl) {
+		return symbols
+	}
+
+	var content *hcl.BodyContent
+	if bodySchema == nil {
+		// without schema everything is interpreted as attributes
+		attrs, _ := body.JustAttributes()
+		content = &hcl.BodyContent{Attributes: attrs}
+	} else {
+		content, _ = genericBodyContent(body, bodySchema)
+	}
+
+	for name, attr := range content.Attributes {
+		symbols = append(symbols, &AttributeSymbol{
+			AttrName:      name,
+			ExprKind:      symbolExprKind(attr.Expr),
+			rng:           attr.Range,
+			nestedSymbols: d.nestedSymbolsForExpr(attr.Expr, 0),
+		})
+	}
+	for _, block := range content.Blocks {
+		var blockBodySchema *schema.BodySchema
+		mergedSchema, err := mergeBodySchemasForBlock(genericBlock{block}, bodySchema.Blocks[block.Type])
+		if err == nil {
+			blockBodySchema = mergedSchema
+		}
+
+		symbols = append(symbols, &BlockSymbol{
+			Type:          block.Type,
+			Labels:        block.Labels,
+			rng:           block.DefRange,
+			nestedSymbols: d.symbolsForGenericBody(block.Body, blockBodySchema, nestingLvl+1),
+		})
+	}
+
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return symbols[i].Range().Start.Byte < symbols[j].Range().Start.Byte
+	})
+
+	return symbols
+}
+
+func (d *Decoder) referenceOriginsInGenericBody(body hcl.Body, bodySchema *schema.BodySchema) lang.ReferenceOrigins {
+	if hsBody, ok := body.(*hclsyntax.Body); ok {
+		return d.referenceOriginsInBody(hsBody, bodySchema)
+	}
+
+	origins := make(lang.ReferenceOrigins, 0)
+	if bodySchema == nil {
+		return origins
+	}
+
+	content, _ := genericBodyContent(body, bodySchema)
+
+	for name, attr := range content.Attributes {
+		aSchema, ok := attributeSchemaForName(bodySchema, name)
+		if !ok {
+			// skip unknown attribute
+			continue
+		}
+
+		origins = append(origins, referenceOriginsInExpr(attr.Expr, ExprConstraints(aSchema.Expr))...)
+	}
+
+	for _, block := range content.Blocks {
+		bSchema := bodySchema.Blocks[block.Type]
+		origins = append(origins, d.referenceOriginsInGenericBody(block.Body, bSchema.Body)...)
+		origins = append(origins, d.syntheticOriginsForBlock(block, bSchema)...)
+	}
+
+	return origins
+}
+
+// decodeReferenceTargetsForGenericBody collects reference targets
+// from a body of any implementation. Compared to native syntax,
+// targets are not inferred from nested bodies or expressions
+// and ranges of blocks only cover their header (DefRange).
+func (d *Decoder) decodeReferenceTargetsForGenericBody(body hcl.Body, bodySchema *schema.BodySchema) lang.ReferenceTargets {
+	if hsBody, ok := body.(*hclsyntax.Body); ok {
+		return d.decodeReferenceTargetsForBody(hsBody, bodySchema)
+	}
+
+	refs := make(lang.ReferenceTargets, 0)
+	if bodySchema == nil {
+		return refs
+	}
+
+	content, _ := genericBodyContent(body, bodySchema)
+
+	for name, attr := range content.Attributes {
+		attrSchema, ok := attributeSchemaForName(bodySchema, name)
+		if !ok {
+			// unknown attribute (no schema)
+			continue
+		}
+		refs = append(refs, referenceTargetsForGenericAttribute(attr, attrSchema)...)
+	}
+
+	for _, block := range content.Blocks {
+		bSchema := bodySchema.Blocks[block.Type]
+		refs = append(refs, d.decodeReferenceTargetsForGenericBody(block.Body, bSchema.Body)...)
+
+		gb := genericBlock{block}
+		addr, ok := resolveBlockContentAddress(gb, bSchema.Address)
+		if !ok {
+			// skip unresolvable address
+			continue
+		}
+
+		if bSchema.Address.AsReference {
+			refs = append(refs, lang.ReferenceTarget{
+				Addr:        addr,
+				ScopeId:     bSchema.Address.ScopeId,
+				RangePtr:    block.DefRange.Ptr(),
+				Name:        bSchema.Address.FriendlyName,
+				Description: descriptionForBlockTarget(bSchema),
+			})
+		}
+
+		isData := false
+		var dataSchema *schema.BodySchema
+		if bSchema.Address.BodyAsData {
+			isData = true
+			dataSchema = bSchema.Body
+		}
+		if bSchema.Address.DependentBodyAsData {
+			depSchema, _, ok := NewBlockSchema(bSchema).dependentBodySchema(gb)
+			if ok {
+				isData = true
+				dataSchema = depSchema
+				if bSchema.Address.BodyAsData {
+					mergedSchema, err := mergeBodySchemasForBlock(gb, bSchema)
+					if err != nil {
+						continue
+					}
+					dataSchema = mergedSchema
+				}
+			}
+		}
+		if isData {
+			refs = append(refs, lang.ReferenceTarget{
+				Addr:        addr,
+				ScopeId:     bSchema.Address.ScopeId,
+				RangePtr:    block.DefRange.Ptr(),
+				Type:        bodyToDataType(bSchema.Type, dataSchema),
+				Description: descriptionForBlockTarget(bSchema),
+			})
+		}
+	}
+
+	sort.Sort(refs)
+
+	return refs
+}
+
+func referenceTargetsForGenericAttribute(attr *hcl.Attribute, attrSchema *schema.AttributeSchema) lang.ReferenceTargets {
+	refs := make(lang.ReferenceTargets, 0)
+
+	attrAddr, ok := resolveAttributeAddress(attr.Name, attrSchema.Address)
+	if ok {
+		if attrSchema.Address.AsReference {
+			refs = append(refs, lang.ReferenceTarget{
+				Addr:        attrAddr,
+				ScopeId:     attrSchema.Address.ScopeId,
+				RangePtr:    attr.Range.Ptr(),
+				Name:        attrSchema.Address.FriendlyName,
+				Description: attrSchema.Description,
+			})
+		}
+
+		if attrSchema.Address.AsExprType {
+			t, ok := exprConstraintToDataType(attrSchema.Expr)
+			if ok {
+				if t == cty.DynamicPseudoType && attr.Expr != nil {
+					// attempt to make the type more specific
+					exprVal, diags := attr.Expr.Value(nil)
+					if !diags.HasErrors() {
+						t = exprVal.Type()
+					}
+				}
+
+				refs = append(refs, lang.ReferenceTarget{
+					Addr:        attrAddr,
+					Type:        t,
+					ScopeId:     attrSchema.Address.ScopeId,
+					RangePtr:    attr.Range.Ptr(),
+					Name:        attrSchema.Address.FriendlyName,
+					Description: attrSchema.Description,
+				})
+			}
+		}
+	}
+
+	ec := ExprConstraints(attrSchema.Expr)
+	refs = append(refs, referencesForExpr(attr.Expr, ec)...)
+	return refs
+}
@@ -0,0 +1,133 @@
+package decoder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var genericBodySchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"variable": {
+			Labels: []*schema.LabelSchema{
+				{Name: "name"},
+			},
+			Address: &schema.BlockAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.StaticStep{Name: "var"},
+					schema.LabelStep{Index: 0},
+				},
+				AsReference: true,
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"default": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.String),
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_ValidateFile_genericBody(t *testing.T) {
+	d := newTestDecoder(t, genericBodySchema, map[string]string{
+		"test.tf.json": `{
+  "variable": {
+    "foo": {
+      "default": ["a"],
+      "unknown": true
+    }
+  }
+}`,
+	})
+
+	diags, err := d.ValidateFile("test.tf.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summaries := make([]string, 0)
+	for _, diag := range diags {
+		summaries = append(summaries, diag.Summary)
+	}
+	expectedSummaries := []string{
+		"Invalid value type",
+		"Extraneous JSON object property",
+	}
+	if diff := cmp.Diff(expectedSummaries, summaries); diff != "" {
+		t.Fatalf("unexpected diagnostics: %s", diff)
+	}
+}
+
+func TestDecoder_CollectReferenceTargets_genericBody(t *testing.T) {
+	d := newTestDecoder(t, genericBodySchema, map[string]string{
+		"test.tf.json": `{
+  "variable": {
+    "foo": {
+      "default": "bar"
+    }
+  }
+}`,
+	})
+
+	targets, err := d.CollectReferenceTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTargets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "foo"},
+			},
+			RangePtr: &hcl.Range{
+				Filename: "test.tf.json",
+				Start:    hcl.Pos{Line: 3, Column: 12, Byte: 29},
+				End:      hcl.Pos{Line: 3, Column: 13, Byte: 30},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedTargets, targets, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected targets: %s", diff)
+	}
+}
+
+func TestDecoder_SymbolsInFile_genericBody(t *testing.T) {
+	d := newTestDecoder(t, genericBodySchema, map[string]string{
+		"test.tf.json": `{
+  "variable": {
+    "foo": {
+      "default": "bar"
+    }
+  }
+}`,
+	})
+
+	symbols, err := d.SymbolsInFile("test.tf.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(symbols) != 1 {
+		t.Fatalf("expected 1 symbol, given %d", len(symbols))
+	}
+	blockSymbol, ok := symbols[0].(*BlockSymbol)
+	if !ok {
+		t.Fatalf("expected block symbol, given %T", symbols[0])
+	}
+	if diff := cmp.Diff([]string{"foo"}, blockSymbol.Labels); diff != "" {
+		t.Fatalf("unexpected labels: %s", diff)
+	}
+	nested := blockSymbol.NestedSymbols()
+	if len(nested) != 1 || nested[0].Name() != "default" {
+		t.Fatalf("unexpected nested symbols: %#v", nested)
+	}
+}
@@ -0,0 +1,121 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// heredocPreviewLines is the maximum number of lines of content
+// previewed in hover of a heredoc marker
+const heredocPreviewLines = 10
+
+// hoverDataForHeredocMarker returns hover data previewing content
+// of the heredoc template where the position is on its opening
+// marker (e.g. <<-EOT), such that the content can be seen
+// even where it's off-screen. Interpolations within the preview
+// are listed along with types of their values, where known.
+func (d *Decoder) hoverDataForHeredocMarker(e *hclsyntax.TemplateExpr, pos hcl.Pos) (*lang.HoverData, bool) {
+	src, err := d.bytesFromRange(e.SrcRange)
+	if err != nil || !bytes.HasPrefix(src, []byte("<<")) {
+		return nil, false
+	}
+
+	markerEnd := bytes.IndexByte(src, '\n')
+	if markerEnd < 0 {
+		return nil, false
+	}
+	// the marker consists of ASCII characters only
+	marker := string(bytes.TrimRight(src[:markerEnd], "\r"))
+	markerRng := hcl.Range{
+		Filename: e.SrcRange.Filename,
+		Start:    e.SrcRange.Start,
+		End: hcl.Pos{
+			Line:   e.SrcRange.Start.Line,
+			Column: e.SrcRange.Start.Column + len(marker),
+			Byte:   e.SrcRange.Start.Byte + len(marker),
+		},
+	}
+	if !containsPosOrEnd(markerRng, pos) {
+		return nil, false
+	}
+
+	// content spans lines between the opening and the closing marker
+	content := src[markerEnd+1:]
+	if closingIdx := bytes.LastIndexByte(content, '\n'); closingIdx >= 0 {
+		content = content[:closingIdx]
+	} else {
+		content = []byte{}
+	}
+	lines := strings.Split(string(content), "\n")
+	if len(content) == 0 {
+		lines = []string{}
+	}
+
+	identifier := strings.TrimLeft(marker, "<-")
+	value := fmt.Sprintf("_heredoc_ `%s`, %d line(s)", identifier, len(lines))
+	if len(lines) > 0 {
+		preview := lines
+		if len(preview) > heredocPreviewLines {
+			preview = preview[:heredocPreviewLines]
+		}
+		value += fmt.Sprintf("\n```\n%s\n", strings.Join(preview, "\n"))
+		if len(lines) > len(preview) {
+			value += "…\n"
+		}
+		value += "```"
+
+		lastLine := e.SrcRange.Start.Line + len(preview)
+		interps := d.heredocInterpolations(e, src, lastLine)
+		if len(interps) > 0 {
+			value += "\n\nInterpolations:\n" + strings.Join(interps, "\n")
+		}
+	}
+
+	return &lang.HoverData{
+		Content: lang.Markdown(value),
+		Range:   markerRng,
+	}, true
+}
+
+// heredocInterpolations returns interpolations (${ ... }) of the heredoc
+// template up to the given line, each rendered as a list item along
+// with the type of the interpolated value, where known
+func (d *Decoder) heredocInterpolations(e *hclsyntax.TemplateExpr, src []byte, lastLine int) []string {
+	interps := make([]string, 0)
+	for _, part := range e.Parts {
+		if _, ok := part.(*hclsyntax.LiteralValueExpr); ok {
+			continue
+		}
+		rng := part.Range()
+		if rng.Start.Line > lastLine {
+			break
+		}
+
+		// directives (%{ ... }) are represented as parts too,
+		// so only parts preceded by ${ are interpolations
+		offset := rng.Start.Byte - e.SrcRange.Start.Byte
+		if offset < 0 || offset > len(src) {
+			continue
+		}
+		prefix := bytes.TrimRight(src[:offset], " \t~")
+		if !bytes.HasSuffix(prefix, []byte("${")) {
+			continue
+		}
+
+		exprSrc, err := d.bytesFromRange(rng)
+		if err != nil {
+			continue
+		}
+		item := fmt.Sprintf("- `${%s}`", exprSrc)
+		if typ, ok := d.exprType(part); ok {
+			item += fmt.Sprintf(" _%s_", typ.FriendlyName())
+		}
+		interps = append(interps, item)
+	}
+	return interps
+}
@@ -0,0 +1,109 @@
+package decoder
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_HoverAtPos_heredocMarker(t *testing.T) {
+	longContent := make([]string, 12)
+	for i := range longContent {
+		longContent[i] = fmt.Sprintf("line %d", i+1)
+	}
+
+	testCases := []struct {
+		name          string
+		cfg           string
+		posAfter      string
+		expectedHover *lang.HoverData
+	}{
+		{
+			"interpolations",
+			`script = <<-EOT
+  echo ${var.name}
+  %{ if var.verbose }set -x%{ endif }
+  exit ${var.code}
+EOT
+`,
+			"script = <<-E",
+			&lang.HoverData{
+				Content: lang.Markdown("_heredoc_ `EOT`, 3 line(s)\n```\n" +
+					"  echo ${var.name}\n" +
+					"  %{ if var.verbose }set -x%{ endif }\n" +
+					"  exit ${var.code}\n" +
+					"```\n\nInterpolations:\n" +
+					"- `${var.name}` _string_\n" +
+					"- `${var.code}`"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+					End:      hcl.Pos{Line: 1, Column: 16, Byte: 15},
+				},
+			},
+		},
+		{
+			"long content",
+			"script = <<EOT\n" + strings.Join(longContent, "\n") + "\nEOT\n",
+			"script = <<",
+			&lang.HoverData{
+				Content: lang.Markdown("_heredoc_ `EOT`, 12 line(s)\n```\n" +
+					strings.Join(longContent[:10], "\n") + "\n…\n```"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+					End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"script": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.String),
+					},
+				},
+			})
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return lang.ReferenceTargets{
+					{
+						Addr: lang.Address{
+							lang.RootStep{Name: "var"},
+							lang.AttrStep{Name: "name"},
+						},
+						Type: cty.String,
+					},
+				}
+			})
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := d.HoverAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedHover, data); diff != "" {
+				t.Fatalf("unexpected hover data: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// heredocMarker is the identifier of heredocs
+// which are suggested or produced by fixes
+const heredocMarker = "EOT"
+
+// heredoc returns whether (and which style of) heredoc
+// is expected for string values accepted by the constraints
+func (ec ExprConstraints) heredoc() schema.Heredoc {
+	for _, c := range ec.byPrecedence() {
+		if lt, ok := c.(schema.LiteralTypeExpr); ok && lt.Type == cty.String {
+			return lt.Heredoc
+		}
+	}
+	return schema.HeredocOptional
+}
+
+func heredocStyleName(style schema.Heredoc) string {
+	switch style {
+	case schema.HeredocIndented:
+		return "an indented heredoc string (<<-EOT)"
+	case schema.HeredocFlush:
+		return "a heredoc string which is not indented (<<EOT)"
+	}
+	return "a heredoc string"
+}
+
+// validateHeredoc reports a string which is not a heredoc
+// or a heredoc of a different style than the constraints expect,
+// along with a fix to correct it, where the value can be preserved
+func (d *Decoder) validateHeredoc(name string, expr hclsyntax.Expression, ec ExprConstraints, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	expected := ec.heredoc()
+	if expected == schema.HeredocOptional {
+		return diags
+	}
+
+	var rng hcl.Range
+	switch e := expr.(type) {
+	case *hclsyntax.TemplateExpr:
+		rng = e.SrcRange
+	case *hclsyntax.TemplateWrapExpr:
+		rng = e.SrcRange
+	default:
+		return diags
+	}
+
+	src, err := d.bytesFromRange(rng)
+	if err != nil {
+		return diags
+	}
+
+	if !bytes.HasPrefix(src, []byte("<<")) {
+		diag := d.newDiagnostic(CodeHeredocExpected, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Heredoc expected",
+			Detail:   fmt.Sprintf("Value of %q is expected to be %s", name, heredocStyleName(expected)),
+			Subject:  rng.Ptr(),
+		})
+		diags = append(diags, diag)
+
+		if fixes != nil {
+			if fix, ok := d.heredocReplacementFix(expr, expected); ok {
+				fix.Diagnostic = diag
+				*fixes = append(*fixes, fix)
+			}
+		}
+		return diags
+	}
+
+	isIndented := bytes.HasPrefix(src, []byte("<<-"))
+	if (expected == schema.HeredocIndented && !isIndented) ||
+		(expected == schema.HeredocFlush && isIndented) {
+		diag := d.newDiagnostic(CodeUnexpectedHeredocStyle, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Unexpected heredoc style",
+			Detail:   fmt.Sprintf("Value of %q is expected to be %s", name, heredocStyleName(expected)),
+			Subject:  rng.Ptr(),
+		})
+		diags = append(diags, diag)
+
+		te, ok := expr.(*hclsyntax.TemplateExpr)
+		if fixes != nil && ok {
+			if fix, ok := heredocStyleFix(te, src, isIndented); ok {
+				fix.Diagnostic = diag
+				*fixes = append(*fixes, fix)
+			}
+		}
+	}
+
+	return diags
+}
+
+// heredocReplacementFix returns a fix which replaces a quoted string
+// (with no interpolation) by a heredoc of the given style
+func (d *Decoder) heredocReplacementFix(expr hclsyntax.Expression, style schema.Heredoc) (lang.DiagnosticFix, bool) {
+	if len(expr.Variables()) > 0 {
+		return lang.DiagnosticFix{}, false
+	}
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.String {
+		return lang.DiagnosticFix{}, false
+	}
+
+	content := strings.TrimSuffix(val.AsString(), "\n")
+	lines := strings.Split(content, "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) == heredocMarker {
+			return lang.DiagnosticFix{}, false
+		}
+	}
+
+	rng := expr.Range()
+	indent := d.lineIndentation(rng)
+
+	opening, contentIndent := "<<"+heredocMarker, ""
+	if style != schema.HeredocFlush {
+		opening, contentIndent = "<<-"+heredocMarker, indent+"  "
+	}
+
+	newText := opening + "\n"
+	for _, line := range lines {
+		if line != "" {
+			line = contentIndent + escapeTemplateSequences(line)
+		}
+		newText += line + "\n"
+	}
+	newText += indent + heredocMarker
+
+	// heredoc always ends with a newline
+	if !templateValueEquals(newText, content+"\n") {
+		return lang.DiagnosticFix{}, false
+	}
+
+	return lang.DiagnosticFix{
+		Title: "Replace quoted string with heredoc",
+		Edits: []lang.TextEdit{
+			{
+				Range:   rng,
+				NewText: newText,
+				Snippet: escapeSnippetText(newText),
+			},
+		},
+	}, true
+}
+
+// heredocStyleFix returns a fix which changes the style of the heredoc
+// by adding or removing the dash of its opening marker, provided
+// that the content remains the same
+func heredocStyleFix(te *hclsyntax.TemplateExpr, src []byte, isIndented bool) (lang.DiagnosticFix, bool) {
+	markerEnd := te.SrcRange.Start
+	markerEnd.Byte += 2
+	markerEnd.Column += 2
+
+	edit := lang.TextEdit{
+		Range: hcl.Range{
+			Filename: te.SrcRange.Filename,
+			Start:    markerEnd,
+			End:      markerEnd,
+		},
+		NewText: "-",
+		Snippet: "-",
+	}
+	title := "Use indented heredoc"
+	newSrc := append([]byte("<<-"), src[2:]...)
+	if isIndented {
+		edit.Range.End.Byte++
+		edit.Range.End.Column++
+		edit.NewText, edit.Snippet = "", ""
+		title = "Use heredoc which is not indented"
+		newSrc = append([]byte("<<"), src[3:]...)
+	}
+
+	// the closing marker must be followed by a newline
+	newExpr, diags := hclsyntax.ParseExpression(append(newSrc, '\n'), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return lang.DiagnosticFix{}, false
+	}
+	newTe, ok := newExpr.(*hclsyntax.TemplateExpr)
+	if !ok || !templateLiteralsEqual(te, newTe) {
+		return lang.DiagnosticFix{}, false
+	}
+
+	return lang.DiagnosticFix{
+		Title: title,
+		Edits: []lang.TextEdit{edit},
+	}, true
+}
+
+// templateLiteralsEqual returns true if both templates consist
+// of the same parts, where literal parts have equal values
+func templateLiteralsEqual(a, b *hclsyntax.TemplateExpr) bool {
+	if len(a.Parts) != len(b.Parts) {
+		return false
+	}
+	for i := range a.Parts {
+		aLit, aOk := a.Parts[i].(*hclsyntax.LiteralValueExpr)
+		bLit, bOk := b.Parts[i].(*hclsyntax.LiteralValueExpr)
+		if aOk != bOk {
+			return false
+		}
+		if aOk && !aLit.Val.RawEquals(bLit.Val) {
+			return false
+		}
+	}
+	return true
+}
+
+// templateValueEquals returns true if the given source
+// of a template evaluates to the given string
+func templateValueEquals(src, expected string) bool {
+	expr, diags := hclsyntax.ParseExpression([]byte(src+"\n"), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return false
+	}
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.String {
+		return false
+	}
+	return val.AsString() == expected
+}
+
+// escapeTemplateSequences escapes sequences which would otherwise
+// begin an interpolation or a directive within a template
+func escapeTemplateSequences(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
+
+// lineIndentation returns the leading whitespace
+// of the line where the given range starts
+func (d *Decoder) lineIndentation(rng hcl.Range) string {
+	f, err := d.fileByName(rng.Filename)
+	if err != nil || rng.Start.Byte > len(f.Bytes) {
+		return ""
+	}
+	lineStart := bytes.LastIndexByte(f.Bytes[:rng.Start.Byte], '\n') + 1
+	line := f.Bytes[lineStart:rng.Start.Byte]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// heredocCandidate returns a candidate inserting
+// an empty heredoc of the given style
+func heredocCandidate(lt schema.LiteralTypeExpr, editRng hcl.Range) lang.Candidate {
+	opening := heredocOpening(lt.Heredoc)
+	return lang.Candidate{
+		Label:       opening,
+		Detail:      lt.FriendlyName(),
+		Description: lang.PlainText("Multi-line string (heredoc)"),
+		Kind:        lang.StringCandidateKind,
+		TextEdit: lang.TextEdit{
+			NewText: newTextForHeredoc(lt.Heredoc, 0),
+			Snippet: snippetForHeredoc(1, lt.Heredoc, 0),
+			Range:   editRng,
+		},
+	}
+}
+
+func heredocOpening(style schema.Heredoc) string {
+	if style == schema.HeredocFlush {
+		return "<<" + heredocMarker
+	}
+	return "<<-" + heredocMarker
+}
+
+func newTextForHeredoc(style schema.Heredoc, nestingLvl int) string {
+	return fmt.Sprintf("%s\n\n%s%s", heredocOpening(style),
+		strings.Repeat("  ", nestingLvl), heredocMarker)
+}
+
+func snippetForHeredoc(placeholder uint, style schema.Heredoc, nestingLvl int) string {
+	contentIndent := ""
+	if style != schema.HeredocFlush {
+		contentIndent = strings.Repeat("  ", nestingLvl+1)
+	}
+	return fmt.Sprintf("%s\n%s${%d}\n%s%s", heredocOpening(style), contentIndent,
+		placeholder, strings.Repeat("  ", nestingLvl), heredocMarker)
+}
@@ -0,0 +1,355 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var heredocSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"script": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralTypeExpr{Type: cty.String, Heredoc: schema.HeredocIndented},
+			},
+		},
+		"policy": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralTypeExpr{Type: cty.String, Heredoc: schema.HeredocFlush},
+			},
+		},
+		"motd": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralTypeExpr{Type: cty.String, Heredoc: schema.HeredocRequired},
+			},
+		},
+		"any": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+		},
+	},
+	Blocks: map[string]*schema.BlockSchema{
+		"provisioner": {
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"script": {
+						IsRequired: true,
+						Expr: schema.ExprConstraints{
+							schema.LiteralTypeExpr{Type: cty.String, Heredoc: schema.HeredocIndented},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_ValidateFileWithFixes_heredoc(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		expectedSummary string
+		expectedFix     string
+		expectedCfg     string
+	}{
+		{
+			"indented heredoc",
+			`script = <<-EOT
+  echo hello
+  EOT
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"nested indented heredoc",
+			`provisioner {
+  script = <<-EOT
+    echo hello
+  EOT
+}
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"quoted string instead of heredoc",
+			`script = "echo $${HOME}\n\necho done\n"
+`,
+			"Heredoc expected",
+			"Replace quoted string with heredoc",
+			`script = <<-EOT
+  echo $${HOME}
+
+  echo done
+EOT
+`,
+		},
+		{
+			"quoted string instead of flush heredoc",
+			`policy = "  allow all"
+`,
+			"Heredoc expected",
+			"Replace quoted string with heredoc",
+			`policy = <<EOT
+  allow all
+EOT
+`,
+		},
+		{
+			"quoted string with interpolation",
+			`motd = "Hello ${var.name}"
+`,
+			"Heredoc expected",
+			"",
+			"",
+		},
+		{
+			"flush heredoc instead of indented",
+			`script = <<EOT
+echo hello
+EOT
+`,
+			"Unexpected heredoc style",
+			"Use indented heredoc",
+			`script = <<-EOT
+echo hello
+EOT
+`,
+		},
+		{
+			"flush heredoc with indented content",
+			`script = <<EOT
+  echo hello
+EOT
+`,
+			"Unexpected heredoc style",
+			"",
+			"",
+		},
+		{
+			"indented heredoc instead of flush",
+			`policy = <<-EOT
+allow ${var.who}
+EOT
+`,
+			"Unexpected heredoc style",
+			"Use heredoc which is not indented",
+			`policy = <<EOT
+allow ${var.who}
+EOT
+`,
+		},
+		{
+			"heredoc of either style",
+			`motd = <<EOT
+Hello
+EOT
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"heredoc not required",
+			`any = "foo"
+`,
+			"",
+			"",
+			"",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(heredocSchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expectedSummary == "" {
+				if len(diags) > 0 {
+					t.Fatalf("expected no diagnostics, given: %#v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, given: %#v", diags)
+			}
+			if diags[0].Severity != hcl.DiagWarning || diags[0].Summary != tc.expectedSummary {
+				t.Fatalf("unexpected diagnostic: %#v", diags[0])
+			}
+
+			diagFixes := fixes.ForDiagnostic(diags[0])
+			if tc.expectedFix == "" {
+				if len(diagFixes) > 0 {
+					t.Fatalf("expected no fixes, given: %#v", diagFixes)
+				}
+				return
+			}
+			if len(diagFixes) != 1 {
+				t.Fatalf("expected 1 fix, given: %#v", diagFixes)
+			}
+			if diagFixes[0].Title != tc.expectedFix {
+				t.Fatalf("unexpected fix: %q", diagFixes[0].Title)
+			}
+
+			fixedCfg := applyTextEdits(tc.cfg, diagFixes[0].Edits)
+			if fixedCfg != tc.expectedCfg {
+				t.Fatalf("unexpected config after fix:\n%s", fixedCfg)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_heredoc(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cfg                string
+		pos                hcl.Pos
+		expectedCandidates lang.Candidates
+	}{
+		{
+			"indented heredoc value",
+			"script = \n",
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:       "<<-EOT",
+					Detail:      "string",
+					Description: lang.PlainText("Multi-line string (heredoc)"),
+					Kind:        lang.StringCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "<<-EOT\n\nEOT",
+						Snippet: "<<-EOT\n  ${1}\nEOT",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+							End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						},
+					},
+				},
+			}),
+		},
+		{
+			"flush heredoc value",
+			"policy = \n",
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:       "<<EOT",
+					Detail:      "string",
+					Description: lang.PlainText("Multi-line string (heredoc)"),
+					Kind:        lang.StringCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "<<EOT\n\nEOT",
+						Snippet: "<<EOT\n${1}\nEOT",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+							End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						},
+					},
+				},
+			}),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(heredocSchema)
+
+			// configs with missing values are expected to be invalid
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, candidates); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_heredocAttribute(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		pos             hcl.Pos
+		label           string
+		expectedSnippet string
+	}{
+		{
+			"attribute",
+			"\n",
+			hcl.Pos{Line: 1, Column: 1, Byte: 0},
+			"script",
+			"script = <<-EOT\n  ${1}\nEOT",
+		},
+		{
+			"block with required attribute",
+			"\n",
+			hcl.Pos{Line: 1, Column: 1, Byte: 0},
+			"provisioner",
+			"provisioner {\n  script = <<-EOT\n    ${1}\n  EOT\n  ${2}\n}",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(heredocSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range candidates.List {
+				if c.Label != tc.label {
+					continue
+				}
+				if diff := cmp.Diff(tc.expectedSnippet, c.TextEdit.Snippet); diff != "" {
+					t.Fatalf("unexpected snippet: %s", diff)
+				}
+				return
+			}
+			t.Fatalf("candidate %q not found", tc.label)
+		})
+	}
+}
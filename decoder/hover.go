@@ -12,13 +12,12 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
-func (d *Decoder) HoverAtPos(filename string, pos hcl.Pos) (*lang.HoverData, error) {
-	f, err := d.fileByName(filename)
-	if err != nil {
-		return nil, err
-	}
+func (d *Decoder) HoverAtPos(filename string, pos hcl.Pos, opts ...RequestOption) (*lang.HoverData, error) {
+	d = d.withRequestOptions(opts)
+
+	pos = d.posForFile(filename, pos)
 
-	rootBody, err := d.bodyForFileAndPos(filename, f, pos)
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
 	if err != nil {
 		return nil, err
 	}
@@ -30,16 +29,16 @@ func (d *Decoder) HoverAtPos(filename string, pos hcl.Pos) (*lang.HoverData, err
 		return nil, &NoSchemaError{}
 	}
 
-	data, err := d.hoverAtPos(rootBody, d.rootSchema, pos)
+	data, err := d.hoverAtPos(rootBody, d.rootSchema, 0, pos)
 	if err != nil {
 		return nil, err
 	}
 
-	return data, nil
+	return d.hoverDataForClient(data), nil
 }
 
-func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema, pos hcl.Pos) (*lang.HoverData, error) {
-	if bodySchema == nil {
+func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema, nestingLvl int, pos hcl.Pos) (*lang.HoverData, error) {
+	if bodySchema == nil || d.isNestedTooDeep(nestingLvl) {
 		return nil, nil
 	}
 
@@ -47,21 +46,18 @@ func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema
 
 	for name, attr := range body.Attributes {
 		if attr.Range().ContainsPos(pos) {
-			aSchema, ok := bodySchema.Attributes[attr.Name]
+			aSchema, ok := attributeSchemaForName(bodySchema, attr.Name)
 			if !ok {
-				if bodySchema.AnyAttribute == nil {
-					return nil, &PositionalError{
-						Filename: filename,
-						Pos:      pos,
-						Msg:      fmt.Sprintf("unknown attribute %q", attr.Name),
-					}
+				return nil, &PositionalError{
+					Filename: filename,
+					Pos:      pos,
+					Msg:      fmt.Sprintf("unknown attribute %q", attr.Name),
 				}
-				aSchema = bodySchema.AnyAttribute
 			}
 
 			if attr.NameRange.ContainsPos(pos) {
 				return &lang.HoverData{
-					Content: hoverContentForAttribute(name, aSchema),
+					Content: d.hoverContentForAttribute(name, aSchema),
 					Range:   attr.Range(),
 				}, nil
 			}
@@ -74,6 +70,7 @@ func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema
 						Filename: filename,
 						Pos:      pos,
 						Msg:      err.Error(),
+						Err:      err,
 					}
 				}
 				return data, nil
@@ -83,7 +80,7 @@ func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema
 
 	for _, block := range body.Blocks {
 		if block.Range().ContainsPos(pos) {
-			bSchema, ok := bodySchema.Blocks[block.Type]
+			bSchema, ok := blockSchemaForType(bodySchema, block.Type)
 			if !ok {
 				return nil, &PositionalError{
 					Filename: filename,
@@ -130,7 +127,7 @@ func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema
 					return nil, err
 				}
 
-				return d.hoverAtPos(block.Body, mergedSchema, pos)
+				return d.hoverAtPos(block.Body, mergedSchema, nestingLvl+1, pos)
 			}
 		}
 	}
@@ -186,11 +183,15 @@ func (d *Decoder) hoverContentForLabel(i int, block *hclsyntax.Block, bSchema *s
 	return lang.Markdown(content)
 }
 
-func hoverContentForAttribute(name string, schema *schema.AttributeSchema) lang.MarkupContent {
+func (d *Decoder) hoverContentForAttribute(name string, schema *schema.AttributeSchema) lang.MarkupContent {
 	value := fmt.Sprintf("**%s** _%s_", name, detailForAttribute(schema))
 	if schema.Description.Value != "" {
 		value += fmt.Sprintf("\n\n%s", schema.Description.Value)
 	}
+	if content, ok := d.hoverContentForDefaultValue(schema); ok {
+		value += fmt.Sprintf("\n\n%s", content)
+	}
+	value += hoverContentForExamples(schema.Examples)
 	return lang.MarkupContent{
 		Kind:  lang.MarkdownKind,
 		Value: value,
@@ -211,6 +212,8 @@ func (d *Decoder) hoverContentForBlock(bType string, schema *schema.BlockSchema)
 		}
 	}
 
+	value += hoverContentForExamples(schema.Examples)
+
 	return lang.MarkupContent{
 		Kind:  lang.MarkdownKind,
 		Value: value,
@@ -218,6 +221,14 @@ func (d *Decoder) hoverContentForBlock(bType string, schema *schema.BlockSchema)
 }
 
 func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstraints, nestingLvl int, pos hcl.Pos) (*lang.HoverData, error) {
+	// hover the constraint which takes precedence where more
+	// than one matches, such that it is consistent with ConstraintAtPos
+	if hsExpr, ok := expr.(hclsyntax.Expression); ok {
+		if c, ok := constraints.constraintForExpr(hsExpr); ok {
+			constraints = ExprConstraints{c}
+		}
+	}
+
 	switch e := expr.(type) {
 	case *hclsyntax.ScopeTraversalExpr:
 		kw, ok := constraints.KeywordExpr()
@@ -236,6 +247,21 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 
 		te, ok := constraints.TraversalExpr()
 		if ok {
+			if traversal, rng, ok := indexStepTraversalAtPos(e.Traversal, pos); ok {
+				if len(traversal) < len(e.Traversal) {
+					// only the whole traversal is expected to conform to the type
+					te.OfType = cty.NilType
+				}
+				content, err := d.hoverContentForIndexStep(traversal, te)
+				if err != nil {
+					return nil, err
+				}
+				return &lang.HoverData{
+					Content: lang.Markdown(content),
+					Range:   rng,
+				}, nil
+			}
+
 			content, err := d.hoverContentForTraversalExpr(e.AsTraversal(), te)
 			if err != nil {
 				return nil, err
@@ -253,29 +279,75 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 				Range:   expr.Range(),
 			}, nil
 		}
+	case *hclsyntax.SplatExpr:
+		te, ok := constraints.TraversalExpr()
+		if ok {
+			if e.Source.Range().ContainsPos(pos) {
+				return d.hoverDataForExpr(e.Source, ExprConstraints{te}, nestingLvl, pos)
+			}
+			content, ok := d.hoverContentForRelativeTraversal(e)
+			if ok {
+				return &lang.HoverData{
+					Content: lang.Markdown(content),
+					Range:   expr.Range(),
+				}, nil
+			}
+		}
+	case *hclsyntax.RelativeTraversalExpr:
+		_, ok := constraints.TraversalExpr()
+		if ok && !e.Source.Range().ContainsPos(pos) {
+			content, ok := d.hoverContentForRelativeTraversal(e)
+			if ok {
+				return &lang.HoverData{
+					Content: lang.Markdown(content),
+					Range:   expr.Range(),
+				}, nil
+			}
+		}
 	case *hclsyntax.FunctionCallExpr:
 		_, ok := constraints.TypeDeclarationExpr()
 		if ok {
 			return &lang.HoverData{
 				Content: lang.Markdown("Type declaration"),
-				Range:   expr.Range(),
+				Range:   typeDeclarationRangeAtPos(e, pos),
+			}, nil
+		}
+		if sig, ok := d.functionSignature(e.Name); ok && e.NameRange.ContainsPos(pos) {
+			return &lang.HoverData{
+				Content: lang.Markdown(hoverContentForFunction(e.Name, sig)),
+				Range:   e.NameRange,
 			}, nil
 		}
 	case *hclsyntax.TemplateExpr:
+		if data, ok := d.hoverDataForHeredocMarker(e, pos); ok {
+			return data, nil
+		}
+		if se, ok := constraints.SizeExpr(); ok {
+			return &lang.HoverData{
+				Content: lang.Markdown(hoverContentForSize(se)),
+				Range:   expr.Range(),
+			}, nil
+		}
 		if e.IsStringLiteral() {
 			data, err := d.hoverDataForExpr(e.Parts[0], constraints, nestingLvl, pos)
 			if err != nil {
 				return nil, err
 			}
+			content := data.Content
+			if v, ok := stringValFromTemplateExpr(e); ok && nestingLvl == 0 {
+				if escContent := d.hoverContentForEscapes(e, v.AsString()); escContent != "" {
+					content.Value += "\n\n" + escContent
+				}
+			}
 			// Account for the enclosing quotes
 			return &lang.HoverData{
-				Content: data.Content,
+				Content: content,
 				Range:   expr.Range(),
 			}, nil
 		}
 		if v, ok := stringValFromTemplateExpr(e); ok {
 			if constraints.HasLiteralTypeOf(cty.String) {
-				content, err := hoverContentForValue(v, 0)
+				content, err := d.hoverContentForValue(v, 0)
 				if err != nil {
 					return nil, err
 				}
@@ -286,7 +358,7 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 			}
 			lv, ok := constraints.LiteralValueOf(v)
 			if ok {
-				content, err := hoverContentForValue(lv.Val, 0)
+				content, err := d.hoverContentForValue(lv.Val, 0)
 				if err != nil {
 					return nil, err
 				}
@@ -309,6 +381,13 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 	case *hclsyntax.TupleConsExpr:
 		tupleCons, ok := constraints.TupleConsExpr()
 		if ok {
+			if len(tupleCons.AnyElem) > 0 {
+				for _, elemExpr := range e.Exprs {
+					if elemExpr.Range().ContainsPos(pos) && !d.isNestedTooDeep(nestingLvl+1) {
+						return d.hoverDataForExpr(elemExpr, ExprConstraints(tupleCons.AnyElem), nestingLvl+1, pos)
+					}
+				}
+			}
 			content := fmt.Sprintf("_%s_", tupleCons.FriendlyName())
 			if tupleCons.Description.Value != "" {
 				content += "\n\n" + tupleCons.Description.Value
@@ -358,13 +437,25 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 						return nil, &ConstraintMismatch{elemExpr}
 					}
 					ec := ExprConstraints(te.Elems[i])
-					return d.hoverDataForExpr(elemExpr, ec, nestingLvl, pos)
+					data, err := d.hoverDataForExpr(elemExpr, ec, nestingLvl, pos)
+					if err != nil {
+						return nil, err
+					}
+					if elemName, ok := te.ElemName(i); ok && data != nil && data.Range == elemExpr.Range() {
+						// describe position of the element as a whole
+						data.Content.Value = fmt.Sprintf("**%s** (element %d of `%s`)\n\n%s",
+							elemName, i+1, labelForNamedTuple(te), data.Content.Value)
+					}
+					return data, nil
 				}
 			}
 			content := fmt.Sprintf("_%s_", te.FriendlyName())
 			if te.Description.Value != "" {
 				content += "\n\n" + te.Description.Value
 			}
+			if te.IsNamed() {
+				content = hoverContentForNamedTuple(te)
+			}
 			return &lang.HoverData{
 				Content: lang.Markdown(content),
 				Range:   expr.Range(),
@@ -372,6 +463,14 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 		}
 		lt, ok := constraints.LiteralTypeOfTupleExpr()
 		if ok {
+			for i, elemExpr := range e.Exprs {
+				if !elemExpr.Range().ContainsPos(pos) || d.isNestedTooDeep(nestingLvl+1) {
+					continue
+				}
+				if elemType, ok := literalElemType(lt.Type, i); ok {
+					return d.hoverDataForExpr(elemExpr, ExprConstraints(schema.LiteralTypeOnly(elemType)), nestingLvl+1, pos)
+				}
+			}
 			content, err := hoverContentForType(lt.Type, nestingLvl)
 			if err != nil {
 				return nil, err
@@ -383,7 +482,7 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 		}
 		lv, ok := constraints.LiteralValueOfTupleExpr(e)
 		if ok {
-			content, err := hoverContentForValue(lv.Val, nestingLvl)
+			content, err := d.hoverContentForValue(lv.Val, nestingLvl)
 			if err != nil {
 				return nil, err
 			}
@@ -399,6 +498,11 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 		}
 		mapExpr, ok := constraints.MapExpr()
 		if ok {
+			for _, item := range e.Items {
+				if item.ValueExpr.Range().ContainsPos(pos) && !d.isNestedTooDeep(nestingLvl+1) {
+					return d.hoverDataForExpr(item.ValueExpr, ExprConstraints(mapExpr.Elem), nestingLvl+1, pos)
+				}
+			}
 			content := mapExpr.FriendlyName()
 			if nestingLvl == 0 {
 				content = fmt.Sprintf("_%s_", mapExpr.FriendlyName())
@@ -413,6 +517,14 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 		}
 		lt, ok := constraints.LiteralTypeOfObjectConsExpr()
 		if ok {
+			for _, item := range e.Items {
+				if !item.ValueExpr.Range().ContainsPos(pos) || d.isNestedTooDeep(nestingLvl+1) {
+					continue
+				}
+				if attrType, ok := literalAttrType(lt.Type, item.KeyExpr); ok {
+					return d.hoverDataForExpr(item.ValueExpr, ExprConstraints(schema.LiteralTypeOnly(attrType)), nestingLvl+1, pos)
+				}
+			}
 			content, err := hoverContentForType(lt.Type, nestingLvl)
 			if err != nil {
 				return nil, err
@@ -424,7 +536,7 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 		}
 		litVal, ok := constraints.LiteralValueOfObjectConsExpr(e)
 		if ok {
-			content, err := hoverContentForValue(litVal.Val, nestingLvl)
+			content, err := d.hoverContentForValue(litVal.Val, nestingLvl)
 			if err != nil {
 				return nil, err
 			}
@@ -433,11 +545,17 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 				Range:   expr.Range(),
 			}, nil
 		}
+	case *hclsyntax.ForExpr:
+		for _, c := range constraints {
+			if forExprMatchesConstraint(c, e) {
+				return d.hoverDataForForExpr(e, c, nestingLvl, pos)
+			}
+		}
 	case *hclsyntax.LiteralValueExpr:
 		if constraints.HasLiteralTypeOf(e.Val.Type()) {
 			content := ""
 			if nestingLvl == 0 {
-				valContent, err := hoverContentForValue(e.Val, nestingLvl)
+				valContent, err := d.hoverContentForValue(e.Val, nestingLvl)
 				if err != nil {
 					return nil, err
 				}
@@ -456,7 +574,7 @@ func (d *Decoder) hoverDataForExpr(expr hcl.Expression, constraints ExprConstrai
 		}
 		lv, ok := constraints.LiteralValueOf(e.Val)
 		if ok {
-			content, err := hoverContentForValue(lv.Val, nestingLvl)
+			content, err := d.hoverContentForValue(lv.Val, nestingLvl)
 			if err != nil {
 				return nil, err
 			}
@@ -486,16 +604,16 @@ func (d *Decoder) hoverDataForObjectExpr(objExpr *hclsyntax.ObjectConsExpr, oe s
 			continue
 		}
 
-		if item.ValueExpr.Range().ContainsPos(pos) {
+		if item.ValueExpr.Range().ContainsPos(pos) && !d.isNestedTooDeep(nestingLvl+1) {
 			return d.hoverDataForExpr(item.ValueExpr, ExprConstraints(attr.Expr), nestingLvl+1, pos)
 		}
 
 		itemRng := hcl.RangeBetween(item.KeyExpr.Range(), item.ValueExpr.Range())
 		if itemRng.ContainsPos(pos) {
-			content := hoverContentForAttribute(key.AsString(), attr)
+			content := d.hoverContentForAttribute(key.AsString(), attr)
 			return &lang.HoverData{
 				Content: content,
-				Range:   itemRng,
+				Range:   item.KeyExpr.Range(),
 			}, nil
 		}
 
@@ -527,7 +645,7 @@ func (d *Decoder) hoverDataForObjectExpr(objExpr *hclsyntax.ObjectConsExpr, oe s
 		ec := oe.Attributes[name].Expr
 		attrData := ec.FriendlyName()
 
-		if attrExpr, ok := declaredAttributes[name]; ok {
+		if attrExpr, ok := declaredAttributes[name]; ok && !d.isNestedTooDeep(nestingLvl+1) {
 			data, err := d.hoverDataForExpr(attrExpr, ExprConstraints(ec), nestingLvl+1, pos)
 			if err == nil && data.Content.Value != "" {
 				attrData = data.Content.Value
@@ -550,6 +668,57 @@ func (d *Decoder) hoverDataForObjectExpr(objExpr *hclsyntax.ObjectConsExpr, oe s
 	}, nil
 }
 
+// typeDeclarationRangeAtPos returns range of the innermost type
+// at the given position, such as string in list(string)
+func typeDeclarationRangeAtPos(expr hcl.Expression, pos hcl.Pos) hcl.Range {
+	switch e := expr.(type) {
+	case *hclsyntax.FunctionCallExpr:
+		for _, arg := range e.Args {
+			if arg.Range().ContainsPos(pos) {
+				return typeDeclarationRangeAtPos(arg, pos)
+			}
+		}
+	case *hclsyntax.ObjectConsExpr:
+		for _, item := range e.Items {
+			if item.ValueExpr.Range().ContainsPos(pos) {
+				return typeDeclarationRangeAtPos(item.ValueExpr, pos)
+			}
+		}
+	}
+	return expr.Range()
+}
+
+// literalElemType returns type of the element at the given index
+// of a list, set or tuple type
+func literalElemType(collType cty.Type, idx int) (cty.Type, bool) {
+	if collType.IsListType() || collType.IsSetType() {
+		return collType.ElementType(), true
+	}
+	if collType.IsTupleType() {
+		elemTypes := collType.TupleElementTypes()
+		if idx < len(elemTypes) {
+			return elemTypes[idx], true
+		}
+	}
+	return cty.NilType, false
+}
+
+// literalAttrType returns type of the attribute with the given key
+// of an object or map type
+func literalAttrType(objType cty.Type, keyExpr hcl.Expression) (cty.Type, bool) {
+	if objType.IsMapType() {
+		return objType.ElementType(), true
+	}
+	key, _ := keyExpr.Value(nil)
+	if key.IsNull() || !key.IsWhollyKnown() || key.Type() != cty.String {
+		return cty.NilType, false
+	}
+	if objType.IsObjectType() && objType.HasAttribute(key.AsString()) {
+		return objType.AttributeType(key.AsString()), true
+	}
+	return cty.NilType, false
+}
+
 func sortedObjectExprAttrNames(attributes schema.ObjectExprAttributes) []string {
 	if len(attributes) == 0 {
 		return []string{}
@@ -596,39 +765,99 @@ func stringValFromTemplateExpr(tplExpr *hclsyntax.TemplateExpr) (cty.Value, bool
 }
 
 func (d *Decoder) hoverContentForTraversalExpr(traversal hcl.Traversal, te schema.TraversalExpr) (string, error) {
-	if d.refTargetReader == nil {
+	readTargets := d.referenceTargetReader()
+	if readTargets == nil {
 		return "", &NoRefTargetFound{}
 	}
 
-	allTargets := ReferenceTargets(d.refTargetReader())
+	allTargets := ReferenceTargets(readTargets())
 
 	origin, err := TraversalToReferenceOrigin(traversal, te)
 	if err != nil {
 		return "", nil
 	}
 
-	ref, err := allTargets.FirstTargetableBy(origin)
+	ref, err := d.referenceTargetForOrigin(allTargets, origin)
+	if err != nil {
+		// fall back to a type-less target matching just by scope,
+		// which provides at least the documentation
+		ref, err = allTargets.FirstScopeMatchFor(origin)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hoverContentForReferenceTarget(ref, d.addrFormat)
+}
+
+// indexStepTraversalAtPos returns the traversal up to (and including)
+// the index step at the given position, e.g. var.map["foo"] of
+// var.map["foo"].bar, along with the range of the index step
+func indexStepTraversalAtPos(traversal hcl.Traversal, pos hcl.Pos) (hcl.Traversal, hcl.Range, bool) {
+	for i, step := range traversal {
+		ti, ok := step.(hcl.TraverseIndex)
+		if !ok || !ti.SrcRange.ContainsPos(pos) {
+			continue
+		}
+		if !ti.Key.IsKnown() || ti.Key.IsNull() ||
+			(ti.Key.Type() != cty.String && ti.Key.Type() != cty.Number) {
+			return nil, hcl.Range{}, false
+		}
+		return traversal[:i+1], ti.SrcRange, true
+	}
+	return nil, hcl.Range{}, false
+}
+
+// hoverContentForIndexStep returns hover content for the element
+// of a collection addressed by the last (index) step of the traversal
+func (d *Decoder) hoverContentForIndexStep(traversal hcl.Traversal, te schema.TraversalExpr) (string, error) {
+	addr, err := lang.TraversalToAddress(traversal)
+	if err != nil {
+		return "", nil
+	}
+	key := addr[len(addr)-1].(lang.IndexStep).Key
+
+	content, err := d.hoverContentForTraversalExpr(traversal, te)
 	if err != nil {
 		return "", err
 	}
 
-	return hoverContentForReferenceTarget(ref)
+	collectionAddr := d.addrFormat.Format(addr[:len(addr)-1])
+	if key.Type() == cty.Number {
+		return content + fmt.Sprintf("\n\nElement of `%s` at index `%s`",
+			collectionAddr, d.numberFormat.Format(key)), nil
+	}
+	return content + fmt.Sprintf("\n\nElement of `%s` at key `%s`",
+		collectionAddr, d.addrFormat.Quote(key.AsString())), nil
 }
 
-func hoverContentForReferenceTarget(ref lang.ReferenceTarget) (string, error) {
-	content := fmt.Sprintf("`%s`", ref.Addr.String())
+func hoverContentForReferenceTarget(ref lang.ReferenceTarget, format lang.AddressFormat) (string, error) {
+	content := fmt.Sprintf("`%s`", format.Format(ref.Addr))
 
-	var friendlyName string
+	var typeContent string
 	if ref.Type != cty.NilType {
-		typeContent, err := hoverContentForType(ref.Type, 0)
+		tc, err := hoverContentForType(ref.Type, 0)
 		if err == nil {
-			friendlyName = "\n" + typeContent
+			typeContent = tc
 		}
 	}
-	if friendlyName == "" {
-		friendlyName = " " + ref.FriendlyName()
+
+	switch {
+	case typeContent != "" && ref.Name != "":
+		content += fmt.Sprintf(" %s\n%s", ref.Name, typeContent)
+	case typeContent != "":
+		content += "\n" + typeContent
+	default:
+		content += " " + ref.FriendlyName()
+	}
+
+	if ref.Sensitive {
+		content += "\n\n**Warning:** This value is sensitive and should not be exposed."
+	}
+
+	if ref.WriteOnly {
+		content += "\n\n**Note:** This value is write-only and can only be referenced from write-only attributes."
 	}
-	content += friendlyName
 
 	if ref.Description.Value != "" {
 		content += fmt.Sprintf("\n\n%s", ref.Description.Value)
@@ -637,7 +866,7 @@ func hoverContentForReferenceTarget(ref lang.ReferenceTarget) (string, error) {
 	return content, nil
 }
 
-func hoverContentForValue(val cty.Value, nestingLvl int) (string, error) {
+func (d *Decoder) hoverContentForValue(val cty.Value, nestingLvl int) (string, error) {
 	if !val.IsWhollyKnown() {
 		if nestingLvl > 0 {
 			return "", nil
@@ -660,7 +889,7 @@ func hoverContentForValue(val cty.Value, nestingLvl int) (string, error) {
 			}
 			value = fmt.Sprintf("%q", val.AsString())
 		case cty.Number:
-			value = formatNumberVal(val)
+			value = d.numberFormat.FormatReadable(val)
 		}
 
 		if nestingLvl > 0 {
@@ -682,7 +911,7 @@ func hoverContentForValue(val cty.Value, nestingLvl int) (string, error) {
 		value += "{\n"
 		for _, name := range attrNames {
 			whitespace := strings.Repeat("  ", nestingLvl+1)
-			val, err := hoverContentForValue(val.GetAttr(name), nestingLvl+1)
+			val, err := d.hoverContentForValue(val.GetAttr(name), nestingLvl+1)
 			if err == nil {
 				value += fmt.Sprintf("%s%s = %s\n",
 					whitespace, name, val)
@@ -709,7 +938,7 @@ func hoverContentForValue(val cty.Value, nestingLvl int) (string, error) {
 		mapKeys := sortedKeysOfValueMap(elems)
 		for _, key := range mapKeys {
 			val := elems[key]
-			elHover, err := hoverContentForValue(val, nestingLvl+1)
+			elHover, err := d.hoverContentForValue(val, nestingLvl+1)
 			if err == nil {
 				whitespace := strings.Repeat("  ", nestingLvl+1)
 				value += fmt.Sprintf("%s%q = %s\n",
@@ -737,7 +966,7 @@ func hoverContentForValue(val cty.Value, nestingLvl int) (string, error) {
 		value += "[\n"
 		for _, elem := range elems {
 			whitespace := strings.Repeat("  ", nestingLvl+1)
-			elHover, err := hoverContentForValue(elem, nestingLvl+1)
+			elHover, err := d.hoverContentForValue(elem, nestingLvl+1)
 			if err == nil {
 				value += fmt.Sprintf("%s%s,\n", whitespace, elHover)
 			}
@@ -3,6 +3,7 @@ package decoder
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -410,8 +411,8 @@ _object_`),
 					},
 					End: hcl.Pos{
 						Line:   3,
-						Column: 24,
-						Byte:   41,
+						Column: 15,
+						Byte:   32,
 					},
 				},
 			},
@@ -540,8 +541,8 @@ _object_`),
 					},
 					End: hcl.Pos{
 						Line:   3,
-						Column: 24,
-						Byte:   49,
+						Column: 15,
+						Byte:   40,
 					},
 				},
 			},
@@ -750,8 +751,8 @@ _object_`),
 					},
 					End: hcl.Pos{
 						Line:   2,
-						Column: 20,
-						Byte:   27,
+						Column: 11,
+						Byte:   18,
 					},
 				},
 			},
@@ -827,7 +828,7 @@ _object_`),
 				"mylist": {Expr: schema.LiteralTypeOnly(cty.List(cty.String))},
 			},
 			`mylist = [ "one", "two" ]`,
-			hcl.Pos{Line: 1, Column: 16, Byte: 15},
+			hcl.Pos{Line: 1, Column: 11, Byte: 10},
 			&lang.HoverData{
 				Content: lang.Markdown("_list of string_"),
 				Range: hcl.Range{
@@ -944,7 +945,7 @@ _object_`),
 				}},
 			},
 			`tuplecons = [ "one", "two" ]`,
-			hcl.Pos{Line: 1, Column: 18, Byte: 17},
+			hcl.Pos{Line: 1, Column: 14, Byte: 13},
 			&lang.HoverData{
 				Content: lang.Markdown("_special tuple_"),
 				Range: hcl.Range{
@@ -1282,7 +1283,7 @@ _object_`),
 			data, err := d.HoverAtPos("test.tf", tc.pos)
 
 			if err != nil {
-				if tc.expectedErr != nil && !errors.As(err, &tc.expectedErr) {
+				if tc.expectedErr != nil && !errors.As(err, newErrorTarget(tc.expectedErr)) {
 					t.Fatalf("unexpected error: %s\nexpected: %s\n",
 						err, tc.expectedErr)
 				} else if tc.expectedErr == nil {
@@ -1462,6 +1463,216 @@ func TestDecoder_HoverAtPos_traversalExpressions(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			"named and typed target with description",
+			map[string]*schema.AttributeSchema{
+				"attr": {
+					Expr: schema.ExprConstraints{
+						schema.TraversalExpr{OfType: cty.String},
+					},
+				},
+			},
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "foo"},
+					},
+					Type:        cty.String,
+					Name:        "variable",
+					Description: lang.Markdown("Name of the _instance_"),
+				},
+			},
+			`attr = var.foo`,
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			&lang.HoverData{
+				Content: lang.Markdown("`var.foo` variable\n_string_\n\nName of the _instance_"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start: hcl.Pos{
+						Line:   1,
+						Column: 8,
+						Byte:   7,
+					},
+					End: hcl.Pos{
+						Line:   1,
+						Column: 15,
+						Byte:   14,
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"typed traversal matching type-less target by scope",
+			map[string]*schema.AttributeSchema{
+				"attr": {
+					Expr: schema.ExprConstraints{
+						schema.TraversalExpr{
+							OfScopeId: lang.ScopeId("resource"),
+							OfType:    cty.String,
+						},
+					},
+				},
+			},
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "foo"},
+					},
+					ScopeId:     lang.ScopeId("resource"),
+					Name:        "resource",
+					Description: lang.Markdown("Instance resource"),
+				},
+			},
+			`attr = aws_instance.foo`,
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			&lang.HoverData{
+				Content: lang.Markdown("`aws_instance.foo` resource\n\nInstance resource"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start: hcl.Pos{
+						Line:   1,
+						Column: 8,
+						Byte:   7,
+					},
+					End: hcl.Pos{
+						Line:   1,
+						Column: 24,
+						Byte:   23,
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"index step with nested target",
+			map[string]*schema.AttributeSchema{
+				"attr": {
+					Expr: schema.ExprConstraints{
+						schema.TraversalExpr{OfType: cty.String},
+					},
+				},
+			},
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "map"},
+					},
+					Type: cty.Map(cty.String),
+					NestedTargets: lang.ReferenceTargets{
+						{
+							Addr: lang.Address{
+								lang.RootStep{Name: "var"},
+								lang.AttrStep{Name: "map"},
+								lang.IndexStep{Key: cty.StringVal("foo")},
+							},
+							Type:        cty.String,
+							Description: lang.Markdown("Foo value"),
+						},
+					},
+				},
+			},
+			`attr = var.map["foo"]`,
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			&lang.HoverData{
+				Content: lang.Markdown("`var.map[\"foo\"]`\n_string_\n\nFoo value" +
+					"\n\nElement of `var.map` at key `\"foo\"`"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start: hcl.Pos{
+						Line:   1,
+						Column: 15,
+						Byte:   14,
+					},
+					End: hcl.Pos{
+						Line:   1,
+						Column: 22,
+						Byte:   21,
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"index step with synthesized target",
+			map[string]*schema.AttributeSchema{
+				"attr": {
+					Expr: schema.ExprConstraints{
+						schema.TraversalExpr{OfType: cty.String},
+					},
+				},
+			},
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "list"},
+					},
+					Type: cty.List(cty.Map(cty.String)),
+				},
+			},
+			`attr = var.list[1].foo`,
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			&lang.HoverData{
+				Content: lang.Markdown("`var.list[1]`\n_map of string_" +
+					"\n\nElement of `var.list` at index `1`"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start: hcl.Pos{
+						Line:   1,
+						Column: 16,
+						Byte:   15,
+					},
+					End: hcl.Pos{
+						Line:   1,
+						Column: 19,
+						Byte:   18,
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"root of indexed traversal",
+			map[string]*schema.AttributeSchema{
+				"attr": {
+					Expr: schema.ExprConstraints{
+						schema.TraversalExpr{OfType: cty.String},
+					},
+				},
+			},
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "list"},
+					},
+					Type: cty.List(cty.Map(cty.String)),
+				},
+			},
+			`attr = var.list[1].foo`,
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			&lang.HoverData{
+				Content: lang.Markdown("`var.list[1].foo`\n_string_"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start: hcl.Pos{
+						Line:   1,
+						Column: 8,
+						Byte:   7,
+					},
+					End: hcl.Pos{
+						Line:   1,
+						Column: 23,
+						Byte:   22,
+					},
+				},
+			},
+			nil,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -1482,7 +1693,7 @@ func TestDecoder_HoverAtPos_traversalExpressions(t *testing.T) {
 
 			data, err := d.HoverAtPos("test.tf", tc.pos)
 			if err != nil {
-				if tc.expectedErr != nil && !errors.As(err, &tc.expectedErr) {
+				if tc.expectedErr != nil && !errors.As(err, newErrorTarget(tc.expectedErr)) {
 					t.Fatalf("unexpected error: %s\nexpected: %s\n",
 						err, tc.expectedErr)
 				} else if tc.expectedErr == nil {
@@ -1498,3 +1709,166 @@ func TestDecoder_HoverAtPos_traversalExpressions(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoder_HoverAtPos_nestedExpressionRanges(t *testing.T) {
+	testCases := []struct {
+		name            string
+		attrSchema      map[string]*schema.AttributeSchema
+		cfg             string
+		hoverAfter      string
+		expectedContent string
+		expectedRange   string
+	}{
+		{
+			"object expression key",
+			map[string]*schema.AttributeSchema{
+				"obj": {Expr: schema.ExprConstraints{
+					schema.ObjectExpr{
+						Attributes: schema.ObjectExprAttributes{
+							"source": {Expr: schema.LiteralTypeOnly(cty.String)},
+						},
+					},
+				}},
+			},
+			`obj = { source = "blah" }`,
+			"sou",
+			"**source** _string_",
+			"source",
+		},
+		{
+			"list expression element",
+			map[string]*schema.AttributeSchema{
+				"list": {Expr: schema.ExprConstraints{
+					schema.ListExpr{Elem: schema.LiteralTypeOnly(cty.Number)},
+				}},
+			},
+			`list = [ 1, 42 ]`,
+			"4",
+			"`42` _number_",
+			"42",
+		},
+		{
+			"tuple constant expression element",
+			map[string]*schema.AttributeSchema{
+				"tuplecons": {Expr: schema.ExprConstraints{
+					schema.TupleConsExpr{AnyElem: schema.LiteralTypeOnly(cty.Number)},
+				}},
+			},
+			`tuplecons = [ 1, 42 ]`,
+			"4",
+			"number",
+			"42",
+		},
+		{
+			"list type element",
+			map[string]*schema.AttributeSchema{
+				"list": {Expr: schema.LiteralTypeOnly(cty.List(cty.String))},
+			},
+			`list = [ "one", "two" ]`,
+			`"tw`,
+			"string",
+			`"two"`,
+		},
+		{
+			"tuple type element",
+			map[string]*schema.AttributeSchema{
+				"tuple": {Expr: schema.LiteralTypeOnly(cty.Tuple([]cty.Type{
+					cty.String, cty.Number,
+				}))},
+			},
+			`tuple = [ "one", 42 ]`,
+			"4",
+			"number",
+			"42",
+		},
+		{
+			"map expression value",
+			map[string]*schema.AttributeSchema{
+				"map": {Expr: schema.ExprConstraints{
+					schema.MapExpr{Elem: schema.LiteralTypeOnly(cty.Number)},
+				}},
+			},
+			`map = { key = 42 }`,
+			"4",
+			"number",
+			"42",
+		},
+		{
+			"map type value",
+			map[string]*schema.AttributeSchema{
+				"map": {Expr: schema.LiteralTypeOnly(cty.Map(cty.Bool))},
+			},
+			`map = { key = true }`,
+			"tr",
+			"bool",
+			"true",
+		},
+		{
+			"object type value",
+			map[string]*schema.AttributeSchema{
+				"obj": {Expr: schema.LiteralTypeOnly(cty.Object(map[string]cty.Type{
+					"foo": cty.String,
+					"bar": cty.Number,
+				}))},
+			},
+			`obj = { foo = "x", bar = 42 }`,
+			"4",
+			"number",
+			"42",
+		},
+		{
+			"type declaration function argument",
+			map[string]*schema.AttributeSchema{
+				"type": {Expr: schema.ExprConstraints{schema.TypeDeclarationExpr{}}},
+			},
+			`type = map(list(string))`,
+			"str",
+			"Type declaration",
+			"string",
+		},
+		{
+			"type declaration object attribute",
+			map[string]*schema.AttributeSchema{
+				"type": {Expr: schema.ExprConstraints{schema.TypeDeclarationExpr{}}},
+			},
+			`type = object({ foo = list(number) })`,
+			"li",
+			"Type declaration",
+			"list(number)",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: tc.attrSchema,
+			})
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := d.HoverAtPos("test.tf", posInCfg(t, tc.cfg, tc.hoverAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedContent, data.Content.Value); diff != "" {
+				t.Fatalf("unexpected content: %s", diff)
+			}
+			rangeText := string(data.Range.SliceBytes([]byte(tc.cfg)))
+			if diff := cmp.Diff(tc.expectedRange, rangeText); diff != "" {
+				t.Fatalf("unexpected range: %s", diff)
+			}
+		})
+	}
+}
+
+// newErrorTarget returns a target for errors.As
+// matching errors of the same type as the given error
+func newErrorTarget(err error) interface{} {
+	return reflect.New(reflect.TypeOf(err)).Interface()
+}
@@ -845,8 +845,8 @@ func TestDecoder_HoverAtPos_typeDeclaration(t *testing.T) {
 				Content: lang.Markdown("Type declaration"),
 				Range: hcl.Range{
 					Filename: "test.tf",
-					Start:    hcl.Pos{Line: 2, Column: 10, Byte: 27},
-					End:      hcl.Pos{Line: 2, Column: 22, Byte: 39},
+					Start:    hcl.Pos{Line: 2, Column: 15, Byte: 32},
+					End:      hcl.Pos{Line: 2, Column: 21, Byte: 38},
 				},
 			},
 		},
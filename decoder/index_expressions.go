@@ -0,0 +1,205 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// indexRole describes the role of a reference
+// within an index expression with a variable key,
+// such as var.map[var.key]
+type indexRole uint
+
+const (
+	indexRoleNone indexRole = iota
+
+	// indexRoleCollection represents the indexed collection (var.map)
+	indexRoleCollection
+
+	// indexRoleKey represents a reference within the key (var.key)
+	indexRoleKey
+)
+
+// indexRolesInExpr returns roles of traversals (keyed by their range)
+// within index expressions with variable keys. Traversals with literal
+// keys (var.map["key"]) are not index expressions and have no role.
+func indexRolesInExpr(expr hcl.Expression) map[hcl.Range]indexRole {
+	roles := make(map[hcl.Range]indexRole, 0)
+
+	hsExpr, ok := expr.(hclsyntax.Expression)
+	if !ok {
+		return roles
+	}
+
+	keyRanges := make([]hcl.Range, 0)
+	hclsyntax.VisitAll(hsExpr, func(node hclsyntax.Node) hcl.Diagnostics {
+		ie, ok := node.(*hclsyntax.IndexExpr)
+		if !ok {
+			return nil
+		}
+		if ste, ok := ie.Collection.(*hclsyntax.ScopeTraversalExpr); ok {
+			roles[ste.Range()] = indexRoleCollection
+		}
+		for _, traversal := range ie.Key.Variables() {
+			keyRanges = append(keyRanges, traversal.SourceRange())
+		}
+		return nil
+	})
+
+	// references within keys are keys, even if they
+	// are also indexed collections (var.a[var.b[var.c]])
+	for _, rng := range keyRanges {
+		roles[rng] = indexRoleKey
+	}
+
+	return roles
+}
+
+// originForConstraint returns origin of the reference
+// which is expected to conform to the given constraint
+func (ref exprReference) originForConstraint(te schema.TraversalExpr) lang.ReferenceOrigin {
+	origin := lang.ReferenceOrigin{
+		Addr:       ref.Addr,
+		Range:      ref.Range,
+		OfScopeId:  te.OfScopeId,
+		OfScopeIds: te.OfScopeIds,
+		OfType:     te.OfType,
+	}
+
+	switch ref.IndexRole {
+	case indexRoleCollection:
+		// only the element is expected to be of the type,
+		// the collection itself can be of any (collection) type
+		origin.OfType = cty.NilType
+	case indexRoleKey:
+		// the key is unrelated to the constraint
+		origin.OfScopeId = ""
+		origin.OfScopeIds = nil
+		origin.OfType = cty.NilType
+	}
+
+	return origin
+}
+
+// indexExprWithKeyAtPos returns the innermost index expression
+// with a variable key at the given position
+func indexExprWithKeyAtPos(expr hclsyntax.Expression, pos hcl.Pos) (*hclsyntax.IndexExpr, bool) {
+	var found *hclsyntax.IndexExpr
+	hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		ie, ok := node.(*hclsyntax.IndexExpr)
+		if !ok {
+			return nil
+		}
+		keyRng := ie.Key.Range()
+		if keyRng.ContainsPos(pos) || keyRng.End.Byte == pos.Byte {
+			// nodes are visited outside-in, so the last match is innermost
+			found = ie
+		}
+		return nil
+	})
+	return found, found != nil
+}
+
+// indexKeyType returns type of keys of the collection,
+// i.e. string for maps and objects and number for lists and tuples,
+// or cty.DynamicPseudoType if the collection type is not known
+func (d *Decoder) indexKeyType(collection hclsyntax.Expression) cty.Type {
+	ste, ok := collection.(*hclsyntax.ScopeTraversalExpr)
+	readTargets := d.referenceTargetReader()
+	if !ok || readTargets == nil {
+		return cty.DynamicPseudoType
+	}
+	addr, err := lang.TraversalToAddress(ste.Traversal)
+	if err != nil {
+		return cty.DynamicPseudoType
+	}
+
+	keyType := cty.DynamicPseudoType
+	ReferenceTargets(readTargets()).DeepWalk(func(target lang.ReferenceTarget) error {
+		if !Address(target.Addr).Equals(Address(addr)) || target.Type == cty.NilType {
+			return nil
+		}
+		switch {
+		case target.Type.IsMapType(), target.Type.IsObjectType():
+			keyType = cty.String
+		case target.Type.IsListType(), target.Type.IsTupleType():
+			keyType = cty.Number
+		}
+		return StopWalking
+	})
+
+	return keyType
+}
+
+// indexKeyCandidatesAtPos returns candidates for references within
+// the key of the index expression, i.e. references to targets
+// of a type convertible to the key type of the collection
+func (d *Decoder) indexKeyCandidatesAtPos(ie *hclsyntax.IndexExpr, outerBodyRng hcl.Range, pos hcl.Pos) lang.Candidates {
+	candidates := lang.NewCandidates()
+	candidates.IsComplete = true
+
+	readTargets := d.referenceTargetReader()
+	if readTargets == nil {
+		return candidates
+	}
+
+	editRng := ie.Key.Range()
+	if _, ok := ie.Key.(*hclsyntax.ScopeTraversalExpr); !ok {
+		editRng = hcl.Range{
+			Filename: editRng.Filename,
+			Start:    pos,
+			End:      pos,
+		}
+	}
+	prefixRng := editRng
+	prefixRng.End = pos
+	prefix := string(d.prefixFromRange(prefixRng))
+
+	match := d.referenceMatch(prefix)
+
+	keyType := d.indexKeyType(ie.Collection)
+
+	ReferenceTargets(readTargets()).DeepWalk(func(ref lang.ReferenceTarget) error {
+		if !isConvertibleToKeyType(ref.Type, keyType) {
+			return nil
+		}
+		// avoid suggesting references to block's own fields from within (for now)
+		if ref.RangePtr != nil &&
+			(outerBodyRng.ContainsPos(ref.RangePtr.Start) ||
+				posEqual(outerBodyRng.End, ref.RangePtr.End)) {
+			return nil
+		}
+
+		if !match(ref.Addr) {
+			return nil
+		}
+		candidates.List = append(candidates.List, d.referenceTargetCandidate(ref, prefixRng, editRng))
+		return nil
+	})
+
+	candidates.Sort()
+
+	return candidates
+}
+
+// isConvertibleToKeyType returns true if a value of the given type
+// can be used as key of a collection with the given key type
+func isConvertibleToKeyType(typ, keyType cty.Type) bool {
+	if typ == cty.NilType {
+		return false
+	}
+	if typ == cty.DynamicPseudoType {
+		return true
+	}
+	if !typ.IsPrimitiveType() {
+		return false
+	}
+	if keyType == cty.DynamicPseudoType {
+		return true
+	}
+	return typ.Equals(keyType) || convert.GetConversion(typ, keyType) != nil
+}
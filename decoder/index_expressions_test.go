@@ -0,0 +1,175 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var indexExprSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"attr": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.TraversalExpr{OfScopeId: lang.ScopeId("variable"), OfType: cty.String},
+			},
+		},
+	},
+}
+
+var indexExprTargets = lang.ReferenceTargets{
+	{
+		Addr:    lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "key"}},
+		ScopeId: lang.ScopeId("variable"),
+		Type:    cty.String,
+	},
+	{
+		Addr:    lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "list"}},
+		ScopeId: lang.ScopeId("variable"),
+		Type:    cty.List(cty.String),
+	},
+	{
+		Addr:    lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "map"}},
+		ScopeId: lang.ScopeId("variable"),
+		Type:    cty.Map(cty.String),
+	},
+	{
+		Addr:    lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "num"}},
+		ScopeId: lang.ScopeId("variable"),
+		Type:    cty.Number,
+	},
+	{
+		Addr:    lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "obj"}},
+		ScopeId: lang.ScopeId("variable"),
+		Type:    cty.Object(map[string]cty.Type{"foo": cty.String}),
+	},
+}
+
+func TestCollectReferenceOrigins_indexExpr(t *testing.T) {
+	d := newTestDecoder(t, indexExprSchema, map[string]string{"test.tf": "attr = var.map[var.list[var.num]]\n"})
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return indexExprTargets
+	})
+
+	origins, err := d.CollectReferenceOrigins()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedOrigins := lang.ReferenceOrigins{
+		{
+			Addr: lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "map"}},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+				End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+			},
+			OfScopeId: lang.ScopeId("variable"),
+		},
+		{
+			Addr: lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "list"}},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 16, Byte: 15},
+				End:      hcl.Pos{Line: 1, Column: 24, Byte: 23},
+			},
+		},
+		{
+			Addr: lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "num"}},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 25, Byte: 24},
+				End:      hcl.Pos{Line: 1, Column: 32, Byte: 31},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedOrigins, origins, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected origins: %s", diff)
+	}
+
+	for _, origin := range origins {
+		targets := ReferenceTargets(indexExprTargets)
+		found := false
+		targets.DeepWalk(func(target lang.ReferenceTarget) error {
+			if ReferenceTarget(target).IsTargetableBy(origin) {
+				found = true
+				return StopWalking
+			}
+			return nil
+		})
+		if !found {
+			t.Fatalf("expected origin %s to target a variable", origin.Addr)
+		}
+	}
+}
+
+func TestDecoder_ValidateFile_indexExpr(t *testing.T) {
+	d := newTestDecoder(t, indexExprSchema, map[string]string{"test.tf": "attr = var.map[var.num]\n"})
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return indexExprTargets
+	})
+
+	diags, err := d.ValidateFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, given: %#v", diags)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_indexKey(t *testing.T) {
+	testCases := []struct {
+		name           string
+		cfg            string
+		pos            hcl.Pos
+		expectedLabels []string
+	}{
+		{
+			"map key",
+			"attr = var.map[var]\n",
+			hcl.Pos{Line: 1, Column: 19, Byte: 18},
+			[]string{"var.key", "var.num"},
+		},
+		{
+			"list index",
+			"attr = var.list[var.n]\n",
+			hcl.Pos{Line: 1, Column: 22, Byte: 21},
+			[]string{"var.num"},
+		},
+		{
+			"unknown collection",
+			"attr = var.unknown[var]\n",
+			hcl.Pos{Line: 1, Column: 23, Byte: 22},
+			[]string{"var.key", "var.num"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, indexExprSchema, map[string]string{"test.tf": tc.cfg})
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return indexExprTargets
+			})
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package decoder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// maxInlineValueLength is the number of characters
+// after which the text of inline values is shortened
+const maxInlineValueLength = 60
+
+// InlineValuesInRange returns statically known values of references
+// within the given range of the file, e.g. for editors to preview
+// what the references evaluate to.
+//
+// A value is known where the targeted attribute (or an item of an object)
+// is set to an expression which can be evaluated without any context,
+// such as a literal. Values of sensitive and write-only targets
+// are never returned.
+func (d *Decoder) InlineValuesInRange(ctx context.Context, filename string, rng hcl.Range, opts ...RequestOption) ([]lang.InlineValue, error) {
+	d = d.withRequestOptions(opts)
+
+	values := make([]lang.InlineValue, 0)
+
+	origins, err := d.referenceOrigins()
+	if err != nil {
+		return nil, err
+	}
+	targets, err := d.referenceTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	type knownValue struct {
+		val cty.Value
+		ok  bool
+	}
+
+	vf := d.valueFormat()
+	known := make(map[hcl.Range]knownValue, 0)
+
+	var iterErr error
+	origins.IterInFile(filename, func(origin lang.ReferenceOrigin) bool {
+		if err := ctx.Err(); err != nil {
+			iterErr = err
+			return false
+		}
+		if origin.IsSynthetic || !origin.Range.Overlaps(rng) {
+			return true
+		}
+
+		target, err := ReferenceTargets(targets).FirstTargetableBy(origin)
+		if err != nil || target.RangePtr == nil || target.Sensitive || target.WriteOnly {
+			return true
+		}
+
+		kv, ok := known[*target.RangePtr]
+		if !ok {
+			val, ok := d.staticValueOfTarget(*target.RangePtr)
+			kv = knownValue{val: val, ok: ok}
+			known[*target.RangePtr] = kv
+		}
+		if !kv.ok {
+			return true
+		}
+
+		values = append(values, lang.InlineValue{
+			Range: origin.Range,
+			Value: kv.val,
+			Text:  shortenInlineText(inlineTextForValue(kv.val, vf)),
+		})
+		return true
+	})
+	if iterErr != nil {
+		return nil, iterErr
+	}
+
+	return values, nil
+}
+
+// staticValueOfTarget returns the value of the expression declared
+// in the given target range, provided it can be evaluated without context
+func (d *Decoder) staticValueOfTarget(rng hcl.Range) (cty.Value, bool) {
+	body, err := d.bodyForFileAndPos(rng.Filename, rng.Start)
+	if err != nil {
+		return cty.NilVal, false
+	}
+
+	expr, ok := exprForTargetRange(body, rng)
+	if !ok || len(expr.Variables()) > 0 {
+		return cty.NilVal, false
+	}
+
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return cty.NilVal, false
+	}
+	return val, true
+}
+
+// exprForTargetRange returns the expression of an attribute, or of
+// an element or item nested within it, whose range is the given range
+func exprForTargetRange(body *hclsyntax.Body, rng hcl.Range) (hclsyntax.Expression, bool) {
+	for _, attr := range body.Attributes {
+		if rangesEqual(attr.SrcRange, rng) {
+			return attr.Expr, true
+		}
+		if attr.SrcRange.Overlaps(rng) {
+			return exprForNestedTargetRange(attr.Expr, rng)
+		}
+	}
+	for _, block := range body.Blocks {
+		if block.Body != nil && block.Body.Range().Overlaps(rng) {
+			return exprForTargetRange(block.Body, rng)
+		}
+	}
+	return nil, false
+}
+
+// exprForNestedTargetRange returns the expression of an element
+// or an item within the expression whose range is the given range
+func exprForNestedTargetRange(expr hclsyntax.Expression, rng hcl.Range) (hclsyntax.Expression, bool) {
+	switch e := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		for _, elemExpr := range e.Exprs {
+			if rangesEqual(elemExpr.Range(), rng) {
+				return elemExpr, true
+			}
+			if elemExpr.Range().Overlaps(rng) {
+				return exprForNestedTargetRange(elemExpr, rng)
+			}
+		}
+	case *hclsyntax.ObjectConsExpr:
+		for _, item := range e.Items {
+			itemRng := hcl.RangeBetween(item.KeyExpr.Range(), item.ValueExpr.Range())
+			if rangesEqual(itemRng, rng) {
+				return item.ValueExpr, true
+			}
+			if item.ValueExpr.Range().Overlaps(rng) {
+				return exprForNestedTargetRange(item.ValueExpr, rng)
+			}
+		}
+	}
+	return nil, false
+}
+
+func rangesEqual(a, b hcl.Range) bool {
+	return a.Filename == b.Filename &&
+		a.Start.Byte == b.Start.Byte &&
+		a.End.Byte == b.End.Byte
+}
+
+// inlineTextForValue renders the value on a single line
+func inlineTextForValue(val cty.Value, vf valueFormat) string {
+	typ := val.Type()
+
+	switch {
+	case typ == cty.String:
+		return fmt.Sprintf("%q", val.AsString())
+	case typ == cty.Bool:
+		return fmt.Sprintf("%t", val.True())
+	case typ == cty.Number:
+		return vf.number.Format(val)
+	case typ.IsListType() || typ.IsSetType() || typ.IsTupleType():
+		elems := make([]string, 0, val.LengthInt())
+		for _, elem := range val.AsValueSlice() {
+			elems = append(elems, inlineTextForValue(elem, vf))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case typ.IsMapType():
+		valueMap := val.AsValueMap()
+		items := make([]string, 0, len(valueMap))
+		for _, key := range sortedKeysOfValueMap(valueMap) {
+			items = append(items, fmt.Sprintf("%q %s %s",
+				key, vf.objectItemSep, inlineTextForValue(valueMap[key], vf)))
+		}
+		return objectInlineText(items)
+	case typ.IsObjectType():
+		items := make([]string, 0)
+		for _, name := range sortedObjectAttrNames(typ) {
+			items = append(items, fmt.Sprintf("%s %s %s",
+				name, vf.objectItemSep, inlineTextForValue(val.GetAttr(name), vf)))
+		}
+		return objectInlineText(items)
+	}
+
+	return ""
+}
+
+func objectInlineText(items []string) string {
+	if len(items) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(items, ", ") + " }"
+}
+
+func shortenInlineText(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxInlineValueLength {
+		return text
+	}
+	return string(runes[:maxInlineValueLength-1]) + "…"
+}
@@ -0,0 +1,194 @@
+package decoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var inlineValuesSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"locals": {
+			Body: &schema.BodySchema{
+				AnyAttribute: &schema.AttributeSchema{
+					IsOptional: true,
+					Expr: schema.ExprConstraints{
+						schema.TraversalExpr{OfType: cty.DynamicPseudoType},
+						schema.LiteralTypeExpr{Type: cty.DynamicPseudoType},
+					},
+					Address: &schema.AttributeAddrSchema{
+						Steps: []schema.AddrStep{
+							schema.StaticStep{Name: "local"},
+							schema.AttrNameStep{},
+						},
+						AsExprType: true,
+					},
+				},
+			},
+		},
+		"output": {
+			Labels: []*schema.LabelSchema{
+				{Name: "name"},
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"value": {
+						IsOptional: true,
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfType: cty.DynamicPseudoType},
+						},
+					},
+				},
+			},
+		},
+	},
+	Attributes: map[string]*schema.AttributeSchema{
+		"password": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+			Address: &schema.AttributeAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.StaticStep{Name: "password"},
+				},
+				AsExprType: true,
+				Sensitive:  true,
+			},
+		},
+	},
+}
+
+const inlineValuesCfg = `locals {
+  name     = "foo"
+  tags     = { env = "dev" }
+  computed = "${local.name}-bar"
+}
+password = "secret"
+output "a" {
+  value = local.name
+}
+output "b" {
+  value = local.tags.env
+}
+output "c" {
+  value = local.computed
+}
+output "d" {
+  value = password
+}
+output "e" {
+  value = local.tags
+}
+`
+
+func TestDecoder_InlineValuesInRange(t *testing.T) {
+	testCases := []struct {
+		name           string
+		startAfter     string
+		endAfter       string
+		expectedValues map[string]string
+	}{
+		{
+			"whole file",
+			"",
+			"",
+			map[string]string{
+				"local.name":     `"foo"`,
+				"local.tags.env": `"dev"`,
+				"local.tags":     `{ env = "dev" }`,
+			},
+		},
+		{
+			"part of file",
+			`output "a"`,
+			`output "c"`,
+			map[string]string{
+				"local.name":     `"foo"`,
+				"local.tags.env": `"dev"`,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(inlineValuesSchema)
+			f, _ := hclsyntax.ParseConfig([]byte(inlineValuesCfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rng := f.Body.(*hclsyntax.Body).SrcRange
+			if tc.startAfter != "" {
+				rng.Start = posInCfg(t, inlineValuesCfg, tc.startAfter)
+			}
+			if tc.endAfter != "" {
+				rng.End = posInCfg(t, inlineValuesCfg, tc.endAfter)
+			}
+
+			values, err := d.InlineValuesInRange(context.Background(), "test.tf", rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			texts := make(map[string]string, 0)
+			for _, v := range values {
+				addr := string(v.Range.SliceBytes([]byte(inlineValuesCfg)))
+				texts[addr] = v.Text
+			}
+			if diff := cmp.Diff(tc.expectedValues, texts); diff != "" {
+				t.Fatalf("unexpected values: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_InlineValuesInRange_cancelled(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(inlineValuesSchema)
+	f, _ := hclsyntax.ParseConfig([]byte(inlineValuesCfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = d.InlineValuesInRange(ctx, "test.tf", f.Body.(*hclsyntax.Body).SrcRange)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, given: %v", err)
+	}
+}
+
+func TestInlineTextForValue(t *testing.T) {
+	testCases := []struct {
+		val          cty.Value
+		expectedText string
+	}{
+		{cty.StringVal("foo"), `"foo"`},
+		{cty.NumberIntVal(42), "42"},
+		{cty.True, "true"},
+		{cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}), `["a", "b"]`},
+		{cty.MapVal(map[string]cty.Value{"b": cty.True, "a": cty.False}), `{ "a" = false, "b" = true }`},
+		{cty.EmptyObjectVal, "{}"},
+		{cty.StringVal(strings.Repeat("a", 70)), `"` + strings.Repeat("a", 58) + "…"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			text := shortenInlineText(inlineTextForValue(tc.val, valueFormat{}))
+			if text != tc.expectedText {
+				t.Fatalf("expected %q, given %q", tc.expectedText, text)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package decoder
 
 import (
-	"encoding/json"
 	"sort"
 	"strings"
 
@@ -10,16 +9,48 @@ import (
 	"github.com/hashicorp/hcl/v2"
 )
 
-func (d *Decoder) labelCandidatesFromDependentSchema(idx int, db map[schema.SchemaKey]*schema.BodySchema, prefixRng, editRng hcl.Range) (lang.Candidates, error) {
+// labelCandidates returns candidates for the label at the given index,
+// i.e. values enumerated by the label's ValueConstraint and,
+// if the label is Completable, values of dependency keys
+func (d *Decoder) labelCandidates(idx int, labelSchema *schema.LabelSchema, db map[schema.SchemaKey]*schema.BodySchema, prefixRng, editRng hcl.Range) (lang.Candidates, error) {
 	candidates := lang.NewCandidates()
-	count := 0
 
 	foundCandidateNames := make(map[string]bool, 0)
 
-	prefix, _ := d.bytesFromRange(prefixRng)
+	prefix := d.prefixFromRange(prefixRng)
 
-	for schemaKey, bodySchema := range db {
-		depKeys, err := decodeSchemaKey(schemaKey)
+	if enum, ok := labelSchema.ValueConstraint.(schema.KeyEnum); ok {
+		for _, name := range enum.Names {
+			if len(prefix) > 0 && !strings.HasPrefix(name, string(prefix)) {
+				continue
+			}
+			if _, ok := foundCandidateNames[name]; ok {
+				continue
+			}
+
+			candidates.List = append(candidates.List, lang.Candidate{
+				Label:       name,
+				Kind:        lang.LabelCandidateKind,
+				Description: labelSchema.Description,
+				TextEdit: lang.TextEdit{
+					NewText: name,
+					Snippet: name,
+					Range:   editRng,
+				},
+			})
+			foundCandidateNames[name] = true
+		}
+	}
+
+	if !labelSchema.Completable {
+		db = nil
+	}
+
+	// iterate in a stable order, so that duplicate labels
+	// are always deduplicated in favour of the same schema
+	for _, schemaKey := range sortedSchemaKeys(db) {
+		bodySchema := db[schemaKey]
+		depKeys, err := schemaKey.DependencyKeys()
 		if err != nil {
 			// key undecodable
 			continue
@@ -27,13 +58,13 @@ func (d *Decoder) labelCandidatesFromDependentSchema(idx int, db map[schema.Sche
 
 		for _, label := range depKeys.Labels {
 			if label.Index == idx {
-				if uint(count) >= d.maxCandidates {
-					// reached maximum no of candidates
-					return candidates, nil
-				}
 				if len(prefix) > 0 && !strings.HasPrefix(label.Value, string(prefix)) {
 					continue
 				}
+				if labelSchema.ValidateValue(label.Value) != nil {
+					// value not valid as per the label rules
+					continue
+				}
 
 				// Dependent keys may be duplicated where one
 				// key is labels-only and other one contains
@@ -70,18 +101,27 @@ func (d *Decoder) labelCandidatesFromDependentSchema(idx int, db map[schema.Sche
 		}
 	}
 
-	candidates.IsComplete = true
+	candidates.Sort()
 
-	// TODO: sort by more metadata, such as IsDeprecated
-	sort.Slice(candidates.List, func(i, j int) bool {
-		return candidates.List[i].Label < candidates.List[j].Label
-	})
+	// candidates are only truncated after sorting, so that
+	// the same subset is returned when the limit is reached
+	if uint(len(candidates.List)) > d.maxCandidates {
+		candidates.List = candidates.List[:d.maxCandidates]
+		return candidates, nil
+	}
+
+	candidates.IsComplete = true
 
 	return candidates, nil
 }
 
-func decodeSchemaKey(key schema.SchemaKey) (schema.DependencyKeys, error) {
-	var dk schema.DependencyKeys
-	err := json.Unmarshal([]byte(key), &dk)
-	return dk, err
+func sortedSchemaKeys(db map[schema.SchemaKey]*schema.BodySchema) []schema.SchemaKey {
+	keys := make([]schema.SchemaKey, 0, len(db))
+	for key := range db {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	return keys
 }
@@ -0,0 +1,210 @@
+package decoder
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var labelRulesSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": {
+			Labels: []*schema.LabelSchema{
+				{
+					Name:        "type",
+					IsDepKey:    true,
+					Completable: true,
+					ValueConstraint: schema.KeyPattern{
+						Pattern: regexp.MustCompile(`^[a-z][a-z0-9_]*$`),
+					},
+				},
+				{
+					Name:          "name",
+					MaxLength:     8,
+					ReservedNames: []string{"self"},
+				},
+			},
+			DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{
+						{Index: 0, Value: "aws_instance"},
+					},
+				}): {
+					Attributes: map[string]*schema.AttributeSchema{
+						"ami": {Expr: schema.LiteralTypeOnly(cty.String)},
+					},
+				},
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{
+						{Index: 0, Value: "Legacy-Type"},
+					},
+				}): {},
+			},
+		},
+	},
+}
+
+func TestDecoder_ValidateFile_labels(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"valid labels",
+			`resource "aws_instance" "web" {
+}
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"invalid labels",
+			`resource "Legacy-Type" "self" {
+}
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid label",
+					Detail:   `Value "Legacy-Type" of label "type" must be matching "^[a-z][a-z0-9_]*$"`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						End:      hcl.Pos{Line: 1, Column: 23, Byte: 22},
+					},
+				},
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid label",
+					Detail:   `Value "self" of label "name" is a reserved name`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 24, Byte: 23},
+						End:      hcl.Pos{Line: 1, Column: 30, Byte: 29},
+					},
+				},
+			},
+		},
+		{
+			"label too long",
+			`resource "aws_instance" "too_long_name" {
+}
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid label",
+					Detail:   `Value "too_long_name" of label "name" must be at most 8 characters long`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 25, Byte: 24},
+						End:      hcl.Pos{Line: 1, Column: 40, Byte: 39},
+					},
+				},
+			},
+		},
+		{
+			"missing label",
+			`resource "aws_instance" {
+}
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Missing name for resource",
+					Detail:   "All resource blocks must have 2 labels (type, name).",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 25, Byte: 24},
+						End:      hcl.Pos{Line: 1, Column: 26, Byte: 25},
+					},
+					Context: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 26, Byte: 25},
+					},
+				},
+			},
+		},
+		{
+			"extraneous label",
+			`resource "aws_instance" "web" "extra" {
+}
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Extraneous label for resource",
+					Detail:   "Only 2 labels (type, name) are expected for resource blocks.",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 31, Byte: 30},
+						End:      hcl.Pos{Line: 1, Column: 38, Byte: 37},
+					},
+					Context: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 40, Byte: 39},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(labelRulesSchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_labelRules(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(labelRulesSchema)
+
+	f, _ := hclsyntax.ParseConfig([]byte(`resource "" {
+}
+`), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := make([]string, 0)
+	for _, c := range candidates.List {
+		labels = append(labels, c.Label)
+	}
+	expectedLabels := []string{"aws_instance"}
+	if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
@@ -0,0 +1,140 @@
+package decoder
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// lazyDependentBodySchema returns schema of provider blocks whose
+// dependent bodies are loaded lazily, counting the loads
+func lazyDependentBodySchema(loads *int32) *schema.BodySchema {
+	keyFor := func(value string) schema.SchemaKey {
+		return schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: value},
+			},
+		})
+	}
+	lazyBody := schema.NewLazyDependentBody(context.Background(),
+		[]schema.SchemaKey{keyFor("aws"), keyFor("google")},
+		func(ctx context.Context, key schema.SchemaKey) (*schema.BodySchema, error) {
+			atomic.AddInt32(loads, 1)
+			if key != keyFor("aws") {
+				return nil, nil
+			}
+			return &schema.BodySchema{
+				Detail: "hashicorp/aws",
+				Attributes: map[string]*schema.AttributeSchema{
+					"region": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.String),
+					},
+				},
+			}, nil
+		})
+
+	return &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"provider": {
+				Labels: []*schema.LabelSchema{
+					{Name: "name", IsDepKey: true, Completable: true},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"alias": {
+							IsOptional: true,
+							Expr:       schema.LiteralTypeOnly(cty.String),
+						},
+					},
+				},
+				LazyDependentBody: lazyBody,
+			},
+		},
+	}
+}
+
+func TestDecoder_CandidatesAtPos_lazyDependentBody(t *testing.T) {
+	var loads int32
+	cfg := `provider "aws" {
+  
+}
+`
+	d := NewDecoder()
+	d.SetSchema(lazyDependentBodySchema(&loads))
+
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 2, Column: 3, Byte: 19})
+	if err != nil {
+		t.Fatal(err)
+	}
+	langtest.AssertCandidates(t, `
+complete: true
+candidates: 2
+---
+label: "alias"
+kind: AttributeCandidateKind
+detail: "optional, string"
+edit: test.tf:2,3-2,3 "alias" (snippet "alias = \"${1:value}\"")
+---
+label: "region"
+kind: AttributeCandidateKind
+detail: "optional, string"
+edit: test.tf:2,3-2,3 "region" (snippet "region = \"${1:value}\"")
+`, candidates)
+
+	_, err = d.CandidatesAtPos("test.tf", hcl.Pos{Line: 2, Column: 3, Byte: 19})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loads != 1 {
+		t.Fatalf("expected schema to be loaded once, %d loads given", loads)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_lazyDependentBodyLabels(t *testing.T) {
+	var loads int32
+	cfg := `provider "" {
+}
+`
+	d := NewDecoder()
+	d.SetSchema(lazyDependentBodySchema(&loads))
+
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	langtest.AssertCandidates(t, `
+complete: true
+candidates: 2
+---
+label: "aws"
+kind: LabelCandidateKind
+edit: test.tf:1,11-1,11 "aws"
+---
+label: "google"
+kind: LabelCandidateKind
+edit: test.tf:1,11-1,11 "google"
+`, candidates)
+
+	if loads != 0 {
+		t.Fatalf("expected no schema to be loaded for label completion, %d loads given", loads)
+	}
+}
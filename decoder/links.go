@@ -5,20 +5,16 @@ import (
 
 	"github.com/hashicorp/hcl-lang/lang"
 	"github.com/hashicorp/hcl-lang/schema"
-	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
 // LinksInFile returns links relevant to parts of config in the given file
 //
 // A link (URI) typically points to the documentation.
-func (d *Decoder) LinksInFile(filename string) ([]lang.Link, error) {
-	f, err := d.fileByName(filename)
-	if err != nil {
-		return nil, err
-	}
+func (d *Decoder) LinksInFile(filename string, opts ...RequestOption) ([]lang.Link, error) {
+	d = d.withRequestOptions(opts)
 
-	body, err := d.bodyForFileAndPos(filename, f, hcl.InitialPos)
+	bodies, err := d.bodiesForFile(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -30,14 +26,23 @@ func (d *Decoder) LinksInFile(filename string) ([]lang.Link, error) {
 		return []lang.Link{}, &NoSchemaError{}
 	}
 
-	return d.linksInBody(body, d.rootSchema)
+	links := make([]lang.Link, 0)
+	for _, body := range bodies {
+		bodyLinks, err := d.linksInBody(body, d.rootSchema)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, bodyLinks...)
+	}
+
+	return links, nil
 }
 
 func (d *Decoder) linksInBody(body *hclsyntax.Body, bodySchema *schema.BodySchema) ([]lang.Link, error) {
 	links := make([]lang.Link, 0)
 
 	for _, block := range body.Blocks {
-		blockSchema, ok := bodySchema.Blocks[block.Type]
+		blockSchema, ok := blockSchemaForType(bodySchema, block.Type)
 		if !ok {
 			// Ignore unknown block
 			continue
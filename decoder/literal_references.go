@@ -0,0 +1,116 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// validateLiteralReferences reports a string literal (as the whole value
+// of an attribute which accepts references) which is equal to the
+// statically known value of a reference target (see InlineValuesInRange),
+// along with fixes replacing the literal with a reference to the target,
+// such that the duplicated values cannot drift apart.
+func (d *Decoder) validateLiteralReferences(attr *hclsyntax.Attribute, ec ExprConstraints, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	readTargets := d.referenceTargetReader()
+	if !d.features.LiteralReferenceHints || readTargets == nil {
+		return diags
+	}
+
+	tes := ec.TraversalExprs()
+	if len(tes) == 0 {
+		return diags
+	}
+
+	literal, ok := stringLiteralValue(attr.Expr)
+	if !ok || literal == "" {
+		return diags
+	}
+
+	matchingAddrs := make([]string, 0)
+	ReferenceTargets(readTargets()).DeepWalk(func(target lang.ReferenceTarget) error {
+		if target.RangePtr == nil || target.Sensitive || target.WriteOnly {
+			return nil
+		}
+		if target.RangePtr.Overlaps(attr.SrcRange) {
+			// the literal is the value of the target itself
+			return nil
+		}
+		if target.Type != cty.NilType && target.Type != cty.DynamicPseudoType && target.Type != cty.String {
+			return nil
+		}
+
+		matchesConstraint := false
+		for _, te := range tes {
+			if ReferenceTarget(target).MatchesConstraint(te) {
+				matchesConstraint = true
+				break
+			}
+		}
+		if !matchesConstraint {
+			return nil
+		}
+
+		val, ok := d.staticValueOfTarget(*target.RangePtr)
+		if !ok || val.Type() != cty.String || val.AsString() != literal {
+			return nil
+		}
+
+		matchingAddrs = append(matchingAddrs, d.addrFormat.Format(target.Addr))
+		return nil
+	})
+	if len(matchingAddrs) == 0 {
+		return diags
+	}
+	sort.Strings(matchingAddrs)
+
+	rng := attr.Expr.Range()
+	diag := d.newDiagnostic(CodeLiteralInsteadOfReference, &hcl.Diagnostic{
+		Severity: lang.DiagHint,
+		Summary:  "Literal instead of reference",
+		Detail: fmt.Sprintf("%q is the value of %s, which can be referenced instead",
+			literal, strings.Join(matchingAddrs, ", ")),
+		Subject: rng.Ptr(),
+	})
+	diags = append(diags, diag)
+
+	if fixes != nil {
+		for _, addr := range matchingAddrs {
+			*fixes = append(*fixes, lang.DiagnosticFix{
+				Title:      fmt.Sprintf("Reference %s", addr),
+				Diagnostic: diag,
+				Edits: []lang.TextEdit{
+					{
+						Range:   rng,
+						NewText: addr,
+						Snippet: escapeSnippetText(addr),
+					},
+				},
+			})
+		}
+	}
+
+	return diags
+}
+
+// stringLiteralValue returns the value of a quoted string
+// without any interpolations or directives
+func stringLiteralValue(expr hclsyntax.Expression) (string, bool) {
+	tplExpr, ok := expr.(*hclsyntax.TemplateExpr)
+	if !ok || !tplExpr.IsStringLiteral() {
+		return "", false
+	}
+
+	val, diags := tplExpr.Value(nil)
+	if diags.HasErrors() || val.Type() != cty.String || !val.IsKnown() || val.IsNull() {
+		return "", false
+	}
+	return val.AsString(), true
+}
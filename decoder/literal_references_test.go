@@ -0,0 +1,275 @@
+package decoder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var literalReferencesSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"locals": inlineValuesSchema.Blocks["locals"],
+		"output": {
+			Labels: []*schema.LabelSchema{
+				{Name: "name"},
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"value": {
+						IsOptional: true,
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfType: cty.String},
+							schema.LiteralTypeExpr{Type: cty.String},
+						},
+					},
+					"description": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.String),
+					},
+				},
+			},
+		},
+	},
+	Attributes: inlineValuesSchema.Attributes,
+}
+
+func TestDecoder_ValidateFileWithFixes_literalReferences(t *testing.T) {
+	testCases := []struct {
+		name           string
+		disabled       bool
+		cfg            string
+		expectedDetail string
+		expectedFixes  map[string]string
+	}{
+		{
+			"literal of target",
+			false,
+			`locals {
+  name = "foo"
+}
+output "a" {
+  value = "foo"
+}
+`,
+			`"foo" is the value of local.name, which can be referenced instead`,
+			map[string]string{
+				"Reference local.name": `locals {
+  name = "foo"
+}
+output "a" {
+  value = local.name
+}
+`,
+			},
+		},
+		{
+			"literal of multiple targets",
+			false,
+			`locals {
+  name  = "foo"
+  alias = local.name
+  other = "foo"
+}
+output "a" {
+  value = "foo"
+}
+`,
+			`"foo" is the value of local.name, local.other, which can be referenced instead`,
+			map[string]string{
+				"Reference local.name": `locals {
+  name  = "foo"
+  alias = local.name
+  other = "foo"
+}
+output "a" {
+  value = local.name
+}
+`,
+				"Reference local.other": `locals {
+  name  = "foo"
+  alias = local.name
+  other = "foo"
+}
+output "a" {
+  value = local.other
+}
+`,
+			},
+		},
+		{
+			"different literal",
+			false,
+			`locals {
+  name = "foo"
+}
+output "a" {
+  value = "bar"
+}
+`,
+			"",
+			nil,
+		},
+		{
+			"references not accepted",
+			false,
+			`locals {
+  name = "foo"
+}
+output "a" {
+  description = "foo"
+}
+`,
+			"",
+			nil,
+		},
+		{
+			"sensitive target",
+			false,
+			`password = "secret"
+output "a" {
+  value = "secret"
+}
+`,
+			"",
+			nil,
+		},
+		{
+			"template",
+			false,
+			`locals {
+  name = "foo"
+}
+output "a" {
+  value = "${local.name}"
+}
+`,
+			"",
+			nil,
+		},
+		{
+			"disabled",
+			true,
+			`locals {
+  name = "foo"
+}
+output "a" {
+  value = "foo"
+}
+`,
+			"",
+			nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(literalReferencesSchema)
+			d.SetFeatures(Features{LiteralReferenceHints: !tc.disabled})
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			targets, err := d.CollectReferenceTargets()
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return targets
+			})
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// only the hint within the output is of interest,
+			// as literals of locals may be reported too
+			outputLine := posInCfg(t, tc.cfg, "output").Line
+			var hint *hcl.Diagnostic
+			for _, diag := range diags {
+				if diag.Summary == "Literal instead of reference" && diag.Subject.Start.Line > outputLine {
+					hint = diag
+				}
+			}
+			if tc.expectedDetail == "" {
+				if hint != nil {
+					t.Fatalf("expected no hint, given: %#v", hint)
+				}
+				return
+			}
+			if hint == nil {
+				t.Fatalf("expected hint, given: %#v", diags)
+			}
+			if hint.Severity != lang.DiagHint || hint.Detail != tc.expectedDetail {
+				t.Fatalf("unexpected hint: %#v", hint)
+			}
+
+			fixedCfgs := make(map[string]string, 0)
+			for _, fix := range fixes.ForDiagnostic(hint) {
+				fixedCfgs[fix.Title] = applyTextEdits(tc.cfg, fix.Edits)
+			}
+			if diff := cmp.Diff(tc.expectedFixes, fixedCfgs); diff != "" {
+				t.Fatalf("unexpected fixes: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFileWithResultID_literalReferences(t *testing.T) {
+	ctx := context.Background()
+
+	d := NewDecoder()
+	d.SetSchema(literalReferencesSchema)
+	d.SetFeatures(Features{LiteralReferenceHints: true})
+
+	loadFile := func(filename, src string) {
+		f, _ := hclsyntax.ParseConfig([]byte(src), filename, hcl.InitialPos)
+		err := d.LoadFile(filename, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	loadFile("locals.tf", "locals {\n  env = \"prod\"\n}\n")
+	loadFile("test.tf", "output \"a\" {\n  value = \"prod\"\n}\n")
+
+	targets, err := d.CollectReferenceTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return targets
+	})
+
+	first, err := d.ValidateFileWithResultID(ctx, "test.tf", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, given: %#v", first.Diagnostics)
+	}
+
+	// value of the target changes in the other file, while its range
+	// (and so the targets) remain the same
+	loadFile("locals.tf", "locals {\n  env = \"test\"\n}\n")
+
+	second, err := d.ValidateFileWithResultID(ctx, "test.tf", first.ResultID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.IsUnchanged || second.ResultID == first.ResultID {
+		t.Fatalf("expected new result after target value change, given: %#v", second)
+	}
+	if len(second.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, given: %#v", second.Diagnostics)
+	}
+}
@@ -0,0 +1,154 @@
+package decoder
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+)
+
+// RootOverlay represents additions to the base shared
+// by all roots of a MultiRootDecoder, which are specific
+// to a single root path (e.g. a module).
+type RootOverlay struct {
+	// Functions are available in addition to the base functions,
+	// taking precedence over base functions of the same name
+	Functions map[string]schema.FunctionSignature
+
+	// ReferenceTargets are available in addition to targets
+	// returned from the reader set via SetReferenceTargetReader
+	ReferenceTargets lang.ReferenceTargets
+}
+
+// MultiRootDecoder manages decoders of many root paths (e.g. module
+// directories), which share the same base schema and functions.
+//
+// The base schema is shared by reference, i.e. it is never copied
+// per path, no matter how many paths are registered. Any path-specific
+// additions are expressed as lightweight overlays.
+//
+// MultiRootDecoder is safe for concurrent use.
+type MultiRootDecoder struct {
+	baseSchema    *schema.BodySchema
+	baseFunctions map[string]schema.FunctionSignature
+
+	decoders map[string]*Decoder
+	mu       *sync.RWMutex
+}
+
+func NewMultiRootDecoder() *MultiRootDecoder {
+	return &MultiRootDecoder{
+		decoders: make(map[string]*Decoder, 0),
+		mu:       &sync.RWMutex{},
+	}
+}
+
+// SetBaseSchema sets the schema shared by decoders of all paths,
+// including paths registered later.
+//
+// The schema must not be modified in place once set,
+// see Decoder.PatchSchema for modifying the schema of a single path.
+// Setting the base schema replaces the schema of every registered path,
+// i.e. any patches applied via Decoder.PatchSchema are discarded
+// and need to be applied again by the caller.
+func (m *MultiRootDecoder) SetBaseSchema(bodySchema *schema.BodySchema) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.baseSchema = bodySchema
+	for _, d := range m.decoders {
+		d.SetSchema(bodySchema)
+	}
+}
+
+// SetBaseFunctions sets signatures of functions available
+// to decoders of all paths, including paths registered later.
+func (m *MultiRootDecoder) SetBaseFunctions(functions map[string]schema.FunctionSignature) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.baseFunctions = functions
+	for _, d := range m.decoders {
+		d.SetFunctions(functions)
+	}
+}
+
+// RegisterPath returns a decoder for the given path, using
+// the base schema and functions along with the (optional) overlay.
+//
+// A decoder of a path which is already registered
+// is returned with the overlay replaced.
+func (m *MultiRootDecoder) RegisterPath(path string, overlay *RootOverlay) *Decoder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, ok := m.decoders[path]
+	if !ok {
+		d = NewDecoder()
+		if m.baseSchema != nil {
+			d.SetSchema(m.baseSchema)
+		}
+		d.SetFunctions(m.baseFunctions)
+		m.decoders[path] = d
+	}
+	d.setOverlay(overlay)
+
+	return d
+}
+
+// UnregisterPath removes the decoder of the given path
+func (m *MultiRootDecoder) UnregisterPath(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.decoders, path)
+}
+
+// DecoderForPath returns the decoder of a registered path
+func (m *MultiRootDecoder) DecoderForPath(path string) (*Decoder, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	d, ok := m.decoders[path]
+	return d, ok
+}
+
+// Paths returns sorted paths of all registered decoders
+func (m *MultiRootDecoder) Paths() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	paths := make([]string, 0, len(m.decoders))
+	for path := range m.decoders {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+func (d *Decoder) setOverlay(overlay *RootOverlay) {
+	d.readersMu.Lock()
+	defer d.readersMu.Unlock()
+	d.overlay = overlay
+	d.refTargetReader = overlayTargetReader(d.targetReader, overlay)
+}
+
+// overlayTargetReader returns a reader which adds
+// reference targets of the overlay to targets of the given reader
+func overlayTargetReader(f ReferenceTargetReader, overlay *RootOverlay) ReferenceTargetReader {
+	if overlay == nil || len(overlay.ReferenceTargets) == 0 {
+		return f
+	}
+
+	return func() lang.ReferenceTargets {
+		if f == nil {
+			return overlay.ReferenceTargets
+		}
+		targets := f()
+		allTargets := make(lang.ReferenceTargets, 0, len(targets)+len(overlay.ReferenceTargets))
+		allTargets = append(allTargets, targets...)
+		return append(allTargets, overlay.ReferenceTargets...)
+	}
+}
@@ -0,0 +1,135 @@
+package decoder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestMultiRootDecoder_sharedSchema(t *testing.T) {
+	m := NewMultiRootDecoder()
+
+	baseSchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"attr": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+		},
+	}
+	m.SetBaseSchema(baseSchema)
+
+	first := m.RegisterPath("first", nil)
+	second := m.RegisterPath("second", nil)
+
+	if first.rootSchema != baseSchema || second.rootSchema != baseSchema {
+		t.Fatal("expected decoders to share the base schema")
+	}
+
+	newSchema := &schema.BodySchema{}
+	m.SetBaseSchema(newSchema)
+	if first.rootSchema != newSchema || second.rootSchema != newSchema {
+		t.Fatal("expected decoders to share the updated base schema")
+	}
+
+	third := m.RegisterPath("third", nil)
+	if third.rootSchema != newSchema {
+		t.Fatal("expected decoder of a new path to use the base schema")
+	}
+
+	err := first.PatchSchema(func(bodySchema *schema.BodySchema) error {
+		bodySchema.Attributes = map[string]*schema.AttributeSchema{
+			"extra": {IsOptional: true},
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.rootSchema != newSchema || len(newSchema.Attributes) != 0 {
+		t.Fatal("expected patch of one path to leave the base schema unchanged")
+	}
+
+	if d, ok := m.DecoderForPath("second"); !ok || d != second {
+		t.Fatal("expected the registered decoder to be returned")
+	}
+
+	m.UnregisterPath("second")
+	expectedPaths := []string{"first", "third"}
+	if diff := cmp.Diff(expectedPaths, m.Paths()); diff != "" {
+		t.Fatalf("unexpected paths: %s", diff)
+	}
+}
+
+func TestMultiRootDecoder_overlay(t *testing.T) {
+	m := NewMultiRootDecoder()
+	m.SetBaseFunctions(map[string]schema.FunctionSignature{
+		"upper": {ReturnType: cty.String},
+		"keys":  {ReturnType: cty.List(cty.String)},
+	})
+
+	overlayTarget := lang.ReferenceTarget{
+		Addr: lang.Address{
+			lang.RootStep{Name: "local"},
+			lang.AttrStep{Name: "extra"},
+		},
+		Type: cty.String,
+	}
+	d := m.RegisterPath("first", &RootOverlay{
+		Functions: map[string]schema.FunctionSignature{
+			"keys":  {ReturnType: cty.Set(cty.String)},
+			"local": {ReturnType: cty.Number},
+		},
+		ReferenceTargets: lang.ReferenceTargets{overlayTarget},
+	})
+	other := m.RegisterPath("second", nil)
+
+	baseTarget := lang.ReferenceTarget{
+		Addr: lang.Address{
+			lang.RootStep{Name: "var"},
+			lang.AttrStep{Name: "foo"},
+		},
+		Type: cty.String,
+	}
+	readTargets := func() lang.ReferenceTargets {
+		return lang.ReferenceTargets{baseTarget}
+	}
+	d.SetReferenceTargetReader(readTargets)
+	other.SetReferenceTargetReader(readTargets)
+
+	expectedTargets := lang.ReferenceTargets{baseTarget, overlayTarget}
+	if diff := cmp.Diff(expectedTargets, d.refTargetReader(), ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected targets: %s", diff)
+	}
+	expectedTargets = lang.ReferenceTargets{baseTarget}
+	if diff := cmp.Diff(expectedTargets, other.refTargetReader(), ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected targets of path without overlay: %s", diff)
+	}
+
+	expectedTypes := map[string]cty.Type{
+		"upper": cty.String,
+		"keys":  cty.Set(cty.String),
+		"local": cty.Number,
+	}
+	for name, expectedType := range expectedTypes {
+		sig, ok := d.functionSignature(name)
+		if !ok {
+			t.Fatalf("expected function %q to be known", name)
+		}
+		if !sig.ReturnType.Equals(expectedType) {
+			t.Fatalf("%q: expected return type %s, given %s", name,
+				expectedType.FriendlyName(), sig.ReturnType.FriendlyName())
+		}
+	}
+	if _, ok := other.functionSignature("local"); ok {
+		t.Fatal("expected overlay function not to leak into other paths")
+	}
+
+	// replacing the overlay keeps the reader
+	m.RegisterPath("first", nil)
+	expectedTargets = lang.ReferenceTargets{baseTarget}
+	if diff := cmp.Diff(expectedTargets, d.refTargetReader(), ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected targets after overlay removal: %s", diff)
+	}
+}
@@ -0,0 +1,322 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_CandidatesAtPos_multibyte(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"attr": {
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.String},
+				},
+			},
+		},
+		Blocks: map[string]*schema.BlockSchema{
+			"myblock": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true, Completable: true},
+				},
+				DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{{Index: 0, Value: "café"}},
+					}): {},
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{{Index: 0, Value: "cafeteria"}},
+					}): {},
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{{Index: 0, Value: "tea"}},
+					}): {},
+				},
+			},
+		},
+	}
+	refTargets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "café"},
+			},
+			Type: cty.String,
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "cafeteria"},
+			},
+			Type: cty.String,
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		cfg                string
+		pos                hcl.Pos
+		expectedCandidates lang.Candidates
+	}{
+		{
+			"traversal after line with multibyte comment",
+			`# ☃ snowman 🚀
+attr = var.caf
+`,
+			hcl.Pos{Line: 2, Column: 15, Byte: 33},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "var.cafeteria",
+					Detail: "string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "var.cafeteria",
+						Snippet: "var.cafeteria",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 8, Byte: 26},
+							End:      hcl.Pos{Line: 2, Column: 15, Byte: 33},
+						},
+					},
+				},
+				{
+					Label:  "var.café",
+					Detail: "string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "var.café",
+						Snippet: "var.café",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 8, Byte: 26},
+							End:      hcl.Pos{Line: 2, Column: 15, Byte: 33},
+						},
+					},
+				},
+			}),
+		},
+		{
+			"traversal after multibyte character",
+			`attr = var.café
+`,
+			hcl.Pos{Line: 1, Column: 16, Byte: 16},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "var.café",
+					Detail: "string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "var.café",
+						Snippet: "var.café",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 16, Byte: 16},
+						},
+					},
+				},
+			}),
+		},
+		{
+			// column calculated differently by the client (e.g. in bytes)
+			// should not affect the range of the edit
+			"traversal with inconsistent column",
+			`attr = var.café
+`,
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "var.café",
+					Detail: "string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "var.café",
+						Snippet: "var.café",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 16, Byte: 16},
+						},
+					},
+				},
+			}),
+		},
+		{
+			"label with multibyte candidates",
+			`myblock "caf" {
+}
+`,
+			hcl.Pos{Line: 1, Column: 13, Byte: 12},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label: "cafeteria",
+					Kind:  lang.LabelCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "cafeteria",
+						Snippet: "cafeteria",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+							End:      hcl.Pos{Line: 1, Column: 13, Byte: 12},
+						},
+					},
+				},
+				{
+					Label: "café",
+					Kind:  lang.LabelCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "café",
+						Snippet: "café",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+							End:      hcl.Pos{Line: 1, Column: 13, Byte: 12},
+						},
+					},
+				},
+			}),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return refTargets
+			})
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedCandidates, candidates, ctydebug.CmpOptions); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_SemanticTokensInFile_multibyte(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"str": {Expr: schema.LiteralTypeOnly(cty.String)},
+			"num": {Expr: schema.LiteralTypeOnly(cty.Number)},
+			"ref": {Expr: schema.ExprConstraints{
+				schema.TraversalExpr{OfType: cty.String},
+			}},
+		},
+	})
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return lang.ReferenceTargets{
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "var"},
+					lang.AttrStep{Name: "café"},
+				},
+				Type: cty.String,
+			},
+		}
+	})
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`str = "☃🚀" # ☃
+num = 42
+ref = var.café
+`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := d.SemanticTokensInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTokens := []lang.SemanticToken{
+		{
+			Type:      lang.TokenAttrName,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:      hcl.Pos{Line: 1, Column: 4, Byte: 3},
+			},
+		},
+		{
+			Type:      lang.TokenString,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 7, Byte: 6},
+				End:      hcl.Pos{Line: 1, Column: 11, Byte: 15},
+			},
+		},
+		{
+			Type:      lang.TokenAttrName,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 1, Byte: 22},
+				End:      hcl.Pos{Line: 2, Column: 4, Byte: 25},
+			},
+		},
+		{
+			Type:      lang.TokenNumber,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 7, Byte: 28},
+				End:      hcl.Pos{Line: 2, Column: 9, Byte: 30},
+			},
+		},
+		{
+			Type:      lang.TokenAttrName,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 3, Column: 1, Byte: 31},
+				End:      hcl.Pos{Line: 3, Column: 4, Byte: 34},
+			},
+		},
+		{
+			Type:      lang.TokenTraversalStep,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 3, Column: 7, Byte: 37},
+				End:      hcl.Pos{Line: 3, Column: 10, Byte: 40},
+			},
+		},
+		{
+			Type:      lang.TokenTraversalStep,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 3, Column: 11, Byte: 41},
+				End:      hcl.Pos{Line: 3, Column: 15, Byte: 46},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expectedTokens, tokens); diff != "" {
+		t.Fatalf("unexpected tokens: %s", diff)
+	}
+}
@@ -0,0 +1,118 @@
+package decoder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// labelForNamedTuple returns label of a tuple with named
+// positions, such as [min, max]
+func labelForNamedTuple(te schema.TupleExpr) string {
+	return fmt.Sprintf("[%s]", strings.Join(te.ElemNames, ", "))
+}
+
+// snippetForNamedTuple returns snippet of a tuple with
+// a placeholder for each named position, starting at the given one
+func snippetForNamedTuple(placeholder uint, te schema.TupleExpr) string {
+	elems := make([]string, len(te.ElemNames))
+	for i, name := range te.ElemNames {
+		elems[i] = fmt.Sprintf("${%d:%s}", placeholder+uint(i), name)
+	}
+	return fmt.Sprintf("[ %s ]", strings.Join(elems, ", "))
+}
+
+// hoverContentForNamedTuple returns hover content listing
+// the named positions of a tuple along with their constraints
+func hoverContentForNamedTuple(te schema.TupleExpr) string {
+	content := fmt.Sprintf("_%s_ `%s`\n", te.FriendlyName(), labelForNamedTuple(te))
+	for i, name := range te.ElemNames {
+		content += fmt.Sprintf("\n%d. `%s`", i+1, name)
+		if i < len(te.Elems) {
+			if elemName := te.Elems[i].FriendlyName(); elemName != "" {
+				content += fmt.Sprintf(" _%s_", elemName)
+			}
+		}
+	}
+	if te.Description.Value != "" {
+		content += "\n\n" + te.Description.Value
+	}
+	return content
+}
+
+// validateNamedTuple reports elements of a tuple with named positions
+// which are missing, extraneous, or of an invalid type, using names
+// of the positions in the diagnostics
+func (d *Decoder) validateNamedTuple(name string, expr hcl.Expression, ec ExprConstraints) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	tupleExpr, ok := expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return diags
+	}
+	c, ok := ec.constraintForExpr(tupleExpr)
+	if !ok {
+		return diags
+	}
+	te, ok := c.(schema.TupleExpr)
+	if !ok || !te.IsNamed() {
+		return diags
+	}
+
+	for i := len(tupleExpr.Exprs); i < len(te.ElemNames); i++ {
+		diags = append(diags, d.newDiagnostic(CodeMissingTupleElement, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing tuple element",
+			Detail: fmt.Sprintf("Value of %q must have %d elements (%s), element %q is missing",
+				name, len(te.ElemNames), strings.Join(te.ElemNames, ", "), te.ElemNames[i]),
+			Subject: tupleExpr.SrcRange.Ptr(),
+		}))
+	}
+
+	for i, elemExpr := range tupleExpr.Exprs {
+		elemName, ok := te.ElemName(i)
+		if !ok {
+			diags = append(diags, d.newDiagnostic(CodeExtraneousTupleElement, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Extraneous tuple element",
+				Detail: fmt.Sprintf("Value of %q must have %d elements (%s)",
+					name, len(te.ElemNames), strings.Join(te.ElemNames, ", ")),
+				Subject: elemExpr.Range().Ptr(),
+			}))
+			continue
+		}
+
+		types, ok := ExprConstraints(te.Elems[i]).LiteralTypesOnly()
+		if !ok || len(elemExpr.Variables()) > 0 {
+			continue
+		}
+		val, vDiags := elemExpr.Value(nil)
+		if vDiags.HasErrors() || !val.IsWhollyKnown() {
+			continue
+		}
+		isConvertible := false
+		for _, t := range types {
+			if _, err := convert.Convert(val, t); err == nil {
+				isConvertible = true
+				break
+			}
+		}
+		if isConvertible {
+			continue
+		}
+
+		diags = append(diags, d.newDiagnostic(CodeInvalidValueType, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value type",
+			Detail: fmt.Sprintf("Element %q of %q must be %s, %s given",
+				elemName, name, te.Elems[i].FriendlyName(), val.Type().FriendlyName()),
+			Subject: elemExpr.Range().Ptr(),
+		}))
+	}
+
+	return diags
+}
@@ -0,0 +1,171 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var namedTupleSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"range": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.TupleExpr{
+					Elems: []schema.ExprConstraints{
+						schema.LiteralTypeOnly(cty.Number),
+						schema.LiteralTypeOnly(cty.Number),
+					},
+					ElemNames:   []string{"min", "max"},
+					Description: lang.Markdown("Range of allowed values"),
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_namedTuple(t *testing.T) {
+	cfg := `range = 
+`
+	d := newTestDecoder(t, namedTupleSchema, map[string]string{"test.tf": cfg})
+
+	candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, cfg, "range = "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	langtest.AssertCandidates(t, `
+complete: true
+candidates: 1
+---
+label: "[min, max]"
+kind: TupleCandidateKind
+detail: "tuple"
+description (MarkdownKind): "Range of allowed values"
+edit: test.tf:1,9-1,9 "[ ]" (snippet "[ ${1:min}, ${2:max} ]")
+`, candidates)
+}
+
+func TestDecoder_HoverAtPos_namedTuple(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		posAfter        string
+		expectedContent lang.MarkupContent
+	}{
+		{
+			"whole tuple",
+			`range = [1, 5]
+`,
+			"range = ",
+			lang.Markdown("_tuple_ `[min, max]`\n\n1. `min` _number_\n2. `max` _number_" +
+				"\n\nRange of allowed values"),
+		},
+		{
+			"element",
+			`range = [1, 5]
+`,
+			"range = [1, ",
+			lang.Markdown("**max** (element 2 of `[min, max]`)\n\n`5` _number_"),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, namedTupleSchema, map[string]string{"test.tf": tc.cfg})
+
+			data, err := d.HoverAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedContent, data.Content); diff != "" {
+				t.Fatalf("unexpected hover content: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFile_namedTuple(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"valid",
+			`range = [1, 5]
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"missing element",
+			`range = [1]
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Missing tuple element",
+					Detail:   `Value of "range" must have 2 elements (min, max), element "max" is missing`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+						End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
+					},
+				},
+			},
+		},
+		{
+			"extraneous element",
+			`range = [1, 5, 9]
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Extraneous tuple element",
+					Detail:   `Value of "range" must have 2 elements (min, max)`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 16, Byte: 15},
+						End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+					},
+				},
+			},
+		},
+		{
+			"invalid element type",
+			`range = [1, true]
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid value type",
+					Detail:   `Element "max" of "range" must be number, bool given`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 13, Byte: 12},
+						End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, namedTupleSchema, map[string]string{"test.tf": tc.cfg})
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package decoder
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const pathologicalNestingLvl = 1000
+
+// recursiveBlockSchema returns schema of a block which may contain
+// any number of blocks of the same type nested within itself
+func recursiveBlockSchema() *schema.BodySchema {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"attr": {Expr: schema.LiteralTypeOnly(cty.Bool)},
+		},
+		Blocks: map[string]*schema.BlockSchema{},
+	}
+	bodySchema.Blocks["nested"] = &schema.BlockSchema{
+		Body: bodySchema,
+	}
+	return bodySchema
+}
+
+// recursiveObjectSchema returns schema of an object which may contain
+// any number of objects of the same type nested within itself
+func recursiveObjectSchema() *schema.BodySchema {
+	attrs := schema.ObjectExprAttributes{
+		"attr": {Expr: schema.LiteralTypeOnly(cty.Bool)},
+	}
+	attrs["nested"] = &schema.AttributeSchema{
+		Expr: schema.ExprConstraints{
+			schema.ObjectExpr{Attributes: attrs},
+		},
+	}
+
+	return &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"obj": {
+				Expr: schema.ExprConstraints{
+					schema.ObjectExpr{Attributes: attrs},
+				},
+			},
+		},
+	}
+}
+
+func nestedBlocksCfg(lvl int) string {
+	return strings.Repeat("nested {\n", lvl) +
+		"\n" +
+		strings.Repeat("}\n", lvl)
+}
+
+func nestedObjectsCfg(lvl int) string {
+	return "obj = " + strings.Repeat("{\nnested = ", lvl) +
+		"{\n\n}" +
+		strings.Repeat("\n}", lvl) + "\n"
+}
+
+// posInCfg returns position right after the last occurrence of substr
+func posInCfg(t *testing.T, cfg, substr string) hcl.Pos {
+	idx := strings.LastIndex(cfg, substr)
+	if idx < 0 {
+		t.Fatalf("%q not found", substr)
+	}
+	offset := idx + len(substr)
+	line := strings.Count(cfg[:offset], "\n") + 1
+	column := offset - strings.LastIndex(cfg[:offset], "\n")
+	return hcl.Pos{Line: line, Column: column, Byte: offset}
+}
+
+func TestDecoder_CandidatesAtPos_pathologicalNesting(t *testing.T) {
+	testCases := []struct {
+		name               string
+		bodySchema         *schema.BodySchema
+		cfg                string
+		maxNestingDepth    uint
+		expectedCandidates int
+	}{
+		{
+			"blocks beyond default depth",
+			recursiveBlockSchema(),
+			nestedBlocksCfg(pathologicalNestingLvl),
+			0,
+			0,
+		},
+		{
+			"blocks within raised depth",
+			recursiveBlockSchema(),
+			nestedBlocksCfg(pathologicalNestingLvl),
+			pathologicalNestingLvl,
+			2,
+		},
+		{
+			"objects beyond default depth",
+			recursiveObjectSchema(),
+			nestedObjectsCfg(pathologicalNestingLvl),
+			0,
+			0,
+		},
+		{
+			"objects within raised depth",
+			recursiveObjectSchema(),
+			nestedObjectsCfg(pathologicalNestingLvl),
+			pathologicalNestingLvl,
+			2,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(tc.bodySchema)
+			if tc.maxNestingDepth > 0 {
+				d.SetMaxNestingDepth(tc.maxNestingDepth)
+			}
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, "{\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(candidates.List) != tc.expectedCandidates {
+				t.Fatalf("expected %d candidates, %d given: %#v",
+					tc.expectedCandidates, len(candidates.List), candidates.List)
+			}
+		})
+	}
+}
+
+func TestDecoder_HoverAtPos_pathologicalNesting(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(recursiveObjectSchema())
+
+	cfg := strings.Replace(nestedObjectsCfg(pathologicalNestingLvl), "{\n\n}", "{\nattr = true\n}", 1)
+	f, pDiags := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pos := posInCfg(t, cfg, "attr = ")
+	data, err := d.HoverAtPos("test.tf", pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// innermost attribute is beyond reach, so the closest
+	// reachable attribute is expected to be described instead
+	if !strings.HasPrefix(data.Content.Value, "**nested** _object_") {
+		t.Fatalf("unexpected hover content: %q", data.Content.Value)
+	}
+	if key := data.Range.SliceBytes([]byte(cfg)); string(key) != "nested" {
+		t.Fatalf("expected hover range to cover the attribute key, %q given", key)
+	}
+}
+
+func TestDecoder_SymbolsInFile_pathologicalNesting(t *testing.T) {
+	d := NewDecoder()
+	d.SetMaxNestingDepth(2)
+
+	cfg := "attr = " + strings.Repeat("[", pathologicalNestingLvl) +
+		strings.Repeat("]", pathologicalNestingLvl) + "\n" +
+		nestedBlocksCfg(pathologicalNestingLvl)
+	f, pDiags := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	symbols, err := d.SymbolsInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDepths := []int{4, 3}
+	depths := make([]int, 0)
+	for _, s := range symbols {
+		depths = append(depths, symbolDepth(s))
+	}
+	if diff := cmp.Diff(expectedDepths, depths); diff != "" {
+		t.Fatalf("unexpected symbol depths: %s", diff)
+	}
+}
+
+func symbolDepth(s Symbol) int {
+	maxDepth := 0
+	for _, ns := range s.NestedSymbols() {
+		if depth := symbolDepth(ns); depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	return maxDepth + 1
+}
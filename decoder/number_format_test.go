@@ -0,0 +1,134 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var numberFormatSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"ratio": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralValue{Val: cty.NumberFloatVal(3.14159)},
+			},
+		},
+		"size": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.Number),
+		},
+	},
+}
+
+func TestDecoder_HoverAtPos_numberFormat(t *testing.T) {
+	testCases := []struct {
+		name            string
+		format          lang.NumberFormat
+		cfg             string
+		expectedContent string
+	}{
+		{
+			"default integer",
+			lang.NumberFormat{},
+			`size = 1234567
+`,
+			"`1234567` _number_",
+		},
+		{
+			"large integer",
+			lang.NumberFormat{},
+			`size = 123456789012345678901234567890
+`,
+			"`123456789012345678901234567890` _number_",
+		},
+		{
+			"grouped integer",
+			lang.NumberFormat{GroupSeparator: ","},
+			`size = 1234567
+`,
+			"`1,234,567` _number_",
+		},
+		{
+			"grouped float",
+			lang.NumberFormat{GroupSeparator: " "},
+			`size = 1234.5678
+`,
+			"`1 234.5678` _number_",
+		},
+		{
+			"precision",
+			lang.NumberFormat{MaxPrecision: 2},
+			`size = 1234.5678
+`,
+			"`1234.57` _number_",
+		},
+		{
+			"precision with trailing zeros",
+			lang.NumberFormat{MaxPrecision: 3},
+			`size = 0.5
+`,
+			"`0.5` _number_",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(numberFormatSchema)
+			d.SetNumberFormat(tc.format)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := d.HoverAtPos("test.tf", posInCfg(t, tc.cfg, "size = "))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedContent, data.Content.Value); diff != "" {
+				t.Fatalf("unexpected hover content: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_numberFormat(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(numberFormatSchema)
+	d.SetNumberFormat(lang.NumberFormat{
+		MaxPrecision:   2,
+		GroupSeparator: ",",
+	})
+
+	cfg := `ratio = 
+`
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, cfg, "ratio = "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	langtest.AssertCandidates(t, `
+complete: true
+candidates: 1
+---
+label: "3.14"
+kind: NumberCandidateKind
+detail: "number"
+edit: test.tf:1,9-1,9 "3.14" (snippet "${1:3.14}")
+`, candidates)
+}
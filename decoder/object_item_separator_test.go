@@ -0,0 +1,197 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var objectItemSeparatorSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"obj": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.ObjectExpr{
+					Attributes: schema.ObjectExprAttributes{
+						"name":    {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+						"enabled": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.Bool)},
+					},
+				},
+			},
+		},
+		"tags": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.Map(cty.String)),
+		},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_objectItemSeparator(t *testing.T) {
+	testCases := []struct {
+		name               string
+		sep                lang.ObjectItemSeparator
+		cfg                string
+		posAfter           string
+		expectedCandidates string
+	}{
+		{
+			"equals by default",
+			lang.EqualsObjectItemSeparator,
+			`obj = {
+  name = "foo"
+  
+}
+`,
+			"\"foo\"\n  ",
+			`
+complete: true
+candidates: 1
+---
+label: "enabled"
+kind: AttributeCandidateKind
+detail: "optional, bool"
+edit: test.tf:3,3-3,3 "enabled = false" (snippet "enabled = ${1:false}")
+`,
+		},
+		{
+			"colon preferred",
+			lang.ColonObjectItemSeparator,
+			`obj = {
+  name = "foo"
+  
+}
+`,
+			"\"foo\"\n  ",
+			`
+complete: true
+candidates: 1
+---
+label: "enabled"
+kind: AttributeCandidateKind
+detail: "optional, bool"
+edit: test.tf:3,3-3,3 "enabled : false" (snippet "enabled : ${1:false}")
+`,
+		},
+		{
+			"colon accepted on input",
+			lang.EqualsObjectItemSeparator,
+			`obj = {
+  name : "foo"
+  
+}
+`,
+			"\"foo\"\n  ",
+			`
+complete: true
+candidates: 1
+---
+label: "enabled"
+kind: AttributeCandidateKind
+detail: "optional, bool"
+edit: test.tf:3,3-3,3 "enabled = false" (snippet "enabled = ${1:false}")
+`,
+		},
+		{
+			"map value with colon",
+			lang.ColonObjectItemSeparator,
+			`tags = 
+`,
+			"tags = ",
+			`
+complete: true
+candidates: 1
+---
+label: "{ \"key\" : string }"
+kind: MapCandidateKind
+detail: "map of string"
+edit: test.tf:1,8-1,8 "{\n  \"key\" : \"\"\n}" (snippet "{\n  \"${1:key}\" : \"${2:value}\"\n}")
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(objectItemSeparatorSchema)
+			d.SetObjectItemSeparator(tc.sep)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_attributeObjectItemSeparator(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(objectItemSeparatorSchema)
+	d.SetObjectItemSeparator(lang.ColonObjectItemSeparator)
+
+	cfg := "\n"
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.InitialPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	langtest.AssertCandidates(t, `
+complete: true
+candidates: 2
+---
+label: "obj"
+kind: AttributeCandidateKind
+detail: "optional, object"
+edit: test.tf:1,1-1,1 "obj" (snippet "obj = {\n  ${2}\n }")
+command: editor.action.triggerSuggest
+---
+label: "tags"
+kind: AttributeCandidateKind
+detail: "optional, map of string"
+edit: test.tf:1,1-1,1 "tags" (snippet "tags = {\n  \"${1:key}\" : \"${2:value}\"\n}")
+`, candidates)
+}
+
+func TestDecoder_ValidateFile_objectItemSeparator(t *testing.T) {
+	cfg := `obj = {
+  name : "foo"
+  enabled = true
+}
+tags = {
+  "a" : "b"
+}
+`
+	d := NewDecoder()
+	d.SetSchema(objectItemSeparatorSchema)
+
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := d.ValidateFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) > 0 {
+		t.Fatalf("expected no diagnostics, given: %#v", diags)
+	}
+}
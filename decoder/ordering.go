@@ -0,0 +1,324 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/position"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// bodyItem represents an attribute or a block of a body
+type bodyItem struct {
+	name    string
+	isAttr  bool
+	rng     hcl.Range
+	nameRng hcl.Range
+}
+
+// bodyItemsInSourceOrder returns attributes and blocks of the body
+// in the order in which they are declared
+func bodyItemsInSourceOrder(body *hclsyntax.Body) []bodyItem {
+	items := make([]bodyItem, 0, len(body.Attributes)+len(body.Blocks))
+	for _, attr := range body.Attributes {
+		items = append(items, bodyItem{
+			name:    attr.Name,
+			isAttr:  true,
+			rng:     attr.SrcRange,
+			nameRng: attr.NameRange,
+		})
+	}
+	for _, block := range body.Blocks {
+		items = append(items, bodyItem{
+			name:    block.Type,
+			rng:     block.Range(),
+			nameRng: block.TypeRange,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].rng.Start.Byte < items[j].rng.Start.Byte
+	})
+
+	return items
+}
+
+// expectedOrder returns indexes of the items in the order
+// expected by the given ordering rules
+func expectedOrder(items []bodyItem, ordering *schema.Ordering) []int {
+	firstIdx := make(map[string]int, len(ordering.First))
+	for i, name := range ordering.First {
+		firstIdx[name] = i
+	}
+	lastIdx := make(map[string]int, len(ordering.Last))
+	for i, name := range ordering.Last {
+		lastIdx[name] = i
+	}
+
+	// group 0 is first, 1 is any other and 2 is last
+	rank := func(item bodyItem) (int, int) {
+		if i, ok := firstIdx[item.name]; ok {
+			return 0, i
+		}
+		if i, ok := lastIdx[item.name]; ok {
+			return 2, i
+		}
+		return 1, 0
+	}
+
+	order := make([]int, len(items))
+	for i := range items {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		iGroup, iIdx := rank(items[order[i]])
+		jGroup, jIdx := rank(items[order[j]])
+		if iGroup != jGroup {
+			return iGroup < jGroup
+		}
+		return iIdx < jIdx
+	})
+
+	if ordering.AlphabeticalAttributes {
+		// sort attributes among the slots they already occupy,
+		// so that blocks in between are not affected
+		slots := make([]int, 0)
+		attrs := make([]int, 0)
+		for slot, idx := range order {
+			if group, _ := rank(items[idx]); group == 1 && items[idx].isAttr {
+				slots = append(slots, slot)
+				attrs = append(attrs, idx)
+			}
+		}
+		sort.SliceStable(attrs, func(i, j int) bool {
+			return items[attrs[i]].name < items[attrs[j]].name
+		})
+		for i, slot := range slots {
+			order[slot] = attrs[i]
+		}
+	}
+
+	return order
+}
+
+// validateOrdering reports the first attribute or block of the body
+// which is out of the order expected by the schema (see schema.Ordering),
+// along with a fix which reorders the whole body
+func (d *Decoder) validateOrdering(body *hclsyntax.Body, bodySchema *schema.BodySchema, fixes *lang.DiagnosticFixes, related relatedInfos) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if bodySchema.Ordering == nil {
+		return diags
+	}
+
+	items := bodyItemsInSourceOrder(body)
+	order := expectedOrder(items, bodySchema.Ordering)
+
+	for slot, idx := range order {
+		if slot == idx {
+			continue
+		}
+
+		expected, actual := items[idx], items[slot]
+		diag := d.newDiagnostic(CodeUnexpectedOrder, &hcl.Diagnostic{
+			Severity: lang.DiagHint,
+			Summary:  "Unexpected order",
+			Detail:   fmt.Sprintf("%q is expected before %q", expected.name, actual.name),
+			Subject:  expected.nameRng.Ptr(),
+		})
+		diags = append(diags, diag)
+		related.add(diag, lang.DiagnosticRelatedInfo{
+			Range:   actual.nameRng,
+			Message: fmt.Sprintf("%q is declared here", actual.name),
+		})
+
+		if fixes != nil {
+			if edit, ok := d.reorderingEdit(body, items, order); ok {
+				*fixes = append(*fixes, lang.DiagnosticFix{
+					Title:      "Reorder attributes and blocks",
+					Diagnostic: diag,
+					Edits:      []lang.TextEdit{edit},
+				})
+			}
+		}
+
+		break
+	}
+
+	return diags
+}
+
+// reorderingEdit returns an edit which reorders items of the body
+// as given, such that any comments and blank lines between them
+// are preserved along with the items, via hclwrite
+func (d *Decoder) reorderingEdit(body *hclsyntax.Body, items []bodyItem, order []int) (lang.TextEdit, bool) {
+	filename := body.SrcRange.Filename
+	f, err := d.fileByName(filename)
+	if err != nil {
+		return lang.TextEdit{}, false
+	}
+	rootBody, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return lang.TextEdit{}, false
+	}
+	path, ok := blockPathToBody(rootBody, body)
+	if !ok {
+		// e.g. body of a file segment
+		return lang.TextEdit{}, false
+	}
+
+	wf, diags := hclwrite.ParseConfig(f.Bytes, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return lang.TextEdit{}, false
+	}
+	wBody := wf.Body()
+	for _, idx := range path {
+		blocks := wBody.Blocks()
+		if idx >= len(blocks) {
+			return lang.TextEdit{}, false
+		}
+		wBody = blocks[idx].Body()
+	}
+
+	bodyTokens := wBody.BuildTokens(nil)
+	wAttrs := wBody.Attributes()
+	wBlocks := wBody.Blocks()
+
+	// tokens of each item (including its comments),
+	// which are located within the body by identity
+	itemTokens := make([]hclwrite.Tokens, len(items))
+	itemStarts := make([]int, len(items))
+	blockIdx := 0
+	for i, item := range items {
+		if item.isAttr {
+			attr, ok := wAttrs[item.name]
+			if !ok {
+				return lang.TextEdit{}, false
+			}
+			itemTokens[i] = attr.BuildTokens(nil)
+		} else {
+			if blockIdx >= len(wBlocks) {
+				return lang.TextEdit{}, false
+			}
+			itemTokens[i] = wBlocks[blockIdx].BuildTokens(nil)
+			blockIdx++
+		}
+
+		if len(itemTokens[i]) == 0 {
+			return lang.TextEdit{}, false
+		}
+		itemStarts[i] = indexOfToken(bodyTokens, itemTokens[i][0])
+		if itemStarts[i] < 0 {
+			return lang.TextEdit{}, false
+		}
+	}
+
+	newTokens := make(hclwrite.Tokens, 0, len(bodyTokens)+1)
+	prevEnd := 0
+	for slot, idx := range order {
+		newTokens = append(newTokens, bodyTokens[prevEnd:itemStarts[slot]]...)
+		tokens := itemTokens[idx]
+		newTokens = append(newTokens, tokens...)
+		if !bytes.HasSuffix(tokens[len(tokens)-1].Bytes, []byte("\n")) {
+			// e.g. the last item of a file without trailing newline
+			newTokens = append(newTokens, &hclwrite.Token{
+				Type:  hclsyntax.TokenNewline,
+				Bytes: []byte("\n"),
+			})
+		}
+		prevEnd = itemStarts[slot] + len(itemTokens[slot])
+	}
+	newTokens = append(newTokens, bodyTokens[prevEnd:]...)
+
+	wBody.Clear()
+	wBody.AppendUnstructuredTokens(newTokens)
+
+	// tokens are written as they are, since formatting
+	// of the whole file (as in File.Bytes) is not desired
+	var buf bytes.Buffer
+	_, err = wf.BuildTokens(nil).WriteTo(&buf)
+	if err != nil {
+		return lang.TextEdit{}, false
+	}
+
+	return textEditForChange(filename, f.Bytes, buf.Bytes())
+}
+
+// blockPathToBody returns indexes of blocks (at each level of nesting)
+// leading from the root body to the given body
+func blockPathToBody(root, body *hclsyntax.Body) ([]int, bool) {
+	if root == body {
+		return []int{}, true
+	}
+	for i, block := range root.Blocks {
+		if block.Body == nil || !block.Body.SrcRange.Overlaps(body.SrcRange) {
+			continue
+		}
+		if path, ok := blockPathToBody(block.Body, body); ok {
+			return append([]int{i}, path...), true
+		}
+	}
+	return nil, false
+}
+
+func indexOfToken(tokens hclwrite.Tokens, token *hclwrite.Token) int {
+	for i, t := range tokens {
+		if t == token {
+			return i
+		}
+	}
+	return -1
+}
+
+// textEditForChange returns a single edit which changes the source
+// to the new one, replacing only the part which differs
+func textEditForChange(filename string, src, newSrc []byte) (lang.TextEdit, bool) {
+	maxLen := len(src)
+	if len(newSrc) < maxLen {
+		maxLen = len(newSrc)
+	}
+
+	prefixLen := 0
+	for prefixLen < maxLen && src[prefixLen] == newSrc[prefixLen] {
+		prefixLen++
+	}
+	suffixLen := 0
+	for suffixLen < maxLen-prefixLen &&
+		src[len(src)-1-suffixLen] == newSrc[len(newSrc)-1-suffixLen] {
+		suffixLen++
+	}
+
+	// avoid splitting multi-byte characters
+	for prefixLen > 0 && prefixLen < len(src) && !utf8.RuneStart(src[prefixLen]) {
+		prefixLen--
+	}
+	for suffixLen > 0 && !utf8.RuneStart(src[len(src)-suffixLen]) {
+		suffixLen--
+	}
+
+	start, err := position.ByteOffsetToPos(src, prefixLen)
+	if err != nil {
+		return lang.TextEdit{}, false
+	}
+	end, err := position.ByteOffsetToPos(src, len(src)-suffixLen)
+	if err != nil {
+		return lang.TextEdit{}, false
+	}
+
+	newText := string(newSrc[prefixLen : len(newSrc)-suffixLen])
+	return lang.TextEdit{
+		Range: hcl.Range{
+			Filename: filename,
+			Start:    start,
+			End:      end,
+		},
+		NewText: newText,
+		Snippet: escapeSnippetText(newText),
+	}, true
+}
@@ -0,0 +1,198 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var orderingSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": {
+			Labels: []*schema.LabelSchema{
+				{Name: "type"},
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"count":         {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.Number)},
+					"ami":           {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+					"instance_type": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+					"name":          {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+					"depends_on":    {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.List(cty.String))},
+				},
+				Blocks: map[string]*schema.BlockSchema{
+					"ebs":       {Body: schema.NewBodySchema()},
+					"lifecycle": {Body: schema.NewBodySchema()},
+				},
+				Ordering: &schema.Ordering{
+					First:                  []string{"count"},
+					Last:                   []string{"lifecycle", "depends_on"},
+					AlphabeticalAttributes: true,
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_ValidateFileWithFixes_ordering(t *testing.T) {
+	testCases := []struct {
+		name           string
+		cfg            string
+		expectedDetail string
+		expectedCfg    string
+	}{
+		{
+			"expected order",
+			`resource "aws_instance" {
+  count = 2
+
+  ami  = "ami-123"
+  name = "foo"
+  ebs {}
+
+  lifecycle {}
+  depends_on = []
+}
+`,
+			"",
+			"",
+		},
+		{
+			"meta-arguments",
+			`resource "aws_instance" {
+  depends_on = []
+  ami = "ami-123"
+  # instances
+  count = 2
+}
+`,
+			`"count" is expected before "depends_on"`,
+			`resource "aws_instance" {
+  # instances
+  count = 2
+  ami = "ami-123"
+  depends_on = []
+}
+`,
+		},
+		{
+			"alphabetical attributes",
+			`resource "aws_instance" {
+  name = "foo" # the name
+  ebs {
+    # nested
+  }
+
+  ami = "ami-123"
+}
+`,
+			`"ami" is expected before "name"`,
+			`resource "aws_instance" {
+  ami = "ami-123"
+  ebs {
+    # nested
+  }
+
+  name = "foo" # the name
+}
+`,
+		},
+		{
+			"last blocks",
+			`resource "aws_instance" {
+  depends_on = []
+  lifecycle {}
+}
+`,
+			`"lifecycle" is expected before "depends_on"`,
+			`resource "aws_instance" {
+  lifecycle {}
+  depends_on = []
+}
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(orderingSchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expectedDetail == "" {
+				if len(diags) > 0 {
+					t.Fatalf("expected no diagnostics, given: %#v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, given: %#v", diags)
+			}
+			if diags[0].Severity != lang.DiagHint || diags[0].Detail != tc.expectedDetail {
+				t.Fatalf("unexpected diagnostic: %#v", diags[0])
+			}
+
+			diagFixes := fixes.ForDiagnostic(diags[0])
+			if len(diagFixes) != 1 {
+				t.Fatalf("expected 1 fix, given: %#v", diagFixes)
+			}
+
+			fixedCfg := applyTextEdits(tc.cfg, diagFixes[0].Edits)
+			if fixedCfg != tc.expectedCfg {
+				t.Fatalf("unexpected config after fix:\n%s", fixedCfg)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFileWithFixes_orderingRootWithoutNewline(t *testing.T) {
+	cfg := `b = 1
+a = 2`
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"a": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.Number)},
+			"b": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.Number)},
+		},
+		Ordering: &schema.Ordering{AlphabeticalAttributes: true},
+	})
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || len(fixes) != 1 {
+		t.Fatalf("expected 1 diagnostic with fix, given: %#v, %#v", diags, fixes)
+	}
+
+	expectedCfg := `a = 2
+b = 1
+`
+	fixedCfg := applyTextEdits(cfg, fixes[0].Edits)
+	if fixedCfg != expectedCfg {
+		t.Fatalf("unexpected config after fix:\n%q", fixedCfg)
+	}
+}
@@ -0,0 +1,62 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/position"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// PosFromLSP converts LSP position within a loaded file into hcl.Pos,
+// which can be passed to any position-based method, such as CandidatesAtPos.
+func (d *Decoder) PosFromLSP(filename string, pos position.LSPPos) (hcl.Pos, error) {
+	b, err := d.bytesForFile(filename)
+	if err != nil {
+		return hcl.Pos{}, err
+	}
+
+	return position.LSPToPos(b, pos)
+}
+
+// RangeToLSP converts a range within a loaded file (e.g. range of TextEdit,
+// HoverData or SemanticToken) into LSP range.
+func (d *Decoder) RangeToLSP(rng hcl.Range) (position.LSPRange, error) {
+	b, err := d.bytesForFile(rng.Filename)
+	if err != nil {
+		return position.LSPRange{}, err
+	}
+
+	return position.RangeToLSP(b, rng)
+}
+
+// prefixFromRange returns bytes of the given range (typically
+// from the start of a token up to the cursor), excluding any
+// trailing incomplete character.
+func (d *Decoder) prefixFromRange(rng hcl.Range) []byte {
+	b, err := d.bytesForFile(rng.Filename)
+	if err != nil {
+		return []byte{}
+	}
+
+	return position.Prefix(b, rng.Start.Byte, rng.End.Byte)
+}
+
+// posForFile recalculates line and column of the given position
+// from its byte offset, which is what HCL treats as authoritative
+// when comparing positions.
+//
+// This ensures that any ranges derived from the position
+// (such as ranges of completion text edits) are consistent,
+// even if the line and column were calculated differently
+// by the caller (e.g. in UTF-16 code units).
+func (d *Decoder) posForFile(filename string, pos hcl.Pos) hcl.Pos {
+	b, err := d.bytesForFile(filename)
+	if err != nil {
+		return pos
+	}
+
+	normalizedPos, err := position.ByteOffsetToPos(b, pos.Byte)
+	if err != nil {
+		return pos
+	}
+
+	return normalizedPos
+}
@@ -0,0 +1,92 @@
+package decoder
+
+// ProgressHook represents a function which is called to report
+// progress of long-running operations processing all loaded files,
+// i.e. ValidateFiles, CollectReferenceTargets and CollectReferenceOrigins,
+// e.g. for servers to report work done progress in LSP.
+//
+// Each operation reports ProgressBegin first, followed by ProgressReport
+// for each file and ProgressEnd last, all from the calling goroutine.
+type ProgressHook func(event ProgressEvent)
+
+type ProgressEventKind uint
+
+const (
+	ProgressBegin ProgressEventKind = iota
+	ProgressReport
+	ProgressEnd
+)
+
+// ProgressEvent represents a change in progress of an operation
+type ProgressEvent struct {
+	Kind ProgressEventKind
+
+	// Title is a human-readable name of the operation,
+	// e.g. "Validating files"
+	Title string
+
+	// Message optionally describes the current step,
+	// e.g. name of the file being processed
+	Message string
+
+	// Percentage represents how much of the operation is done (0-100)
+	Percentage uint
+}
+
+// SetProgressHook sets a hook which is called to report
+// progress of long-running operations (see ProgressHook)
+func (d *Decoder) SetProgressHook(f ProgressHook) {
+	d.progressHook = f
+}
+
+// progress reports progress of an operation processing
+// the given number of files, if the hook is set
+type progress struct {
+	hook  ProgressHook
+	title string
+	total int
+}
+
+func beginProgress(hook ProgressHook, title string, total int) *progress {
+	p := &progress{
+		hook:  hook,
+		title: title,
+		total: total,
+	}
+	if hook != nil {
+		hook(ProgressEvent{
+			Kind:  ProgressBegin,
+			Title: title,
+		})
+	}
+	return p
+}
+
+// report reports that the given number of files is done
+// and the named one is being processed
+func (p *progress) report(done int, filename string) {
+	if p.hook == nil {
+		return
+	}
+	percentage := uint(100)
+	if p.total > 0 {
+		percentage = uint(done * 100 / p.total)
+	}
+	p.hook(ProgressEvent{
+		Kind:       ProgressReport,
+		Title:      p.title,
+		Message:    filename,
+		Percentage: percentage,
+	})
+}
+
+func (p *progress) end() {
+	if p.hook == nil {
+		return
+	}
+	p.hook(ProgressEvent{
+		Kind:       ProgressEnd,
+		Title:      p.title,
+		Percentage: 100,
+	})
+}
@@ -0,0 +1,60 @@
+package decoder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_SetProgressHook(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"name": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+		},
+	})
+	for _, filename := range []string{"a.tf", "b.tf"} {
+		f, _ := hclsyntax.ParseConfig([]byte(`name = "foo"`), filename, hcl.InitialPos)
+		err := d.LoadFile(filename, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events := make([]ProgressEvent, 0)
+	d.SetProgressHook(func(event ProgressEvent) {
+		events = append(events, event)
+	})
+
+	_, err := d.ValidateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = d.CollectReferenceTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// collection on demand is not reported
+	_, err = d.CandidatesAtPos("a.tf", hcl.InitialPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEvents := []ProgressEvent{
+		{Kind: ProgressBegin, Title: "Validating files"},
+		{Kind: ProgressReport, Title: "Validating files", Message: "a.tf", Percentage: 0},
+		{Kind: ProgressReport, Title: "Validating files", Message: "b.tf", Percentage: 50},
+		{Kind: ProgressEnd, Title: "Validating files", Percentage: 100},
+		{Kind: ProgressBegin, Title: "Collecting reference targets"},
+		{Kind: ProgressReport, Title: "Collecting reference targets", Message: "a.tf", Percentage: 0},
+		{Kind: ProgressReport, Title: "Collecting reference targets", Message: "b.tf", Percentage: 50},
+		{Kind: ProgressEnd, Title: "Collecting reference targets", Percentage: 100},
+	}
+	if diff := cmp.Diff(expectedEvents, events); diff != "" {
+		t.Fatalf("unexpected events: %s", diff)
+	}
+}
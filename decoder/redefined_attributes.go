@@ -0,0 +1,127 @@
+package decoder
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// redefinedAttributeSummary is the summary of diagnostics reported
+// by hclsyntax for attributes set more than once in the same body
+const redefinedAttributeSummary = "Attribute redefined"
+
+// redefinedAttribute represents a definition of an attribute
+// which the parser dropped, as it was already set in the body
+type redefinedAttribute struct {
+	body *hclsyntax.Body
+	attr *hclsyntax.Attribute
+}
+
+// LoadFileWithDiagnostics loads a new parsed file (see LoadFile)
+// along with diagnostics reported when parsing it, such that
+// the decoder can recover from some of the errors.
+//
+// Attributes set more than once in the same body are dropped
+// by the parser beyond the first definition. Such redefinitions
+// are recovered, so that e.g. their values can still be completed,
+// and the returned diagnostics are related to the first definition.
+func (d *Decoder) LoadFileWithDiagnostics(filename string, f *hcl.File, diags hcl.Diagnostics) (lang.Diagnostics, error) {
+	err := d.LoadFile(filename, f)
+	if err != nil {
+		return nil, err
+	}
+
+	related := make(relatedInfos, 0)
+
+	rootBody, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return related.diagnostics(diags), nil
+	}
+
+	redefined := make([]redefinedAttribute, 0)
+	for _, diag := range diags {
+		if diag.Summary != redefinedAttributeSummary || diag.Subject == nil ||
+			diag.Subject.Filename != filename {
+			continue
+		}
+
+		ra, ok := recoverRedefinedAttribute(rootBody, f.Bytes, *diag.Subject)
+		if !ok {
+			continue
+		}
+		redefined = append(redefined, ra)
+
+		if first, ok := ra.body.Attributes[ra.attr.Name]; ok {
+			related.add(diag, lang.DiagnosticRelatedInfo{
+				Range:   first.NameRange,
+				Message: fmt.Sprintf("%q is first set here", ra.attr.Name),
+			})
+		}
+	}
+
+	d.filesMu.Lock()
+	d.redefinedAttrs[filename] = redefined
+	d.filesMu.Unlock()
+
+	return related.diagnostics(diags), nil
+}
+
+// recoverRedefinedAttribute parses the attribute whose name
+// is in the given range, along with the body it belongs to
+func recoverRedefinedAttribute(rootBody *hclsyntax.Body, src []byte, nameRng hcl.Range) (redefinedAttribute, bool) {
+	if nameRng.Start.Byte < 0 || nameRng.End.Byte > len(src) ||
+		nameRng.Start.Byte >= nameRng.End.Byte {
+		return redefinedAttribute{}, false
+	}
+	name := string(nameRng.SliceBytes(src))
+
+	// the rest of the enclosing body is parsed along with the attribute,
+	// which is fine, as only the first item is of interest
+	f, _ := hclsyntax.ParseConfig(src[nameRng.Start.Byte:], nameRng.Filename, nameRng.Start)
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return redefinedAttribute{}, false
+	}
+	attr, ok := body.Attributes[name]
+	if !ok || attr.NameRange.Start.Byte != nameRng.Start.Byte {
+		return redefinedAttribute{}, false
+	}
+
+	return redefinedAttribute{
+		body: innermostBodyAtPos(rootBody, nameRng.Start),
+		attr: attr,
+	}, true
+}
+
+// innermostBodyAtPos returns the body of the innermost block
+// containing the given position, or the body itself
+func innermostBodyAtPos(body *hclsyntax.Body, pos hcl.Pos) *hclsyntax.Body {
+	for _, block := range body.Blocks {
+		if block.Body != nil && block.Body.Range().ContainsPos(pos) {
+			return innermostBodyAtPos(block.Body, pos)
+		}
+	}
+	return body
+}
+
+// attributesOfBody returns attributes of the body, including
+// any redefined ones recovered via LoadFileWithDiagnostics
+func (d *Decoder) attributesOfBody(body *hclsyntax.Body) []*hclsyntax.Attribute {
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+
+	d.filesMu.RLock()
+	defer d.filesMu.RUnlock()
+
+	for _, ra := range d.redefinedAttrs[body.SrcRange.Filename] {
+		if ra.body == body {
+			attrs = append(attrs, ra.attr)
+		}
+	}
+
+	return attrs
+}
@@ -0,0 +1,143 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var redefinedAttributesSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": {
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"enabled": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.Bool)},
+					"name":    {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_LoadFileWithDiagnostics_redefinedAttribute(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		cfg                  string
+		pos                  hcl.Pos
+		expectedRelatedRange hcl.Range
+		expectedCandidates   []string
+	}{
+		{
+			"value of redefined attribute",
+			`resource {
+  enabled = true
+  enabled = 
+}
+`,
+			hcl.Pos{Line: 3, Column: 13, Byte: 39},
+			hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 3, Byte: 13},
+				End:      hcl.Pos{Line: 2, Column: 10, Byte: 20},
+			},
+			[]string{"false", "true"},
+		},
+		{
+			"redefined after other attribute",
+			`resource {
+  enabled = true
+  name = "foo"
+  enabled = 
+}
+`,
+			hcl.Pos{Line: 4, Column: 13, Byte: 55},
+			hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 3, Byte: 13},
+				End:      hcl.Pos{Line: 2, Column: 10, Byte: 20},
+			},
+			[]string{"false", "true"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(redefinedAttributesSchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			diags, err := d.LoadFileWithDiagnostics("test.tf", f, pDiags)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(diags) != len(pDiags) {
+				t.Fatalf("expected %d diagnostics, given: %#v", len(pDiags), diags)
+			}
+			var redefinedDiag *lang.Diagnostic
+			for i, diag := range diags {
+				if diag.Summary == redefinedAttributeSummary {
+					redefinedDiag = &diags[i]
+				}
+			}
+			if redefinedDiag == nil {
+				t.Fatalf("expected redefined attribute diagnostic, given: %#v", pDiags)
+			}
+			if len(redefinedDiag.RelatedInfo) != 1 {
+				t.Fatalf("expected 1 related information, given: %#v", redefinedDiag.RelatedInfo)
+			}
+			if diff := cmp.Diff(tc.expectedRelatedRange, redefinedDiag.RelatedInfo[0].Range); diff != "" {
+				t.Fatalf("unexpected related range: %s", diff)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			labels := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_LoadFile_clearsRedefinedAttributes(t *testing.T) {
+	cfg := `resource {
+  enabled = true
+  enabled = 
+}
+`
+	pos := hcl.Pos{Line: 3, Column: 13, Byte: 39}
+
+	d := NewDecoder()
+	d.SetSchema(redefinedAttributesSchema)
+	f, pDiags := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	_, err := d.LoadFileWithDiagnostics("test.tf", f, pDiags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range candidates.List {
+		if c.Kind != lang.AttributeCandidateKind {
+			t.Fatalf("expected only attribute candidates, given: %#v", c)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package decoder
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// canonicalAddr returns the given address with its leading steps
+// replaced by the canonical address of a target, where they match
+// one of its aliases (see lang.ReferenceTarget.Aliases), along with
+// the target. The longest matching alias wins.
+func (refs ReferenceTargets) canonicalAddr(addr lang.Address) (lang.Address, lang.ReferenceTarget, bool) {
+	var aliasedTarget lang.ReferenceTarget
+	var matchingAlias lang.Address
+	refs.DeepWalk(func(ref lang.ReferenceTarget) error {
+		for _, alias := range ref.Aliases {
+			if len(alias) > len(addr) || len(alias) <= len(matchingAlias) {
+				continue
+			}
+			if Address(alias).Equals(Address(addr).FirstSteps(uint(len(alias)))) {
+				aliasedTarget, matchingAlias = ref, alias
+			}
+		}
+		return nil
+	})
+	if matchingAlias == nil {
+		return nil, lang.ReferenceTarget{}, false
+	}
+
+	canonical := make(lang.Address, 0, len(aliasedTarget.Addr)+len(addr)-len(matchingAlias))
+	canonical = append(canonical, aliasedTarget.Addr...)
+	canonical = append(canonical, addr[len(matchingAlias):]...)
+
+	return canonical, aliasedTarget, true
+}
+
+// validateAliasedReferences reports references via an alias
+// of the target, such as a legacy address, along with a fix
+// replacing the reference with the canonical address.
+func (d *Decoder) validateAliasedReferences(expr hcl.Expression, fixes *lang.DiagnosticFixes, related relatedInfos) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	readTargets := d.referenceTargetReader()
+	if readTargets == nil {
+		return diags
+	}
+
+	targets := ReferenceTargets(readTargets())
+
+	for _, ref := range exprReferences(expr) {
+		canonical, target, ok := targets.canonicalAddr(ref.Addr)
+		if !ok {
+			continue
+		}
+
+		canonicalText := d.addrFormat.Format(canonical)
+		diag := d.newDiagnostic(CodeNonCanonicalReference, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Non-canonical reference",
+			Detail: fmt.Sprintf("%s is an alias of %s, which should be used instead",
+				d.addrFormat.Format(ref.Addr), canonicalText),
+			Subject: ref.Range.Ptr(),
+		})
+		d.addTargetDefinition(related, diag, target)
+		diags = append(diags, diag)
+
+		if fixes != nil {
+			*fixes = append(*fixes, lang.DiagnosticFix{
+				Title:      fmt.Sprintf("Use %s", canonicalText),
+				Diagnostic: diag,
+				Edits: []lang.TextEdit{
+					{
+						Range:   ref.Range,
+						NewText: canonicalText,
+						Snippet: escapeSnippetText(canonicalText),
+					},
+				},
+			})
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,246 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var aliasesSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"value": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.TraversalExpr{OfType: cty.DynamicPseudoType},
+			},
+		},
+	},
+}
+
+var aliasesTargets = lang.ReferenceTargets{
+	{
+		Addr: lang.Address{
+			lang.RootStep{Name: "local"},
+			lang.AttrStep{Name: "conf"},
+		},
+		Aliases: []lang.Address{
+			{
+				lang.RootStep{Name: "legacy"},
+				lang.AttrStep{Name: "conf"},
+			},
+		},
+		Type: cty.Object(map[string]cty.Type{
+			"port": cty.Number,
+		}),
+		RangePtr: &hcl.Range{
+			Filename: "locals.tf",
+			Start:    hcl.Pos{Line: 2, Column: 3, Byte: 11},
+			End:      hcl.Pos{Line: 2, Column: 26, Byte: 34},
+		},
+		NestedTargets: lang.ReferenceTargets{
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "local"},
+					lang.AttrStep{Name: "conf"},
+					lang.AttrStep{Name: "port"},
+				},
+				Type: cty.Number,
+				RangePtr: &hcl.Range{
+					Filename: "locals.tf",
+					Start:    hcl.Pos{Line: 2, Column: 12, Byte: 20},
+					End:      hcl.Pos{Line: 2, Column: 24, Byte: 32},
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_ReferenceTargetForOrigin_aliases(t *testing.T) {
+	testCases := []struct {
+		name         string
+		addr         lang.Address
+		expectedAddr lang.Address
+	}{
+		{
+			"canonical address",
+			lang.Address{
+				lang.RootStep{Name: "local"},
+				lang.AttrStep{Name: "conf"},
+			},
+			lang.Address{
+				lang.RootStep{Name: "local"},
+				lang.AttrStep{Name: "conf"},
+			},
+		},
+		{
+			"alias",
+			lang.Address{
+				lang.RootStep{Name: "legacy"},
+				lang.AttrStep{Name: "conf"},
+			},
+			lang.Address{
+				lang.RootStep{Name: "local"},
+				lang.AttrStep{Name: "conf"},
+			},
+		},
+		{
+			"nested under alias",
+			lang.Address{
+				lang.RootStep{Name: "legacy"},
+				lang.AttrStep{Name: "conf"},
+				lang.AttrStep{Name: "port"},
+			},
+			lang.Address{
+				lang.RootStep{Name: "local"},
+				lang.AttrStep{Name: "conf"},
+				lang.AttrStep{Name: "port"},
+			},
+		},
+		{
+			"unknown",
+			lang.Address{
+				lang.RootStep{Name: "legacy"},
+				lang.AttrStep{Name: "other"},
+			},
+			nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, aliasesSchema, map[string]string{"test.tf": ""})
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return aliasesTargets
+			})
+
+			target, err := d.ReferenceTargetForOrigin(lang.ReferenceOrigin{
+				Addr: tc.addr,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var addr lang.Address
+			if target != nil {
+				addr = target.Addr
+			}
+			if diff := cmp.Diff(tc.expectedAddr, addr); diff != "" {
+				t.Fatalf("unexpected target: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_HoverAtPos_aliases(t *testing.T) {
+	d := newTestDecoder(t, aliasesSchema, map[string]string{"test.tf": "value = legacy.conf.port\n"})
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return aliasesTargets
+	})
+
+	data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 1, Column: 10, Byte: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedContent := lang.Markdown("`local.conf.port`\n_number_")
+	if diff := cmp.Diff(expectedContent, data.Content); diff != "" {
+		t.Fatalf("unexpected hover content: %s", diff)
+	}
+}
+
+func TestDecoder_ValidateFileWithFixes_aliases(t *testing.T) {
+	testCases := []struct {
+		name           string
+		cfg            string
+		expectedDetail string
+		expectedCfg    string
+	}{
+		{
+			"canonical address",
+			"value = local.conf\n",
+			"",
+			"",
+		},
+		{
+			"alias",
+			"value = legacy.conf\n",
+			"legacy.conf is an alias of local.conf, which should be used instead",
+			"value = local.conf\n",
+		},
+		{
+			"nested under alias",
+			"value = legacy.conf.port\n",
+			"legacy.conf.port is an alias of local.conf.port, which should be used instead",
+			"value = local.conf.port\n",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, aliasesSchema, map[string]string{"test.tf": tc.cfg})
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return aliasesTargets
+			})
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expectedDetail == "" {
+				if len(diags) > 0 {
+					t.Fatalf("expected no diagnostics, given: %#v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, given: %#v", diags)
+			}
+			if diags[0].Severity != hcl.DiagWarning || diags[0].Detail != tc.expectedDetail {
+				t.Fatalf("unexpected diagnostic: %#v", diags[0])
+			}
+
+			diagFixes := fixes.ForDiagnostic(diags[0])
+			if len(diagFixes) != 1 {
+				t.Fatalf("expected 1 fix, given: %#v", diagFixes)
+			}
+			fixedCfg := applyTextEdits(tc.cfg, diagFixes[0].Edits)
+			if fixedCfg != tc.expectedCfg {
+				t.Fatalf("unexpected config after fix:\n%s", fixedCfg)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFileWithRelatedInfo_aliases(t *testing.T) {
+	d := newTestDecoder(t, aliasesSchema, map[string]string{"test.tf": "value = legacy.conf\n"})
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return aliasesTargets
+	})
+
+	diags, err := d.ValidateFileWithRelatedInfo("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, given: %#v", diags)
+	}
+
+	if diags[0].Code != CodeNonCanonicalReference {
+		t.Fatalf("unexpected code: %q", diags[0].Code)
+	}
+	expectedRelated := []lang.DiagnosticRelatedInfo{
+		{
+			Range:   *aliasesTargets[0].RangePtr,
+			Message: "local.conf is defined here",
+		},
+	}
+	if diff := cmp.Diff(expectedRelated, diags[0].RelatedInfo); diff != "" {
+		t.Fatalf("unexpected related info: %s", diff)
+	}
+}
@@ -0,0 +1,90 @@
+package decoder
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// ReferenceCandidateOptions represents options of completion
+// of references to targets (see SetReferenceCandidateOptions)
+type ReferenceCandidateOptions struct {
+	// MaxLabelLength limits the length (in characters) of labels
+	// of reference candidates, such that long addresses (e.g. of deeply
+	// nested attributes) don't blow up the width of the completion UI.
+	//
+	// Steps in the middle of longer addresses are left out
+	// of the label, e.g. var.config…instances[0].name, while the full
+	// address is still inserted and presented in Detail.
+	// Zero means no limit.
+	MaxLabelLength uint
+
+	// MatchAnySegment enables matching of the typed prefix
+	// at any step of addresses rather than only at the beginning,
+	// e.g. such that "inst" matches var.config.instances[0].name
+	MatchAnySegment bool
+}
+
+// SetReferenceCandidateOptions sets options of completion of references
+func (d *Decoder) SetReferenceCandidateOptions(opts ReferenceCandidateOptions) {
+	d.refCandidateOpts = opts
+}
+
+// referenceMatch returns a function matching addresses of targets
+// to be completed for the given prefix
+func (d *Decoder) referenceMatch(prefix string) addressMatchFunc {
+	if !d.refCandidateOpts.MatchAnySegment {
+		return addressPrefixMatch(prefix, d.addrFormat)
+	}
+	return func(addr lang.Address) bool {
+		return addressSegmentMatch(prefix, addr, d.addrFormat)
+	}
+}
+
+// addressSegmentMatch returns true if the address (rendered in the given
+// format) starts with the prefix, or if the prefix matches
+// the address from any of its steps other than the first one
+func addressSegmentMatch(prefix string, addr lang.Address, format lang.AddressFormat) bool {
+	if strings.HasPrefix(format.Format(addr), prefix) {
+		return true
+	}
+	for i := 1; i < len(addr); i++ {
+		tail := strings.TrimPrefix(format.Format(addr[i:]), ".")
+		if strings.HasPrefix(tail, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateAddress returns the address (rendered in the given format)
+// with as many steps in the middle left out as necessary
+// to fit the given length, always keeping the first and last step
+func truncateAddress(addr lang.Address, format lang.AddressFormat, maxLen uint) string {
+	full := format.Format(addr)
+	if maxLen == 0 || uint(utf8.RuneCountInString(full)) <= maxLen || len(addr) < 3 {
+		return full
+	}
+
+	head := format.Format(addr[:1]) + "…"
+	tail := strings.TrimPrefix(format.Format(addr[len(addr)-1:]), ".")
+	for i := len(addr) - 2; i > 0; i-- {
+		longerTail := strings.TrimPrefix(format.Format(addr[i:]), ".")
+		if uint(utf8.RuneCountInString(head+longerTail)) > maxLen {
+			break
+		}
+		tail = longerTail
+	}
+
+	return head + tail
+}
+
+// withFullAddress returns the candidate whose label is truncated
+// with the full address presented in Detail and used for filtering
+func withFullAddress(candidate lang.Candidate, addr string) lang.Candidate {
+	candidate.Detail = fmt.Sprintf("%s (%s)", addr, candidate.Detail)
+	candidate.FilterText = addr
+	return candidate
+}
@@ -0,0 +1,366 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var longAddress = lang.Address{
+	lang.RootStep{Name: "var"},
+	lang.AttrStep{Name: "config"},
+	lang.AttrStep{Name: "instances"},
+	lang.IndexStep{Key: cty.NumberIntVal(0)},
+	lang.AttrStep{Name: "name"},
+}
+
+func TestTruncateAddress(t *testing.T) {
+	testCases := []struct {
+		name          string
+		addr          lang.Address
+		maxLen        uint
+		expectedLabel string
+	}{
+		{
+			"no limit",
+			longAddress,
+			0,
+			"var.config.instances[0].name",
+		},
+		{
+			"within limit",
+			longAddress,
+			28,
+			"var.config.instances[0].name",
+		},
+		{
+			"one step left out",
+			longAddress,
+			22,
+			"var…instances[0].name",
+		},
+		{
+			"index step kept",
+			longAddress,
+			12,
+			"var…[0].name",
+		},
+		{
+			"first and last step kept beyond limit",
+			longAddress,
+			4,
+			"var…name",
+		},
+		{
+			"short address beyond limit",
+			lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "configuration"},
+			},
+			4,
+			"var.configuration",
+		},
+		{
+			"quoted key",
+			lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "config"},
+				lang.IndexStep{Key: cty.StringVal("foo")},
+				lang.AttrStep{Name: "name"},
+			},
+			16,
+			`var…["foo"].name`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			label := truncateAddress(tc.addr, lang.AddressFormat{}, tc.maxLen)
+			if diff := cmp.Diff(tc.expectedLabel, label); diff != "" {
+				t.Fatalf("unexpected label: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_referenceCandidateOptions(t *testing.T) {
+	instanceType := cty.Object(map[string]cty.Type{
+		"name": cty.String,
+	})
+	targets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "region"},
+			},
+			Type: cty.String,
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "config"},
+			},
+			Type: cty.Object(map[string]cty.Type{
+				"instances": cty.Tuple([]cty.Type{instanceType}),
+			}),
+			NestedTargets: lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "config"},
+						lang.AttrStep{Name: "instances"},
+					},
+					Type: cty.Tuple([]cty.Type{instanceType}),
+					NestedTargets: lang.ReferenceTargets{
+						{
+							Addr: longAddress,
+							Type: cty.String,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		cfg                string
+		pos                hcl.Pos
+		opts               ReferenceCandidateOptions
+		expectedCandidates []lang.Candidate
+	}{
+		{
+			"no options",
+			"attr = var.\n",
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			ReferenceCandidateOptions{},
+			[]lang.Candidate{
+				{
+					Label:  "var.config",
+					Detail: "object",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "var.config",
+						Snippet: "var.config",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
+						},
+					},
+				},
+				{
+					Label:  "var.region",
+					Detail: "string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "var.region",
+						Snippet: "var.region",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
+						},
+					},
+				},
+			},
+		},
+		{
+			"label within limit",
+			"attr = var.\n",
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			ReferenceCandidateOptions{MaxLabelLength: 16},
+			[]lang.Candidate{
+				{
+					Label:  "var.config",
+					Detail: "object",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "var.config",
+						Snippet: "var.config",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
+						},
+					},
+				},
+				{
+					Label:  "var.region",
+					Detail: "string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "var.region",
+						Snippet: "var.region",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
+						},
+					},
+				},
+			},
+		},
+		{
+			"truncated label",
+			"attr = var.config.instances[0].\n",
+			hcl.Pos{Line: 1, Column: 32, Byte: 31},
+			ReferenceCandidateOptions{MaxLabelLength: 16},
+			[]lang.Candidate{
+				{
+					Label:      "var…[0].name",
+					Detail:     "var.config.instances[0].name (string)",
+					Kind:       lang.TraversalCandidateKind,
+					FilterText: "var.config.instances[0].name",
+					TextEdit: lang.TextEdit{
+						NewText: "var.config.instances[0].name",
+						Snippet: "var.config.instances[0].name",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 32, Byte: 31},
+						},
+					},
+				},
+			},
+		},
+		{
+			"prefix matching segment without option",
+			"attr = inst\n",
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			ReferenceCandidateOptions{},
+			[]lang.Candidate{},
+		},
+		{
+			"prefix matching segment",
+			"attr = inst\n",
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			ReferenceCandidateOptions{MatchAnySegment: true},
+			[]lang.Candidate{
+				{
+					Label:      "var.config.instances",
+					Detail:     "tuple",
+					Kind:       lang.TraversalCandidateKind,
+					FilterText: "inst",
+					TextEdit: lang.TextEdit{
+						NewText: "var.config.instances",
+						Snippet: "var.config.instances",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
+						},
+					},
+				},
+			},
+		},
+		{
+			"prefix matching multiple segments",
+			"attr = config.inst\n",
+			hcl.Pos{Line: 1, Column: 19, Byte: 18},
+			ReferenceCandidateOptions{MatchAnySegment: true},
+			[]lang.Candidate{
+				{
+					Label:      "var.config.instances",
+					Detail:     "tuple",
+					Kind:       lang.TraversalCandidateKind,
+					FilterText: "config.inst",
+					TextEdit: lang.TextEdit{
+						NewText: "var.config.instances",
+						Snippet: "var.config.instances",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 19, Byte: 18},
+						},
+					},
+				},
+			},
+		},
+		{
+			"prefix matching beginning",
+			"attr = var.reg\n",
+			hcl.Pos{Line: 1, Column: 15, Byte: 14},
+			ReferenceCandidateOptions{MatchAnySegment: true},
+			[]lang.Candidate{
+				{
+					Label:  "var.region",
+					Detail: "string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: "var.region",
+						Snippet: "var.region",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+						},
+					},
+				},
+			},
+		},
+		{
+			"truncated label matching segment",
+			"attr = na\n",
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			ReferenceCandidateOptions{MaxLabelLength: 16, MatchAnySegment: true},
+			[]lang.Candidate{
+				{
+					Label:      "var…[0].name",
+					Detail:     "var.config.instances[0].name (string)",
+					Kind:       lang.TraversalCandidateKind,
+					FilterText: "na",
+					TextEdit: lang.TextEdit{
+						NewText: "var.config.instances[0].name",
+						Snippet: "var.config.instances[0].name",
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"attr": {
+						IsOptional: true,
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfType: cty.String},
+						},
+					},
+				},
+			})
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return targets
+			})
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos,
+				WithReferenceCandidateOptions(tc.opts))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, candidates.List); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
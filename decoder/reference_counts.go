@@ -0,0 +1,134 @@
+package decoder
+
+import (
+	"context"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// ReferenceCountForTarget returns the number of reference origins
+// targeting the given target or any target nested within it,
+// e.g. for rendering in a code lens.
+//
+// Origins are obtained from the ReferenceOriginReader, if set,
+// or collected from loaded files otherwise.
+func (d *Decoder) ReferenceCountForTarget(ctx context.Context, target lang.ReferenceTarget) (int, error) {
+	counts, err := d.ReferenceCountsForTargets(ctx, lang.ReferenceTargets{target})
+	if err != nil {
+		return 0, err
+	}
+	return counts[0], nil
+}
+
+// ReferenceCountsForTargets returns the number of reference origins
+// targeting each of the given targets (see ReferenceCountForTarget),
+// in the same order as the targets, e.g. for detection of unused targets.
+//
+// Origins are indexed by address once for all targets, such that each
+// target is only compared against origins whose address begins
+// with the target's address (or any of its aliases), rather than
+// against all origins.
+func (d *Decoder) ReferenceCountsForTargets(ctx context.Context, targets lang.ReferenceTargets) ([]int, error) {
+	d = d.withRequestOptions(nil)
+
+	origins, err := d.referenceOrigins()
+	if err != nil {
+		return nil, err
+	}
+
+	index := newOriginAddrIndex(origins)
+
+	counts := make([]int, len(targets))
+	for i, target := range targets {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		targetTree := ReferenceTargets{target}
+		for _, origin := range index.originsWithinAnyAddr(targetAddrs(target)) {
+			if d.isOriginTargetingAny(targetTree, origin) {
+				counts[i]++
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// isOriginTargetingAny returns true if the origin targets any of the
+// targets (including nested ones), or an element of a target of
+// collection type, such as aws_instance.web[0].id
+func (d *Decoder) isOriginTargetingAny(targets ReferenceTargets, origin lang.ReferenceOrigin) bool {
+	targeted := false
+	targets.DeepWalk(func(ref lang.ReferenceTarget) error {
+		if ReferenceTarget(ref).IsTargetableBy(origin) {
+			targeted = true
+			return StopWalking
+		}
+		return nil
+	})
+	if targeted {
+		return true
+	}
+
+	if targets.containsAddr(origin.Addr) {
+		return false
+	}
+	synthesized, ok := d.synthesizedTargetForAddr(targets, origin.Addr)
+	return ok && ReferenceTarget(synthesized).IsTargetableBy(origin)
+}
+
+// originAddrIndex indexes reference origins by each
+// of the leading parts of their address
+type originAddrIndex map[string]lang.ReferenceOrigins
+
+func newOriginAddrIndex(origins lang.ReferenceOrigins) originAddrIndex {
+	index := make(originAddrIndex, 0)
+	origins.Iter(func(origin lang.ReferenceOrigin) bool {
+		key := ""
+		for _, step := range origin.Addr {
+			key += step.String()
+			index[key] = append(index[key], origin)
+		}
+		return true
+	})
+	return index
+}
+
+// targetAddrs returns the address of the target along with
+// any aliases, i.e. all addresses origins may refer to it by
+func targetAddrs(target lang.ReferenceTarget) []lang.Address {
+	addrs := make([]lang.Address, 0, len(target.Aliases)+1)
+	addrs = append(addrs, target.Addr)
+	return append(addrs, target.Aliases...)
+}
+
+// originsWithinAnyAddr returns origins whose address begins with
+// any of the given addresses, each origin at most once
+func (idx originAddrIndex) originsWithinAnyAddr(addrs []lang.Address) lang.ReferenceOrigins {
+	if len(addrs) == 1 {
+		return idx.originsWithinAddr(addrs[0])
+	}
+
+	origins := make(lang.ReferenceOrigins, 0)
+	seen := make(map[string]bool, 0)
+	for _, addr := range addrs {
+		for _, origin := range idx.originsWithinAddr(addr) {
+			key := origin.Range.String() + origin.Addr.String()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// originsWithinAddr returns origins whose address begins with the given address
+func (idx originAddrIndex) originsWithinAddr(addr lang.Address) lang.ReferenceOrigins {
+	if len(addr) == 0 {
+		return lang.ReferenceOrigins{}
+	}
+	return idx[addr.String()]
+}
@@ -0,0 +1,146 @@
+package decoder
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_ReferenceCountsForTargets(t *testing.T) {
+	targets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "foo"},
+			},
+			Type: cty.String,
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "list"},
+			},
+			Type: cty.List(cty.String),
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "obj"},
+			},
+			Type: cty.Object(map[string]cty.Type{
+				"attr": cty.String,
+			}),
+			NestedTargets: lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "obj"},
+						lang.AttrStep{Name: "attr"},
+					},
+					Type: cty.String,
+				},
+			},
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "unused"},
+			},
+			Type: cty.String,
+		},
+	}
+	originAt := func(line int, addr ...lang.AddressStep) lang.ReferenceOrigin {
+		return lang.ReferenceOrigin{
+			Addr: addr,
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: line, Column: 1, Byte: 0},
+				End:      hcl.Pos{Line: line, Column: 2, Byte: 1},
+			},
+		}
+	}
+	origins := lang.ReferenceOrigins{
+		originAt(1, lang.RootStep{Name: "var"}, lang.AttrStep{Name: "foo"}),
+		originAt(2, lang.RootStep{Name: "var"}, lang.AttrStep{Name: "foo"}),
+		originAt(3, lang.RootStep{Name: "var"}, lang.AttrStep{Name: "foobar"}),
+		originAt(4, lang.RootStep{Name: "var"}, lang.AttrStep{Name: "list"},
+			lang.IndexStep{Key: cty.NumberIntVal(0)}),
+		originAt(5, lang.RootStep{Name: "var"}, lang.AttrStep{Name: "obj"}),
+		originAt(6, lang.RootStep{Name: "var"}, lang.AttrStep{Name: "obj"},
+			lang.AttrStep{Name: "attr"}),
+		originAt(7, lang.RootStep{Name: "local"}, lang.AttrStep{Name: "foo"}),
+	}
+
+	d := NewDecoder()
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return targets
+	})
+	d.SetReferenceOriginReader(func() lang.ReferenceOrigins {
+		return origins
+	})
+
+	counts, err := d.ReferenceCountsForTargets(context.Background(), targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCounts := []int{2, 1, 2, 0}
+	if diff := cmp.Diff(expectedCounts, counts); diff != "" {
+		t.Fatalf("unexpected counts: %s", diff)
+	}
+
+	count, err := d.ReferenceCountForTarget(context.Background(), targets[2].NestedTargets[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("unexpected count of nested target: %d", count)
+	}
+
+	aliasedTarget := lang.ReferenceTarget{
+		Addr: lang.Address{
+			lang.RootStep{Name: "var"},
+			lang.AttrStep{Name: "foo"},
+		},
+		Aliases: []lang.Address{
+			{
+				lang.RootStep{Name: "legacy"},
+				lang.AttrStep{Name: "foo"},
+			},
+			{
+				lang.RootStep{Name: "legacy"},
+				lang.AttrStep{Name: "foo"},
+			},
+			{
+				lang.RootStep{Name: "var"},
+			},
+		},
+		Type: cty.String,
+	}
+	aliasedOrigins := lang.ReferenceOrigins{
+		originAt(1, lang.RootStep{Name: "var"}, lang.AttrStep{Name: "foo"}),
+		originAt(2, lang.RootStep{Name: "legacy"}, lang.AttrStep{Name: "foo"}),
+		originAt(3, lang.RootStep{Name: "legacy"}, lang.AttrStep{Name: "bar"}),
+	}
+	d.SetReferenceOriginReader(func() lang.ReferenceOrigins {
+		return aliasedOrigins
+	})
+	count, err = d.ReferenceCountForTarget(context.Background(), aliasedTarget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("unexpected count of aliased target: %d", count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = d.ReferenceCountsForTargets(ctx, targets)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, given: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+var iterTargets = lang.ReferenceTargets{
+	{
+		Addr:     lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "one"}},
+		ScopeId:  lang.ScopeId("variable"),
+		RangePtr: &hcl.Range{Filename: "first.tf"},
+		NestedTargets: lang.ReferenceTargets{
+			{
+				Addr:     lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "one"}, lang.AttrStep{Name: "nested"}},
+				ScopeId:  lang.ScopeId("variable"),
+				RangePtr: &hcl.Range{Filename: "first.tf"},
+			},
+		},
+	},
+	{
+		Addr:     lang.Address{lang.RootStep{Name: "local"}, lang.AttrStep{Name: "two"}},
+		ScopeId:  lang.ScopeId("local"),
+		RangePtr: &hcl.Range{Filename: "second.tf"},
+	},
+	{
+		Addr:    lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "three"}},
+		ScopeId: lang.ScopeId("variable"),
+	},
+}
+
+func TestReferenceTargets_Iter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		iter          func(f func(lang.ReferenceTarget) bool) bool
+		stopAfter     int
+		expectedAddrs []string
+		expectedAll   bool
+	}{
+		{
+			"all",
+			iterTargets.Iter,
+			0,
+			[]string{"var.one", "var.one.nested", "local.two", "var.three"},
+			true,
+		},
+		{
+			"stop within nested targets",
+			iterTargets.Iter,
+			2,
+			[]string{"var.one", "var.one.nested"},
+			false,
+		},
+		{
+			"in file",
+			func(f func(lang.ReferenceTarget) bool) bool {
+				return iterTargets.IterInFile("first.tf", f)
+			},
+			0,
+			[]string{"var.one", "var.one.nested"},
+			true,
+		},
+		{
+			"in scope",
+			func(f func(lang.ReferenceTarget) bool) bool {
+				return iterTargets.IterInScope(lang.ScopeId("variable"), f)
+			},
+			0,
+			[]string{"var.one", "var.one.nested", "var.three"},
+			true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			addrs := make([]string, 0)
+			all := tc.iter(func(ref lang.ReferenceTarget) bool {
+				addrs = append(addrs, ref.Addr.String())
+				return tc.stopAfter == 0 || len(addrs) < tc.stopAfter
+			})
+			if diff := cmp.Diff(tc.expectedAddrs, addrs); diff != "" {
+				t.Fatalf("unexpected targets: %s", diff)
+			}
+			if all != tc.expectedAll {
+				t.Fatalf("expected all visited: %t, given: %t", tc.expectedAll, all)
+			}
+		})
+	}
+}
+
+func TestReferenceTargets_DeepWalk_stopInNested(t *testing.T) {
+	addrs := make([]string, 0)
+	ReferenceTargets(iterTargets).DeepWalk(func(ref lang.ReferenceTarget) error {
+		addrs = append(addrs, ref.Addr.String())
+		if len(ref.Addr) == 3 {
+			return StopWalking
+		}
+		return nil
+	})
+
+	expectedAddrs := []string{"var.one", "var.one.nested"}
+	if diff := cmp.Diff(expectedAddrs, addrs); diff != "" {
+		t.Fatalf("unexpected targets: %s", diff)
+	}
+}
+
+func TestReferenceOrigins_IterInFile(t *testing.T) {
+	origins := lang.ReferenceOrigins{
+		{
+			Addr:  lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "one"}},
+			Range: hcl.Range{Filename: "first.tf"},
+		},
+		{
+			Addr:  lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "two"}},
+			Range: hcl.Range{Filename: "second.tf"},
+		},
+		{
+			Addr:  lang.Address{lang.RootStep{Name: "var"}, lang.AttrStep{Name: "three"}},
+			Range: hcl.Range{Filename: "first.tf"},
+		},
+	}
+
+	addrs := make([]string, 0)
+	all := origins.IterInFile("first.tf", func(origin lang.ReferenceOrigin) bool {
+		addrs = append(addrs, origin.Addr.String())
+		return true
+	})
+	expectedAddrs := []string{"var.one", "var.three"}
+	if diff := cmp.Diff(expectedAddrs, addrs); diff != "" {
+		t.Fatalf("unexpected origins: %s", diff)
+	}
+	if !all {
+		t.Fatal("expected all origins to be visited")
+	}
+
+	addrs = make([]string, 0)
+	all = origins.Iter(func(origin lang.ReferenceOrigin) bool {
+		addrs = append(addrs, origin.Addr.String())
+		return false
+	})
+	expectedAddrs = []string{"var.one"}
+	if diff := cmp.Diff(expectedAddrs, addrs); diff != "" {
+		t.Fatalf("unexpected origins: %s", diff)
+	}
+	if all {
+		t.Fatal("expected iteration to stop")
+	}
+}
@@ -11,13 +11,12 @@ import (
 
 // ReferenceOriginAtPos returns the ReferenceOrigin
 // enclosing the position in a file, if one exists, else nil
-func (d *Decoder) ReferenceOriginAtPos(filename string, pos hcl.Pos) (*lang.ReferenceOrigin, error) {
-	f, err := d.fileByName(filename)
-	if err != nil {
-		return nil, err
-	}
+func (d *Decoder) ReferenceOriginAtPos(filename string, pos hcl.Pos, opts ...RequestOption) (*lang.ReferenceOrigin, error) {
+	d = d.withRequestOptions(opts)
+
+	pos = d.posForFile(filename, pos)
 
-	rootBody, err := d.bodyForFileAndPos(filename, f, pos)
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
 	if err != nil {
 		return nil, err
 	}
@@ -25,17 +24,65 @@ func (d *Decoder) ReferenceOriginAtPos(filename string, pos hcl.Pos) (*lang.Refe
 	return d.referenceOriginAtPos(rootBody, pos)
 }
 
+// ReferenceOriginsHook represents a function which is called for every
+// block with known schema when collecting reference origins. It can return
+// origins which are implied rather than expressed in the configuration,
+// e.g. a reference from a resource to a provider derived from the resource type.
+type ReferenceOriginsHook func(block *hcl.Block, blockSchema *schema.BlockSchema) lang.ReferenceOrigins
+
+// SetReferenceOriginsHook sets a hook to contribute synthetic origins,
+// which are collected along with all other origins by CollectReferenceOrigins.
+//
+// Synthetic origins are marked via IsSynthetic and never renamed.
+func (d *Decoder) SetReferenceOriginsHook(f ReferenceOriginsHook) {
+	d.originsHook = f
+}
+
+func (d *Decoder) syntheticOriginsForBlock(block *hcl.Block, blockSchema *schema.BlockSchema) lang.ReferenceOrigins {
+	if d.originsHook == nil {
+		return lang.ReferenceOrigins{}
+	}
+
+	origins := d.originsHook(block, blockSchema)
+	for i := range origins {
+		origins[i].IsSynthetic = true
+	}
+	return origins
+}
+
 func (d *Decoder) ReferenceOriginsTargeting(refTarget lang.ReferenceTarget) (lang.ReferenceOrigins, error) {
-	if d.refOriginReader == nil {
+	readOrigins := d.referenceOriginReader()
+	if readOrigins == nil {
 		return nil, nil
 	}
 
-	allOrigins := ReferenceOrigins(d.refOriginReader())
+	allOrigins := readOrigins()
+	origins := ReferenceOrigins(allOrigins).Targeting(refTarget)
+
+	// include origins referring to elements of the target,
+	// such as aws_instance.web[0].id or aws_instance.web[*].id
+	targets := ReferenceTargets{refTarget}
+	allOrigins.Iter(func(origin lang.ReferenceOrigin) bool {
+		if targets.containsAddr(origin.Addr) {
+			return true
+		}
+		synthesized, ok := d.synthesizedTargetForAddr(targets, origin.Addr)
+		if ok && ReferenceTarget(synthesized).IsTargetableBy(origin) {
+			origins = append(origins, origin)
+		}
+		return true
+	})
 
-	return allOrigins.Targeting(refTarget), nil
+	return origins, nil
 }
 
+// CollectReferenceOrigins returns reference origins of all loaded files,
+// reporting progress via the hook, if any (see SetProgressHook)
 func (d *Decoder) CollectReferenceOrigins() (lang.ReferenceOrigins, error) {
+	return d.collectReferenceOrigins(d.progressHook)
+}
+
+func (d *Decoder) collectReferenceOrigins(hook ProgressHook) (lang.ReferenceOrigins, error) {
 	refOrigins := make(lang.ReferenceOrigins, 0)
 
 	d.rootSchemaMu.RLock()
@@ -46,20 +93,19 @@ func (d *Decoder) CollectReferenceOrigins() (lang.ReferenceOrigins, error) {
 	}
 
 	files := d.Filenames()
-	for _, filename := range files {
-		f, err := d.fileByName(filename)
+	p := beginProgress(hook, "Collecting reference origins", len(files))
+	defer p.end()
+	for i, filename := range files {
+		p.report(i, filename)
+		segments, err := d.segmentsForFile(filename)
 		if err != nil {
 			// skip unparseable file
 			continue
 		}
 
-		body, ok := f.Body.(*hclsyntax.Body)
-		if !ok {
-			// skip JSON or other body format
-			continue
+		for _, segment := range segments {
+			refOrigins = append(refOrigins, d.referenceOriginsInGenericBody(segment.Body, d.rootSchema)...)
 		}
-
-		refOrigins = append(refOrigins, d.referenceOriginsInBody(body, d.rootSchema)...)
 	}
 
 	sort.SliceStable(refOrigins, func(i, j int) bool {
@@ -70,6 +116,33 @@ func (d *Decoder) CollectReferenceOrigins() (lang.ReferenceOrigins, error) {
 	return refOrigins, nil
 }
 
+// CollectReferenceOriginsInFile returns reference origins
+// of the given file, e.g. for incremental indexing of files
+// as they change (see CollectReferenceOrigins)
+func (d *Decoder) CollectReferenceOriginsInFile(filename string) (lang.ReferenceOrigins, error) {
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	segments, err := d.segmentsForFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	refOrigins := make(lang.ReferenceOrigins, 0)
+	for _, segment := range segments {
+		refOrigins = append(refOrigins, d.referenceOriginsInGenericBody(segment.Body, d.rootSchema)...)
+	}
+
+	sort.SliceStable(refOrigins, func(i, j int) bool {
+		return refOrigins[i].Range.Start.Byte < refOrigins[j].Range.Start.Byte
+	})
+
+	return refOrigins, nil
+}
+
 func (d *Decoder) referenceOriginsInBody(body *hclsyntax.Body, bodySchema *schema.BodySchema) lang.ReferenceOrigins {
 	origins := make(lang.ReferenceOrigins, 0)
 
@@ -78,53 +151,147 @@ func (d *Decoder) referenceOriginsInBody(body *hclsyntax.Body, bodySchema *schem
 	}
 
 	for _, attr := range body.Attributes {
-		aSchema, ok := bodySchema.Attributes[attr.Name]
-		if !ok {
-			if bodySchema.AnyAttribute == nil {
-				// skip unknown attribute
-				continue
-			}
-			aSchema = bodySchema.AnyAttribute
-		}
-
-		te, ok := ExprConstraints(aSchema.Expr).TraversalExpr()
+		aSchema, ok := attributeSchemaForName(bodySchema, attr.Name)
 		if !ok {
+			// skip unknown attribute
 			continue
 		}
-		for _, traversal := range attr.Expr.Variables() {
-			origin, err := TraversalToReferenceOrigin(traversal, te)
-			if err != nil {
-				continue
-			}
 
-			origins = append(origins, origin)
-		}
+		origins = append(origins, referenceOriginsInExpr(attr.Expr, ExprConstraints(aSchema.Expr))...)
 	}
 
 	for _, block := range body.Blocks {
 		if block.Body != nil {
-			bSchema, ok := bodySchema.Blocks[block.Type]
+			bSchema, ok := blockSchemaForType(bodySchema, block.Type)
 			if !ok {
 				// skip unknown blocks
 				continue
 			}
 			origins = append(origins, d.referenceOriginsInBody(block.Body, bSchema.Body)...)
+			origins = append(origins, d.syntheticOriginsForBlock(block.AsHCLBlock(), bSchema)...)
 		}
 	}
 
 	return origins
 }
 
+// referenceOriginsInExpr returns origins of references within
+// the expression, which are expected to conform to the traversal
+// constraint, if any. References within object keys are unrelated
+// to the constraint and are collected regardless of it.
+func referenceOriginsInExpr(expr hcl.Expression, ec ExprConstraints) lang.ReferenceOrigins {
+	origins := referenceOriginsInObjectKeys(expr)
+
+	te, ok := ec.TraversalExpr()
+	if !ok {
+		return origins
+	}
+
+	keyRanges := make(map[hcl.Range]bool, len(origins))
+	for _, origin := range origins {
+		keyRanges[origin.Range] = true
+	}
+	for _, ref := range exprReferences(expr) {
+		if keyRanges[ref.Range] {
+			continue
+		}
+		origins = append(origins, ref.originForConstraint(te))
+	}
+
+	return origins
+}
+
+// referenceOriginsInObjectKeys returns origins for traversals
+// within object keys which are not literals, such as (var.name)
+// or "${var.prefix}-name", in the native syntax as well as in JSON.
+//
+// Such keys cannot be interpreted without further context, so the
+// address of the item itself remains abstract, but the key expression
+// may still refer to other targets.
+func referenceOriginsInObjectKeys(expr hcl.Expression) lang.ReferenceOrigins {
+	origins := make(lang.ReferenceOrigins, 0)
+
+	var traversals []hcl.Traversal
+	if hsExpr, ok := expr.(hclsyntax.Expression); ok {
+		traversals = syntaxObjectKeyVariables(hsExpr)
+	} else {
+		traversals = genericObjectKeyVariables(expr)
+	}
+
+	for _, traversal := range traversals {
+		addr, err := lang.TraversalToAddress(traversal)
+		if err != nil {
+			continue
+		}
+		origins = append(origins, lang.ReferenceOrigin{
+			Addr:  addr,
+			Range: traversal.SourceRange(),
+		})
+	}
+
+	return origins
+}
+
+func syntaxObjectKeyVariables(expr hclsyntax.Expression) []hcl.Traversal {
+	traversals := make([]hcl.Traversal, 0)
+	hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		objExpr, ok := node.(*hclsyntax.ObjectConsExpr)
+		if !ok {
+			return nil
+		}
+		for _, item := range objExpr.Items {
+			traversals = append(traversals, objectKeyVariables(item.KeyExpr)...)
+		}
+		return nil
+	})
+	return traversals
+}
+
+func objectKeyVariables(keyExpr hclsyntax.Expression) []hcl.Traversal {
+	ke, ok := keyExpr.(*hclsyntax.ObjectConsKeyExpr)
+	if !ok {
+		return keyExpr.Variables()
+	}
+	if _, ok := ke.Wrapped.(*hclsyntax.ScopeTraversalExpr); ok && !ke.ForceNonLiteral {
+		// naked key is interpreted as a literal
+		return nil
+	}
+	return ke.Wrapped.Variables()
+}
+
+// genericObjectKeyVariables returns traversals within object keys
+// of expressions of other implementations (e.g. JSON), where keys
+// are strings which may contain template sequences ("${var.prefix}-name")
+func genericObjectKeyVariables(expr hcl.Expression) []hcl.Traversal {
+	traversals := make([]hcl.Traversal, 0)
+
+	items, diags := hcl.ExprMap(expr)
+	if !diags.HasErrors() {
+		for _, item := range items {
+			traversals = append(traversals, item.Key.Variables()...)
+			traversals = append(traversals, genericObjectKeyVariables(item.Value)...)
+		}
+		return traversals
+	}
+
+	elems, diags := hcl.ExprList(expr)
+	if !diags.HasErrors() {
+		for _, elem := range elems {
+			traversals = append(traversals, genericObjectKeyVariables(elem)...)
+		}
+	}
+
+	return traversals
+}
+
 func (d *Decoder) referenceOriginAtPos(body *hclsyntax.Body, pos hcl.Pos) (*lang.ReferenceOrigin, error) {
 	for _, attr := range body.Attributes {
 		if d.isPosInsideAttrExpr(attr, pos) {
-			traversal, ok := d.traversalAtPos(attr.Expr, pos)
-			if ok {
-				addr, err := lang.TraversalToAddress(traversal)
-				if err == nil {
+			for _, ref := range exprReferences(attr.Expr) {
+				if ref.Range.ContainsPos(pos) {
 					return &lang.ReferenceOrigin{
-						Addr:  addr,
-						Range: traversal.SourceRange(),
+						Addr:  ref.Addr,
+						Range: ref.Range,
 					}, nil
 				}
 			}
@@ -144,32 +311,20 @@ func (d *Decoder) referenceOriginAtPos(body *hclsyntax.Body, pos hcl.Pos) (*lang
 	return nil, nil
 }
 
-func (d *Decoder) traversalAtPos(expr hclsyntax.Expression, pos hcl.Pos) (hcl.Traversal, bool) {
-	for _, traversal := range expr.Variables() {
-		if traversal.SourceRange().ContainsPos(pos) {
-			return traversal, true
-		}
-	}
-
-	return nil, false
-}
-
 type ReferenceOrigins lang.ReferenceOrigins
 
 func (ro ReferenceOrigins) Targeting(refTarget lang.ReferenceTarget) lang.ReferenceOrigins {
 	origins := make(lang.ReferenceOrigins, 0)
 
-	target := ReferenceTarget(refTarget)
-
-	for _, refOrigin := range ro {
-		if target.IsTargetableBy(refOrigin) {
-			origins = append(origins, refOrigin)
-		}
-	}
-
-	for _, iTarget := range refTarget.NestedTargets {
-		origins = append(origins, ro.Targeting(iTarget)...)
-	}
+	lang.ReferenceTargets{refTarget}.Iter(func(target lang.ReferenceTarget) bool {
+		lang.ReferenceOrigins(ro).Iter(func(refOrigin lang.ReferenceOrigin) bool {
+			if ReferenceTarget(target).IsTargetableBy(refOrigin) {
+				origins = append(origins, refOrigin)
+			}
+			return true
+		})
+		return true
+	})
 
 	return origins
 }
@@ -181,9 +336,10 @@ func TraversalToReferenceOrigin(traversal hcl.Traversal, te schema.TraversalExpr
 	}
 
 	return lang.ReferenceOrigin{
-		Addr:      addr,
-		Range:     traversal.SourceRange(),
-		OfScopeId: te.OfScopeId,
-		OfType:    te.OfType,
+		Addr:       addr,
+		Range:      traversal.SourceRange(),
+		OfScopeId:  te.OfScopeId,
+		OfScopeIds: te.OfScopeIds,
+		OfType:     te.OfType,
 	}, nil
 }
@@ -2,6 +2,7 @@ package decoder
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -534,6 +535,137 @@ attr3 = onestep`,
 				},
 			},
 		},
+		{
+			"non-literal object keys",
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"tags": {
+						Expr: schema.LiteralTypeOnly(cty.Map(cty.String)),
+					},
+				},
+			},
+			`tags = {
+  (var.name) = "x"
+  "${var.prefix}-foo" = "y"
+  bare = "z"
+}
+`,
+			lang.ReferenceOrigins{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "name"},
+					},
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start: hcl.Pos{
+							Line:   2,
+							Column: 4,
+							Byte:   12,
+						},
+						End: hcl.Pos{
+							Line:   2,
+							Column: 12,
+							Byte:   20,
+						},
+					},
+				},
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "prefix"},
+					},
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start: hcl.Pos{
+							Line:   3,
+							Column: 6,
+							Byte:   33,
+						},
+						End: hcl.Pos{
+							Line:   3,
+							Column: 16,
+							Byte:   43,
+						},
+					},
+				},
+			},
+		},
+		{
+			"splat expression",
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"attr": {
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfType: cty.List(cty.String)},
+						},
+					},
+				},
+			},
+			`attr = aws_instance.web[*].id`,
+			lang.ReferenceOrigins{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+						lang.SplatStep{},
+						lang.AttrStep{Name: "id"},
+					},
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start: hcl.Pos{
+							Line:   1,
+							Column: 8,
+							Byte:   7,
+						},
+						End: hcl.Pos{
+							Line:   1,
+							Column: 30,
+							Byte:   29,
+						},
+					},
+					OfType: cty.List(cty.String),
+				},
+			},
+		},
+		{
+			"object keys alongside traversal",
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"tags": {
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfType: cty.Map(cty.String)},
+							schema.LiteralTypeExpr{Type: cty.Map(cty.String)},
+						},
+					},
+				},
+			},
+			`tags = {
+  (var.name) = "x"
+}
+`,
+			lang.ReferenceOrigins{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "name"},
+					},
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start: hcl.Pos{
+							Line:   2,
+							Column: 4,
+							Byte:   12,
+						},
+						End: hcl.Pos{
+							Line:   2,
+							Column: 12,
+							Byte:   20,
+						},
+					},
+				},
+			},
+		},
 	}
 	for i, tc := range testCases {
 		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
@@ -558,6 +690,55 @@ attr3 = onestep`,
 	}
 }
 
+func TestCollectReferenceOrigins_jsonObjectKeys(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"tags": {
+				Expr: schema.LiteralTypeOnly(cty.Map(cty.String)),
+			},
+		},
+	}
+	d := newTestDecoder(t, bodySchema, map[string]string{
+		"test.tf.json": `{
+  "tags": {
+    "${var.prefix}-foo": "x",
+    "bare": "y"
+  }
+}
+`,
+	})
+
+	origins, err := d.CollectReferenceOrigins()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedOrigins := lang.ReferenceOrigins{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "prefix"},
+			},
+			Range: hcl.Range{
+				Filename: "test.tf.json",
+				Start: hcl.Pos{
+					Line:   3,
+					Column: 8,
+					Byte:   21,
+				},
+				End: hcl.Pos{
+					Line:   3,
+					Column: 18,
+					Byte:   31,
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedOrigins, origins, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("mismatched reference origins: %s", diff)
+	}
+}
+
 func TestReferenceOriginsTargeting(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -728,6 +909,92 @@ func TestReferenceOriginsTargeting(t *testing.T) {
 				},
 			},
 		},
+		{
+			"elements of collection target",
+			lang.ReferenceOrigins{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+						lang.IndexStep{Key: cty.NumberIntVal(0)},
+						lang.AttrStep{Name: "id"},
+					},
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 23, Byte: 22},
+					},
+					OfType: cty.String,
+				},
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+						lang.SplatStep{},
+						lang.AttrStep{Name: "id"},
+					},
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 24, Byte: 23},
+					},
+					OfType: cty.List(cty.String),
+				},
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+						lang.SplatStep{},
+						lang.AttrStep{Name: "id"},
+					},
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 24, Byte: 23},
+					},
+					OfType: cty.Number,
+				},
+			},
+			lang.ReferenceTarget{
+				Addr: lang.Address{
+					lang.RootStep{Name: "aws_instance"},
+					lang.AttrStep{Name: "web"},
+				},
+				Type: cty.List(cty.Object(map[string]cty.Type{
+					"id": cty.String,
+				})),
+			},
+			lang.ReferenceOrigins{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+						lang.IndexStep{Key: cty.NumberIntVal(0)},
+						lang.AttrStep{Name: "id"},
+					},
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 23, Byte: 22},
+					},
+					OfType: cty.String,
+				},
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+						lang.SplatStep{},
+						lang.AttrStep{Name: "id"},
+					},
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 24, Byte: 23},
+					},
+					OfType: cty.List(cty.String),
+				},
+			},
+		},
 	}
 	for i, tc := range testCases {
 		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
@@ -746,3 +1013,98 @@ func TestReferenceOriginsTargeting(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectReferenceOrigins_hook(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"provider": {
+				Labels: []*schema.LabelSchema{
+					{Name: "name"},
+				},
+				Address: &schema.BlockAddrSchema{
+					Steps: []schema.AddrStep{
+						schema.StaticStep{Name: "provider"},
+						schema.LabelStep{Index: 0},
+					},
+					AsReference: true,
+				},
+			},
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type"},
+					{Name: "name"},
+				},
+				Body: &schema.BodySchema{},
+			},
+		},
+	}
+	cfg := `provider "aws" {}
+resource "aws_instance" "web" {}
+`
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.SetReferenceOriginsHook(func(block *hcl.Block, blockSchema *schema.BlockSchema) lang.ReferenceOrigins {
+		if block.Type != "resource" {
+			return nil
+		}
+		provider := strings.SplitN(block.Labels[0], "_", 2)[0]
+		return lang.ReferenceOrigins{
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "provider"},
+					lang.AttrStep{Name: provider},
+				},
+				Range: block.LabelRanges[0],
+			},
+		}
+	})
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	origins, err := d.CollectReferenceOrigins()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedOrigins := lang.ReferenceOrigins{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "provider"},
+				lang.AttrStep{Name: "aws"},
+			},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 10, Byte: 27},
+				End:      hcl.Pos{Line: 2, Column: 24, Byte: 41},
+			},
+			IsSynthetic: true,
+		},
+	}
+	if diff := cmp.Diff(expectedOrigins, origins, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected origins: %s", diff)
+	}
+
+	d.SetReferenceOriginReader(func() lang.ReferenceOrigins {
+		return origins
+	})
+	targeting, err := d.ReferenceOriginsTargeting(lang.ReferenceTarget{
+		Addr: lang.Address{
+			lang.RootStep{Name: "provider"},
+			lang.AttrStep{Name: "aws"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expectedOrigins, targeting, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected targeting origins: %s", diff)
+	}
+}
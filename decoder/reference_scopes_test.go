@@ -0,0 +1,214 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func scopedBlockSchema(scopeId lang.ScopeId) *schema.BlockSchema {
+	return &schema.BlockSchema{
+		Labels: []*schema.LabelSchema{
+			{Name: "name"},
+		},
+		Address: &schema.BlockAddrSchema{
+			Steps: []schema.AddrStep{
+				schema.LabelStep{Index: 0},
+			},
+			ScopeId:     scopeId,
+			AsReference: true,
+		},
+		Body: &schema.BodySchema{},
+	}
+}
+
+var referenceScopesSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": scopedBlockSchema(lang.ScopeId("resource")),
+		"data":     scopedBlockSchema(lang.ScopeId("data")),
+		"module":   scopedBlockSchema(lang.ScopeId("module")),
+	},
+	Attributes: map[string]*schema.AttributeSchema{
+		"depends_on": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.TraversalExpr{
+					OfScopeId:  lang.ScopeId("resource"),
+					OfScopeIds: []lang.ScopeId{"data"},
+				},
+			},
+		},
+		"any": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.TraversalExpr{},
+			},
+		},
+	},
+}
+
+const referenceScopesCfg = `resource "foo" {}
+data "bar" {}
+module "baz" {}
+`
+
+func TestReferenceTargets_FirstTargetableBy_scopePriority(t *testing.T) {
+	targets := ReferenceTargets{
+		{
+			Addr:    lang.Address{lang.RootStep{Name: "foo"}},
+			ScopeId: lang.ScopeId("resource"),
+		},
+		{
+			Addr:    lang.Address{lang.RootStep{Name: "foo"}},
+			ScopeId: lang.ScopeId("data"),
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		origin          lang.ReferenceOrigin
+		expectedScopeId lang.ScopeId
+		expectFound     bool
+	}{
+		{
+			"any scope",
+			lang.ReferenceOrigin{
+				Addr: lang.Address{lang.RootStep{Name: "foo"}},
+			},
+			lang.ScopeId("resource"),
+			true,
+		},
+		{
+			"scope of lower priority declared first",
+			lang.ReferenceOrigin{
+				Addr:       lang.Address{lang.RootStep{Name: "foo"}},
+				OfScopeId:  lang.ScopeId("data"),
+				OfScopeIds: []lang.ScopeId{"resource"},
+			},
+			lang.ScopeId("data"),
+			true,
+		},
+		{
+			"alternative scopes only",
+			lang.ReferenceOrigin{
+				Addr:       lang.Address{lang.RootStep{Name: "foo"}},
+				OfScopeIds: []lang.ScopeId{"module", "data"},
+			},
+			lang.ScopeId("data"),
+			true,
+		},
+		{
+			"no matching scope",
+			lang.ReferenceOrigin{
+				Addr:       lang.Address{lang.RootStep{Name: "foo"}},
+				OfScopeId:  lang.ScopeId("module"),
+				OfScopeIds: []lang.ScopeId{"provider"},
+			},
+			"",
+			false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			target, err := targets.FirstTargetableBy(tc.origin)
+			if !tc.expectFound {
+				if err == nil {
+					t.Fatalf("expected no target, %#v found", target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedScopeId, target.ScopeId); diff != "" {
+				t.Fatalf("unexpected target scope: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFile_referenceScopes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"primary scope",
+			"depends_on = foo\n",
+			hcl.Diagnostics{},
+		},
+		{
+			"alternative scope",
+			"depends_on = bar\n",
+			hcl.Diagnostics{},
+		},
+		{
+			"unknown target",
+			"depends_on = unknown\n",
+			hcl.Diagnostics{},
+		},
+		{
+			"any scope",
+			"any = baz\n",
+			hcl.Diagnostics{},
+		},
+		{
+			"unexpected scope",
+			"depends_on = baz\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid reference scope",
+					Detail:   `baz is of scope "module", expected "resource" or "data"`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 14, Byte: 13},
+						End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(referenceScopesSchema)
+
+			files := map[string]string{
+				"blocks.tf": referenceScopesCfg,
+				"test.tf":   tc.cfg,
+			}
+			for filename, src := range files {
+				f, _ := hclsyntax.ParseConfig([]byte(src), filename, hcl.InitialPos)
+				err := d.LoadFile(filename, f)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			targets, err := d.CollectReferenceTargets()
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return targets
+			})
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
@@ -18,13 +18,14 @@ import (
 // ReferenceTargetForOrigin returns the first ReferenceTarget
 // with matching ReferenceOrigin Address, if one exists, else nil
 func (d *Decoder) ReferenceTargetForOrigin(refOrigin lang.ReferenceOrigin) (*lang.ReferenceTarget, error) {
-	if d.refTargetReader == nil {
+	readTargets := d.referenceTargetReader()
+	if readTargets == nil {
 		return nil, nil
 	}
 
-	allTargets := ReferenceTargets(d.refTargetReader())
+	allTargets := ReferenceTargets(readTargets())
 
-	ref, err := allTargets.FirstTargetableBy(refOrigin)
+	ref, err := d.referenceTargetForOrigin(allTargets, refOrigin)
 	if err != nil {
 		if _, ok := err.(*NoRefTargetFound); ok {
 			return nil, nil
@@ -36,11 +37,12 @@ func (d *Decoder) ReferenceTargetForOrigin(refOrigin lang.ReferenceOrigin) (*lan
 }
 
 func (d *Decoder) OutermostReferenceTargetAtPos(file string, pos hcl.Pos) (*lang.ReferenceTarget, error) {
-	if d.refTargetReader == nil {
+	readTargets := d.referenceTargetReader()
+	if readTargets == nil {
 		return nil, nil
 	}
 
-	allTargets := ReferenceTargets(d.refTargetReader())
+	allTargets := ReferenceTargets(readTargets())
 
 	for _, target := range allTargets {
 		if target.RangePtr == nil {
@@ -58,35 +60,30 @@ func (d *Decoder) OutermostReferenceTargetAtPos(file string, pos hcl.Pos) (*lang
 }
 
 func (d *Decoder) InnermostReferenceTargetAtPos(file string, pos hcl.Pos) (*lang.ReferenceTarget, error) {
-	if d.refTargetReader == nil {
+	readTargets := d.referenceTargetReader()
+	if readTargets == nil {
 		return nil, nil
 	}
 
-	target, _ := d.innermostReferenceTargetAtPos(d.refTargetReader(), file, pos)
+	target, _ := d.innermostReferenceTargetAtPos(readTargets(), file, pos)
 
 	return target, nil
 }
 
 func (d *Decoder) innermostReferenceTargetAtPos(targets lang.ReferenceTargets, file string, pos hcl.Pos) (*lang.ReferenceTarget, bool) {
-	allTargets := ReferenceTargets(targets)
-
-	matchingTargets := make(lang.ReferenceTargets, 0)
+	var innermostTarget *lang.ReferenceTarget
 
-	for _, target := range allTargets {
+	for _, target := range targets {
 		if target.RangePtr == nil {
 			continue
 		}
 		if target.RangePtr.Filename != file {
 			continue
 		}
-		if target.RangePtr.ContainsPos(pos) {
-			matchingTargets = append(matchingTargets, target)
+		if !target.RangePtr.ContainsPos(pos) {
+			continue
 		}
-	}
-
-	var innermostTarget *lang.ReferenceTarget
 
-	for _, target := range matchingTargets {
 		nestedTarget, ok := d.innermostReferenceTargetAtPos(target.NestedTargets, file, pos)
 		if ok {
 			innermostTarget = nestedTarget
@@ -102,13 +99,34 @@ func (d *Decoder) innermostReferenceTargetAtPos(targets lang.ReferenceTargets, f
 type ReferenceTarget lang.ReferenceTarget
 
 func (ref ReferenceTarget) MatchesConstraint(te schema.TraversalExpr) bool {
-	return ref.MatchesScopeId(te.OfScopeId) && ref.ConformsToType(te.OfType)
+	return ref.MatchesAnyScopeId(te.ScopeIds()) && ref.ConformsToType(te.OfType)
 }
 
 func (ref ReferenceTarget) MatchesScopeId(scopeId lang.ScopeId) bool {
 	return scopeId == "" || ref.ScopeId == scopeId
 }
 
+// MatchesAnyScopeId returns true if the target is of any of the given
+// scopes, or if no scopes are given (i.e. any scope is acceptable)
+func (ref ReferenceTarget) MatchesAnyScopeId(scopeIds []lang.ScopeId) bool {
+	_, ok := ref.scopePriority(scopeIds)
+	return ok
+}
+
+// scopePriority returns the position of the target's scope among
+// the given scopes, where lower values represent higher priority
+func (ref ReferenceTarget) scopePriority(scopeIds []lang.ScopeId) (int, bool) {
+	if len(scopeIds) == 0 {
+		return 0, true
+	}
+	for i, scopeId := range scopeIds {
+		if ref.ScopeId == scopeId {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
 func (ref ReferenceTarget) ConformsToType(typ cty.Type) bool {
 	conformsToType := false
 	if typ != cty.NilType && ref.Type != cty.NilType {
@@ -121,23 +139,37 @@ func (ref ReferenceTarget) ConformsToType(typ cty.Type) bool {
 }
 
 func (target ReferenceTarget) IsTargetableBy(origin lang.ReferenceOrigin) bool {
-	if len(target.Addr) > len(origin.Addr) {
+	if target.isTargetableByAddr(target.Addr, origin) {
+		return true
+	}
+	for _, alias := range target.Aliases {
+		if target.isTargetableByAddr(alias, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTargetableByAddr reports whether the target
+// is targetable by the origin under the given address
+func (target ReferenceTarget) isTargetableByAddr(targetAddr lang.Address, origin lang.ReferenceOrigin) bool {
+	if len(targetAddr) > len(origin.Addr) {
 		return false
 	}
 
-	if !target.MatchesScopeId(origin.OfScopeId) {
+	if !target.MatchesAnyScopeId(origin.ScopeIds()) {
 		return false
 	}
 
 	originAddr := Address(origin.Addr)
 
 	if target.Type == cty.DynamicPseudoType {
-		originAddr = Address(origin.Addr).FirstSteps(uint(len(target.Addr)))
+		originAddr = Address(origin.Addr).FirstSteps(uint(len(targetAddr)))
 	} else if origin.OfType != cty.NilType && !target.ConformsToType(origin.OfType) {
 		return false
 	}
 
-	return Address(target.Addr).Equals(originAddr)
+	return Address(targetAddr).Equals(originAddr)
 }
 
 type ReferenceTargets lang.ReferenceTargets
@@ -147,41 +179,81 @@ type RefTargetWalkFunc func(lang.ReferenceTarget) error
 var StopWalking error = errors.New("stop walking")
 
 func (refs ReferenceTargets) DeepWalk(f RefTargetWalkFunc) {
-	for _, ref := range refs {
-		err := f(ref)
-		if err == StopWalking {
-			return
-		}
+	lang.ReferenceTargets(refs).Iter(func(ref lang.ReferenceTarget) bool {
+		return f(ref) != StopWalking
+	})
+}
 
-		if len(ref.NestedTargets) > 0 {
-			irefs := ReferenceTargets(ref.NestedTargets)
-			irefs.DeepWalk(f)
-		}
+func (refs ReferenceTargets) MatchWalk(te schema.TraversalExpr, prefix string, f RefTargetWalkFunc) {
+	refs.matchWalk(te, addressPrefixMatch(prefix, lang.AddressFormat{}), f)
+}
+
+// addressMatchFunc represents a function which determines
+// whether an address matches (e.g. the typed prefix)
+type addressMatchFunc func(addr lang.Address) bool
+
+// addressPrefixMatch returns a function matching addresses
+// which (rendered in the given format) start with the given prefix
+func addressPrefixMatch(prefix string, format lang.AddressFormat) addressMatchFunc {
+	return func(addr lang.Address) bool {
+		return strings.HasPrefix(format.Format(addr), prefix)
 	}
 }
 
-func (refs ReferenceTargets) MatchWalk(te schema.TraversalExpr, prefix string, f RefTargetWalkFunc) {
+// matchWalk walks targets matching the constraint,
+// whose addresses are matched by the given function.
+// Abstract targets (see isAbstractAddress) cannot be
+// referenced as such and are never matched.
+func (refs ReferenceTargets) matchWalk(te schema.TraversalExpr, match addressMatchFunc, f RefTargetWalkFunc) {
 	for _, ref := range refs {
-		if strings.HasPrefix(ref.Addr.String(), string(prefix)) {
-			nestedMatches := ReferenceTargets(ref.NestedTargets).ContainsMatch(te, prefix)
+		if isAbstractAddress(ref.Addr) {
+			continue
+		}
+		if match(ref.Addr) {
+			nestedMatches := ReferenceTargets(ref.NestedTargets).containsMatch(te, match)
 			if ReferenceTarget(ref).MatchesConstraint(te) || nestedMatches {
 				f(ref)
 				continue
 			}
 		}
 
-		ReferenceTargets(ref.NestedTargets).MatchWalk(te, prefix, f)
+		ReferenceTargets(ref.NestedTargets).matchWalk(te, match, f)
+	}
+}
+
+// scopeMatchWalk walks targets matching the constraint by scope only,
+// i.e. regardless of type, whose addresses are matched
+// by the given function
+func (refs ReferenceTargets) scopeMatchWalk(te schema.TraversalExpr, match addressMatchFunc, f RefTargetWalkFunc) {
+	scopeIds := te.ScopeIds()
+	for _, ref := range refs {
+		if isAbstractAddress(ref.Addr) {
+			continue
+		}
+		if len(scopeIds) > 0 && match(ref.Addr) &&
+			ReferenceTarget(ref).MatchesAnyScopeId(scopeIds) {
+			f(ref)
+			continue
+		}
+
+		ReferenceTargets(ref.NestedTargets).scopeMatchWalk(te, match, f)
 	}
 }
 
 func (refs ReferenceTargets) ContainsMatch(te schema.TraversalExpr, prefix string) bool {
+	return refs.containsMatch(te, addressPrefixMatch(prefix, lang.AddressFormat{}))
+}
+
+func (refs ReferenceTargets) containsMatch(te schema.TraversalExpr, match addressMatchFunc) bool {
 	for _, ref := range refs {
-		if strings.HasPrefix(ref.Addr.String(), string(prefix)) &&
-			ReferenceTarget(ref).MatchesConstraint(te) {
+		if isAbstractAddress(ref.Addr) {
+			continue
+		}
+		if match(ref.Addr) && ReferenceTarget(ref).MatchesConstraint(te) {
 			return true
 		}
 		if len(ref.NestedTargets) > 0 {
-			if match := ReferenceTargets(ref.NestedTargets).ContainsMatch(te, prefix); match {
+			if match := ReferenceTargets(ref.NestedTargets).containsMatch(te, match); match {
 				return true
 			}
 		}
@@ -189,12 +261,52 @@ func (refs ReferenceTargets) ContainsMatch(te schema.TraversalExpr, prefix strin
 	return false
 }
 
+// FirstTargetableBy returns first target targetable by the origin.
+//
+// Where the origin accepts more than one scope, targets of the scope
+// of the highest priority are preferred over the first target found.
 func (refs ReferenceTargets) FirstTargetableBy(origin lang.ReferenceOrigin) (lang.ReferenceTarget, error) {
+	return refs.firstByScopePriority(origin.ScopeIds(), func(ref lang.ReferenceTarget) bool {
+		return ReferenceTarget(ref).IsTargetableBy(origin)
+	})
+}
+
+// FirstScopeMatchFor returns first type-less target matching
+// the address and scope of the origin, regardless of the type
+// the origin may require.
+//
+// This is useful where the target cannot be matched by type,
+// because it is addressable only as a type-less reference.
+func (refs ReferenceTargets) FirstScopeMatchFor(origin lang.ReferenceOrigin) (lang.ReferenceTarget, error) {
+	scopeIds := origin.ScopeIds()
+	if len(scopeIds) == 0 {
+		return lang.ReferenceTarget{}, &NoRefTargetFound{}
+	}
+
+	return refs.firstByScopePriority(scopeIds, func(ref lang.ReferenceTarget) bool {
+		return ref.Type == cty.NilType &&
+			ReferenceTarget(ref).MatchesAnyScopeId(scopeIds) &&
+			Address(ref.Addr).Equals(Address(origin.Addr))
+	})
+}
+
+// firstByScopePriority returns the first target matched by f
+// of the scope with the highest priority among the given scopes
+func (refs ReferenceTargets) firstByScopePriority(scopeIds []lang.ScopeId, f func(lang.ReferenceTarget) bool) (lang.ReferenceTarget, error) {
 	var matchingReference *lang.ReferenceTarget
+	matchingPriority := 0
 
 	refs.DeepWalk(func(ref lang.ReferenceTarget) error {
-		if ReferenceTarget(ref).IsTargetableBy(origin) {
+		if !f(ref) {
+			return nil
+		}
+		priority, _ := ReferenceTarget(ref).scopePriority(scopeIds)
+		if matchingReference == nil || priority < matchingPriority {
 			matchingReference = &ref
+			matchingPriority = priority
+		}
+		if priority == 0 {
+			// no other target can take priority
 			return StopWalking
 		}
 		return nil
@@ -226,7 +338,13 @@ func (a Address) FirstSteps(steps uint) Address {
 	return a[0:steps]
 }
 
+// CollectReferenceTargets returns reference targets of all loaded files,
+// reporting progress via the hook, if any (see SetProgressHook)
 func (d *Decoder) CollectReferenceTargets() (lang.ReferenceTargets, error) {
+	return d.collectReferenceTargets(d.progressHook)
+}
+
+func (d *Decoder) collectReferenceTargets(hook ProgressHook) (lang.ReferenceTargets, error) {
 	d.rootSchemaMu.RLock()
 	defer d.rootSchemaMu.RUnlock()
 	if d.rootSchema == nil {
@@ -236,20 +354,42 @@ func (d *Decoder) CollectReferenceTargets() (lang.ReferenceTargets, error) {
 
 	refs := make(lang.ReferenceTargets, 0)
 	files := d.Filenames()
-	for _, filename := range files {
-		f, err := d.fileByName(filename)
+	p := beginProgress(hook, "Collecting reference targets", len(files))
+	defer p.end()
+	for i, filename := range files {
+		p.report(i, filename)
+		segments, err := d.segmentsForFile(filename)
 		if err != nil {
 			// skip unparseable file
 			continue
 		}
 
-		body, ok := f.Body.(*hclsyntax.Body)
-		if !ok {
-			// skip JSON or other body format
-			continue
+		for _, segment := range segments {
+			refs = append(refs, d.decodeReferenceTargetsForGenericBody(segment.Body, d.rootSchema)...)
 		}
+	}
+
+	return refs, nil
+}
 
-		refs = append(refs, d.decodeReferenceTargetsForBody(body, d.rootSchema)...)
+// CollectReferenceTargetsInFile returns reference targets
+// of the given file, e.g. for incremental indexing of files
+// as they change (see CollectReferenceTargets)
+func (d *Decoder) CollectReferenceTargetsInFile(filename string) (lang.ReferenceTargets, error) {
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	segments, err := d.segmentsForFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(lang.ReferenceTargets, 0)
+	for _, segment := range segments {
+		refs = append(refs, d.decodeReferenceTargetsForGenericBody(segment.Body, d.rootSchema)...)
 	}
 
 	return refs, nil
@@ -263,20 +403,17 @@ func (d *Decoder) decodeReferenceTargetsForBody(body *hclsyntax.Body, bodySchema
 	}
 
 	for _, attr := range body.Attributes {
-		attrSchema, ok := bodySchema.Attributes[attr.Name]
+		attrSchema, ok := attributeSchemaForName(bodySchema, attr.Name)
 		if !ok {
-			if bodySchema.AnyAttribute == nil {
-				// unknown attribute (no schema)
-				continue
-			}
-			attrSchema = bodySchema.AnyAttribute
+			// unknown attribute (no schema)
+			continue
 		}
 
 		refs = append(refs, decodeReferenceTargetsForAttribute(attr, attrSchema)...)
 	}
 
 	for _, block := range body.Blocks {
-		bSchema, ok := bodySchema.Blocks[block.Type]
+		bSchema, ok := blockSchemaForType(bodySchema, block.Type)
 		if !ok {
 			// unknown block (no schema)
 			continue
@@ -291,12 +428,16 @@ func (d *Decoder) decodeReferenceTargetsForBody(body *hclsyntax.Body, bodySchema
 			continue
 		}
 
+		// targets of the block itself start here
+		blockRefsIdx := len(refs)
+
 		if bSchema.Address.AsReference {
 			ref := lang.ReferenceTarget{
-				Addr:     addr,
-				ScopeId:  bSchema.Address.ScopeId,
-				RangePtr: block.Range().Ptr(),
-				Name:     bSchema.Address.FriendlyName,
+				Addr:        addr,
+				ScopeId:     bSchema.Address.ScopeId,
+				RangePtr:    block.Range().Ptr(),
+				Name:        bSchema.Address.FriendlyName,
+				Description: descriptionForBlockTarget(bSchema),
 			}
 			refs = append(refs, ref)
 		}
@@ -309,13 +450,10 @@ func (d *Decoder) decodeReferenceTargetsForBody(body *hclsyntax.Body, bodySchema
 
 		if bSchema.Address.BodyAsData {
 			bodyRef = lang.ReferenceTarget{
-				Addr:     addr,
-				ScopeId:  bSchema.Address.ScopeId,
-				RangePtr: block.Range().Ptr(),
-			}
-
-			if bSchema.Body != nil {
-				bodyRef.Description = bSchema.Body.Description
+				Addr:        addr,
+				ScopeId:     bSchema.Address.ScopeId,
+				RangePtr:    block.Range().Ptr(),
+				Description: descriptionForBlockTarget(bSchema),
 			}
 
 			if bSchema.Address.InferBody && bSchema.Body != nil {
@@ -331,9 +469,10 @@ func (d *Decoder) decodeReferenceTargetsForBody(body *hclsyntax.Body, bodySchema
 		if bSchema.Address.DependentBodyAsData {
 			if !bSchema.Address.BodyAsData {
 				bodyRef = lang.ReferenceTarget{
-					Addr:     addr,
-					ScopeId:  bSchema.Address.ScopeId,
-					RangePtr: block.Range().Ptr(),
+					Addr:        addr,
+					ScopeId:     bSchema.Address.ScopeId,
+					RangePtr:    block.Range().Ptr(),
+					Description: descriptionForBlockTarget(bSchema),
 				}
 			}
 
@@ -343,6 +482,9 @@ func (d *Decoder) decodeReferenceTargetsForBody(body *hclsyntax.Body, bodySchema
 				if bSchema.Address.BodyAsData {
 					mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
 					if err != nil {
+						if bSchema.Address.Sensitive {
+							markSensitive(refs[blockRefsIdx:])
+						}
 						continue
 					}
 					bodyRef.NestedTargets = make(lang.ReferenceTargets, 0)
@@ -351,7 +493,7 @@ func (d *Decoder) decodeReferenceTargetsForBody(body *hclsyntax.Body, bodySchema
 
 				bodyRef.Type = bodyToDataType(bSchema.Type, fullSchema)
 
-				if bSchema.Address.InferDependentBody && len(bSchema.DependentBody) > 0 {
+				if bSchema.Address.InferDependentBody && hasDependentBody(bSchema) {
 					bodyRef.NestedTargets = append(bodyRef.NestedTargets,
 						d.collectInferredReferenceTargetsForBody(addr, bSchema.Address.ScopeId, block.Body, fullSchema)...)
 				}
@@ -363,6 +505,10 @@ func (d *Decoder) decodeReferenceTargetsForBody(body *hclsyntax.Body, bodySchema
 		}
 
 		sort.Sort(bodyRef.NestedTargets)
+
+		if bSchema.Address.Sensitive {
+			markSensitive(refs[blockRefsIdx:])
+		}
 	}
 
 	sort.Sort(refs)
@@ -370,17 +516,36 @@ func (d *Decoder) decodeReferenceTargetsForBody(body *hclsyntax.Body, bodySchema
 	return refs
 }
 
+// markWriteOnly marks the targets and all their nested targets as write-only
+func markWriteOnly(refs lang.ReferenceTargets) {
+	for i := range refs {
+		refs[i].WriteOnly = true
+		markWriteOnly(refs[i].NestedTargets)
+	}
+}
+
+// markSensitive marks the targets and all their nested targets as sensitive
+func markSensitive(refs lang.ReferenceTargets) {
+	for i := range refs {
+		refs[i].Sensitive = true
+		markSensitive(refs[i].NestedTargets)
+	}
+}
+
 func decodeReferenceTargetsForAttribute(attr *hclsyntax.Attribute, attrSchema *schema.AttributeSchema) lang.ReferenceTargets {
 	refs := make(lang.ReferenceTargets, 0)
 
-	attrAddr, ok := resolveAttributeAddress(attr, attrSchema.Address)
+	attrAddr, ok := resolveAttributeAddress(attr.Name, attrSchema.Address)
 	if ok {
 		if attrSchema.Address.AsReference {
 			ref := lang.ReferenceTarget{
-				Addr:     attrAddr,
-				ScopeId:  attrSchema.Address.ScopeId,
-				RangePtr: attr.SrcRange.Ptr(),
-				Name:     attrSchema.Address.FriendlyName,
+				Addr:        attrAddr,
+				ScopeId:     attrSchema.Address.ScopeId,
+				RangePtr:    attr.SrcRange.Ptr(),
+				Name:        attrSchema.Address.FriendlyName,
+				Description: attrSchema.Description,
+				Sensitive:   attrSchema.Address.Sensitive,
+				WriteOnly:   attrSchema.IsWriteOnly,
 			}
 			refs = append(refs, ref)
 		}
@@ -399,11 +564,12 @@ func decodeReferenceTargetsForAttribute(attr *hclsyntax.Attribute, attrSchema *s
 				scopeId := attrSchema.Address.ScopeId
 
 				ref := lang.ReferenceTarget{
-					Addr:     attrAddr,
-					Type:     t,
-					ScopeId:  scopeId,
-					RangePtr: attr.SrcRange.Ptr(),
-					Name:     attrSchema.Address.FriendlyName,
+					Addr:        attrAddr,
+					Type:        t,
+					ScopeId:     scopeId,
+					RangePtr:    attr.SrcRange.Ptr(),
+					Name:        attrSchema.Address.FriendlyName,
+					Description: attrSchema.Description,
 				}
 
 				if attr.Expr != nil && !t.IsPrimitiveType() {
@@ -411,6 +577,15 @@ func decodeReferenceTargetsForAttribute(attr *hclsyntax.Attribute, attrSchema *s
 					ref.NestedTargets = append(ref.NestedTargets, decodeReferenceTargetsForComplexTypeExpr(attrAddr, attr.Expr, t, scopeId)...)
 				}
 
+				if attrSchema.Address.Sensitive {
+					ref.Sensitive = true
+					markSensitive(ref.NestedTargets)
+				}
+				if attrSchema.IsWriteOnly {
+					ref.WriteOnly = true
+					markWriteOnly(ref.NestedTargets)
+				}
+
 				refs = append(refs, ref)
 			}
 		}
@@ -481,19 +656,20 @@ func decodeReferenceTargetsForComplexTypeExpr(addr lang.Address, expr hclsyntax.
 		}
 		if t.IsMapType() {
 			for _, item := range e.Items {
-				key, _ := item.KeyExpr.Value(nil)
-				if key.IsNull() || !key.IsWhollyKnown() || key.Type() != cty.String {
-					// skip items keys that can't be interpolated
-					// without further context
-					continue
-				}
 				elemTypePtr := t.MapElementType()
 				if elemTypePtr == nil {
 					continue
 				}
 				elemType := *elemTypePtr
 
-				elemAddr := append(addr.Copy(), lang.IndexStep{Key: key})
+				// keys that can't be interpolated without further
+				// context, such as (var.name), leave the address
+				// of the element abstract (any element of the map)
+				elemAddr := append(addr.Copy(), lang.SplatStep{})
+				key, _ := item.KeyExpr.Value(nil)
+				if !key.IsNull() && key.IsWhollyKnown() && key.Type() == cty.String {
+					elemAddr = append(addr.Copy(), lang.IndexStep{Key: key})
+				}
 				rng := hcl.RangeBetween(item.KeyExpr.Range(), item.ValueExpr.Range())
 
 				ref := lang.ReferenceTarget{
@@ -517,14 +693,11 @@ func decodeReferenceTargetsForComplexTypeExpr(addr lang.Address, expr hclsyntax.
 
 func referenceAsTypeOf(block *hclsyntax.Block, bSchema *schema.BlockSchema, addr lang.Address) lang.ReferenceTargets {
 	ref := lang.ReferenceTarget{
-		Addr:     addr,
-		ScopeId:  bSchema.Address.ScopeId,
-		RangePtr: block.Range().Ptr(),
-		Type:     cty.DynamicPseudoType,
-	}
-
-	if bSchema.Body != nil {
-		ref.Description = bSchema.Body.Description
+		Addr:        addr,
+		ScopeId:     bSchema.Address.ScopeId,
+		RangePtr:    block.Range().Ptr(),
+		Type:        cty.DynamicPseudoType,
+		Description: descriptionForBlockTarget(bSchema),
 	}
 
 	attrs, diags := block.Body.JustAttributes()
@@ -561,6 +734,16 @@ func referenceAsTypeOf(block *hclsyntax.Block, bSchema *schema.BlockSchema, addr
 	return lang.ReferenceTargets{ref}
 }
 
+// descriptionForBlockTarget returns description of the block
+// to be used for reference targets (e.g. in hover), preferring
+// the description of the body, which is typically more specific.
+func descriptionForBlockTarget(bSchema *schema.BlockSchema) lang.MarkupContent {
+	if bSchema.Body != nil && bSchema.Body.Description.Value != "" {
+		return bSchema.Body.Description
+	}
+	return bSchema.Description
+}
+
 func asTypeOfAttrExpr(attrs hcl.Attributes, bSchema *schema.BlockSchema) (cty.Type, bool) {
 	attrName := bSchema.Address.AsTypeOf.AttributeExpr
 	attr, ok := attrs[attrName]
@@ -784,6 +967,11 @@ func (d *Decoder) collectInferredReferenceTargetsForBody(addr lang.Address, scop
 			ref.NestedTargets = append(ref.NestedTargets, decodeReferenceTargetsForComplexTypeExpr(attrAddr, attrExpr, attrType, scopeId)...)
 		}
 
+		if aSchema.IsWriteOnly {
+			ref.WriteOnly = true
+			markWriteOnly(ref.NestedTargets)
+		}
+
 		refs = append(refs, ref)
 	}
 
@@ -1053,7 +1241,7 @@ func bodyToDataType(blockType schema.BlockType, body *schema.BodySchema) cty.Typ
 	return cty.Object(bodySchemaAsAttrTypes(body))
 }
 
-func resolveAttributeAddress(attr *hclsyntax.Attribute, addr *schema.AttributeAddrSchema) (lang.Address, bool) {
+func resolveAttributeAddress(attrName string, addr *schema.AttributeAddrSchema) (lang.Address, bool) {
 	address := make(lang.Address, 0)
 
 	if addr == nil {
@@ -1068,7 +1256,7 @@ func resolveAttributeAddress(attr *hclsyntax.Attribute, addr *schema.AttributeAd
 		case schema.StaticStep:
 			stepName = step.Name
 		case schema.AttrNameStep:
-			stepName = attr.Name
+			stepName = attrName
 		// TODO: AttrValueStep? Currently no use case for it
 		default:
 			// unknown step
@@ -1090,6 +1278,10 @@ func resolveAttributeAddress(attr *hclsyntax.Attribute, addr *schema.AttributeAd
 }
 
 func resolveBlockAddress(block *hclsyntax.Block, addr *schema.BlockAddrSchema) (lang.Address, bool) {
+	return resolveBlockContentAddress(syntaxBlock{block}, addr)
+}
+
+func resolveBlockContentAddress(block blockContent, addr *schema.BlockAddrSchema) (lang.Address, bool) {
 	address := make(lang.Address, 0)
 
 	if addr == nil {
@@ -1104,13 +1296,13 @@ func resolveBlockAddress(block *hclsyntax.Block, addr *schema.BlockAddrSchema) (
 		case schema.StaticStep:
 			stepName = step.Name
 		case schema.LabelStep:
-			if uint(len(block.Labels)-1) < step.Index {
+			if step.Index >= uint(len(block.labels())) {
 				// label not present
 				return lang.Address{}, false
 			}
-			stepName = block.Labels[step.Index]
+			stepName = block.labels()[step.Index]
 		case schema.AttrValueStep:
-			attr, ok := block.Body.Attributes[step.Name]
+			expr, ok := block.attributeExpr(step.Name)
 			if !ok && step.IsOptional {
 				// skip step if not found and optional
 				continue
@@ -1120,11 +1312,11 @@ func resolveBlockAddress(block *hclsyntax.Block, addr *schema.BlockAddrSchema) (
 				// attribute not present
 				return lang.Address{}, false
 			}
-			if attr.Expr == nil {
+			if expr == nil {
 				// empty attribute
 				return lang.Address{}, false
 			}
-			val, _ := attr.Expr.Value(nil)
+			val, _ := expr.Value(nil)
 			if !val.IsWhollyKnown() {
 				// unknown value
 				return lang.Address{}, false
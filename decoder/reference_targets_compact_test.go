@@ -0,0 +1,112 @@
+package decoder
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestReferenceTargets_Compact(t *testing.T) {
+	refs := generateReferenceTargets(10)
+
+	compacted := refs.Compact()
+	if diff := cmp.Diff(refs, compacted, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("compacted targets differ: %s", diff)
+	}
+
+	// appending to nested targets must not overwrite siblings
+	nested := compacted[0].NestedTargets
+	_ = append(nested, lang.ReferenceTarget{Name: "appended"})
+	if diff := cmp.Diff(refs, compacted, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("append to nested targets affected other targets: %s", diff)
+	}
+
+	if lang.ReferenceTargets(nil).Compact() != nil {
+		t.Fatal("expected nil targets to remain nil")
+	}
+}
+
+func BenchmarkReferenceTargets_memory(b *testing.B) {
+	b.Run("original", func(b *testing.B) {
+		benchmarkRetainedTargets(b, func(refs lang.ReferenceTargets) lang.ReferenceTargets {
+			return refs
+		})
+	})
+	b.Run("compact", func(b *testing.B) {
+		benchmarkRetainedTargets(b, func(refs lang.ReferenceTargets) lang.ReferenceTargets {
+			return refs.Compact()
+		})
+	})
+}
+
+func benchmarkRetainedTargets(b *testing.B, f func(lang.ReferenceTargets) lang.ReferenceTargets) {
+	const targetCount = 100000
+
+	var retained int64
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		refs := f(generateReferenceTargets(targetCount))
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(refs)
+
+		retained += int64(after.HeapAlloc) - int64(before.HeapAlloc)
+	}
+
+	b.ReportMetric(float64(retained)/float64(b.N)/targetCount, "B/target")
+}
+
+// generateReferenceTargets generates targets as if decoded
+// e.g. from a cache, i.e. with no strings shared between targets
+func generateReferenceTargets(count int) lang.ReferenceTargets {
+	refs := make(lang.ReferenceTargets, count)
+	for i := range refs {
+		filename := string([]byte(fmt.Sprintf("%s.tf", "main")))
+		addr := lang.Address{
+			lang.RootStep{Name: "aws_instance"},
+			lang.AttrStep{Name: fmt.Sprintf("web%d", i)},
+		}
+		refs[i] = lang.ReferenceTarget{
+			Addr:    addr,
+			ScopeId: lang.ScopeId(string([]byte("resource"))),
+			RangePtr: &hcl.Range{
+				Filename: filename,
+				Start:    hcl.Pos{Line: i + 1, Column: 1, Byte: i * 10},
+				End:      hcl.Pos{Line: i + 1, Column: 10, Byte: i*10 + 9},
+			},
+			Type: cty.Object(map[string]cty.Type{
+				"id": cty.String,
+			}),
+			Aliases: []lang.Address{
+				{
+					lang.RootStep{Name: "legacy_instance"},
+					lang.AttrStep{Name: fmt.Sprintf("web%d", i)},
+				},
+			},
+			NestedTargets: lang.ReferenceTargets{
+				{
+					Addr:    append(addr.Copy(), lang.AttrStep{Name: "id"}),
+					ScopeId: lang.ScopeId(string([]byte("resource"))),
+					RangePtr: &hcl.Range{
+						Filename: string([]byte(filename)),
+						Start:    hcl.Pos{Line: i + 1, Column: 3, Byte: i*10 + 2},
+						End:      hcl.Pos{Line: i + 1, Column: 5, Byte: i*10 + 4},
+					},
+					Type: cty.String,
+				},
+			},
+		}
+	}
+	return refs
+}
@@ -152,6 +152,77 @@ func TestCollectReferenceTargets_basic(t *testing.T) {
 				},
 			},
 		},
+		{
+			"root attribute as reference with description",
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"testattr": {
+						Address: &schema.AttributeAddrSchema{
+							Steps: []schema.AddrStep{
+								schema.StaticStep{Name: "special"},
+								schema.AttrNameStep{},
+							},
+							AsReference: true,
+						},
+						Description: lang.PlainText("Test attribute"),
+						IsOptional:  true,
+						Expr:        schema.LiteralTypeOnly(cty.String),
+					},
+				},
+			},
+			`testattr = "example"
+`,
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "special"},
+						lang.AttrStep{Name: "testattr"},
+					},
+					Description: lang.PlainText("Test attribute"),
+					RangePtr: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 21, Byte: 20},
+					},
+				},
+			},
+		},
+		{
+			"block as reference with description",
+			&schema.BodySchema{
+				Blocks: map[string]*schema.BlockSchema{
+					"resource": {
+						Labels: []*schema.LabelSchema{
+							{Name: "name"},
+						},
+						Address: &schema.BlockAddrSchema{
+							Steps: []schema.AddrStep{
+								schema.StaticStep{Name: "res"},
+								schema.LabelStep{Index: 0},
+							},
+							AsReference: true,
+						},
+						Description: lang.Markdown("Test _resource_"),
+					},
+				},
+			},
+			`resource "foo" {}
+`,
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "res"},
+						lang.AttrStep{Name: "foo"},
+					},
+					Description: lang.Markdown("Test _resource_"),
+					RangePtr: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 1, Column: 18, Byte: 17},
+					},
+				},
+			},
+		},
 		{
 			"root attribute as string type",
 			&schema.BodySchema{
@@ -374,6 +445,73 @@ func TestCollectReferenceTargets_basic(t *testing.T) {
 				},
 			},
 		},
+		{
+			"root attribute as map type with computed key",
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"testattr": {
+						Address: &schema.AttributeAddrSchema{
+							Steps: []schema.AddrStep{
+								schema.StaticStep{Name: "special"},
+								schema.AttrNameStep{},
+							},
+							AsExprType: true,
+						},
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.Map(cty.String)),
+					},
+				},
+			},
+			`testattr = {
+	(var.name) = "test"
+}
+`,
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "special"},
+						lang.AttrStep{Name: "testattr"},
+					},
+					Type: cty.Map(cty.String),
+					RangePtr: &hcl.Range{
+						Filename: "test.tf",
+						Start: hcl.Pos{
+							Line:   1,
+							Column: 1,
+							Byte:   0,
+						},
+						End: hcl.Pos{
+							Line:   3,
+							Column: 2,
+							Byte:   35,
+						},
+					},
+					NestedTargets: lang.ReferenceTargets{
+						{
+							Addr: lang.Address{
+								lang.RootStep{Name: "special"},
+								lang.AttrStep{Name: "testattr"},
+								lang.SplatStep{},
+							},
+							Type: cty.String,
+							RangePtr: &hcl.Range{
+								Filename: "test.tf",
+								Start: hcl.Pos{
+									Line:   2,
+									Column: 2,
+									Byte:   14,
+								},
+								End: hcl.Pos{
+									Line:   2,
+									Column: 21,
+									Byte:   33,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			"root attribute with undeclared type",
 			&schema.BodySchema{
@@ -3583,6 +3721,148 @@ func TestReferenceTargetForOrigin(t *testing.T) {
 				Type: cty.String,
 			},
 		},
+		{
+			"synthesized index target",
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+					},
+					RangePtr: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 3, Column: 2, Byte: 40},
+					},
+					Type: cty.List(cty.Object(map[string]cty.Type{
+						"id": cty.String,
+					})),
+				},
+			},
+			lang.ReferenceOrigin{
+				Addr: lang.Address{
+					lang.RootStep{Name: "aws_instance"},
+					lang.AttrStep{Name: "web"},
+					lang.IndexStep{Key: cty.NumberIntVal(0)},
+					lang.AttrStep{Name: "id"},
+				},
+				OfType: cty.String,
+			},
+			&lang.ReferenceTarget{
+				Addr: lang.Address{
+					lang.RootStep{Name: "aws_instance"},
+					lang.AttrStep{Name: "web"},
+					lang.IndexStep{Key: cty.NumberIntVal(0)},
+					lang.AttrStep{Name: "id"},
+				},
+				RangePtr: &hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+					End:      hcl.Pos{Line: 3, Column: 2, Byte: 40},
+				},
+				Type: cty.String,
+			},
+		},
+		{
+			"synthesized splat target",
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+					},
+					RangePtr: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 3, Column: 2, Byte: 40},
+					},
+					Type: cty.List(cty.Object(map[string]cty.Type{
+						"id": cty.String,
+					})),
+				},
+			},
+			lang.ReferenceOrigin{
+				Addr: lang.Address{
+					lang.RootStep{Name: "aws_instance"},
+					lang.AttrStep{Name: "web"},
+					lang.SplatStep{},
+					lang.AttrStep{Name: "id"},
+				},
+				OfType: cty.List(cty.String),
+			},
+			&lang.ReferenceTarget{
+				Addr: lang.Address{
+					lang.RootStep{Name: "aws_instance"},
+					lang.AttrStep{Name: "web"},
+					lang.SplatStep{},
+					lang.AttrStep{Name: "id"},
+				},
+				RangePtr: &hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+					End:      hcl.Pos{Line: 3, Column: 2, Byte: 40},
+				},
+				Type: cty.List(cty.String),
+			},
+		},
+		{
+			"index out of bound",
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+					},
+					RangePtr: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 3, Column: 2, Byte: 40},
+					},
+					Type: cty.List(cty.Object(map[string]cty.Type{
+						"id": cty.String,
+					})),
+				},
+			},
+			lang.ReferenceOrigin{
+				Addr: lang.Address{
+					lang.RootStep{Name: "aws_instance"},
+					lang.AttrStep{Name: "web"},
+					lang.IndexStep{Key: cty.NumberIntVal(100)},
+					lang.AttrStep{Name: "id"},
+				},
+				OfType: cty.String,
+			},
+			nil,
+		},
+		{
+			"unknown attribute of element",
+			lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "aws_instance"},
+						lang.AttrStep{Name: "web"},
+					},
+					RangePtr: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+						End:      hcl.Pos{Line: 3, Column: 2, Byte: 40},
+					},
+					Type: cty.List(cty.Object(map[string]cty.Type{
+						"id": cty.String,
+					})),
+				},
+			},
+			lang.ReferenceOrigin{
+				Addr: lang.Address{
+					lang.RootStep{Name: "aws_instance"},
+					lang.AttrStep{Name: "web"},
+					lang.SplatStep{},
+					lang.AttrStep{Name: "unknown"},
+				},
+				OfType: cty.DynamicPseudoType,
+			},
+			nil,
+		},
 	}
 	for i, tc := range testCases {
 		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
@@ -0,0 +1,310 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// candidatesForRelativeTraversal returns candidates for attributes
+// following a splat operator (var.list[*].) or a relative traversal
+// of a function result (keys(var.map)[0].), along with a bool
+// indicating whether the prefix represents such traversal.
+func (d *Decoder) candidatesForRelativeTraversal(tc schema.TraversalExpr, prefix []byte, prefixRng, editRng hcl.Range) ([]lang.Candidate, bool) {
+	candidates := make([]lang.Candidate, 0)
+
+	lastDot := bytes.LastIndexByte(prefix, '.')
+	if lastDot < 0 {
+		return candidates, false
+	}
+	attrPrefix := string(prefix[lastDot+1:])
+	if attrPrefix != "" && !hclsyntax.ValidIdentifier(attrPrefix) {
+		return candidates, false
+	}
+
+	base := prefix[:lastDot]
+	baseExpr, diags := hclsyntax.ParseExpression(base, prefixRng.Filename, prefixRng.Start)
+	if diags.HasErrors() {
+		return candidates, false
+	}
+
+	var objType cty.Type
+	var ok bool
+	switch e := baseExpr.(type) {
+	case *hclsyntax.SplatExpr:
+		// attributes apply to each element
+		objType, ok = d.splatEachType(e)
+	case *hclsyntax.RelativeTraversalExpr, *hclsyntax.FunctionCallExpr:
+		objType, ok = d.exprType(e)
+	default:
+		return candidates, false
+	}
+	if !ok || !objType.IsObjectType() {
+		return candidates, true
+	}
+
+	attrNames := make([]string, 0)
+	for name := range objType.AttributeTypes() {
+		if strings.HasPrefix(name, attrPrefix) {
+			attrNames = append(attrNames, name)
+		}
+	}
+	sort.Strings(attrNames)
+
+	for _, name := range attrNames {
+		text := fmt.Sprintf("%s.%s", base, name)
+
+		expr, diags := hclsyntax.ParseExpression([]byte(text), prefixRng.Filename, prefixRng.Start)
+		if diags.HasErrors() {
+			continue
+		}
+		typ, ok := d.exprType(expr)
+		if !ok {
+			continue
+		}
+		if tc.OfType != cty.NilType && !typ.IsObjectType() &&
+			len(typ.TestConformance(tc.OfType)) > 0 {
+			continue
+		}
+
+		candidates = append(candidates, lang.Candidate{
+			Label:  text,
+			Detail: typ.FriendlyName(),
+			Kind:   lang.TraversalCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: text,
+				Snippet: text,
+				Range:   editRng,
+			},
+		})
+	}
+
+	return candidates, true
+}
+
+// hoverContentForRelativeTraversal returns hover content
+// for a splat expression or a relative traversal, describing
+// the type of the resulting value
+func (d *Decoder) hoverContentForRelativeTraversal(expr hclsyntax.Expression) (string, bool) {
+	typ, ok := d.exprType(expr)
+	if !ok {
+		return "", false
+	}
+
+	b, err := d.bytesFromRange(expr.Range())
+	if err != nil {
+		return "", false
+	}
+
+	typeContent, err := hoverContentForType(typ, 0)
+	if err != nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("`%s`\n%s", b, typeContent), true
+}
+
+// exprType returns type of the value which the given expression
+// evaluates to, as far as it can be determined from the types
+// of reference targets and return types of known functions.
+func (d *Decoder) exprType(expr hclsyntax.Expression) (cty.Type, bool) {
+	var typ cty.Type
+	var ok bool
+
+	switch e := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		typ, ok = d.traversalType(e.Traversal)
+	case *hclsyntax.FunctionCallExpr:
+		sig, known := d.functionSignature(e.Name)
+		if !known {
+			return cty.NilType, false
+		}
+		typ, ok = sig.ReturnType, sig.ReturnType != cty.NilType
+	case *hclsyntax.RelativeTraversalExpr:
+		srcType, srcOk := d.exprType(e.Source)
+		if !srcOk {
+			return cty.NilType, false
+		}
+		typ, ok = typeOfTraversalSteps(srcType, e.Traversal)
+	case *hclsyntax.SplatExpr:
+		typ, ok = d.splatType(e)
+	}
+
+	if !ok || typ == cty.DynamicPseudoType {
+		return cty.NilType, false
+	}
+
+	return typ, true
+}
+
+// splatType returns type of the value produced by a splat expression
+func (d *Decoder) splatType(e *hclsyntax.SplatExpr) (cty.Type, bool) {
+	srcType, ok := d.exprType(e.Source)
+	if !ok {
+		return cty.NilType, false
+	}
+
+	eachType, ok := d.splatEachType(e)
+	if !ok {
+		return cty.NilType, false
+	}
+
+	if srcType.IsListType() || srcType.IsSetType() {
+		return cty.List(eachType), true
+	}
+	if srcType.IsTupleType() {
+		elemTypes := make([]cty.Type, len(srcType.TupleElementTypes()))
+		for i := range elemTypes {
+			elemTypes[i] = eachType
+		}
+		return cty.Tuple(elemTypes), true
+	}
+
+	// any other value is treated as a single-element tuple
+	return cty.Tuple([]cty.Type{eachType}), true
+}
+
+// splatEachType returns type of the value produced
+// for each element of the splat expression's source
+func (d *Decoder) splatEachType(e *hclsyntax.SplatExpr) (cty.Type, bool) {
+	srcType, ok := d.exprType(e.Source)
+	if !ok {
+		return cty.NilType, false
+	}
+
+	elemType := srcType
+	switch {
+	case srcType.IsListType() || srcType.IsSetType():
+		elemType = srcType.ElementType()
+	case srcType.IsTupleType():
+		elemTypes := srcType.TupleElementTypes()
+		if len(elemTypes) == 0 {
+			return cty.NilType, false
+		}
+		for _, t := range elemTypes {
+			if !t.Equals(elemTypes[0]) {
+				// heterogeneous elements
+				return cty.NilType, false
+			}
+		}
+		elemType = elemTypes[0]
+	}
+
+	switch each := e.Each.(type) {
+	case *hclsyntax.AnonSymbolExpr:
+		return elemType, true
+	case *hclsyntax.RelativeTraversalExpr:
+		if _, ok := each.Source.(*hclsyntax.AnonSymbolExpr); ok {
+			return typeOfTraversalSteps(elemType, each.Traversal)
+		}
+	}
+
+	return cty.NilType, false
+}
+
+// traversalType returns type of the given traversal based on
+// the type of the closest reference target it refers to
+func (d *Decoder) traversalType(traversal hcl.Traversal) (cty.Type, bool) {
+	readTargets := d.referenceTargetReader()
+	if readTargets == nil {
+		return cty.NilType, false
+	}
+
+	addr, err := lang.TraversalToAddress(traversal)
+	if err != nil {
+		return cty.NilType, false
+	}
+
+	var matchingTarget *lang.ReferenceTarget
+	ReferenceTargets(readTargets()).DeepWalk(func(ref lang.ReferenceTarget) error {
+		if ref.Type == cty.NilType || len(ref.Addr) > len(addr) {
+			return nil
+		}
+		if matchingTarget != nil && len(matchingTarget.Addr) >= len(ref.Addr) {
+			return nil
+		}
+		if Address(ref.Addr).Equals(Address(addr).FirstSteps(uint(len(ref.Addr)))) {
+			matchingTarget = &ref
+		}
+		return nil
+	})
+	if matchingTarget == nil {
+		return cty.NilType, false
+	}
+
+	return typeOfAddressSteps(matchingTarget.Type, addr[len(matchingTarget.Addr):])
+}
+
+// typeOfTraversalSteps returns type of the value which results
+// from applying the (relative) traversal to a value of the given type
+func typeOfTraversalSteps(typ cty.Type, traversal hcl.Traversal) (cty.Type, bool) {
+	steps, err := lang.TraversalToAddress(traversal)
+	if err != nil {
+		return cty.NilType, false
+	}
+	return typeOfAddressSteps(typ, steps)
+}
+
+// typeOfAddressSteps returns type of the value which results
+// from applying the address steps to a value of the given type
+func typeOfAddressSteps(typ cty.Type, steps lang.Address) (cty.Type, bool) {
+	for i, step := range steps {
+		if typ == cty.DynamicPseudoType {
+			return cty.NilType, false
+		}
+
+		switch s := step.(type) {
+		case lang.AttrStep:
+			switch {
+			case typ.IsObjectType() && typ.HasAttribute(s.Name):
+				typ = typ.AttributeType(s.Name)
+			case typ.IsMapType():
+				typ = typ.ElementType()
+			default:
+				return cty.NilType, false
+			}
+		case lang.IndexStep:
+			switch {
+			case typ.IsListType() || typ.IsMapType():
+				typ = typ.ElementType()
+			case typ.IsTupleType():
+				var idx int
+				err := gocty.FromCtyValue(s.Key, &idx)
+				if err != nil || idx < 0 || idx >= len(typ.TupleElementTypes()) {
+					return cty.NilType, false
+				}
+				typ = typ.TupleElementType(idx)
+			case typ.IsObjectType():
+				var name string
+				err := gocty.FromCtyValue(s.Key, &name)
+				if err != nil || !typ.HasAttribute(name) {
+					return cty.NilType, false
+				}
+				typ = typ.AttributeType(name)
+			default:
+				return cty.NilType, false
+			}
+		case lang.SplatStep:
+			if !typ.IsListType() && !typ.IsSetType() {
+				return cty.NilType, false
+			}
+			eachType, ok := typeOfAddressSteps(typ.ElementType(), steps[i+1:])
+			if !ok {
+				return cty.NilType, false
+			}
+			return cty.List(eachType), true
+		default:
+			return cty.NilType, false
+		}
+	}
+
+	return typ, true
+}
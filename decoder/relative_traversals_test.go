@@ -0,0 +1,289 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var relativeTraversalTargets = lang.ReferenceTargets{
+	{
+		Addr: lang.Address{
+			lang.RootStep{Name: "var"},
+			lang.AttrStep{Name: "list"},
+		},
+		Type: cty.List(cty.Object(map[string]cty.Type{
+			"id":   cty.Number,
+			"name": cty.String,
+		})),
+	},
+	{
+		Addr: lang.Address{
+			lang.RootStep{Name: "var"},
+			lang.AttrStep{Name: "map"},
+		},
+		Type: cty.Map(cty.String),
+	},
+}
+
+var relativeTraversalFunctions = map[string]schema.FunctionSignature{
+	"entries": {
+		ReturnType: cty.List(cty.Object(map[string]cty.Type{
+			"key":   cty.String,
+			"value": cty.String,
+		})),
+	},
+	"anything": {
+		ReturnType: cty.DynamicPseudoType,
+	},
+}
+
+func TestDecoder_CandidatesAtPos_relativeTraversals(t *testing.T) {
+	testCases := []struct {
+		name               string
+		attrSchema         *schema.AttributeSchema
+		cfg                string
+		pos                hcl.Pos
+		expectedCandidates lang.Candidates
+	}{
+		{
+			"splat without prefix",
+			&schema.AttributeSchema{
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.DynamicPseudoType},
+				},
+			},
+			`attr = var.list[*].
+`,
+			hcl.Pos{Line: 1, Column: 20, Byte: 19},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "var.list[*].id",
+					Detail: "list of number",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 20, Byte: 19},
+						},
+						NewText: "var.list[*].id",
+						Snippet: "var.list[*].id",
+					},
+				},
+				{
+					Label:  "var.list[*].name",
+					Detail: "list of string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 20, Byte: 19},
+						},
+						NewText: "var.list[*].name",
+						Snippet: "var.list[*].name",
+					},
+				},
+			}),
+		},
+		{
+			"splat with prefix and type constraint",
+			&schema.AttributeSchema{
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.List(cty.String)},
+				},
+			},
+			`attr = var.list[*].n
+`,
+			hcl.Pos{Line: 1, Column: 21, Byte: 20},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "var.list[*].name",
+					Detail: "list of string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 21, Byte: 20},
+						},
+						NewText: "var.list[*].name",
+						Snippet: "var.list[*].name",
+					},
+				},
+			}),
+		},
+		{
+			"function result index",
+			&schema.AttributeSchema{
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.String},
+				},
+			},
+			`attr = entries(var.map)[0].
+`,
+			hcl.Pos{Line: 1, Column: 28, Byte: 27},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "entries(var.map)[0].key",
+					Detail: "string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 28, Byte: 27},
+						},
+						NewText: "entries(var.map)[0].key",
+						Snippet: "entries(var.map)[0].key",
+					},
+				},
+				{
+					Label:  "entries(var.map)[0].value",
+					Detail: "string",
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+							End:      hcl.Pos{Line: 1, Column: 28, Byte: 27},
+						},
+						NewText: "entries(var.map)[0].value",
+						Snippet: "entries(var.map)[0].value",
+					},
+				},
+			}),
+		},
+		{
+			"function with dynamic return type",
+			&schema.AttributeSchema{
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.String},
+				},
+			},
+			`attr = anything(var.map)[0].
+`,
+			hcl.Pos{Line: 1, Column: 29, Byte: 28},
+			lang.CompleteCandidates([]lang.Candidate{}),
+		},
+		{
+			"splat of unknown target",
+			&schema.AttributeSchema{
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.String},
+				},
+			},
+			`attr = var.unknown[*].
+`,
+			hcl.Pos{Line: 1, Column: 23, Byte: 22},
+			lang.CompleteCandidates([]lang.Candidate{}),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"attr": tc.attrSchema,
+				},
+			})
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return relativeTraversalTargets
+			})
+			d.SetFunctions(relativeTraversalFunctions)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, candidates); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_HoverAtPos_relativeTraversals(t *testing.T) {
+	testCases := []struct {
+		name         string
+		cfg          string
+		pos          hcl.Pos
+		expectedData *lang.HoverData
+	}{
+		{
+			"splat attribute",
+			`attr = var.list[*].name
+`,
+			hcl.Pos{Line: 1, Column: 22, Byte: 21},
+			&lang.HoverData{
+				Content: lang.Markdown("`var.list[*].name`\n_list of string_"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+					End:      hcl.Pos{Line: 1, Column: 24, Byte: 23},
+				},
+			},
+		},
+		{
+			"function result attribute",
+			`attr = entries(var.map)[0].key
+`,
+			hcl.Pos{Line: 1, Column: 30, Byte: 29},
+			&lang.HoverData{
+				Content: lang.Markdown("`entries(var.map)[0].key`\n_string_"),
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+					End:      hcl.Pos{Line: 1, Column: 31, Byte: 30},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"attr": {
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfType: cty.String},
+						},
+					},
+				},
+			})
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return relativeTraversalTargets
+			})
+			d.SetFunctions(relativeTraversalFunctions)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := d.HoverAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedData, data); diff != "" {
+				t.Fatalf("unexpected hover data: %s", diff)
+			}
+		})
+	}
+}
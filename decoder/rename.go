@@ -0,0 +1,259 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// RenameBlockLabelAtPos returns text edits necessary to rename
+// the block label at the given position to newName, across all files.
+//
+// If the label is part of the block's address (via schema.LabelStep),
+// the edits also include all reference origins pointing to the block
+// or anything nested within it. Reference targets and origins
+// are obtained from the respective readers, if set,
+// or collected from loaded files otherwise.
+//
+// AddressCollisionError is returned if the renamed block would
+// have the same address as an existing reference target.
+func (d *Decoder) RenameBlockLabelAtPos(filename string, pos hcl.Pos, newName string) ([]lang.TextEdit, error) {
+	d = d.withRequestOptions(nil)
+
+	f, err := d.fileByName(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	pos = d.posForFile(filename, pos)
+
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	d.rootSchemaMu.RLock()
+	rootSchema := d.rootSchema
+	d.rootSchemaMu.RUnlock()
+
+	if rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	block, bSchema, labelIdx, ok := blockLabelAtPos(rootBody, rootSchema, pos)
+	if !ok {
+		return nil, &PositionalError{
+			Filename: filename,
+			Pos:      pos,
+			Msg:      "no known block label found",
+		}
+	}
+
+	edits := []lang.TextEdit{
+		labelTextEdit(f.Bytes, block, labelIdx, newName),
+	}
+
+	if !isLabelAddressable(bSchema.Address, labelIdx) {
+		return edits, nil
+	}
+
+	if !hclsyntax.ValidIdentifier(newName) {
+		return nil, fmt.Errorf("%q is not a valid name for an addressable label", newName)
+	}
+
+	oldAddr, ok := resolveBlockAddress(block, bSchema.Address)
+	if !ok {
+		return edits, nil
+	}
+
+	renamedBlock := *block
+	renamedBlock.Labels = make([]string, len(block.Labels))
+	copy(renamedBlock.Labels, block.Labels)
+	renamedBlock.Labels[labelIdx] = newName
+
+	newAddr, ok := resolveBlockAddress(&renamedBlock, bSchema.Address)
+	if !ok {
+		return edits, nil
+	}
+	if Address(oldAddr).Equals(Address(newAddr)) {
+		return edits, nil
+	}
+
+	targets, err := d.referenceTargets()
+	if err != nil {
+		return nil, err
+	}
+	var collisionErr error
+	ReferenceTargets(targets).DeepWalk(func(target lang.ReferenceTarget) error {
+		if Address(target.Addr).Equals(Address(newAddr)) {
+			collisionErr = &AddressCollisionError{
+				Addr:  newAddr,
+				Range: target.RangePtr,
+			}
+			return StopWalking
+		}
+		return nil
+	})
+	if collisionErr != nil {
+		return nil, collisionErr
+	}
+
+	origins, err := d.referenceOrigins()
+	if err != nil {
+		return nil, err
+	}
+
+	target := ReferenceTarget{
+		Addr:    oldAddr,
+		ScopeId: bSchema.Address.ScopeId,
+	}
+	for _, origin := range origins {
+		if origin.IsSynthetic {
+			// not present in the configuration
+			continue
+		}
+		if len(origin.Addr) < len(oldAddr) ||
+			!target.MatchesAnyScopeId(origin.ScopeIds()) ||
+			!Address(origin.Addr).FirstSteps(uint(len(oldAddr))).Equals(Address(oldAddr)) {
+			continue
+		}
+
+		edit, ok := d.originPrefixTextEdit(origin, len(oldAddr), newAddr)
+		if !ok {
+			continue
+		}
+		edits = append(edits, edit)
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].Range.Filename != edits[j].Range.Filename {
+			return edits[i].Range.Filename < edits[j].Range.Filename
+		}
+		return edits[i].Range.Start.Byte < edits[j].Range.Start.Byte
+	})
+
+	return edits, nil
+}
+
+func blockLabelAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema, pos hcl.Pos) (*hclsyntax.Block, *schema.BlockSchema, int, bool) {
+	if bodySchema == nil {
+		return nil, nil, 0, false
+	}
+
+	for _, block := range body.Blocks {
+		if !block.Range().ContainsPos(pos) {
+			continue
+		}
+
+		bSchema, ok := blockSchemaForType(bodySchema, block.Type)
+		if !ok {
+			return nil, nil, 0, false
+		}
+
+		for i, labelRange := range block.LabelRanges {
+			if labelRange.ContainsPos(pos) {
+				if i+1 > len(bSchema.Labels) {
+					return nil, nil, 0, false
+				}
+				return block, bSchema, i, true
+			}
+		}
+
+		if block.Body != nil && block.Body.Range().ContainsPos(pos) {
+			mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
+			if err != nil {
+				return nil, nil, 0, false
+			}
+			return blockLabelAtPos(block.Body, mergedSchema, pos)
+		}
+	}
+
+	return nil, nil, 0, false
+}
+
+func isLabelAddressable(addr *schema.BlockAddrSchema, labelIdx int) bool {
+	if addr == nil {
+		return false
+	}
+	for _, step := range addr.Steps {
+		if ls, ok := step.(schema.LabelStep); ok && ls.Index == uint(labelIdx) {
+			return true
+		}
+	}
+	return false
+}
+
+func labelTextEdit(src []byte, block *hclsyntax.Block, labelIdx int, newName string) lang.TextEdit {
+	rng := block.LabelRanges[labelIdx]
+
+	// labels may be declared as quoted strings or bare identifiers,
+	// where quoted labels are escaped as any other string in HCL,
+	// incl. template sequences (${ and %{)
+	newText := newName
+	labelBytes := rng.SliceBytes(src)
+	isQuoted := len(labelBytes) > 0 && labelBytes[0] == '"'
+	if isQuoted || !hclsyntax.ValidIdentifier(newName) {
+		newText = string(hclwrite.TokensForValue(cty.StringVal(newName)).Bytes())
+	}
+
+	return lang.TextEdit{
+		Range:   rng,
+		NewText: newText,
+		Snippet: escapeSnippetText(newText),
+	}
+}
+
+// originPrefixTextEdit returns edit replacing the first steps
+// of the origin's traversal with the given address
+func (d *Decoder) originPrefixTextEdit(origin lang.ReferenceOrigin, steps int, addr lang.Address) (lang.TextEdit, bool) {
+	src, err := d.bytesFromRange(origin.Range)
+	if err != nil {
+		return lang.TextEdit{}, false
+	}
+
+	expr, diags := hclsyntax.ParseExpression(src, origin.Range.Filename, origin.Range.Start)
+	if diags.HasErrors() {
+		return lang.TextEdit{}, false
+	}
+	if splat, ok := expr.(*hclsyntax.SplatExpr); ok {
+		// e.g. aws_instance.web[*].id
+		expr = splat.Source
+	}
+	traversalExpr, ok := expr.(*hclsyntax.ScopeTraversalExpr)
+	if !ok || len(traversalExpr.Traversal) < steps {
+		return lang.TextEdit{}, false
+	}
+	traversal := traversalExpr.Traversal
+
+	rng := hcl.RangeBetween(traversal[0].SourceRange(), traversal[steps-1].SourceRange())
+
+	newText := d.addrFormat.Format(addr)
+	return lang.TextEdit{
+		Range:   rng,
+		NewText: newText,
+		Snippet: escapeSnippetText(newText),
+	}, true
+}
+
+func (d *Decoder) referenceTargets() (lang.ReferenceTargets, error) {
+	readTargets := d.referenceTargetReader()
+	if readTargets != nil {
+		return readTargets(), nil
+	}
+	// progress of collection on demand is not reported
+	return d.collectReferenceTargets(nil)
+}
+
+func (d *Decoder) referenceOrigins() (lang.ReferenceOrigins, error) {
+	readOrigins := d.referenceOriginReader()
+	if readOrigins != nil {
+		return readOrigins(), nil
+	}
+	return d.collectReferenceOrigins(nil)
+}
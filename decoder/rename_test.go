@@ -0,0 +1,206 @@
+package decoder
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestDecoder_RenameBlockLabelAtPos(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type"},
+					{Name: "name"},
+				},
+				Address: &schema.BlockAddrSchema{
+					Steps: []schema.AddrStep{
+						schema.LabelStep{Index: 0},
+						schema.LabelStep{Index: 1},
+					},
+					ScopeId:     lang.ScopeId("resource"),
+					AsReference: true,
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"attr": {
+							Expr: schema.ExprConstraints{
+								schema.TraversalExpr{OfScopeId: lang.ScopeId("resource")},
+							},
+						},
+					},
+				},
+			},
+			"output": {
+				Labels: []*schema.LabelSchema{
+					{Name: "name"},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"value": {
+							Expr: schema.ExprConstraints{
+								schema.TraversalExpr{OfScopeId: lang.ScopeId("resource")},
+								schema.TraversalExpr{OfScopeId: lang.ScopeId("other")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	files := map[string]string{
+		"first.tf": `resource "aws_instance" "foo" {
+}
+resource "aws_instance" "bar" {
+  attr = aws_instance.foo
+}
+`,
+		"second.tf": `output "foo" {
+  value = aws_instance.foo.id
+}
+output "bar" {
+  value = aws_instance.foobar
+}
+`,
+	}
+
+	testCases := []struct {
+		name          string
+		filename      string
+		pos           hcl.Pos
+		newName       string
+		expectedEdits []lang.TextEdit
+		expectedErr   error
+	}{
+		{
+			"addressable label",
+			"first.tf",
+			hcl.Pos{Line: 1, Column: 27, Byte: 26},
+			"baz",
+			[]lang.TextEdit{
+				{
+					NewText: `"baz"`,
+					Snippet: `"baz"`,
+					Range: hcl.Range{
+						Filename: "first.tf",
+						Start:    hcl.Pos{Line: 1, Column: 25, Byte: 24},
+						End:      hcl.Pos{Line: 1, Column: 30, Byte: 29},
+					},
+				},
+				{
+					NewText: "aws_instance.baz",
+					Snippet: "aws_instance.baz",
+					Range: hcl.Range{
+						Filename: "first.tf",
+						Start:    hcl.Pos{Line: 4, Column: 10, Byte: 75},
+						End:      hcl.Pos{Line: 4, Column: 26, Byte: 91},
+					},
+				},
+				{
+					NewText: "aws_instance.baz",
+					Snippet: "aws_instance.baz",
+					Range: hcl.Range{
+						Filename: "second.tf",
+						Start:    hcl.Pos{Line: 2, Column: 11, Byte: 25},
+						End:      hcl.Pos{Line: 2, Column: 27, Byte: 41},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"non-addressable label",
+			"second.tf",
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			"baz",
+			[]lang.TextEdit{
+				{
+					NewText: `"baz"`,
+					Snippet: `"baz"`,
+					Range: hcl.Range{
+						Filename: "second.tf",
+						Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+						End:      hcl.Pos{Line: 1, Column: 13, Byte: 12},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"non-addressable label with template sequences",
+			"second.tf",
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			`${var.name} "%{x}"`,
+			[]lang.TextEdit{
+				{
+					NewText: `"$${var.name} \"%%{x}\""`,
+					Snippet: `"\$\${var.name\} \\"%%{x\}\\""`,
+					Range: hcl.Range{
+						Filename: "second.tf",
+						Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+						End:      hcl.Pos{Line: 1, Column: 13, Byte: 12},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"colliding address",
+			"first.tf",
+			hcl.Pos{Line: 1, Column: 27, Byte: 26},
+			"bar",
+			nil,
+			&AddressCollisionError{},
+		},
+		{
+			"no label",
+			"first.tf",
+			hcl.Pos{Line: 1, Column: 3, Byte: 2},
+			"baz",
+			nil,
+			&PositionalError{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			for filename, cfg := range files {
+				f, pDiags := hclsyntax.ParseConfig([]byte(cfg), filename, hcl.InitialPos)
+				if len(pDiags) > 0 {
+					t.Fatal(pDiags)
+				}
+				err := d.LoadFile(filename, f)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			edits, err := d.RenameBlockLabelAtPos(tc.filename, tc.pos, tc.newName)
+			if tc.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected error: %T", tc.expectedErr)
+				}
+				if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedErr) {
+					t.Fatalf("expected %T, %T given: %s", tc.expectedErr, err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedEdits, edits); diff != "" {
+				t.Fatalf("unexpected edits: %s", diff)
+			}
+		})
+	}
+}
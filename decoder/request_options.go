@@ -0,0 +1,123 @@
+package decoder
+
+import (
+	"sync"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// RequestOption represents an override of a setting of the Decoder
+// which applies to a single request (e.g. CandidatesAtPos), such that
+// the setting can differ between requests (e.g. of different clients)
+// without mutating the Decoder, which would race with other requests.
+type RequestOption func(d *Decoder)
+
+// WithMaxCandidates overrides the maximum number of candidates
+// of attributes and blocks returned by the request
+func WithMaxCandidates(max uint) RequestOption {
+	return func(d *Decoder) {
+		d.maxCandidates = max
+	}
+}
+
+// WithFeatures overrides optional features enabled
+// for the request (see SetFeatures)
+func WithFeatures(features Features) RequestOption {
+	return func(d *Decoder) {
+		d.features = features
+	}
+}
+
+// WithClientCapabilities overrides capabilities of the client
+// which issued the request (see SetClientCapabilities)
+func WithClientCapabilities(caps ClientCapabilities) RequestOption {
+	return func(d *Decoder) {
+		d.clientCaps = caps
+	}
+}
+
+// WithValidationOptions overrides options of validation
+// for the request (see SetValidationOptions)
+func WithValidationOptions(opts ValidationOptions) RequestOption {
+	return func(d *Decoder) {
+		d.validationOpts = opts
+	}
+}
+
+// WithReferenceCandidateOptions overrides options of completion
+// of references for the request (see SetReferenceCandidateOptions)
+func WithReferenceCandidateOptions(opts ReferenceCandidateOptions) RequestOption {
+	return func(d *Decoder) {
+		d.refCandidateOpts = opts
+	}
+}
+
+// withRequestOptions returns the decoder to serve a single request
+// with the given options, i.e. a shallow copy of d with the options
+// applied, which shares loaded files with d, or d itself where d
+// already serves a request and there are no options.
+//
+// The schema, functions and reference readers of the copy are those
+// in use at the time of the copy, as if they were swapped after the
+// request. Reference targets and origins are read at most once per
+// request (on first use), such that all parts of the request, e.g.
+// validation of each attribute, observe the same targets and origins.
+func (d *Decoder) withRequestOptions(opts []RequestOption) *Decoder {
+	if d.isRequest && len(opts) == 0 {
+		return d
+	}
+
+	d.rootSchemaMu.RLock()
+	d.readersMu.RLock()
+	d.tokensMu.RLock()
+	rd := *d
+	d.tokensMu.RUnlock()
+	d.readersMu.RUnlock()
+	d.rootSchemaMu.RUnlock()
+
+	if !rd.isRequest {
+		rd.refTargetReader = onceTargetReader(rd.refTargetReader)
+		rd.refOriginReader = onceOriginReader(rd.refOriginReader)
+		rd.isRequest = true
+	}
+
+	for _, opt := range opts {
+		opt(&rd)
+	}
+
+	return &rd
+}
+
+// onceTargetReader returns a reader which reads targets
+// of the given reader once and returns the same targets afterwards
+func onceTargetReader(f ReferenceTargetReader) ReferenceTargetReader {
+	if f == nil {
+		return nil
+	}
+
+	var once sync.Once
+	var targets lang.ReferenceTargets
+	return func() lang.ReferenceTargets {
+		once.Do(func() {
+			targets = f()
+		})
+		return targets
+	}
+}
+
+// onceOriginReader returns a reader which reads origins
+// of the given reader once and returns the same origins afterwards
+func onceOriginReader(f ReferenceOriginReader) ReferenceOriginReader {
+	if f == nil {
+		return nil
+	}
+
+	var once sync.Once
+	var origins lang.ReferenceOrigins
+	return func() lang.ReferenceOrigins {
+		once.Do(func() {
+			origins = f()
+		})
+		return origins
+	}
+}
@@ -0,0 +1,176 @@
+package decoder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var requestOptionsSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"name": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+		},
+		"count": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.Number),
+		},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_requestOptions(t *testing.T) {
+	testCases := []struct {
+		name               string
+		opts               []RequestOption
+		expectedCandidates string
+	}{
+		{
+			"no options",
+			nil,
+			`
+complete: true
+candidates: 2
+---
+label: "count"
+kind: AttributeCandidateKind
+detail: "optional, number"
+edit: test.tf:1,1-1,1 "count" (snippet "count = ${1:1}")
+---
+label: "name"
+kind: AttributeCandidateKind
+detail: "optional, string"
+edit: test.tf:1,1-1,1 "name" (snippet "name = \"${1:value}\"")
+`,
+		},
+		{
+			"max candidates",
+			[]RequestOption{WithMaxCandidates(1)},
+			`
+complete: false
+candidates: 1
+---
+label: "count"
+kind: AttributeCandidateKind
+detail: "optional, number"
+edit: test.tf:1,1-1,1 "count" (snippet "count = ${1:1}")
+`,
+		},
+		{
+			"client capabilities",
+			[]RequestOption{WithClientCapabilities(ClientCapabilities{})},
+			`
+complete: true
+candidates: 2
+---
+label: "count"
+kind: AttributeCandidateKind
+detail: "optional, number"
+edit: test.tf:1,1-1,1 "count"
+---
+label: "name"
+kind: AttributeCandidateKind
+detail: "optional, string"
+edit: test.tf:1,1-1,1 "name"
+`,
+		},
+	}
+
+	d := newTestDecoder(t, requestOptionsSchema, map[string]string{"test.tf": ""})
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			candidates, err := d.CandidatesAtPos("test.tf", hcl.InitialPos, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
+
+func TestDecoder_ValidateFile_requestOptions(t *testing.T) {
+	d := newTestDecoder(t, requestOptionsSchema, map[string]string{"test.tf": "name = [1]\ncount = \"two\"\n"})
+
+	diags, err := d.ValidateFile("test.tf", WithValidationOptions(ValidationOptions{
+		MaxDiagnosticsPerFile: 1,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 2 || diags[1].Summary != "Too many diagnostics" {
+		t.Fatalf("expected truncated diagnostics, given: %#v", diags)
+	}
+
+	// options of previous requests must not apply
+	diags, err = d.ValidateFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 2 || diags[1].Summary != "Invalid value type" {
+		t.Fatalf("expected all diagnostics, given: %#v", diags)
+	}
+}
+
+func TestDecoder_ValidateFileWithResultID_requestOptions(t *testing.T) {
+	d := newTestDecoder(t, requestOptionsSchema, map[string]string{"test.tf": "name = [1]\ncount = \"two\"\n"})
+	ctx := context.Background()
+
+	report, err := d.ValidateFileWithResultID(ctx, "test.tf", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := WithValidationOptions(ValidationOptions{MaxDiagnosticsPerFile: 1})
+	limitedReport, err := d.ValidateFileWithResultID(ctx, "test.tf", report.ResultID, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limitedReport.IsUnchanged {
+		t.Fatal("expected result with different options to be changed")
+	}
+	if len(limitedReport.Diagnostics) != 2 {
+		t.Fatalf("expected truncated diagnostics, given: %#v", limitedReport.Diagnostics)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_requestOptionsConcurrently(t *testing.T) {
+	d := newTestDecoder(t, requestOptionsSchema, map[string]string{"test.tf": ""})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			candidates, err := d.CandidatesAtPos("test.tf", hcl.InitialPos, WithMaxCandidates(1))
+			if err == nil && len(candidates.List) != 1 {
+				err = fmt.Errorf("expected 1 candidate, given %d", len(candidates.List))
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			d.SetSchema(requestOptionsSchema)
+			candidates, err := d.CandidatesAtPos("test.tf", hcl.InitialPos)
+			if err == nil && len(candidates.List) != 2 {
+				err = fmt.Errorf("expected 2 candidates, given %d", len(candidates.List))
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
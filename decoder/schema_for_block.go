@@ -0,0 +1,111 @@
+package decoder
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// SchemaForBlockAtPos returns body schema of the innermost block
+// at the given position, merged with any dependent body schema
+// which applies to the block (based on its labels or attributes).
+//
+// This is the same schema which the decoder itself uses
+// for completion, hover or validation inside the block.
+func (d *Decoder) SchemaForBlockAtPos(filename string, pos hcl.Pos) (*schema.BodySchema, error) {
+	_, bodySchema, err := d.blockAndSchemaAtPos(filename, pos)
+	return bodySchema, err
+}
+
+// SchemaForBlock returns body schema of the given block, merged
+// with any dependent body schema which applies to the block.
+//
+// The block is expected to come from one of the loaded files.
+func (d *Decoder) SchemaForBlock(block *hclsyntax.Block) (*schema.BodySchema, error) {
+	rng := block.Range()
+	foundBlock, bodySchema, err := d.blockAndSchemaAtPos(rng.Filename, block.TypeRange.Start)
+	if err != nil {
+		return nil, err
+	}
+	if foundBlock != block {
+		return nil, &PositionalError{
+			Filename: rng.Filename,
+			Pos:      block.TypeRange.Start,
+			Msg:      fmt.Sprintf("block %q not found in loaded file", block.Type),
+		}
+	}
+	return bodySchema, nil
+}
+
+func (d *Decoder) blockAndSchemaAtPos(filename string, pos hcl.Pos) (*hclsyntax.Block, *schema.BodySchema, error) {
+	rootBody, err := d.bodyForFileAndPos(filename, pos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return nil, nil, &NoSchemaError{}
+	}
+
+	block, bodySchema, err := d.innermostBlockAndSchema(rootBody, d.rootSchema, 0, pos)
+	if err != nil {
+		return nil, nil, err
+	}
+	if block == nil {
+		return nil, nil, &PositionalError{
+			Filename: filename,
+			Pos:      pos,
+			Msg:      "no block found",
+		}
+	}
+
+	return block, bodySchema, nil
+}
+
+// innermostBlockAndSchema returns the innermost block containing
+// the given position along with its (merged) body schema,
+// or nil if there is no such block in the body
+func (d *Decoder) innermostBlockAndSchema(body *hclsyntax.Body, bodySchema *schema.BodySchema, nestingLvl int, pos hcl.Pos) (*hclsyntax.Block, *schema.BodySchema, error) {
+	if bodySchema == nil || d.isNestedTooDeep(nestingLvl) {
+		return nil, nil, nil
+	}
+
+	for _, block := range body.Blocks {
+		if !block.Range().ContainsPos(pos) {
+			continue
+		}
+
+		bSchema, ok := blockSchemaForType(bodySchema, block.Type)
+		if !ok {
+			return nil, nil, &PositionalError{
+				Filename: block.Range().Filename,
+				Pos:      pos,
+				Msg:      fmt.Sprintf("unknown block type %q", block.Type),
+			}
+		}
+
+		mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if block.Body != nil && block.Body.Range().ContainsPos(pos) {
+			innerBlock, innerSchema, err := d.innermostBlockAndSchema(block.Body, mergedSchema, nestingLvl+1, pos)
+			if err != nil {
+				return nil, nil, err
+			}
+			if innerBlock != nil {
+				return innerBlock, innerSchema, nil
+			}
+		}
+
+		return block, mergedSchema, nil
+	}
+
+	return nil, nil, nil
+}
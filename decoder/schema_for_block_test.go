@@ -0,0 +1,168 @@
+package decoder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var schemaForBlockSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": {
+			Labels: []*schema.LabelSchema{
+				{Name: "type", IsDepKey: true},
+				{Name: "name"},
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"count": {Expr: schema.LiteralTypeOnly(cty.Number)},
+				},
+			},
+			DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{
+						{Index: 0, Value: "aws_instance"},
+					},
+				}): {
+					Attributes: map[string]*schema.AttributeSchema{
+						"ami": {Expr: schema.LiteralTypeOnly(cty.String)},
+					},
+					Blocks: map[string]*schema.BlockSchema{
+						"ebs": {
+							Body: &schema.BodySchema{
+								Attributes: map[string]*schema.AttributeSchema{
+									"size": {Expr: schema.LiteralTypeOnly(cty.Number)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+const schemaForBlockCfg = `resource "aws_instance" "web" {
+  ami = "ami-123"
+  ebs {
+    size = 10
+  }
+}
+
+resource "unknown" "foo" {
+}
+`
+
+func TestDecoder_SchemaForBlockAtPos(t *testing.T) {
+	testCases := []struct {
+		name          string
+		pos           hcl.Pos
+		expectedAttrs []string
+		expectedBlock []string
+		expectedErr   error
+	}{
+		{
+			"dependent body",
+			hcl.Pos{Line: 2, Column: 3, Byte: 34},
+			[]string{"ami", "count"},
+			[]string{"ebs"},
+			nil,
+		},
+		{
+			"nested block of dependent body",
+			hcl.Pos{Line: 4, Column: 5, Byte: 62},
+			[]string{"size"},
+			[]string{},
+			nil,
+		},
+		{
+			"no dependent body",
+			hcl.Pos{Line: 8, Column: 3, Byte: 81},
+			[]string{"count"},
+			[]string{},
+			nil,
+		},
+		{
+			"outside of any block",
+			hcl.Pos{Line: 7, Column: 1, Byte: 78},
+			nil,
+			nil,
+			&PositionalError{
+				Filename: "test.tf",
+				Pos:      hcl.Pos{Line: 7, Column: 1, Byte: 78},
+				Msg:      "no block found",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(schemaForBlockSchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(schemaForBlockCfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bodySchema, err := d.SchemaForBlockAtPos("test.tf", tc.pos)
+			if tc.expectedErr != nil {
+				if diff := cmp.Diff(tc.expectedErr, err); diff != "" {
+					t.Fatalf("unexpected error: %s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedAttrs, sortedAttributeNames(bodySchema.Attributes)); diff != "" {
+				t.Fatalf("unexpected attributes: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedBlock, sortedBlockTypes(bodySchema.Blocks)); diff != "" {
+				t.Fatalf("unexpected blocks: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_SchemaForBlock(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(schemaForBlockSchema)
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(schemaForBlockCfg), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ebsBlock := f.Body.(*hclsyntax.Body).Blocks[0].Body.Blocks[0]
+	bodySchema, err := d.SchemaForBlock(ebsBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"size"}, sortedAttributeNames(bodySchema.Attributes)); diff != "" {
+		t.Fatalf("unexpected attributes: %s", diff)
+	}
+
+	// block which is not part of any loaded file
+	other, _ := hclsyntax.ParseConfig([]byte(schemaForBlockCfg), "test.tf", hcl.InitialPos)
+	_, err = d.SchemaForBlock(other.Body.(*hclsyntax.Body).Blocks[0])
+	posErr := &PositionalError{}
+	if !errors.As(err, &posErr) {
+		t.Fatalf("expected PositionalError for unknown block, given: %#v", err)
+	}
+}
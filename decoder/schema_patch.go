@@ -0,0 +1,68 @@
+package decoder
+
+import (
+	"sync/atomic"
+
+	"github.com/hashicorp/hcl-lang/schema"
+)
+
+// schemaRevisions is incremented on every schema change
+// of any Decoder, such that each schema in use can be
+// told apart, even if decoders are short-lived.
+var schemaRevisions uint64
+
+func nextSchemaRevision() uint64 {
+	return atomic.AddUint64(&schemaRevisions, 1)
+}
+
+// SchemaPatchFunc modifies the given schema in place,
+// e.g. adds or removes attributes after a refresh of the
+// schema from an external source.
+type SchemaPatchFunc func(bodySchema *schema.BodySchema) error
+
+// SchemaChangeHook represents a function which is called
+// whenever the schema is set or patched, such that any data
+// derived from the schema (e.g. collected reference targets
+// or origins) can be invalidated.
+type SchemaChangeHook func()
+
+// SetSchemaChangeHook sets a hook which is called after
+// the schema is changed via SetSchema or PatchSchema.
+func (d *Decoder) SetSchemaChangeHook(f SchemaChangeHook) {
+	d.schemaChangeHook = f
+}
+
+// PatchSchema applies the patch to the schema in use
+// without reconstructing the Decoder or reloading any files.
+//
+// The patch is applied to a copy of the schema, which then replaces
+// the current one, such that any request in progress keeps using
+// the schema it started with. Concurrent patches are applied
+// one after another. If the patch returns an error, the schema
+// is left unchanged and the error is returned.
+func (d *Decoder) PatchSchema(patch SchemaPatchFunc) error {
+	d.rootSchemaMu.Lock()
+
+	newSchema := d.rootSchema.Copy()
+	if newSchema == nil {
+		newSchema = schema.NewBodySchema()
+	}
+
+	err := patch(newSchema)
+	if err != nil {
+		d.rootSchemaMu.Unlock()
+		return err
+	}
+	d.rootSchema = newSchema
+	d.schemaRevision = nextSchemaRevision()
+	d.rootSchemaMu.Unlock()
+
+	d.schemaChanged()
+	return nil
+}
+
+func (d *Decoder) schemaChanged() {
+	if d.schemaChangeHook != nil {
+		d.schemaChangeHook()
+	}
+}
@@ -0,0 +1,133 @@
+package decoder
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_PatchSchema(t *testing.T) {
+	originalSchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"provider": {
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"region": {
+							IsOptional: true,
+							Expr:       schema.LiteralTypeOnly(cty.String),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(originalSchema)
+
+	hookCalls := 0
+	d.SetSchemaChangeHook(func() {
+		hookCalls++
+	})
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`provider {
+  region = "eu-west-1"
+  profile = "default"
+}
+`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := d.ValidateFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic before patching, given: %#v", diags)
+	}
+
+	err = d.PatchSchema(func(bodySchema *schema.BodySchema) error {
+		bodySchema.Blocks["provider"].Body.Attributes["profile"] = &schema.AttributeSchema{
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err = d.ValidateFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(hcl.Diagnostics{}, diags); diff != "" {
+		t.Fatalf("unexpected diagnostics after patching: %s", diff)
+	}
+	if _, ok := originalSchema.Blocks["provider"].Body.Attributes["profile"]; ok {
+		t.Fatal("expected original schema to remain unchanged")
+	}
+
+	patchErr := errors.New("schema unavailable")
+	err = d.PatchSchema(func(bodySchema *schema.BodySchema) error {
+		delete(bodySchema.Blocks["provider"].Body.Attributes, "profile")
+		return patchErr
+	})
+	if !errors.Is(err, patchErr) {
+		t.Fatalf("expected patch error, given: %#v", err)
+	}
+
+	diags, err = d.ValidateFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(hcl.Diagnostics{}, diags); diff != "" {
+		t.Fatalf("unexpected diagnostics after failed patch: %s", diff)
+	}
+
+	if hookCalls != 1 {
+		t.Fatalf("expected schema change hook to be called once, given: %d", hookCalls)
+	}
+}
+
+func TestDecoder_PatchSchema_concurrent(t *testing.T) {
+	d := NewDecoder()
+
+	var wg sync.WaitGroup
+	names := []string{"first", "second", "third", "fourth"}
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := d.PatchSchema(func(bodySchema *schema.BodySchema) error {
+				bodySchema.Attributes[name] = &schema.AttributeSchema{
+					IsOptional: true,
+					Expr:       schema.LiteralTypeOnly(cty.String),
+				}
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+	expectedNames := []string{"first", "fourth", "second", "third"}
+	if diff := cmp.Diff(expectedNames, sortedAttributeNames(d.rootSchema.Attributes)); diff != "" {
+		t.Fatalf("unexpected attributes: %s", diff)
+	}
+}
@@ -0,0 +1,109 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// schemaPathAtPos returns path to the innermost element of the schema
+// matching the body at the given position, along with whether it is
+// an attribute. Blocks and attributes not declared in the schema
+// are not part of the path.
+//
+// Attributes are only matched where the position is inside
+// the value, unless wholeAttr is true.
+func (d *Decoder) schemaPathAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema, pos hcl.Pos, wholeAttr bool) (lang.SchemaPath, bool) {
+	path := lang.SchemaPath{}
+
+	for bodySchema != nil {
+		for _, attr := range d.attributesOfBody(body) {
+			isInAttr := d.isPosInsideAttrExpr(attr, pos)
+			if wholeAttr {
+				isInAttr = containsPosOrEnd(attr.SrcRange, pos)
+			}
+			if !isInAttr {
+				continue
+			}
+			if _, ok := attributeSchemaForName(bodySchema, attr.Name); ok {
+				return append(path, attr.Name), true
+			}
+			return path, false
+		}
+
+		var nextBody *hclsyntax.Body
+		var nextSchema *schema.BodySchema
+		for _, block := range body.Blocks {
+			if !block.Range().ContainsPos(pos) {
+				continue
+			}
+			bSchema, ok := blockSchemaForType(bodySchema, block.Type)
+			if !ok {
+				return path, false
+			}
+
+			path = append(path, block.Type)
+			for i, label := range bSchema.Labels {
+				if label.IsDepKey && i < len(block.Labels) {
+					path = append(path, block.Labels[i])
+				}
+			}
+
+			if block.Body != nil && block.Body.Range().ContainsPos(pos) {
+				mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
+				if err == nil {
+					nextBody, nextSchema = block.Body, mergedSchema
+				}
+			}
+			break
+		}
+
+		body, bodySchema = nextBody, nextSchema
+	}
+
+	return path, false
+}
+
+// candidatesWithSchemaPaths attaches schema paths to the candidates
+// completed at the given position, where paths of attributes and
+// blocks being completed include their name
+func (d *Decoder) candidatesWithSchemaPaths(candidates lang.Candidates, body *hclsyntax.Body, pos hcl.Pos) lang.Candidates {
+	if !d.features.SchemaPaths {
+		return candidates
+	}
+
+	path, isAttr := d.schemaPathAtPos(body, d.rootSchema, pos, false)
+	for i, c := range candidates.List {
+		cPath := path
+		if !isAttr && (c.Kind == lang.AttributeCandidateKind || c.Kind == lang.BlockCandidateKind) {
+			cPath = make(lang.SchemaPath, len(path), len(path)+1)
+			copy(cPath, path)
+			cPath = append(cPath, c.Label)
+		}
+		candidates.List[i].SchemaPath = cPath
+	}
+
+	return candidates
+}
+
+// diagnosticsWithSchemaPaths attaches schema paths
+// to the diagnostics, based on their subject
+func (d *Decoder) diagnosticsWithSchemaPaths(diags lang.Diagnostics) lang.Diagnostics {
+	if !d.features.SchemaPaths {
+		return diags
+	}
+
+	for i, diag := range diags {
+		if diag.Subject == nil {
+			continue
+		}
+		body, err := d.bodyForFileAndPos(diag.Subject.Filename, diag.Subject.Start)
+		if err != nil {
+			continue
+		}
+		diags[i].SchemaPath, _ = d.schemaPathAtPos(body, d.rootSchema, diag.Subject.Start, true)
+	}
+
+	return diags
+}
@@ -0,0 +1,197 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var schemaPathSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": {
+			Labels: []*schema.LabelSchema{
+				{Name: "type", IsDepKey: true},
+				{Name: "name"},
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"count": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.Number)},
+				},
+			},
+			DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{
+						{Index: 0, Value: "aws_instance"},
+					},
+				}): {
+					Blocks: map[string]*schema.BlockSchema{
+						"network_interface": {
+							Body: &schema.BodySchema{
+								Attributes: map[string]*schema.AttributeSchema{
+									"ips": {
+										IsOptional: true,
+										Expr:       schema.LiteralTypeOnly(cty.List(cty.String)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_schemaPaths(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cfg                string
+		posAfter           string
+		expectedCandidates string
+	}{
+		{
+			"block types",
+			`res
+`,
+			"res",
+			`
+complete: true
+candidates: 1
+---
+label: "resource"
+kind: BlockCandidateKind
+detail: "Block"
+schema path: resource
+edit: test.tf:1,1-1,4 "resource" (snippet "resource \"${1}\" \"${2:name}\" {\n  ${3}\n}")
+command: editor.action.triggerSuggest
+`,
+		},
+		{
+			"attribute names in dependent body",
+			`resource "aws_instance" "foo" {
+  network_interface {
+    
+  }
+}
+`,
+			"network_interface {\n    ",
+			`
+complete: true
+candidates: 1
+---
+label: "ips"
+kind: AttributeCandidateKind
+detail: "optional, list of string"
+schema path: resource.aws_instance.network_interface.ips
+edit: test.tf:3,5-3,5 "ips" (snippet "ips = [ \"${1:value}\" ]")
+`,
+		},
+		{
+			"attribute value",
+			`resource "aws_instance" "foo" {
+  network_interface {
+    ips = 
+  }
+}
+`,
+			"ips = ",
+			`
+complete: true
+candidates: 1
+---
+label: "[ string ]"
+kind: ListCandidateKind
+detail: "list of string"
+schema path: resource.aws_instance.network_interface.ips
+edit: test.tf:3,11-3,11 "[ \"\" ]" (snippet "[ \"${1:value}\" ]")
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(schemaPathSchema)
+			d.SetFeatures(Features{SchemaPaths: true})
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
+
+func TestDecoder_ValidateFileWithRelatedInfo_schemaPaths(t *testing.T) {
+	cfg := `resource "aws_instance" "foo" {
+  count = "many"
+  network_interface {
+    ips = "10.0.0.1"
+    unknown = 1
+  }
+}
+`
+	d := NewDecoder()
+	d.SetSchema(schemaPathSchema)
+	d.SetFeatures(Features{SchemaPaths: true})
+
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := d.ValidateFileWithRelatedInfo("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := make([]string, len(diags))
+	for i, diag := range diags {
+		paths[i] = fmt.Sprintf("%s: %s", diag.Summary, diag.SchemaPath)
+	}
+	expectedPaths := []string{
+		"Invalid value type: resource.aws_instance.count",
+		"Invalid value type: resource.aws_instance.network_interface.ips",
+		"Unexpected attribute: resource.aws_instance.network_interface",
+	}
+	if diff := cmp.Diff(expectedPaths, paths); diff != "" {
+		t.Fatalf("unexpected schema paths: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_schemaPathsDisabled(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(schemaPathSchema)
+
+	cfg := `res
+`
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, cfg, "res"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range candidates.List {
+		if c.SchemaPath != nil {
+			t.Fatalf("unexpected schema path: %s", c.SchemaPath)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_CandidatesAtPos_scopeFallback(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"value": {
+				IsOptional: true,
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{
+						OfScopeId: lang.ScopeId("variable"),
+						OfType:    cty.DynamicPseudoType,
+					},
+				},
+			},
+			"anything": {
+				IsOptional: true,
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.DynamicPseudoType},
+				},
+			},
+		},
+	}
+	untypedTargets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "foo"},
+			},
+			ScopeId: lang.ScopeId("variable"),
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "local"},
+				lang.AttrStep{Name: "bar"},
+			},
+			ScopeId: lang.ScopeId("local"),
+		},
+	}
+	typedTargets := append(lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "typed"},
+			},
+			ScopeId: lang.ScopeId("variable"),
+			Type:    cty.String,
+		},
+	}, untypedTargets...)
+
+	testCases := []struct {
+		name           string
+		cfg            string
+		features       Features
+		targets        lang.ReferenceTargets
+		expectedLabels []string
+		expectedDetail string
+	}{
+		{
+			"disabled",
+			"value = \n",
+			Features{},
+			untypedTargets,
+			[]string{},
+			"",
+		},
+		{
+			"enabled",
+			"value = \n",
+			Features{ScopeFallbackCompletion: true},
+			untypedTargets,
+			[]string{"var.foo"},
+			"unknown type, reference",
+		},
+		{
+			"targets matching by type",
+			"value = \n",
+			Features{ScopeFallbackCompletion: true},
+			typedTargets,
+			[]string{"var.typed"},
+			"string",
+		},
+		{
+			"constraint without scope",
+			"anything = \n",
+			Features{ScopeFallbackCompletion: true},
+			untypedTargets,
+			[]string{},
+			"",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			d.SetFeatures(tc.features)
+			targets := tc.targets
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return targets
+			})
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, "= "))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+				if c.Detail != tc.expectedDetail {
+					t.Fatalf("unexpected detail of %q: %q, expected %q",
+						c.Label, c.Detail, tc.expectedDetail)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package decoder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// SemanticChangeKind represents the kind of a SemanticChange
+type SemanticChangeKind uint
+
+const (
+	NilSemanticChangeKind SemanticChangeKind = iota
+
+	// AddedSemanticChangeKind represents a block or an attribute
+	// present only in the new version
+	AddedSemanticChangeKind
+
+	// RemovedSemanticChangeKind represents a block or an attribute
+	// present only in the old version
+	RemovedSemanticChangeKind
+
+	// ChangedSemanticChangeKind represents an attribute
+	// whose value changed
+	ChangedSemanticChangeKind
+
+	// MovedSemanticChangeKind represents a block which moved
+	// among its siblings. Changes of its content (if any)
+	// are represented by separate changes.
+	MovedSemanticChangeKind
+
+	// ReformattedSemanticChangeKind represents an attribute whose
+	// value only differs in formatting (i.e. whitespace or comments)
+	ReformattedSemanticChangeKind
+)
+
+func (k SemanticChangeKind) String() string {
+	switch k {
+	case AddedSemanticChangeKind:
+		return "added"
+	case RemovedSemanticChangeKind:
+		return "removed"
+	case ChangedSemanticChangeKind:
+		return "changed"
+	case MovedSemanticChangeKind:
+		return "moved"
+	case ReformattedSemanticChangeKind:
+		return "reformatted"
+	}
+	return "nil"
+}
+
+// SemanticChange represents a change of a block or an attribute
+// between two versions of a file (see SemanticDiff)
+type SemanticChange struct {
+	Kind    SemanticChangeKind
+	IsBlock bool
+
+	// Path identifies the block or attribute within the file by types
+	// and labels of blocks, followed by the name of the attribute,
+	// e.g. resource.aws_instance.web.ami
+	Path []string
+
+	// Addr represents the address of the block or attribute
+	// as declared in the schema, if any
+	Addr lang.Address
+
+	// OldRange is the range in the old version,
+	// which is nil for added blocks and attributes
+	OldRange *hcl.Range
+	// NewRange is the range in the new version,
+	// which is nil for removed blocks and attributes
+	NewRange *hcl.Range
+}
+
+// SemanticDiff compares two versions of a file (in the native syntax)
+// and returns added, removed, changed and moved blocks and attributes,
+// e.g. to summarize changes in review tooling.
+//
+// Unlike a text diff, blocks are matched by their type and labels,
+// such that a moved block is not reported as removed and added,
+// and values which only differ in formatting are reported
+// as reformatted rather than changed.
+//
+// Changes are ordered by their path. Schema is required in order
+// to resolve addresses and method will return error if there isn't one.
+// Both versions must be free of syntax errors.
+func (d *Decoder) SemanticDiff(ctx context.Context, filename string, oldSrc, newSrc []byte) ([]SemanticChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	oldFile, diags := hclsyntax.ParseConfig(oldSrc, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	newFile, diags := hclsyntax.ParseConfig(newSrc, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	sd := &semanticDiff{
+		ctx:     ctx,
+		oldSrc:  oldSrc,
+		newSrc:  newSrc,
+		changes: make([]SemanticChange, 0),
+	}
+	err := sd.diffBodies(oldFile.Body.(*hclsyntax.Body), newFile.Body.(*hclsyntax.Body),
+		d.rootSchema, []string{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(sd.changes, func(i, j int) bool {
+		return strings.Join(sd.changes[i].Path, "\x00") < strings.Join(sd.changes[j].Path, "\x00")
+	})
+
+	return sd.changes, nil
+}
+
+type semanticDiff struct {
+	ctx            context.Context
+	oldSrc, newSrc []byte
+	changes        []SemanticChange
+}
+
+// diffBodies compares bodies of the same block (or the root bodies),
+// where bodyAddr is the address under which attributes of the body
+// are addressable (e.g. if the body is data), if any
+func (sd *semanticDiff) diffBodies(oldBody, newBody *hclsyntax.Body, bodySchema *schema.BodySchema, path []string, bodyAddr lang.Address) error {
+	if err := sd.ctx.Err(); err != nil {
+		return err
+	}
+
+	sd.diffAttributes(oldBody.Attributes, newBody.Attributes, bodySchema, path, bodyAddr)
+
+	oldKeys := blockKeys(oldBody.Blocks)
+	newKeys := blockKeys(newBody.Blocks)
+	oldIdxs := make(map[string]int, len(oldKeys))
+	for i, key := range oldKeys {
+		oldIdxs[key] = i
+	}
+	newIdxs := make(map[string]int, len(newKeys))
+	for i, key := range newKeys {
+		newIdxs[key] = i
+	}
+
+	for _, pair := range matchSymbolKeys(oldKeys, newKeys) {
+		oldIdx, newIdx := pair[0], pair[1]
+		isMoved := false
+
+		switch {
+		case newIdx < 0:
+			idx, ok := newIdxs[oldKeys[oldIdx]]
+			if !ok {
+				block := oldBody.Blocks[oldIdx]
+				sd.addBlockChange(RemovedSemanticChangeKind, block, nil, bodySchema, path)
+				continue
+			}
+			newIdx, isMoved = idx, true
+		case oldIdx < 0:
+			if _, ok := oldIdxs[newKeys[newIdx]]; !ok {
+				block := newBody.Blocks[newIdx]
+				sd.addBlockChange(AddedSemanticChangeKind, nil, block, bodySchema, path)
+			}
+			// moved blocks are compared when paired from the old side
+			continue
+		}
+
+		oldBlock, newBlock := oldBody.Blocks[oldIdx], newBody.Blocks[newIdx]
+		if isMoved {
+			sd.addBlockChange(MovedSemanticChangeKind, oldBlock, newBlock, bodySchema, path)
+		}
+
+		var blockSchema *schema.BodySchema
+		var blockAddr lang.Address
+		if bSchema, ok := blockSchemaForType(bodySchema, newBlock.Type); ok {
+			blockSchema, _ = mergeBlockBodySchemas(newBlock, bSchema)
+			if bSchema.Address != nil && bSchema.Address.BodyAsData {
+				blockAddr, _ = resolveBlockAddress(newBlock, bSchema.Address)
+			}
+		}
+		err := sd.diffBodies(oldBlock.Body, newBlock.Body, blockSchema, blockPath(path, newBlock), blockAddr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sd *semanticDiff) diffAttributes(oldAttrs, newAttrs hclsyntax.Attributes, bodySchema *schema.BodySchema, path []string, bodyAddr lang.Address) {
+	for name, oldAttr := range oldAttrs {
+		if _, ok := newAttrs[name]; !ok {
+			sd.addAttributeChange(RemovedSemanticChangeKind, name, oldAttr, nil, bodySchema, path, bodyAddr)
+		}
+	}
+
+	for name, newAttr := range newAttrs {
+		oldAttr, ok := oldAttrs[name]
+		if !ok {
+			sd.addAttributeChange(AddedSemanticChangeKind, name, nil, newAttr, bodySchema, path, bodyAddr)
+			continue
+		}
+
+		oldExpr := oldAttr.Expr.Range().SliceBytes(sd.oldSrc)
+		newExpr := newAttr.Expr.Range().SliceBytes(sd.newSrc)
+		if !tokensEqualIgnoringFormatting(oldExpr, newExpr) {
+			sd.addAttributeChange(ChangedSemanticChangeKind, name, oldAttr, newAttr, bodySchema, path, bodyAddr)
+			continue
+		}
+		if !bytes.Equal(oldAttr.SrcRange.SliceBytes(sd.oldSrc), newAttr.SrcRange.SliceBytes(sd.newSrc)) {
+			sd.addAttributeChange(ReformattedSemanticChangeKind, name, oldAttr, newAttr, bodySchema, path, bodyAddr)
+		}
+	}
+}
+
+func (sd *semanticDiff) addAttributeChange(kind SemanticChangeKind, name string, oldAttr, newAttr *hclsyntax.Attribute, bodySchema *schema.BodySchema, path []string, bodyAddr lang.Address) {
+	change := SemanticChange{
+		Kind: kind,
+		Path: appendPathElems(path, name),
+	}
+	if oldAttr != nil {
+		change.OldRange = oldAttr.SrcRange.Ptr()
+	}
+	if newAttr != nil {
+		change.NewRange = newAttr.SrcRange.Ptr()
+	}
+
+	if aSchema, ok := attributeSchemaForName(bodySchema, name); ok && aSchema.Address != nil {
+		change.Addr, _ = resolveAttributeAddress(name, aSchema.Address)
+	} else if len(bodyAddr) > 0 {
+		change.Addr = append(bodyAddr.Copy(), lang.AttrStep{Name: name})
+	}
+
+	sd.changes = append(sd.changes, change)
+}
+
+func (sd *semanticDiff) addBlockChange(kind SemanticChangeKind, oldBlock, newBlock *hclsyntax.Block, bodySchema *schema.BodySchema, path []string) {
+	block := newBlock
+	if block == nil {
+		block = oldBlock
+	}
+
+	change := SemanticChange{
+		Kind:    kind,
+		IsBlock: true,
+		Path:    blockPath(path, block),
+	}
+	if oldBlock != nil {
+		change.OldRange = oldBlock.Range().Ptr()
+	}
+	if newBlock != nil {
+		change.NewRange = newBlock.Range().Ptr()
+	}
+
+	if bSchema, ok := blockSchemaForType(bodySchema, block.Type); ok && bSchema.Address != nil {
+		change.Addr, _ = resolveBlockAddress(block, bSchema.Address)
+	}
+
+	sd.changes = append(sd.changes, change)
+}
+
+// blockKeys returns keys identifying blocks among their siblings
+// by their type and labels, where blocks of the same type and labels
+// are told apart by their order
+func blockKeys(blocks hclsyntax.Blocks) []string {
+	keys := make([]string, len(blocks))
+	seen := make(map[string]int, 0)
+	for i, block := range blocks {
+		key := strings.Join(append([]string{block.Type}, block.Labels...), "\x00")
+		keys[i] = fmt.Sprintf("%s#%d", key, seen[key])
+		seen[key]++
+	}
+	return keys
+}
+
+func blockPath(path []string, block *hclsyntax.Block) []string {
+	return appendPathElems(path, append([]string{block.Type}, block.Labels...)...)
+}
+
+func appendPathElems(path []string, elems ...string) []string {
+	newPath := make([]string, len(path), len(path)+len(elems))
+	copy(newPath, path)
+	return append(newPath, elems...)
+}
+
+// tokensEqualIgnoringFormatting returns true if both expressions
+// consist of the same tokens, disregarding newlines and comments
+func tokensEqualIgnoringFormatting(a, b []byte) bool {
+	aTokens := significantTokens(a)
+	bTokens := significantTokens(b)
+	if len(aTokens) != len(bTokens) {
+		return false
+	}
+	for i := range aTokens {
+		if aTokens[i].Type != bTokens[i].Type || !bytes.Equal(aTokens[i].Bytes, bTokens[i].Bytes) {
+			return false
+		}
+	}
+	return true
+}
+
+func significantTokens(src []byte) hclsyntax.Tokens {
+	tokens, _ := hclsyntax.LexExpression(src, "", hcl.InitialPos)
+	significant := make(hclsyntax.Tokens, 0, len(tokens))
+	for _, token := range tokens {
+		switch token.Type {
+		case hclsyntax.TokenNewline, hclsyntax.TokenComment, hclsyntax.TokenEOF:
+			continue
+		}
+		significant = append(significant, token)
+	}
+	return significant
+}
@@ -0,0 +1,212 @@
+package decoder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var semanticDiffSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"region": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+			Address: &schema.AttributeAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.StaticStep{Name: "region"},
+				},
+			},
+		},
+	},
+	Blocks: map[string]*schema.BlockSchema{
+		"resource": {
+			Labels: []*schema.LabelSchema{
+				{Name: "type"},
+				{Name: "name"},
+			},
+			Address: &schema.BlockAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.LabelStep{Index: 0},
+					schema.LabelStep{Index: 1},
+				},
+				BodyAsData: true,
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"ami": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.String),
+					},
+					"tags": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.Map(cty.String)),
+					},
+				},
+				Blocks: map[string]*schema.BlockSchema{
+					"disk": {
+						Body: &schema.BodySchema{
+							Attributes: map[string]*schema.AttributeSchema{
+								"size": {
+									IsOptional: true,
+									Expr:       schema.LiteralTypeOnly(cty.Number),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_SemanticDiff(t *testing.T) {
+	testCases := []struct {
+		name            string
+		oldCfg          string
+		newCfg          string
+		expectedChanges []string
+	}{
+		{
+			"no change",
+			`region = "eu"
+resource "vm" "a" {
+  ami = "foo"
+}
+`,
+			`region = "eu"
+resource "vm" "a" {
+  ami = "foo"
+}
+`,
+			[]string{},
+		},
+		{
+			"attributes added, removed and changed",
+			`region = "eu"
+resource "vm" "a" {
+  ami = "foo"
+}
+`,
+			`resource "vm" "a" {
+  ami  = "bar"
+  tags = {}
+}
+`,
+			[]string{
+				"removed region (region) 1,1-1,14 -> -",
+				`changed resource.vm.a.ami (vm.a.ami) 3,3-3,14 -> 2,3-2,15`,
+				`added resource.vm.a.tags (vm.a.tags) - -> 3,3-3,12`,
+			},
+		},
+		{
+			"blocks added and removed",
+			`resource "vm" "a" {}
+resource "vm" "b" {}
+`,
+			`resource "vm" "a" {}
+resource "vm" "c" {
+  disk {}
+}
+`,
+			[]string{
+				"removed block resource.vm.b (vm.b) 2,1-2,21 -> -",
+				"added block resource.vm.c (vm.c) - -> 2,1-4,2",
+			},
+		},
+		{
+			"block moved",
+			`resource "vm" "a" {}
+resource "vm" "b" {
+  ami = "foo"
+}
+`,
+			`resource "vm" "b" {
+  ami = "bar"
+}
+resource "vm" "a" {}
+`,
+			[]string{
+				"moved block resource.vm.a (vm.a) 1,1-1,21 -> 4,1-4,21",
+				"changed resource.vm.b.ami (vm.b.ami) 3,3-3,14 -> 2,3-2,14",
+			},
+		},
+		{
+			"formatting only",
+			`resource "vm" "a" {
+  tags = { env = "prod", team = "a" } # inline
+  disk {
+    size = 10
+  }
+}
+`,
+			`resource "vm" "a" {
+  tags = {
+    env = "prod", team = "a"
+  }
+  disk {
+    size   =   10
+  }
+}
+`,
+			[]string{
+				"reformatted resource.vm.a.disk.size 4,5-4,14 -> 6,5-6,18",
+				"reformatted resource.vm.a.tags (vm.a.tags) 2,3-2,38 -> 2,3-4,4",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(semanticDiffSchema)
+
+			changes, err := d.SemanticDiff(context.Background(), "test.tf", []byte(tc.oldCfg), []byte(tc.newCfg))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			given := make([]string, len(changes))
+			for i, change := range changes {
+				given[i] = semanticChangeString(change)
+			}
+			if diff := cmp.Diff(tc.expectedChanges, given); diff != "" {
+				t.Fatalf("unexpected changes: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_SemanticDiff_syntaxError(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(semanticDiffSchema)
+
+	_, err := d.SemanticDiff(context.Background(), "test.tf", []byte("region = \"eu\"\n"), []byte("region = \n"))
+	if err == nil {
+		t.Fatal("expected error for invalid syntax")
+	}
+}
+
+func semanticChangeString(change SemanticChange) string {
+	s := change.Kind.String()
+	if change.IsBlock {
+		s += " block"
+	}
+	s += " " + strings.Join(change.Path, ".")
+	if len(change.Addr) > 0 {
+		s += fmt.Sprintf(" (%s)", change.Addr)
+	}
+
+	oldRng, newRng := "-", "-"
+	if change.OldRange != nil {
+		oldRng = rangeWithoutFilename(change.OldRange)
+	}
+	if change.NewRange != nil {
+		newRng = rangeWithoutFilename(change.NewRange)
+	}
+	return fmt.Sprintf("%s %s -> %s", s, oldRng, newRng)
+}
@@ -12,13 +12,13 @@ import (
 
 // SemanticTokensInFile returns a sequence of semantic tokens
 // within the config file.
-func (d *Decoder) SemanticTokensInFile(filename string) ([]lang.SemanticToken, error) {
-	f, err := d.fileByName(filename)
-	if err != nil {
-		return nil, err
-	}
+func (d *Decoder) SemanticTokensInFile(filename string, opts ...RequestOption) ([]lang.SemanticToken, error) {
+	d = d.withRequestOptions(opts)
 
-	body, err := d.bodyForFileAndPos(filename, f, hcl.InitialPos)
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	bodies, err := d.bodiesForFile(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +27,10 @@ func (d *Decoder) SemanticTokensInFile(filename string) ([]lang.SemanticToken, e
 		return []lang.SemanticToken{}, nil
 	}
 
-	tokens := d.tokensForBody(body, d.rootSchema, false)
+	tokens := make([]lang.SemanticToken, 0)
+	for _, body := range bodies {
+		tokens = append(tokens, d.tokensForBody(body, d.rootSchema, false)...)
+	}
 
 	sort.Slice(tokens, func(i, j int) bool {
 		return tokens[i].Range.Start.Byte < tokens[j].Range.Start.Byte
@@ -44,13 +47,10 @@ func (d *Decoder) tokensForBody(body *hclsyntax.Body, bodySchema *schema.BodySch
 	}
 
 	for name, attr := range body.Attributes {
-		attrSchema, ok := bodySchema.Attributes[name]
+		attrSchema, ok := attributeSchemaForName(bodySchema, name)
 		if !ok {
-			if bodySchema.AnyAttribute == nil {
-				// unknown attribute
-				continue
-			}
-			attrSchema = bodySchema.AnyAttribute
+			// unknown attribute
+			continue
 		}
 
 		modifiers := make([]lang.SemanticTokenModifier, 0)
@@ -60,10 +60,16 @@ func (d *Decoder) tokensForBody(body *hclsyntax.Body, bodySchema *schema.BodySch
 		if attrSchema.IsDeprecated {
 			modifiers = append(modifiers, lang.TokenModifierDeprecated)
 		}
+		if attrSchema.IsComputed && !attrSchema.IsOptional {
+			modifiers = append(modifiers, lang.TokenModifierReadonly)
+		}
+		if attrSchema.IsBuiltin {
+			modifiers = append(modifiers, lang.TokenModifierDefaultLibrary)
+		}
 
 		tokens = append(tokens, lang.SemanticToken{
-			Type:      lang.TokenAttrName,
-			Modifiers: modifiers,
+			Type:      d.customTokenType(attrSchema.SemanticToken, lang.TokenAttrName),
+			Modifiers: d.withCustomTokenModifiers(attrSchema.SemanticToken, modifiers),
 			Range:     attr.NameRange,
 		})
 
@@ -72,7 +78,7 @@ func (d *Decoder) tokensForBody(body *hclsyntax.Body, bodySchema *schema.BodySch
 	}
 
 	for _, block := range body.Blocks {
-		blockSchema, ok := bodySchema.Blocks[block.Type]
+		blockSchema, ok := blockSchemaForType(bodySchema, block.Type)
 		if !ok {
 			// unknown block
 			continue
@@ -85,13 +91,18 @@ func (d *Decoder) tokensForBody(body *hclsyntax.Body, bodySchema *schema.BodySch
 		if blockSchema.IsDeprecated {
 			modifiers = append(modifiers, lang.TokenModifierDeprecated)
 		}
+		if blockSchema.IsBuiltin {
+			modifiers = append(modifiers, lang.TokenModifierDefaultLibrary)
+		}
 
 		tokens = append(tokens, lang.SemanticToken{
-			Type:      lang.TokenBlockType,
-			Modifiers: modifiers,
+			Type:      d.customTokenType(blockSchema.SemanticToken, lang.TokenBlockType),
+			Modifiers: d.withCustomTokenModifiers(blockSchema.SemanticToken, modifiers),
 			Range:     block.TypeRange,
 		})
 
+		depSchema, _, hasDepSchema := NewBlockSchema(blockSchema).DependentBodySchema(block)
+
 		for i, labelRange := range block.LabelRanges {
 			if i+1 > len(blockSchema.Labels) {
 				// unknown label
@@ -103,11 +114,16 @@ func (d *Decoder) tokensForBody(body *hclsyntax.Body, bodySchema *schema.BodySch
 			modifiers := make([]lang.SemanticTokenModifier, 0)
 			if labelSchema.IsDepKey {
 				modifiers = append(modifiers, lang.TokenModifierDependent)
+
+				// label selecting a deprecated body (e.g. resource type)
+				if hasDepSchema && depSchema.IsDeprecated {
+					modifiers = append(modifiers, lang.TokenModifierDeprecated)
+				}
 			}
 
 			tokens = append(tokens, lang.SemanticToken{
-				Type:      lang.TokenBlockLabel,
-				Modifiers: modifiers,
+				Type:      d.customTokenType(labelSchema.SemanticToken, lang.TokenBlockLabel),
+				Modifiers: d.withCustomTokenModifiers(labelSchema.SemanticToken, modifiers),
 				Range:     labelRange,
 			})
 		}
@@ -116,8 +132,7 @@ func (d *Decoder) tokensForBody(body *hclsyntax.Body, bodySchema *schema.BodySch
 			tokens = append(tokens, d.tokensForBody(block.Body, blockSchema.Body, false)...)
 		}
 
-		depSchema, _, ok := NewBlockSchema(blockSchema).DependentBodySchema(block)
-		if ok {
+		if hasDepSchema {
 			tokens = append(tokens, d.tokensForBody(block.Body, depSchema, true)...)
 		}
 	}
@@ -143,8 +158,9 @@ func (d *Decoder) tokensForExpression(expr hclsyntax.Expression, constraints Exp
 		}
 
 		te, ok := constraints.TraversalExpr()
-		if ok && d.refTargetReader != nil {
-			refs := ReferenceTargets(d.refTargetReader())
+		readTargets := d.referenceTargetReader()
+		if ok && readTargets != nil {
+			refs := ReferenceTargets(readTargets())
 			traversal := eType.AsTraversal()
 
 			origin, err := TraversalToReferenceOrigin(traversal, te)
@@ -152,7 +168,7 @@ func (d *Decoder) tokensForExpression(expr hclsyntax.Expression, constraints Exp
 				return tokens
 			}
 
-			_, err = refs.FirstTargetableBy(origin)
+			_, err = d.referenceTargetForOrigin(refs, origin)
 			if err != nil {
 				return tokens
 			}
@@ -0,0 +1,109 @@
+package decoder
+
+import (
+	"errors"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+)
+
+// RegisterSemanticTokenType registers a custom (dialect-specific)
+// token type, which can then be referenced by name from the schema
+// (see schema.SemanticToken).
+//
+// Registering the same name again returns the same token type.
+// Types can be registered while queries are being served.
+func (d *Decoder) RegisterSemanticTokenType(name string) (lang.SemanticTokenType, error) {
+	if name == "" {
+		return lang.TokenNil, errors.New("token type name must not be empty")
+	}
+
+	d.tokensMu.Lock()
+	defer d.tokensMu.Unlock()
+
+	for i, typeName := range d.customTokenTypes {
+		if typeName == name {
+			return lang.SemanticTokenType(lang.CustomTokenTypeOffset + i), nil
+		}
+	}
+
+	d.customTokenTypes = append(d.customTokenTypes, name)
+	return lang.SemanticTokenType(lang.CustomTokenTypeOffset + len(d.customTokenTypes) - 1), nil
+}
+
+// RegisterSemanticTokenModifier registers a custom (dialect-specific)
+// token modifier, which can then be referenced by name from the schema
+// (see schema.SemanticToken).
+//
+// Registering the same name again returns the same token modifier.
+// Modifiers can be registered while queries are being served.
+func (d *Decoder) RegisterSemanticTokenModifier(name string) (lang.SemanticTokenModifier, error) {
+	if name == "" {
+		return lang.TokenModifierNil, errors.New("token modifier name must not be empty")
+	}
+
+	d.tokensMu.Lock()
+	defer d.tokensMu.Unlock()
+
+	for i, modifierName := range d.customTokenModifiers {
+		if modifierName == name {
+			return lang.SemanticTokenModifier(lang.CustomTokenModifierOffset + i), nil
+		}
+	}
+
+	d.customTokenModifiers = append(d.customTokenModifiers, name)
+	return lang.SemanticTokenModifier(lang.CustomTokenModifierOffset + len(d.customTokenModifiers) - 1), nil
+}
+
+// SemanticTokensLegend returns custom token types and modifiers
+// registered with the decoder
+func (d *Decoder) SemanticTokensLegend() lang.SemanticTokensLegend {
+	d.tokensMu.RLock()
+	defer d.tokensMu.RUnlock()
+
+	legend := lang.SemanticTokensLegend{
+		TokenTypes:     make([]string, len(d.customTokenTypes)),
+		TokenModifiers: make([]string, len(d.customTokenModifiers)),
+	}
+	copy(legend.TokenTypes, d.customTokenTypes)
+	copy(legend.TokenModifiers, d.customTokenModifiers)
+
+	return legend
+}
+
+// customTokenType returns the custom token type referenced
+// by the schema, or the given default type
+func (d *Decoder) customTokenType(st *schema.SemanticToken, defaultType lang.SemanticTokenType) lang.SemanticTokenType {
+	if st == nil || st.Type == "" {
+		return defaultType
+	}
+
+	d.tokensMu.RLock()
+	defer d.tokensMu.RUnlock()
+	for i, typeName := range d.customTokenTypes {
+		if typeName == st.Type {
+			return lang.SemanticTokenType(lang.CustomTokenTypeOffset + i)
+		}
+	}
+	return defaultType
+}
+
+// withCustomTokenModifiers returns the given modifiers
+// along with any custom modifiers referenced by the schema
+func (d *Decoder) withCustomTokenModifiers(st *schema.SemanticToken, modifiers []lang.SemanticTokenModifier) []lang.SemanticTokenModifier {
+	if st == nil {
+		return modifiers
+	}
+
+	d.tokensMu.RLock()
+	defer d.tokensMu.RUnlock()
+	for _, name := range st.Modifiers {
+		for i, modifierName := range d.customTokenModifiers {
+			if modifierName == name {
+				modifiers = append(modifiers, lang.SemanticTokenModifier(lang.CustomTokenModifierOffset+i))
+				break
+			}
+		}
+	}
+	return modifiers
+}
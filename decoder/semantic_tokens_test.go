@@ -854,3 +854,272 @@ variable "bah" {
 		t.Fatalf("unexpected tokens: %s", diff)
 	}
 }
+
+func TestDecoder_SemanticTokensInFile_modifiers(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"lifecycle": {
+				IsBuiltin: true,
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"id": {
+							Expr:       schema.LiteralTypeOnly(cty.String),
+							IsComputed: true,
+						},
+					},
+				},
+			},
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"count": {
+							Expr:      schema.LiteralTypeOnly(cty.Number),
+							IsBuiltin: true,
+						},
+					},
+				},
+				DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{
+							{Index: 0, Value: "old_type"},
+						},
+					}): {
+						IsDeprecated: true,
+					},
+				},
+			},
+		},
+	})
+
+	testCfg := []byte(`lifecycle {
+  id = "x"
+}
+resource "old_type" "foo" {
+  count = 1
+}
+`)
+
+	f, pDiags := hclsyntax.ParseConfig(testCfg, "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := d.SemanticTokensInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTokens := []lang.SemanticToken{
+		{ // lifecycle
+			Type: lang.TokenBlockType,
+			Modifiers: []lang.SemanticTokenModifier{
+				lang.TokenModifierDefaultLibrary,
+			},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			},
+		},
+		{ // id
+			Type: lang.TokenAttrName,
+			Modifiers: []lang.SemanticTokenModifier{
+				lang.TokenModifierReadonly,
+			},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 3, Byte: 14},
+				End:      hcl.Pos{Line: 2, Column: 5, Byte: 16},
+			},
+		},
+		{ // "x"
+			Type:      lang.TokenString,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 8, Byte: 19},
+				End:      hcl.Pos{Line: 2, Column: 11, Byte: 22},
+			},
+		},
+		{ // resource
+			Type:      lang.TokenBlockType,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 4, Column: 1, Byte: 25},
+				End:      hcl.Pos{Line: 4, Column: 9, Byte: 33},
+			},
+		},
+		{ // "old_type"
+			Type: lang.TokenBlockLabel,
+			Modifiers: []lang.SemanticTokenModifier{
+				lang.TokenModifierDependent,
+				lang.TokenModifierDeprecated,
+			},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 4, Column: 10, Byte: 34},
+				End:      hcl.Pos{Line: 4, Column: 20, Byte: 44},
+			},
+		},
+		{ // "foo"
+			Type:      lang.TokenBlockLabel,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 4, Column: 21, Byte: 45},
+				End:      hcl.Pos{Line: 4, Column: 26, Byte: 50},
+			},
+		},
+		{ // count
+			Type: lang.TokenAttrName,
+			Modifiers: []lang.SemanticTokenModifier{
+				lang.TokenModifierDefaultLibrary,
+			},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 5, Column: 3, Byte: 55},
+				End:      hcl.Pos{Line: 5, Column: 8, Byte: 60},
+			},
+		},
+		{ // 1
+			Type:      lang.TokenNumber,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 5, Column: 11, Byte: 63},
+				End:      hcl.Pos{Line: 5, Column: 12, Byte: 64},
+			},
+		},
+	}
+
+	diff := cmp.Diff(expectedTokens, tokens)
+	if diff != "" {
+		t.Fatalf("unexpected tokens: %s", diff)
+	}
+}
+
+func TestDecoder_SemanticTokensInFile_customTokens(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{
+						Name: "type",
+						SemanticToken: &schema.SemanticToken{
+							Type:      "resourceType",
+							Modifiers: []string{"unknown", "managed"},
+						},
+					},
+					{
+						Name: "name",
+						SemanticToken: &schema.SemanticToken{
+							Type: "unknown",
+						},
+					},
+				},
+				SemanticToken: &schema.SemanticToken{
+					Modifiers: []string{"managed"},
+				},
+			},
+		},
+	})
+
+	resourceType, err := d.RegisterSemanticTokenType("resourceType")
+	if err != nil {
+		t.Fatal(err)
+	}
+	managed, err := d.RegisterSemanticTokenModifier("managed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameType, err := d.RegisterSemanticTokenType("resourceType")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameType != resourceType {
+		t.Fatalf("expected re-registered type to be %d, %d given", resourceType, sameType)
+	}
+	_, err = d.RegisterSemanticTokenType("")
+	if err == nil {
+		t.Fatal("expected error for empty token type name")
+	}
+
+	expectedLegend := lang.SemanticTokensLegend{
+		TokenTypes:     []string{"resourceType"},
+		TokenModifiers: []string{"managed"},
+	}
+	legend := d.SemanticTokensLegend()
+	if diff := cmp.Diff(expectedLegend, legend); diff != "" {
+		t.Fatalf("unexpected legend: %s", diff)
+	}
+	if name, ok := legend.TokenTypeName(resourceType); !ok || name != "resourceType" {
+		t.Fatalf("unexpected name of token type %d: %q", resourceType, name)
+	}
+	if name, ok := legend.TokenModifierName(managed); !ok || name != "managed" {
+		t.Fatalf("unexpected name of token modifier %d: %q", managed, name)
+	}
+
+	testCfg := []byte(`resource "aws_instance" "foo" {
+}
+`)
+
+	f, pDiags := hclsyntax.ParseConfig(testCfg, "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err = d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := d.SemanticTokensInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTokens := []lang.SemanticToken{
+		{ // resource
+			Type:      lang.TokenBlockType,
+			Modifiers: []lang.SemanticTokenModifier{managed},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:      hcl.Pos{Line: 1, Column: 9, Byte: 8},
+			},
+		},
+		{ // "aws_instance"
+			Type:      resourceType,
+			Modifiers: []lang.SemanticTokenModifier{managed},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+				End:      hcl.Pos{Line: 1, Column: 24, Byte: 23},
+			},
+		},
+		{ // "foo"
+			Type:      lang.TokenBlockLabel,
+			Modifiers: []lang.SemanticTokenModifier{},
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 25, Byte: 24},
+				End:      hcl.Pos{Line: 1, Column: 30, Byte: 29},
+			},
+		},
+	}
+
+	diff := cmp.Diff(expectedTokens, tokens)
+	if diff != "" {
+		t.Fatalf("unexpected tokens: %s", diff)
+	}
+}
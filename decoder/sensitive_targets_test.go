@@ -0,0 +1,221 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var sensitiveSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"secret": {
+			Labels: []*schema.LabelSchema{
+				{Name: "name"},
+			},
+			Address: &schema.BlockAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.StaticStep{Name: "secret"},
+					schema.LabelStep{Index: 0},
+				},
+				ScopeId:    lang.ScopeId("secret"),
+				BodyAsData: true,
+				InferBody:  true,
+				Sensitive:  true,
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"value": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.String),
+					},
+				},
+			},
+		},
+	},
+	Attributes: map[string]*schema.AttributeSchema{
+		"password": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+			Address: &schema.AttributeAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.StaticStep{Name: "password"},
+				},
+				AsExprType: true,
+				Sensitive:  true,
+			},
+		},
+		"name": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+			Address: &schema.AttributeAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.StaticStep{Name: "name"},
+				},
+				AsExprType: true,
+			},
+		},
+		"log_message": {
+			IsOptional: true,
+			IsInsecure: true,
+			Expr: schema.ExprConstraints{
+				schema.TraversalExpr{OfType: cty.String},
+			},
+		},
+		"token": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.TraversalExpr{OfType: cty.String},
+			},
+		},
+	},
+}
+
+const sensitiveCfg = `secret "db" {
+  value = "foo"
+}
+password = "bar"
+name = "baz"
+`
+
+func TestCollectReferenceTargets_sensitive(t *testing.T) {
+	d := newTestDecoder(t, sensitiveSchema, map[string]string{
+		"secrets.tf": sensitiveCfg,
+		"test.tf":    "",
+	})
+
+	targets, err := d.CollectReferenceTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sensitiveAddrs := make(map[string]bool, 0)
+	ReferenceTargets(targets).DeepWalk(func(target lang.ReferenceTarget) error {
+		sensitiveAddrs[target.Addr.String()] = target.Sensitive
+		return nil
+	})
+
+	expectedAddrs := map[string]bool{
+		"name":            false,
+		"password":        true,
+		"secret.db":       true,
+		"secret.db.value": true,
+	}
+	if diff := cmp.Diff(expectedAddrs, sensitiveAddrs); diff != "" {
+		t.Fatalf("unexpected sensitive targets: %s", diff)
+	}
+}
+
+func TestDecoder_HoverAtPos_sensitive(t *testing.T) {
+	d := newTestDecoder(t, sensitiveSchema, map[string]string{
+		"secrets.tf": sensitiveCfg,
+		"test.tf":    "token = password\n",
+	})
+
+	data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedContent := lang.Markdown("`password`\n_string_\n\n" +
+		"**Warning:** This value is sensitive and should not be exposed.")
+	if diff := cmp.Diff(expectedContent, data.Content); diff != "" {
+		t.Fatalf("unexpected hover content: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_sensitive(t *testing.T) {
+	d := newTestDecoder(t, sensitiveSchema, map[string]string{
+		"secrets.tf": sensitiveCfg,
+		"test.tf":    "token = \n",
+	})
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 9, Byte: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details := make(map[string]string, 0)
+	for _, c := range candidates.List {
+		details[c.Label] = c.Detail
+	}
+	expectedDetails := map[string]string{
+		"name":     "string",
+		"password": "sensitive, string",
+	}
+	if diff := cmp.Diff(expectedDetails, details); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_ValidateFile_sensitive(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"non-sensitive in insecure attribute",
+			"log_message = name\n",
+			hcl.Diagnostics{},
+		},
+		{
+			"sensitive in secure attribute",
+			"token = password\n",
+			hcl.Diagnostics{},
+		},
+		{
+			"sensitive in insecure attribute",
+			"log_message = password\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagWarning,
+					Summary:  "Sensitive value in insecure attribute",
+					Detail:   `password is sensitive and may be exposed via "log_message"`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 15, Byte: 14},
+						End:      hcl.Pos{Line: 1, Column: 23, Byte: 22},
+					},
+				},
+			},
+		},
+		{
+			"nested sensitive in insecure attribute",
+			"log_message = secret.db.value\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagWarning,
+					Summary:  "Sensitive value in insecure attribute",
+					Detail:   `secret.db.value is sensitive and may be exposed via "log_message"`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 15, Byte: 14},
+						End:      hcl.Pos{Line: 1, Column: 30, Byte: 29},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, sensitiveSchema, map[string]string{
+				"secrets.tf": sensitiveCfg,
+				"test.tf":    tc.cfg,
+			})
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package decoder
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// parseSize splits a (possibly partially typed) size into
+// the number and the unit, e.g. "512" and "Mi" of "512Mi".
+//
+// It returns false if the size doesn't start with a number,
+// or if the number isn't followed by letters only.
+func parseSize(size string) (number, unit string, ok bool) {
+	hasDot := false
+	i := 0
+	for ; i < len(size); i++ {
+		c := size[i]
+		if c == '.' && !hasDot && i > 0 {
+			hasDot = true
+			continue
+		}
+		if c < '0' || c > '9' {
+			break
+		}
+	}
+	number, unit = size[:i], size[i:]
+	if number == "" || strings.HasSuffix(number, ".") {
+		return "", "", false
+	}
+	for _, r := range unit {
+		if !unicode.IsLetter(r) {
+			return "", "", false
+		}
+	}
+	return number, unit, true
+}
+
+// sizeCandidatesAtPos returns candidates completing the unit of
+// a partially typed size (e.g. 512 or "512M") of the attribute.
+//
+// The raw source is inspected, as a number followed by a partial
+// unit outside of quotes (e.g. 512M) isn't parsed as a single expression.
+func (d *Decoder) sizeCandidatesAtPos(attr *hclsyntax.Attribute, aSchema *schema.AttributeSchema, pos hcl.Pos) (lang.Candidates, bool) {
+	se, ok := ExprConstraints(aSchema.Expr).SizeExpr()
+	if !ok {
+		return lang.Candidates{}, false
+	}
+
+	eqEnd := attr.EqualsRange.End
+	if pos.Line != eqEnd.Line || pos.Byte <= eqEnd.Byte {
+		return lang.Candidates{}, false
+	}
+	src, err := d.bytesForFile(attr.SrcRange.Filename)
+	if err != nil || pos.Byte > len(src) {
+		return lang.Candidates{}, false
+	}
+
+	typed := strings.TrimLeft(string(src[eqEnd.Byte:pos.Byte]), " \t")
+	isQuoted := strings.HasPrefix(typed, `"`)
+	if isQuoted {
+		typed = typed[1:]
+	}
+	number, unitPrefix, ok := parseSize(typed)
+	if !ok {
+		return lang.Candidates{}, false
+	}
+
+	// units may contain non-ASCII letters (e.g. µs),
+	// which take up more than one byte, but a single column
+	editRng := hcl.Range{
+		Filename: attr.SrcRange.Filename,
+		Start: hcl.Pos{
+			Line:   pos.Line,
+			Column: pos.Column - utf8.RuneCountInString(typed),
+			Byte:   pos.Byte - len(typed),
+		},
+		End: pos,
+	}
+
+	candidates := lang.NewCandidates()
+	for _, unit := range se.Units {
+		if !strings.HasPrefix(strings.ToLower(unit), strings.ToLower(unitPrefix)) {
+			continue
+		}
+		size := number + unit
+		newText := size
+		if !isQuoted {
+			newText = fmt.Sprintf("%q", size)
+		}
+		candidates.List = append(candidates.List, lang.Candidate{
+			Label:       size,
+			Detail:      se.FriendlyName(),
+			Description: se.Description,
+			Kind:        lang.StringCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: newText,
+				Snippet: newText,
+				Range:   editRng,
+			},
+		})
+	}
+	candidates.IsComplete = true
+
+	return candidates, true
+}
+
+// hoverContentForSize returns hover content of a size
+// listing the accepted units
+func hoverContentForSize(se schema.SizeExpr) string {
+	units := make([]string, len(se.Units))
+	for i, unit := range se.Units {
+		units[i] = fmt.Sprintf("`%s`", unit)
+	}
+	content := fmt.Sprintf("_%s_ in %s", se.FriendlyName(), strings.Join(units, ", "))
+	if se.Description.Value != "" {
+		content += "\n\n" + se.Description.Value
+	}
+	return content
+}
+
+// validateSize reports a size which lacks a number or a unit,
+// or whose unit is not accepted, along with a fix correcting
+// the spelling of a unit where there is an obvious candidate
+func (d *Decoder) validateSize(name string, expr hcl.Expression, ec ExprConstraints, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	tplExpr, ok := expr.(*hclsyntax.TemplateExpr)
+	if !ok {
+		return diags
+	}
+	c, ok := ec.constraintForExpr(tplExpr)
+	if !ok {
+		return diags
+	}
+	se, ok := c.(schema.SizeExpr)
+	if !ok || len(se.Units) == 0 {
+		return diags
+	}
+	val, ok := stringValFromTemplateExpr(tplExpr)
+	if !ok {
+		// interpolated value cannot be validated
+		return diags
+	}
+
+	size := val.AsString()
+	number, unit, ok := parseSize(size)
+	if !ok || unit == "" {
+		diags = append(diags, d.newDiagnostic(CodeInvalidSize, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid size",
+			Detail: fmt.Sprintf("Value of %q must be a number followed by a unit (%s), e.g. \"1%s\"",
+				name, strings.Join(se.Units, ", "), se.Units[0]),
+			Subject: tplExpr.SrcRange.Ptr(),
+		}))
+		return diags
+	}
+
+	for _, u := range se.Units {
+		if u == unit {
+			return diags
+		}
+	}
+
+	suggestedUnit, ok := suggestedSizeUnit(unit, se.Units)
+	if !ok {
+		diags = append(diags, d.newDiagnostic(CodeInvalidSizeUnit, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid size unit",
+			Detail: fmt.Sprintf("%q is not a valid unit of %q, expected one of: %s",
+				unit, name, strings.Join(se.Units, ", ")),
+			Subject: tplExpr.SrcRange.Ptr(),
+		}))
+		return diags
+	}
+
+	diag := d.newDiagnostic(CodeInvalidSizeUnit, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid size unit",
+		Detail:   fmt.Sprintf("%q is not a valid unit of %q, did you mean %q?", unit, name, suggestedUnit),
+		Subject:  tplExpr.SrcRange.Ptr(),
+	})
+	diags = append(diags, diag)
+
+	if fixes != nil {
+		newText := fmt.Sprintf("%q", number+suggestedUnit)
+		*fixes = append(*fixes, lang.DiagnosticFix{
+			Title:      fmt.Sprintf("Replace with %s", newText),
+			Diagnostic: diag,
+			Edits: []lang.TextEdit{
+				{
+					Range:   tplExpr.SrcRange,
+					NewText: newText,
+					Snippet: newText,
+				},
+			},
+		})
+	}
+
+	return diags
+}
+
+// suggestedSizeUnit returns the accepted unit which differs
+// from the given one only by case, or by a single typo
+func suggestedSizeUnit(unit string, units []string) (string, bool) {
+	for _, u := range units {
+		if strings.EqualFold(u, unit) {
+			return u, true
+		}
+	}
+	if len([]rune(unit)) < minFuzzyPrefixLength {
+		// short units would match nearly any other unit
+		return "", false
+	}
+	for _, u := range units {
+		if isWithinOneEdit([]rune(unit), []rune(u)) {
+			return u, true
+		}
+	}
+	return "", false
+}
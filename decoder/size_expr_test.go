@@ -0,0 +1,300 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+)
+
+var sizeSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"memory": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.SizeExpr{
+					Units:       []string{"Ki", "Mi", "Gi", "MB"},
+					Description: lang.PlainText("Memory limit"),
+				},
+			},
+		},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_size(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cfg                string
+		posAfter           string
+		expectedCandidates string
+	}{
+		{
+			"number without quotes",
+			`memory = 512
+`,
+			"512",
+			`
+complete: true
+candidates: 4
+---
+label: "512Ki"
+kind: StringCandidateKind
+detail: "size"
+description (PlainTextKind): "Memory limit"
+edit: test.tf:1,10-1,13 "\"512Ki\""
+---
+label: "512Mi"
+kind: StringCandidateKind
+detail: "size"
+description (PlainTextKind): "Memory limit"
+edit: test.tf:1,10-1,13 "\"512Mi\""
+---
+label: "512Gi"
+kind: StringCandidateKind
+detail: "size"
+description (PlainTextKind): "Memory limit"
+edit: test.tf:1,10-1,13 "\"512Gi\""
+---
+label: "512MB"
+kind: StringCandidateKind
+detail: "size"
+description (PlainTextKind): "Memory limit"
+edit: test.tf:1,10-1,13 "\"512MB\""
+`,
+		},
+		{
+			"partial unit without quotes",
+			`memory = 512m
+`,
+			"512m",
+			`
+complete: true
+candidates: 2
+---
+label: "512Mi"
+kind: StringCandidateKind
+detail: "size"
+description (PlainTextKind): "Memory limit"
+edit: test.tf:1,10-1,14 "\"512Mi\""
+---
+label: "512MB"
+kind: StringCandidateKind
+detail: "size"
+description (PlainTextKind): "Memory limit"
+edit: test.tf:1,10-1,14 "\"512MB\""
+`,
+		},
+		{
+			"partial unit in quotes",
+			`memory = "1.5G"
+`,
+			"1.5G",
+			`
+complete: true
+candidates: 1
+---
+label: "1.5Gi"
+kind: StringCandidateKind
+detail: "size"
+description (PlainTextKind): "Memory limit"
+edit: test.tf:1,11-1,15 "1.5Gi"
+`,
+		},
+		{
+			"no number",
+			`memory = "G"
+`,
+			`"G`,
+			`
+complete: true
+candidates: 0
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, sizeSchema, map[string]string{"test.tf": tc.cfg})
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_sizeNonASCIIUnit(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"timeout": {
+				IsOptional: true,
+				Expr: schema.ExprConstraints{
+					schema.SizeExpr{Units: []string{"µs", "ms"}},
+				},
+			},
+		},
+	}
+	cfg := `timeout = "10µ"
+`
+	d := newTestDecoder(t, bodySchema, map[string]string{"test.tf": cfg})
+
+	// µ takes up two bytes, but a single column
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 15, Byte: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	langtest.AssertCandidates(t, `
+complete: true
+candidates: 1
+---
+label: "10µs"
+kind: StringCandidateKind
+detail: "size"
+edit: test.tf:1,12-1,15 "10µs"
+`, candidates)
+}
+
+func TestDecoder_HoverAtPos_size(t *testing.T) {
+	cfg := `memory = "512Mi"
+`
+	d := newTestDecoder(t, sizeSchema, map[string]string{"test.tf": cfg})
+
+	data, err := d.HoverAtPos("test.tf", posInCfg(t, cfg, `"51`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedContent := lang.Markdown("_size_ in `Ki`, `Mi`, `Gi`, `MB`\n\nMemory limit")
+	if diff := cmp.Diff(expectedContent, data.Content); diff != "" {
+		t.Fatalf("unexpected hover content: %s", diff)
+	}
+}
+
+func TestDecoder_ValidateFileWithFixes_size(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		expectedSummary string
+		expectedDetail  string
+		expectedFix     string
+		expectedCfg     string
+	}{
+		{
+			"valid",
+			`memory = "512Mi"
+`,
+			"",
+			"",
+			"",
+			"",
+		},
+		{
+			"interpolated",
+			`memory = "${var.size}Mi"
+`,
+			"",
+			"",
+			"",
+			"",
+		},
+		{
+			"missing unit",
+			`memory = "512"
+`,
+			"Invalid size",
+			`Value of "memory" must be a number followed by a unit (Ki, Mi, Gi, MB), e.g. "1Ki"`,
+			"",
+			"",
+		},
+		{
+			"missing number",
+			`memory = "Mi"
+`,
+			"Invalid size",
+			`Value of "memory" must be a number followed by a unit (Ki, Mi, Gi, MB), e.g. "1Ki"`,
+			"",
+			"",
+		},
+		{
+			"misspelled unit case",
+			`memory = "512mb"
+`,
+			"Invalid size unit",
+			`"mb" is not a valid unit of "memory", did you mean "MB"?`,
+			`Replace with "512MB"`,
+			`memory = "512MB"
+`,
+		},
+		{
+			"misspelled unit",
+			`memory = "2Gb"
+`,
+			"Invalid size unit",
+			`"Gb" is not a valid unit of "memory", did you mean "Gi"?`,
+			`Replace with "2Gi"`,
+			`memory = "2Gi"
+`,
+		},
+		{
+			"unknown unit",
+			`memory = "2bytes"
+`,
+			"Invalid size unit",
+			`"bytes" is not a valid unit of "memory", expected one of: Ki, Mi, Gi, MB`,
+			"",
+			"",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, sizeSchema, map[string]string{"test.tf": tc.cfg})
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expectedSummary == "" {
+				if len(diags) > 0 {
+					t.Fatalf("expected no diagnostics, given: %#v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, given: %#v", diags)
+			}
+			if diags[0].Summary != tc.expectedSummary {
+				t.Fatalf("unexpected summary: %q", diags[0].Summary)
+			}
+			if diags[0].Detail != tc.expectedDetail {
+				t.Fatalf("unexpected detail: %q", diags[0].Detail)
+			}
+
+			diagFixes := fixes.ForDiagnostic(diags[0])
+			if tc.expectedFix == "" {
+				if len(diagFixes) > 0 {
+					t.Fatalf("expected no fixes, given: %#v", diagFixes)
+				}
+				return
+			}
+			if len(diagFixes) != 1 {
+				t.Fatalf("expected 1 fix, given: %#v", diagFixes)
+			}
+			if diagFixes[0].Title != tc.expectedFix {
+				t.Fatalf("unexpected fix: %q", diagFixes[0].Title)
+			}
+
+			fixedCfg := applyTextEdits(tc.cfg, diagFixes[0].Edits)
+			if fixedCfg != tc.expectedCfg {
+				t.Fatalf("unexpected fixed config: %q", fixedCfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var skipLiteralComplexTypesSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"tags": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralTypeExpr{Type: cty.Map(cty.String), SkipLiteralComplexTypes: true},
+				schema.MapExpr{Name: "tags", Elem: schema.LiteralTypeOnly(cty.String)},
+			},
+		},
+		"ports": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralTypeExpr{Type: cty.List(cty.Number), SkipLiteralComplexTypes: true},
+				schema.LiteralValue{
+					Val:         cty.ListVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(443)}),
+					Description: lang.Markdown("Default ports"),
+				},
+			},
+		},
+		"any": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralTypeExpr{Type: cty.DynamicPseudoType, SkipLiteralComplexTypes: true},
+			},
+		},
+		"mixed": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralTypeExpr{Type: cty.List(cty.String), SkipLiteralComplexTypes: true},
+				schema.LiteralTypeExpr{Type: cty.Number},
+			},
+		},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_skipLiteralComplexTypes(t *testing.T) {
+	testCases := []struct {
+		name           string
+		cfg            string
+		pos            hcl.Pos
+		expectedLabels []string
+	}{
+		{
+			"map",
+			"tags = \n",
+			hcl.Pos{Line: 1, Column: 8, Byte: 7},
+			[]string{"{ key = string }"},
+		},
+		{
+			"list",
+			"ports = \n",
+			hcl.Pos{Line: 1, Column: 9, Byte: 8},
+			[]string{"[ 80, 443 ]"},
+		},
+		{
+			"dynamic",
+			"any = \n",
+			hcl.Pos{Line: 1, Column: 7, Byte: 6},
+			[]string{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, skipLiteralComplexTypesSchema, map[string]string{"test.tf": tc.cfg})
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, len(candidates.List))
+			for i, c := range candidates.List {
+				labels[i] = c.Label
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_HoverAtPos_skipLiteralComplexTypes(t *testing.T) {
+	d := newTestDecoder(t, skipLiteralComplexTypesSchema, map[string]string{"test.tf": "ports = [80, 443]\n"})
+
+	data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 1, Column: 10, Byte: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedData := &lang.HoverData{
+		Content: lang.Markdown("```\n[\n  80,\n  443,\n]\n```\n_list of number_"),
+		Range: hcl.Range{
+			Filename: "test.tf",
+			Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+			End:      hcl.Pos{Line: 1, Column: 18, Byte: 17},
+		},
+	}
+	if diff := cmp.Diff(expectedData, data); diff != "" {
+		t.Fatalf("unexpected hover data: %s", diff)
+	}
+}
+
+func TestDecoder_ValidateFile_skipLiteralComplexTypes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags []expressionDiag
+	}{
+		{
+			"primitive value of dynamic type",
+			"any = \"foo\"\n",
+			[]expressionDiag{},
+		},
+		{
+			"tuple of dynamic type",
+			"any = [1]\n",
+			[]expressionDiag{
+				{
+					summary: "Invalid value type",
+					detail:  `Value of "any" cannot be a literal tuple`,
+					rng:     "1,7-1,10",
+				},
+			},
+		},
+		{
+			"object of dynamic type",
+			"any = { foo = 1 }\n",
+			[]expressionDiag{
+				{
+					summary: "Invalid value type",
+					detail:  `Value of "any" cannot be a literal object`,
+					rng:     "1,7-1,18",
+				},
+			},
+		},
+		{
+			"tuple of skipped list type",
+			"mixed = [\"a\"]\n",
+			[]expressionDiag{
+				{
+					summary: "Invalid value type",
+					detail:  `Value of "mixed" must be number, tuple given`,
+					rng:     "1,9-1,14",
+				},
+			},
+		},
+		{
+			"number",
+			"mixed = 42\n",
+			[]expressionDiag{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, skipLiteralComplexTypesSchema, map[string]string{"test.tf": tc.cfg})
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			given := make([]expressionDiag, len(diags))
+			for i, diag := range diags {
+				given[i] = expressionDiag{
+					summary: diag.Summary,
+					detail:  diag.Detail,
+					rng:     rangeWithoutFilename(diag.Subject),
+				}
+			}
+			if diff := cmp.Diff(tc.expectedDiags, given, cmp.AllowUnexported(expressionDiag{})); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
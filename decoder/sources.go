@@ -0,0 +1,82 @@
+package decoder
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+)
+
+// NewFromSources creates a Decoder ready to serve queries
+// for the given sources (keyed by filename), e.g. for CLI tools
+// and tests which don't need to manage files and references.
+//
+// Sources with the .json extension are parsed as HCL JSON,
+// any other sources as the native syntax. Sources are loaded
+// despite any parse errors, which are returned as diagnostics.
+//
+// Reference targets and origins of all sources are collected
+// once and made available via SetReferenceTargetReader
+// and SetReferenceOriginReader, provided that schema is not nil.
+//
+// Options are applied as settings of the Decoder,
+// i.e. to all queries rather than a single one.
+func NewFromSources(sources map[string][]byte, bodySchema *schema.BodySchema, opts ...RequestOption) (*Decoder, hcl.Diagnostics, error) {
+	d := NewDecoder()
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	filenames := make([]string, 0, len(sources))
+	for filename := range sources {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	diags := hcl.Diagnostics{}
+	for _, filename := range filenames {
+		f, pDiags := parseSource(filename, sources[filename])
+		diags = append(diags, pDiags...)
+
+		err := d.LoadFile(filename, f)
+		if err != nil {
+			return nil, diags, err
+		}
+	}
+
+	if bodySchema == nil {
+		return d, diags, nil
+	}
+	d.SetSchema(bodySchema)
+
+	targets, err := d.CollectReferenceTargets()
+	if err != nil {
+		return nil, diags, err
+	}
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return targets
+	})
+
+	origins, err := d.CollectReferenceOrigins()
+	if err != nil {
+		return nil, diags, err
+	}
+	d.SetReferenceOriginReader(func() lang.ReferenceOrigins {
+		return origins
+	})
+
+	return d, diags, nil
+}
+
+// parseSource parses the source as HCL JSON if the filename
+// has the .json extension, or as the native syntax otherwise
+func parseSource(filename string, src []byte) (*hcl.File, hcl.Diagnostics) {
+	if filepath.Ext(filename) == ".json" {
+		return json.Parse(src, filename)
+	}
+	return hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+}
@@ -0,0 +1,115 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestNewFromSources(t *testing.T) {
+	sources := map[string][]byte{
+		"secrets.tf.json": []byte(`{
+  "secret": {
+    "db": {
+      "value": "foo"
+    }
+  },
+  "name": "baz"
+}`),
+		"test.tf": []byte("token = name\nlog_message = \n"),
+	}
+
+	d, diags, err := NewFromSources(sources, sensitiveSchema,
+		WithFeatures(Features{ScopeFallbackCompletion: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// missing value is expected to be reported, but not to prevent loading
+	if len(diags) != 1 || diags[0].Subject.Filename != "test.tf" {
+		t.Fatalf("unexpected diagnostics: %#v", diags)
+	}
+
+	expectedFilenames := []string{"secrets.tf.json", "test.tf"}
+	if diff := cmp.Diff(expectedFilenames, d.Filenames()); diff != "" {
+		t.Fatalf("unexpected filenames: %s", diff)
+	}
+
+	if !d.features.ScopeFallbackCompletion {
+		t.Fatal("expected options to be applied to the decoder")
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 2, Column: 15, Byte: 27})
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := make([]string, 0)
+	for _, c := range candidates.List {
+		labels = append(labels, c.Label)
+	}
+	// targets declared in JSON are expected to be collected
+	expectedLabels := []string{"name"}
+	if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+
+	var nameTarget lang.ReferenceTarget
+	ReferenceTargets(d.referenceTargetReader()()).DeepWalk(func(target lang.ReferenceTarget) error {
+		if target.Addr.String() == "name" {
+			nameTarget = target
+			return StopWalking
+		}
+		return nil
+	})
+	origins, err := d.ReferenceOriginsTargeting(nameTarget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(origins) != 1 || origins[0].Range.Filename != "test.tf" {
+		t.Fatalf("unexpected origins: %#v", origins)
+	}
+}
+
+func TestNewFromSources_noSchema(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filename string
+		src      string
+	}{
+		{
+			"native syntax",
+			"test.tf",
+			"name = \"foo\"\n",
+		},
+		{
+			"json",
+			"test.tf.json",
+			`{"name": "foo"}`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d, diags, err := NewFromSources(map[string][]byte{
+				tc.filename: []byte(tc.src),
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(diags) > 0 {
+				t.Fatal(diags)
+			}
+
+			symbols, err := d.SymbolsInFile(tc.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(symbols) != 1 || symbols[0].Name() != "name" {
+				t.Fatalf("unexpected symbols: %#v", symbols)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/position"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// validEscapeSequences describes escape sequences of quoted strings
+// as per the HCL native syntax specification
+const validEscapeSequences = `\n, \r, \t, \", \\, \uNNNN and \UNNNNNNNN`
+
+// invalidEscape represents an invalid escape sequence
+// at the given byte offsets of a quoted literal
+type invalidEscape struct {
+	start, end int
+	detail     string
+
+	// isUnknownSelector is true where the backslash is followed
+	// by a character which does not select any escape sequence,
+	// i.e. where the backslash was likely meant literally
+	isUnknownSelector bool
+}
+
+// invalidEscapesInLiteral returns invalid escape sequences
+// within the source of a quoted literal (without the quotes)
+func invalidEscapesInLiteral(lit []byte) []invalidEscape {
+	escapes := make([]invalidEscape, 0)
+
+	for i := 0; i < len(lit); i++ {
+		if lit[i] != '\\' {
+			continue
+		}
+		if i+1 >= len(lit) {
+			escapes = append(escapes, invalidEscape{
+				start:  i,
+				end:    i + 1,
+				detail: "The backslash must be followed by an escape sequence selector, such as n or u",
+			})
+			break
+		}
+
+		switch sel := lit[i+1]; sel {
+		case 'n', 'r', 't', '"', '\\':
+			i++
+		case 'u', 'U':
+			digits, digitsName := 4, "four"
+			if sel == 'U' {
+				digits, digitsName = 8, "eight"
+			}
+			n := 0
+			for n < digits && i+2+n < len(lit) && isHexDigit(lit[i+2+n]) {
+				n++
+			}
+			end := i + 2 + n
+			if n < digits {
+				escapes = append(escapes, invalidEscape{
+					start: i,
+					end:   end,
+					detail: fmt.Sprintf(`The \%c escape sequence must be followed by %s hexadecimal digits, %d given`,
+						sel, digitsName, n),
+				})
+				i = end - 1
+				continue
+			}
+			code, _ := strconv.ParseUint(string(lit[i+2:end]), 16, 32)
+			if !utf8.ValidRune(rune(code)) {
+				escapes = append(escapes, invalidEscape{
+					start:  i,
+					end:    end,
+					detail: fmt.Sprintf("U+%04X is not a valid Unicode character", code),
+				})
+			}
+			i = end - 1
+		default:
+			_, size := utf8.DecodeRune(lit[i+1:])
+			escapes = append(escapes, invalidEscape{
+				start: i,
+				end:   i + 1 + size,
+				detail: fmt.Sprintf("%q is not a valid escape sequence, valid sequences are %s",
+					lit[i:i+1+size], validEscapeSequences),
+				isUnknownSelector: true,
+			})
+			i += size
+		}
+	}
+
+	return escapes
+}
+
+func isHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
+// validateStringEscapes reports invalid escape sequences of quoted
+// strings within the body, along with a fix escaping the backslash
+// where it was likely meant literally (e.g. C:\path)
+func (d *Decoder) validateStringEscapes(body *hclsyntax.Body, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	tokens, src, err := d.lexBody(body)
+	if err != nil {
+		return diags
+	}
+
+	for _, token := range tokens {
+		if token.Type != hclsyntax.TokenQuotedLit {
+			continue
+		}
+
+		for _, esc := range invalidEscapesInLiteral(token.Bytes) {
+			start, err := position.ByteOffsetToPos(src, token.Range.Start.Byte+esc.start)
+			if err != nil {
+				continue
+			}
+			end, err := position.ByteOffsetToPos(src, token.Range.Start.Byte+esc.end)
+			if err != nil {
+				continue
+			}
+			rng := hcl.Range{
+				Filename: token.Range.Filename,
+				Start:    start,
+				End:      end,
+			}
+
+			diag := d.newDiagnostic(CodeInvalidEscapeSequence, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid escape sequence",
+				Detail:   esc.detail,
+				Subject:  rng.Ptr(),
+			})
+			diags = append(diags, diag)
+
+			if fixes != nil && esc.isUnknownSelector {
+				backslashRng := rng
+				backslashRng.End = hcl.Pos{
+					Line:   start.Line,
+					Column: start.Column + 1,
+					Byte:   start.Byte + 1,
+				}
+				*fixes = append(*fixes, lang.DiagnosticFix{
+					Title:      "Escape the backslash",
+					Diagnostic: diag,
+					Edits: []lang.TextEdit{
+						{
+							Range:   backslashRng,
+							NewText: `\\`,
+							Snippet: escapeSnippetText(`\\`),
+						},
+					},
+				})
+			}
+		}
+	}
+
+	return diags
+}
+
+// hoverContentForEscapes returns hover content showing the decoded
+// value of a string literal whose source contains escape sequences,
+// or an empty string if there are none (or any is invalid)
+func (d *Decoder) hoverContentForEscapes(tplExpr *hclsyntax.TemplateExpr, val string) string {
+	raw, err := d.bytesFromRange(tplExpr.Range())
+	if err != nil || !bytes.ContainsRune(raw, '\\') {
+		return ""
+	}
+	if len(invalidEscapesInLiteral(raw)) > 0 {
+		return ""
+	}
+	if bytes.ContainsAny([]byte(val), "\n`") {
+		// multi-line values are already displayed as decoded
+		return ""
+	}
+
+	return fmt.Sprintf("Decoded value: `%s`", val)
+}
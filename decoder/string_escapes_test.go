@@ -0,0 +1,157 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var stringEscapesSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"str": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+	},
+}
+
+func TestDecoder_ValidateFileWithFixes_stringEscapes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+		expectedCfg   string
+	}{
+		{
+			"valid",
+			`str = "a\n\r\t\"\\ \u00e9 \U0001F600 $${b} %%{c}"
+`,
+			hcl.Diagnostics{},
+			"",
+		},
+		{
+			"unknown selector",
+			`str = "C:\path"
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid escape sequence",
+					Detail:   `"\\p" is not a valid escape sequence, valid sequences are \n, \r, \t, \", \\, \uNNNN and \UNNNNNNNN`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						End:      hcl.Pos{Line: 1, Column: 12, Byte: 11},
+					},
+				},
+			},
+			`str = "C:\\path"
+`,
+		},
+		{
+			"not enough digits",
+			`str = "é\u12"
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid escape sequence",
+					Detail:   `The \u escape sequence must be followed by four hexadecimal digits, 2 given`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 9, Byte: 9},
+						End:      hcl.Pos{Line: 1, Column: 13, Byte: 13},
+					},
+				},
+			},
+			"",
+		},
+		{
+			"invalid character",
+			`str = "\uD800"
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid escape sequence",
+					Detail:   `U+D800 is not a valid Unicode character`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+						End:      hcl.Pos{Line: 1, Column: 14, Byte: 13},
+					},
+				},
+			},
+			"",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, stringEscapesSchema, map[string]string{"test.tf": tc.cfg})
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+			if len(diags) == 0 {
+				return
+			}
+
+			diagFixes := fixes.ForDiagnostic(diags[0])
+			if tc.expectedCfg == "" {
+				if len(diagFixes) > 0 {
+					t.Fatalf("expected no fixes, given: %#v", diagFixes)
+				}
+				return
+			}
+			if len(diagFixes) != 1 {
+				t.Fatalf("expected 1 fix, given: %#v", diagFixes)
+			}
+			fixedCfg := applyTextEdits(tc.cfg, diagFixes[0].Edits)
+			if fixedCfg != tc.expectedCfg {
+				t.Fatalf("unexpected config after fix:\n%s", fixedCfg)
+			}
+		})
+	}
+}
+
+func TestDecoder_HoverAtPos_stringEscapes(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		expectedContent lang.MarkupContent
+	}{
+		{
+			"without escapes",
+			`str = "foo"
+`,
+			lang.Markdown("`\"foo\"` _string_"),
+		},
+		{
+			"with escapes",
+			`str = "caf\u00e9\t\"bar\""
+`,
+			lang.Markdown("`\"café\\t\\\"bar\\\"\"` _string_\n\nDecoded value: `café\t\"bar\"`"),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, stringEscapesSchema, map[string]string{"test.tf": tc.cfg})
+
+			data, err := d.HoverAtPos("test.tf", posInCfg(t, tc.cfg, `str = "`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedContent, data.Content); diff != "" {
+				t.Fatalf("unexpected hover content: %s", diff)
+			}
+		})
+	}
+}
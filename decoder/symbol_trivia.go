@@ -0,0 +1,147 @@
+package decoder
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// SymbolTrivia describes which trivia surrounding blocks
+// and attributes is included in ranges of their symbols,
+// e.g. such that moving a symbol up or down in the editor
+// also moves its comments.
+type SymbolTrivia struct {
+	// LeadingComments includes comments placed on their own
+	// line(s) directly above the symbol, not separated by a blank line
+	LeadingComments bool
+
+	// TrailingBlankLines includes any blank lines
+	// directly following the symbol
+	TrailingBlankLines bool
+}
+
+// SetSymbolTrivia sets which trivia is included in ranges
+// of block and attribute symbols. No trivia is included by default.
+//
+// Trivia is only recognized in native syntax files.
+func (d *Decoder) SetSymbolTrivia(trivia SymbolTrivia) {
+	d.symbolTrivia = trivia
+}
+
+func (st SymbolTrivia) isEmpty() bool {
+	return !st.LeadingComments && !st.TrailingBlankLines
+}
+
+type symbolTokens struct {
+	tokens        hclsyntax.Tokens
+	src           []byte
+	byStart       map[int]int
+	byEnd         map[int]int
+	commentStyles CommentStyle
+}
+
+func newSymbolTokens(tokens hclsyntax.Tokens, src []byte, commentStyles CommentStyle) symbolTokens {
+	st := symbolTokens{
+		tokens:        tokens,
+		src:           src,
+		byStart:       make(map[int]int, len(tokens)),
+		byEnd:         make(map[int]int, len(tokens)),
+		commentStyles: commentStyles,
+	}
+	for i, token := range tokens {
+		if token.Type == hclsyntax.TokenEOF {
+			continue
+		}
+		if _, ok := st.byStart[token.Range.Start.Byte]; !ok {
+			st.byStart[token.Range.Start.Byte] = i
+		}
+		st.byEnd[token.Range.End.Byte] = i
+	}
+	return st
+}
+
+// applySymbolTrivia extends ranges of the given block and attribute
+// symbols (and their nested symbols) by trivia as configured
+func (d *Decoder) applySymbolTrivia(symbols []Symbol, st symbolTokens) {
+	for _, symbol := range symbols {
+		switch s := symbol.(type) {
+		case *BlockSymbol:
+			s.rng = d.rangeWithTrivia(s.rng, st)
+			d.applySymbolTrivia(s.nestedSymbols, st)
+		case *AttributeSymbol:
+			s.rng = d.rangeWithTrivia(s.rng, st)
+		}
+	}
+}
+
+func (d *Decoder) rangeWithTrivia(rng hcl.Range, st symbolTokens) hcl.Range {
+	if d.symbolTrivia.LeadingComments {
+		if idx, ok := st.byStart[rng.Start.Byte]; ok {
+			rng.Start = st.leadingCommentsStart(idx, rng.Start)
+		}
+	}
+	if d.symbolTrivia.TrailingBlankLines {
+		if idx, ok := st.byEnd[rng.End.Byte]; ok {
+			rng.End = st.trailingBlankLinesEnd(idx, rng.End)
+		}
+	}
+	return rng
+}
+
+// leadingCommentsStart returns start of the first comment
+// in the uninterrupted sequence of comments (of recognized styles)
+// on their own line(s) preceding the token at the given index
+func (st symbolTokens) leadingCommentsStart(idx int, start hcl.Pos) hcl.Pos {
+	for idx > 0 {
+		commentIdx := idx - 1
+		prev := st.tokens[commentIdx]
+
+		if prev.Type == hclsyntax.TokenNewline && commentIdx > 0 {
+			// block comment (/* */) is followed by a separate newline
+			comment := st.tokens[commentIdx-1]
+			if style, ok := commentStyleOfToken(comment); !ok || style != BlockComment {
+				break
+			}
+			commentIdx--
+			prev = comment
+		}
+
+		if !isRecognizedComment(prev, st.commentStyles) || !st.isOnOwnLine(prev.Range.Start) {
+			break
+		}
+
+		start = prev.Range.Start
+		idx = commentIdx
+	}
+
+	return start
+}
+
+// trailingBlankLinesEnd returns end of the last blank line
+// following the token at the given index
+func (st symbolTokens) trailingBlankLinesEnd(idx int, end hcl.Pos) hcl.Pos {
+	newlines := 0
+	for i := idx + 1; i < len(st.tokens); i++ {
+		token := st.tokens[i]
+		if token.Type != hclsyntax.TokenNewline {
+			break
+		}
+		newlines++
+		if newlines > 1 {
+			// the first newline terminates the line of the symbol itself
+			end = token.Range.Start
+		}
+	}
+	return end
+}
+
+// isOnOwnLine returns true if there is only whitespace
+// between the start of the line and the given position
+func (st symbolTokens) isOnOwnLine(pos hcl.Pos) bool {
+	if pos.Byte > len(st.src) {
+		return false
+	}
+	lineStart := bytes.LastIndexByte(st.src[:pos.Byte], '\n') + 1
+	return len(bytes.TrimSpace(st.src[lineStart:pos.Byte])) == 0
+}
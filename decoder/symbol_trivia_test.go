@@ -0,0 +1,148 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestDecoder_SymbolsInFile_trivia(t *testing.T) {
+	cfg := `# leading comment
+# second line
+resource "a" "b" {
+  /* block comment */
+  count = 1
+
+
+  # detached
+
+  other = 2
+}
+
+
+trailing = 1 # trailing comment
+`
+	d := NewDecoder()
+	d.SetSymbolTrivia(SymbolTrivia{
+		LeadingComments:    true,
+		TrailingBlankLines: true,
+	})
+	f, pDiags := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	symbols, err := d.SymbolsInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ranges := make(map[string]hcl.Range, 0)
+	var collectRanges func(symbols []Symbol)
+	collectRanges = func(symbols []Symbol) {
+		for _, symbol := range symbols {
+			ranges[symbol.Name()] = symbol.Range()
+			collectRanges(symbol.NestedSymbols())
+		}
+	}
+	collectRanges(symbols)
+
+	expectedRanges := map[string]hcl.Range{
+		`resource "a" "b"`: {
+			Filename: "test.tf",
+			Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+			End:      hcl.Pos{Line: 13, Column: 1, Byte: 116},
+		},
+		"count": {
+			Filename: "test.tf",
+			Start:    hcl.Pos{Line: 4, Column: 3, Byte: 53},
+			End:      hcl.Pos{Line: 7, Column: 1, Byte: 86},
+		},
+		"other": {
+			Filename: "test.tf",
+			Start:    hcl.Pos{Line: 10, Column: 3, Byte: 103},
+			End:      hcl.Pos{Line: 10, Column: 12, Byte: 112},
+		},
+		"trailing": {
+			Filename: "test.tf",
+			Start:    hcl.Pos{Line: 14, Column: 1, Byte: 117},
+			End:      hcl.Pos{Line: 14, Column: 13, Byte: 129},
+		},
+	}
+	if diff := cmp.Diff(expectedRanges, ranges); diff != "" {
+		t.Fatalf("unexpected symbol ranges: %s", diff)
+	}
+}
+
+func TestDecoder_SymbolsInFile_triviaCommentStyles(t *testing.T) {
+	testCases := []struct {
+		name          string
+		styles        CommentStyle
+		cfg           string
+		expectedStart hcl.Pos
+	}{
+		{
+			"all styles",
+			AllCommentStyles,
+			`// first
+# second
+/* third */
+attr = 1
+`,
+			hcl.Pos{Line: 1, Column: 1, Byte: 0},
+		},
+		{
+			"hash and block only",
+			HashComment | BlockComment,
+			`// first
+# second
+/* third */
+attr = 1
+`,
+			hcl.Pos{Line: 2, Column: 1, Byte: 9},
+		},
+		{
+			"unrecognized comment directly above",
+			HashComment,
+			`# first
+// second
+attr = 1
+`,
+			hcl.Pos{Line: 3, Column: 1, Byte: 18},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSymbolTrivia(SymbolTrivia{LeadingComments: true})
+			d.SetCommentStyles(tc.styles)
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			symbols, err := d.SymbolsInFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(symbols) != 1 {
+				t.Fatalf("expected 1 symbol, given: %#v", symbols)
+			}
+			if diff := cmp.Diff(tc.expectedStart, symbols[0].Range().Start); diff != "" {
+				t.Fatalf("unexpected start of symbol range: %s", diff)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package decoder
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -14,19 +16,36 @@ import (
 // SymbolsInFile returns a hierarchy of symbols within the config file
 //
 // A symbol is typically represented by a block or an attribute.
-func (d *Decoder) SymbolsInFile(filename string) ([]Symbol, error) {
+func (d *Decoder) SymbolsInFile(filename string, opts ...RequestOption) ([]Symbol, error) {
+	d = d.withRequestOptions(opts)
+
 	symbols := make([]Symbol, 0)
 
-	f, err := d.fileByName(filename)
+	segments, err := d.segmentsForFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	body, err := d.bodyForFileAndPos(filename, f, hcl.InitialPos)
-	if err != nil {
-		return nil, err
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	for _, segment := range segments {
+		segmentSymbols := d.symbolsForGenericBody(segment.Body, d.rootSchema, 0)
+
+		if _, isHcl := segment.Body.(*hclsyntax.Body); isHcl && !d.symbolTrivia.isEmpty() {
+			src, err := d.bytesForFile(filename)
+			if err != nil {
+				return nil, err
+			}
+			tokens, err := d.tokensForFileAndPos(filename, segment.Offset)
+			if err == nil {
+				d.applySymbolTrivia(segmentSymbols, newSymbolTokens(tokens, src, d.commentStyles))
+			}
+			// otherwise symbols are returned without trivia
+		}
+
+		symbols = append(symbols, segmentSymbols...)
 	}
-	symbols = append(symbols, symbolsForBody(body)...)
 
 	return symbols, nil
 }
@@ -37,7 +56,9 @@ func (d *Decoder) SymbolsInFile(filename string) ([]Symbol, error) {
 // in which case all symbols are returned.
 //
 // A symbol is typically represented by a block or an attribute.
-func (d *Decoder) Symbols(query string) ([]Symbol, error) {
+func (d *Decoder) Symbols(query string, opts ...RequestOption) ([]Symbol, error) {
+	d = d.withRequestOptions(opts)
+
 	symbols := make([]Symbol, 0)
 
 	files := d.Filenames()
@@ -57,9 +78,52 @@ func (d *Decoder) Symbols(query string) ([]Symbol, error) {
 	return symbols, nil
 }
 
-func symbolsForBody(body *hclsyntax.Body) []Symbol {
+// SymbolsForPath calls fn with symbols of each loaded file
+// within the given path (a file or directory), one file at a time
+// in order of filenames. An empty path represents all loaded files.
+//
+// Unlike Symbols, symbols of all files are never held in memory
+// at once, which makes it suitable for indexing whole workspaces.
+// Cancellation of the context is checked between files.
+func (d *Decoder) SymbolsForPath(ctx context.Context, path string, fn func(filename string, symbols []Symbol)) error {
+	for _, filename := range d.Filenames() {
+		if !isFileWithinPath(filename, path) {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		symbols, err := d.SymbolsInFile(filename)
+		if err != nil {
+			return err
+		}
+		fn(filename, symbols)
+	}
+
+	return nil
+}
+
+// isFileWithinPath returns true if the file is the path itself
+// or is located anywhere within the directory of the path
+func isFileWithinPath(filename, path string) bool {
+	if path == "" {
+		return true
+	}
+
+	filename, path = filepath.Clean(filename), filepath.Clean(path)
+	if path == "." && !filepath.IsAbs(filename) {
+		return !strings.HasPrefix(filename, ".."+string(filepath.Separator))
+	}
+
+	return filename == path ||
+		strings.HasPrefix(filename, strings.TrimSuffix(path, string(filepath.Separator))+string(filepath.Separator))
+}
+
+func (d *Decoder) symbolsForBody(body *hclsyntax.Body, nestingLvl int) []Symbol {
 	symbols := make([]Symbol, 0)
-	if body == nil {
+	if body == nil || d.isNestedTooDeep(nestingLvl) {
 		return symbols
 	}
 
@@ -68,7 +132,7 @@ func symbolsForBody(body *hclsyntax.Body) []Symbol {
 			AttrName:      name,
 			ExprKind:      symbolExprKind(attr.Expr),
 			rng:           attr.Range(),
-			nestedSymbols: nestedSymbolsForExpr(attr.Expr),
+			nestedSymbols: d.nestedSymbolsForExpr(attr.Expr, 0),
 		})
 	}
 	for _, block := range body.Blocks {
@@ -76,7 +140,7 @@ func symbolsForBody(body *hclsyntax.Body) []Symbol {
 			Type:          block.Type,
 			Labels:        block.Labels,
 			rng:           block.Range(),
-			nestedSymbols: symbolsForBody(block.Body),
+			nestedSymbols: d.symbolsForBody(block.Body, nestingLvl+1),
 		})
 	}
 
@@ -108,8 +172,11 @@ func symbolExprKind(expr hcl.Expression) lang.SymbolExprKind {
 	return nil
 }
 
-func nestedSymbolsForExpr(expr hcl.Expression) []Symbol {
+func (d *Decoder) nestedSymbolsForExpr(expr hcl.Expression, nestingLvl int) []Symbol {
 	symbols := make([]Symbol, 0)
+	if d.isNestedTooDeep(nestingLvl) {
+		return symbols
+	}
 
 	switch e := expr.(type) {
 	case *hclsyntax.TupleConsExpr:
@@ -118,7 +185,7 @@ func nestedSymbolsForExpr(expr hcl.Expression) []Symbol {
 				ExprName:      fmt.Sprintf("%d", i),
 				ExprKind:      symbolExprKind(item),
 				rng:           item.Range(),
-				nestedSymbols: nestedSymbolsForExpr(item),
+				nestedSymbols: d.nestedSymbolsForExpr(item, nestingLvl+1),
 			})
 		}
 	case *hclsyntax.ObjectConsExpr:
@@ -133,7 +200,7 @@ func nestedSymbolsForExpr(expr hcl.Expression) []Symbol {
 				ExprName:      key.AsString(),
 				ExprKind:      symbolExprKind(item.ValueExpr),
 				rng:           hcl.RangeBetween(item.KeyExpr.Range(), item.ValueExpr.Range()),
-				nestedSymbols: nestedSymbolsForExpr(item.ValueExpr),
+				nestedSymbols: d.nestedSymbolsForExpr(item.ValueExpr, nestingLvl+1),
 			})
 		}
 	}
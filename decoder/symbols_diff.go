@@ -0,0 +1,210 @@
+package decoder
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SymbolEditKind represents the kind of a SymbolEdit
+type SymbolEditKind uint
+
+const (
+	NilSymbolEditKind SymbolEditKind = iota
+
+	// InsertSymbolEditKind represents a new symbol (including
+	// its nested symbols) to be inserted at NewPath
+	InsertSymbolEditKind
+
+	// DeleteSymbolEditKind represents a symbol (including
+	// its nested symbols) to be deleted from OldPath
+	DeleteSymbolEditKind
+
+	// UpdateSymbolEditKind represents a symbol whose range or
+	// expression kind changed, but which otherwise remains in place.
+	// Changes of its nested symbols are represented by separate edits.
+	UpdateSymbolEditKind
+)
+
+// SymbolEdit represents a structural change of an outline
+// (a tree of symbols, as returned from SymbolsInFile)
+type SymbolEdit struct {
+	Kind SymbolEditKind
+
+	// OldPath represents indexes of the symbol (and its parents)
+	// in the old outline. It is nil for inserted symbols.
+	OldPath []int
+	// NewPath represents indexes of the symbol (and its parents)
+	// in the new outline. It is nil for deleted symbols.
+	NewPath []int
+
+	OldSymbol Symbol
+	NewSymbol Symbol
+}
+
+// SymbolsDiff compares two outlines of the same document and returns
+// edits which turn the old outline into the new one, such that
+// the outline can be updated incrementally.
+//
+// Symbols are matched by their type and name among their siblings.
+// Deletions come first, in reverse document order, followed by insertions
+// and updates in document order, i.e. each edit can be applied
+// to the result of applying all the preceding edits.
+//
+// A symbol which moved among its siblings is represented
+// as a deletion followed by an insertion.
+func SymbolsDiff(old, new []Symbol) []SymbolEdit {
+	deletions := make([]SymbolEdit, 0)
+	edits := make([]SymbolEdit, 0)
+
+	diffSymbols(old, new, []int{}, []int{}, &deletions, &edits)
+
+	allEdits := make([]SymbolEdit, 0, len(deletions)+len(edits))
+	for i := len(deletions) - 1; i >= 0; i-- {
+		allEdits = append(allEdits, deletions[i])
+	}
+
+	return append(allEdits, edits...)
+}
+
+func diffSymbols(old, new []Symbol, oldPath, newPath []int, deletions, edits *[]SymbolEdit) {
+	oldKeys := symbolKeys(old)
+	newKeys := symbolKeys(new)
+
+	for _, pair := range matchSymbolKeys(oldKeys, newKeys) {
+		oldIdx, newIdx := pair[0], pair[1]
+
+		switch {
+		case newIdx < 0:
+			*deletions = append(*deletions, SymbolEdit{
+				Kind:      DeleteSymbolEditKind,
+				OldPath:   appendPath(oldPath, oldIdx),
+				OldSymbol: old[oldIdx],
+			})
+		case oldIdx < 0:
+			*edits = append(*edits, SymbolEdit{
+				Kind:      InsertSymbolEditKind,
+				NewPath:   appendPath(newPath, newIdx),
+				NewSymbol: new[newIdx],
+			})
+		default:
+			oldSymbolPath := appendPath(oldPath, oldIdx)
+			newSymbolPath := appendPath(newPath, newIdx)
+
+			if !isSymbolShallowEqual(old[oldIdx], new[newIdx]) {
+				*edits = append(*edits, SymbolEdit{
+					Kind:      UpdateSymbolEditKind,
+					OldPath:   oldSymbolPath,
+					NewPath:   newSymbolPath,
+					OldSymbol: old[oldIdx],
+					NewSymbol: new[newIdx],
+				})
+			}
+
+			diffSymbols(old[oldIdx].NestedSymbols(), new[newIdx].NestedSymbols(),
+				oldSymbolPath, newSymbolPath, deletions, edits)
+		}
+	}
+}
+
+// symbolKeys returns keys identifying symbols among their siblings,
+// where symbols of the same name are told apart by their order
+func symbolKeys(symbols []Symbol) []string {
+	keys := make([]string, len(symbols))
+	seen := make(map[string]int, 0)
+	for i, symbol := range symbols {
+		key := fmt.Sprintf("%T:%s", symbol, symbol.Name())
+		keys[i] = fmt.Sprintf("%s#%d", key, seen[key])
+		seen[key]++
+	}
+	return keys
+}
+
+// matchSymbolKeys returns pairs of indexes of old and new keys
+// in document order, based on the longest common subsequence.
+// Unmatched keys are paired with -1.
+func matchSymbolKeys(old, new []string) [][2]int {
+	pairs := make([][2]int, 0)
+
+	// trim the common prefix and suffix first, which is cheap and
+	// typically leaves just a few keys around the edited part
+	prefixLen := 0
+	for prefixLen < len(old) && prefixLen < len(new) && old[prefixLen] == new[prefixLen] {
+		prefixLen++
+	}
+	suffixLen := 0
+	for suffixLen < len(old)-prefixLen && suffixLen < len(new)-prefixLen &&
+		old[len(old)-1-suffixLen] == new[len(new)-1-suffixLen] {
+		suffixLen++
+	}
+
+	for i := 0; i < prefixLen; i++ {
+		pairs = append(pairs, [2]int{i, i})
+	}
+
+	oldMid := old[prefixLen : len(old)-suffixLen]
+	newMid := new[prefixLen : len(new)-suffixLen]
+
+	// lengths of common subsequences of suffixes
+	lcs := make([][]int, len(oldMid)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newMid)+1)
+	}
+	for i := len(oldMid) - 1; i >= 0; i-- {
+		for j := len(newMid) - 1; j >= 0; j-- {
+			if oldMid[i] == newMid[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(oldMid) || j < len(newMid) {
+		switch {
+		case i < len(oldMid) && j < len(newMid) && oldMid[i] == newMid[j]:
+			pairs = append(pairs, [2]int{prefixLen + i, prefixLen + j})
+			i++
+			j++
+		case j == len(newMid) || (i < len(oldMid) && lcs[i+1][j] >= lcs[i][j+1]):
+			pairs = append(pairs, [2]int{prefixLen + i, -1})
+			i++
+		default:
+			pairs = append(pairs, [2]int{-1, prefixLen + j})
+			j++
+		}
+	}
+
+	for k := 0; k < suffixLen; k++ {
+		pairs = append(pairs, [2]int{len(old) - suffixLen + k, len(new) - suffixLen + k})
+	}
+
+	return pairs
+}
+
+// isSymbolShallowEqual returns true if both symbols are equal,
+// regardless of their nested symbols
+func isSymbolShallowEqual(a, b Symbol) bool {
+	if a.Range() != b.Range() {
+		return false
+	}
+
+	switch as := a.(type) {
+	case *AttributeSymbol:
+		bs, ok := b.(*AttributeSymbol)
+		return ok && reflect.DeepEqual(as.ExprKind, bs.ExprKind)
+	case *ExprSymbol:
+		bs, ok := b.(*ExprSymbol)
+		return ok && reflect.DeepEqual(as.ExprKind, bs.ExprKind)
+	}
+
+	return true
+}
+
+func appendPath(path []int, idx int) []int {
+	newPath := make([]int, len(path), len(path)+1)
+	copy(newPath, path)
+	return append(newPath, idx)
+}
@@ -0,0 +1,209 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestSymbolsDiff(t *testing.T) {
+	testCases := []struct {
+		name          string
+		oldCfg        string
+		newCfg        string
+		expectedEdits []string
+	}{
+		{
+			"no change",
+			`attr = 1
+block "a" {
+  nested = 2
+}
+`,
+			`attr = 1
+block "a" {
+  nested = 2
+}
+`,
+			[]string{},
+		},
+		{
+			"attribute inserted",
+			`block "a" {
+  one = 1
+}
+`,
+			`block "a" {
+  one = 1
+  two = 2
+}
+`,
+			[]string{
+				`update [0]->[0] block "a"`,
+				`insert [0 1] two`,
+			},
+		},
+		{
+			"block deleted",
+			`block "a" {}
+block "b" {}
+block "c" {}
+`,
+			`block "a" {}
+block "c" {}
+`,
+			[]string{
+				`delete [1] block "b"`,
+				`update [2]->[1] block "c"`,
+			},
+		},
+		{
+			"nested attribute changed",
+			`block "a" {
+  one = 1
+}
+`,
+			`block "a" {
+  one = "1"
+}
+`,
+			[]string{
+				`update [0]->[0] block "a"`,
+				`update [0 0]->[0 0] one`,
+			},
+		},
+		{
+			"block moved",
+			`block "a" {}
+block "b" {}
+`,
+			`block "b" {}
+block "a" {}
+`,
+			[]string{
+				`delete [0] block "a"`,
+				`update [1]->[0] block "b"`,
+				`insert [1] block "a"`,
+			},
+		},
+		{
+			"duplicate names",
+			`block "a" {}
+block "a" {}
+`,
+			`block "a" {}
+`,
+			[]string{
+				`delete [1] block "a"`,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			oldSymbols := symbolsForConfig(t, tc.oldCfg)
+			newSymbols := symbolsForConfig(t, tc.newCfg)
+
+			edits := SymbolsDiff(oldSymbols, newSymbols)
+
+			summaries := make([]string, 0)
+			for _, edit := range edits {
+				summaries = append(summaries, summarizeSymbolEdit(edit))
+			}
+			if diff := cmp.Diff(tc.expectedEdits, summaries); diff != "" {
+				t.Fatalf("unexpected edits: %s", diff)
+			}
+
+			applied := applySymbolEdits(t, symbolNodesFor(oldSymbols), edits)
+			if diff := cmp.Diff(symbolNodesFor(newSymbols), applied); diff != "" {
+				t.Fatalf("edits don't produce the new outline: %s", diff)
+			}
+		})
+	}
+}
+
+func symbolsForConfig(t *testing.T, cfg string) []Symbol {
+	d := NewDecoder()
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols, err := d.SymbolsInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return symbols
+}
+
+func summarizeSymbolEdit(edit SymbolEdit) string {
+	switch edit.Kind {
+	case InsertSymbolEditKind:
+		return fmt.Sprintf("insert %v %s", edit.NewPath, edit.NewSymbol.Name())
+	case DeleteSymbolEditKind:
+		return fmt.Sprintf("delete %v %s", edit.OldPath, edit.OldSymbol.Name())
+	case UpdateSymbolEditKind:
+		return fmt.Sprintf("update %v->%v %s", edit.OldPath, edit.NewPath, edit.NewSymbol.Name())
+	}
+	return "unknown"
+}
+
+// symbolNode is a simplified outline used to verify
+// that edits can be applied sequentially
+type symbolNode struct {
+	Name   string
+	Range  hcl.Range
+	Nested []*symbolNode
+}
+
+func symbolNodesFor(symbols []Symbol) []*symbolNode {
+	nodes := make([]*symbolNode, len(symbols))
+	for i, symbol := range symbols {
+		nodes[i] = &symbolNode{
+			Name:   symbol.Name(),
+			Range:  symbol.Range(),
+			Nested: symbolNodesFor(symbol.NestedSymbols()),
+		}
+	}
+	return nodes
+}
+
+func applySymbolEdits(t *testing.T, nodes []*symbolNode, edits []SymbolEdit) []*symbolNode {
+	root := &symbolNode{Nested: nodes}
+
+	parentOf := func(path []int) *symbolNode {
+		parent := root
+		for _, idx := range path[:len(path)-1] {
+			parent = parent.Nested[idx]
+		}
+		return parent
+	}
+
+	for _, edit := range edits {
+		switch edit.Kind {
+		case DeleteSymbolEditKind:
+			parent := parentOf(edit.OldPath)
+			idx := edit.OldPath[len(edit.OldPath)-1]
+			parent.Nested = append(parent.Nested[:idx], parent.Nested[idx+1:]...)
+		case InsertSymbolEditKind:
+			parent := parentOf(edit.NewPath)
+			idx := edit.NewPath[len(edit.NewPath)-1]
+			node := symbolNodesFor([]Symbol{edit.NewSymbol})[0]
+			parent.Nested = append(parent.Nested, nil)
+			copy(parent.Nested[idx+1:], parent.Nested[idx:])
+			parent.Nested[idx] = node
+		case UpdateSymbolEditKind:
+			parent := parentOf(edit.NewPath)
+			node := parent.Nested[edit.NewPath[len(edit.NewPath)-1]]
+			if node.Name != edit.NewSymbol.Name() {
+				t.Fatalf("update of %q applied to %q", edit.NewSymbol.Name(), node.Name)
+			}
+			node.Range = edit.NewSymbol.Range()
+		}
+	}
+
+	return root.Nested
+}
@@ -1,7 +1,10 @@
 package decoder
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -21,10 +24,12 @@ func TestDecoder_SymbolsInFile_emptyBody(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, err = d.SymbolsInFile("test.tf")
-	unknownFormatErr := &UnknownFileFormatError{}
-	if !errors.As(err, &unknownFormatErr) {
-		t.Fatal("expected UnknownFileFormatError for empty body")
+	symbols, err := d.SymbolsInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(symbols) > 0 {
+		t.Fatalf("expected no symbols for empty body, given: %#v", symbols)
 	}
 }
 
@@ -695,3 +700,113 @@ provider "google" {
 		t.Fatalf("unexpected symbols: %s", diff)
 	}
 }
+
+func TestDecoder_SymbolsForPath(t *testing.T) {
+	d := NewDecoder()
+
+	filenames := []string{
+		"main.tf",
+		filepath.Join("modules", "a", "main.tf"),
+		filepath.Join("modules", "a", "variables.tf"),
+		filepath.Join("modules", "ab", "main.tf"),
+	}
+	for _, filename := range filenames {
+		f, _ := hclsyntax.ParseConfig([]byte(`attr = "value"`), filename, hcl.InitialPos)
+		err := d.LoadFile(filename, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name              string
+		path              string
+		expectedFilenames []string
+	}{
+		{
+			"all files",
+			"",
+			[]string{
+				"main.tf",
+				filepath.Join("modules", "a", "main.tf"),
+				filepath.Join("modules", "a", "variables.tf"),
+				filepath.Join("modules", "ab", "main.tf"),
+			},
+		},
+		{
+			"directory",
+			filepath.Join("modules", "a"),
+			[]string{
+				filepath.Join("modules", "a", "main.tf"),
+				filepath.Join("modules", "a", "variables.tf"),
+			},
+		},
+		{
+			"directory with trailing separator",
+			filepath.Join("modules", "a") + string(filepath.Separator),
+			[]string{
+				filepath.Join("modules", "a", "main.tf"),
+				filepath.Join("modules", "a", "variables.tf"),
+			},
+		},
+		{
+			"single file",
+			filepath.Join("modules", "ab", "main.tf"),
+			[]string{
+				filepath.Join("modules", "ab", "main.tf"),
+			},
+		},
+		{
+			"unknown path",
+			"foo",
+			[]string{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			visited := make([]string, 0)
+			err := d.SymbolsForPath(context.Background(), tc.path, func(filename string, symbols []Symbol) {
+				if len(symbols) != 1 {
+					t.Fatalf("expected 1 symbol for %q, %d given", filename, len(symbols))
+				}
+				visited = append(visited, filename)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedFilenames, visited); diff != "" {
+				t.Fatalf("unexpected files: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_SymbolsForPath_cancelled(t *testing.T) {
+	d := NewDecoder()
+
+	for _, filename := range []string{"first.tf", "second.tf"} {
+		f, _ := hclsyntax.ParseConfig([]byte(`attr = "value"`), filename, hcl.InitialPos)
+		err := d.LoadFile(filename, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	visited := make([]string, 0)
+	err := d.SymbolsForPath(ctx, "", func(filename string, symbols []Symbol) {
+		visited = append(visited, filename)
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, %v given", err)
+	}
+
+	expectedFilenames := []string{"first.tf"}
+	if diff := cmp.Diff(expectedFilenames, visited); diff != "" {
+		t.Fatalf("unexpected files: %s", diff)
+	}
+}
@@ -0,0 +1,398 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// controlSequence represents a template directive, i.e. the control
+// sequence (%{ ... }) of a quoted or heredoc template
+type controlSequence struct {
+	// template identifies the template the sequence belongs to
+	template int
+
+	// controlRng represents range of the opening %{ or %{~
+	controlRng hcl.Range
+	// stripsLeft is true if the opening contains the strip marker (~)
+	stripsLeft bool
+	// firstToken represents the first token following the opening,
+	// typically the keyword, or nil if there is none
+	firstToken *hclsyntax.Token
+	// rng represents range of the whole sequence, which only
+	// spans the opening if the sequence is not terminated
+	rng    hcl.Range
+	closed bool
+}
+
+// keyword returns the keyword of the directive (e.g. if or endfor),
+// or an empty string if there is none
+func (cs controlSequence) keyword() string {
+	if cs.firstToken == nil || cs.firstToken.Type != hclsyntax.TokenIdent {
+		return ""
+	}
+	return string(cs.firstToken.Bytes)
+}
+
+// closingKeywords maps keywords of directives
+// to the keywords of the directives closing them
+var closingKeywords = map[string]string{
+	"if":  "endif",
+	"for": "endfor",
+}
+
+// openingKeywords maps keywords of directives closing
+// other directives to the keywords of those directives
+var openingKeywords = map[string]string{
+	"endif":  "if",
+	"endfor": "for",
+}
+
+// templateDirectiveSnippets maps keywords of directives
+// to snippets of the directives (without the opening)
+var templateDirectiveSnippets = map[string]string{
+	"if":     "if ${1:condition}",
+	"else":   "else",
+	"endif":  "endif",
+	"for":    "for ${1:item} in ${2:collection}",
+	"endfor": "endfor",
+}
+
+var templateDirectiveDescriptions = map[string]string{
+	"if":     "Includes the template up to `%{ else }` or `%{ endif }` if the condition is true",
+	"else":   "Includes the template up to `%{ endif }` if the condition is false",
+	"endif":  "Ends the `%{ if }` directive",
+	"for":    "Includes the template up to `%{ endfor }` once for each element of the collection",
+	"endfor": "Ends the `%{ for }` directive",
+}
+
+// controlSequencesInBody returns control sequences of all templates
+// within the body, which is lexed (rather than parsed), such that
+// sequences of incomplete templates are also returned
+func (d *Decoder) controlSequencesInBody(body *hclsyntax.Body) ([]controlSequence, []byte, error) {
+	tokens, src, err := d.lexBody(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return controlSequencesInTokens(tokens), src, nil
+}
+
+// lexBody returns tokens of the body, along with the source
+// of the whole file, such that tokens can be inspected even where
+// the body could not be fully parsed
+func (d *Decoder) lexBody(body *hclsyntax.Body) (hclsyntax.Tokens, []byte, error) {
+	src, err := d.bytesForFile(body.SrcRange.Filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	rng := body.SrcRange
+	if rng.End.Byte > len(src) || rng.Start.Byte > rng.End.Byte {
+		return hclsyntax.Tokens{}, src, nil
+	}
+
+	tokens, _ := hclsyntax.LexConfig(rng.SliceBytes(src), rng.Filename, rng.Start)
+	return tokens, src, nil
+}
+
+// controlSequencesInTokens returns control sequences of all templates
+// (including templates nested in interpolations) in the given tokens
+func controlSequencesInTokens(tokens hclsyntax.Tokens) []controlSequence {
+	sequences := make([]controlSequence, 0)
+
+	// stack of templates (>= 0) and of sequences (< 0) which are open,
+	// where interpolation sequences are represented as -1 and control
+	// sequences as -(2+index)
+	stack := make([]int, 0)
+	nextTemplate := 0
+	lastControlIdx := -1
+
+	for i, token := range tokens {
+		if lastControlIdx >= 0 && i == lastControlIdx+1 {
+			t := token
+			sequences[len(sequences)-1].firstToken = &t
+		}
+
+		switch token.Type {
+		case hclsyntax.TokenOQuote, hclsyntax.TokenOHeredoc:
+			stack = append(stack, nextTemplate)
+			nextTemplate++
+		case hclsyntax.TokenCQuote, hclsyntax.TokenCHeredoc:
+			// any unterminated sequences end along with the template
+			for len(stack) > 0 {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if top >= 0 {
+					break
+				}
+			}
+		case hclsyntax.TokenTemplateInterp:
+			stack = append(stack, -1)
+		case hclsyntax.TokenTemplateControl:
+			template := -1
+			if len(stack) > 0 {
+				template = stack[len(stack)-1]
+			}
+			if template < 0 {
+				// not a part of any template
+				continue
+			}
+			sequences = append(sequences, controlSequence{
+				template:   template,
+				controlRng: token.Range,
+				stripsLeft: bytes.HasSuffix(token.Bytes, []byte("~")),
+				rng:        token.Range,
+			})
+			stack = append(stack, -(2 + len(sequences) - 1))
+			lastControlIdx = i
+		case hclsyntax.TokenTemplateSeqEnd:
+			if len(stack) == 0 || stack[len(stack)-1] >= 0 {
+				continue
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if top <= -2 {
+				idx := -top - 2
+				sequences[idx].rng = hcl.RangeBetween(sequences[idx].controlRng, token.Range)
+				sequences[idx].closed = true
+			}
+		}
+	}
+
+	return sequences
+}
+
+// openDirectivesBefore returns the directives (if and for) opened
+// before the sequence of the given index within the same template,
+// which are not closed yet, along with whether the innermost if
+// directive is already in its else clause
+func openDirectivesBefore(sequences []controlSequence, idx int) ([]controlSequence, bool) {
+	open := make([]controlSequence, 0)
+	inElse := make([]bool, 0)
+
+	for _, cs := range sequences[:idx] {
+		if cs.template != sequences[idx].template || !cs.closed {
+			continue
+		}
+		keyword := cs.keyword()
+		if _, ok := closingKeywords[keyword]; ok {
+			open = append(open, cs)
+			inElse = append(inElse, false)
+			continue
+		}
+		if keyword == "else" && len(open) > 0 {
+			inElse[len(inElse)-1] = true
+			continue
+		}
+		if opening, ok := openingKeywords[keyword]; ok && len(open) > 0 &&
+			open[len(open)-1].keyword() == opening {
+			open = open[:len(open)-1]
+			inElse = inElse[:len(inElse)-1]
+		}
+	}
+
+	if len(open) == 0 {
+		return open, false
+	}
+	return open, inElse[len(inElse)-1]
+}
+
+// templateDirectiveCandidatesAtPos returns candidates for the keyword
+// of a template directive, if the position is within the opening
+// of a control sequence (e.g. "%{ i") of any template in the body
+func (d *Decoder) templateDirectiveCandidatesAtPos(body *hclsyntax.Body, pos hcl.Pos) (lang.Candidates, bool) {
+	sequences, src, err := d.controlSequencesInBody(body)
+	if err != nil {
+		return lang.ZeroCandidates(), false
+	}
+
+	for i, cs := range sequences {
+		if pos.Byte < cs.controlRng.End.Byte {
+			break
+		}
+
+		prefix := ""
+		editRng := hcl.Range{
+			Filename: body.SrcRange.Filename,
+			Start:    pos,
+			End:      pos,
+		}
+		needsClosing := !cs.closed
+		if cs.firstToken != nil && cs.firstToken.Range.Start.Byte < pos.Byte {
+			if cs.firstToken.Type != hclsyntax.TokenIdent || cs.firstToken.Range.End.Byte < pos.Byte {
+				// e.g. condition of the if directive
+				continue
+			}
+			prefix = string(src[cs.firstToken.Range.Start.Byte:pos.Byte])
+			editRng = cs.firstToken.Range
+		} else if cs.firstToken == nil || cs.firstToken.Range.Start.Line != pos.Line {
+			// the rest of the line is not a part of the sequence
+			needsClosing = true
+		}
+		if cs.closed && pos.Byte >= cs.rng.End.Byte {
+			continue
+		}
+
+		return d.templateDirectiveCandidates(sequences, i, prefix, editRng, needsClosing), true
+	}
+
+	return lang.ZeroCandidates(), false
+}
+
+func (d *Decoder) templateDirectiveCandidates(sequences []controlSequence, idx int, prefix string, editRng hcl.Range, needsClosing bool) lang.Candidates {
+	candidates := lang.NewCandidates()
+	candidates.IsComplete = true
+
+	keywords := []string{"if", "for"}
+	open, inElse := openDirectivesBefore(sequences, idx)
+	if len(open) > 0 {
+		innermost := open[len(open)-1].keyword()
+		if innermost == "if" && !inElse {
+			keywords = append(keywords, "else")
+		}
+		keywords = append(keywords, closingKeywords[innermost])
+	}
+
+	for _, keyword := range keywords {
+		if !strings.HasPrefix(keyword, prefix) {
+			continue
+		}
+		newText, snippet := keyword, templateDirectiveSnippets[keyword]
+		if needsClosing {
+			newText += " }"
+			snippet += " }"
+		}
+		candidates.List = append(candidates.List, lang.Candidate{
+			Label:       keyword,
+			Detail:      "directive",
+			Description: lang.Markdown(templateDirectiveDescriptions[keyword]),
+			Kind:        lang.KeywordCandidateKind,
+			TextEdit: lang.TextEdit{
+				Range:   editRng,
+				NewText: newText,
+				Snippet: snippet,
+			},
+		})
+	}
+
+	cs := sequences[idx]
+	if prefix == "" && !cs.stripsLeft && editRng.Start.Byte == cs.controlRng.End.Byte {
+		candidates.List = append(candidates.List, lang.Candidate{
+			Label:       "~",
+			Detail:      "strip marker",
+			Description: lang.Markdown("Strips any whitespace preceding the directive"),
+			Kind:        lang.KeywordCandidateKind,
+			TextEdit: lang.TextEdit{
+				Range:   editRng,
+				NewText: "~",
+				Snippet: "~",
+			},
+		})
+	}
+
+	candidates.Sort()
+	return candidates
+}
+
+// validateTemplateDirectives reports directives of templates within
+// the body which are not closed, or which close (or continue)
+// a directive which is not open, using ranges of the directives
+func (d *Decoder) validateTemplateDirectives(body *hclsyntax.Body, related relatedInfos) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	sequences, _, err := d.controlSequencesInBody(body)
+	if err != nil {
+		return diags
+	}
+
+	open := make(map[int][]controlSequence, 0)
+	inElse := make(map[int][]bool, 0)
+	templates := make([]int, 0)
+
+	for _, cs := range sequences {
+		if !cs.closed {
+			// incomplete sequences are reported by the parser
+			continue
+		}
+		if _, ok := open[cs.template]; !ok {
+			templates = append(templates, cs.template)
+		}
+		tplOpen, tplInElse := open[cs.template], inElse[cs.template]
+
+		keyword := cs.keyword()
+		if _, ok := closingKeywords[keyword]; ok {
+			open[cs.template] = append(tplOpen, cs)
+			inElse[cs.template] = append(tplInElse, false)
+			continue
+		}
+
+		if keyword == "else" {
+			switch {
+			case len(tplOpen) == 0 || tplOpen[len(tplOpen)-1].keyword() != "if":
+				diags = append(diags, d.unexpectedDirectiveDiagnostic(cs,
+					"The else directive is only expected within an if directive", nil, related))
+			case tplInElse[len(tplInElse)-1]:
+				diags = append(diags, d.unexpectedDirectiveDiagnostic(cs,
+					"The if directive is already in its else clause", &tplOpen[len(tplOpen)-1], related))
+			default:
+				tplInElse[len(tplInElse)-1] = true
+			}
+			continue
+		}
+
+		opening, ok := openingKeywords[keyword]
+		if !ok {
+			// invalid keywords are reported by the parser
+			continue
+		}
+		if len(tplOpen) == 0 {
+			diags = append(diags, d.unexpectedDirectiveDiagnostic(cs,
+				fmt.Sprintf("The %s directive has no corresponding %s directive", keyword, opening),
+				nil, related))
+			continue
+		}
+		innermost := tplOpen[len(tplOpen)-1]
+		if innermost.keyword() != opening {
+			diags = append(diags, d.unexpectedDirectiveDiagnostic(cs,
+				fmt.Sprintf("Expecting an %s directive corresponding to the %s directive",
+					closingKeywords[innermost.keyword()], innermost.keyword()),
+				&innermost, related))
+			continue
+		}
+		open[cs.template] = tplOpen[:len(tplOpen)-1]
+		inElse[cs.template] = tplInElse[:len(tplInElse)-1]
+	}
+
+	for _, template := range templates {
+		for _, cs := range open[template] {
+			diags = append(diags, d.newDiagnostic(CodeUnclosedDirective, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unclosed template directive",
+				Detail: fmt.Sprintf("The %s directive is missing its corresponding %s directive",
+					cs.keyword(), closingKeywords[cs.keyword()]),
+				Subject: cs.rng.Ptr(),
+			}))
+		}
+	}
+
+	return diags
+}
+
+func (d *Decoder) unexpectedDirectiveDiagnostic(cs controlSequence, detail string, openCs *controlSequence, related relatedInfos) *hcl.Diagnostic {
+	diag := d.newDiagnostic(CodeUnexpectedDirective, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unexpected template directive",
+		Detail:   detail,
+		Subject:  cs.rng.Ptr(),
+	})
+	if openCs != nil {
+		related.add(diag, lang.DiagnosticRelatedInfo{
+			Range:   openCs.rng,
+			Message: fmt.Sprintf("The %s directive is declared here", openCs.keyword()),
+		})
+	}
+	return diag
+}
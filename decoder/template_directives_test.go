@@ -0,0 +1,238 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var templateDirectivesSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"tpl": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+	},
+}
+
+func TestDecoder_CandidatesAtPos_templateDirectives(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cfg                string
+		posAfter           string
+		expectedCandidates string
+	}{
+		{
+			"empty directive",
+			`tpl = "%{  }"
+`,
+			"%{ ",
+			`
+complete: true
+candidates: 2
+---
+label: "for"
+kind: KeywordCandidateKind
+detail: "directive"
+description (MarkdownKind): "Includes the template up to ` + "`%{ endfor }`" + ` once for each element of the collection"
+edit: test.tf:1,11-1,11 "for" (snippet "for ${1:item} in ${2:collection}")
+---
+label: "if"
+kind: KeywordCandidateKind
+detail: "directive"
+description (MarkdownKind): "Includes the template up to ` + "`%{ else }` or `%{ endif }`" + ` if the condition is true"
+edit: test.tf:1,11-1,11 "if" (snippet "if ${1:condition}")
+`,
+		},
+		{
+			"within if directive",
+			`tpl = "%{ if x }a%{ e }"
+`,
+			"a%{ e",
+			`
+complete: true
+candidates: 2
+---
+label: "else"
+kind: KeywordCandidateKind
+detail: "directive"
+description (MarkdownKind): "Includes the template up to ` + "`%{ endif }`" + ` if the condition is false"
+edit: test.tf:1,21-1,22 "else"
+---
+label: "endif"
+kind: KeywordCandidateKind
+detail: "directive"
+description (MarkdownKind): "Ends the ` + "`%{ if }`" + ` directive"
+edit: test.tf:1,21-1,22 "endif"
+`,
+		},
+		{
+			"unterminated directive within for directive",
+			`tpl = <<EOT
+%{ for x in y }
+%{ end
+EOT
+`,
+			"%{ end",
+			`
+complete: true
+candidates: 1
+---
+label: "endfor"
+kind: KeywordCandidateKind
+detail: "directive"
+description (MarkdownKind): "Ends the ` + "`%{ for }`" + ` directive"
+edit: test.tf:3,4-3,7 "endfor }"
+`,
+		},
+		{
+			"strip marker",
+			`tpl = "%{}"
+`,
+			"%{",
+			`
+complete: true
+candidates: 3
+---
+label: "for"
+kind: KeywordCandidateKind
+detail: "directive"
+description (MarkdownKind): "Includes the template up to ` + "`%{ endfor }`" + ` once for each element of the collection"
+edit: test.tf:1,10-1,10 "for" (snippet "for ${1:item} in ${2:collection}")
+---
+label: "if"
+kind: KeywordCandidateKind
+detail: "directive"
+description (MarkdownKind): "Includes the template up to ` + "`%{ else }` or `%{ endif }`" + ` if the condition is true"
+edit: test.tf:1,10-1,10 "if" (snippet "if ${1:condition}")
+---
+label: "~"
+kind: KeywordCandidateKind
+detail: "strip marker"
+description (MarkdownKind): "Strips any whitespace preceding the directive"
+edit: test.tf:1,10-1,10 "~"
+`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, templateDirectivesSchema, map[string]string{"test.tf": tc.cfg})
+
+			candidates, err := d.CandidatesAtPos("test.tf", posInCfg(t, tc.cfg, tc.posAfter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			langtest.AssertCandidates(t, tc.expectedCandidates, candidates)
+		})
+	}
+}
+
+func TestDecoder_ValidateFile_templateDirectives(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"balanced",
+			`tpl = "%{ if x }%{ for y in z }a%{ endfor }%{ else }b%{ endif }"
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"unclosed",
+			`tpl = "%{~ if x ~}a"
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unclosed template directive",
+					Detail:   "The if directive is missing its corresponding endif directive",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+						End:      hcl.Pos{Line: 1, Column: 19, Byte: 18},
+					},
+				},
+			},
+		},
+		{
+			"unexpected endif",
+			`tpl = "a%{ endif }"
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected template directive",
+					Detail:   "The endif directive has no corresponding if directive",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+						End:      hcl.Pos{Line: 1, Column: 19, Byte: 18},
+					},
+				},
+			},
+		},
+		{
+			"mismatched",
+			`tpl = "%{ for x in y }a%{ endif }"
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unclosed template directive",
+					Detail:   "The for directive is missing its corresponding endfor directive",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+						End:      hcl.Pos{Line: 1, Column: 23, Byte: 22},
+					},
+				},
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected template directive",
+					Detail:   "Expecting an endfor directive corresponding to the for directive",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 24, Byte: 23},
+						End:      hcl.Pos{Line: 1, Column: 34, Byte: 33},
+					},
+				},
+			},
+		},
+		{
+			"second else",
+			`tpl = "%{ if x }a%{ else }b%{ else }c%{ endif }"
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected template directive",
+					Detail:   "The if directive is already in its else clause",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 28, Byte: 27},
+						End:      hcl.Pos{Line: 1, Column: 37, Byte: 36},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, templateDirectivesSchema, map[string]string{"test.tf": tc.cfg})
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package decoder
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestMergeTextEdits(t *testing.T) {
+	mainEdit := lang.TextEdit{
+		NewText: "main",
+		Snippet: "main",
+		Range: hcl.Range{
+			Filename: "test.tf",
+			Start:    hcl.Pos{Line: 3, Column: 1, Byte: 20},
+			End:      hcl.Pos{Line: 3, Column: 5, Byte: 24},
+		},
+	}
+	insertionAt := func(byteOffset int, text string) lang.TextEdit {
+		return lang.TextEdit{
+			NewText: text,
+			Snippet: text,
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: byteOffset + 1, Byte: byteOffset},
+				End:      hcl.Pos{Line: 1, Column: byteOffset + 1, Byte: byteOffset},
+			},
+		}
+	}
+	replacementOf := func(start, end int, text string) lang.TextEdit {
+		return lang.TextEdit{
+			NewText: text,
+			Snippet: text,
+			Range: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: start + 1, Byte: start},
+				End:      hcl.Pos{Line: 1, Column: end + 1, Byte: end},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		edits         []lang.TextEdit
+		expectedEdits []lang.TextEdit
+	}{
+		{
+			"no edits",
+			[]lang.TextEdit{},
+			[]lang.TextEdit{},
+		},
+		{
+			"sorted by position",
+			[]lang.TextEdit{
+				insertionAt(10, "second"),
+				insertionAt(0, "first"),
+			},
+			[]lang.TextEdit{
+				insertionAt(0, "first"),
+				insertionAt(10, "second"),
+			},
+		},
+		{
+			"insertions at the same position",
+			[]lang.TextEdit{
+				insertionAt(0, "foo {}\n"),
+				insertionAt(0, "bar {}\n"),
+			},
+			[]lang.TextEdit{
+				insertionAt(0, "foo {}\nbar {}\n"),
+			},
+		},
+		{
+			"insertions at the same position with and without snippet",
+			[]lang.TextEdit{
+				{
+					NewText: "foo = ${var.foo}\n",
+					Range:   insertionAt(0, "").Range,
+				},
+				{
+					NewText: "bar = 1\n",
+					Snippet: "bar = ${1:1}\n",
+					Range:   insertionAt(0, "").Range,
+				},
+			},
+			[]lang.TextEdit{
+				{
+					NewText: "foo = ${var.foo}\nbar = 1\n",
+					Snippet: "foo = \\${var.foo\\}\nbar = ${1:1}\n",
+					Range:   insertionAt(0, "").Range,
+				},
+			},
+		},
+		{
+			"duplicate edits",
+			[]lang.TextEdit{
+				insertionAt(0, "foo {}\n"),
+				replacementOf(2, 5, "bar"),
+				insertionAt(0, "foo {}\n"),
+				replacementOf(2, 5, "bar"),
+			},
+			[]lang.TextEdit{
+				insertionAt(0, "foo {}\n"),
+				replacementOf(2, 5, "bar"),
+			},
+		},
+		{
+			"conflicting edits",
+			[]lang.TextEdit{
+				replacementOf(2, 8, "foo"),
+				replacementOf(5, 10, "bar"),
+				insertionAt(4, "baz"),
+				replacementOf(8, 10, "touching"),
+			},
+			[]lang.TextEdit{
+				replacementOf(2, 8, "foo"),
+				replacementOf(8, 10, "touching"),
+			},
+		},
+		{
+			"overlapping main edit",
+			[]lang.TextEdit{
+				replacementOf(18, 22, "foo"),
+				insertionAt(21, "bar"),
+				insertionAt(22, "baz"),
+				insertionAt(24, "touching"),
+			},
+			[]lang.TextEdit{
+				insertionAt(24, "touching"),
+			},
+		},
+		{
+			"invalid range",
+			[]lang.TextEdit{
+				replacementOf(8, 2, "foo"),
+				insertionAt(10, "bar"),
+			},
+			[]lang.TextEdit{
+				insertionAt(10, "bar"),
+			},
+		},
+		{
+			"different file",
+			[]lang.TextEdit{
+				{
+					NewText: "foo",
+					Range: hcl.Range{
+						Filename: "other.tf",
+						Start:    hcl.InitialPos,
+						End:      hcl.InitialPos,
+					},
+				},
+			},
+			[]lang.TextEdit{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			edits := lang.MergeTextEdits(mainEdit, tc.edits)
+			if diff := cmp.Diff(tc.expectedEdits, edits); diff != "" {
+				t.Fatalf("unexpected edits: %s", diff)
+			}
+		})
+	}
+}
+
+// TestMergeTextEdits_properties checks properties of merged edits
+// which clients rely on, for randomly generated edits
+func TestMergeTextEdits_properties(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+
+	randomEdit := func(filename string) lang.TextEdit {
+		start := rnd.Intn(30)
+		end := start
+		if rnd.Intn(2) == 0 {
+			end += rnd.Intn(6) - 1
+		}
+		text := string(rune('a' + rnd.Intn(3)))
+		return lang.TextEdit{
+			NewText: text,
+			Snippet: text,
+			Range: hcl.Range{
+				Filename: filename,
+				Start:    hcl.Pos{Line: 1, Column: start + 1, Byte: start},
+				End:      hcl.Pos{Line: 1, Column: end + 1, Byte: end},
+			},
+		}
+	}
+	// edits which merely touch each other are not overlapping,
+	// but two insertions at the same position are
+	overlap := func(a, b hcl.Range) bool {
+		if a.Start.Byte == a.End.Byte && b.Start.Byte == b.End.Byte {
+			return a.Start.Byte == b.Start.Byte
+		}
+		if a.Start.Byte == a.End.Byte || b.Start.Byte == b.End.Byte {
+			return (a.Start.Byte > b.Start.Byte && a.Start.Byte < b.End.Byte) ||
+				(b.Start.Byte > a.Start.Byte && b.Start.Byte < a.End.Byte)
+		}
+		return a.Start.Byte < b.End.Byte && b.Start.Byte < a.End.Byte
+	}
+
+	for i := 0; i < 1000; i++ {
+		mainEdit := randomEdit("test.tf")
+		if mainEdit.Range.End.Byte < mainEdit.Range.Start.Byte {
+			continue
+		}
+
+		edits := make([]lang.TextEdit, rnd.Intn(8))
+		for j := range edits {
+			filename := "test.tf"
+			if rnd.Intn(10) == 0 {
+				filename = "other.tf"
+			}
+			edits[j] = randomEdit(filename)
+		}
+
+		merged := lang.MergeTextEdits(mainEdit, edits)
+
+		for j, edit := range merged {
+			if edit.Range.Filename != mainEdit.Range.Filename {
+				t.Fatalf("%d: edit targets different file: %s", i, edit)
+			}
+			if edit.Range.End.Byte < edit.Range.Start.Byte {
+				t.Fatalf("%d: edit has invalid range: %s", i, edit)
+			}
+			if overlap(edit.Range, mainEdit.Range) {
+				t.Fatalf("%d: edit %s overlaps main edit %s", i, edit, mainEdit)
+			}
+			if j > 0 {
+				prev := merged[j-1]
+				if prev.Range.Start.Byte > edit.Range.Start.Byte {
+					t.Fatalf("%d: edits not sorted: %s, %s", i, prev, edit)
+				}
+			}
+			for _, other := range merged[j+1:] {
+				if overlap(edit.Range, other.Range) {
+					t.Fatalf("%d: edits overlap: %s, %s", i, edit, other)
+				}
+			}
+			if !hasEditWithRange(edits, edit.Range) {
+				t.Fatalf("%d: edit not derived from given edits: %s", i, edit)
+			}
+		}
+
+		if diff := cmp.Diff(merged, lang.MergeTextEdits(mainEdit, merged)); diff != "" {
+			t.Fatalf("%d: merging is not idempotent: %s", i, diff)
+		}
+	}
+}
+
+func hasEditWithRange(edits []lang.TextEdit, rng hcl.Range) bool {
+	for _, edit := range edits {
+		if edit.Range == rng {
+			return true
+		}
+	}
+	return false
+}
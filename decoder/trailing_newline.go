@@ -0,0 +1,177 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// trailingNewline returns significance of a trailing newline
+// of string values accepted by the constraints
+func (ec ExprConstraints) trailingNewline() schema.TrailingNewline {
+	for _, c := range ec.byPrecedence() {
+		if lt, ok := c.(schema.LiteralTypeExpr); ok && lt.Type == cty.String {
+			return lt.TrailingNewline
+		}
+	}
+	return schema.TrailingNewlineInsignificant
+}
+
+// validateTrailingNewline reports a string template whose trailing newline
+// doesn't match the expectation of the constraints, typically because
+// a heredoc (which always ends with a newline) is used where a quoted
+// string is expected or vice versa, along with a fix to correct it
+func (d *Decoder) validateTrailingNewline(name string, expr hclsyntax.Expression, ec ExprConstraints, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	expected := ec.trailingNewline()
+	if expected == schema.TrailingNewlineInsignificant {
+		return diags
+	}
+
+	te, ok := expr.(*hclsyntax.TemplateExpr)
+	if !ok {
+		return diags
+	}
+	hasNewline, ok := templateHasTrailingNewline(te)
+	if !ok {
+		// the end of the string is not known without further context
+		return diags
+	}
+
+	src, err := d.bytesFromRange(te.SrcRange)
+	if err != nil {
+		return diags
+	}
+	isHeredoc := bytes.HasPrefix(src, []byte("<<"))
+
+	switch {
+	case expected == schema.TrailingNewlineRequired && !hasNewline:
+		diag := d.newDiagnostic(CodeMissingTrailingNewline, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Missing trailing newline",
+			Detail:   fmt.Sprintf("Value of %q is expected to end with a newline", name),
+			Subject:  te.SrcRange.Ptr(),
+		})
+		diags = append(diags, diag)
+
+		if fixes != nil && !isHeredoc && bytes.HasSuffix(src, []byte(`"`)) {
+			// insert the escape sequence right before the closing quote
+			closingQuote := te.SrcRange.End
+			closingQuote.Byte--
+			closingQuote.Column--
+
+			*fixes = append(*fixes, lang.DiagnosticFix{
+				Title:      "Add trailing newline",
+				Diagnostic: diag,
+				Edits: []lang.TextEdit{
+					{
+						Range: hcl.Range{
+							Filename: te.SrcRange.Filename,
+							Start:    closingQuote,
+							End:      closingQuote,
+						},
+						NewText: `\n`,
+						Snippet: escapeSnippetText(`\n`),
+					},
+				},
+			})
+		}
+	case expected == schema.TrailingNewlineForbidden && hasNewline:
+		detail := fmt.Sprintf("Value of %q is not expected to end with a newline", name)
+		if isHeredoc {
+			detail += ", but a heredoc string always ends with one"
+		}
+		diag := d.newDiagnostic(CodeUnexpectedTrailingNewline, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Unexpected trailing newline",
+			Detail:   detail,
+			Subject:  te.SrcRange.Ptr(),
+		})
+		diags = append(diags, diag)
+
+		if fixes != nil {
+			if fix, ok := d.trailingNewlineRemovalFix(te, src, isHeredoc); ok {
+				fix.Diagnostic = diag
+				*fixes = append(*fixes, fix)
+			}
+		}
+	}
+
+	return diags
+}
+
+// trailingNewlineRemovalFix returns a fix which removes the trailing
+// newline of the template, i.e. replaces a heredoc (with no interpolation)
+// by a quoted string or removes the trailing \n from a quoted string
+func (d *Decoder) trailingNewlineRemovalFix(te *hclsyntax.TemplateExpr, src []byte, isHeredoc bool) (lang.DiagnosticFix, bool) {
+	if isHeredoc {
+		if len(te.Variables()) > 0 {
+			return lang.DiagnosticFix{}, false
+		}
+		val, diags := te.Value(nil)
+		if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+			return lang.DiagnosticFix{}, false
+		}
+		quoted := d.addrFormat.Quote(strings.TrimSuffix(val.AsString(), "\n"))
+
+		return lang.DiagnosticFix{
+			Title: "Replace heredoc with quoted string",
+			Edits: []lang.TextEdit{
+				{
+					Range:   te.SrcRange,
+					NewText: quoted,
+					Snippet: escapeSnippetText(quoted),
+				},
+			},
+		}, true
+	}
+
+	if !bytes.HasSuffix(src, []byte(`\n"`)) {
+		return lang.DiagnosticFix{}, false
+	}
+
+	// remove the escape sequence right before the closing quote
+	end := te.SrcRange.End
+	end.Byte--
+	end.Column--
+	start := end
+	start.Byte -= 2
+	start.Column -= 2
+
+	return lang.DiagnosticFix{
+		Title: "Remove trailing newline",
+		Edits: []lang.TextEdit{
+			{
+				Range: hcl.Range{
+					Filename: te.SrcRange.Filename,
+					Start:    start,
+					End:      end,
+				},
+				NewText: "",
+				Snippet: "",
+			},
+		},
+	}, true
+}
+
+// templateHasTrailingNewline returns true if the template ends
+// with a newline, provided that its last part is a literal
+func templateHasTrailingNewline(te *hclsyntax.TemplateExpr) (bool, bool) {
+	if len(te.Parts) == 0 {
+		return false, true
+	}
+
+	lve, ok := te.Parts[len(te.Parts)-1].(*hclsyntax.LiteralValueExpr)
+	if !ok || lve.Val.Type() != cty.String || lve.Val.IsNull() {
+		return false, false
+	}
+
+	return strings.HasSuffix(lve.Val.AsString(), "\n"), true
+}
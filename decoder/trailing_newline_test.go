@@ -0,0 +1,202 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var trailingNewlineSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"cert": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralTypeExpr{Type: cty.String, TrailingNewline: schema.TrailingNewlineRequired},
+			},
+		},
+		"token": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.LiteralTypeExpr{Type: cty.String, TrailingNewline: schema.TrailingNewlineForbidden},
+			},
+		},
+		"any": {
+			IsOptional: true,
+			Expr:       schema.LiteralTypeOnly(cty.String),
+		},
+	},
+}
+
+func TestDecoder_ValidateFileWithFixes_trailingNewline(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		expectedSummary string
+		expectedFix     string
+		expectedCfg     string
+	}{
+		{
+			"required newline in heredoc",
+			`cert = <<EOT
+-----BEGIN CERTIFICATE-----
+-----END CERTIFICATE-----
+EOT
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"required newline in quoted string",
+			`cert = "-----BEGIN CERTIFICATE-----\n"
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"missing newline in quoted string",
+			`cert = "-----END CERTIFICATE-----"
+`,
+			"Missing trailing newline",
+			"Add trailing newline",
+			`cert = "-----END CERTIFICATE-----\n"
+`,
+		},
+		{
+			"unexpected newline in heredoc",
+			`token = <<EOT
+sec"ret
+EOT
+`,
+			"Unexpected trailing newline",
+			"Replace heredoc with quoted string",
+			`token = "sec\"ret"
+`,
+		},
+		{
+			"unexpected newline in indented heredoc",
+			`token = <<-EOT
+    secret
+    EOT
+`,
+			"Unexpected trailing newline",
+			"Replace heredoc with quoted string",
+			`token = "secret"
+`,
+		},
+		{
+			"unexpected newline in heredoc with interpolation",
+			`token = <<EOT
+${var.prefix}secret
+EOT
+`,
+			"Unexpected trailing newline",
+			"",
+			"",
+		},
+		{
+			"unexpected newline in quoted string",
+			`token = "secret\n"
+`,
+			"Unexpected trailing newline",
+			"Remove trailing newline",
+			`token = "secret"
+`,
+		},
+		{
+			"no newline in quoted string",
+			`token = "secret"
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"unknown end of template",
+			`token = "secret${var.suffix}"
+`,
+			"",
+			"",
+			"",
+		},
+		{
+			"insignificant newline",
+			`any = <<EOT
+anything
+EOT
+`,
+			"",
+			"",
+			"",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(trailingNewlineSchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expectedSummary == "" {
+				if len(diags) > 0 {
+					t.Fatalf("expected no diagnostics, given: %#v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, given: %#v", diags)
+			}
+			if diags[0].Severity != hcl.DiagWarning || diags[0].Summary != tc.expectedSummary {
+				t.Fatalf("unexpected diagnostic: %#v", diags[0])
+			}
+
+			diagFixes := fixes.ForDiagnostic(diags[0])
+			if tc.expectedFix == "" {
+				if len(diagFixes) > 0 {
+					t.Fatalf("expected no fixes, given: %#v", diagFixes)
+				}
+				return
+			}
+			if len(diagFixes) != 1 {
+				t.Fatalf("expected 1 fix, given: %#v", diagFixes)
+			}
+			if diagFixes[0].Title != tc.expectedFix {
+				t.Fatalf("unexpected fix: %q", diagFixes[0].Title)
+			}
+
+			fixedCfg := applyTextEdits(tc.cfg, diagFixes[0].Edits)
+			if fixedCfg != tc.expectedCfg {
+				t.Fatalf("unexpected config after fix:\n%s", fixedCfg)
+			}
+		})
+	}
+}
+
+// applyTextEdits applies edits (assumed to be non-overlapping
+// and in document order) to the config
+func applyTextEdits(cfg string, edits []lang.TextEdit) string {
+	for i := len(edits) - 1; i >= 0; i-- {
+		edit := edits[i]
+		cfg = cfg[:edit.Range.Start.Byte] + edit.NewText + cfg[edit.Range.End.Byte:]
+	}
+	return cfg
+}
@@ -0,0 +1,208 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var unknownAttributesSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"strict": {
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"known": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+				},
+			},
+		},
+		"lenient": {
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"known": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+				},
+				AllowUnknownAttributes: true,
+			},
+		},
+		"overridden": {
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"known": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.String)},
+				},
+			},
+			AllowUnknownAttributes: true,
+		},
+	},
+}
+
+func TestDecoder_ValidateFile_unknownAttributes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		features      Features
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"strict body",
+			`strict {
+  unknown = "foo"
+}
+`,
+			Features{},
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected attribute",
+					Detail:   `An attribute named "unknown" is not expected here`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 2, Column: 3, Byte: 11},
+						End:      hcl.Pos{Line: 2, Column: 10, Byte: 18},
+					},
+				},
+			},
+		},
+		{
+			"lenient body",
+			`lenient {
+  unknown = "foo"
+}
+`,
+			Features{},
+			hcl.Diagnostics{},
+		},
+		{
+			"lenient body with hints",
+			`lenient {
+  unknown = "foo"
+}
+`,
+			Features{UnknownAttributeHints: true},
+			hcl.Diagnostics{
+				{
+					Severity: lang.DiagHint,
+					Summary:  "Unknown attribute",
+					Detail:   `An attribute named "unknown" is not declared in the schema`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 2, Column: 3, Byte: 12},
+						End:      hcl.Pos{Line: 2, Column: 10, Byte: 19},
+					},
+				},
+			},
+		},
+		{
+			"lenient body still validates known attributes",
+			`lenient {
+  known = ["foo"]
+}
+`,
+			Features{},
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid value type",
+					Detail:   `Value of "known" must be string, tuple given`,
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 2, Column: 11, Byte: 20},
+						End:      hcl.Pos{Line: 2, Column: 18, Byte: 27},
+					},
+				},
+			},
+		},
+		{
+			"block override",
+			`overridden {
+  unknown = "foo"
+}
+`,
+			Features{},
+			hcl.Diagnostics{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(unknownAttributesSchema)
+			d.SetFeatures(tc.features)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFile_unknownAttributesJSON(t *testing.T) {
+	f, pDiags := json.Parse([]byte(`{
+  "lenient": {
+    "unknown": "foo"
+  }
+}`), "test.tf.json")
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+
+	d := NewDecoder()
+	d.SetSchema(unknownAttributesSchema)
+	err := d.LoadFile("test.tf.json", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := d.ValidateFile("test.tf.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) > 0 {
+		t.Fatalf("expected no diagnostics, given: %#v", diags)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_unknownAttributes(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(unknownAttributesSchema)
+
+	cfg := `lenient {
+  unknown = "foo"
+  
+}
+`
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 3, Column: 3, Byte: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := make([]string, 0)
+	for _, c := range candidates.List {
+		labels = append(labels, c.Label)
+	}
+	expectedLabels := []string{"known"}
+	if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
@@ -0,0 +1,597 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// ValidateFile returns diagnostics for the given file based on the schema,
+// such as unexpected attributes or blocks, attribute values
+// which do not conform to the declared type, or references
+// to targets of a type not convertible to the one expected.
+//
+// References are only validated if ReferenceTargetReader is set.
+//
+// Schema is required in order to validate the file and method will return
+// error if there isn't one.
+func (d *Decoder) ValidateFile(filename string, opts ...RequestOption) (hcl.Diagnostics, error) {
+	d = d.withRequestOptions(opts)
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	return d.validateFile(filename, nil, nil)
+}
+
+// ValidateFileWithFixes returns diagnostics for the given file
+// (see ValidateFile) along with fixes suggested for some of them,
+// such as correction of misspelled keywords.
+func (d *Decoder) ValidateFileWithFixes(filename string, opts ...RequestOption) (hcl.Diagnostics, lang.DiagnosticFixes, error) {
+	d = d.withRequestOptions(opts)
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	fixes := make(lang.DiagnosticFixes, 0)
+	diags, err := d.validateFile(filename, &fixes, nil)
+	if err != nil {
+		return diags, lang.DiagnosticFixes{}, err
+	}
+
+	return diags, fixes, nil
+}
+
+// ValidateFileWithRelatedInfo returns diagnostics for the given file
+// (see ValidateFile) along with their codes and locations related
+// to some of them, such as the definition of a target of an invalid
+// reference or docs of a body where an attribute or block is not expected.
+func (d *Decoder) ValidateFileWithRelatedInfo(filename string, opts ...RequestOption) (lang.Diagnostics, error) {
+	d = d.withRequestOptions(opts)
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	d = d.withDiagnosticCodes(make(diagnosticCodes, 0))
+
+	related := make(relatedInfos, 0)
+	diags, err := d.validateFile(filename, nil, related)
+	if err != nil {
+		return d.validationDiagnostics(diags, related), err
+	}
+
+	return d.validationDiagnostics(diags, related), nil
+}
+
+// validateFile returns diagnostics for the given file,
+// assuming the read lock of the schema is held.
+// Any suggested fixes are appended to fixes and any related
+// locations are collected in related, unless nil.
+func (d *Decoder) validateFile(filename string, fixes *lang.DiagnosticFixes, related relatedInfos) (hcl.Diagnostics, error) {
+	segments, err := d.segmentsForFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.rootSchema == nil {
+		return hcl.Diagnostics{}, &NoSchemaError{}
+	}
+
+	diags := hcl.Diagnostics{}
+	for _, segment := range segments {
+		diags = append(diags, d.validateGenericBody(segment.Body, d.rootSchema, fixes, related)...)
+		if body, ok := segment.Body.(*hclsyntax.Body); ok {
+			diags = append(diags, d.validateTemplateDirectives(body, related)...)
+			diags = append(diags, d.validateStringEscapes(body, fixes)...)
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Subject.Start.Byte < diags[j].Subject.Start.Byte
+	})
+
+	return d.truncateDiagnostics(filename, diags, fixes), nil
+}
+
+func (d *Decoder) validateBody(body *hclsyntax.Body, bodySchema *schema.BodySchema, fixes *lang.DiagnosticFixes, related relatedInfos) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if bodySchema == nil {
+		return diags
+	}
+
+	diags = append(diags, d.validateOrdering(body, bodySchema, fixes, related)...)
+
+	for _, attr := range body.Attributes {
+		aSchema, ok := attributeSchemaForName(bodySchema, attr.Name)
+		if !ok {
+			diags = append(diags, d.unknownAttributeDiagnostics(bodySchema, attr.Name, attr.NameRange, related)...)
+			continue
+		}
+
+		diags = append(diags, d.validateAttributeExpr(attr.Name, attr.Expr, aSchema)...)
+		diags = append(diags, d.validateNamedTuple(attr.Name, attr.Expr, ExprConstraints(aSchema.Expr))...)
+		diags = append(diags, d.validateForExprGrouping(attr.Name, attr.Expr, ExprConstraints(aSchema.Expr), fixes)...)
+		diags = append(diags, d.validateSize(attr.Name, attr.Expr, ExprConstraints(aSchema.Expr), fixes)...)
+		diags = append(diags, d.validateReferenceTypes(attr.Expr, ExprConstraints(aSchema.Expr), related)...)
+		diags = append(diags, d.validateReferenceScopes(attr.Expr, ExprConstraints(aSchema.Expr), related)...)
+		diags = append(diags, d.validateKeywordSpelling(attr.Expr, ExprConstraints(aSchema.Expr), fixes)...)
+		diags = append(diags, d.validateTrailingNewline(attr.Name, attr.Expr, ExprConstraints(aSchema.Expr), fixes)...)
+		diags = append(diags, d.validateHeredoc(attr.Name, attr.Expr, ExprConstraints(aSchema.Expr), fixes)...)
+		diags = append(diags, d.validateFunctionCalls(attr.Expr, ExprConstraints(aSchema.Expr), fixes)...)
+		diags = append(diags, d.validateSensitiveReferences(attr.Name, attr.Expr, aSchema)...)
+		diags = append(diags, d.validateWriteOnlyReferences(attr.Expr, aSchema)...)
+		diags = append(diags, d.validateAliasedReferences(attr.Expr, fixes, related)...)
+		diags = append(diags, d.validateRedundantDefault(attr, aSchema, fixes)...)
+		diags = append(diags, d.validateLiteralReferences(attr, ExprConstraints(aSchema.Expr), fixes)...)
+	}
+
+	for _, block := range body.Blocks {
+		bSchema, ok := blockSchemaForType(bodySchema, block.Type)
+		if !ok {
+			diag := d.newDiagnostic(CodeUnexpectedBlock, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unexpected block",
+				Detail:   fmt.Sprintf("Blocks of type %q are not expected here", block.Type),
+				Subject:  block.TypeRange.Ptr(),
+			})
+			d.addBodyDocs(related, diag, bodySchema)
+			diags = append(diags, diag)
+			continue
+		}
+
+		diags = append(diags, d.validateLabelCount(block, bSchema.Labels)...)
+		diags = append(diags, d.validateLabels(block.Labels, block.LabelRanges, bSchema.Labels)...)
+
+		if block.Body != nil {
+			mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
+			if err != nil {
+				continue
+			}
+			diags = append(diags, d.validateBody(block.Body, mergedSchema, fixes, related)...)
+		}
+	}
+
+	return diags
+}
+
+// validateLabelCount returns diagnostics for missing or extraneous
+// labels of a block in the native syntax. Labels of blocks
+// in other syntaxes are validated when decoding the body content.
+func (d *Decoder) validateLabelCount(block *hclsyntax.Block, labelSchemas []*schema.LabelSchema) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	labelNames := make([]string, len(labelSchemas))
+	for i, labelSchema := range labelSchemas {
+		labelNames[i] = labelSchema.Name
+	}
+
+	if len(block.Labels) < len(labelSchemas) {
+		return append(diags, d.newDiagnostic(CodeMissingLabel, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Missing %s for %s", labelNames[len(block.Labels)], block.Type),
+			Detail: fmt.Sprintf("All %s blocks must have %d labels (%s).",
+				block.Type, len(labelNames), strings.Join(labelNames, ", ")),
+			Subject: block.OpenBraceRange.Ptr(),
+			Context: block.DefRange().Ptr(),
+		}))
+	}
+
+	if len(block.Labels) > len(labelSchemas) && len(block.LabelRanges) > len(labelSchemas) {
+		detail := fmt.Sprintf("No labels are expected for %s blocks.", block.Type)
+		if len(labelNames) > 0 {
+			detail = fmt.Sprintf("Only %d labels (%s) are expected for %s blocks.",
+				len(labelNames), strings.Join(labelNames, ", "), block.Type)
+		}
+		return append(diags, d.newDiagnostic(CodeExtraneousLabel, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Extraneous label for %s", block.Type),
+			Detail:   detail,
+			Subject:  block.LabelRanges[len(labelSchemas)].Ptr(),
+			Context:  block.DefRange().Ptr(),
+		}))
+	}
+
+	return diags
+}
+
+// validateLabels returns diagnostics for label values
+// which do not conform to the rules of their label schema
+func (d *Decoder) validateLabels(labels []string, labelRanges []hcl.Range, labelSchemas []*schema.LabelSchema) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for i, labelSchema := range labelSchemas {
+		if i >= len(labels) || i >= len(labelRanges) {
+			break
+		}
+
+		err := labelSchema.ValidateValue(labels[i])
+		if err != nil {
+			diags = append(diags, d.newDiagnostic(CodeInvalidLabel, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid label",
+				Detail:   fmt.Sprintf("Value %q of label %q %s", labels[i], labelSchema.Name, err),
+				Subject:  labelRanges[i].Ptr(),
+			}))
+		}
+	}
+
+	return diags
+}
+
+// unknownAttributeDiagnostics returns diagnostics for an attribute
+// which is not declared in the schema, unless the body allows such
+// attributes, in which case only a hint is returned, if enabled
+func (d *Decoder) unknownAttributeDiagnostics(bodySchema *schema.BodySchema, name string, nameRng hcl.Range, related relatedInfos) hcl.Diagnostics {
+	if !bodySchema.AllowUnknownAttributes {
+		diag := d.unexpectedAttributeDiagnostic(bodySchema, name, nameRng)
+		d.addBodyDocs(related, diag, bodySchema)
+		return hcl.Diagnostics{diag}
+	}
+
+	if !d.features.UnknownAttributeHints {
+		return hcl.Diagnostics{}
+	}
+
+	return hcl.Diagnostics{
+		d.newDiagnostic(CodeUnknownAttribute, &hcl.Diagnostic{
+			Severity: lang.DiagHint,
+			Summary:  "Unknown attribute",
+			Detail:   fmt.Sprintf("An attribute named %q is not declared in the schema", name),
+			Subject:  nameRng.Ptr(),
+		}),
+	}
+}
+
+func (d *Decoder) unexpectedAttributeDiagnostic(bodySchema *schema.BodySchema, name string, nameRng hcl.Range) *hcl.Diagnostic {
+	if bodySchema.AnyAttribute != nil && bodySchema.AnyAttribute.NameConstraint != nil {
+		return d.newDiagnostic(CodeInvalidAttributeName, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid attribute name",
+			Detail: fmt.Sprintf("An attribute named %q is not valid here, expected name %s",
+				name, bodySchema.AnyAttribute.NameConstraint.FriendlyName()),
+			Subject: nameRng.Ptr(),
+		})
+	}
+	return d.newDiagnostic(CodeUnexpectedAttribute, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unexpected attribute",
+		Detail:   fmt.Sprintf("An attribute named %q is not expected here", name),
+		Subject:  nameRng.Ptr(),
+	})
+}
+
+func (d *Decoder) validateAttributeExpr(name string, expr hcl.Expression, aSchema *schema.AttributeSchema) hcl.Diagnostics {
+	ec := ExprConstraints(aSchema.Expr)
+
+	if _, ok := ec.LiteralTypesOnly(); !ok {
+		// other constraints (e.g. references) cannot be validated
+		// without further context
+		return hcl.Diagnostics{}
+	}
+
+	if len(expr.Variables()) > 0 {
+		return hcl.Diagnostics{}
+	}
+
+	val, vDiags := expr.Value(nil)
+	if vDiags.HasErrors() || !val.IsWhollyKnown() {
+		// value not known without further context (e.g. functions)
+		return hcl.Diagnostics{}
+	}
+
+	isComplexLiteral := false
+	switch hcl.UnwrapExpression(expr).(type) {
+	case *hclsyntax.TupleConsExpr, *hclsyntax.ObjectConsExpr:
+		isComplexLiteral = true
+	}
+
+	// only constraints which the value can match are described
+	considered := make(schema.ExprConstraints, 0, len(ec))
+	for _, c := range ec.byPrecedence() {
+		if isComplexLiteral && skipsComplexLiterals(c) {
+			continue
+		}
+		if _, err := convert.Convert(val, c.(schema.LiteralTypeExpr).Type); err == nil {
+			return hcl.Diagnostics{}
+		}
+		considered = append(considered, c)
+	}
+
+	detail := fmt.Sprintf("Value of %q must be %s, %s given",
+		name, considered.FriendlyName(), val.Type().FriendlyName())
+	if len(considered) == 0 {
+		detail = fmt.Sprintf("Value of %q cannot be a literal %s",
+			name, val.Type().FriendlyName())
+	}
+
+	return hcl.Diagnostics{
+		d.newDiagnostic(CodeInvalidValueType, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value type",
+			Detail:   detail,
+			Subject:  expr.Range().Ptr(),
+		}),
+	}
+}
+
+// validateReferenceTypes reports a reference (making up the whole
+// expression) whose target is known, but of a type which cannot
+// be converted to the type expected by any of the traversal constraints.
+func (d *Decoder) validateReferenceTypes(expr hcl.Expression, ec ExprConstraints, related relatedInfos) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	readTargets := d.referenceTargetReader()
+	if readTargets == nil {
+		return diags
+	}
+
+	tes := ec.TraversalExprs()
+	if len(tes) == 0 {
+		return diags
+	}
+	for _, te := range tes {
+		if te.OfType == cty.NilType || te.OfType == cty.DynamicPseudoType {
+			// any type is acceptable
+			return diags
+		}
+	}
+
+	targets := ReferenceTargets(readTargets())
+
+	// the constraint only applies to the expression as a whole,
+	// not to references nested e.g. within function calls,
+	// operators, conditionals or templates
+	exprRng := hcl.UnwrapExpression(expr).Range()
+
+	for _, ref := range exprReferences(expr) {
+		if ref.Range != exprRng {
+			continue
+		}
+		if ref.IndexRole != indexRoleNone {
+			// type of the element or key is not known upfront
+			continue
+		}
+		addr := ref.Addr
+
+		addrTargets := targets
+		if !targets.containsAddr(addr) {
+			// e.g. element of a list (aws_instance.web[0].id)
+			synthesized, ok := d.synthesizedTargetForAddr(targets, addr)
+			if !ok {
+				continue
+			}
+			addrTargets = ReferenceTargets{synthesized}
+		}
+
+		var mismatchingTarget *lang.ReferenceTarget
+		isConvertible := false
+		addrTargets.DeepWalk(func(target lang.ReferenceTarget) error {
+			if !Address(target.Addr).Equals(Address(addr)) {
+				return nil
+			}
+			if target.Type == cty.NilType || target.Type == cty.DynamicPseudoType {
+				// type unknown
+				isConvertible = true
+				return StopWalking
+			}
+			for _, te := range tes {
+				if !ReferenceTarget(target).MatchesAnyScopeId(te.ScopeIds()) {
+					continue
+				}
+				if target.Type.Equals(te.OfType) || convert.GetConversion(target.Type, te.OfType) != nil {
+					isConvertible = true
+					return StopWalking
+				}
+				if mismatchingTarget == nil {
+					mismatchingTarget = &target
+				}
+			}
+			return nil
+		})
+
+		if isConvertible || mismatchingTarget == nil {
+			continue
+		}
+
+		diag := d.newDiagnostic(CodeInvalidReferenceType, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid reference type",
+			Detail: fmt.Sprintf("%s is %s, which cannot be converted to %s",
+				addr, mismatchingTarget.Type.FriendlyName(), friendlyNameForTraversalTypes(tes)),
+			Subject: ref.Range.Ptr(),
+		})
+		d.addTargetDefinition(related, diag, *mismatchingTarget)
+		diags = append(diags, diag)
+	}
+
+	return diags
+}
+
+// validateSensitiveReferences reports references to sensitive targets
+// within value of an attribute which is flagged as insecure,
+// i.e. where the sensitive value may be exposed.
+func (d *Decoder) validateSensitiveReferences(name string, expr hcl.Expression, aSchema *schema.AttributeSchema) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	readTargets := d.referenceTargetReader()
+	if !aSchema.IsInsecure || readTargets == nil {
+		return diags
+	}
+
+	targets := ReferenceTargets(readTargets())
+
+	for _, ref := range exprReferences(expr) {
+		if !targets.isSensitiveAddr(ref.Addr) {
+			continue
+		}
+
+		diags = append(diags, d.newDiagnostic(CodeSensitiveValueInInsecure, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Sensitive value in insecure attribute",
+			Detail: fmt.Sprintf("%s is sensitive and may be exposed via %q",
+				d.addrFormat.Format(ref.Addr), name),
+			Subject: ref.Range.Ptr(),
+		}))
+	}
+
+	return diags
+}
+
+// validateWriteOnlyReferences reports references to write-only targets
+// within value of an attribute which is not write-only itself,
+// i.e. where the value is not available.
+func (d *Decoder) validateWriteOnlyReferences(expr hcl.Expression, aSchema *schema.AttributeSchema) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	readTargets := d.referenceTargetReader()
+	if aSchema.IsWriteOnly || readTargets == nil {
+		return diags
+	}
+
+	targets := ReferenceTargets(readTargets())
+
+	for _, ref := range exprReferences(expr) {
+		if !targets.isWriteOnlyAddr(ref.Addr) {
+			continue
+		}
+
+		diags = append(diags, d.newDiagnostic(CodeReferenceToWriteOnly, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Reference to write-only value",
+			Detail: fmt.Sprintf("%s is write-only, so it can only be referenced from write-only attributes",
+				d.addrFormat.Format(ref.Addr)),
+			Subject: ref.Range.Ptr(),
+		}))
+	}
+
+	return diags
+}
+
+// validateReferenceScopes reports references whose target is known,
+// but of a scope which none of the traversal constraints accept.
+func (d *Decoder) validateReferenceScopes(expr hcl.Expression, ec ExprConstraints, related relatedInfos) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	readTargets := d.referenceTargetReader()
+	if readTargets == nil {
+		return diags
+	}
+
+	tes := ec.TraversalExprs()
+	if len(tes) == 0 {
+		return diags
+	}
+	scopeIds := make([]lang.ScopeId, 0)
+	for _, te := range tes {
+		teScopeIds := te.ScopeIds()
+		if len(teScopeIds) == 0 {
+			// any scope is acceptable
+			return diags
+		}
+		scopeIds = lang.ScopeIds("", append(scopeIds, teScopeIds...))
+	}
+
+	targets := ReferenceTargets(readTargets())
+
+	for _, ref := range exprReferences(expr) {
+		if ref.IndexRole == indexRoleKey {
+			// the key is unrelated to the constraint
+			continue
+		}
+
+		var mismatchingTarget *lang.ReferenceTarget
+		isMatching := false
+		targets.DeepWalk(func(target lang.ReferenceTarget) error {
+			if target.ScopeId == "" || !Address(target.Addr).Equals(Address(ref.Addr)) {
+				return nil
+			}
+			if ReferenceTarget(target).MatchesAnyScopeId(scopeIds) {
+				isMatching = true
+				return StopWalking
+			}
+			if mismatchingTarget == nil {
+				mismatchingTarget = &target
+			}
+			return nil
+		})
+
+		if isMatching || mismatchingTarget == nil {
+			continue
+		}
+
+		diag := d.newDiagnostic(CodeInvalidReferenceScope, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid reference scope",
+			Detail: fmt.Sprintf("%s is of scope %q, expected %s",
+				ref.Addr, mismatchingTarget.ScopeId, friendlyNameForScopeIds(scopeIds)),
+			Subject: ref.Range.Ptr(),
+		})
+		d.addTargetDefinition(related, diag, *mismatchingTarget)
+		diags = append(diags, diag)
+	}
+
+	return diags
+}
+
+// isSensitiveAddr returns true if the address or any of its parents
+// (e.g. var.secret of var.secret.foo) is targeting a sensitive target
+func (refs ReferenceTargets) isSensitiveAddr(addr lang.Address) bool {
+	return refs.isAddrOfTarget(addr, func(target lang.ReferenceTarget) bool {
+		return target.Sensitive
+	})
+}
+
+// isWriteOnlyAddr returns true if the address or any of its parents
+// is targeting a write-only target
+func (refs ReferenceTargets) isWriteOnlyAddr(addr lang.Address) bool {
+	return refs.isAddrOfTarget(addr, func(target lang.ReferenceTarget) bool {
+		return target.WriteOnly
+	})
+}
+
+// isAddrOfTarget returns true if the address or any of its parents
+// is targeting a target for which f returns true
+func (refs ReferenceTargets) isAddrOfTarget(addr lang.Address, f func(lang.ReferenceTarget) bool) bool {
+	found := false
+	refs.DeepWalk(func(target lang.ReferenceTarget) error {
+		if !f(target) || len(target.Addr) > len(addr) {
+			return nil
+		}
+		if Address(target.Addr).Equals(Address(addr).FirstSteps(uint(len(target.Addr)))) {
+			found = true
+			return StopWalking
+		}
+		return nil
+	})
+	return found
+}
+
+func friendlyNameForTraversalTypes(tes []schema.TraversalExpr) string {
+	names := make([]string, 0)
+	seen := make(map[string]bool, 0)
+	for _, te := range tes {
+		name := te.OfType.FriendlyNameForConstraint()
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return strings.Join(names, " or ")
+}
+
+func friendlyNameForScopeIds(scopeIds []lang.ScopeId) string {
+	names := make([]string, len(scopeIds))
+	for i, scopeId := range scopeIds {
+		names[i] = fmt.Sprintf("%q", scopeId)
+	}
+	return strings.Join(names, " or ")
+}
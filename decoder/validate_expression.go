@@ -0,0 +1,90 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/position"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// expressionAttrName is the name used to refer to a standalone
+// expression in details of diagnostics (e.g. Value of "expression" ...)
+const expressionAttrName = "expression"
+
+// ValidateExpression returns diagnostics for a standalone expression
+// in the native syntax, such as a value entered into a form, based
+// on the given constraints and signatures of functions which can
+// be called.
+//
+// Positions of diagnostics are relative to the beginning of src
+// and the filename of their ranges is empty.
+//
+// Syntax errors are returned as the only diagnostics, if any.
+// References are not validated, as there are no reference targets
+// to validate them against.
+func ValidateExpression(src []byte, constraints schema.ExprConstraints, funcs map[string]schema.FunctionSignature) hcl.Diagnostics {
+	d, diags := newExpressionDecoder(src, constraints, funcs)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	vDiags, err := d.ValidateFile("")
+	if err != nil {
+		return diags
+	}
+
+	return append(diags, vDiags...)
+}
+
+// newExpressionDecoder returns a decoder of a standalone expression,
+// which is decoded as a value of an attribute of an otherwise empty
+// file (with an empty name), along with any syntax errors
+func newExpressionDecoder(src []byte, constraints schema.ExprConstraints, funcs map[string]schema.FunctionSignature) (*Decoder, hcl.Diagnostics) {
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.InitialPos)
+
+	// the body spans all of the source, as the expression
+	// doesn't, e.g. where it ends with a dot (var.)
+	endPos, err := position.ByteOffsetToPos(src, len(src))
+	if err != nil {
+		endPos = expr.Range().End
+	}
+	bodyRng := hcl.Range{
+		Start: hcl.InitialPos,
+		End:   endPos,
+	}
+
+	attr := &hclsyntax.Attribute{
+		Name:      expressionAttrName,
+		Expr:      expr,
+		SrcRange:  expr.Range(),
+		NameRange: expr.StartRange(),
+	}
+	body := &hclsyntax.Body{
+		Attributes: hclsyntax.Attributes{
+			expressionAttrName: attr,
+		},
+		SrcRange: bodyRng,
+		EndRange: hcl.Range{
+			Start: endPos,
+			End:   endPos,
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			expressionAttrName: {
+				Expr:       constraints,
+				IsOptional: true,
+			},
+		},
+	})
+	d.SetFunctions(funcs)
+	// the file is valid, as it always has a body
+	_ = d.LoadFile("", &hcl.File{
+		Body:  body,
+		Bytes: src,
+	})
+
+	return d, diags
+}
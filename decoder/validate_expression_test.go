@@ -0,0 +1,107 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestValidateExpression(t *testing.T) {
+	testCases := []struct {
+		name          string
+		src           string
+		constraints   schema.ExprConstraints
+		expectedDiags []expressionDiag
+	}{
+		{
+			"valid literal",
+			`42`,
+			schema.LiteralTypeOnly(cty.Number),
+			[]expressionDiag{},
+		},
+		{
+			"invalid value type",
+			`"foo"`,
+			schema.LiteralTypeOnly(cty.Number),
+			[]expressionDiag{
+				{
+					summary: "Invalid value type",
+					detail:  `Value of "expression" must be number, string given`,
+					rng:     "1,1-1,6",
+				},
+			},
+		},
+		{
+			"syntax error",
+			`[1,`,
+			schema.LiteralTypeOnly(cty.List(cty.Number)),
+			[]expressionDiag{
+				{
+					summary: "Invalid expression",
+					detail:  "Expected the start of an expression, but found an invalid expression token.",
+					rng:     "1,4-1,4",
+				},
+			},
+		},
+		{
+			"function call with too many arguments",
+			`upper("a", "b")`,
+			schema.LiteralTypeOnly(cty.String),
+			[]expressionDiag{
+				{
+					summary: "Too many function arguments",
+					detail:  `Function "upper" expects only 1 argument(s).`,
+					rng:     "1,12-1,15",
+				},
+			},
+		},
+		{
+			"deprecated function",
+			`lowercase("A")`,
+			schema.LiteralTypeOnly(cty.String),
+			[]expressionDiag{
+				{
+					summary: "Deprecated function",
+					detail:  `Function "lowercase" is deprecated: Deprecated since v2. Use "lower" instead.`,
+					rng:     "1,1-1,10",
+				},
+			},
+		},
+		{
+			"reference",
+			`var.foo`,
+			schema.LiteralTypeOnly(cty.Number),
+			[]expressionDiag{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			diags := ValidateExpression([]byte(tc.src), tc.constraints, functionCallFunctions)
+
+			given := make([]expressionDiag, len(diags))
+			for i, diag := range diags {
+				given[i] = expressionDiag{
+					summary: diag.Summary,
+					detail:  diag.Detail,
+					rng:     rangeWithoutFilename(diag.Subject),
+				}
+			}
+			if diff := cmp.Diff(tc.expectedDiags, given, cmp.AllowUnexported(expressionDiag{})); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
+
+type expressionDiag struct {
+	summary, detail, rng string
+}
+
+func rangeWithoutFilename(rng *hcl.Range) string {
+	return fmt.Sprintf("%d,%d-%d,%d", rng.Start.Line, rng.Start.Column, rng.End.Line, rng.End.Column)
+}
@@ -0,0 +1,471 @@
+package decoder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_ValidateFile_noSchema(t *testing.T) {
+	d := NewDecoder()
+	f, pDiags := hclsyntax.ParseConfig([]byte(`attr = "foo"`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = d.ValidateFile("test.tf")
+	noSchemaErr := &NoSchemaError{}
+	if !errors.As(err, &noSchemaErr) {
+		t.Fatal("expected NoSchemaError for no schema")
+	}
+}
+
+func TestDecoder_ValidateFile_valuesFile(t *testing.T) {
+	valuesSchema := schema.NewValuesFileSchema(schema.ValueDefinitions{
+		"name": {
+			Type:        cty.String,
+			Description: lang.PlainText("Name of the instance"),
+		},
+		"instance_count": {
+			Type: cty.Number,
+		},
+		"tags": {
+			Type: cty.Map(cty.String),
+		},
+		"anything": {},
+	})
+
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"valid values",
+			`name = "foo"
+instance_count = 2
+tags = {
+  env = "prod"
+}
+anything = [1, "two"]
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"undeclared value",
+			`name = "foo"
+unknown = 42
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected attribute",
+					Detail:   `An attribute named "unknown" is not expected here`,
+					Subject: &hcl.Range{
+						Filename: "test.tfvars",
+						Start:    hcl.Pos{Line: 2, Column: 1, Byte: 13},
+						End:      hcl.Pos{Line: 2, Column: 8, Byte: 20},
+					},
+				},
+			},
+		},
+		{
+			"mismatching types",
+			`instance_count = "many"
+tags = ["foo"]
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid value type",
+					Detail:   `Value of "instance_count" must be number, string given`,
+					Subject: &hcl.Range{
+						Filename: "test.tfvars",
+						Start:    hcl.Pos{Line: 1, Column: 18, Byte: 17},
+						End:      hcl.Pos{Line: 1, Column: 24, Byte: 23},
+					},
+				},
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid value type",
+					Detail:   `Value of "tags" must be map of string, tuple given`,
+					Subject: &hcl.Range{
+						Filename: "test.tfvars",
+						Start:    hcl.Pos{Line: 2, Column: 8, Byte: 31},
+						End:      hcl.Pos{Line: 2, Column: 15, Byte: 38},
+					},
+				},
+			},
+		},
+		{
+			"unexpected block",
+			`name = "foo"
+tags {
+}
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unexpected block",
+					Detail:   `Blocks of type "tags" are not expected here`,
+					Subject: &hcl.Range{
+						Filename: "test.tfvars",
+						Start:    hcl.Pos{Line: 2, Column: 1, Byte: 13},
+						End:      hcl.Pos{Line: 2, Column: 5, Byte: 17},
+					},
+				},
+			},
+		},
+		{
+			"value referring to unknown context",
+			`name = upper("foo")
+instance_count = var.count
+`,
+			hcl.Diagnostics{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(valuesSchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tfvars", hcl.InitialPos)
+			err := d.LoadFile("test.tfvars", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, err := d.ValidateFile("test.tfvars")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_valuesFile(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(schema.NewValuesFileSchema(schema.ValueDefinitions{
+		"name": {
+			Type:        cty.String,
+			Description: lang.PlainText("Name of the instance"),
+		},
+		"instance_count": {
+			Type: cty.Number,
+		},
+		"password": {
+			Type:        cty.String,
+			IsSensitive: true,
+		},
+	}))
+
+	f, _ := hclsyntax.ParseConfig([]byte(`name = "foo"
+`), "test.tfvars", hcl.InitialPos)
+	err := d.LoadFile("test.tfvars", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pos := hcl.Pos{Line: 2, Column: 1, Byte: 13}
+	candidates, err := d.CandidatesAtPos("test.tfvars", pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := hcl.Range{
+		Filename: "test.tfvars",
+		Start:    pos,
+		End:      pos,
+	}
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:  "instance_count",
+			Detail: "optional, number",
+			Kind:   lang.AttributeCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: "instance_count",
+				Snippet: "instance_count = ${1:1}",
+				Range:   rng,
+			},
+		},
+		{
+			Label:  "password",
+			Detail: "optional, sensitive, string",
+			Kind:   lang.AttributeCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: "password",
+				Snippet: `password = "${1:value}"`,
+				Range:   rng,
+			},
+		},
+	})
+
+	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_ValidateFile_readsTargetsOnce(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"count": {
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.Number},
+				},
+			},
+			"name": {
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.Number},
+				},
+			},
+		},
+	}
+
+	d := newTestDecoder(t, bodySchema, map[string]string{
+		"test.tf": `count = var.foo
+name = var.foo
+`,
+	})
+
+	// targets change with every read, as if swapped during validation
+	reads := 0
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		reads++
+		typ := cty.Number
+		if reads > 1 {
+			typ = cty.Bool
+		}
+		return lang.ReferenceTargets{
+			{
+				Addr: lang.Address{
+					lang.RootStep{Name: "var"},
+					lang.AttrStep{Name: "foo"},
+				},
+				Type: typ,
+			},
+		}
+	})
+
+	diags, err := d.ValidateFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(hcl.Diagnostics{}, diags); diff != "" {
+		t.Fatalf("unexpected diagnostics: %s", diff)
+	}
+	if reads != 1 {
+		t.Fatalf("expected targets to be read once, read %d times", reads)
+	}
+}
+
+func TestDecoder_ValidateFile_referenceTypes(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"count": {
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.Number},
+				},
+			},
+			"name": {
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfType: cty.String},
+					schema.LiteralTypeExpr{Type: cty.String},
+				},
+			},
+			"any": {
+				Expr: schema.ExprConstraints{
+					schema.TraversalExpr{OfScopeId: lang.ScopeId("variable")},
+				},
+			},
+		},
+	}
+	refTargets := lang.ReferenceTargets{
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "num"},
+			},
+			Type: cty.Number,
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "str"},
+			},
+			Type: cty.String,
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "obj"},
+			},
+			Type: cty.Object(map[string]cty.Type{
+				"list": cty.List(cty.String),
+			}),
+			NestedTargets: lang.ReferenceTargets{
+				{
+					Addr: lang.Address{
+						lang.RootStep{Name: "var"},
+						lang.AttrStep{Name: "obj"},
+						lang.AttrStep{Name: "list"},
+					},
+					Type: cty.List(cty.String),
+				},
+			},
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "any"},
+			},
+			Type: cty.DynamicPseudoType,
+		},
+		{
+			Addr: lang.Address{
+				lang.RootStep{Name: "var"},
+				lang.AttrStep{Name: "list"},
+			},
+			Type: cty.List(cty.Object(map[string]cty.Type{
+				"name": cty.String,
+				"size": cty.Number,
+			})),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"convertible references",
+			`count = var.num
+name = var.num
+any = var.obj
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"unknown and dynamic references",
+			`count = var.unknown
+name = var.any
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"inconvertible references",
+			`count = var.str
+name = var.obj.list
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid reference type",
+					Detail:   "var.str is string, which cannot be converted to number",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+						End:      hcl.Pos{Line: 1, Column: 16, Byte: 15},
+					},
+				},
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid reference type",
+					Detail:   "var.obj.list is list of string, which cannot be converted to string",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 2, Column: 8, Byte: 23},
+						End:      hcl.Pos{Line: 2, Column: 20, Byte: 35},
+					},
+				},
+			},
+		},
+		{
+			"references nested in expressions",
+			`count = length(var.list)
+name = "${var.list[0].size} items"
+any = var.num > 0 ? var.str : var.obj.list[0]
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"collection element references",
+			`count = var.list[0].size
+name = var.list[1].name
+`,
+			hcl.Diagnostics{},
+		},
+		{
+			"inconvertible collection element references",
+			`count = var.list[0].name
+name = var.list[*].size
+`,
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid reference type",
+					Detail:   "var.list[0].name is string, which cannot be converted to number",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+						End:      hcl.Pos{Line: 1, Column: 25, Byte: 24},
+					},
+				},
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid reference type",
+					Detail:   "var.list[*].size is list of number, which cannot be converted to string",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 2, Column: 8, Byte: 32},
+						End:      hcl.Pos{Line: 2, Column: 24, Byte: 48},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+				return refTargets
+			})
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// ValidationOptions represents options of validation
+// (see ValidateFile and its variants)
+type ValidationOptions struct {
+	// MaxDiagnosticsPerFile limits the number of diagnostics reported
+	// for a single file, such that pathological files don't flood
+	// the editor. The most severe diagnostics are kept (and then
+	// the first ones in the file) along with a warning indicating
+	// that the rest was left out. Zero means no limit.
+	MaxDiagnosticsPerFile uint
+}
+
+// SetValidationOptions sets options of validation
+func (d *Decoder) SetValidationOptions(opts ValidationOptions) {
+	d.validationOpts = opts
+}
+
+// truncateDiagnostics returns diagnostics (sorted by position)
+// limited as per MaxDiagnosticsPerFile, along with a diagnostic
+// indicating the truncation, and removes any fixes of diagnostics
+// which were left out
+func (d *Decoder) truncateDiagnostics(filename string, diags hcl.Diagnostics, fixes *lang.DiagnosticFixes) hcl.Diagnostics {
+	max := int(d.validationOpts.MaxDiagnosticsPerFile)
+	if max == 0 || len(diags) <= max {
+		return diags
+	}
+
+	bySeverity := make(hcl.Diagnostics, len(diags))
+	copy(bySeverity, diags)
+	sort.SliceStable(bySeverity, func(i, j int) bool {
+		// lower severity values are more severe (see lang.DiagHint)
+		return bySeverity[i].Severity < bySeverity[j].Severity
+	})
+
+	kept := make(map[*hcl.Diagnostic]bool, max)
+	for _, diag := range bySeverity[:max] {
+		kept[diag] = true
+	}
+
+	truncated := make(hcl.Diagnostics, 0, max+1)
+	for _, diag := range diags {
+		if kept[diag] {
+			truncated = append(truncated, diag)
+		}
+	}
+
+	if fixes != nil {
+		keptFixes := make(lang.DiagnosticFixes, 0, len(*fixes))
+		for _, fix := range *fixes {
+			if kept[fix.Diagnostic] {
+				keptFixes = append(keptFixes, fix)
+			}
+		}
+		*fixes = keptFixes
+	}
+
+	rng := hcl.Range{
+		Filename: filename,
+		Start:    hcl.InitialPos,
+		End:      hcl.InitialPos,
+	}
+	return append(truncated, d.newDiagnostic(CodeTooManyDiagnostics, &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  "Too many diagnostics",
+		Detail: fmt.Sprintf("Only %d of %d diagnostics are reported for this file",
+			max, len(diags)),
+		Subject: rng.Ptr(),
+	}))
+}
@@ -0,0 +1,130 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_ValidateFileWithFixes_maxDiagnosticsPerFile(t *testing.T) {
+	cfg := `retries = 5
+a = "one"
+b = "two"
+c = "three"
+`
+	testCases := []struct {
+		name          string
+		max           uint
+		expectedDiags []string
+		expectedFixes int
+	}{
+		{
+			"no limit",
+			0,
+			[]string{
+				"1,1: Redundant default value",
+				"2,5: Invalid value type",
+				"3,5: Invalid value type",
+				"4,5: Invalid value type",
+			},
+			1,
+		},
+		{
+			"limit not reached",
+			4,
+			[]string{
+				"1,1: Redundant default value",
+				"2,5: Invalid value type",
+				"3,5: Invalid value type",
+				"4,5: Invalid value type",
+			},
+			1,
+		},
+		{
+			"most severe kept",
+			2,
+			[]string{
+				"2,5: Invalid value type",
+				"3,5: Invalid value type",
+				"1,1: Too many diagnostics",
+			},
+			0,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"retries": {
+						IsOptional:   true,
+						Expr:         schema.LiteralTypeOnly(cty.Number),
+						DefaultValue: cty.NumberIntVal(5),
+					},
+					"a": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.Number)},
+					"b": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.Number)},
+					"c": {IsOptional: true, Expr: schema.LiteralTypeOnly(cty.Number)},
+				},
+			})
+			d.SetFeatures(Features{RedundantDefaultHints: true})
+			d.SetValidationOptions(ValidationOptions{MaxDiagnosticsPerFile: tc.max})
+
+			f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diags, fixes, err := d.ValidateFileWithFixes("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			given := make([]string, len(diags))
+			for i, diag := range diags {
+				given[i] = fmt.Sprintf("%d,%d: %s", diag.Subject.Start.Line, diag.Subject.Start.Column, diag.Summary)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, given); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+			if len(fixes) != tc.expectedFixes {
+				t.Fatalf("expected %d fixes, given: %#v", tc.expectedFixes, fixes)
+			}
+		})
+	}
+}
+
+func TestDecoder_ValidateFileWithRelatedInfo_tooManyDiagnosticsCode(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(schema.NewBodySchema())
+	d.SetValidationOptions(ValidationOptions{MaxDiagnosticsPerFile: 1})
+
+	cfg := `foo = 1
+bar = 2
+`
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := d.ValidateFileWithRelatedInfo("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := make([]lang.DiagnosticCode, len(diags))
+	for i, diag := range diags {
+		codes[i] = diag.Code
+	}
+	expectedCodes := []lang.DiagnosticCode{CodeUnexpectedAttribute, CodeTooManyDiagnostics}
+	if diff := cmp.Diff(expectedCodes, codes); diff != "" {
+		t.Fatalf("unexpected codes: %s", diff)
+	}
+}
@@ -0,0 +1,19 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// valueFormat represents options of rendering values as text,
+// shared by completion candidates (snippets) and hover
+type valueFormat struct {
+	objectItemSep lang.ObjectItemSeparator
+	number        lang.NumberFormat
+}
+
+func (d *Decoder) valueFormat() valueFormat {
+	return valueFormat{
+		objectItemSep: d.objectItemSep,
+		number:        d.numberFormat,
+	}
+}
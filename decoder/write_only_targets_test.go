@@ -0,0 +1,191 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var writeOnlySchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"credentials": {
+			Labels: []*schema.LabelSchema{
+				{Name: "name"},
+			},
+			Address: &schema.BlockAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.StaticStep{Name: "credentials"},
+					schema.LabelStep{Index: 0},
+				},
+				ScopeId:    lang.ScopeId("credentials"),
+				BodyAsData: true,
+				InferBody:  true,
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"username": {
+						IsOptional: true,
+						Expr:       schema.LiteralTypeOnly(cty.String),
+					},
+					"password": {
+						IsOptional:  true,
+						IsWriteOnly: true,
+						Expr:        schema.LiteralTypeOnly(cty.String),
+					},
+				},
+			},
+		},
+	},
+	Attributes: map[string]*schema.AttributeSchema{
+		"token": {
+			IsOptional:  true,
+			IsWriteOnly: true,
+			Expr:        schema.LiteralTypeOnly(cty.String),
+			Address: &schema.AttributeAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.StaticStep{Name: "token"},
+				},
+				AsExprType: true,
+			},
+		},
+		"output": {
+			IsOptional: true,
+			Expr: schema.ExprConstraints{
+				schema.TraversalExpr{OfType: cty.String},
+			},
+		},
+		"secret": {
+			IsOptional:  true,
+			IsWriteOnly: true,
+			Expr: schema.ExprConstraints{
+				schema.TraversalExpr{OfType: cty.String},
+			},
+		},
+	},
+}
+
+const writeOnlyCfg = `credentials "db" {
+  username = "admin"
+  password = "foo"
+}
+token = "bar"
+`
+
+func TestCollectReferenceTargets_writeOnly(t *testing.T) {
+	d := newTestDecoder(t, writeOnlySchema, map[string]string{
+		"credentials.tf": writeOnlyCfg,
+		"test.tf":        "",
+	})
+
+	targets, err := d.CollectReferenceTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeOnlyAddrs := make(map[string]bool, 0)
+	ReferenceTargets(targets).DeepWalk(func(target lang.ReferenceTarget) error {
+		writeOnlyAddrs[target.Addr.String()] = target.WriteOnly
+		return nil
+	})
+
+	expectedAddrs := map[string]bool{
+		"credentials.db":          false,
+		"credentials.db.password": true,
+		"credentials.db.username": false,
+		"token":                   true,
+	}
+	if diff := cmp.Diff(expectedAddrs, writeOnlyAddrs); diff != "" {
+		t.Fatalf("unexpected write-only targets: %s", diff)
+	}
+}
+
+func TestDecoder_HoverAtPos_writeOnly(t *testing.T) {
+	d := newTestDecoder(t, writeOnlySchema, map[string]string{
+		"credentials.tf": writeOnlyCfg,
+		"test.tf":        "secret = token\n",
+	})
+
+	data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedContent := lang.Markdown("`token`\n_string_\n\n" +
+		"**Note:** This value is write-only and can only be referenced from write-only attributes.")
+	if diff := cmp.Diff(expectedContent, data.Content); diff != "" {
+		t.Fatalf("unexpected hover content: %s", diff)
+	}
+}
+
+func TestDecoder_ValidateFile_writeOnly(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           string
+		expectedDiags hcl.Diagnostics
+	}{
+		{
+			"regular value",
+			"output = credentials.db.username\n",
+			hcl.Diagnostics{},
+		},
+		{
+			"write-only in write-only attribute",
+			"secret = token\n",
+			hcl.Diagnostics{},
+		},
+		{
+			"write-only in regular attribute",
+			"output = token\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Reference to write-only value",
+					Detail:   "token is write-only, so it can only be referenced from write-only attributes",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+					},
+				},
+			},
+		},
+		{
+			"nested write-only in regular attribute",
+			"output = credentials.db.password\n",
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Reference to write-only value",
+					Detail:   "credentials.db.password is write-only, so it can only be referenced from write-only attributes",
+					Subject: &hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						End:      hcl.Pos{Line: 1, Column: 33, Byte: 32},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := newTestDecoder(t, writeOnlySchema, map[string]string{
+				"credentials.tf": writeOnlyCfg,
+				"test.tf":        tc.cfg,
+			})
+
+			diags, err := d.ValidateFile("test.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedDiags, diags); diff != "" {
+				t.Fatalf("unexpected diagnostics: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Package decodertest provides helpers for testing schemas
+// against the decoder's behaviour via golden files.
+//
+// Results of completion, hover and validation are rendered
+// as stable human-readable text, which is compared with
+// the content of a golden file. Golden files can be (re)generated
+// by setting the environment variable named by langtest.UpdateGoldenEnvVar.
+package decodertest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/decoder"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/langtest"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+const (
+	// CursorMarker represents position of the cursor within
+	// TestCase.Config. It is removed before the config is parsed.
+	CursorMarker = "<cursor>"
+
+	// DefaultFilename is the name of the file the config
+	// is loaded as, unless TestCase.Filename is set
+	DefaultFilename = "test.tf"
+)
+
+// TestCase represents a single golden test
+type TestCase struct {
+	Name   string
+	Schema *schema.BodySchema
+
+	// Config represents content of the file. It is expected
+	// to contain exactly one CursorMarker for positional tests
+	// (completion and hover).
+	Config string
+
+	// Filename is the name of the file (DefaultFilename if empty)
+	Filename string
+
+	// GoldenFile is the path to the golden file holding
+	// the expected result. If empty, the path is derived
+	// from the test name as testdata/<test name>.golden
+	GoldenFile string
+}
+
+// NewDecoder returns a decoder with the given schema and files
+// (map of filenames to content) loaded. Reference targets
+// and origins are collected from the files.
+//
+// Parser diagnostics are ignored, as the decoder is expected
+// to handle incomplete configuration.
+func NewDecoder(t testing.TB, bodySchema *schema.BodySchema, files map[string][]byte) *decoder.Decoder {
+	t.Helper()
+
+	d := decoder.NewDecoder()
+	d.SetSchema(bodySchema)
+
+	for filename, src := range files {
+		f, _ := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+		err := d.LoadFile(filename, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	targets, err := d.CollectReferenceTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetReferenceTargetReader(func() lang.ReferenceTargets {
+		return targets
+	})
+
+	origins, err := d.CollectReferenceOrigins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetReferenceOriginReader(func() lang.ReferenceOrigins {
+		return origins
+	})
+
+	return d
+}
+
+// PosFromMarker returns the config with the (only) marker removed
+// and the position of where the marker was
+func PosFromMarker(t testing.TB, cfg, marker string) (string, hcl.Pos) {
+	t.Helper()
+
+	if strings.Count(cfg, marker) != 1 {
+		t.Fatalf("expected exactly one %q in config, %d found",
+			marker, strings.Count(cfg, marker))
+	}
+
+	offset := strings.Index(cfg, marker)
+	pos := hcl.Pos{
+		Line:   strings.Count(cfg[:offset], "\n") + 1,
+		Column: len([]rune(cfg[strings.LastIndex(cfg[:offset], "\n")+1:offset])) + 1,
+		Byte:   offset,
+	}
+
+	return strings.Replace(cfg, marker, "", 1), pos
+}
+
+// RunCandidatesTests runs completion at the cursor position
+// of each test case and compares rendered candidates
+// with the golden file
+func RunCandidatesTests(t *testing.T, testCases []TestCase) {
+	runPositionalTests(t, testCases, func(t *testing.T, d *decoder.Decoder, filename string, pos hcl.Pos) string {
+		candidates, err := d.CandidatesAtPos(filename, pos)
+		if err != nil {
+			return RenderError(err)
+		}
+		return RenderCandidates(candidates)
+	})
+}
+
+// RunHoverTests runs hover at the cursor position
+// of each test case and compares rendered hover data
+// with the golden file
+func RunHoverTests(t *testing.T, testCases []TestCase) {
+	runPositionalTests(t, testCases, func(t *testing.T, d *decoder.Decoder, filename string, pos hcl.Pos) string {
+		data, err := d.HoverAtPos(filename, pos)
+		if err != nil {
+			return RenderError(err)
+		}
+		return RenderHoverData(data)
+	})
+}
+
+// RunValidateTests validates the config of each test case
+// and compares rendered diagnostics with the golden file
+func RunValidateTests(t *testing.T, testCases []TestCase) {
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			filename := tc.filename()
+			d := NewDecoder(t, tc.Schema, map[string][]byte{
+				filename: []byte(tc.Config),
+			})
+
+			var out string
+			diags, err := d.ValidateFile(filename)
+			if err != nil {
+				out = RenderError(err)
+			} else {
+				out = RenderDiagnostics(diags)
+			}
+
+			langtest.AssertGolden(t, tc.goldenFile(t), out)
+		})
+	}
+}
+
+type positionalFunc func(t *testing.T, d *decoder.Decoder, filename string, pos hcl.Pos) string
+
+func runPositionalTests(t *testing.T, testCases []TestCase, fn positionalFunc) {
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			filename := tc.filename()
+			cfg, pos := PosFromMarker(t, tc.Config, CursorMarker)
+			d := NewDecoder(t, tc.Schema, map[string][]byte{
+				filename: []byte(cfg),
+			})
+
+			langtest.AssertGolden(t, tc.goldenFile(t), fn(t, d, filename, pos))
+		})
+	}
+}
+
+func (tc TestCase) filename() string {
+	if tc.Filename != "" {
+		return tc.Filename
+	}
+	return DefaultFilename
+}
+
+func (tc TestCase) goldenFile(t *testing.T) string {
+	if tc.GoldenFile != "" {
+		return tc.GoldenFile
+	}
+	return filepath.Join("testdata", filepath.FromSlash(t.Name())+".golden")
+}
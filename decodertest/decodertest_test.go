@@ -0,0 +1,152 @@
+package decodertest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var testSchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"name": {
+			Expr:        schema.LiteralTypeOnly(cty.String),
+			Description: lang.Markdown("Name of the thing"),
+			IsRequired:  true,
+		},
+		"count": {
+			Expr:         schema.LiteralTypeOnly(cty.Number),
+			IsDeprecated: true,
+		},
+	},
+	Blocks: map[string]*schema.BlockSchema{
+		"thing": {
+			Labels: []*schema.LabelSchema{
+				{Name: "name"},
+			},
+			Description: lang.PlainText("A thing"),
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"enabled": {Expr: schema.LiteralTypeOnly(cty.Bool)},
+				},
+			},
+		},
+	},
+}
+
+func TestRunCandidatesTests(t *testing.T) {
+	RunCandidatesTests(t, []TestCase{
+		{
+			Name:   "empty body",
+			Schema: testSchema,
+			Config: "<cursor>\n",
+		},
+		{
+			Name:   "block body",
+			Schema: testSchema,
+			Config: `thing "foo" {
+  <cursor>
+}
+`,
+		},
+		{
+			Name:   "bool value",
+			Schema: testSchema,
+			Config: `thing "foo" {
+  enabled = <cursor>
+}
+`,
+		},
+	})
+}
+
+func TestRunHoverTests(t *testing.T) {
+	RunHoverTests(t, []TestCase{
+		{
+			Name:   "attribute",
+			Schema: testSchema,
+			Config: `na<cursor>me = "foo"
+`,
+		},
+		{
+			Name:   "block",
+			Schema: testSchema,
+			Config: `th<cursor>ing "foo" {
+}
+`,
+		},
+		{
+			Name:   "nothing",
+			Schema: testSchema,
+			Config: `name = "foo"
+<cursor>
+`,
+		},
+	})
+}
+
+func TestRunValidateTests(t *testing.T) {
+	RunValidateTests(t, []TestCase{
+		{
+			Name:   "valid",
+			Schema: testSchema,
+			Config: `name = "foo"
+`,
+		},
+		{
+			Name:   "invalid",
+			Schema: testSchema,
+			Config: `count = 42
+unknown = true
+thing {
+  enabled = "yes"
+}
+`,
+		},
+	})
+}
+
+func TestPosFromMarker(t *testing.T) {
+	testCases := []struct {
+		cfg         string
+		expectedCfg string
+		expectedPos hcl.Pos
+	}{
+		{
+			"<cursor>",
+			"",
+			hcl.InitialPos,
+		},
+		{
+			"foo = <cursor>",
+			"foo = ",
+			hcl.Pos{Line: 1, Column: 7, Byte: 6},
+		},
+		{
+			"foo = \"☃\"\nbar = <cursor>\n",
+			"foo = \"☃\"\nbar = \n",
+			hcl.Pos{Line: 2, Column: 7, Byte: 18},
+		},
+		{
+			"foo = \"☃<cursor>\"",
+			"foo = \"☃\"",
+			hcl.Pos{Line: 1, Column: 9, Byte: 10},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			cfg, pos := PosFromMarker(t, tc.cfg, CursorMarker)
+			if diff := cmp.Diff(tc.expectedCfg, cfg); diff != "" {
+				t.Fatalf("unexpected config: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedPos, pos); diff != "" {
+				t.Fatalf("unexpected position: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package decodertest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// RenderCandidates renders candidates as stable human-readable text
+// (see lang.Candidates.String)
+func RenderCandidates(candidates lang.Candidates) string {
+	return candidates.String()
+}
+
+// RenderHoverData renders hover data as stable human-readable text
+func RenderHoverData(data *lang.HoverData) string {
+	if data == nil {
+		return "no hover data\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "range: %s\n", renderRange(data.Range))
+	fmt.Fprintf(&b, "kind: %s\n", data.Content.Kind)
+	b.WriteString("content:\n")
+	b.WriteString(data.Content.Value)
+	if !strings.HasSuffix(data.Content.Value, "\n") {
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// RenderDiagnostics renders diagnostics as stable human-readable text,
+// sorted by position and summary
+func RenderDiagnostics(diags hcl.Diagnostics) string {
+	sorted := make(hcl.Diagnostics, len(diags))
+	copy(sorted, diags)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iStart, jStart := diagStartByte(sorted[i]), diagStartByte(sorted[j])
+		if iStart != jStart {
+			return iStart < jStart
+		}
+		return sorted[i].Summary < sorted[j].Summary
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "diagnostics: %d\n", len(sorted))
+
+	for _, diag := range sorted {
+		b.WriteString("---\n")
+		fmt.Fprintf(&b, "%s: %s\n", renderSeverity(diag.Severity), diag.Summary)
+		if diag.Detail != "" {
+			fmt.Fprintf(&b, "detail: %s\n", diag.Detail)
+		}
+		if diag.Subject != nil {
+			fmt.Fprintf(&b, "range: %s\n", renderRange(*diag.Subject))
+		}
+	}
+
+	return b.String()
+}
+
+// RenderError renders an error returned by the decoder
+func RenderError(err error) string {
+	return fmt.Sprintf("error: %s\n", err)
+}
+
+func renderRange(rng hcl.Range) string {
+	return fmt.Sprintf("%s:%d,%d-%d,%d",
+		rng.Filename, rng.Start.Line, rng.Start.Column, rng.End.Line, rng.End.Column)
+}
+
+func renderSeverity(severity hcl.DiagnosticSeverity) string {
+	switch severity {
+	case hcl.DiagError:
+		return "error"
+	case hcl.DiagWarning:
+		return "warning"
+	}
+	return "invalid"
+}
+
+func diagStartByte(diag *hcl.Diagnostic) int {
+	if diag.Subject == nil {
+		return -1
+	}
+	return diag.Subject.Start.Byte
+}
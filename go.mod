@@ -3,6 +3,7 @@ module github.com/hashicorp/hcl-lang
 go 1.14
 
 require (
+	github.com/apparentlymart/go-textseg/v13 v13.0.0
 	github.com/google/go-cmp v0.5.6
 	github.com/hashicorp/go-multierror v1.1.1
 	github.com/hashicorp/hcl/v2 v2.10.0
@@ -0,0 +1,252 @@
+// Package indexer provides an index of reference targets and origins
+// of files loaded into a decoder, which is kept up to date in the
+// background as files change, such that queries are answered
+// from a consistent snapshot without collecting the references
+// of all files on demand.
+//
+// The index can be used as a source of targets and origins
+// for the decoder itself:
+//
+//	idx := indexer.NewIndexer(d, 100*time.Millisecond)
+//	d.SetReferenceTargetReader(idx.ReferenceTargets)
+//	d.SetReferenceOriginReader(idx.ReferenceOrigins)
+//	go idx.Run(ctx, watcher)
+package indexer
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// Collector collects reference targets and origins of a single file,
+// which is implemented by *decoder.Decoder
+type Collector interface {
+	CollectReferenceTargetsInFile(filename string) (lang.ReferenceTargets, error)
+	CollectReferenceOriginsInFile(filename string) (lang.ReferenceOrigins, error)
+}
+
+// FileWatcher notifies the indexer about changed files
+type FileWatcher interface {
+	// FileChanges returns a channel of names of files which were
+	// loaded, changed or removed, which is closed when no more
+	// changes are expected
+	FileChanges() <-chan string
+}
+
+// Snapshot represents the index at a point in time,
+// which is never modified once published
+type Snapshot struct {
+	// Revision is incremented with each update of the index
+	Revision uint64
+
+	Targets lang.ReferenceTargets
+	Origins lang.ReferenceOrigins
+}
+
+// UpdateHook represents a function which is called
+// whenever a new snapshot is published
+type UpdateHook func(snapshot Snapshot)
+
+// Indexer maintains an index of reference targets and origins
+// of files, which is updated as files change (see Run).
+//
+// Indexer is safe for concurrent use.
+type Indexer struct {
+	collector Collector
+	debounce  time.Duration
+
+	// files holds the index per file, guarded by indexMu,
+	// which also serializes updates
+	files   map[string]fileIndex
+	indexMu *sync.Mutex
+
+	snapshot   Snapshot
+	snapshotMu *sync.RWMutex
+
+	updateHook UpdateHook
+}
+
+type fileIndex struct {
+	targets lang.ReferenceTargets
+	origins lang.ReferenceOrigins
+}
+
+// NewIndexer creates a new Indexer collecting references
+// via the given collector, which waits for changes to settle
+// for the debounce duration before updating the index
+func NewIndexer(collector Collector, debounce time.Duration) *Indexer {
+	return &Indexer{
+		collector:  collector,
+		debounce:   debounce,
+		files:      make(map[string]fileIndex, 0),
+		indexMu:    &sync.Mutex{},
+		snapshotMu: &sync.RWMutex{},
+		snapshot: Snapshot{
+			Targets: lang.ReferenceTargets{},
+			Origins: lang.ReferenceOrigins{},
+		},
+	}
+}
+
+// SetUpdateHook sets a hook which is called (from the goroutine
+// updating the index) whenever a new snapshot is published
+func (idx *Indexer) SetUpdateHook(f UpdateHook) {
+	idx.indexMu.Lock()
+	defer idx.indexMu.Unlock()
+	idx.updateHook = f
+}
+
+// Snapshot returns the latest published snapshot of the index
+func (idx *Indexer) Snapshot() Snapshot {
+	idx.snapshotMu.RLock()
+	defer idx.snapshotMu.RUnlock()
+	return idx.snapshot
+}
+
+// ReferenceTargets returns targets of the latest snapshot,
+// which makes it usable as decoder.ReferenceTargetReader
+func (idx *Indexer) ReferenceTargets() lang.ReferenceTargets {
+	return idx.Snapshot().Targets
+}
+
+// ReferenceOrigins returns origins of the latest snapshot,
+// which makes it usable as decoder.ReferenceOriginReader
+func (idx *Indexer) ReferenceOrigins() lang.ReferenceOrigins {
+	return idx.Snapshot().Origins
+}
+
+// Run updates the index as files change, until the context
+// is cancelled or the channel of changes is closed.
+//
+// Changes are collected until none arrives for the debounce
+// duration, after which only the changed files are indexed again
+// and a new snapshot is published. Any pending changes are indexed
+// before returning when the channel is closed, but not when the context
+// is cancelled, in which case the context error is returned.
+func (idx *Indexer) Run(ctx context.Context, watcher FileWatcher) error {
+	changes := watcher.FileChanges()
+	pending := make(map[string]bool, 0)
+
+	timer := time.NewTimer(idx.debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case filename, ok := <-changes:
+			if !ok {
+				return idx.Reindex(ctx, sortedFilenames(pending)...)
+			}
+			pending[filename] = true
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(idx.debounce)
+		case <-timer.C:
+			err := idx.Reindex(ctx, sortedFilenames(pending)...)
+			if err != nil {
+				return err
+			}
+			pending = make(map[string]bool, 0)
+		}
+	}
+}
+
+// Reindex indexes the given files again and publishes a new snapshot,
+// e.g. to index all files when they are first loaded.
+//
+// Files which cannot be collected (e.g. as they were removed)
+// are removed from the index. If the context is cancelled,
+// the index is left unchanged and the context error is returned.
+func (idx *Indexer) Reindex(ctx context.Context, filenames ...string) error {
+	if len(filenames) == 0 {
+		return nil
+	}
+
+	idx.indexMu.Lock()
+	defer idx.indexMu.Unlock()
+
+	updated := make(map[string]*fileIndex, len(filenames))
+	for _, filename := range filenames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		targets, err := idx.collector.CollectReferenceTargetsInFile(filename)
+		if err != nil {
+			updated[filename] = nil
+			continue
+		}
+		origins, err := idx.collector.CollectReferenceOriginsInFile(filename)
+		if err != nil {
+			updated[filename] = nil
+			continue
+		}
+		updated[filename] = &fileIndex{
+			targets: targets,
+			origins: origins,
+		}
+	}
+
+	for filename, fi := range updated {
+		if fi == nil {
+			delete(idx.files, filename)
+			continue
+		}
+		idx.files[filename] = *fi
+	}
+
+	snapshot := idx.newSnapshot()
+
+	idx.snapshotMu.Lock()
+	idx.snapshot = snapshot
+	idx.snapshotMu.Unlock()
+
+	if idx.updateHook != nil {
+		idx.updateHook(snapshot)
+	}
+
+	return nil
+}
+
+// newSnapshot combines the index of all files (in order of their names),
+// assuming the lock of the index is held
+func (idx *Indexer) newSnapshot() Snapshot {
+	filenames := make([]string, 0, len(idx.files))
+	for filename := range idx.files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	snapshot := Snapshot{
+		Revision: idx.Snapshot().Revision + 1,
+		Targets:  make(lang.ReferenceTargets, 0),
+		Origins:  make(lang.ReferenceOrigins, 0),
+	}
+	for _, filename := range filenames {
+		snapshot.Targets = append(snapshot.Targets, idx.files[filename].targets...)
+		snapshot.Origins = append(snapshot.Origins, idx.files[filename].origins...)
+	}
+
+	return snapshot
+}
+
+func sortedFilenames(filenames map[string]bool) []string {
+	sorted := make([]string, 0, len(filenames))
+	for filename := range filenames {
+		sorted = append(sorted, filename)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
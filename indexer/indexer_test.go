@@ -0,0 +1,258 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/decoder"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var testSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"locals": {
+			Body: &schema.BodySchema{
+				AnyAttribute: &schema.AttributeSchema{
+					Expr: schema.ExprConstraints{
+						schema.TraversalExpr{OfType: cty.DynamicPseudoType},
+						schema.LiteralTypeExpr{Type: cty.DynamicPseudoType},
+					},
+					Address: &schema.AttributeAddrSchema{
+						Steps: []schema.AddrStep{
+							schema.StaticStep{Name: "local"},
+							schema.AttrNameStep{},
+						},
+						AsReference: true,
+					},
+				},
+			},
+		},
+	},
+}
+
+type testWatcher chan string
+
+func (w testWatcher) FileChanges() <-chan string {
+	return w
+}
+
+func newTestDecoder() *decoder.Decoder {
+	d := decoder.NewDecoder()
+	d.SetSchema(testSchema)
+	return d
+}
+
+func loadFile(t *testing.T, d *decoder.Decoder, filename, cfg string) {
+	t.Helper()
+	f, _ := hclsyntax.ParseConfig([]byte(cfg), filename, hcl.InitialPos)
+	err := d.LoadFile(filename, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func targetAddrs(targets lang.ReferenceTargets) []string {
+	addrs := make([]string, 0)
+	for _, target := range targets {
+		addrs = append(addrs, target.Addr.String())
+	}
+	return addrs
+}
+
+func originAddrs(origins lang.ReferenceOrigins) []string {
+	addrs := make([]string, 0)
+	for _, origin := range origins {
+		addrs = append(addrs, origin.Addr.String())
+	}
+	return addrs
+}
+
+func TestIndexer_Reindex(t *testing.T) {
+	d := newTestDecoder()
+	loadFile(t, d, "b.tf", `locals {
+  bar = local.foo
+}
+`)
+	loadFile(t, d, "a.tf", `locals {
+  foo = "foo"
+}
+`)
+
+	idx := NewIndexer(d, 0)
+	err := idx.Reindex(context.Background(), "a.tf", "b.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := idx.Snapshot()
+	if snapshot.Revision != 1 {
+		t.Fatalf("expected revision 1, given: %d", snapshot.Revision)
+	}
+	if diff := cmp.Diff([]string{"local.foo", "local.bar"}, targetAddrs(snapshot.Targets)); diff != "" {
+		t.Fatalf("unexpected targets: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"local.foo"}, originAddrs(snapshot.Origins)); diff != "" {
+		t.Fatalf("unexpected origins: %s", diff)
+	}
+
+	// only the changed file is collected again
+	loadFile(t, d, "b.tf", `locals {
+  baz = local.foo
+}
+`)
+	err = idx.Reindex(context.Background(), "b.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot = idx.Snapshot()
+	if diff := cmp.Diff([]string{"local.foo", "local.baz"}, targetAddrs(snapshot.Targets)); diff != "" {
+		t.Fatalf("unexpected targets: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"local.foo"}, targetAddrs(idx.ReferenceTargets()[:1])); diff != "" {
+		t.Fatalf("unexpected targets: %s", diff)
+	}
+}
+
+type removedFilesCollector struct {
+	*decoder.Decoder
+	removed map[string]bool
+}
+
+func (c *removedFilesCollector) CollectReferenceTargetsInFile(filename string) (lang.ReferenceTargets, error) {
+	if c.removed[filename] {
+		return nil, &decoder.FileNotFoundError{Filename: filename}
+	}
+	return c.Decoder.CollectReferenceTargetsInFile(filename)
+}
+
+func TestIndexer_Reindex_removedFile(t *testing.T) {
+	d := newTestDecoder()
+	loadFile(t, d, "a.tf", `locals {
+  foo = "foo"
+}
+`)
+	loadFile(t, d, "b.tf", `locals {
+  bar = "bar"
+}
+`)
+	c := &removedFilesCollector{Decoder: d, removed: map[string]bool{}}
+
+	idx := NewIndexer(c, 0)
+	err := idx.Reindex(context.Background(), "a.tf", "b.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.removed["a.tf"] = true
+	err = idx.Reindex(context.Background(), "a.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"local.bar"}, targetAddrs(idx.ReferenceTargets())); diff != "" {
+		t.Fatalf("unexpected targets: %s", diff)
+	}
+}
+
+func TestIndexer_Reindex_cancelled(t *testing.T) {
+	d := newTestDecoder()
+	loadFile(t, d, "a.tf", `locals {
+  foo = "foo"
+}
+`)
+
+	idx := NewIndexer(d, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := idx.Reindex(ctx, "a.tf")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, given: %v", err)
+	}
+	if snapshot := idx.Snapshot(); snapshot.Revision != 0 || len(snapshot.Targets) != 0 {
+		t.Fatalf("expected empty snapshot, given: %#v", snapshot)
+	}
+}
+
+func TestIndexer_Run(t *testing.T) {
+	d := newTestDecoder()
+	idx := NewIndexer(d, 200*time.Millisecond)
+
+	snapshots := make(chan Snapshot, 10)
+	idx.SetUpdateHook(func(snapshot Snapshot) {
+		snapshots <- snapshot
+	})
+
+	watcher := make(testWatcher)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- idx.Run(ctx, watcher)
+	}()
+
+	// changes in quick succession result in a single update
+	for i := 0; i < 3; i++ {
+		filename := fmt.Sprintf("%d.tf", i)
+		loadFile(t, d, filename, fmt.Sprintf(`locals {
+  foo_%d = "foo"
+}
+`, i))
+		watcher <- filename
+	}
+
+	select {
+	case snapshot := <-snapshots:
+		expectedAddrs := []string{"local.foo_0", "local.foo_1", "local.foo_2"}
+		if diff := cmp.Diff(expectedAddrs, targetAddrs(snapshot.Targets)); diff != "" {
+			t.Fatalf("unexpected targets: %s", diff)
+		}
+		if snapshot.Revision != 1 {
+			t.Fatalf("expected revision 1, given: %d", snapshot.Revision)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, given: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+	if len(snapshots) > 0 {
+		t.Fatalf("expected single update, given %d more", len(snapshots))
+	}
+}
+
+func TestIndexer_Run_closedWatcher(t *testing.T) {
+	d := newTestDecoder()
+	loadFile(t, d, "a.tf", `locals {
+  foo = "foo"
+}
+`)
+	idx := NewIndexer(d, time.Hour)
+
+	watcher := make(testWatcher, 1)
+	watcher <- "a.tf"
+	close(watcher)
+
+	// pending changes are indexed without waiting for the debounce
+	err := idx.Run(context.Background(), watcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"local.foo"}, targetAddrs(idx.ReferenceTargets())); diff != "" {
+		t.Fatalf("unexpected targets: %s", diff)
+	}
+}
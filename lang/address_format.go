@@ -0,0 +1,101 @@
+package lang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// AddressFormat describes how addresses are rendered
+// as text, e.g. in completion candidates or hover.
+//
+// Unlike Address.String(), which is meant for comparison
+// and sorting, formatted addresses are always valid traversals
+// in the native syntax: attribute steps with names which are
+// not valid identifiers are rendered as index steps
+// and string keys are quoted and escaped as HCL string literals.
+type AddressFormat struct {
+	// EscapeNonASCII escapes any non-ASCII characters in keys
+	// as \uNNNN or \UNNNNNNNN instead of rendering them as-is,
+	// e.g. for clients with limited Unicode support. Attribute steps
+	// with non-ASCII names are rendered as index steps to allow that.
+	// Non-printable characters are always escaped.
+	EscapeNonASCII bool
+}
+
+// Format renders the address as text according to the format
+func (f AddressFormat) Format(addr Address) string {
+	var b strings.Builder
+	for _, s := range addr {
+		b.WriteString(f.formatStep(s))
+	}
+	return b.String()
+}
+
+func (f AddressFormat) formatStep(step AddressStep) string {
+	switch s := step.(type) {
+	case AttrStep:
+		if !hclsyntax.ValidIdentifier(s.Name) || (f.EscapeNonASCII && !isASCII(s.Name)) {
+			return fmt.Sprintf("[%s]", f.Quote(s.Name))
+		}
+	case IndexStep:
+		if s.Key.Type() == cty.String && s.Key.IsKnown() && !s.Key.IsNull() {
+			return fmt.Sprintf("[%s]", f.Quote(s.Key.AsString()))
+		}
+	}
+	return step.String()
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// Quote renders the given string as a quoted HCL string literal,
+// escaping characters as necessary
+func (f AddressFormat) Quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$', '%':
+			// avoid interpretation as template sequence
+			b.WriteRune(r)
+			if i+1 < len(runes) && runes[i+1] == '{' {
+				b.WriteRune(r)
+			}
+		default:
+			if !unicode.IsPrint(r) || (f.EscapeNonASCII && r > unicode.MaxASCII) {
+				if r > 0xFFFF {
+					fmt.Fprintf(&b, `\U%08X`, r)
+				} else {
+					fmt.Fprintf(&b, `\u%04X`, r)
+				}
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+
+	b.WriteByte('"')
+	return b.String()
+}
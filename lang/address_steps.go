@@ -79,6 +79,18 @@ func (IndexStep) isRefStepImpl() addrStepSigil {
 	return addrStepSigil{}
 }
 
+// SplatStep represents the splat operator ([*]) which
+// applies any subsequent steps to each element of a collection
+type SplatStep struct{}
+
+func (SplatStep) String() string {
+	return "[*]"
+}
+
+func (SplatStep) isRefStepImpl() addrStepSigil {
+	return addrStepSigil{}
+}
+
 func TraversalToAddress(traversal hcl.Traversal) (Address, error) {
 	addr := Address{}
 	for _, tr := range traversal {
@@ -1,6 +1,9 @@
 package lang
 
 import (
+	"encoding/json"
+	"sort"
+
 	"github.com/hashicorp/hcl/v2"
 )
 
@@ -23,6 +26,10 @@ const (
 	StringCandidateKind
 	TupleCandidateKind
 	TraversalCandidateKind
+	FunctionCandidateKind
+
+	// TemplateCandidateKind represents content of a whole file
+	TemplateCandidateKind
 )
 
 //go:generate stringer -type=CandidateKind -output=candidate_kind_string.go
@@ -39,9 +46,64 @@ type Candidate struct {
 	AdditionalTextEdits []TextEdit
 	Kind                CandidateKind
 
-	// TriggerSuggest allows server to instruct the client whether
-	// to reopen candidate suggestion popup after insertion
-	TriggerSuggest bool
+	// LabelDetails optionally represents details rendered
+	// next to the label, such as parameters of a function
+	LabelDetails *LabelDetails
+
+	// Command represents an optional command for the client
+	// to execute after insertion, such as reopening
+	// the candidate suggestion popup (see TriggerSuggestCommand)
+	Command *Command
+
+	// Score represents relevance of the candidate, where candidates
+	// with a higher score are more relevant, e.g. references to targets
+	// declared nearby. Zero represents no particular relevance.
+	Score float64
+
+	// FilterText represents text which the client should use
+	// to filter candidates instead of Label, if not empty,
+	// e.g. for candidates matching the typed prefix
+	// only with a tolerance of typos.
+	FilterText string
+
+	// SchemaPath optionally represents path to the element
+	// of the schema which the candidate was produced for
+	// (see decoder.Features.SchemaPaths)
+	SchemaPath SchemaPath
+}
+
+// LabelDetails represents details of a candidate rendered
+// inconspicuously next to its label
+type LabelDetails struct {
+	// Detail is rendered directly after the label,
+	// e.g. parameters of a function, such as (str string)
+	Detail string
+
+	// Description is rendered further away from the label,
+	// e.g. the type of the value returned by a function
+	Description string
+}
+
+// Command represents a client-side command to be executed
+// after a candidate is inserted
+type Command struct {
+	// Name is the identifier of the command as known to the client,
+	// e.g. editor.action.triggerSuggest
+	Name string
+
+	// Arguments represents JSON-encoded arguments for the command
+	Arguments []json.RawMessage
+}
+
+const TriggerSuggestCommandName = "editor.action.triggerSuggest"
+
+// TriggerSuggestCommand returns a command which instructs the client
+// to reopen candidate suggestion popup after insertion
+func TriggerSuggestCommand() *Command {
+	return &Command{
+		Name:      TriggerSuggestCommandName,
+		Arguments: []json.RawMessage{},
+	}
 }
 
 // TextEdit represents a change (edit) of an HCL config file
@@ -62,9 +124,47 @@ type TextEdit struct {
 //
 // Decoder has an upper limit for the number of candidates it can return
 // and when the limit is reached, the list is considered incomplete.
+//
+// Candidates returned by the decoder are always sorted (see Sort).
 type Candidates struct {
 	List       []Candidate
 	IsComplete bool
+
+	// Groups optionally represents the candidates as ordered groups
+	// (sections), in which case List is empty. See Flatten for
+	// turning the groups into a single list.
+	Groups []CandidateGroup
+}
+
+// Sort sorts the list of candidates in place, in a deterministic
+// order, i.e. the same candidates always end up in the same order
+// regardless of the order in which they were collected.
+//
+// Candidates are ordered by Score (highest first), then by Label,
+// Kind, Detail and finally by the text of TextEdit (NewText and Snippet).
+func (c Candidates) Sort() {
+	sort.SliceStable(c.List, func(i, j int) bool {
+		return c.List[i].less(c.List[j])
+	})
+}
+
+func (c Candidate) less(other Candidate) bool {
+	if c.Score != other.Score {
+		return c.Score > other.Score
+	}
+	if c.Label != other.Label {
+		return c.Label < other.Label
+	}
+	if c.Kind != other.Kind {
+		return c.Kind < other.Kind
+	}
+	if c.Detail != other.Detail {
+		return c.Detail < other.Detail
+	}
+	if c.TextEdit.NewText != other.TextEdit.NewText {
+		return c.TextEdit.NewText < other.TextEdit.NewText
+	}
+	return c.TextEdit.Snippet < other.TextEdit.Snippet
 }
 
 // NewCandidates creates a new (incomplete) list of candidates
@@ -0,0 +1,46 @@
+package lang
+
+// CandidateGroup represents a named section of candidates,
+// such as references or literal values, which clients
+// may render with a separator or collapse
+type CandidateGroup struct {
+	Name string
+	List []Candidate
+
+	// IsComplete indicates whether the group contains
+	// all of its candidates (see Candidates.IsComplete)
+	IsComplete bool
+}
+
+// Flatten returns candidates of all groups as a single list
+// in order of the groups, e.g. for clients which don't support
+// groups. Candidates without any groups are returned as they are.
+//
+// The list is complete only if all of the groups are complete.
+func (c Candidates) Flatten() Candidates {
+	if c.Groups == nil {
+		return c
+	}
+
+	flat := Candidates{
+		List:       make([]Candidate, 0),
+		IsComplete: c.IsComplete,
+	}
+	for _, group := range c.Groups {
+		flat.List = append(flat.List, group.List...)
+		if !group.IsComplete {
+			flat.IsComplete = false
+		}
+	}
+
+	return flat
+}
+
+// Len returns the number of candidates, including those of any groups
+func (c Candidates) Len() int {
+	n := len(c.List)
+	for _, group := range c.Groups {
+		n += len(group.List)
+	}
+	return n
+}
@@ -0,0 +1,96 @@
+package lang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// String renders candidates as stable human-readable text,
+// suitable for snapshot (golden) comparisons in tests.
+//
+// Fields which are empty are omitted, so that adding a field
+// to Candidate doesn't change rendering of existing candidates.
+func (c Candidates) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "complete: %t\n", c.IsComplete)
+	fmt.Fprintf(&b, "candidates: %d\n", c.Len())
+
+	for _, candidate := range c.List {
+		b.WriteString("---\n")
+		b.WriteString(candidate.String())
+	}
+	for _, group := range c.Groups {
+		fmt.Fprintf(&b, "=== group: %q (complete: %t)\n", group.Name, group.IsComplete)
+		for _, candidate := range group.List {
+			b.WriteString("---\n")
+			b.WriteString(candidate.String())
+		}
+	}
+
+	return b.String()
+}
+
+// GoString renders candidates the same way as String, such that
+// candidates printed via %#v (e.g. in test failures) remain readable.
+func (c Candidates) GoString() string {
+	return c.String()
+}
+
+// String renders the candidate as stable human-readable text
+func (c Candidate) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "label: %q\n", c.Label)
+	fmt.Fprintf(&b, "kind: %s\n", c.Kind)
+	if c.Detail != "" {
+		fmt.Fprintf(&b, "detail: %q\n", c.Detail)
+	}
+	if c.LabelDetails != nil {
+		fmt.Fprintf(&b, "label details: %q %q\n", c.LabelDetails.Detail, c.LabelDetails.Description)
+	}
+	if c.Description.Value != "" {
+		fmt.Fprintf(&b, "description (%s): %q\n", c.Description.Kind, c.Description.Value)
+	}
+	if c.IsDeprecated {
+		b.WriteString("deprecated: true\n")
+	}
+	if c.Score != 0 {
+		fmt.Fprintf(&b, "score: %g\n", c.Score)
+	}
+	if c.FilterText != "" {
+		fmt.Fprintf(&b, "filter text: %q\n", c.FilterText)
+	}
+	if len(c.SchemaPath) > 0 {
+		fmt.Fprintf(&b, "schema path: %s\n", c.SchemaPath)
+	}
+	fmt.Fprintf(&b, "edit: %s\n", c.TextEdit)
+	for _, edit := range c.AdditionalTextEdits {
+		fmt.Fprintf(&b, "additional edit: %s\n", edit)
+	}
+	if c.Command != nil {
+		fmt.Fprintf(&b, "command: %s", c.Command.Name)
+		for _, arg := range c.Command.Arguments {
+			fmt.Fprintf(&b, " %s", arg)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// String renders the edit as its range followed by the new text
+// (and the snippet, if it differs from the new text)
+func (te TextEdit) String() string {
+	if te.NewText == te.Snippet {
+		return fmt.Sprintf("%s %q", rangeString(te.Range), te.NewText)
+	}
+	return fmt.Sprintf("%s %q (snippet %q)", rangeString(te.Range), te.NewText, te.Snippet)
+}
+
+func rangeString(rng hcl.Range) string {
+	return fmt.Sprintf("%s:%d,%d-%d,%d",
+		rng.Filename, rng.Start.Line, rng.Start.Column, rng.End.Line, rng.End.Column)
+}
@@ -0,0 +1,12 @@
+package lang
+
+// CodeAction represents a change of the configuration which
+// is not tied to any diagnostic, such as a refactoring
+// which servers can offer as a code action.
+type CodeAction struct {
+	// Title is a human-readable description of the action
+	Title string
+
+	// Edits represent changes of the file which perform the action
+	Edits []TextEdit
+}
@@ -0,0 +1,19 @@
+package lang
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Definition represents location of a definition outside of
+// the configuration (e.g. docs or a generated schema file)
+// of a construct in the configuration, such as a label.
+type Definition struct {
+	// OriginRange represents range of the construct in the configuration
+	OriginRange hcl.Range
+
+	// URI represents the document containing the definition
+	URI string
+	// Range represents range of the definition within the document,
+	// which is zero if the whole document is the definition
+	Range hcl.Range
+}
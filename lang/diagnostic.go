@@ -0,0 +1,67 @@
+package lang
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// DiagHint represents severity of diagnostics which merely point out
+// something which may be a mistake, such as an unknown attribute
+// in a body which allows them.
+//
+// HCL itself doesn't define such severity, so these diagnostics
+// are only produced on request and callers are expected
+// to translate them, e.g. to the hint severity in LSP.
+const DiagHint hcl.DiagnosticSeverity = hcl.DiagWarning + 1
+
+// Diagnostic represents a diagnostic along with any locations
+// related to it, which servers can translate e.g. to related
+// information of the diagnostic (DiagnosticRelatedInformation) in LSP.
+type Diagnostic struct {
+	*hcl.Diagnostic
+
+	// Code represents the kind of the diagnostic, if known
+	Code DiagnosticCode
+
+	// CodeDescriptionURL optionally represents a link
+	// to a description of the code, such as docs
+	CodeDescriptionURL string
+
+	// RelatedInfo represents locations related to the diagnostic,
+	// such as the definition of a referenced target
+	RelatedInfo []DiagnosticRelatedInfo
+
+	// SchemaPath optionally represents path to the element
+	// of the schema which the diagnostic relates to
+	SchemaPath SchemaPath
+}
+
+// DiagnosticCode represents a stable machine-readable code
+// of a kind of diagnostics (e.g. HCLLANG001), which users
+// can use to look up or suppress specific findings
+// and servers can translate e.g. to code of the diagnostic in LSP.
+type DiagnosticCode string
+
+// DiagnosticRelatedInfo represents a location related to a diagnostic
+type DiagnosticRelatedInfo struct {
+	// Range represents the location within the configuration,
+	// unless URI is set
+	Range hcl.Range
+
+	// URI optionally represents a location outside of the configuration,
+	// such as a link to docs of the body
+	URI string
+
+	// Message describes how the location relates to the diagnostic
+	Message string
+}
+
+type Diagnostics []Diagnostic
+
+// HCL returns the diagnostics without any codes or related information
+func (diags Diagnostics) HCL() hcl.Diagnostics {
+	hclDiags := make(hcl.Diagnostics, len(diags))
+	for i, diag := range diags {
+		hclDiags[i] = diag.Diagnostic
+	}
+	return hclDiags
+}
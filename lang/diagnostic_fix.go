@@ -0,0 +1,31 @@
+package lang
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// DiagnosticFix represents a suggested fix of a diagnostic,
+// which servers can offer e.g. as a quick fix code action.
+type DiagnosticFix struct {
+	// Title is a human-readable description of the fix
+	Title string
+
+	// Diagnostic is the diagnostic which the fix resolves
+	Diagnostic *hcl.Diagnostic
+
+	// Edits represent changes of the file which apply the fix
+	Edits []TextEdit
+}
+
+type DiagnosticFixes []DiagnosticFix
+
+// ForDiagnostic returns fixes which resolve the given diagnostic
+func (fixes DiagnosticFixes) ForDiagnostic(diag *hcl.Diagnostic) DiagnosticFixes {
+	matching := make(DiagnosticFixes, 0)
+	for _, fix := range fixes {
+		if fix.Diagnostic == diag {
+			matching = append(matching, fix)
+		}
+	}
+	return matching
+}
@@ -0,0 +1,21 @@
+package lang
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// InlineValue represents a statically known value of an expression,
+// such as a reference, which clients can render next to the expression,
+// e.g. as ghost text previewing what the expression evaluates to.
+type InlineValue struct {
+	// Range is the range of the expression
+	Range hcl.Range
+
+	// Value is the value which the expression evaluates to
+	Value cty.Value
+
+	// Text is the value rendered on a single line in the native syntax,
+	// shortened if it would be too long to render inline
+	Text string
+}
@@ -0,0 +1,89 @@
+package lang
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// NumberFormat describes how numbers are rendered as text,
+// e.g. default values in completion snippets or values in hover.
+type NumberFormat struct {
+	// MaxPrecision limits the number of decimal places
+	// of non-integer numbers, with any trailing zeros removed.
+	// Zero means the shortest representation which identifies
+	// the number (as a 64-bit float) is used.
+	MaxPrecision uint
+
+	// GroupSeparator separates groups of three digits of
+	// the integer part, e.g. "," renders 1000000 as 1,000,000.
+	//
+	// The separator is only used for documentation (FormatReadable),
+	// as numbers with separators aren't valid in the configuration.
+	GroupSeparator string
+}
+
+// Format renders the number as a valid number literal
+// of the native syntax. Unknown and null values render as "".
+func (f NumberFormat) Format(val cty.Value) string {
+	if val.Type() != cty.Number || !val.IsKnown() || val.IsNull() {
+		return ""
+	}
+	return f.format(val.AsBigFloat())
+}
+
+// FormatReadable renders the number for documentation,
+// i.e. with digits grouped by GroupSeparator (if any).
+func (f NumberFormat) FormatReadable(val cty.Value) string {
+	s := f.Format(val)
+	if f.GroupSeparator == "" || s == "" {
+		return s
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(f.GroupSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	b.WriteString(fracPart)
+
+	return b.String()
+}
+
+func (f NumberFormat) format(bf *big.Float) string {
+	if bf.IsInf() {
+		// not representable as a literal, but still worth rendering
+		return bf.String()
+	}
+
+	if bf.IsInt() {
+		return bf.Text('f', 0)
+	}
+
+	if f.MaxPrecision == 0 {
+		fNum, _ := bf.Float64()
+		return strconv.FormatFloat(fNum, 'f', -1, 64)
+	}
+
+	s := bf.Text('f', int(f.MaxPrecision))
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,24 @@
+package lang
+
+// ObjectItemSeparator represents the separator between the key
+// and the value of items of object and map expressions,
+// both of which are accepted by the HCL native syntax.
+type ObjectItemSeparator uint
+
+const (
+	// EqualsObjectItemSeparator represents key = value,
+	// which is the default
+	EqualsObjectItemSeparator ObjectItemSeparator = iota
+
+	// ColonObjectItemSeparator represents key : value,
+	// as preferred e.g. by users coming from JSON
+	ColonObjectItemSeparator
+)
+
+// String returns the separator as it appears in the configuration
+func (s ObjectItemSeparator) String() string {
+	if s == ColonObjectItemSeparator {
+		return ":"
+	}
+	return "="
+}
@@ -11,6 +11,16 @@ type ReferenceOrigin struct {
 
 	OfScopeId ScopeId
 	OfType    cty.Type
+
+	// OfScopeIds represents further acceptable scopes
+	// in addition to OfScopeId, in order of priority.
+	OfScopeIds []ScopeId
+
+	// IsSynthetic indicates that the origin is not expressed
+	// as a reference in the configuration, but was contributed
+	// by ReferenceOriginsHook, e.g. an implied reference
+	// derived from a label.
+	IsSynthetic bool
 }
 
 type ReferenceOrigins []ReferenceOrigin
@@ -28,11 +38,76 @@ func (ro ReferenceOrigins) Copy() ReferenceOrigins {
 	return newOrigins
 }
 
+// Iter calls f for each origin until f returns false.
+//
+// It reports whether all origins were visited.
+func (ro ReferenceOrigins) Iter(f func(ReferenceOrigin) bool) bool {
+	for _, origin := range ro {
+		if !f(origin) {
+			return false
+		}
+	}
+	return true
+}
+
+// IterInFile calls f for each origin in the given file
+// until f returns false.
+func (ro ReferenceOrigins) IterInFile(filename string, f func(ReferenceOrigin) bool) bool {
+	return ro.Iter(func(origin ReferenceOrigin) bool {
+		if origin.Range.Filename != filename {
+			return true
+		}
+		return f(origin)
+	})
+}
+
 func (ro ReferenceOrigin) Copy() ReferenceOrigin {
-	return ReferenceOrigin{
+	newOrigin := ReferenceOrigin{
 		Addr:      ro.Addr,
 		Range:     ro.Range,
 		OfScopeId: ro.OfScopeId,
 		OfType:    ro.OfType,
+
+		IsSynthetic: ro.IsSynthetic,
+	}
+	if ro.OfScopeIds != nil {
+		newOrigin.OfScopeIds = make([]ScopeId, len(ro.OfScopeIds))
+		copy(newOrigin.OfScopeIds, ro.OfScopeIds)
+	}
+	return newOrigin
+}
+
+// ScopeIds returns all acceptable scopes of the origin
+// in order of priority, or nil if any scope is acceptable.
+func (ro ReferenceOrigin) ScopeIds() []ScopeId {
+	return ScopeIds(ro.OfScopeId, ro.OfScopeIds)
+}
+
+// ScopeIds combines the primary scope (if any) with further
+// scopes into a single list of unique scopes, in order of priority.
+func ScopeIds(scopeId ScopeId, scopeIds []ScopeId) []ScopeId {
+	if scopeId == "" && len(scopeIds) == 0 {
+		return nil
+	}
+
+	ids := make([]ScopeId, 0, len(scopeIds)+1)
+	if scopeId != "" {
+		ids = append(ids, scopeId)
+	}
+	for _, id := range scopeIds {
+		if id == "" || scopeIdsContain(ids, id) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func scopeIdsContain(ids []ScopeId, id ScopeId) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
 	}
+	return false
 }
@@ -12,10 +12,27 @@ type ReferenceTarget struct {
 	ScopeId  ScopeId
 	RangePtr *hcl.Range
 
+	// Aliases represents other (e.g. legacy) addresses by which
+	// the target can be referenced, such as where a dialect renamed
+	// a namespace. Addr is the canonical (preferred) address.
+	//
+	// References via an alias are also resolved for any
+	// (nested) targets under the aliased address.
+	Aliases []Address
+
 	Type        cty.Type
 	Name        string
 	Description MarkupContent
 
+	// Sensitive indicates that the value of the target is sensitive
+	// (e.g. a password) and should not be exposed
+	Sensitive bool
+
+	// WriteOnly indicates that the value of the target is only
+	// passed on and not retained (e.g. ephemeral), such that
+	// it can only be referenced from write-only attributes
+	WriteOnly bool
+
 	NestedTargets ReferenceTargets
 }
 
@@ -38,14 +55,64 @@ func (ref ReferenceTarget) Copy() ReferenceTarget {
 	return ReferenceTarget{
 		Addr:          ref.Addr,
 		ScopeId:       ref.ScopeId,
+		Aliases:       copyAddresses(ref.Aliases),
 		RangePtr:      copyHclRangePtr(ref.RangePtr),
 		Type:          ref.Type, // cty.Type is immutable by design
 		Name:          ref.Name,
 		Description:   ref.Description,
+		Sensitive:     ref.Sensitive,
+		WriteOnly:     ref.WriteOnly,
 		NestedTargets: ref.NestedTargets.Copy(),
 	}
 }
 
+// Iter calls f for each target, including nested targets
+// (depth-first), until f returns false.
+//
+// It reports whether all targets were visited.
+func (refs ReferenceTargets) Iter(f func(ReferenceTarget) bool) bool {
+	for _, ref := range refs {
+		if !f(ref) {
+			return false
+		}
+		if !ref.NestedTargets.Iter(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// IterInFile calls f for each target (see Iter)
+// declared in the given file, until f returns false.
+func (refs ReferenceTargets) IterInFile(filename string, f func(ReferenceTarget) bool) bool {
+	return refs.Iter(func(ref ReferenceTarget) bool {
+		if ref.RangePtr == nil || ref.RangePtr.Filename != filename {
+			return true
+		}
+		return f(ref)
+	})
+}
+
+// IterInScope calls f for each target (see Iter)
+// of the given scope, until f returns false.
+func (refs ReferenceTargets) IterInScope(scopeId ScopeId, f func(ReferenceTarget) bool) bool {
+	return refs.Iter(func(ref ReferenceTarget) bool {
+		if ref.ScopeId != scopeId {
+			return true
+		}
+		return f(ref)
+	})
+}
+
+func copyAddresses(addrs []Address) []Address {
+	if addrs == nil {
+		return nil
+	}
+	newAddrs := make([]Address, len(addrs))
+	copy(newAddrs, addrs)
+	return newAddrs
+}
+
 func copyHclRangePtr(rng *hcl.Range) *hcl.Range {
 	if rng == nil {
 		return nil
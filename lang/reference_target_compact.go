@@ -0,0 +1,190 @@
+package lang
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Compact returns a copy of the targets which takes up less memory
+// than targets built up one by one, which matters mostly for
+// long-lived targets of large workspaces (100k+ targets).
+//
+//   - repeated strings (filenames, scope IDs and names) are interned,
+//     such that e.g. targets decoded from a cache share the same strings
+//   - address steps (incl. aliases) are allocated in a single slice, with repeated
+//     root and attribute steps (e.g. of nested targets) shared
+//   - equal types (e.g. object types of many blocks of the same type)
+//     are shared, as types are immutable
+//   - ranges are allocated in a single slice, rather than one by one
+//   - nested targets (at any depth) are allocated in a single slice,
+//     each NestedTargets being a sub-slice of it
+//
+// Descriptions are shared with the original targets.
+// Appending to Addr or NestedTargets of the compacted targets
+// doesn't affect any other targets.
+//
+// Compacting is relatively expensive, so it is best done once
+// for targets which are kept around, e.g. after CollectReferenceTargets.
+func (refs ReferenceTargets) Compact() ReferenceTargets {
+	if refs == nil {
+		return nil
+	}
+
+	c := &targetCompactor{
+		strings: make(map[string]string, 0),
+		steps:   make(map[AddressStep]AddressStep, 0),
+		types:   make(map[string]cty.Type, 0),
+	}
+	c.count(refs, true)
+
+	c.addrSteps = make(Address, 0, c.stepCount)
+	c.ranges = make([]hcl.Range, 0, c.rangeCount)
+	c.nested = make(ReferenceTargets, 0, c.nestedCount)
+
+	newRefs := make(ReferenceTargets, len(refs))
+	c.compactInto(newRefs, refs)
+
+	return newRefs
+}
+
+type targetCompactor struct {
+	strings map[string]string
+	steps   map[AddressStep]AddressStep
+	types   map[string]cty.Type
+
+	stepCount   int
+	rangeCount  int
+	nestedCount int
+
+	addrSteps Address
+	ranges    []hcl.Range
+	nested    ReferenceTargets
+}
+
+func (c *targetCompactor) count(refs ReferenceTargets, isRoot bool) {
+	if !isRoot {
+		c.nestedCount += len(refs)
+	}
+	for _, ref := range refs {
+		c.stepCount += len(ref.Addr)
+		for _, alias := range ref.Aliases {
+			c.stepCount += len(alias)
+		}
+		if ref.RangePtr != nil {
+			c.rangeCount++
+		}
+		c.count(ref.NestedTargets, false)
+	}
+}
+
+func (c *targetCompactor) compactInto(dst, src ReferenceTargets) {
+	for i, ref := range src {
+		dst[i] = ReferenceTarget{
+			Addr:        c.address(ref.Addr),
+			Aliases:     c.addresses(ref.Aliases),
+			ScopeId:     ScopeId(c.intern(string(ref.ScopeId))),
+			RangePtr:    c.rangePtr(ref.RangePtr),
+			Type:        c.internType(ref.Type),
+			Name:        c.intern(ref.Name),
+			Description: ref.Description,
+			Sensitive:   ref.Sensitive,
+			WriteOnly:   ref.WriteOnly,
+		}
+
+		if ref.NestedTargets == nil {
+			continue
+		}
+
+		// reserve space for the nested targets first, such that
+		// targets of the same level are next to each other
+		start := len(c.nested)
+		end := start + len(ref.NestedTargets)
+		c.nested = c.nested[:end]
+		nested := c.nested[start:end:end]
+		c.compactInto(nested, ref.NestedTargets)
+		dst[i].NestedTargets = nested
+	}
+}
+
+func (c *targetCompactor) address(addr Address) Address {
+	if addr == nil {
+		return nil
+	}
+
+	start := len(c.addrSteps)
+	for _, step := range addr {
+		c.addrSteps = append(c.addrSteps, c.internStep(step))
+	}
+	end := len(c.addrSteps)
+
+	return c.addrSteps[start:end:end]
+}
+
+func (c *targetCompactor) addresses(addrs []Address) []Address {
+	if addrs == nil {
+		return nil
+	}
+
+	newAddrs := make([]Address, len(addrs))
+	for i, addr := range addrs {
+		newAddrs[i] = c.address(addr)
+	}
+	return newAddrs
+}
+
+// internStep returns a shared instance of root and attribute steps,
+// avoiding allocation of each step boxed in the interface
+func (c *targetCompactor) internStep(step AddressStep) AddressStep {
+	switch s := step.(type) {
+	case RootStep:
+		step = RootStep{Name: c.intern(s.Name)}
+	case AttrStep:
+		step = AttrStep{Name: c.intern(s.Name)}
+	default:
+		// e.g. index steps, which may not be comparable
+		return step
+	}
+
+	if interned, ok := c.steps[step]; ok {
+		return interned
+	}
+	c.steps[step] = step
+	return step
+}
+
+func (c *targetCompactor) internType(typ cty.Type) cty.Type {
+	if typ == cty.NilType || typ.IsPrimitiveType() || typ == cty.DynamicPseudoType {
+		// already shared
+		return typ
+	}
+
+	key := typ.GoString()
+	if interned, ok := c.types[key]; ok {
+		return interned
+	}
+	c.types[key] = typ
+	return typ
+}
+
+func (c *targetCompactor) rangePtr(rng *hcl.Range) *hcl.Range {
+	if rng == nil {
+		return nil
+	}
+
+	newRng := *rng
+	newRng.Filename = c.intern(rng.Filename)
+	c.ranges = append(c.ranges, newRng)
+
+	return &c.ranges[len(c.ranges)-1]
+}
+
+func (c *targetCompactor) intern(s string) string {
+	if s == "" {
+		return s
+	}
+	if interned, ok := c.strings[s]; ok {
+		return interned
+	}
+	c.strings[s] = s
+	return s
+}
@@ -0,0 +1,19 @@
+package lang
+
+import (
+	"strings"
+)
+
+// SchemaPath represents a machine-readable path to the element
+// of the schema which a candidate or a diagnostic relates to,
+// consisting of the type of each block (followed by any labels
+// selecting its dependent body) and the name of an attribute,
+// e.g. resource.aws_instance.network_interface.ips
+//
+// Servers can use the path e.g. to aggregate which elements
+// of the schema users interact with.
+type SchemaPath []string
+
+func (p SchemaPath) String() string {
+	return strings.Join(p, ".")
+}
@@ -46,8 +46,45 @@ const (
 	TokenModifierNil SemanticTokenModifier = iota
 	TokenModifierDependent
 	TokenModifierDeprecated
+	TokenModifierReadonly
+	TokenModifierDefaultLibrary
 )
 
 func (m SemanticTokenModifier) GoString() string {
 	return fmt.Sprintf("lang.%s", m.String())
 }
+
+// Custom (dialect-specific) token types and modifiers registered
+// with the decoder are assigned values starting from these offsets,
+// so that they never collide with the built-in ones.
+const (
+	CustomTokenTypeOffset     = 1000
+	CustomTokenModifierOffset = 1000
+)
+
+// SemanticTokensLegend represents custom (dialect-specific) token
+// types and modifiers which a server is expected to advertise
+// to the client, in addition to the built-in ones.
+//
+// Custom token type at index i of TokenTypes has the value
+// of CustomTokenTypeOffset+i, and similarly for modifiers.
+type SemanticTokensLegend struct {
+	TokenTypes     []string
+	TokenModifiers []string
+}
+
+// TokenTypeName returns name of the given custom token type
+func (l SemanticTokensLegend) TokenTypeName(t SemanticTokenType) (string, bool) {
+	if t < CustomTokenTypeOffset || int(t-CustomTokenTypeOffset) >= len(l.TokenTypes) {
+		return "", false
+	}
+	return l.TokenTypes[t-CustomTokenTypeOffset], true
+}
+
+// TokenModifierName returns name of the given custom token modifier
+func (l SemanticTokensLegend) TokenModifierName(m SemanticTokenModifier) (string, bool) {
+	if m < CustomTokenModifierOffset || int(m-CustomTokenModifierOffset) >= len(l.TokenModifiers) {
+		return "", false
+	}
+	return l.TokenModifiers[m-CustomTokenModifierOffset], true
+}
@@ -11,11 +11,13 @@ func _() {
 	_ = x[TokenModifierNil-0]
 	_ = x[TokenModifierDependent-1]
 	_ = x[TokenModifierDeprecated-2]
+	_ = x[TokenModifierReadonly-3]
+	_ = x[TokenModifierDefaultLibrary-4]
 }
 
-const _SemanticTokenModifier_name = "TokenModifierNilTokenModifierDependentTokenModifierDeprecated"
+const _SemanticTokenModifier_name = "TokenModifierNilTokenModifierDependentTokenModifierDeprecatedTokenModifierReadonlyTokenModifierDefaultLibrary"
 
-var _SemanticTokenModifier_index = [...]uint8{0, 16, 38, 61}
+var _SemanticTokenModifier_index = [...]uint8{0, 16, 38, 61, 82, 109}
 
 func (i SemanticTokenModifier) String() string {
 	if i >= SemanticTokenModifier(len(_SemanticTokenModifier_index)-1) {
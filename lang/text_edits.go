@@ -0,0 +1,106 @@
+package lang
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// MergeTextEdits returns additional edits which can be safely applied
+// alongside the given main edit, sorted by position, such that they
+// can be passed to the client (e.g. as additionalTextEdits in LSP)
+// without violating the requirement of edits not overlapping.
+//
+// Insertions at the same position are merged into a single edit
+// (in the order given), duplicate edits are removed and any edits
+// which overlap with the main edit, with other edits, which target
+// a different file or which have an invalid range are discarded.
+func MergeTextEdits(mainEdit TextEdit, edits []TextEdit) []TextEdit {
+	merged := make([]TextEdit, 0)
+
+	for _, edit := range edits {
+		if edit.Range.Filename != mainEdit.Range.Filename {
+			continue
+		}
+		if edit.Range.End.Byte < edit.Range.Start.Byte {
+			continue
+		}
+		if rangesOverlap(edit.Range, mainEdit.Range) {
+			continue
+		}
+
+		isMerged := false
+		for i, existing := range merged {
+			if existing.Range.Start.Byte == edit.Range.Start.Byte &&
+				existing.Range.End.Byte == edit.Range.End.Byte {
+				if existing.NewText == edit.NewText {
+					// duplicate edit
+					isMerged = true
+					break
+				}
+				if isInsertion(existing.Range) && isInsertion(edit.Range) {
+					if existing.Snippet != "" || edit.Snippet != "" {
+						merged[i].Snippet = snippetOf(existing) + snippetOf(edit)
+					}
+					merged[i].NewText += edit.NewText
+					isMerged = true
+					break
+				}
+			}
+			if rangesOverlap(existing.Range, edit.Range) {
+				// conflicting edit
+				isMerged = true
+				break
+			}
+		}
+		if !isMerged {
+			merged = append(merged, edit)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Range.Start.Byte < merged[j].Range.Start.Byte
+	})
+
+	return merged
+}
+
+// MergedAdditionalTextEdits returns AdditionalTextEdits of the candidate
+// which can be safely applied alongside its TextEdit (see MergeTextEdits)
+func (c Candidate) MergedAdditionalTextEdits() []TextEdit {
+	return MergeTextEdits(c.TextEdit, c.AdditionalTextEdits)
+}
+
+// snippetOf returns the snippet of the edit, or its NewText
+// escaped for use as a snippet where the edit has no snippet
+func snippetOf(edit TextEdit) string {
+	if edit.Snippet != "" {
+		return edit.Snippet
+	}
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`$`, `\$`,
+		`}`, `\}`,
+	).Replace(edit.NewText)
+}
+
+func isInsertion(rng hcl.Range) bool {
+	return rng.Start.Byte == rng.End.Byte
+}
+
+// rangesOverlap reports whether the two ranges overlap, including
+// an insertion placed strictly inside the other range.
+// Ranges which merely touch each other are not considered overlapping.
+func rangesOverlap(a, b hcl.Range) bool {
+	if isInsertion(a) && isInsertion(b) {
+		return a.Start.Byte == b.Start.Byte
+	}
+	if isInsertion(a) {
+		return a.Start.Byte > b.Start.Byte && a.Start.Byte < b.End.Byte
+	}
+	if isInsertion(b) {
+		return b.Start.Byte > a.Start.Byte && b.Start.Byte < a.End.Byte
+	}
+	return a.Start.Byte < b.End.Byte && b.Start.Byte < a.End.Byte
+}
@@ -0,0 +1,72 @@
+// Package langtest provides helpers for testing consumers of the lang
+// package, such as comparing candidates with concise snapshots
+// instead of literal lang.Candidates structs.
+package langtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// UpdateGoldenEnvVar is the name of the environment variable which,
+// when set to a non-empty value, causes AssertGolden to (over)write
+// golden files with actual results instead of comparing them
+const UpdateGoldenEnvVar = "HCL_LANG_UPDATE_GOLDEN"
+
+// AssertCandidates compares candidates with the expected snapshot,
+// i.e. text as rendered by lang.Candidates.String.
+//
+// A leading newline of the snapshot is ignored, such that the snapshot
+// can start on its own line of a raw string literal.
+func AssertCandidates(t testing.TB, expected string, candidates lang.Candidates) {
+	t.Helper()
+
+	expected = strings.TrimPrefix(expected, "\n")
+	if diff := cmp.Diff(expected, candidates.String()); diff != "" {
+		t.Fatalf("candidates mismatch: %s", diff)
+	}
+}
+
+// AssertCandidatesGolden compares candidates with the snapshot
+// in the golden file (see AssertGolden)
+func AssertCandidatesGolden(t testing.TB, goldenFile string, candidates lang.Candidates) {
+	t.Helper()
+	AssertGolden(t, goldenFile, candidates.String())
+}
+
+// AssertGolden compares actual output with the content of the golden file
+func AssertGolden(t testing.TB, goldenFile, actual string) {
+	t.Helper()
+
+	if os.Getenv(UpdateGoldenEnvVar) != "" {
+		err := os.MkdirAll(filepath.Dir(goldenFile), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(goldenFile, []byte(actual), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(goldenFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			t.Fatalf("golden file %q not found, set %s=1 to create it",
+				goldenFile, UpdateGoldenEnvVar)
+		}
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(expected), actual); diff != "" {
+		t.Fatalf("output mismatch with %q (set %s=1 to update): %s",
+			goldenFile, UpdateGoldenEnvVar, diff)
+	}
+}
@@ -0,0 +1,92 @@
+package langtest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+var testCandidates = lang.Candidates{
+	List: []lang.Candidate{
+		{
+			Label:       "name",
+			Kind:        lang.AttributeCandidateKind,
+			Detail:      "required, string",
+			Description: lang.Markdown("Name of the thing"),
+			TextEdit: lang.TextEdit{
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+					End:      hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				},
+				NewText: "name",
+				Snippet: `name = "${1:value}"`,
+			},
+		},
+		{
+			Label:        "thing",
+			Kind:         lang.BlockCandidateKind,
+			IsDeprecated: true,
+			Score:        0.5,
+			FilterText:   "thnig",
+			TextEdit: lang.TextEdit{
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+					End:      hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				},
+				NewText: "thing",
+				Snippet: "thing",
+			},
+			Command: lang.TriggerSuggestCommand(),
+		},
+	},
+	IsComplete: true,
+}
+
+func TestAssertCandidates(t *testing.T) {
+	AssertCandidates(t, `
+complete: true
+candidates: 2
+---
+label: "name"
+kind: AttributeCandidateKind
+detail: "required, string"
+description (MarkdownKind): "Name of the thing"
+edit: test.tf:1,1-1,1 "name" (snippet "name = \"${1:value}\"")
+---
+label: "thing"
+kind: BlockCandidateKind
+deprecated: true
+score: 0.5
+filter text: "thnig"
+edit: test.tf:1,1-1,1 "thing"
+command: editor.action.triggerSuggest
+`, testCandidates)
+}
+
+func TestCandidates_GoString(t *testing.T) {
+	testCases := []struct {
+		name       string
+		candidates lang.Candidates
+	}{
+		{"empty", lang.NewCandidates()},
+		{"candidates", testCandidates},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			goString := fmt.Sprintf("%#v", tc.candidates)
+			if goString != tc.candidates.String() {
+				t.Fatalf("unexpected GoString: %s", goString)
+			}
+		})
+	}
+}
+
+func TestAssertCandidatesGolden(t *testing.T) {
+	AssertCandidatesGolden(t, filepath.Join("testdata", t.Name()+".golden"), testCandidates)
+}
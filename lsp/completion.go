@@ -0,0 +1,147 @@
+package lsp
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// CompletionList converts the given candidates into a completion list.
+//
+// Groups of candidates (if any) are flattened and the order
+// in which the decoder returned candidates is preserved
+// via the sort text of items.
+func (c *Converter) CompletionList(candidates lang.Candidates) (CompletionList, error) {
+	flat := candidates.Flatten()
+
+	items := make([]CompletionItem, len(flat.List))
+	for i, candidate := range flat.List {
+		item, err := c.CompletionItem(candidate)
+		if err != nil {
+			return CompletionList{}, err
+		}
+		item.SortText = fmt.Sprintf("%05d", i)
+		items[i] = item
+	}
+
+	return CompletionList{
+		IsIncomplete: !flat.IsComplete,
+		Items:        items,
+	}, nil
+}
+
+// CompletionItem converts the given candidate into a completion item
+func (c *Converter) CompletionItem(candidate lang.Candidate) (CompletionItem, error) {
+	item := CompletionItem{
+		Label:         candidate.Label,
+		Kind:          completionItemKind(candidate.Kind),
+		Detail:        candidate.Detail,
+		Documentation: markupContent(candidate.Description),
+		Deprecated:    candidate.IsDeprecated,
+		FilterText:    candidate.FilterText,
+	}
+
+	edit, format, err := c.candidateTextEdit(candidate.TextEdit)
+	if err != nil {
+		return CompletionItem{}, err
+	}
+	item.TextEdit = &edit
+	item.InsertTextFormat = format
+
+	if len(candidate.AdditionalTextEdits) > 0 {
+		item.AdditionalTextEdits = make([]TextEdit, len(candidate.AdditionalTextEdits))
+		for i, te := range candidate.AdditionalTextEdits {
+			item.AdditionalTextEdits[i], err = c.TextEdit(te)
+			if err != nil {
+				return CompletionItem{}, err
+			}
+		}
+	}
+
+	if candidate.LabelDetails != nil {
+		item.LabelDetails = &CompletionItemLabelDetails{
+			Detail:      candidate.LabelDetails.Detail,
+			Description: candidate.LabelDetails.Description,
+		}
+	}
+
+	if candidate.Command != nil {
+		item.Command = &Command{
+			Title:     candidate.Command.Name,
+			Command:   candidate.Command.Name,
+			Arguments: candidate.Command.Arguments,
+		}
+	}
+
+	return item, nil
+}
+
+// candidateTextEdit converts the edit of a candidate, preferring
+// the snippet where it differs from the plain text
+func (c *Converter) candidateTextEdit(te lang.TextEdit) (TextEdit, InsertTextFormat, error) {
+	rng, err := c.Range(te.Range)
+	if err != nil {
+		return TextEdit{}, 0, err
+	}
+
+	if te.Snippet != "" && te.Snippet != te.NewText {
+		return TextEdit{Range: rng, NewText: te.Snippet}, SnippetTextFormat, nil
+	}
+	return TextEdit{Range: rng, NewText: te.NewText}, PlainTextTextFormat, nil
+}
+
+// TextEdit converts the given edit into a plain text edit
+func (c *Converter) TextEdit(te lang.TextEdit) (TextEdit, error) {
+	rng, err := c.Range(te.Range)
+	if err != nil {
+		return TextEdit{}, err
+	}
+	return TextEdit{
+		Range:   rng,
+		NewText: te.NewText,
+	}, nil
+}
+
+func completionItemKind(kind lang.CandidateKind) CompletionItemKind {
+	switch kind {
+	case lang.AttributeCandidateKind:
+		return PropertyCompletion
+	case lang.BlockCandidateKind:
+		return ClassCompletion
+	case lang.LabelCandidateKind:
+		return FieldCompletion
+	case lang.BoolCandidateKind:
+		return EnumMemberCompletion
+	case lang.StringCandidateKind:
+		return TextCompletion
+	case lang.NumberCandidateKind:
+		return ValueCompletion
+	case lang.KeywordCandidateKind:
+		return KeywordCompletion
+	case lang.ListCandidateKind, lang.SetCandidateKind, lang.TupleCandidateKind:
+		return FieldCompletion
+	case lang.MapCandidateKind, lang.ObjectCandidateKind:
+		return StructCompletion
+	case lang.TraversalCandidateKind:
+		return VariableCompletion
+	case lang.FunctionCandidateKind:
+		return FunctionCompletion
+	case lang.TemplateCandidateKind:
+		return SnippetCompletion
+	}
+	return TextCompletion
+}
+
+func markupContent(content lang.MarkupContent) *MarkupContent {
+	if content.Value == "" {
+		return nil
+	}
+	kind := "plaintext"
+	if content.Kind == lang.MarkdownKind {
+		kind = "markdown"
+	}
+	return &MarkupContent{
+		Kind:  kind,
+		Value: content.Value,
+	}
+}
@@ -0,0 +1,98 @@
+// Package lsp provides conversion of results of the decoder
+// (such as candidates, hover data, symbols, semantic tokens
+// and diagnostics) into structures of the Language Server Protocol,
+// with positions translated into UTF-16 code units, such that
+// servers need not duplicate the mapping.
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/position"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Files provides content of files which positions of results
+// relate to, such as *decoder.Decoder
+type Files interface {
+	File(filename string) (*hcl.File, error)
+}
+
+// FileURIFunc represents a function which returns URI of the given
+// file, as used in locations of related information of diagnostics
+type FileURIFunc func(filename string) string
+
+// Converter converts results of the decoder into LSP structures
+type Converter struct {
+	files   Files
+	fileURI FileURIFunc
+}
+
+// NewConverter creates a new Converter which reads content
+// of files from the given source, typically the decoder
+// which produced the results.
+func NewConverter(files Files) *Converter {
+	return &Converter{
+		files:   files,
+		fileURI: defaultFileURI,
+	}
+}
+
+// SetFileURIFunc sets the function which provides URIs of files.
+// Filenames are turned into file:// URIs by default.
+func (c *Converter) SetFileURIFunc(f FileURIFunc) {
+	c.fileURI = f
+}
+
+func defaultFileURI(filename string) string {
+	path := filepath.ToSlash(filename)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u := url.URL{
+		Scheme: "file",
+		Path:   path,
+	}
+	return u.String()
+}
+
+func (c *Converter) content(filename string) ([]byte, error) {
+	f, err := c.files.File(filename)
+	if err != nil {
+		return nil, err
+	}
+	return f.Bytes, nil
+}
+
+// Range converts the given range within a file into LSP range
+func (c *Converter) Range(rng hcl.Range) (Range, error) {
+	content, err := c.content(rng.Filename)
+	if err != nil {
+		return Range{}, err
+	}
+	return rangeInContent(content, rng)
+}
+
+func rangeInContent(content []byte, rng hcl.Range) (Range, error) {
+	lspRng, err := position.RangeToLSP(content, rng)
+	if err != nil {
+		return Range{}, err
+	}
+	return fromLSPRange(lspRng), nil
+}
+
+func fromLSPRange(rng position.LSPRange) Range {
+	return Range{
+		Start: fromLSPPos(rng.Start),
+		End:   fromLSPPos(rng.End),
+	}
+}
+
+func fromLSPPos(pos position.LSPPos) Position {
+	return Position{
+		Line:      uint32(pos.Line),
+		Character: uint32(pos.Character),
+	}
+}
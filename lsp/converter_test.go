@@ -0,0 +1,468 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/decoder"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestConverter_CompletionList(t *testing.T) {
+	c := testConverter(t, "test.tf", "name = \"😀\"\n")
+
+	candidates := lang.Candidates{
+		Groups: []lang.CandidateGroup{
+			{
+				Name: "Attributes",
+				List: []lang.Candidate{
+					{
+						Label:       "count",
+						Kind:        lang.AttributeCandidateKind,
+						Detail:      "optional, number",
+						Description: lang.Markdown("Number of *instances*"),
+						LabelDetails: &lang.LabelDetails{
+							Description: "number",
+						},
+						TextEdit: lang.TextEdit{
+							Range:   testRange("test.tf", 14, 14),
+							NewText: "count",
+							Snippet: "count = ${1:1}",
+						},
+						Command: lang.TriggerSuggestCommand(),
+					},
+				},
+				IsComplete: true,
+			},
+			{
+				Name: "Literals",
+				List: []lang.Candidate{
+					{
+						Label: "true",
+						Kind:  lang.BoolCandidateKind,
+						TextEdit: lang.TextEdit{
+							Range:   testRange("test.tf", 7, 13),
+							NewText: "true",
+							Snippet: "true",
+						},
+						AdditionalTextEdits: []lang.TextEdit{
+							{
+								Range:   testRange("test.tf", 0, 4),
+								NewText: "enabled",
+							},
+						},
+					},
+				},
+			},
+		},
+		IsComplete: true,
+	}
+
+	list, err := c.CompletionList(candidates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedList := CompletionList{
+		IsIncomplete: true,
+		Items: []CompletionItem{
+			{
+				Label: "count",
+				LabelDetails: &CompletionItemLabelDetails{
+					Description: "number",
+				},
+				Kind:   PropertyCompletion,
+				Detail: "optional, number",
+				Documentation: &MarkupContent{
+					Kind:  "markdown",
+					Value: "Number of *instances*",
+				},
+				SortText:         "00000",
+				InsertTextFormat: SnippetTextFormat,
+				TextEdit: &TextEdit{
+					Range: Range{
+						Start: Position{Line: 1, Character: 0},
+						End:   Position{Line: 1, Character: 0},
+					},
+					NewText: "count = ${1:1}",
+				},
+				Command: &Command{
+					Title:     "editor.action.triggerSuggest",
+					Command:   "editor.action.triggerSuggest",
+					Arguments: []json.RawMessage{},
+				},
+			},
+			{
+				Label:            "true",
+				Kind:             EnumMemberCompletion,
+				SortText:         "00001",
+				InsertTextFormat: PlainTextTextFormat,
+				TextEdit: &TextEdit{
+					Range: Range{
+						Start: Position{Line: 0, Character: 7},
+						End:   Position{Line: 0, Character: 11},
+					},
+					NewText: "true",
+				},
+				AdditionalTextEdits: []TextEdit{
+					{
+						Range: Range{
+							Start: Position{Line: 0, Character: 0},
+							End:   Position{Line: 0, Character: 4},
+						},
+						NewText: "enabled",
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedList, list); diff != "" {
+		t.Fatalf("unexpected completion list: %s", diff)
+	}
+}
+
+func TestConverter_Hover(t *testing.T) {
+	c := testConverter(t, "test.tf", "ü = 1\n")
+
+	hover, err := c.Hover(&lang.HoverData{
+		Content: lang.PlainText("**ü** _number_"),
+		Range:   testRange("test.tf", 0, 2),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedHover := &Hover{
+		Contents: MarkupContent{
+			Kind:  "plaintext",
+			Value: "**ü** _number_",
+		},
+		Range: &Range{
+			Start: Position{Line: 0, Character: 0},
+			End:   Position{Line: 0, Character: 1},
+		},
+	}
+	if diff := cmp.Diff(expectedHover, hover); diff != "" {
+		t.Fatalf("unexpected hover: %s", diff)
+	}
+
+	hover, err = c.Hover(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hover != nil {
+		t.Fatalf("expected no hover, given: %#v", hover)
+	}
+}
+
+func TestConverter_Diagnostics(t *testing.T) {
+	c := testConverter(t, "test.tf", "a = \"😀\"\nb = 1\n")
+
+	diags := lang.Diagnostics{
+		{
+			Diagnostic: &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Non-canonical reference",
+				Detail:   "Use the canonical address instead",
+				Subject:  testRange("test.tf", 4, 10).Ptr(),
+			},
+			Code:               "HCLLANG031",
+			CodeDescriptionURL: "https://example.com/HCLLANG031",
+			RelatedInfo: []lang.DiagnosticRelatedInfo{
+				{
+					Range:   testRange("test.tf", 11, 16),
+					Message: "Target defined here",
+				},
+				{
+					URI:     "https://example.com/docs",
+					Message: "Documentation",
+				},
+			},
+		},
+		{
+			Diagnostic: &hcl.Diagnostic{
+				Severity: lang.DiagHint,
+				Summary:  "Unknown attribute",
+			},
+		},
+	}
+
+	lspDiags, err := c.Diagnostics(diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDiags := []Diagnostic{
+		{
+			Range: Range{
+				Start: Position{Line: 0, Character: 4},
+				End:   Position{Line: 0, Character: 8},
+			},
+			Severity: SeverityWarning,
+			Code:     "HCLLANG031",
+			CodeDescription: &CodeDescription{
+				Href: "https://example.com/HCLLANG031",
+			},
+			Message: "Non-canonical reference: Use the canonical address instead",
+			RelatedInformation: []DiagnosticRelatedInformation{
+				{
+					Location: Location{
+						URI: "file:///test.tf",
+						Range: Range{
+							Start: Position{Line: 1, Character: 0},
+							End:   Position{Line: 1, Character: 5},
+						},
+					},
+					Message: "Target defined here",
+				},
+				{
+					Location: Location{
+						URI: "https://example.com/docs",
+					},
+					Message: "Documentation",
+				},
+			},
+		},
+		{
+			Severity: SeverityHint,
+			Message:  "Unknown attribute",
+		},
+	}
+	if diff := cmp.Diff(expectedDiags, lspDiags); diff != "" {
+		t.Fatalf("unexpected diagnostics: %s", diff)
+	}
+}
+
+func TestConverter_DocumentSymbols(t *testing.T) {
+	src := "resource \"ü\" {\n  count = 1\n  tags = {}\n}\n"
+	c := testConverter(t, "test.tf", src)
+	d := c.files.(*decoder.Decoder)
+	d.SetSchema(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type"},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"count": {Expr: schema.LiteralTypeOnly(cty.Number)},
+						"tags":  {Expr: schema.LiteralTypeOnly(cty.Map(cty.String))},
+					},
+				},
+			},
+		},
+	})
+
+	symbols, err := d.SymbolsInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docSymbols, err := c.DocumentSymbols(symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSymbols := []DocumentSymbol{
+		{
+			Name: `resource "ü"`,
+			Kind: ClassSymbol,
+			Range: Range{
+				Start: Position{Line: 0, Character: 0},
+				End:   Position{Line: 3, Character: 1},
+			},
+			SelectionRange: Range{
+				Start: Position{Line: 0, Character: 0},
+				End:   Position{Line: 3, Character: 1},
+			},
+			Children: []DocumentSymbol{
+				{
+					Name: "count",
+					Kind: NumberSymbol,
+					Range: Range{
+						Start: Position{Line: 1, Character: 2},
+						End:   Position{Line: 1, Character: 11},
+					},
+					SelectionRange: Range{
+						Start: Position{Line: 1, Character: 2},
+						End:   Position{Line: 1, Character: 11},
+					},
+				},
+				{
+					Name: "tags",
+					Kind: ObjectSymbol,
+					Range: Range{
+						Start: Position{Line: 2, Character: 2},
+						End:   Position{Line: 2, Character: 11},
+					},
+					SelectionRange: Range{
+						Start: Position{Line: 2, Character: 2},
+						End:   Position{Line: 2, Character: 11},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedSymbols, docSymbols); diff != "" {
+		t.Fatalf("unexpected symbols: %s", diff)
+	}
+}
+
+func TestConverter_SemanticTokens(t *testing.T) {
+	testCases := []struct {
+		name         string
+		src          string
+		tokens       []lang.SemanticToken
+		expectedData []uint32
+	}{
+		{
+			"single-line tokens",
+			"ü = \"😀\"\nfoo = true\n",
+			[]lang.SemanticToken{
+				{
+					Type:  lang.TokenBool,
+					Range: testRange("test.tf", 18, 22),
+				},
+				{
+					Type:  lang.TokenAttrName,
+					Range: testRange("test.tf", 0, 2),
+				},
+				{
+					Type:      lang.TokenAttrName,
+					Modifiers: []lang.SemanticTokenModifier{lang.TokenModifierDeprecated},
+					Range:     testRange("test.tf", 12, 15),
+				},
+				{
+					Type:  lang.TokenString,
+					Range: testRange("test.tf", 5, 11),
+				},
+			},
+			[]uint32{
+				0, 0, 1, 0, 0,
+				0, 4, 4, 4, 0,
+				1, 0, 3, 0, 2,
+				0, 6, 4, 3, 0,
+			},
+		},
+		{
+			"multi-line token",
+			"foo = <<EOT\n😀\r\n\nbar\nEOT\n",
+			[]lang.SemanticToken{
+				{
+					Type:  lang.TokenString,
+					Range: testRange("test.tf", 6, 26),
+				},
+			},
+			[]uint32{
+				0, 6, 5, 4, 0,
+				1, 0, 2, 4, 0,
+				2, 0, 3, 4, 0,
+				1, 0, 3, 4, 0,
+			},
+		},
+		{
+			"custom type and modifier",
+			"foo = 1\n",
+			[]lang.SemanticToken{
+				{
+					Type:      lang.CustomTokenTypeOffset,
+					Modifiers: []lang.SemanticTokenModifier{lang.CustomTokenModifierOffset + 1},
+					Range:     testRange("test.tf", 0, 3),
+				},
+				{
+					Type:  lang.CustomTokenTypeOffset + 5,
+					Range: testRange("test.tf", 6, 7),
+				},
+			},
+			[]uint32{
+				0, 0, 3, 12, 1 << 5,
+			},
+		},
+	}
+
+	custom := lang.SemanticTokensLegend{
+		TokenTypes:     []string{"foo"},
+		TokenModifiers: []string{"bar", "baz"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			c := testConverter(t, "test.tf", tc.src)
+
+			tokens, err := c.SemanticTokens("test.tf", tc.tokens, custom)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedData, tokens.Data); diff != "" {
+				t.Fatalf("unexpected data: %s", diff)
+			}
+		})
+	}
+}
+
+func TestConverter_SemanticTokensLegend(t *testing.T) {
+	c := NewConverter(decoder.NewDecoder())
+
+	legend := c.SemanticTokensLegend(lang.SemanticTokensLegend{
+		TokenTypes:     []string{"foo"},
+		TokenModifiers: []string{"bar"},
+	})
+
+	expectedLegend := SemanticTokensLegend{
+		TokenTypes: []string{
+			"hcl-attrName",
+			"hcl-blockType",
+			"hcl-blockLabel",
+			"hcl-bool",
+			"hcl-string",
+			"hcl-number",
+			"hcl-objectKey",
+			"hcl-mapKey",
+			"hcl-keyword",
+			"hcl-traversalStep",
+			"hcl-typeCapsule",
+			"hcl-typePrimitive",
+			"foo",
+		},
+		TokenModifiers: []string{
+			"hcl-dependent",
+			"deprecated",
+			"readonly",
+			"defaultLibrary",
+			"bar",
+		},
+	}
+	if diff := cmp.Diff(expectedLegend, legend); diff != "" {
+		t.Fatalf("unexpected legend: %s", diff)
+	}
+}
+
+func testConverter(t *testing.T, filename, src string) *Converter {
+	f, diags := hclsyntax.ParseConfig([]byte(src), filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	d := decoder.NewDecoder()
+	err := d.LoadFile(filename, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewConverter(d)
+}
+
+// testRange returns a range between the given byte offsets,
+// whose lines and columns are not relevant to the conversion
+func testRange(filename string, start, end int) hcl.Range {
+	return hcl.Range{
+		Filename: filename,
+		Start:    hcl.Pos{Byte: start},
+		End:      hcl.Pos{Byte: end},
+	}
+}
@@ -0,0 +1,91 @@
+package lsp
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Diagnostics converts the given diagnostics along with their codes
+// and related information, where diagnostics without subject
+// are placed at the beginning of the file.
+func (c *Converter) Diagnostics(diags lang.Diagnostics) ([]Diagnostic, error) {
+	lspDiags := make([]Diagnostic, len(diags))
+	for i, diag := range diags {
+		var err error
+		lspDiags[i], err = c.diagnostic(diag)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lspDiags, nil
+}
+
+// HCLDiagnostics converts the given HCL diagnostics (without codes
+// and related information), such as those of ValidateFile
+func (c *Converter) HCLDiagnostics(diags hcl.Diagnostics) ([]Diagnostic, error) {
+	langDiags := make(lang.Diagnostics, len(diags))
+	for i, diag := range diags {
+		langDiags[i] = lang.Diagnostic{Diagnostic: diag}
+	}
+	return c.Diagnostics(langDiags)
+}
+
+func (c *Converter) diagnostic(diag lang.Diagnostic) (Diagnostic, error) {
+	lspDiag := Diagnostic{
+		Severity: diagnosticSeverity(diag.Severity),
+		Code:     string(diag.Code),
+		Message:  diag.Summary,
+	}
+	if diag.Detail != "" {
+		lspDiag.Message = fmt.Sprintf("%s: %s", diag.Summary, diag.Detail)
+	}
+	if diag.CodeDescriptionURL != "" {
+		lspDiag.CodeDescription = &CodeDescription{
+			Href: diag.CodeDescriptionURL,
+		}
+	}
+
+	if diag.Subject != nil {
+		rng, err := c.Range(*diag.Subject)
+		if err != nil {
+			return Diagnostic{}, err
+		}
+		lspDiag.Range = rng
+	}
+
+	for _, info := range diag.RelatedInfo {
+		location := Location{
+			URI: info.URI,
+		}
+		if info.URI == "" {
+			rng, err := c.Range(info.Range)
+			if err != nil {
+				return Diagnostic{}, err
+			}
+			location = Location{
+				URI:   c.fileURI(info.Range.Filename),
+				Range: rng,
+			}
+		}
+		lspDiag.RelatedInformation = append(lspDiag.RelatedInformation, DiagnosticRelatedInformation{
+			Location: location,
+			Message:  info.Message,
+		})
+	}
+
+	return lspDiag, nil
+}
+
+func diagnosticSeverity(severity hcl.DiagnosticSeverity) DiagnosticSeverity {
+	switch severity {
+	case hcl.DiagError:
+		return SeverityError
+	case hcl.DiagWarning:
+		return SeverityWarning
+	case lang.DiagHint:
+		return SeverityHint
+	}
+	return SeverityInformation
+}
@@ -0,0 +1,30 @@
+package lsp
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// Hover converts the given hover data, where nil data
+// (i.e. nothing to show) is converted into nil hover
+func (c *Converter) Hover(data *lang.HoverData) (*Hover, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	rng, err := c.Range(data.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	hover := &Hover{
+		Contents: MarkupContent{
+			Kind:  "plaintext",
+			Value: data.Content.Value,
+		},
+		Range: &rng,
+	}
+	if content := markupContent(data.Content); content != nil {
+		hover.Contents = *content
+	}
+	return hover, nil
+}
@@ -0,0 +1,150 @@
+package lsp
+
+import (
+	"encoding/json"
+)
+
+// The types below mirror structures of the Language Server Protocol
+// (3.17) along with their JSON encoding, such that they can be sent
+// as-is or converted field by field to types of any LSP library.
+
+type Position struct {
+	Line      uint32 `json:"line"`
+	Character uint32 `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type Command struct {
+	Title     string            `json:"title"`
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+type CompletionList struct {
+	IsIncomplete bool             `json:"isIncomplete"`
+	Items        []CompletionItem `json:"items"`
+}
+
+type CompletionItem struct {
+	Label               string                      `json:"label"`
+	LabelDetails        *CompletionItemLabelDetails `json:"labelDetails,omitempty"`
+	Kind                CompletionItemKind          `json:"kind,omitempty"`
+	Detail              string                      `json:"detail,omitempty"`
+	Documentation       *MarkupContent              `json:"documentation,omitempty"`
+	Deprecated          bool                        `json:"deprecated,omitempty"`
+	SortText            string                      `json:"sortText,omitempty"`
+	FilterText          string                      `json:"filterText,omitempty"`
+	InsertTextFormat    InsertTextFormat            `json:"insertTextFormat,omitempty"`
+	TextEdit            *TextEdit                   `json:"textEdit,omitempty"`
+	AdditionalTextEdits []TextEdit                  `json:"additionalTextEdits,omitempty"`
+	Command             *Command                    `json:"command,omitempty"`
+}
+
+type CompletionItemLabelDetails struct {
+	Detail      string `json:"detail,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type CompletionItemKind uint32
+
+const (
+	TextCompletion       CompletionItemKind = 1
+	FunctionCompletion   CompletionItemKind = 3
+	FieldCompletion      CompletionItemKind = 5
+	VariableCompletion   CompletionItemKind = 6
+	ClassCompletion      CompletionItemKind = 7
+	PropertyCompletion   CompletionItemKind = 10
+	ValueCompletion      CompletionItemKind = 12
+	KeywordCompletion    CompletionItemKind = 14
+	SnippetCompletion    CompletionItemKind = 15
+	EnumMemberCompletion CompletionItemKind = 20
+	StructCompletion     CompletionItemKind = 22
+)
+
+type InsertTextFormat uint32
+
+const (
+	PlainTextTextFormat InsertTextFormat = 1
+	SnippetTextFormat   InsertTextFormat = 2
+)
+
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Kind           SymbolKind       `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+type SymbolKind uint32
+
+const (
+	ClassSymbol    SymbolKind = 5
+	PropertySymbol SymbolKind = 7
+	VariableSymbol SymbolKind = 13
+	StringSymbol   SymbolKind = 15
+	NumberSymbol   SymbolKind = 16
+	BooleanSymbol  SymbolKind = 17
+	ArraySymbol    SymbolKind = 18
+	ObjectSymbol   SymbolKind = 19
+)
+
+type SemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+type SemanticTokens struct {
+	Data []uint32 `json:"data"`
+}
+
+type Diagnostic struct {
+	Range              Range                          `json:"range"`
+	Severity           DiagnosticSeverity             `json:"severity,omitempty"`
+	Code               string                         `json:"code,omitempty"`
+	CodeDescription    *CodeDescription               `json:"codeDescription,omitempty"`
+	Message            string                         `json:"message"`
+	RelatedInformation []DiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+type DiagnosticSeverity uint32
+
+const (
+	SeverityError       DiagnosticSeverity = 1
+	SeverityWarning     DiagnosticSeverity = 2
+	SeverityInformation DiagnosticSeverity = 3
+	SeverityHint        DiagnosticSeverity = 4
+)
+
+type CodeDescription struct {
+	Href string `json:"href"`
+}
+
+type DiagnosticRelatedInformation struct {
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
+}
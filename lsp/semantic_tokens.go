@@ -0,0 +1,203 @@
+package lsp
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/position"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// builtinTokenTypes represents names of built-in token types
+// in the order in which they're advertised in the legend
+var builtinTokenTypes = []lang.SemanticTokenType{
+	lang.TokenAttrName,
+	lang.TokenBlockType,
+	lang.TokenBlockLabel,
+	lang.TokenBool,
+	lang.TokenString,
+	lang.TokenNumber,
+	lang.TokenObjectKey,
+	lang.TokenMapKey,
+	lang.TokenKeyword,
+	lang.TokenTraversalStep,
+	lang.TokenTypeCapsule,
+	lang.TokenTypePrimitive,
+}
+
+var builtinTokenTypeNames = map[lang.SemanticTokenType]string{
+	lang.TokenAttrName:      "hcl-attrName",
+	lang.TokenBlockType:     "hcl-blockType",
+	lang.TokenBlockLabel:    "hcl-blockLabel",
+	lang.TokenBool:          "hcl-bool",
+	lang.TokenString:        "hcl-string",
+	lang.TokenNumber:        "hcl-number",
+	lang.TokenObjectKey:     "hcl-objectKey",
+	lang.TokenMapKey:        "hcl-mapKey",
+	lang.TokenKeyword:       "hcl-keyword",
+	lang.TokenTraversalStep: "hcl-traversalStep",
+	lang.TokenTypeCapsule:   "hcl-typeCapsule",
+	lang.TokenTypePrimitive: "hcl-typePrimitive",
+}
+
+// builtinTokenModifiers represents names of built-in token modifiers
+// in the order in which they're advertised in the legend
+var builtinTokenModifiers = []lang.SemanticTokenModifier{
+	lang.TokenModifierDependent,
+	lang.TokenModifierDeprecated,
+	lang.TokenModifierReadonly,
+	lang.TokenModifierDefaultLibrary,
+}
+
+var builtinTokenModifierNames = map[lang.SemanticTokenModifier]string{
+	lang.TokenModifierDependent:      "hcl-dependent",
+	lang.TokenModifierDeprecated:     "deprecated",
+	lang.TokenModifierReadonly:       "readonly",
+	lang.TokenModifierDefaultLibrary: "defaultLibrary",
+}
+
+// SemanticTokensLegend returns the legend of all built-in token types
+// and modifiers followed by the given custom ones (as returned
+// by the decoder), which a server is expected to advertise
+// as part of its capabilities.
+func (c *Converter) SemanticTokensLegend(custom lang.SemanticTokensLegend) SemanticTokensLegend {
+	legend := SemanticTokensLegend{
+		TokenTypes:     make([]string, 0, len(builtinTokenTypes)+len(custom.TokenTypes)),
+		TokenModifiers: make([]string, 0, len(builtinTokenModifiers)+len(custom.TokenModifiers)),
+	}
+	for _, t := range builtinTokenTypes {
+		legend.TokenTypes = append(legend.TokenTypes, builtinTokenTypeNames[t])
+	}
+	legend.TokenTypes = append(legend.TokenTypes, custom.TokenTypes...)
+	for _, m := range builtinTokenModifiers {
+		legend.TokenModifiers = append(legend.TokenModifiers, builtinTokenModifierNames[m])
+	}
+	legend.TokenModifiers = append(legend.TokenModifiers, custom.TokenModifiers...)
+
+	return legend
+}
+
+// SemanticTokens encodes the given tokens of a file relative
+// to the legend (see SemanticTokensLegend) with the given custom
+// token types and modifiers.
+//
+// Tokens spanning multiple lines are split into a token per line,
+// as not all clients support multi-line tokens. Tokens of types
+// which aren't part of the legend are left out and so are
+// unknown modifiers.
+func (c *Converter) SemanticTokens(filename string, tokens []lang.SemanticToken, custom lang.SemanticTokensLegend) (SemanticTokens, error) {
+	content, err := c.content(filename)
+	if err != nil {
+		return SemanticTokens{}, err
+	}
+
+	sortedTokens := make([]lang.SemanticToken, len(tokens))
+	copy(sortedTokens, tokens)
+	sort.SliceStable(sortedTokens, func(i, j int) bool {
+		return sortedTokens[i].Range.Start.Byte < sortedTokens[j].Range.Start.Byte
+	})
+
+	data := make([]uint32, 0, len(tokens)*5)
+	var prevLine, prevChar uint32
+	for _, token := range sortedTokens {
+		typeIdx, ok := tokenTypeIndex(token.Type, custom)
+		if !ok {
+			continue
+		}
+		modifiers := tokenModifiersBitset(token.Modifiers, custom)
+
+		segments, err := lineSegments(content, token.Range)
+		if err != nil {
+			return SemanticTokens{}, err
+		}
+		for _, seg := range segments {
+			deltaLine := seg.Start.Line - prevLine
+			deltaStart := seg.Start.Character
+			if deltaLine == 0 {
+				deltaStart -= prevChar
+			}
+			length := seg.End.Character - seg.Start.Character
+
+			data = append(data, deltaLine, deltaStart, length, typeIdx, modifiers)
+			prevLine, prevChar = seg.Start.Line, seg.Start.Character
+		}
+	}
+
+	return SemanticTokens{Data: data}, nil
+}
+
+func tokenTypeIndex(t lang.SemanticTokenType, custom lang.SemanticTokensLegend) (uint32, bool) {
+	for i, bt := range builtinTokenTypes {
+		if bt == t {
+			return uint32(i), true
+		}
+	}
+	if t >= lang.CustomTokenTypeOffset && int(t-lang.CustomTokenTypeOffset) < len(custom.TokenTypes) {
+		return uint32(len(builtinTokenTypes)) + uint32(t-lang.CustomTokenTypeOffset), true
+	}
+	return 0, false
+}
+
+func tokenModifiersBitset(modifiers []lang.SemanticTokenModifier, custom lang.SemanticTokensLegend) uint32 {
+	var bitset uint32
+	for _, m := range modifiers {
+		for i, bm := range builtinTokenModifiers {
+			if bm == m {
+				bitset |= 1 << uint32(i)
+			}
+		}
+		if m >= lang.CustomTokenModifierOffset && int(m-lang.CustomTokenModifierOffset) < len(custom.TokenModifiers) {
+			bitset |= 1 << (uint32(len(builtinTokenModifiers)) + uint32(m-lang.CustomTokenModifierOffset))
+		}
+	}
+	return bitset
+}
+
+// lineSegments returns LSP ranges of the given range,
+// one per line which the range spans, each excluding
+// the line ending
+func lineSegments(content []byte, rng hcl.Range) ([]Range, error) {
+	lspRng, err := rangeInContent(content, rng)
+	if err != nil {
+		return nil, err
+	}
+	if lspRng.Start.Line == lspRng.End.Line {
+		return []Range{lspRng}, nil
+	}
+
+	segments := make([]Range, 0, lspRng.End.Line-lspRng.Start.Line+1)
+	start := lspRng.Start
+	offset := rng.Start.Byte
+	for line := lspRng.Start.Line; line < lspRng.End.Line; line++ {
+		eol := bytes.IndexByte(content[offset:], '\n')
+		if eol < 0 {
+			break
+		}
+		eolOffset := offset + eol
+		if eolOffset > 0 && content[eolOffset-1] == '\r' {
+			eolOffset--
+		}
+		end, err := position.PosToLSP(content, hcl.Pos{Byte: eolOffset})
+		if err != nil {
+			return nil, err
+		}
+		if end.Character > int(start.Character) {
+			segments = append(segments, Range{
+				Start: start,
+				End:   fromLSPPos(end),
+			})
+		}
+
+		offset += eol + 1
+		start = Position{Line: line + 1}
+	}
+	if lspRng.End.Character > 0 {
+		segments = append(segments, Range{
+			Start: start,
+			End:   lspRng.End,
+		})
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,77 @@
+package lsp
+
+import (
+	"github.com/hashicorp/hcl-lang/decoder"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DocumentSymbols converts the given symbols (of a single file)
+// along with their nested symbols into document symbols
+func (c *Converter) DocumentSymbols(symbols []decoder.Symbol) ([]DocumentSymbol, error) {
+	docSymbols := make([]DocumentSymbol, len(symbols))
+	for i, symbol := range symbols {
+		rng, err := c.Range(symbol.Range())
+		if err != nil {
+			return nil, err
+		}
+
+		docSymbols[i] = DocumentSymbol{
+			Name:           symbol.Name(),
+			Kind:           symbolKind(symbol),
+			Range:          rng,
+			SelectionRange: rng,
+		}
+
+		if nested := symbol.NestedSymbols(); len(nested) > 0 {
+			docSymbols[i].Children, err = c.DocumentSymbols(nested)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return docSymbols, nil
+}
+
+func symbolKind(symbol decoder.Symbol) SymbolKind {
+	switch s := symbol.(type) {
+	case *decoder.BlockSymbol:
+		return ClassSymbol
+	case *decoder.AttributeSymbol:
+		if kind, ok := exprSymbolKind(s.ExprKind); ok {
+			return kind
+		}
+		return PropertySymbol
+	case *decoder.ExprSymbol:
+		if kind, ok := exprSymbolKind(s.ExprKind); ok {
+			return kind
+		}
+		return PropertySymbol
+	}
+	return PropertySymbol
+}
+
+func exprSymbolKind(kind lang.SymbolExprKind) (SymbolKind, bool) {
+	switch k := kind.(type) {
+	case lang.LiteralTypeKind:
+		switch {
+		case k.Type == cty.Bool:
+			return BooleanSymbol, true
+		case k.Type == cty.String:
+			return StringSymbol, true
+		case k.Type == cty.Number:
+			return NumberSymbol, true
+		case k.Type.IsListType(), k.Type.IsSetType(), k.Type.IsTupleType():
+			return ArraySymbol, true
+		case k.Type.IsMapType(), k.Type.IsObjectType():
+			return ObjectSymbol, true
+		}
+	case lang.TupleConsExprKind:
+		return ArraySymbol, true
+	case lang.ObjectConsExprKind:
+		return ObjectSymbol, true
+	case lang.TraversalExprKind:
+		return VariableSymbol, true
+	}
+	return 0, false
+}
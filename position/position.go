@@ -0,0 +1,213 @@
+// Package position provides utilities for translating positions between
+// HCL and the Language Server Protocol (LSP).
+//
+// HCL positions (hcl.Pos) carry a byte offset, along with 1-indexed line
+// and column, where column is counted in characters (grapheme clusters).
+// LSP positions are 0-indexed and the character offset is counted
+// in UTF-16 code units.
+package position
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/apparentlymart/go-textseg/v13/textseg"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// LSPPos represents a position as defined by LSP,
+// i.e. 0-indexed line and character offset in UTF-16 code units
+type LSPPos struct {
+	Line      int
+	Character int
+}
+
+// LSPRange represents a range as defined by LSP
+type LSPRange struct {
+	Start LSPPos
+	End   LSPPos
+}
+
+// ByteOffsetToPos converts the given byte offset in content
+// into hcl.Pos, with column counted the same way as HCL does it.
+func ByteOffsetToPos(content []byte, offset int) (hcl.Pos, error) {
+	if offset < 0 || offset > len(content) {
+		return hcl.Pos{}, &OffsetOutOfRangeError{Offset: offset, Length: len(content)}
+	}
+
+	lineStart := lineStartOffset(content, offset)
+	line := bytes.Count(content[:lineStart], []byte{'\n'}) + 1
+
+	chars, err := textseg.TokenCount(content[lineStart:offset], textseg.ScanGraphemeClusters)
+	if err != nil {
+		return hcl.Pos{}, err
+	}
+
+	return hcl.Pos{
+		Line:   line,
+		Column: chars + 1,
+		Byte:   offset,
+	}, nil
+}
+
+// PosToLSP converts hcl.Pos into LSPPos.
+//
+// The byte offset of the position is considered authoritative,
+// line and column are recalculated from the content.
+func PosToLSP(content []byte, pos hcl.Pos) (LSPPos, error) {
+	offset := pos.Byte
+	if offset < 0 || offset > len(content) {
+		return LSPPos{}, &OffsetOutOfRangeError{Offset: offset, Length: len(content)}
+	}
+
+	lineStart := lineStartOffset(content, offset)
+
+	return LSPPos{
+		Line:      bytes.Count(content[:lineStart], []byte{'\n'}),
+		Character: utf16Len(content[lineStart:offset]),
+	}, nil
+}
+
+// LSPToPos converts LSPPos into hcl.Pos.
+//
+// Character offset beyond the end of the line is treated
+// as the end of the line, as per LSP.
+func LSPToPos(content []byte, pos LSPPos) (hcl.Pos, error) {
+	if pos.Line < 0 || pos.Character < 0 {
+		return hcl.Pos{}, &InvalidLSPPosError{Pos: pos}
+	}
+
+	lineStart := 0
+	for i := 0; i < pos.Line; i++ {
+		idx := bytes.IndexByte(content[lineStart:], '\n')
+		if idx < 0 {
+			return hcl.Pos{}, &InvalidLSPPosError{Pos: pos}
+		}
+		lineStart += idx + 1
+	}
+
+	lineEnd := len(content)
+	if idx := bytes.IndexByte(content[lineStart:], '\n'); idx >= 0 {
+		lineEnd = lineStart + idx
+	}
+	line := bytes.TrimSuffix(content[lineStart:lineEnd], []byte{'\r'})
+
+	offset := lineStart
+	units := 0
+	for len(line) > 0 && units < pos.Character {
+		r, size := utf8.DecodeRune(line)
+		units += utf16RuneLen(r)
+		if units > pos.Character {
+			// position points into the middle of a surrogate pair
+			return hcl.Pos{}, &InvalidLSPPosError{Pos: pos}
+		}
+		offset += size
+		line = line[size:]
+	}
+
+	return ByteOffsetToPos(content, offset)
+}
+
+// RangeToLSP converts hcl.Range into LSPRange
+func RangeToLSP(content []byte, rng hcl.Range) (LSPRange, error) {
+	start, err := PosToLSP(content, rng.Start)
+	if err != nil {
+		return LSPRange{}, err
+	}
+	end, err := PosToLSP(content, rng.End)
+	if err != nil {
+		return LSPRange{}, err
+	}
+
+	return LSPRange{
+		Start: start,
+		End:   end,
+	}, nil
+}
+
+// LSPToRange converts LSPRange into hcl.Range of the given file
+func LSPToRange(filename string, content []byte, rng LSPRange) (hcl.Range, error) {
+	start, err := LSPToPos(content, rng.Start)
+	if err != nil {
+		return hcl.Range{}, err
+	}
+	end, err := LSPToPos(content, rng.End)
+	if err != nil {
+		return hcl.Range{}, err
+	}
+
+	return hcl.Range{
+		Filename: filename,
+		Start:    start,
+		End:      end,
+	}, nil
+}
+
+// Prefix returns content between the start and end byte offsets,
+// excluding any trailing incomplete character (grapheme cluster),
+// such that it is safe to use e.g. for filtering completion candidates.
+//
+// Offsets are clamped to the bounds of the content.
+func Prefix(content []byte, start, end int) []byte {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(content) {
+		end = len(content)
+	}
+	if start >= end {
+		return []byte{}
+	}
+
+	offset := start
+	for offset < end {
+		adv, _, err := textseg.ScanGraphemeClusters(content[offset:], true)
+		if err != nil || adv == 0 || offset+adv > end {
+			break
+		}
+		offset += adv
+	}
+
+	return content[start:offset]
+}
+
+func lineStartOffset(content []byte, offset int) int {
+	return bytes.LastIndexByte(content[:offset], '\n') + 1
+}
+
+func utf16Len(b []byte) int {
+	units := 0
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		units += utf16RuneLen(r)
+		b = b[size:]
+	}
+	return units
+}
+
+func utf16RuneLen(r rune) int {
+	if r1, _ := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		// rune requires surrogate pair
+		return 2
+	}
+	return 1
+}
+
+type OffsetOutOfRangeError struct {
+	Offset int
+	Length int
+}
+
+func (e *OffsetOutOfRangeError) Error() string {
+	return fmt.Sprintf("offset %d is out of range (%d bytes)", e.Offset, e.Length)
+}
+
+type InvalidLSPPosError struct {
+	Pos LSPPos
+}
+
+func (e *InvalidLSPPosError) Error() string {
+	return fmt.Sprintf("invalid position %d:%d", e.Pos.Line, e.Pos.Character)
+}
@@ -0,0 +1,166 @@
+package position
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+var testContent = []byte(`attr = "foo"
+# comentário 🚀 here
+emoji = "👩‍👩‍👧 family"
+`)
+
+func TestByteOffsetToPos(t *testing.T) {
+	testCases := []struct {
+		offset      int
+		expectedPos hcl.Pos
+	}{
+		{0, hcl.Pos{Line: 1, Column: 1, Byte: 0}},
+		{12, hcl.Pos{Line: 1, Column: 13, Byte: 12}},
+		{13, hcl.Pos{Line: 2, Column: 1, Byte: 13}},
+		// after "# comentário 🚀"
+		{31, hcl.Pos{Line: 2, Column: 15, Byte: 31}},
+		// after `emoji = "👩‍👩‍👧` (one grapheme cluster)
+		{64, hcl.Pos{Line: 3, Column: 11, Byte: 64}},
+		{len(testContent), hcl.Pos{Line: 4, Column: 1, Byte: len(testContent)}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			pos, err := ByteOffsetToPos(testContent, tc.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedPos, pos); diff != "" {
+				t.Fatalf("unexpected position: %s", diff)
+			}
+		})
+	}
+}
+
+func TestByteOffsetToPos_outOfRange(t *testing.T) {
+	_, err := ByteOffsetToPos(testContent, len(testContent)+1)
+	rangeErr := &OffsetOutOfRangeError{}
+	if !errors.As(err, &rangeErr) {
+		t.Fatalf("expected OffsetOutOfRangeError, %#v given", err)
+	}
+}
+
+func TestByteOffsetToPos_matchesHCL(t *testing.T) {
+	tokens, diags := hclsyntax.LexConfig(testContent, "test.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	for _, token := range tokens {
+		for _, hclPos := range []hcl.Pos{token.Range.Start, token.Range.End} {
+			pos, err := ByteOffsetToPos(testContent, hclPos.Byte)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(hclPos, pos); diff != "" {
+				t.Fatalf("position mismatch for %s token: %s", token.Type, diff)
+			}
+		}
+	}
+}
+
+func TestPosToLSP_roundTrip(t *testing.T) {
+	testCases := []struct {
+		pos         hcl.Pos
+		expectedPos LSPPos
+	}{
+		{hcl.Pos{Line: 1, Column: 1, Byte: 0}, LSPPos{Line: 0, Character: 0}},
+		{hcl.Pos{Line: 1, Column: 8, Byte: 7}, LSPPos{Line: 0, Character: 7}},
+		// 'á' is a single UTF-16 code unit
+		{hcl.Pos{Line: 2, Column: 13, Byte: 26}, LSPPos{Line: 1, Character: 12}},
+		// '🚀' is a surrogate pair
+		{hcl.Pos{Line: 2, Column: 15, Byte: 31}, LSPPos{Line: 1, Character: 15}},
+		// family emoji is 5 runes, 8 UTF-16 code units
+		{hcl.Pos{Line: 3, Column: 11, Byte: 64}, LSPPos{Line: 2, Character: 17}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			lspPos, err := PosToLSP(testContent, tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedPos, lspPos); diff != "" {
+				t.Fatalf("unexpected LSP position: %s", diff)
+			}
+
+			pos, err := LSPToPos(testContent, lspPos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.pos, pos); diff != "" {
+				t.Fatalf("unexpected position: %s", diff)
+			}
+		})
+	}
+}
+
+func TestLSPToPos_beyondLineEnd(t *testing.T) {
+	pos, err := LSPToPos(testContent, LSPPos{Line: 0, Character: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedPos := hcl.Pos{Line: 1, Column: 13, Byte: 12}
+	if diff := cmp.Diff(expectedPos, pos); diff != "" {
+		t.Fatalf("unexpected position: %s", diff)
+	}
+}
+
+func TestLSPToPos_invalid(t *testing.T) {
+	testCases := []LSPPos{
+		{Line: 10, Character: 0},
+		{Line: -1, Character: 0},
+		// middle of surrogate pair
+		{Line: 1, Character: 14},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			_, err := LSPToPos(testContent, tc)
+			posErr := &InvalidLSPPosError{}
+			if !errors.As(err, &posErr) {
+				t.Fatalf("expected InvalidLSPPosError, %#v given", err)
+			}
+		})
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	testCases := []struct {
+		content        string
+		start, end     int
+		expectedPrefix string
+	}{
+		{"foobar", 0, 3, "foo"},
+		{"foobar", 3, 100, "bar"},
+		{"foobar", 4, 2, ""},
+		{"café", 0, 5, "café"},
+		// in the middle of 'é'
+		{"café", 0, 4, "caf"},
+		// in the middle of 'e' + combining acute accent
+		{"cafe\u0301s", 0, 4, "caf"},
+		{"cafe\u0301s", 0, 6, "cafe\u0301"},
+		// in the middle of surrogate pair
+		{"🚀🚀", 0, 6, "🚀"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			prefix := Prefix([]byte(tc.content), tc.start, tc.end)
+			if diff := cmp.Diff(tc.expectedPrefix, string(prefix)); diff != "" {
+				t.Fatalf("unexpected prefix: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package refactor
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// CodeAction represents a refactoring which servers can offer
+// as a code action, consisting of edits of one or more files
+type CodeAction struct {
+	// Title is a human-readable description of the action
+	Title string
+
+	// Edit represents changes which perform the action
+	Edit *WorkspaceEdit
+}
+
+// defaultValueName is the name of extracted values where it cannot
+// be derived from the context, which users are expected to rename
+const defaultValueName = "extracted"
+
+// ExtractValueActions returns actions extracting the expression
+// in the given range into a new named value, one for each
+// declaration site (see DeclarationSites).
+//
+// The value is named after the attribute whose whole expression
+// is extracted, if any, and suffixed with a number where the name
+// would collide with an existing reference target.
+func (p *Planner) ExtractValueActions(filename string, rng hcl.Range) ([]CodeAction, error) {
+	actions := make([]CodeAction, 0)
+
+	f, err := p.d.File(filename)
+	if err != nil {
+		return nil, err
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return actions, nil
+	}
+	if _, ok := exprTextInRange(f.Bytes, rng); !ok {
+		return actions, nil
+	}
+
+	sites, err := p.DeclarationSites()
+	if err != nil {
+		return nil, err
+	}
+	if len(sites) == 0 {
+		return actions, nil
+	}
+
+	targets, err := p.d.CollectReferenceTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	baseName, ok := attributeNameForExprRange(body, rng)
+	if !ok {
+		baseName = defaultValueName
+	}
+
+	for _, site := range sites {
+		name := uniqueValueName(targets, site, baseName)
+		edit, err := p.ExtractValueTo(filename, rng, site, name)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, CodeAction{
+			Title: fmt.Sprintf("Extract to %s %s", site.FriendlyName, site.addrForName(name)),
+			Edit:  edit,
+		})
+	}
+
+	return actions, nil
+}
+
+// uniqueValueName returns the name, suffixed with a number if necessary,
+// such that the address of the value does not collide with any target
+func uniqueValueName(targets lang.ReferenceTargets, site DeclarationSite, name string) string {
+	taken := make(map[string]bool, 0)
+	targets.Iter(func(target lang.ReferenceTarget) bool {
+		taken[target.Addr.String()] = true
+		return true
+	})
+
+	candidate := name
+	for i := 2; taken[site.addrForName(candidate).String()]; i++ {
+		candidate = fmt.Sprintf("%s_%d", name, i)
+	}
+	return candidate
+}
+
+// attributeNameForExprRange returns the name of the attribute
+// (at any nesting level) whose expression has the given range
+func attributeNameForExprRange(body *hclsyntax.Body, rng hcl.Range) (string, bool) {
+	for _, attr := range body.Attributes {
+		exprRng := attr.Expr.Range()
+		if exprRng.Start.Byte == rng.Start.Byte && exprRng.End.Byte == rng.End.Byte {
+			return attr.Name, hclsyntax.ValidIdentifier(attr.Name)
+		}
+	}
+	for _, block := range body.Blocks {
+		if !block.Range().Overlaps(rng) {
+			continue
+		}
+		if name, ok := attributeNameForExprRange(block.Body, rng); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,180 @@
+package refactor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/decoder"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func namedValuesSchema() *schema.BodySchema {
+	bodySchema := testSchema.Copy()
+	bodySchema.Blocks["locals"].NamedValues = &schema.NamedValues{
+		FriendlyName: "local value",
+	}
+	bodySchema.Blocks["variable"] = &schema.BlockSchema{
+		Labels: []*schema.LabelSchema{
+			{Name: "name"},
+		},
+		Address: &schema.BlockAddrSchema{
+			Steps: []schema.AddrStep{
+				schema.StaticStep{Name: "var"},
+				schema.LabelStep{Index: 0},
+			},
+			ScopeId:     lang.ScopeId("variable"),
+			AsReference: true,
+		},
+		Body: &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"default": {
+					IsOptional: true,
+					Expr:       schema.LiteralTypeOnly(cty.String),
+				},
+			},
+		},
+		NamedValues: &schema.NamedValues{
+			FriendlyName:  "variable",
+			ValueAttrName: "default",
+		},
+	}
+	// blocks without metadata are not considered once any block has it
+	bodySchema.Blocks["settings"] = testSchema.Blocks["locals"].Copy()
+	return bodySchema
+}
+
+func TestPlanner_ExtractValueActions(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             string
+		exprText        string
+		expectedActions map[string]string
+	}{
+		{
+			"named after attribute",
+			`resource "aws_instance" "foo" {
+  name = "foo"
+}
+`,
+			`"foo"`,
+			map[string]string{
+				"Extract to local value local.name": `resource "aws_instance" "foo" {
+  name = local.name
+}
+
+locals {
+  name = "foo"
+}
+`,
+				"Extract to variable var.name": `resource "aws_instance" "foo" {
+  name = var.name
+}
+
+variable "name" {
+  default = "foo"
+}
+`,
+			},
+		},
+		{
+			"name collision",
+			`locals {
+  name = "dev"
+}
+resource "aws_instance" "foo" {
+  name = "foo"
+}
+`,
+			`"foo"`,
+			map[string]string{
+				"Extract to local value local.name_2": `locals {
+  name = "dev"
+  name_2 = "foo"
+}
+resource "aws_instance" "foo" {
+  name = local.name_2
+}
+`,
+				"Extract to variable var.name": `locals {
+  name = "dev"
+}
+resource "aws_instance" "foo" {
+  name = var.name
+}
+
+variable "name" {
+  default = "foo"
+}
+`,
+			},
+		},
+		{
+			"part of expression",
+			`resource "aws_instance" "foo" {
+  name = "foo-${local.env}"
+}
+`,
+			`local.env`,
+			map[string]string{
+				"Extract to local value local.extracted": `resource "aws_instance" "foo" {
+  name = "foo-${local.extracted}"
+}
+
+locals {
+  extracted = local.env
+}
+`,
+				"Extract to variable var.extracted": `resource "aws_instance" "foo" {
+  name = "foo-${var.extracted}"
+}
+
+variable "extracted" {
+  default = local.env
+}
+`,
+			},
+		},
+		{
+			"invalid expression",
+			`resource "aws_instance" "foo" {
+  name = "foo"
+}
+`,
+			`= "foo`,
+			map[string]string{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := decoder.NewDecoder()
+			d.SetSchema(namedValuesSchema())
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "main.tf", hcl.InitialPos)
+			err := d.LoadFile("main.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := NewPlanner(d)
+
+			rng := rangeOf(t, "main.tf", tc.cfg, tc.exprText)
+			actions, err := p.ExtractValueActions("main.tf", rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results := make(map[string]string, 0)
+			for _, action := range actions {
+				files := applyWorkspaceEdit(map[string]string{"main.tf": tc.cfg}, action.Edit)
+				results[action.Title] = files["main.tf"]
+			}
+			if diff := cmp.Diff(tc.expectedActions, results); diff != "" {
+				t.Fatalf("unexpected actions: %s", diff)
+			}
+		})
+	}
+}
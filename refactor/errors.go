@@ -0,0 +1,48 @@
+package refactor
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+type NoDeclarationSiteError struct{}
+
+func (*NoDeclarationSiteError) Error() string {
+	return "schema declares no block for named values"
+}
+
+type InvalidNameError struct {
+	Name string
+}
+
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("%q is not a valid name", e.Name)
+}
+
+type NotDeclaredError struct {
+	Addr lang.Address
+}
+
+func (e *NotDeclaredError) Error() string {
+	return fmt.Sprintf("%s is not declared as a named value", e.Addr)
+}
+
+type UnsupportedReferenceError struct {
+	Addr  lang.Address
+	Range hcl.Range
+}
+
+func (e *UnsupportedReferenceError) Error() string {
+	return fmt.Sprintf("%s: reference %s cannot be inlined", e.Range, e.Addr)
+}
+
+type OverlappingEditsError struct {
+	Filename string
+	Range    hcl.Range
+}
+
+func (e *OverlappingEditsError) Error() string {
+	return fmt.Sprintf("%s: edit of %s overlaps with another edit", e.Filename, e.Range)
+}
@@ -0,0 +1,486 @@
+// Package refactor provides planning of refactorings which span
+// multiple files, such as renaming, extracting or inlining
+// of named values, in the form of edits applied all together.
+package refactor
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/decoder"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Planner plans refactorings of files loaded into the decoder,
+// using the decoder's schema to find out where named values
+// can be declared and how they are referenced.
+type Planner struct {
+	d *decoder.Decoder
+}
+
+func NewPlanner(d *decoder.Decoder) *Planner {
+	return &Planner{
+		d: d,
+	}
+}
+
+// DeclarationSite represents a block type in which named values
+// can be declared, such as locals in Terraform, which are then
+// addressable as AddrPrefix followed by the name of the value.
+type DeclarationSite struct {
+	BlockType  string
+	AddrPrefix lang.Address
+	ScopeId    lang.ScopeId
+
+	// FriendlyName is the name of a single value used in UI
+	FriendlyName string
+
+	// ValueAttrName is the name of the attribute holding the value
+	// of a block declaring a single value named by its label,
+	// or empty if values are declared as attributes of the block
+	// (see schema.NamedValues)
+	ValueAttrName string
+}
+
+// DeclarationSites returns block types of the schema (sorted by name)
+// which can hold declarations of named values.
+//
+// Top-level blocks marked via schema.NamedValues are returned if any.
+// Otherwise sites are inferred as top-level blocks without labels
+// with attributes of any name, addressed by their name.
+func (p *Planner) DeclarationSites() ([]DeclarationSite, error) {
+	bodySchema, err := p.d.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	sites := make([]DeclarationSite, 0)
+	for blockType, bSchema := range bodySchema.Blocks {
+		if bSchema.NamedValues == nil {
+			continue
+		}
+		site, ok := declarationSiteForBlock(blockType, bSchema)
+		if !ok {
+			continue
+		}
+		if bSchema.NamedValues.FriendlyName != "" {
+			site.FriendlyName = bSchema.NamedValues.FriendlyName
+		}
+		site.ValueAttrName = bSchema.NamedValues.ValueAttrName
+		sites = append(sites, site)
+	}
+
+	if len(sites) == 0 {
+		for blockType, bSchema := range bodySchema.Blocks {
+			if len(bSchema.Labels) > 0 {
+				continue
+			}
+			site, ok := declarationSiteForBlock(blockType, bSchema)
+			if !ok {
+				continue
+			}
+			sites = append(sites, site)
+		}
+	}
+
+	sort.Slice(sites, func(i, j int) bool {
+		return sites[i].BlockType < sites[j].BlockType
+	})
+
+	return sites, nil
+}
+
+// declarationSiteForBlock returns the site of named values declared
+// either as attributes of the block body, or by the block itself
+// where it has a single label
+func declarationSiteForBlock(blockType string, bSchema *schema.BlockSchema) (DeclarationSite, bool) {
+	var steps []schema.AddrStep
+	var scopeId lang.ScopeId
+
+	if len(bSchema.Labels) == 1 {
+		if bSchema.Address == nil || len(bSchema.Address.Steps) == 0 {
+			return DeclarationSite{}, false
+		}
+		steps = bSchema.Address.Steps
+		if _, ok := steps[len(steps)-1].(schema.LabelStep); !ok {
+			return DeclarationSite{}, false
+		}
+		scopeId = bSchema.Address.ScopeId
+	} else {
+		if len(bSchema.Labels) > 0 || bSchema.Body == nil || bSchema.Body.AnyAttribute == nil {
+			return DeclarationSite{}, false
+		}
+		addr := bSchema.Body.AnyAttribute.Address
+		if addr == nil || len(addr.Steps) < 2 {
+			return DeclarationSite{}, false
+		}
+		steps = addr.Steps
+		if _, ok := steps[len(steps)-1].(schema.AttrNameStep); !ok {
+			return DeclarationSite{}, false
+		}
+		scopeId = addr.ScopeId
+	}
+
+	prefix, ok := staticAddrPrefix(steps[:len(steps)-1])
+	if !ok {
+		return DeclarationSite{}, false
+	}
+	return DeclarationSite{
+		BlockType:    blockType,
+		AddrPrefix:   prefix,
+		ScopeId:      scopeId,
+		FriendlyName: blockType,
+	}, true
+}
+
+func staticAddrPrefix(steps []schema.AddrStep) (lang.Address, bool) {
+	addr := make(lang.Address, 0, len(steps))
+	for i, s := range steps {
+		step, ok := s.(schema.StaticStep)
+		if !ok {
+			return lang.Address{}, false
+		}
+		if i == 0 {
+			addr = append(addr, lang.RootStep{Name: step.Name})
+			continue
+		}
+		addr = append(addr, lang.AttrStep{Name: step.Name})
+	}
+	return addr, true
+}
+
+func (ds DeclarationSite) addrForName(name string) lang.Address {
+	if len(ds.AddrPrefix) == 0 {
+		return lang.Address{lang.RootStep{Name: name}}
+	}
+	return append(ds.AddrPrefix.Copy(), lang.AttrStep{Name: name})
+}
+
+// RenameTarget plans renaming of the block label at the given position
+// along with all references to the block (see decoder.RenameBlockLabelAtPos)
+func (p *Planner) RenameTarget(filename string, pos hcl.Pos, newName string) (*WorkspaceEdit, error) {
+	edits, err := p.d.RenameBlockLabelAtPos(filename, pos, newName)
+	if err != nil {
+		return nil, err
+	}
+	return newWorkspaceEdit(edits)
+}
+
+// ExtractValue plans extraction of the expression in the given range
+// into a new named value of the given name, declared in the first
+// declaration site (see DeclarationSites) and referenced in place
+// of the expression (see ExtractValueTo).
+func (p *Planner) ExtractValue(filename string, rng hcl.Range, name string) (*WorkspaceEdit, error) {
+	sites, err := p.DeclarationSites()
+	if err != nil {
+		return nil, err
+	}
+	if len(sites) == 0 {
+		return nil, &NoDeclarationSiteError{}
+	}
+	return p.ExtractValueTo(filename, rng, sites[0], name)
+}
+
+// ExtractValueTo plans extraction of the expression in the given range
+// into a new named value of the given name, declared in the given site
+// and referenced in place of the expression.
+//
+// Values declared as attributes are added to the last existing block
+// of the site within the same file. New blocks are appended to the file.
+//
+// decoder.AddressCollisionError is returned if the name is already taken.
+func (p *Planner) ExtractValueTo(filename string, rng hcl.Range, site DeclarationSite, name string) (*WorkspaceEdit, error) {
+	if !hclsyntax.ValidIdentifier(name) {
+		return nil, &InvalidNameError{Name: name}
+	}
+
+	f, err := p.d.File(filename)
+	if err != nil {
+		return nil, err
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, &decoder.UnknownFileFormatError{Filename: filename}
+	}
+
+	exprText, ok := exprTextInRange(f.Bytes, rng)
+	if !ok {
+		return nil, &decoder.PositionalError{
+			Filename: filename,
+			Pos:      rng.Start,
+			Msg:      "range does not contain a valid expression",
+		}
+	}
+
+	addr := site.addrForName(name)
+	if err := p.checkAddressCollision(addr); err != nil {
+		return nil, err
+	}
+
+	edits := []lang.TextEdit{
+		textEdit(rng, addr.String()),
+		declarationEdit(f.Bytes, body, site, name, string(exprText)),
+	}
+
+	return newWorkspaceEdit(edits)
+}
+
+// exprTextInRange returns the text of the range, provided
+// that it represents a valid expression
+func exprTextInRange(src []byte, rng hcl.Range) ([]byte, bool) {
+	if rng.End.Byte > len(src) || rng.Start.Byte > rng.End.Byte {
+		return nil, false
+	}
+	exprText := bytes.TrimSpace(rng.SliceBytes(src))
+	_, diags := hclsyntax.ParseExpression(exprText, rng.Filename, rng.Start)
+	if len(exprText) == 0 || diags.HasErrors() {
+		return nil, false
+	}
+	return exprText, true
+}
+
+func (p *Planner) checkAddressCollision(addr lang.Address) error {
+	targets, err := p.d.CollectReferenceTargets()
+	if err != nil {
+		return err
+	}
+
+	var collisionErr error
+	targets.Iter(func(target lang.ReferenceTarget) bool {
+		if target.Addr.String() == addr.String() {
+			collisionErr = &decoder.AddressCollisionError{
+				Addr:  addr,
+				Range: target.RangePtr,
+			}
+			return false
+		}
+		return true
+	})
+	return collisionErr
+}
+
+// declarationEdit returns an edit declaring the named value in the last
+// block of the given type in the body, or in a new block at the end
+func declarationEdit(src []byte, body *hclsyntax.Body, site DeclarationSite, name, exprText string) lang.TextEdit {
+	attrText := name + " = " + exprText + "\n"
+
+	var block *hclsyntax.Block
+	if site.ValueAttrName == "" {
+		for _, b := range body.Blocks {
+			if b.Type == site.BlockType {
+				block = b
+			}
+		}
+	}
+
+	if block == nil {
+		end := body.SrcRange.End
+		newText := site.BlockType + " {\n  " + attrText + "}\n"
+		if site.ValueAttrName != "" {
+			newText = fmt.Sprintf("%s %q {\n  %s = %s\n}\n", site.BlockType, name, site.ValueAttrName, exprText)
+		}
+		if len(src) > 0 {
+			newText = "\n" + newText
+			if src[len(src)-1] != '\n' {
+				newText = "\n" + newText
+			}
+		}
+		return textEdit(hcl.Range{
+			Filename: body.SrcRange.Filename,
+			Start:    end,
+			End:      end,
+		}, newText)
+	}
+
+	bracePos := block.CloseBraceRange.Start
+	lineStart := bracePos.Byte - (bracePos.Column - 1)
+	if lineStart >= 0 && len(bytes.TrimSpace(src[lineStart:bracePos.Byte])) == 0 {
+		pos := hcl.Pos{
+			Line:   bracePos.Line,
+			Column: 1,
+			Byte:   lineStart,
+		}
+		return textEdit(hcl.Range{
+			Filename: block.CloseBraceRange.Filename,
+			Start:    pos,
+			End:      pos,
+		}, "  "+attrText)
+	}
+
+	// closing brace shares the line with other content, e.g. locals {}
+	return textEdit(hcl.Range{
+		Filename: block.CloseBraceRange.Filename,
+		Start:    bracePos,
+		End:      bracePos,
+	}, "\n  "+attrText)
+}
+
+// InlineTarget plans inlining of the named value declared at the given
+// position into all references to it, across all files, along with
+// removal of the declaration.
+//
+// Only values declared as attributes (i.e. not by whole blocks)
+// can be inlined. UnsupportedReferenceError is returned if the value
+// is referenced in a way which could not be inlined, such as
+// a nested attribute.
+func (p *Planner) InlineTarget(filename string, pos hcl.Pos) (*WorkspaceEdit, error) {
+	sites, err := p.DeclarationSites()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := p.d.File(filename)
+	if err != nil {
+		return nil, err
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, &decoder.UnknownFileFormatError{Filename: filename}
+	}
+
+	site, attr, ok := declarationAtPos(body, sites, pos)
+	if !ok {
+		return nil, &decoder.PositionalError{
+			Filename: filename,
+			Pos:      pos,
+			Msg:      "no named value declaration found",
+		}
+	}
+	addr := site.addrForName(attr.Name)
+
+	target, ok, err := p.targetForAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &NotDeclaredError{Addr: addr}
+	}
+
+	allOrigins, err := p.d.CollectReferenceOrigins()
+	if err != nil {
+		return nil, err
+	}
+	origins := decoder.ReferenceOrigins(allOrigins).Targeting(target)
+
+	valueText := string(attr.Expr.Range().SliceBytes(f.Bytes))
+	if needsParens(attr.Expr) {
+		valueText = "(" + valueText + ")"
+	}
+
+	edits := make([]lang.TextEdit, 0)
+	for _, origin := range origins {
+		if origin.IsSynthetic {
+			continue
+		}
+		if len(origin.Addr) != len(addr) {
+			return nil, &UnsupportedReferenceError{
+				Addr:  origin.Addr,
+				Range: origin.Range,
+			}
+		}
+		edits = append(edits, textEdit(origin.Range, valueText))
+	}
+	edits = append(edits, textEdit(attributeLineRange(f.Bytes, attr), ""))
+
+	return newWorkspaceEdit(edits)
+}
+
+func (p *Planner) targetForAddr(addr lang.Address) (lang.ReferenceTarget, bool, error) {
+	targets, err := p.d.CollectReferenceTargets()
+	if err != nil {
+		return lang.ReferenceTarget{}, false, err
+	}
+
+	var found *lang.ReferenceTarget
+	targets.Iter(func(target lang.ReferenceTarget) bool {
+		if target.Addr.String() == addr.String() {
+			found = &target
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return lang.ReferenceTarget{}, false, nil
+	}
+	return *found, true, nil
+}
+
+// declarationAtPos returns the attribute at the given position
+// declared within a block of any of the declaration sites
+func declarationAtPos(body *hclsyntax.Body, sites []DeclarationSite, pos hcl.Pos) (DeclarationSite, *hclsyntax.Attribute, bool) {
+	for _, block := range body.Blocks {
+		if !block.Range().ContainsPos(pos) {
+			continue
+		}
+		for _, site := range sites {
+			if site.BlockType != block.Type || site.ValueAttrName != "" {
+				continue
+			}
+			for _, attr := range block.Body.Attributes {
+				if attr.Range().ContainsPos(pos) {
+					return site, attr, true
+				}
+			}
+		}
+	}
+	return DeclarationSite{}, nil, false
+}
+
+// needsParens returns true if the expression could bind differently
+// once placed into another expression, e.g. a binary operation
+func needsParens(expr hclsyntax.Expression) bool {
+	switch expr.(type) {
+	case *hclsyntax.BinaryOpExpr, *hclsyntax.UnaryOpExpr, *hclsyntax.ConditionalExpr:
+		return true
+	}
+	return false
+}
+
+// attributeLineRange returns the range of the whole line(s) of the
+// attribute, including the newline, provided that nothing else
+// is on the same line(s), or the range of the attribute otherwise
+func attributeLineRange(src []byte, attr *hclsyntax.Attribute) hcl.Range {
+	rng := attr.SrcRange
+
+	lineStart := rng.Start.Byte - (rng.Start.Column - 1)
+	if lineStart < 0 || len(bytes.TrimLeft(src[lineStart:rng.Start.Byte], " \t")) > 0 {
+		return rng
+	}
+	if rng.End.Byte < len(src) && src[rng.End.Byte] != '\n' {
+		return rng
+	}
+
+	lineRng := rng
+	lineRng.Start = hcl.Pos{
+		Line:   rng.Start.Line,
+		Column: 1,
+		Byte:   lineStart,
+	}
+	if rng.End.Byte < len(src) {
+		lineRng.End = hcl.Pos{
+			Line:   rng.End.Line + 1,
+			Column: 1,
+			Byte:   rng.End.Byte + 1,
+		}
+	}
+	return lineRng
+}
+
+func textEdit(rng hcl.Range, newText string) lang.TextEdit {
+	return lang.TextEdit{
+		Range:   rng,
+		NewText: newText,
+		Snippet: snippetReplacer.Replace(newText),
+	}
+}
+
+var snippetReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`$`, `\$`,
+	`}`, `\}`,
+)
@@ -0,0 +1,428 @@
+package refactor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/decoder"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var testSchema = &schema.BodySchema{
+	Blocks: map[string]*schema.BlockSchema{
+		"locals": {
+			Body: &schema.BodySchema{
+				AnyAttribute: &schema.AttributeSchema{
+					IsOptional: true,
+					Expr: schema.ExprConstraints{
+						schema.TraversalExpr{OfScopeId: lang.ScopeId("local")},
+						schema.LiteralTypeExpr{Type: cty.String},
+						schema.LiteralTypeExpr{Type: cty.Number},
+					},
+					Address: &schema.AttributeAddrSchema{
+						Steps: []schema.AddrStep{
+							schema.StaticStep{Name: "local"},
+							schema.AttrNameStep{},
+						},
+						ScopeId:     lang.ScopeId("local"),
+						AsReference: true,
+					},
+				},
+			},
+		},
+		"resource": {
+			Labels: []*schema.LabelSchema{
+				{Name: "type"},
+				{Name: "name"},
+			},
+			Address: &schema.BlockAddrSchema{
+				Steps: []schema.AddrStep{
+					schema.LabelStep{Index: 0},
+					schema.LabelStep{Index: 1},
+				},
+				ScopeId:     lang.ScopeId("resource"),
+				AsReference: true,
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"name": {
+						IsOptional: true,
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfScopeId: lang.ScopeId("local")},
+							schema.LiteralTypeExpr{Type: cty.String},
+						},
+					},
+					"depends_on": {
+						IsOptional: true,
+						Expr: schema.ExprConstraints{
+							schema.TraversalExpr{OfScopeId: lang.ScopeId("resource")},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func newTestPlanner(t *testing.T, files map[string]string) *Planner {
+	d := decoder.NewDecoder()
+	d.SetSchema(testSchema)
+
+	for filename, src := range files {
+		f, _ := hclsyntax.ParseConfig([]byte(src), filename, hcl.InitialPos)
+		err := d.LoadFile(filename, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return NewPlanner(d)
+}
+
+// applyWorkspaceEdit returns the files with edits applied
+func applyWorkspaceEdit(files map[string]string, we *WorkspaceEdit) map[string]string {
+	result := make(map[string]string, len(files))
+	for filename, src := range files {
+		result[filename] = src
+	}
+	for filename, edits := range we.Changes {
+		src := result[filename]
+		for i := len(edits) - 1; i >= 0; i-- {
+			edit := edits[i]
+			src = src[:edit.Range.Start.Byte] + edit.NewText + src[edit.Range.End.Byte:]
+		}
+		result[filename] = src
+	}
+	return result
+}
+
+// posOf returns the position of the first occurrence of substr
+func posOf(t *testing.T, src, substr string) hcl.Pos {
+	idx := strings.Index(src, substr)
+	if idx < 0 {
+		t.Fatalf("%q not found", substr)
+	}
+	return posAtByte(src, idx)
+}
+
+// rangeOf returns the range of the last occurrence of substr
+func rangeOf(t *testing.T, filename, src, substr string) hcl.Range {
+	idx := strings.LastIndex(src, substr)
+	if idx < 0 {
+		t.Fatalf("%q not found", substr)
+	}
+	return hcl.Range{
+		Filename: filename,
+		Start:    posAtByte(src, idx),
+		End:      posAtByte(src, idx+len(substr)),
+	}
+}
+
+func posAtByte(src string, idx int) hcl.Pos {
+	return hcl.Pos{
+		Line:   strings.Count(src[:idx], "\n") + 1,
+		Column: idx - strings.LastIndex(src[:idx], "\n"),
+		Byte:   idx,
+	}
+}
+
+func TestPlanner_DeclarationSites(t *testing.T) {
+	p := newTestPlanner(t, map[string]string{})
+
+	sites, err := p.DeclarationSites()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSites := []DeclarationSite{
+		{
+			BlockType: "locals",
+			AddrPrefix: lang.Address{
+				lang.RootStep{Name: "local"},
+			},
+			ScopeId:      lang.ScopeId("local"),
+			FriendlyName: "locals",
+		},
+	}
+	if diff := cmp.Diff(expectedSites, sites); diff != "" {
+		t.Fatalf("unexpected sites: %s", diff)
+	}
+}
+
+func TestPlanner_RenameTarget(t *testing.T) {
+	files := map[string]string{
+		"main.tf": `resource "aws_instance" "foo" {
+}
+`,
+		"other.tf": `resource "aws_instance" "bar" {
+  depends_on = aws_instance.foo
+}
+`,
+	}
+	p := newTestPlanner(t, files)
+
+	we, err := p.RenameTarget("main.tf", posOf(t, files["main.tf"], `"foo"`), "baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFiles := map[string]string{
+		"main.tf": `resource "aws_instance" "baz" {
+}
+`,
+		"other.tf": `resource "aws_instance" "bar" {
+  depends_on = aws_instance.baz
+}
+`,
+	}
+	if diff := cmp.Diff(expectedFiles, applyWorkspaceEdit(files, we)); diff != "" {
+		t.Fatalf("unexpected files: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"main.tf", "other.tf"}, we.Filenames()); diff != "" {
+		t.Fatalf("unexpected filenames: %s", diff)
+	}
+}
+
+func TestPlanner_ExtractValue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		files         map[string]string
+		exprText      string
+		valueName     string
+		expectedFiles map[string]string
+		expectedErr   error
+	}{
+		{
+			"new block",
+			map[string]string{
+				"main.tf": `resource "aws_instance" "foo" {
+  name = "foo-${local.env}"
+}
+`,
+			},
+			`"foo-${local.env}"`,
+			"instance_name",
+			map[string]string{
+				"main.tf": `resource "aws_instance" "foo" {
+  name = local.instance_name
+}
+
+locals {
+  instance_name = "foo-${local.env}"
+}
+`,
+			},
+			nil,
+		},
+		{
+			"existing block",
+			map[string]string{
+				"main.tf": `locals {
+  env = "dev"
+}
+resource "aws_instance" "foo" {
+  name = "foo"
+}
+`,
+			},
+			`"foo"`,
+			"instance_name",
+			map[string]string{
+				"main.tf": `locals {
+  env = "dev"
+  instance_name = "foo"
+}
+resource "aws_instance" "foo" {
+  name = local.instance_name
+}
+`,
+			},
+			nil,
+		},
+		{
+			"existing single-line block",
+			map[string]string{
+				"main.tf": `locals {}
+resource "aws_instance" "foo" {
+  name = "foo"
+}
+`,
+			},
+			`"foo"`,
+			"instance_name",
+			map[string]string{
+				"main.tf": `locals {
+  instance_name = "foo"
+}
+resource "aws_instance" "foo" {
+  name = local.instance_name
+}
+`,
+			},
+			nil,
+		},
+		{
+			"name collision",
+			map[string]string{
+				"main.tf": `locals {
+  env = "dev"
+}
+resource "aws_instance" "foo" {
+  name = "foo"
+}
+`,
+			},
+			`"foo"`,
+			"env",
+			nil,
+			&decoder.AddressCollisionError{},
+		},
+		{
+			"invalid name",
+			map[string]string{
+				"main.tf": `resource "aws_instance" "foo" {
+  name = "foo"
+}
+`,
+			},
+			`"foo"`,
+			"1st",
+			nil,
+			&InvalidNameError{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			p := newTestPlanner(t, tc.files)
+
+			rng := rangeOf(t, "main.tf", tc.files["main.tf"], tc.exprText)
+			we, err := p.ExtractValue("main.tf", rng, tc.valueName)
+			if tc.expectedErr != nil {
+				if err == nil || fmt.Sprintf("%T", err) != fmt.Sprintf("%T", tc.expectedErr) {
+					t.Fatalf("expected %T error, given: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedFiles, applyWorkspaceEdit(tc.files, we)); diff != "" {
+				t.Fatalf("unexpected files: %s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanner_InlineTarget(t *testing.T) {
+	files := map[string]string{
+		"locals.tf": `locals {
+  env  = "dev"
+  name = "foo-${local.env}"
+}
+`,
+		"main.tf": `resource "aws_instance" "foo" {
+  name = local.name
+}
+resource "aws_instance" "bar" {
+  name = local.name
+}
+`,
+	}
+	p := newTestPlanner(t, files)
+
+	we, err := p.InlineTarget("locals.tf", posOf(t, files["locals.tf"], "name ="))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFiles := map[string]string{
+		"locals.tf": `locals {
+  env  = "dev"
+}
+`,
+		"main.tf": `resource "aws_instance" "foo" {
+  name = "foo-${local.env}"
+}
+resource "aws_instance" "bar" {
+  name = "foo-${local.env}"
+}
+`,
+	}
+	if diff := cmp.Diff(expectedFiles, applyWorkspaceEdit(files, we)); diff != "" {
+		t.Fatalf("unexpected files: %s", diff)
+	}
+
+	_, err = p.InlineTarget("main.tf", posOf(t, files["main.tf"], "name ="))
+	var posErr *decoder.PositionalError
+	if !errors.As(err, &posErr) {
+		t.Fatalf("expected PositionalError, given: %v", err)
+	}
+}
+
+func TestNewWorkspaceEdit_overlapping(t *testing.T) {
+	rng := func(start, end int) hcl.Range {
+		return hcl.Range{
+			Filename: "main.tf",
+			Start:    hcl.Pos{Line: 1, Column: start + 1, Byte: start},
+			End:      hcl.Pos{Line: 1, Column: end + 1, Byte: end},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		edits       []lang.TextEdit
+		expectedErr bool
+	}{
+		{
+			"touching edits",
+			[]lang.TextEdit{
+				textEdit(rng(5, 8), "foo"),
+				textEdit(rng(0, 5), "bar"),
+				textEdit(rng(8, 8), "baz"),
+			},
+			false,
+		},
+		{
+			"overlapping replacements",
+			[]lang.TextEdit{
+				textEdit(rng(0, 5), "foo"),
+				textEdit(rng(4, 8), "bar"),
+			},
+			true,
+		},
+		{
+			"insertion within replacement",
+			[]lang.TextEdit{
+				textEdit(rng(0, 5), "foo"),
+				textEdit(rng(3, 3), "bar"),
+			},
+			true,
+		},
+		{
+			"insertions at the same position",
+			[]lang.TextEdit{
+				textEdit(rng(3, 3), "foo"),
+				textEdit(rng(3, 3), "bar"),
+			},
+			true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			_, err := newWorkspaceEdit(tc.edits)
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("expected error: %t, given: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package refactor
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// WorkspaceEdit represents edits of one or more files which
+// make up a single change, i.e. are to be applied all together
+type WorkspaceEdit struct {
+	// Changes holds edits of each file, sorted by position
+	// and guaranteed not to overlap with each other
+	Changes map[string][]lang.TextEdit
+}
+
+// newWorkspaceEdit groups the given edits by file, returning
+// OverlappingEditsError if any two edits of the same file overlap,
+// as the whole change could not be applied then
+func newWorkspaceEdit(edits []lang.TextEdit) (*WorkspaceEdit, error) {
+	changes := make(map[string][]lang.TextEdit, 0)
+	for _, edit := range edits {
+		filename := edit.Range.Filename
+		changes[filename] = append(changes[filename], edit)
+	}
+
+	for filename, fileEdits := range changes {
+		sort.SliceStable(fileEdits, func(i, j int) bool {
+			return fileEdits[i].Range.Start.Byte < fileEdits[j].Range.Start.Byte
+		})
+		for i := 1; i < len(fileEdits); i++ {
+			if editsOverlap(fileEdits[i-1].Range, fileEdits[i].Range) {
+				return nil, &OverlappingEditsError{
+					Filename: filename,
+					Range:    fileEdits[i].Range,
+				}
+			}
+		}
+	}
+
+	return &WorkspaceEdit{
+		Changes: changes,
+	}, nil
+}
+
+// Filenames returns sorted names of files which are changed
+func (we *WorkspaceEdit) Filenames() []string {
+	filenames := make([]string, 0, len(we.Changes))
+	for filename := range we.Changes {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+// editsOverlap reports whether two edits sorted by position overlap,
+// where two insertions at the same position are considered overlapping
+// as the order in which they apply would be ambiguous
+func editsOverlap(a, b hcl.Range) bool {
+	if a.Start.Byte == b.Start.Byte && (a.Start.Byte == a.End.Byte || b.Start.Byte == b.End.Byte) {
+		return a.End.Byte == b.End.Byte
+	}
+	return a.End.Byte > b.Start.Byte
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // AttributeSchema describes schema for an attribute
@@ -16,17 +17,71 @@ type AttributeSchema struct {
 	IsComputed   bool
 	IsSensitive  bool
 
+	// IsBuiltin describes whether the attribute is provided
+	// by the language itself, rather than by an extension
+	// (e.g. meta-arguments, such as count in Terraform)
+	IsBuiltin bool
+
+	// IsInsecure describes whether the attribute value may be exposed,
+	// e.g. logged or stored in plain text, such that references
+	// to sensitive values should not be interpolated into it
+	IsInsecure bool
+
+	// IsWriteOnly describes whether the attribute value is only
+	// passed on and not retained (e.g. ephemeral), such that
+	// it cannot be referenced, except from other write-only attributes
+	IsWriteOnly bool
+
+	// SemanticToken represents dialect-specific semantic token
+	// type and/or modifiers of the attribute name
+	SemanticToken *SemanticToken
+
 	// Expr represents expression constraints e.g. what types of
 	// expressions are expected for the attribute
 	Expr ExprConstraints
 
+	// DefaultValue represents the value used when the attribute
+	// is not declared, if any. It is shown in hover and used
+	// as the value when completing the attribute.
+	DefaultValue cty.Value
+
 	// IsDepKey describes whether to use this attribute (and its value)
 	// as key when looking up dependent schema
 	IsDepKey bool
 
 	Address *AttributeAddrSchema
+
+	// NameConstraint constrains names of attributes matched
+	// by BodySchema.AnyAttribute. It is not applicable
+	// to attributes declared by name.
+	NameConstraint KeyConstraint
+
+	// NameCompletion describes what is inserted when completing
+	// the attribute name. The decoder decides based on client
+	// capabilities by default.
+	NameCompletion NameCompletion
+
+	// Examples represents examples of the attribute
+	Examples Examples
 }
 
+// NameCompletion describes what is inserted
+// when completing the name of an attribute
+type NameCompletion uint
+
+const (
+	// NameCompletionDefault leaves the decision to the decoder
+	NameCompletionDefault NameCompletion = iota
+
+	// NameCompletionWithValue inserts the name followed by " = "
+	// and a placeholder for the value and triggers completion of the value
+	NameCompletionWithValue
+
+	// NameCompletionNameOnly inserts just the name, e.g. such that
+	// it doesn't interfere with the editor inserting " = " on its own
+	NameCompletionNameOnly
+)
+
 type AttributeAddrSchema struct {
 	Steps []AddrStep
 
@@ -44,6 +99,10 @@ type AttributeAddrSchema struct {
 	// AsReference defines whether the attribute
 	// is addressable as a type-less reference
 	AsReference bool
+
+	// Sensitive defines whether the value of the attribute
+	// (and any of its nested targets) is sensitive
+	Sensitive bool
 }
 
 func (*AttributeSchema) isSchemaImpl() schemaImplSigil {
@@ -59,10 +118,18 @@ func (as *AttributeSchema) Validate() error {
 		return errors.New("cannot be both IsRequired and IsComputed")
 	}
 
+	if as.IsWriteOnly && as.IsComputed {
+		return errors.New("cannot be both IsWriteOnly and IsComputed")
+	}
+
 	if !as.IsRequired && !as.IsOptional && !as.IsComputed {
 		return errors.New("one of IsRequired, IsOptional, or IsComputed must be set")
 	}
 
+	if as.DefaultValue != cty.NilVal && as.IsRequired {
+		return errors.New("DefaultValue is not applicable to required attribute")
+	}
+
 	if as.Address != nil {
 		if !as.Address.AsExprType && !as.Address.AsReference {
 			return fmt.Errorf("Address: at least one of AsExprType or AsReference must be set")
@@ -78,6 +145,21 @@ func (as *AttributeSchema) Validate() error {
 		}
 	}
 
+	if as.NameCompletion > NameCompletionNameOnly {
+		return fmt.Errorf("NameCompletion: unknown value %d", as.NameCompletion)
+	}
+
+	if as.NameConstraint != nil {
+		err := as.NameConstraint.Validate()
+		if err != nil {
+			return fmt.Errorf("NameConstraint: %w", err)
+		}
+	}
+
+	if err := as.Examples.Validate(); err != nil {
+		return err
+	}
+
 	return as.Expr.Validate()
 }
 
@@ -92,10 +174,22 @@ func (as *AttributeSchema) Copy() *AttributeSchema {
 		IsDeprecated: as.IsDeprecated,
 		IsComputed:   as.IsComputed,
 		IsSensitive:  as.IsSensitive,
+		IsBuiltin:    as.IsBuiltin,
+		IsInsecure:   as.IsInsecure,
+		IsWriteOnly:  as.IsWriteOnly,
 		IsDepKey:     as.IsDepKey,
 		Description:  as.Description,
 		Expr:         as.Expr.Copy(),
 		Address:      as.Address.Copy(),
+		DefaultValue: as.DefaultValue,
+
+		NameCompletion: as.NameCompletion,
+		SemanticToken:  as.SemanticToken.Copy(),
+		Examples:       as.Examples.Copy(),
+	}
+
+	if as.NameConstraint != nil {
+		newAs.NameConstraint = as.NameConstraint.Copy()
 	}
 
 	return newAs
@@ -111,6 +205,7 @@ func (aas *AttributeAddrSchema) Copy() *AttributeAddrSchema {
 		ScopeId:      aas.ScopeId,
 		AsExprType:   aas.AsExprType,
 		AsReference:  aas.AsReference,
+		Sensitive:    aas.Sensitive,
 	}
 
 	newAas.Steps = make([]AddrStep, len(aas.Steps))
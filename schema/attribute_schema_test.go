@@ -3,6 +3,7 @@ package schema
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/hcl-lang/lang"
@@ -46,6 +47,41 @@ func TestAttributeSchema_Validate(t *testing.T) {
 			},
 			errors.New("cannot be both IsRequired and IsComputed"),
 		},
+		{
+			&AttributeSchema{
+				Expr:        LiteralTypeOnly(cty.String),
+				IsOptional:  true,
+				IsComputed:  true,
+				IsWriteOnly: true,
+			},
+			errors.New("cannot be both IsWriteOnly and IsComputed"),
+		},
+		{
+			&AttributeSchema{
+				Expr:         LiteralTypeOnly(cty.Number),
+				IsOptional:   true,
+				DefaultValue: cty.NumberIntVal(5),
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				Expr:         LiteralTypeOnly(cty.Number),
+				IsRequired:   true,
+				DefaultValue: cty.NumberIntVal(5),
+			},
+			errors.New("DefaultValue is not applicable to required attribute"),
+		},
+		{
+			&AttributeSchema{
+				Expr:       LiteralTypeOnly(cty.Number),
+				IsOptional: true,
+				Examples: Examples{
+					{Code: "size = 5"},
+				},
+			},
+			errors.New("Examples[0]: Name must be set"),
+		},
 		{
 			&AttributeSchema{
 				Expr:       LiteralTypeOnly(cty.String),
@@ -112,6 +148,198 @@ func TestAttributeSchema_Validate(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					TraversalExpr{
+						OfScopeId:  lang.ScopeId("resource"),
+						OfScopeIds: []lang.ScopeId{"data"},
+					},
+				},
+				IsOptional: true,
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					TraversalExpr{
+						OfScopeId:  lang.ScopeId("resource"),
+						OfScopeIds: []lang.ScopeId{"data", "resource"},
+					},
+				},
+				IsOptional: true,
+			},
+			errors.New(`(0: schema.TraversalExpr) OfScopeIds[1]: duplicate ScopeId "resource"`),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					TraversalExpr{OfScopeIds: []lang.ScopeId{""}},
+				},
+				IsOptional: true,
+			},
+			errors.New("(0: schema.TraversalExpr) OfScopeIds[0]: ScopeId must not be empty"),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					TraversalExpr{
+						OfScopeIds: []lang.ScopeId{"data"},
+						Address: &TraversalAddrSchema{
+							ScopeId: lang.ScopeId("blah"),
+						},
+					},
+				},
+				IsOptional: true,
+			},
+			errors.New("(0: schema.TraversalExpr) cannot be have both Address and OfType/OfScopeId set"),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					TupleExpr{
+						Elems: []ExprConstraints{
+							LiteralTypeOnly(cty.Number),
+							LiteralTypeOnly(cty.Number),
+						},
+						ElemNames: []string{"min", "max"},
+					},
+				},
+				IsOptional: true,
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					TupleExpr{
+						Elems: []ExprConstraints{
+							LiteralTypeOnly(cty.Number),
+							LiteralTypeOnly(cty.Number),
+						},
+						ElemNames: []string{"min"},
+					},
+				},
+				IsOptional: true,
+			},
+			errors.New("(0: schema.TupleExpr) ElemNames: expected 2 names (one per element), 1 given"),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					TupleExpr{
+						Elems: []ExprConstraints{
+							LiteralTypeOnly(cty.Number),
+							LiteralTypeOnly(cty.Number),
+						},
+						ElemNames: []string{"min", "min"},
+					},
+				},
+				IsOptional: true,
+			},
+			errors.New(`(0: schema.TupleExpr) ElemNames[1]: duplicate name "min"`),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					SizeExpr{Units: []string{"Mi", "Gi"}},
+				},
+				IsOptional: true,
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					SizeExpr{},
+				},
+				IsOptional: true,
+			},
+			errors.New("(0: schema.SizeExpr) Units must not be empty"),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					SizeExpr{Units: []string{"Mi", "G1"}},
+				},
+				IsOptional: true,
+			},
+			errors.New(`(0: schema.SizeExpr) Units[1]: unit "G1" must consist of letters only`),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					SizeExpr{Units: []string{"Mi", "Mi"}},
+				},
+				IsOptional: true,
+			},
+			errors.New(`(0: schema.SizeExpr) Units[1]: duplicate unit "Mi"`),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					LiteralTypeExpr{Type: cty.String, TrailingNewline: TrailingNewlineRequired},
+				},
+				IsOptional: true,
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					LiteralTypeExpr{Type: cty.Number, TrailingNewline: TrailingNewlineForbidden},
+				},
+				IsOptional: true,
+			},
+			errors.New("(0: schema.LiteralTypeExpr) TrailingNewline requires string Type"),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					LiteralTypeExpr{Type: cty.String, Heredoc: HeredocIndented, TrailingNewline: TrailingNewlineRequired},
+				},
+				IsOptional: true,
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					LiteralTypeExpr{Type: cty.Number, Heredoc: HeredocRequired},
+				},
+				IsOptional: true,
+			},
+			errors.New("(0: schema.LiteralTypeExpr) Heredoc requires string Type"),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					LiteralTypeExpr{Type: cty.String, Heredoc: HeredocFlush, TrailingNewline: TrailingNewlineForbidden},
+				},
+				IsOptional: true,
+			},
+			errors.New("(0: schema.LiteralTypeExpr) Heredoc conflicts with TrailingNewlineForbidden, as heredoc always ends with a newline"),
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					LiteralTypeExpr{Type: cty.Map(cty.String), SkipLiteralComplexTypes: true},
+					MapExpr{Elem: LiteralTypeOnly(cty.String)},
+				},
+				IsOptional: true,
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				Expr: ExprConstraints{
+					LiteralTypeExpr{Type: cty.String, SkipLiteralComplexTypes: true},
+				},
+				IsOptional: true,
+			},
+			errors.New("(0: schema.LiteralTypeExpr) SkipLiteralComplexTypes requires complex or dynamic Type"),
+		},
 		{
 			&AttributeSchema{
 				Address: &AttributeAddrSchema{
@@ -170,6 +398,43 @@ func TestAttributeSchema_Validate(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			&AttributeSchema{
+				IsOptional:     true,
+				NameConstraint: KeyPattern{},
+			},
+			errors.New("NameConstraint: Pattern must be set"),
+		},
+		{
+			&AttributeSchema{
+				IsOptional:     true,
+				NameConstraint: KeyEnum{},
+			},
+			errors.New("NameConstraint: Names must not be empty"),
+		},
+		{
+			&AttributeSchema{
+				IsOptional: true,
+				NameConstraint: KeyPattern{
+					Pattern: regexp.MustCompile(`^[a-z_]+$`),
+				},
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				IsOptional:     true,
+				NameCompletion: NameCompletionNameOnly,
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				IsOptional:     true,
+				NameCompletion: NameCompletion(42),
+			},
+			errors.New("NameCompletion: unknown value 42"),
+		},
 	}
 
 	for i, tc := range testCases {
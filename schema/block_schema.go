@@ -22,12 +22,38 @@ type BlockSchema struct {
 	// depending on SchemaKey (labels or attributes)
 	DependentBody map[SchemaKey]*BodySchema
 
+	// LazyDependentBody optionally represents dependent body schemas
+	// loaded on first use, in addition to DependentBody
+	LazyDependentBody *LazyDependentBody
+
 	Description  lang.MarkupContent
 	IsDeprecated bool
 	MinItems     uint64
 	MaxItems     uint64
 
+	// IsBuiltin describes whether the block is provided
+	// by the language itself, rather than by an extension
+	// (e.g. meta-arguments, such as lifecycle in Terraform)
+	IsBuiltin bool
+
+	// SemanticToken represents dialect-specific semantic token
+	// type and/or modifiers of the block type
+	SemanticToken *SemanticToken
+
 	Address *BlockAddrSchema
+
+	// AllowUnknownAttributes defines whether attributes not declared
+	// in the schema are tolerated in the body of the block,
+	// regardless of AllowUnknownAttributes of the body schema,
+	// e.g. where the body schema is shared with other blocks.
+	AllowUnknownAttributes bool
+
+	// Examples represents examples of the block
+	Examples Examples
+
+	// NamedValues marks the block as a place where
+	// expressions can be extracted into named values
+	NamedValues *NamedValues
 }
 
 type BlockAddrSchema struct {
@@ -64,6 +90,10 @@ type BlockAddrSchema struct {
 	// blocks and attributes are also walked
 	// and their addresses inferred as data
 	InferDependentBody bool
+
+	// Sensitive defines whether the data of the block
+	// (and any of its nested targets) is sensitive
+	Sensitive bool
 }
 
 type BlockAsTypeOf struct {
@@ -113,6 +143,7 @@ func (bas *BlockAddrSchema) Copy() *BlockAddrSchema {
 		InferBody:           bas.InferBody,
 		DependentBodyAsData: bas.DependentBodyAsData,
 		InferDependentBody:  bas.InferDependentBody,
+		Sensitive:           bas.Sensitive,
 	}
 
 	newBas.Steps = make([]AddrStep, len(bas.Steps))
@@ -141,6 +172,13 @@ func (*BlockSchema) isSchemaImpl() schemaImplSigil {
 func (bSchema *BlockSchema) Validate() error {
 	var errs *multierror.Error
 
+	for i, label := range bSchema.Labels {
+		err := label.Validate()
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("Labels[%d]: %w", i, err))
+		}
+	}
+
 	if bSchema.Address != nil {
 		err := bSchema.Address.Validate()
 		if err != nil {
@@ -148,6 +186,17 @@ func (bSchema *BlockSchema) Validate() error {
 		}
 	}
 
+	if err := bSchema.Examples.Validate(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	if bSchema.NamedValues != nil {
+		err := bSchema.NamedValues.validate(bSchema)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("NamedValues: %w", err))
+		}
+	}
+
 	if bSchema.Body != nil {
 		err := bSchema.Body.Validate()
 		if err != nil {
@@ -170,11 +219,21 @@ func (bs *BlockSchema) Copy() *BlockSchema {
 	newBs := &BlockSchema{
 		Type:         bs.Type,
 		IsDeprecated: bs.IsDeprecated,
+		IsBuiltin:    bs.IsBuiltin,
 		MinItems:     bs.MinItems,
 		MaxItems:     bs.MaxItems,
 		Description:  bs.Description,
 		Body:         bs.Body.Copy(),
 		Address:      bs.Address.Copy(),
+
+		SemanticToken: bs.SemanticToken.Copy(),
+
+		AllowUnknownAttributes: bs.AllowUnknownAttributes,
+		Examples:               bs.Examples.Copy(),
+		NamedValues:            bs.NamedValues.Copy(),
+
+		// loaded schemas are shared by copies
+		LazyDependentBody: bs.LazyDependentBody,
 	}
 
 	if bs.Labels != nil {
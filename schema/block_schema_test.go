@@ -60,6 +60,103 @@ func TestBlockSchema_Validate(t *testing.T) {
 			},
 			errors.New("Address: InferDependentBody requires DependentBodyAsData"),
 		},
+		{
+			&BlockSchema{
+				Examples: Examples{
+					{Name: "basic", Code: "foo {}"},
+				},
+			},
+			nil,
+		},
+		{
+			&BlockSchema{
+				Examples: Examples{
+					{Name: "basic", Code: "foo {}"},
+					{Name: "advanced"},
+				},
+			},
+			errors.New("Examples[1]: Code must be set"),
+		},
+		{
+			&BlockSchema{
+				Body: &BodySchema{
+					AnyAttribute: &AttributeSchema{
+						IsOptional: true,
+						Address: &AttributeAddrSchema{
+							Steps: []AddrStep{
+								StaticStep{Name: "local"},
+								AttrNameStep{},
+							},
+							AsReference: true,
+						},
+					},
+				},
+				NamedValues: &NamedValues{FriendlyName: "local value"},
+			},
+			nil,
+		},
+		{
+			&BlockSchema{
+				Body: &BodySchema{
+					AnyAttribute: &AttributeSchema{
+						IsOptional: true,
+					},
+				},
+				NamedValues: &NamedValues{},
+			},
+			errors.New("NamedValues: empty ValueAttrName requires addressable Body.AnyAttribute"),
+		},
+		{
+			&BlockSchema{
+				Labels: []*LabelSchema{
+					{Name: "name"},
+				},
+				Address: &BlockAddrSchema{
+					Steps: []AddrStep{
+						StaticStep{Name: "var"},
+						LabelStep{Index: 0},
+					},
+				},
+				Body: &BodySchema{
+					Attributes: map[string]*AttributeSchema{
+						"default": {IsOptional: true},
+					},
+				},
+				NamedValues: &NamedValues{ValueAttrName: "default"},
+			},
+			nil,
+		},
+		{
+			&BlockSchema{
+				Labels: []*LabelSchema{
+					{Name: "name"},
+				},
+				Address: &BlockAddrSchema{
+					Steps: []AddrStep{
+						StaticStep{Name: "var"},
+						LabelStep{Index: 0},
+					},
+				},
+				Body: &BodySchema{
+					Attributes: map[string]*AttributeSchema{
+						"default": {IsOptional: true},
+					},
+				},
+				NamedValues: &NamedValues{ValueAttrName: "value"},
+			},
+			errors.New(`NamedValues: ValueAttrName: "value" is not declared in Body`),
+		},
+		{
+			&BlockSchema{
+				Body: &BodySchema{
+					Attributes: map[string]*AttributeSchema{
+						"default": {IsOptional: true},
+					},
+				},
+				NamedValues: &NamedValues{ValueAttrName: "default"},
+			},
+			errors.New("NamedValues: ValueAttrName requires exactly one label"),
+		},
 	}
 
 	for i, tc := range testCases {
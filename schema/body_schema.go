@@ -5,6 +5,7 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
 )
 
 // BodySchema describes schema of a body comprised of blocks or attributes
@@ -17,6 +18,17 @@ type BodySchema struct {
 	Detail       string
 	Description  lang.MarkupContent
 
+	// AnyBlock represents schema of any block whose type
+	// is not declared in Blocks, e.g. in dialects which allow
+	// arbitrary block types with a known inner structure
+	AnyBlock *BlockSchema
+
+	// AllowUnknownAttributes defines whether attributes which are not
+	// declared in the schema are tolerated in the body, rather than
+	// reported as errors, e.g. in dialects with lenient blocks.
+	// Such attributes are still not offered in completion.
+	AllowUnknownAttributes bool
+
 	// DocsLink represents a link to docs that will be exposed
 	// as part of LinksInFile()
 	DocsLink *DocsLink
@@ -26,6 +38,22 @@ type BodySchema struct {
 	// but often will match.
 	HoverURL string
 
+	// DefinitionLocation represents location of the definition
+	// of the body, which DefinitionAtPos() resolves labels selecting
+	// the body (as DependentBody) to, e.g. provider docs
+	// or a generated schema file.
+	DefinitionLocation *DefinitionLocation
+
+	// TopLevelCompletionHints provides guidance for completion
+	// at the top level of a file, such as in an empty file.
+	// It is only applicable to the root body schema.
+	TopLevelCompletionHints *CompletionHints
+
+	// Ordering optionally represents the order in which attributes
+	// and blocks are expected, which is validated with hints
+	// and can be fixed by reordering them.
+	Ordering *Ordering
+
 	// TODO: Functions
 }
 
@@ -34,6 +62,19 @@ type DocsLink struct {
 	Tooltip string
 }
 
+// DefinitionLocation represents location of a definition
+// outside of the configuration
+type DefinitionLocation struct {
+	// URI represents the document, e.g. a file:// URI of a generated
+	// schema file, a https:// URL of docs, or any (virtual) URI
+	// the client knows how to open
+	URI string
+
+	// Range optionally represents the range of the definition
+	// within the document, where Filename is ignored
+	Range hcl.Range
+}
+
 func (*BodySchema) isSchemaImpl() schemaImplSigil {
 	return schemaImplSigil{}
 }
@@ -53,12 +94,38 @@ func (bs *BodySchema) Validate() error {
 
 	var result *multierror.Error
 	for name, attr := range bs.Attributes {
+		if attr.NameConstraint != nil {
+			result = multierror.Append(result, fmt.Errorf("%s: NameConstraint is only applicable to AnyAttribute", name))
+			continue
+		}
 		err := attr.Validate()
 		if err != nil {
 			result = multierror.Append(result, fmt.Errorf("%s: %w", name, err))
 		}
 	}
 
+	if bs.AnyAttribute != nil {
+		err := bs.AnyAttribute.Validate()
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("AnyAttribute: %w", err))
+		}
+	}
+
+	if bs.AnyBlock != nil {
+		err := bs.AnyBlock.Validate()
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("AnyBlock: %w", err))
+		}
+	}
+
+	if err := bs.TopLevelCompletionHints.Validate(); err != nil {
+		result = multierror.Append(result, fmt.Errorf("TopLevelCompletionHints: %w", err))
+	}
+
+	if err := bs.Ordering.Validate(); err != nil {
+		result = multierror.Append(result, fmt.Errorf("Ordering: %w", err))
+	}
+
 	for bType, block := range bs.Blocks {
 		err := block.Validate()
 		if err != nil {
@@ -87,6 +154,16 @@ func (bs *BodySchema) Copy() *BodySchema {
 		AnyAttribute: bs.AnyAttribute.Copy(),
 		HoverURL:     bs.HoverURL,
 		DocsLink:     bs.DocsLink.Copy(),
+
+		DefinitionLocation: bs.DefinitionLocation.Copy(),
+
+		AnyBlock: bs.AnyBlock.Copy(),
+
+		AllowUnknownAttributes: bs.AllowUnknownAttributes,
+
+		TopLevelCompletionHints: bs.TopLevelCompletionHints.Copy(),
+
+		Ordering: bs.Ordering.Copy(),
 	}
 
 	if bs.Attributes != nil {
@@ -106,6 +183,17 @@ func (bs *BodySchema) Copy() *BodySchema {
 	return newBs
 }
 
+func (dl *DefinitionLocation) Copy() *DefinitionLocation {
+	if dl == nil {
+		return nil
+	}
+
+	return &DefinitionLocation{
+		URI:   dl.URI,
+		Range: dl.Range,
+	}
+}
+
 func (dl *DocsLink) Copy() *DocsLink {
 	if dl == nil {
 		return nil
@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// CompletionHints provides guidance for completion of a body,
+// beyond what is declared in the schema
+type CompletionHints struct {
+	// Weights represents relevance of blocks and attributes
+	// (keyed by block type or attribute name), such that candidates
+	// of a higher weight are listed first, followed by any others.
+	Weights map[string]float64
+
+	// Templates represents content offered in an empty file,
+	// e.g. a skeleton of the most common blocks
+	Templates []FileTemplate
+}
+
+// FileTemplate represents content of a whole file,
+// offered as a completion candidate
+type FileTemplate struct {
+	Name        string
+	Description lang.MarkupContent
+
+	// Text represents the content as inserted by clients
+	// without support for snippets
+	Text string
+
+	// Snippet optionally represents the content in the snippet syntax,
+	// e.g. with placeholders such as ${1:name}. Text is used if empty.
+	Snippet string
+}
+
+func (ch *CompletionHints) Validate() error {
+	if ch == nil {
+		return nil
+	}
+
+	for i, tpl := range ch.Templates {
+		if tpl.Name == "" {
+			return fmt.Errorf("Templates[%d]: %w", i, errors.New("Name must be set"))
+		}
+		if tpl.Text == "" {
+			return fmt.Errorf("Templates[%d]: %w", i, errors.New("Text must be set"))
+		}
+	}
+
+	return nil
+}
+
+func (ch *CompletionHints) Copy() *CompletionHints {
+	if ch == nil {
+		return nil
+	}
+
+	newCh := &CompletionHints{}
+
+	if ch.Weights != nil {
+		newCh.Weights = make(map[string]float64, len(ch.Weights))
+		for name, weight := range ch.Weights {
+			newCh.Weights[name] = weight
+		}
+	}
+
+	if ch.Templates != nil {
+		newCh.Templates = make([]FileTemplate, len(ch.Templates))
+		copy(newCh.Templates, ch.Templates)
+	}
+
+	return newCh
+}
+
+// Weight returns weight of the block type or attribute name,
+// which is zero if not declared (or if there are no hints)
+func (ch *CompletionHints) Weight(name string) float64 {
+	if ch == nil {
+		return 0
+	}
+	return ch.Weights[name]
+}
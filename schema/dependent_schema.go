@@ -6,6 +6,8 @@ import (
 	"sort"
 
 	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
@@ -55,6 +57,13 @@ func NewSchemaKey(keys DependencyKeys) SchemaKey {
 	return SchemaKey(string(b))
 }
 
+// DependencyKeys decodes the key back into DependencyKeys
+func (sk SchemaKey) DependencyKeys() (DependencyKeys, error) {
+	var dk DependencyKeys
+	err := json.Unmarshal([]byte(sk), &dk)
+	return dk, err
+}
+
 type depKeySigil struct{}
 
 // DependencyKey represents a key used to find a dependent body schema
@@ -108,6 +117,120 @@ func (ev ExpressionValue) MarshalJSON() ([]byte, error) {
 	return json.Marshal(val)
 }
 
+func (ev *ExpressionValue) UnmarshalJSON(b []byte) error {
+	var val struct {
+		Static  json.RawMessage `json:"static,omitempty"`
+		Address string          `json:"addr,omitempty"`
+	}
+	err := json.Unmarshal(b, &val)
+	if err != nil {
+		return err
+	}
+
+	if len(val.Static) > 0 {
+		var sv ctyjson.SimpleJSONValue
+		err := sv.UnmarshalJSON(val.Static)
+		if err != nil {
+			return err
+		}
+		ev.Static = sv.Value
+	}
+
+	if val.Address != "" {
+		traversal, diags := hclsyntax.ParseTraversalAbs([]byte(val.Address), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			return diags
+		}
+		addr, err := lang.TraversalToAddress(traversal)
+		if err != nil {
+			return err
+		}
+		ev.Address = addr
+	}
+
+	return nil
+}
+
 func (ad AttributeDependent) isDependencyKeyImpl() depKeySigil {
 	return depKeySigil{}
 }
+
+// DependentKeyValues represents all values of dependency keys
+// which the DependentBody of a block is keyed by,
+// e.g. all resource types in Terraform
+type DependentKeyValues struct {
+	// Labels represents unique label values
+	// (sorted alphabetically) by label index
+	Labels map[int][]string
+
+	// Attributes represents unique attribute values by attribute name
+	Attributes map[string][]ExpressionValue
+}
+
+// DependentKeyValues returns values of all dependency keys
+// declared in DependentBody or LazyDependentBody (without loading
+// any schemas). Keys which cannot be decoded are ignored.
+func (bs *BlockSchema) DependentKeyValues() DependentKeyValues {
+	dkv := DependentKeyValues{
+		Labels:     make(map[int][]string, 0),
+		Attributes: make(map[string][]ExpressionValue, 0),
+	}
+
+	seenLabels := make(map[int]map[string]bool, 0)
+	seenAttrs := make(map[string]map[string]bool, 0)
+
+	keys := make([]SchemaKey, 0, len(bs.DependentBody))
+	for key := range bs.DependentBody {
+		keys = append(keys, key)
+	}
+	if bs.LazyDependentBody != nil {
+		keys = append(keys, bs.LazyDependentBody.Keys...)
+	}
+
+	for _, key := range keys {
+		dk, err := key.DependencyKeys()
+		if err != nil {
+			continue
+		}
+
+		for _, label := range dk.Labels {
+			if _, ok := seenLabels[label.Index]; !ok {
+				seenLabels[label.Index] = make(map[string]bool, 0)
+			}
+			if seenLabels[label.Index][label.Value] {
+				continue
+			}
+			seenLabels[label.Index][label.Value] = true
+			dkv.Labels[label.Index] = append(dkv.Labels[label.Index], label.Value)
+		}
+
+		for _, attr := range dk.Attributes {
+			b, err := attr.Expr.MarshalJSON()
+			if err != nil {
+				continue
+			}
+			if _, ok := seenAttrs[attr.Name]; !ok {
+				seenAttrs[attr.Name] = make(map[string]bool, 0)
+			}
+			if seenAttrs[attr.Name][string(b)] {
+				continue
+			}
+			seenAttrs[attr.Name][string(b)] = true
+			dkv.Attributes[attr.Name] = append(dkv.Attributes[attr.Name], attr.Expr)
+		}
+	}
+
+	for idx := range dkv.Labels {
+		sort.Strings(dkv.Labels[idx])
+	}
+	for name, values := range dkv.Attributes {
+		sort.SliceStable(values, func(i, j int) bool {
+			bi, _ := values[i].MarshalJSON()
+			bj, _ := values[j].MarshalJSON()
+			return string(bi) < string(bj)
+		})
+		dkv.Attributes[name] = values
+	}
+
+	return dkv
+}
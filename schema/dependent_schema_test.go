@@ -0,0 +1,118 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSchemaKey_DependencyKeys(t *testing.T) {
+	keys := DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+		Attributes: []AttributeDependent{
+			{
+				Name: "provider",
+				Expr: ExpressionValue{
+					Address: lang.Address{
+						lang.RootStep{Name: "aws"},
+						lang.AttrStep{Name: "west"},
+					},
+				},
+			},
+			{
+				Name: "region",
+				Expr: ExpressionValue{
+					Static: cty.StringVal("eu-west-1"),
+				},
+			},
+		},
+	}
+
+	decodedKeys, err := NewSchemaKey(keys).DependencyKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(keys, decodedKeys, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected keys: %s", diff)
+	}
+}
+
+func TestBlockSchema_DependentKeyValues(t *testing.T) {
+	bs := &BlockSchema{
+		Labels: []*LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "name"},
+		},
+		DependentBody: map[SchemaKey]*BodySchema{
+			NewSchemaKey(DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "google_instance"},
+				},
+			}): {},
+			NewSchemaKey(DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+			}): {},
+			NewSchemaKey(DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+				Attributes: []AttributeDependent{
+					{
+						Name: "provider",
+						Expr: ExpressionValue{
+							Address: lang.Address{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "west"},
+							},
+						},
+					},
+				},
+			}): {},
+			NewSchemaKey(DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "aws_vpc"},
+				},
+				Attributes: []AttributeDependent{
+					{
+						Name: "provider",
+						Expr: ExpressionValue{
+							Address: lang.Address{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "west"},
+							},
+						},
+					},
+				},
+			}): {},
+			SchemaKey("undecodable"): {},
+		},
+	}
+
+	expectedValues := DependentKeyValues{
+		Labels: map[int][]string{
+			0: {"aws_instance", "aws_vpc", "google_instance"},
+		},
+		Attributes: map[string][]ExpressionValue{
+			"provider": {
+				{
+					Address: lang.Address{
+						lang.RootStep{Name: "aws"},
+						lang.AttrStep{Name: "west"},
+					},
+				},
+			},
+		},
+	}
+
+	values := bs.DependentKeyValues()
+	if diff := cmp.Diff(expectedValues, values, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected values: %s", diff)
+	}
+}
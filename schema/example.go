@@ -0,0 +1,53 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// Example represents a named example of a block or an attribute,
+// which is shown in hover and can be inserted e.g. via a code action
+// (see decoder.ExamplesAtPos)
+type Example struct {
+	Name        string
+	Description lang.MarkupContent
+
+	// Code represents the example in the native syntax,
+	// e.g. the whole block, including its type and labels
+	Code string
+}
+
+// Examples represents a list of examples
+type Examples []Example
+
+func (e Example) Validate() error {
+	if e.Name == "" {
+		return errors.New("Name must be set")
+	}
+	if e.Code == "" {
+		return errors.New("Code must be set")
+	}
+	return nil
+}
+
+func (e Examples) Validate() error {
+	for i, example := range e {
+		err := example.Validate()
+		if err != nil {
+			return fmt.Errorf("Examples[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (e Examples) Copy() Examples {
+	if e == nil {
+		return nil
+	}
+
+	newExamples := make(Examples, len(e))
+	copy(newExamples, e)
+	return newExamples
+}
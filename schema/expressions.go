@@ -3,7 +3,9 @@ package schema
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl-lang/lang"
@@ -65,6 +67,47 @@ func (ec ExprConstraints) Validate() error {
 	return errs.ErrorOrNil()
 }
 
+// ByPrecedence returns the constraints in order of their precedence,
+// with any WeightedExpr unwrapped.
+//
+// When more than one constraint matches the same expression,
+// the one of the highest weight takes precedence. Constraints of
+// the same weight (or without weight) take precedence in the order
+// of declaration.
+func (ec ExprConstraints) ByPrecedence() ExprConstraints {
+	hasWeights := false
+	for _, c := range ec {
+		if _, ok := c.(WeightedExpr); ok {
+			hasWeights = true
+			break
+		}
+	}
+	if !hasWeights {
+		return ec
+	}
+
+	type weighted struct {
+		constraint ExprConstraint
+		weight     int
+	}
+	wcs := make([]weighted, len(ec))
+	for i, c := range ec {
+		wcs[i] = weighted{constraint: c}
+		if we, ok := c.(WeightedExpr); ok {
+			wcs[i] = weighted{constraint: we.Expr, weight: we.Weight}
+		}
+	}
+	sort.SliceStable(wcs, func(i, j int) bool {
+		return wcs[i].weight > wcs[j].weight
+	})
+
+	sorted := make(ExprConstraints, len(wcs))
+	for i, wc := range wcs {
+		sorted[i] = wc.constraint
+	}
+	return sorted
+}
+
 func namesContain(names []string, name string) bool {
 	for _, n := range names {
 		if n == name {
@@ -84,6 +127,36 @@ type ExprConstraint interface {
 
 type LiteralTypeExpr struct {
 	Type cty.Type
+
+	// TrailingNewline indicates whether a trailing newline
+	// of a string value is significant, such as for certificates
+	// or keys, in which case validation reports strings where
+	// the trailing newline is missing or unexpected, e.g. due to
+	// the choice between a heredoc and a quoted string.
+	//
+	// Only applicable to cty.String.
+	TrailingNewline TrailingNewline
+
+	// Heredoc indicates whether a string value is expected to be
+	// a heredoc (and of which style), such as for inline scripts
+	// or other conventionally multiline content, in which case
+	// completion suggests a heredoc and validation reports quoted
+	// strings or heredocs of a different style.
+	//
+	// Only applicable to cty.String.
+	Heredoc Heredoc
+
+	// SkipLiteralComplexTypes indicates that complex literal values
+	// (i.e. [ ] and { }) do not match the constraint, such that they're
+	// matched against other constraints of the same set instead,
+	// e.g. where the type is also represented by MapExpr or ListExpr
+	// which describe the value in more detail.
+	//
+	// This applies consistently to completion (no candidates or snippets
+	// of the complex type), hover, semantic tokens and validation.
+	//
+	// Only applicable to complex types and cty.DynamicPseudoType.
+	SkipLiteralComplexTypes bool
 }
 
 func (LiteralTypeExpr) isExprConstraintImpl() exprConstrSigil {
@@ -97,10 +170,69 @@ func (lt LiteralTypeExpr) FriendlyName() string {
 func (lt LiteralTypeExpr) Copy() ExprConstraint {
 	return LiteralTypeExpr{
 		// cty.Type is immutable by design
-		Type: lt.Type,
+		Type:                    lt.Type,
+		TrailingNewline:         lt.TrailingNewline,
+		Heredoc:                 lt.Heredoc,
+		SkipLiteralComplexTypes: lt.SkipLiteralComplexTypes,
+	}
+}
+
+func (lt LiteralTypeExpr) Validate() error {
+	if lt.TrailingNewline != TrailingNewlineInsignificant && lt.Type != cty.String {
+		return errors.New("TrailingNewline requires string Type")
+	}
+	if lt.Heredoc != HeredocOptional && lt.Type != cty.String {
+		return errors.New("Heredoc requires string Type")
+	}
+	if lt.Heredoc != HeredocOptional && lt.TrailingNewline == TrailingNewlineForbidden {
+		return errors.New("Heredoc conflicts with TrailingNewlineForbidden, as heredoc always ends with a newline")
 	}
+	if lt.SkipLiteralComplexTypes && lt.Type.IsPrimitiveType() {
+		return errors.New("SkipLiteralComplexTypes requires complex or dynamic Type")
+	}
+	return nil
 }
 
+// TrailingNewline represents significance of a trailing newline
+// of a string value
+type TrailingNewline uint
+
+const (
+	// TrailingNewlineInsignificant represents strings which
+	// may or may not end with a newline (default)
+	TrailingNewlineInsignificant TrailingNewline = iota
+
+	// TrailingNewlineRequired represents strings which are expected
+	// to end with a newline, such as PEM-encoded certificates
+	TrailingNewlineRequired
+
+	// TrailingNewlineForbidden represents strings which are expected
+	// not to end with a newline, such as tokens or passwords
+	TrailingNewlineForbidden
+)
+
+// Heredoc represents whether a string value
+// is expected to be a heredoc, and of which style
+type Heredoc uint
+
+const (
+	// HeredocOptional represents strings which may be
+	// either quoted or heredoc (default)
+	HeredocOptional Heredoc = iota
+
+	// HeredocRequired represents strings which are expected
+	// to be a heredoc of either style
+	HeredocRequired
+
+	// HeredocIndented represents strings which are expected
+	// to be an indented heredoc (<<-EOT)
+	HeredocIndented
+
+	// HeredocFlush represents strings which are expected
+	// to be a heredoc which is not indented (<<EOT)
+	HeredocFlush
+)
+
 type LiteralValue struct {
 	Val          cty.Value
 	IsDeprecated bool
@@ -202,6 +334,11 @@ func (se SetExpr) Copy() ExprConstraint {
 type TupleExpr struct {
 	Elems       []ExprConstraints
 	Description lang.MarkupContent
+
+	// ElemNames (if any) represents names of the positions
+	// of a fixed-length tuple (e.g. min and max of [min, max]),
+	// in the same order as Elems.
+	ElemNames []string
 }
 
 func (TupleExpr) isExprConstraintImpl() exprConstrSigil {
@@ -222,9 +359,97 @@ func (te TupleExpr) Copy() ExprConstraint {
 			newTe.Elems[i] = elem.Copy()
 		}
 	}
+	if te.ElemNames != nil {
+		newTe.ElemNames = make([]string, len(te.ElemNames))
+		copy(newTe.ElemNames, te.ElemNames)
+	}
 	return newTe
 }
 
+// IsNamed returns true if positions of the tuple are named
+func (te TupleExpr) IsNamed() bool {
+	return len(te.ElemNames) > 0
+}
+
+// ElemName returns name of the position at the given index
+func (te TupleExpr) ElemName(idx int) (string, bool) {
+	if idx < 0 || idx >= len(te.ElemNames) {
+		return "", false
+	}
+	return te.ElemNames[idx], true
+}
+
+func (te TupleExpr) Validate() error {
+	if len(te.ElemNames) == 0 {
+		return nil
+	}
+	if len(te.ElemNames) != len(te.Elems) {
+		return fmt.Errorf("ElemNames: expected %d names (one per element), %d given",
+			len(te.Elems), len(te.ElemNames))
+	}
+	seen := make(map[string]bool, 0)
+	for i, name := range te.ElemNames {
+		if name == "" {
+			return fmt.Errorf("ElemNames[%d]: name must not be empty", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("ElemNames[%d]: duplicate name %q", i, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// SizeExpr represents a size expressed as a string consisting
+// of a number followed by a unit, such as "10Gi" or "512MB"
+type SizeExpr struct {
+	// Units represents all units accepted after the number,
+	// in the order in which they are suggested
+	Units       []string
+	Description lang.MarkupContent
+}
+
+func (SizeExpr) isExprConstraintImpl() exprConstrSigil {
+	return exprConstrSigil{}
+}
+
+func (SizeExpr) FriendlyName() string {
+	return "size"
+}
+
+func (se SizeExpr) Copy() ExprConstraint {
+	newSe := SizeExpr{
+		Description: se.Description,
+	}
+	if se.Units != nil {
+		newSe.Units = make([]string, len(se.Units))
+		copy(newSe.Units, se.Units)
+	}
+	return newSe
+}
+
+func (se SizeExpr) Validate() error {
+	if len(se.Units) == 0 {
+		return errors.New("Units must not be empty")
+	}
+	seen := make(map[string]bool, 0)
+	for i, unit := range se.Units {
+		if unit == "" {
+			return fmt.Errorf("Units[%d]: unit must not be empty", i)
+		}
+		for _, r := range unit {
+			if !unicode.IsLetter(r) {
+				return fmt.Errorf("Units[%d]: unit %q must consist of letters only", i, unit)
+			}
+		}
+		if seen[unit] {
+			return fmt.Errorf("Units[%d]: duplicate unit %q", i, unit)
+		}
+		seen[unit] = true
+	}
+	return nil
+}
+
 type MapExpr struct {
 	Elem        ExprConstraints
 	Name        string
@@ -327,6 +552,16 @@ type TraversalExpr struct {
 	OfType    cty.Type
 	Name      string
 
+	// OfScopeIds declares further acceptable scopes in addition
+	// to OfScopeId, for constructs which can refer to more than
+	// one kind of target (e.g. a resource or a data source).
+	//
+	// Scopes are listed in order of priority, i.e. where targets
+	// of more than one acceptable scope share the same address,
+	// the target of the scope listed first is preferred.
+	// OfScopeId (if set) always takes priority over OfScopeIds.
+	OfScopeIds []lang.ScopeId
+
 	// Address (if not nil) makes the expression
 	// itself addressable and provides scope
 	// for the decoded reference
@@ -363,18 +598,42 @@ func (te TraversalExpr) FriendlyName() string {
 }
 
 func (te TraversalExpr) Copy() ExprConstraint {
-	return TraversalExpr{
+	newTe := TraversalExpr{
 		OfScopeId: te.OfScopeId,
 		OfType:    te.OfType,
 		Name:      te.Name,
 		Address:   te.Address.Copy(),
 	}
+	if te.OfScopeIds != nil {
+		newTe.OfScopeIds = make([]lang.ScopeId, len(te.OfScopeIds))
+		copy(newTe.OfScopeIds, te.OfScopeIds)
+	}
+	return newTe
+}
+
+// ScopeIds returns all acceptable scopes in order of priority,
+// or nil if targets of any scope are acceptable.
+func (te TraversalExpr) ScopeIds() []lang.ScopeId {
+	return lang.ScopeIds(te.OfScopeId, te.OfScopeIds)
 }
 
 func (te TraversalExpr) Validate() error {
-	if te.Address != nil && (te.OfType != cty.NilType || te.OfScopeId != "") {
+	if te.Address != nil && (te.OfType != cty.NilType || te.OfScopeId != "" || len(te.OfScopeIds) > 0) {
 		return errors.New("cannot be have both Address and OfType/OfScopeId set")
 	}
+	seen := make(map[lang.ScopeId]bool, 0)
+	if te.OfScopeId != "" {
+		seen[te.OfScopeId] = true
+	}
+	for i, scopeId := range te.OfScopeIds {
+		if scopeId == "" {
+			return fmt.Errorf("OfScopeIds[%d]: ScopeId must not be empty", i)
+		}
+		if seen[scopeId] {
+			return fmt.Errorf("OfScopeIds[%d]: duplicate ScopeId %q", i, scopeId)
+		}
+		seen[scopeId] = true
+	}
 	if te.Address != nil && te.Address.ScopeId == "" {
 		return errors.New("Address requires non-emmpty ScopeId")
 	}
@@ -400,3 +659,47 @@ func (td TypeDeclarationExpr) FriendlyName() string {
 func (td TypeDeclarationExpr) Copy() ExprConstraint {
 	return TypeDeclarationExpr{}
 }
+
+// WeightedExpr assigns weight to a constraint, which
+// determines its precedence over any other constraints
+// matching the same expression, e.g. where both MapExpr and
+// ObjectExpr would match an object. Constraints without weight
+// are of weight 0. See also ExprConstraints.ByPrecedence().
+type WeightedExpr struct {
+	Expr   ExprConstraint
+	Weight int
+}
+
+func (WeightedExpr) isExprConstraintImpl() exprConstrSigil {
+	return exprConstrSigil{}
+}
+
+func (we WeightedExpr) FriendlyName() string {
+	if we.Expr == nil {
+		return ""
+	}
+	return we.Expr.FriendlyName()
+}
+
+func (we WeightedExpr) Copy() ExprConstraint {
+	newWe := WeightedExpr{
+		Weight: we.Weight,
+	}
+	if we.Expr != nil {
+		newWe.Expr = we.Expr.Copy()
+	}
+	return newWe
+}
+
+func (we WeightedExpr) Validate() error {
+	if we.Expr == nil {
+		return errors.New("Expr must be set")
+	}
+	if _, ok := we.Expr.(WeightedExpr); ok {
+		return errors.New("Expr cannot be WeightedExpr")
+	}
+	if c, ok := we.Expr.(interface{ Validate() error }); ok {
+		return c.Validate()
+	}
+	return nil
+}
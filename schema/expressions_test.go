@@ -1,5 +1,14 @@
 package schema
 
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
 var (
 	_ ExprConstraint = KeywordExpr{}
 	_ ExprConstraint = ListExpr{}
@@ -10,8 +19,56 @@ var (
 	_ ExprConstraint = ObjectExprAttributes{}
 	_ ExprConstraint = ObjectExpr{}
 	_ ExprConstraint = SetExpr{}
+	_ ExprConstraint = SizeExpr{}
 	_ ExprConstraint = TraversalExpr{}
 	_ ExprConstraint = TupleConsExpr{}
 	_ ExprConstraint = TupleExpr{}
 	_ ExprConstraint = TypeDeclarationExpr{}
+	_ ExprConstraint = WeightedExpr{}
 )
+
+func TestExprConstraints_ByPrecedence(t *testing.T) {
+	testCases := []struct {
+		name        string
+		constraints ExprConstraints
+		expected    ExprConstraints
+	}{
+		{
+			"declaration order",
+			ExprConstraints{
+				LiteralTypeExpr{Type: cty.String},
+				KeywordExpr{Keyword: "foo"},
+			},
+			ExprConstraints{
+				LiteralTypeExpr{Type: cty.String},
+				KeywordExpr{Keyword: "foo"},
+			},
+		},
+		{
+			"weights",
+			ExprConstraints{
+				LiteralTypeExpr{Type: cty.String},
+				WeightedExpr{Expr: KeywordExpr{Keyword: "low"}, Weight: -1},
+				WeightedExpr{Expr: KeywordExpr{Keyword: "high"}, Weight: 2},
+				WeightedExpr{Expr: KeywordExpr{Keyword: "zero"}},
+				WeightedExpr{Expr: KeywordExpr{Keyword: "higher"}, Weight: 3},
+			},
+			ExprConstraints{
+				KeywordExpr{Keyword: "higher"},
+				KeywordExpr{Keyword: "high"},
+				LiteralTypeExpr{Type: cty.String},
+				KeywordExpr{Keyword: "zero"},
+				KeywordExpr{Keyword: "low"},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			sorted := tc.constraints.ByPrecedence()
+			if diff := cmp.Diff(tc.expected, sorted, ctydebug.CmpOptions); diff != "" {
+				t.Fatalf("unexpected constraints: %s", diff)
+			}
+		})
+	}
+}
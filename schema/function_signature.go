@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// FunctionSignature describes signature of a function
+// which can be called within expressions
+type FunctionSignature struct {
+	Description lang.MarkupContent
+
+	// ReturnType represents the type returned by the function,
+	// where cty.DynamicPseudoType represents a type which
+	// depends on arguments
+	ReturnType cty.Type
+
+	// Params describes fixed positional parameters of the function
+	Params []function.Parameter
+
+	// VarParam describes variadic parameter, if the function has one
+	VarParam *function.Parameter
+
+	// DeprecatedText explains why the function is deprecated
+	// (e.g. since which version of the dialect), if it is
+	DeprecatedText string
+
+	// ReplacedBy represents name of a function which should
+	// be called instead of this (deprecated) one, if any.
+	// The replacement is expected to accept the same arguments.
+	ReplacedBy string
+}
+
+// IsDeprecated returns true if the function is deprecated,
+// i.e. either DeprecatedText or ReplacedBy is set
+func (fs FunctionSignature) IsDeprecated() bool {
+	return fs.DeprecatedText != "" || fs.ReplacedBy != ""
+}
+
+func (fs FunctionSignature) Copy() FunctionSignature {
+	newFs := FunctionSignature{
+		Description:    fs.Description,
+		ReturnType:     fs.ReturnType,
+		DeprecatedText: fs.DeprecatedText,
+		ReplacedBy:     fs.ReplacedBy,
+	}
+
+	if fs.Params != nil {
+		newFs.Params = make([]function.Parameter, len(fs.Params))
+		copy(newFs.Params, fs.Params)
+	}
+
+	if fs.VarParam != nil {
+		varParam := *fs.VarParam
+		newFs.VarParam = &varParam
+	}
+
+	return newFs
+}
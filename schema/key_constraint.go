@@ -0,0 +1,92 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type keyConstrSigil struct{}
+
+// KeyConstraint represents a constraint on names of keys
+// which are not known upfront, such as names of attributes
+// matched by BodySchema.AnyAttribute
+type KeyConstraint interface {
+	isKeyConstraintImpl() keyConstrSigil
+	FriendlyName() string
+	IsValidKey(key string) bool
+	Validate() error
+	Copy() KeyConstraint
+}
+
+// KeyPattern represents keys matching a regular expression
+type KeyPattern struct {
+	Pattern *regexp.Regexp
+}
+
+func (KeyPattern) isKeyConstraintImpl() keyConstrSigil {
+	return keyConstrSigil{}
+}
+
+func (kp KeyPattern) FriendlyName() string {
+	if kp.Pattern == nil {
+		return ""
+	}
+	return fmt.Sprintf("matching %q", kp.Pattern.String())
+}
+
+func (kp KeyPattern) IsValidKey(key string) bool {
+	return kp.Pattern != nil && kp.Pattern.MatchString(key)
+}
+
+func (kp KeyPattern) Validate() error {
+	if kp.Pattern == nil {
+		return errors.New("Pattern must be set")
+	}
+	return nil
+}
+
+func (kp KeyPattern) Copy() KeyConstraint {
+	return KeyPattern{
+		// compiled regexp is safe for concurrent use
+		Pattern: kp.Pattern,
+	}
+}
+
+// KeyEnum represents keys from a fixed set of names
+type KeyEnum struct {
+	Names []string
+}
+
+func (KeyEnum) isKeyConstraintImpl() keyConstrSigil {
+	return keyConstrSigil{}
+}
+
+func (ke KeyEnum) FriendlyName() string {
+	quoted := make([]string, len(ke.Names))
+	for i, name := range ke.Names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return fmt.Sprintf("one of %s", strings.Join(quoted, ", "))
+}
+
+func (ke KeyEnum) IsValidKey(key string) bool {
+	return namesContain(ke.Names, key)
+}
+
+func (ke KeyEnum) Validate() error {
+	if len(ke.Names) == 0 {
+		return errors.New("Names must not be empty")
+	}
+	return nil
+}
+
+func (ke KeyEnum) Copy() KeyConstraint {
+	newKe := KeyEnum{}
+	if ke.Names != nil {
+		newKe.Names = make([]string, len(ke.Names))
+		copy(newKe.Names, ke.Names)
+	}
+	return newKe
+}
@@ -1,6 +1,10 @@
 package schema
 
 import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
 	"github.com/hashicorp/hcl-lang/lang"
 )
 
@@ -17,21 +21,81 @@ type LabelSchema struct {
 	// within Blocks's DependentBody can be used for completion
 	// This enables such behaviour.
 	Completable bool
+
+	// SemanticToken represents dialect-specific semantic token
+	// type and/or modifiers of the label
+	SemanticToken *SemanticToken
+
+	// ValueConstraint constrains valid values of the label,
+	// e.g. to identifiers via KeyPattern
+	ValueConstraint KeyConstraint
+
+	// MaxLength represents the maximum number of characters
+	// of the label value, where 0 means unlimited
+	MaxLength uint
+
+	// ReservedNames represents values which are not valid
+	// for the label, e.g. keywords of the dialect
+	ReservedNames []string
 }
 
 func (*LabelSchema) isSchemaImpl() schemaImplSigil {
 	return schemaImplSigil{}
 }
 
+func (ls *LabelSchema) Validate() error {
+	if ls.ValueConstraint != nil {
+		err := ls.ValueConstraint.Validate()
+		if err != nil {
+			return fmt.Errorf("ValueConstraint: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateValue returns an error describing why the given value
+// is not valid for the label, or nil if it is valid
+func (ls *LabelSchema) ValidateValue(value string) error {
+	if ls.MaxLength > 0 && uint(utf8.RuneCountInString(value)) > ls.MaxLength {
+		return fmt.Errorf("must be at most %d characters long", ls.MaxLength)
+	}
+
+	for _, name := range ls.ReservedNames {
+		if value == name {
+			return errors.New("is a reserved name")
+		}
+	}
+
+	if ls.ValueConstraint != nil && !ls.ValueConstraint.IsValidKey(value) {
+		return fmt.Errorf("must be %s", ls.ValueConstraint.FriendlyName())
+	}
+
+	return nil
+}
+
 func (ls *LabelSchema) Copy() *LabelSchema {
 	if ls == nil {
 		return nil
 	}
 
-	return &LabelSchema{
+	newLs := &LabelSchema{
 		Name:        ls.Name,
 		Completable: ls.Completable,
 		Description: ls.Description,
 		IsDepKey:    ls.IsDepKey,
+		MaxLength:   ls.MaxLength,
+
+		SemanticToken: ls.SemanticToken.Copy(),
 	}
+
+	if ls.ValueConstraint != nil {
+		newLs.ValueConstraint = ls.ValueConstraint.Copy()
+	}
+
+	if ls.ReservedNames != nil {
+		newLs.ReservedNames = make([]string, len(ls.ReservedNames))
+		copy(newLs.ReservedNames, ls.ReservedNames)
+	}
+
+	return newLs
 }
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func TestLabelSchema_ValidateValue(t *testing.T) {
+	testCases := []struct {
+		schema      *LabelSchema
+		value       string
+		expectedErr error
+	}{
+		{
+			&LabelSchema{Name: "name"},
+			"anything goes",
+			nil,
+		},
+		{
+			&LabelSchema{Name: "name", MaxLength: 3},
+			"fóo",
+			nil,
+		},
+		{
+			&LabelSchema{Name: "name", MaxLength: 3},
+			"foobar",
+			errors.New("must be at most 3 characters long"),
+		},
+		{
+			&LabelSchema{Name: "name", ReservedNames: []string{"count", "for_each"}},
+			"count",
+			errors.New("is a reserved name"),
+		},
+		{
+			&LabelSchema{
+				Name: "name",
+				ValueConstraint: KeyPattern{
+					Pattern: regexp.MustCompile(`^[a-z][a-z0-9_]*$`),
+				},
+			},
+			"1foo",
+			errors.New(`must be matching "^[a-z][a-z0-9_]*$"`),
+		},
+		{
+			&LabelSchema{
+				Name: "name",
+				ValueConstraint: KeyEnum{
+					Names: []string{"foo", "bar"},
+				},
+			},
+			"bar",
+			nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := tc.schema.ValidateValue(tc.value)
+			if tc.expectedErr == nil && err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectedErr != nil && err == nil {
+				t.Fatalf("expected error: %q, none given", tc.expectedErr.Error())
+			}
+			if tc.expectedErr != nil && tc.expectedErr.Error() != err.Error() {
+				t.Fatalf("error mismatch,\nexpected: %q\ngiven: %q", tc.expectedErr.Error(), err.Error())
+			}
+		})
+	}
+}
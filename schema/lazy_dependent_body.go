@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"context"
+	"sync"
+)
+
+// LoadDependentBodyFunc loads the dependent body schema for the key,
+// returning nil if there is no schema for the key
+type LoadDependentBodyFunc func(ctx context.Context, key SchemaKey) (*BodySchema, error)
+
+// LazyDependentBody represents dependent body schemas which are
+// loaded on first use and cached, rather than declared up front
+// in BlockSchema.DependentBody, e.g. to avoid loading schemas
+// of all providers of a large registry on startup.
+//
+// Schemas declared in BlockSchema.DependentBody take precedence.
+//
+// The decoder loads schemas synchronously while serving a query
+// and while holding the read lock of its schema, i.e. a slow load
+// delays not only the query, but also SetSchema and PatchSchema
+// until it finishes. Loading should therefore be fast (e.g. reading
+// a local cache), with any slow fetching done ahead of time.
+type LazyDependentBody struct {
+	// Keys represents all keys which schemas can be loaded for,
+	// such that these can be offered in completion of labels
+	// without loading the schemas.
+	Keys []SchemaKey
+
+	// ctx is the long-lived context of the whole LazyDependentBody
+	// (rather than of any query), as decoder queries have no context
+	ctx  context.Context
+	load LoadDependentBodyFunc
+
+	mu      sync.Mutex
+	entries map[SchemaKey]*lazyBodyEntry
+}
+
+type lazyBodyEntry struct {
+	done   chan struct{}
+	schema *BodySchema
+	err    error
+}
+
+// NewLazyDependentBody creates a new LazyDependentBody which loads
+// schemas via load.
+//
+// Schemas looked up by the decoder are always loaded within ctx,
+// which is expected to live as long as the LazyDependentBody itself,
+// e.g. the context of the language server. Cancelling ctx (e.g. on
+// shutdown) cancels any loading in progress and any further loading.
+// Loads triggered by the decoder cannot be cancelled per query,
+// see Load for loading within a context of the caller.
+func NewLazyDependentBody(ctx context.Context, keys []SchemaKey, load LoadDependentBodyFunc) *LazyDependentBody {
+	return &LazyDependentBody{
+		Keys:    keys,
+		ctx:     ctx,
+		load:    load,
+		entries: make(map[SchemaKey]*lazyBodyEntry, 0),
+	}
+}
+
+// BodySchema returns the schema for the key, loading it
+// within the context of the LazyDependentBody (see
+// NewLazyDependentBody) if needed, as used by the decoder.
+// It reports false if there is no schema or it failed to load.
+func (l *LazyDependentBody) BodySchema(key SchemaKey) (*BodySchema, bool) {
+	bodySchema, err := l.Load(l.ctx, key)
+	if err != nil || bodySchema == nil {
+		return nil, false
+	}
+	return bodySchema, true
+}
+
+// Load returns the schema for the key, loading it on first use.
+//
+// Concurrent calls for the same key share a single load. Errors
+// (including cancellation) are not cached, such that loading
+// is attempted again on next use.
+func (l *LazyDependentBody) Load(ctx context.Context, key SchemaKey) (*BodySchema, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &lazyBodyEntry{done: make(chan struct{})}
+		l.entries[key] = entry
+		l.mu.Unlock()
+
+		entry.schema, entry.err = l.load(ctx, key)
+		if entry.err != nil {
+			l.mu.Lock()
+			delete(l.entries, key)
+			l.mu.Unlock()
+		}
+		close(entry.done)
+
+		return entry.schema, entry.err
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-entry.done:
+		if entry.err != nil {
+			// the other load failed, e.g. as its context was cancelled
+			return l.Load(ctx, key)
+		}
+		return entry.schema, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Cached returns the schema for the key if it was loaded already
+func (l *LazyDependentBody) Cached(key SchemaKey) (*BodySchema, bool) {
+	l.mu.Lock()
+	entry, ok := l.entries[key]
+	l.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	select {
+	case <-entry.done:
+		return entry.schema, entry.err == nil && entry.schema != nil
+	default:
+		return nil, false
+	}
+}
+
+// Reset forgets all loaded schemas, e.g. after they changed on disk
+func (l *LazyDependentBody) Reset() {
+	l.mu.Lock()
+	l.entries = make(map[SchemaKey]*lazyBodyEntry, 0)
+	l.mu.Unlock()
+}
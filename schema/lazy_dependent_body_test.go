@@ -0,0 +1,133 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+func lazyTestKey(value string) SchemaKey {
+	return NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: value},
+		},
+	})
+}
+
+func TestLazyDependentBody_Load(t *testing.T) {
+	var loads int32
+	l := NewLazyDependentBody(context.Background(), nil, func(ctx context.Context, key SchemaKey) (*BodySchema, error) {
+		atomic.AddInt32(&loads, 1)
+		if key == lazyTestKey("unknown") {
+			return nil, nil
+		}
+		return &BodySchema{Detail: string(key)}, nil
+	})
+
+	key := lazyTestKey("foo")
+	if _, ok := l.Cached(key); ok {
+		t.Fatal("expected no cached schema before first use")
+	}
+
+	for i := 0; i < 3; i++ {
+		bodySchema, ok := l.BodySchema(key)
+		if !ok {
+			t.Fatal("expected schema to be loaded")
+		}
+		if bodySchema.Detail != string(key) {
+			t.Fatalf("unexpected schema: %#v", bodySchema)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("expected 1 load, %d given", loads)
+	}
+	if _, ok := l.Cached(key); !ok {
+		t.Fatal("expected cached schema after first use")
+	}
+
+	if _, ok := l.BodySchema(lazyTestKey("unknown")); ok {
+		t.Fatal("expected no schema for unknown key")
+	}
+
+	l.Reset()
+	if _, ok := l.Cached(key); ok {
+		t.Fatal("expected no cached schema after reset")
+	}
+	l.BodySchema(key)
+	if loads != 3 {
+		t.Fatalf("expected 3 loads, %d given", loads)
+	}
+}
+
+func TestLazyDependentBody_Load_concurrent(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	l := NewLazyDependentBody(context.Background(), nil, func(ctx context.Context, key SchemaKey) (*BodySchema, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return &BodySchema{Description: lang.PlainText("loaded")}, nil
+	})
+
+	key := lazyTestKey("foo")
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bodySchema, err := l.Load(context.Background(), key)
+			if err != nil || bodySchema == nil {
+				t.Errorf("unexpected result: %#v, %v", bodySchema, err)
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Fatalf("expected 1 load, %d given", loads)
+	}
+}
+
+func TestLazyDependentBody_Load_cancelled(t *testing.T) {
+	var loads int32
+	l := NewLazyDependentBody(context.Background(), nil, func(ctx context.Context, key SchemaKey) (*BodySchema, error) {
+		atomic.AddInt32(&loads, 1)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return &BodySchema{}, nil
+	})
+
+	key := lazyTestKey("foo")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := l.Load(ctx, key)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation error, given: %v", err)
+	}
+	if loads != 0 {
+		t.Fatalf("expected no load with cancelled context, %d given", loads)
+	}
+
+	var failingLoads int32
+	failing := NewLazyDependentBody(context.Background(), nil, func(ctx context.Context, key SchemaKey) (*BodySchema, error) {
+		if atomic.AddInt32(&failingLoads, 1) == 1 {
+			return nil, errors.New("temporary failure")
+		}
+		return &BodySchema{}, nil
+	})
+	if _, ok := failing.BodySchema(key); ok {
+		t.Fatal("expected failed load")
+	}
+	if _, ok := failing.BodySchema(key); !ok {
+		t.Fatal("expected failure not to be cached")
+	}
+}
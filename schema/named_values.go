@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NamedValues marks a block as a place where named values can be
+// declared, such that expressions can be extracted into them
+// (e.g. via a code action), and describes how the values are declared.
+type NamedValues struct {
+	// FriendlyName is the name of a single value used in UI,
+	// e.g. "local value" or "variable"
+	FriendlyName string
+
+	// ValueAttrName is the name of the attribute which holds the value,
+	// where each block declares a single value named by its only label,
+	// e.g. variable "name" { default = ... } in Terraform.
+	//
+	// If empty, each block declares any number of values as attributes
+	// of its body (see BodySchema.AnyAttribute), e.g. locals { name = ... }
+	ValueAttrName string
+}
+
+func (nv *NamedValues) Copy() *NamedValues {
+	if nv == nil {
+		return nil
+	}
+	return &NamedValues{
+		FriendlyName:  nv.FriendlyName,
+		ValueAttrName: nv.ValueAttrName,
+	}
+}
+
+func (nv *NamedValues) validate(bSchema *BlockSchema) error {
+	if nv.ValueAttrName == "" {
+		if len(bSchema.Labels) > 0 {
+			return errors.New("block with labels requires ValueAttrName")
+		}
+		if bSchema.Body == nil || bSchema.Body.AnyAttribute == nil {
+			return errors.New("empty ValueAttrName requires Body.AnyAttribute")
+		}
+		addr := bSchema.Body.AnyAttribute.Address
+		if addr == nil || len(addr.Steps) == 0 {
+			return errors.New("empty ValueAttrName requires addressable Body.AnyAttribute")
+		}
+		if _, ok := addr.Steps[len(addr.Steps)-1].(AttrNameStep); !ok {
+			return errors.New("empty ValueAttrName requires Body.AnyAttribute addressed by AttrNameStep")
+		}
+		return nil
+	}
+
+	if len(bSchema.Labels) != 1 {
+		return errors.New("ValueAttrName requires exactly one label")
+	}
+	if bSchema.Address == nil || len(bSchema.Address.Steps) == 0 {
+		return errors.New("ValueAttrName requires addressable block")
+	}
+	if _, ok := bSchema.Address.Steps[len(bSchema.Address.Steps)-1].(LabelStep); !ok {
+		return errors.New("ValueAttrName requires block addressed by LabelStep")
+	}
+	if bSchema.Body == nil {
+		return fmt.Errorf("ValueAttrName: %q is not declared in Body", nv.ValueAttrName)
+	}
+	if _, ok := bSchema.Body.Attributes[nv.ValueAttrName]; !ok {
+		return fmt.Errorf("ValueAttrName: %q is not declared in Body", nv.ValueAttrName)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// Ordering represents the order in which attributes and blocks
+// are expected within a body, e.g. per style conventions of a dialect.
+//
+// Attributes and blocks are only reordered relative to each other
+// as described; any not mentioned retain their relative order.
+type Ordering struct {
+	// First represents names of attributes and types of blocks
+	// expected before any others, in the given order,
+	// e.g. meta-arguments such as count or for_each
+	First []string
+
+	// Last represents names of attributes and types of blocks
+	// expected after any others, in the given order,
+	// e.g. meta-arguments such as depends_on or lifecycle
+	Last []string
+
+	// AlphabeticalAttributes defines whether attributes which are
+	// in neither First nor Last are expected in alphabetical order
+	// relative to each other. Blocks are not affected.
+	AlphabeticalAttributes bool
+}
+
+func (o *Ordering) Validate() error {
+	if o == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, 0)
+	for i, name := range o.First {
+		if name == "" {
+			return fmt.Errorf("First[%d]: name must be set", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("First[%d]: %q is declared more than once", i, name)
+		}
+		seen[name] = true
+	}
+	for i, name := range o.Last {
+		if name == "" {
+			return fmt.Errorf("Last[%d]: name must be set", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("Last[%d]: %q is declared more than once", i, name)
+		}
+		seen[name] = true
+	}
+
+	return nil
+}
+
+func (o *Ordering) Copy() *Ordering {
+	if o == nil {
+		return nil
+	}
+
+	newO := &Ordering{
+		AlphabeticalAttributes: o.AlphabeticalAttributes,
+	}
+	if o.First != nil {
+		newO.First = make([]string, len(o.First))
+		copy(newO.First, o.First)
+	}
+	if o.Last != nil {
+		newO.Last = make([]string, len(o.Last))
+		copy(newO.Last, o.Last)
+	}
+
+	return newO
+}
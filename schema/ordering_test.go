@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOrdering_Validate(t *testing.T) {
+	testCases := []struct {
+		ordering    *Ordering
+		expectedErr error
+	}{
+		{
+			&Ordering{
+				First:                  []string{"count", "for_each"},
+				Last:                   []string{"lifecycle"},
+				AlphabeticalAttributes: true,
+			},
+			nil,
+		},
+		{
+			&Ordering{
+				First: []string{""},
+			},
+			errors.New("First[0]: name must be set"),
+		},
+		{
+			&Ordering{
+				First: []string{"count", "count"},
+			},
+			errors.New(`First[1]: "count" is declared more than once`),
+		},
+		{
+			&Ordering{
+				First: []string{"count"},
+				Last:  []string{"lifecycle", "count"},
+			},
+			errors.New(`Last[1]: "count" is declared more than once`),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := tc.ordering.Validate()
+			if tc.expectedErr == nil && err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectedErr != nil && err == nil {
+				t.Fatalf("expected error: %q, none given", tc.expectedErr.Error())
+			}
+			if tc.expectedErr != nil && tc.expectedErr.Error() != err.Error() {
+				t.Fatalf("error mismatch,\nexpected: %q\ngiven: %q", tc.expectedErr.Error(), err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package schema
+
+// SemanticToken describes dialect-specific semantic token type
+// and/or modifiers of the token representing an attribute name,
+// block type or a label.
+//
+// Names refer to custom token types and modifiers registered
+// with the decoder (see Decoder.RegisterSemanticTokenType).
+// Unregistered names are ignored.
+type SemanticToken struct {
+	// Type replaces the default token type
+	Type string
+
+	// Modifiers are added to the default token modifiers
+	Modifiers []string
+}
+
+func (st *SemanticToken) Copy() *SemanticToken {
+	if st == nil {
+		return nil
+	}
+
+	newSt := &SemanticToken{
+		Type: st.Type,
+	}
+	if st.Modifiers != nil {
+		newSt.Modifiers = make([]string, len(st.Modifiers))
+		copy(newSt.Modifiers, st.Modifiers)
+	}
+
+	return newSt
+}
@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValueDefinition describes an externally declared value
+// (e.g. variable in Terraform) which can be assigned
+// in a values file (e.g. *.tfvars in Terraform)
+type ValueDefinition struct {
+	// Type represents the type constraint of the value
+	// cty.DynamicPseudoType (also known as "any type") is assumed
+	// if no type is declared.
+	Type cty.Type
+
+	Description  lang.MarkupContent
+	IsDeprecated bool
+	IsSensitive  bool
+}
+
+// ValueDefinitions represents value definitions keyed by name
+type ValueDefinitions map[string]*ValueDefinition
+
+func (vd *ValueDefinition) Copy() *ValueDefinition {
+	if vd == nil {
+		return nil
+	}
+
+	return &ValueDefinition{
+		// cty.Type is immutable by design
+		Type:         vd.Type,
+		Description:  vd.Description,
+		IsDeprecated: vd.IsDeprecated,
+		IsSensitive:  vd.IsSensitive,
+	}
+}
+
+func (vds ValueDefinitions) Copy() ValueDefinitions {
+	if vds == nil {
+		return nil
+	}
+
+	newVds := make(ValueDefinitions, len(vds))
+	for name, vd := range vds {
+		newVds[name] = vd.Copy()
+	}
+
+	return newVds
+}
+
+// NewValuesFileSchema creates a BodySchema for a "values file",
+// where each of the given definitions can be assigned
+// as a top-level attribute and no blocks are allowed.
+//
+// Values are always optional within a single file, as they may be
+// assigned elsewhere (e.g. in other file or via CLI flag).
+func NewValuesFileSchema(defs ValueDefinitions) *BodySchema {
+	bodySchema := &BodySchema{
+		Attributes: make(map[string]*AttributeSchema, len(defs)),
+		Blocks:     make(map[string]*BlockSchema, 0),
+	}
+
+	for name, def := range defs {
+		if def == nil {
+			continue
+		}
+
+		valType := def.Type
+		if valType == cty.NilType {
+			valType = cty.DynamicPseudoType
+		}
+
+		bodySchema.Attributes[name] = &AttributeSchema{
+			Description:  def.Description,
+			IsOptional:   true,
+			IsDeprecated: def.IsDeprecated,
+			IsSensitive:  def.IsSensitive,
+			Expr:         LiteralTypeOnly(valType),
+		}
+	}
+
+	return bodySchema
+}